@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"wms/internal/repository"
+	"wms/internal/service"
+	"wms/pkg/config"
+	"wms/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// reconcile 校验 stocks.quantity 与 stock_movements 流水合计是否一致
+// 全部一致时退出码为 0，存在差异时输出差异明细并以退出码 2 退出，便于在定时任务或 CI 中使用
+func main() {
+	postOpening := flag.Bool("post-opening-balances", false, "post opening_balance movements for stocks that have no ledger entries before reconciling")
+	operator := flag.String("operator", "system", "operator recorded on opening balance movements")
+	flag.Parse()
+
+	// 初始化配置
+	cfg, err := config.NewConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	// 初始化日志
+	log, err := logger.NewLogger(cfg.Environment)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize logger: %v\n", err)
+		os.Exit(1)
+	}
+	defer log.Sync()
+
+	// 建立数据库连接
+	db, err := gorm.Open(postgres.Open(cfg.DatabaseDSN), &gorm.Config{})
+	if err != nil {
+		log.Fatal("Failed to connect to database", zap.Error(err))
+	}
+
+	stockService := service.NewStockService(repository.NewStockRepository(db), log)
+
+	if *postOpening {
+		count, err := stockService.PostOpeningBalances(*operator)
+		if err != nil {
+			log.Fatal("Failed to post opening balances", zap.Error(err))
+		}
+		log.Info("Opening balances posted", zap.Int("count", count))
+	}
+
+	result, err := stockService.ReconcileLedger()
+	if err != nil {
+		log.Fatal("Failed to reconcile stock ledger", zap.Error(err))
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		log.Fatal("Failed to write reconciliation result", zap.Error(err))
+	}
+
+	if !result.Balanced {
+		os.Exit(2)
+	}
+}
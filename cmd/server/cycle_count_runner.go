@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"time"
+	"wms/internal/service"
+	"wms/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// runCycleCountScheduler 在后台定时生成循环盘点任务，直到 ctx 被取消
+// 启动时先补跑一次当天的计划（任务生成可重复执行），之后每天在 runHour 点执行
+func runCycleCountScheduler(ctx context.Context, svc service.CycleCountService, runHour int, log *logger.Logger) {
+	log.Info("Cycle count scheduler started", zap.Int("run_hour", runHour))
+	generateCycleCount(svc, time.Now(), log)
+
+	for {
+		next := nextCycleCountRun(time.Now(), runHour)
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			log.Info("Cycle count scheduler stopped")
+			return
+		case <-timer.C:
+			generateCycleCount(svc, next, log)
+		}
+	}
+}
+
+// generateCycleCount 生成指定日期的循环盘点任务，失败时记录日志等待下次执行
+func generateCycleCount(svc service.CycleCountService, date time.Time, log *logger.Logger) {
+	if _, err := svc.GenerateCycleCount(date); err != nil {
+		log.Error("Scheduled cycle count generation failed",
+			zap.String("date", date.Format("2006-01-02")),
+			zap.Error(err),
+		)
+	}
+}
+
+// nextCycleCountRun 返回 now 之后下一个 runHour 整点
+func nextCycleCountRun(now time.Time, runHour int) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), runHour, 0, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
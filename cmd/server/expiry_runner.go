@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"time"
+	"wms/internal/service"
+	"wms/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// runExpiryAlertScheduler 每天在 runHour 点列出 days 天内到期的库存并按库位记录预警，直到 ctx 被取消
+func runExpiryAlertScheduler(ctx context.Context, svc service.StockService, days, runHour int, log *logger.Logger) {
+	log.Info("Expiry alert scheduler started", zap.Int("days", days), zap.Int("run_hour", runHour))
+
+	for {
+		next := nextCycleCountRun(time.Now(), runHour)
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			log.Info("Expiry alert scheduler stopped")
+			return
+		case <-timer.C:
+			reportExpiringStocks(svc, days, log)
+		}
+	}
+}
+
+// reportExpiringStocks 生成效期预警并逐库位记录日志，失败时记录日志等待下次执行
+func reportExpiringStocks(svc service.StockService, days int, log *logger.Logger) {
+	report, err := svc.ExpiryReport(days)
+	if err != nil {
+		log.Error("Scheduled expiry alert failed", zap.Int("days", days), zap.Error(err))
+		return
+	}
+
+	for _, location := range report.Locations {
+		log.Warn("Stock expiring at location",
+			zap.String("location_code", location.LocationCode),
+			zap.Int("expiring_quantity", location.ExpiringQuantity),
+			zap.Int("expired_quantity", location.ExpiredQuantity),
+			zap.Int("stock_lines", len(location.Stocks)),
+		)
+	}
+	log.Info("Expiry alert completed",
+		zap.Int("days", days),
+		zap.String("cutoff", report.Cutoff.Format("2006-01-02")),
+		zap.Int("stock_lines", report.StockCount),
+		zap.Int("expired_lines", report.ExpiredCount),
+		zap.Int("locations", report.LocationCount),
+	)
+}
@@ -65,21 +65,121 @@ func main() {
 	log.Info("Database connection established successfully")
 
 	// 自动迁移数据库模型
-	if err := db.AutoMigrate(&model.Stock{}, &model.InventoryCheckRecord{}); err != nil {
+	if err := db.AutoMigrate(&model.Stock{}, &model.InventoryCheckRecord{}, &model.VarianceTolerance{}, &model.StockMovement{},
+		&model.StockTake{}, &model.StockTakeLocation{}, &model.StockTakeMaterial{}, &model.StockTakeChecker{},
+		&model.CountTask{}, &model.CountTaskLine{}, &model.RecountTask{},
+		&model.CycleCountPolicy{}, &model.MaterialValuation{}, &model.CycleCountTask{},
+		&model.VarianceReason{}, &model.InboundOrder{}, &model.InboundOrderLine{}, &model.InboundReceipt{},
+		&model.Location{}, &model.PutawayPolicy{}, &model.PutawayFixedBin{}, &model.PutawayTask{},
+		&model.StockTransfer{}, &model.StockTransferLine{},
+		&model.OutboundOrder{}, &model.OutboundOrderLine{}, &model.StockAllocation{},
+		&model.Wave{}, &model.PickTask{}, &model.WarehouseLayout{}, &model.LayoutEdge{},
+		&model.Material{}, &model.MaterialBarcode{}, &model.CartonType{}, &model.Package{}, &model.PackageLine{}, &model.Shipment{},
+		&model.ReturnOrder{}, &model.ReturnOrderLine{}, &model.ReturnReceipt{},
+		&model.ReplenishmentRule{}, &model.ReplenishmentTask{},
+		&model.SerialNumber{}, &model.InventoryCheckSerial{}); err != nil {
 		log.Fatal("Failed to auto-migrate database models", zap.Error(err))
 	}
+	if err := dropLegacyIndexes(db); err != nil {
+		log.Fatal("Failed to drop legacy indexes", zap.Error(err))
+	}
 
 	log.Info("Database migration completed successfully")
 
 	// 手动注入依赖
 	// 仓储层
 	inventoryRepo := repository.NewInventoryCheckRepository(db)
+	toleranceRepo := repository.NewVarianceToleranceRepository(db)
+	stockRepo := repository.NewStockRepository(db)
+	stockTakeRepo := repository.NewStockTakeRepository(db)
+	cycleCountRepo := repository.NewCycleCountRepository(db)
+	reasonRepo := repository.NewVarianceReasonRepository(db)
+	inboundRepo := repository.NewInboundRepository(db)
+	locationRepo := repository.NewLocationRepository(db)
+	putawayRepo := repository.NewPutawayRepository(db)
+	transferRepo := repository.NewTransferRepository(db)
+	outboundRepo := repository.NewOutboundRepository(db)
+	waveRepo := repository.NewWaveRepository(db)
+	layoutRepo := repository.NewLayoutRepository(db)
+	materialRepo := repository.NewMaterialRepository(db)
+	packingRepo := repository.NewPackingRepository(db)
+	returnRepo := repository.NewReturnRepository(db)
+	replenishmentRepo := repository.NewReplenishmentRepository(db)
 
 	// 服务层
-	inventoryService := service.NewInventoryService(inventoryRepo, log)
+	retry := retryPolicy(cfg)
+	inventoryService := service.NewInventoryService(inventoryRepo, stockRepo, toleranceRepo, stockTakeRepo, cycleCountRepo, reasonRepo, replenishmentRepo, service.InventoryOptions{
+		DefaultTolerance:    defaultTolerance(cfg),
+		Retry:               retry,
+		ReasonRequiredAbove: reasonThreshold(cfg),
+	}, log)
+	toleranceService := service.NewVarianceToleranceService(toleranceRepo, log)
+	stockService := service.NewStockService(stockRepo, log)
+	stockTakeService := service.NewStockTakeService(stockTakeRepo, inventoryRepo, stockRepo, locationRepo, layoutRepo, replenishmentRepo, retry, log)
+	cycleCountService := service.NewCycleCountService(cycleCountRepo, log)
+	reasonService := service.NewVarianceReasonService(reasonRepo, log)
+	inboundService := service.NewInboundService(inboundRepo, stockRepo, putawayRepo, locationRepo, outboundRepo, materialRepo, service.InboundOptions{
+		ReceivingLocation:  cfg.ReceivingLocation,
+		OverReceiptPercent: cfg.OverReceiptPercent,
+		CrossDockLocation:  cfg.CrossDockLocation,
+		Retry:              retry,
+	}, log)
+	locationService := service.NewLocationService(locationRepo, log)
+	putawayService := service.NewPutawayService(putawayRepo, locationRepo, stockRepo, retry, log)
+	transferService := service.NewTransferService(transferRepo, stockRepo, retry, log)
+	outboundService := service.NewOutboundService(outboundRepo, stockRepo, service.OutboundOptions{
+		AllocationStrategy: cfg.AllocationStrategy,
+		Retry:              retry,
+	}, log)
+	waveService := service.NewWaveService(waveRepo, outboundRepo, locationRepo, layoutRepo, cycleCountRepo, stockRepo, replenishmentRepo, service.WaveOptions{
+		PackingLocation: cfg.PackingLocation,
+		Retry:           retry,
+	}, log)
+	layoutService := service.NewLayoutService(layoutRepo, locationRepo, log)
+	packingService := service.NewPackingService(packingRepo, outboundRepo, materialRepo, stockRepo, retry, log)
+	materialService := service.NewMaterialService(materialRepo, log)
+	returnService := service.NewReturnService(returnRepo, outboundRepo, packingRepo, stockRepo, putawayRepo, locationRepo, service.ReturnOptions{
+		RestockLocation:      cfg.ReturnsLocation,
+		QuarantineLocation:   cfg.QuarantineLocation,
+		ScrapLocation:        cfg.ScrapLocation,
+		VendorReturnLocation: cfg.VendorReturnLocation,
+		Retry:                retry,
+	}, log)
+	replenishmentService := service.NewReplenishmentService(replenishmentRepo, stockRepo, retry, log)
+
+	// 预置默认差异原因代码，已存在的代码不会被覆盖
+	if err := reasonService.SeedDefaultReasons(); err != nil {
+		log.Fatal("Failed to seed variance reasons", zap.Error(err))
+	}
+
+	// 登记系统使用的暂存库位，并为启用库位校验前已有库存的库位编码补建主数据
+	if _, err := locationService.RegisterKnownLocations(stagingLocations(cfg)); err != nil {
+		log.Fatal("Failed to register known locations", zap.Error(err))
+	}
+
+	// 为启用物料校验前已有库存的物料编码补建主数据，避免这些库存无法作业
+	if _, err := materialService.RegisterStockedMaterials(); err != nil {
+		log.Fatal("Failed to register stocked materials", zap.Error(err))
+	}
 
 	// 处理器层
 	inventoryHandler := handlers.NewInventoryHandler(inventoryService, log)
+	toleranceHandler := handlers.NewToleranceHandler(toleranceService, log)
+	stockHandler := handlers.NewStockHandler(stockService, log)
+	stockTakeHandler := handlers.NewStockTakeHandler(stockTakeService, log)
+	cycleCountHandler := handlers.NewCycleCountHandler(cycleCountService, log)
+	reasonHandler := handlers.NewReasonHandler(reasonService, log)
+	inboundHandler := handlers.NewInboundHandler(inboundService, log)
+	locationHandler := handlers.NewLocationHandler(locationService, log)
+	putawayHandler := handlers.NewPutawayHandler(putawayService, log)
+	transferHandler := handlers.NewTransferHandler(transferService, log)
+	outboundHandler := handlers.NewOutboundHandler(outboundService, log)
+	waveHandler := handlers.NewWaveHandler(waveService, log)
+	layoutHandler := handlers.NewLayoutHandler(layoutService, log)
+	packingHandler := handlers.NewPackingHandler(packingService, log)
+	materialHandler := handlers.NewMaterialHandler(materialService, log)
+	returnHandler := handlers.NewReturnHandler(returnService, log)
+	replenishmentHandler := handlers.NewReplenishmentHandler(replenishmentService, log)
 
 	// 初始化 Gin 路由
 	if cfg.IsProduction() {
@@ -89,7 +189,25 @@ func main() {
 	router := gin.Default()
 
 	// 注册 API 路由
-	routes.SetupRoutes(router, inventoryHandler)
+	routes.SetupRoutes(router, routes.Handlers{
+		Inventory:     inventoryHandler,
+		Tolerance:     toleranceHandler,
+		StockTake:     stockTakeHandler,
+		Stock:         stockHandler,
+		CycleCount:    cycleCountHandler,
+		Reason:        reasonHandler,
+		Inbound:       inboundHandler,
+		Location:      locationHandler,
+		Putaway:       putawayHandler,
+		Transfer:      transferHandler,
+		Outbound:      outboundHandler,
+		Wave:          waveHandler,
+		Layout:        layoutHandler,
+		Packing:       packingHandler,
+		Material:      materialHandler,
+		Return:        returnHandler,
+		Replenishment: replenishmentHandler,
+	})
 
 	// 创建 HTTP 服务器
 	srv := &http.Server{
@@ -107,6 +225,20 @@ func main() {
 		}
 	}()
 
+	// 启动循环盘点、补货扫描与效期预警定时任务，停机时随上下文取消退出
+	schedulerCtx, stopScheduler := context.WithCancel(context.Background())
+	defer stopScheduler()
+	if cfg.CycleCountEnabled {
+		go runCycleCountScheduler(schedulerCtx, cycleCountService, cfg.CycleCountRunHour, log)
+	}
+	if cfg.ReplenishmentEnabled {
+		interval := time.Duration(cfg.ReplenishmentIntervalMinutes) * time.Minute
+		go runReplenishmentScheduler(schedulerCtx, replenishmentService, interval, log)
+	}
+	if cfg.ExpiryAlertEnabled {
+		go runExpiryAlertScheduler(schedulerCtx, stockService, cfg.ExpiryAlertDays, cfg.ExpiryAlertRunHour, log)
+	}
+
 	log.Info("WMS Inventory System is running",
 		zap.String("address", cfg.GetServerAddr()),
 		zap.String("environment", cfg.Environment),
@@ -122,6 +254,8 @@ func main() {
 		zap.String("signal", sig.String()),
 	)
 
+	stopScheduler()
+
 	// 创建 5 秒超时的上下文
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -142,3 +276,81 @@ func main() {
 
 	log.Info("WMS Inventory System shutdown complete")
 }
+
+// defaultTolerance 将配置中的默认容差转换为服务层容差，负数表示该维度不限制
+func defaultTolerance(cfg *config.Config) service.Tolerance {
+	var tolerance service.Tolerance
+	if cfg.VarianceAbsoluteTolerance >= 0 {
+		absolute := cfg.VarianceAbsoluteTolerance
+		tolerance.Absolute = &absolute
+	}
+	if cfg.VariancePercentTolerance >= 0 {
+		percentage := cfg.VariancePercentTolerance
+		tolerance.Percentage = &percentage
+	}
+	return tolerance
+}
+
+// reasonThreshold 将配置中的差异原因阈值转换为服务层配置，负数表示原因始终可选
+func reasonThreshold(cfg *config.Config) *int {
+	if cfg.VarianceReasonThreshold < 0 {
+		return nil
+	}
+	threshold := cfg.VarianceReasonThreshold
+	return &threshold
+}
+
+// stagingLocations 根据配置构造系统使用的暂存库位：收货区与退货区为月台，质检区为隔离位，其余为虚拟库位，均不参与上架推荐
+func stagingLocations(cfg *config.Config) []model.Location {
+	staging := []struct {
+		code         string
+		locationType string
+	}{
+		{cfg.ReceivingLocation, model.LocationTypeDock},
+		{cfg.ReturnsLocation, model.LocationTypeDock},
+		{cfg.QuarantineLocation, model.LocationTypeQuarantine},
+		{cfg.PackingLocation, model.LocationTypeVirtual},
+		{cfg.CrossDockLocation, model.LocationTypeVirtual},
+		{cfg.ScrapLocation, model.LocationTypeVirtual},
+		{cfg.VendorReturnLocation, model.LocationTypeVirtual},
+	}
+	seen := make(map[string]bool, len(staging))
+	locations := make([]model.Location, 0, len(staging))
+	for _, s := range staging {
+		if s.code == "" || seen[s.code] {
+			continue
+		}
+		seen[s.code] = true
+		locations = append(locations, model.Location{Code: s.code, Type: s.locationType, Active: true})
+	}
+	return locations
+}
+
+// retryPolicy 根据配置构造事务冲突重试策略
+func retryPolicy(cfg *config.Config) service.RetryPolicy {
+	policy := service.DefaultRetryPolicy()
+	policy.MaxAttempts = cfg.TxMaxAttempts
+	policy.BaseDelay = time.Duration(cfg.TxRetryBaseDelayMs) * time.Millisecond
+	return policy
+}
+
+// dropLegacyIndexes 删除已被新唯一索引取代的旧唯一索引：库存与盘点任务行启用批次后，
+// 同一 (物料, 库位) 与 (盘点任务, 物料) 可以有多个批次，旧索引会阻止写入
+func dropLegacyIndexes(db *gorm.DB) error {
+	legacy := []struct {
+		model interface{}
+		name  string
+	}{
+		{&model.Stock{}, model.LegacyStockUniqueIndex},
+		{&model.CountTaskLine{}, model.LegacyCountTaskLineUniqueIndex},
+	}
+	for _, l := range legacy {
+		if !db.Migrator().HasIndex(l.model, l.name) {
+			continue
+		}
+		if err := db.Migrator().DropIndex(l.model, l.name); err != nil {
+			return fmt.Errorf("failed to drop index %s: %w", l.name, err)
+		}
+	}
+	return nil
+}
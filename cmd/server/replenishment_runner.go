@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"time"
+	"wms/internal/model"
+	"wms/internal/service"
+	"wms/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// replenishmentOperator 记录在定时补货扫描生成的补货任务上的创建人
+const replenishmentOperator = "scheduler"
+
+// runReplenishmentScheduler 在后台按固定间隔扫描拣货位补货水位，直到 ctx 被取消
+// 启动时先扫描一次；已有未完成补货任务覆盖缺口的拣货位不会重复生成任务
+func runReplenishmentScheduler(ctx context.Context, svc service.ReplenishmentService, interval time.Duration, log *logger.Logger) {
+	log.Info("Replenishment scheduler started", zap.Duration("interval", interval))
+	runReplenishment(svc, log)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Replenishment scheduler stopped")
+			return
+		case <-ticker.C:
+			runReplenishment(svc, log)
+		}
+	}
+}
+
+// runReplenishment 扫描全部启用的补货水位，失败时记录日志等待下次执行
+func runReplenishment(svc service.ReplenishmentService, log *logger.Logger) {
+	input := service.ReplenishmentRunInput{CreatedBy: replenishmentOperator}
+	if _, err := svc.Run(input, model.ReplenishmentTriggerScheduled); err != nil {
+		log.Error("Scheduled replenishment run failed", zap.Error(err))
+	}
+}
@@ -1,17 +1,700 @@
 package dto
 
+import "time"
+
 // InventoryCheckRequest 表示库存盘点上传的请求负载
+// 启用批次管理的物料须给出 lot_number，manufacture_date、expiry_date 在批次首次入账时记录；
+// 启用序列号管理的物料以 serial_numbers 提交盘点到的序列号清单，actual_quantity 可省略，取清单中的序列号数
 type InventoryCheckRequest struct {
-	CheckerID      string `json:"checker_id" binding:"required"`
-	LocationCode   string `json:"location_code" binding:"required"`
-	MaterialCode   string `json:"material_code" binding:"required"`
-	ActualQuantity int    `json:"actual_quantity" binding:"required,min=0"`
+	CheckerID       string     `json:"checker_id" binding:"required"`
+	LocationCode    string     `json:"location_code" binding:"required"`
+	MaterialCode    string     `json:"material_code" binding:"required"`
+	LotNumber       string     `json:"lot_number" binding:"max=100"`
+	ManufactureDate *time.Time `json:"manufacture_date"`
+	ExpiryDate      *time.Time `json:"expiry_date"`
+	ActualQuantity  int        `json:"actual_quantity" binding:"required_without=SerialNumbers,min=0"`
+	SerialNumbers   []string   `json:"serial_numbers" binding:"omitempty,dive,required,max=100"`
+	StockTakeID     *uint      `json:"stock_take_id" binding:"omitempty,min=1"`
+	RecountTaskID   *uint      `json:"recount_task_id" binding:"omitempty,min=1"`
+	ReasonCode      string     `json:"reason_code" binding:"max=50"`
+	Note            string     `json:"note" binding:"max=500"`
+}
+
+// InventoryCheckLineResult 表示批量盘点中单行的处理结果
+type InventoryCheckLineResult struct {
+	Index        int    `json:"index"`
+	Status       string `json:"status"`
+	ErrorCode    string `json:"error_code,omitempty"`
+	ErrorMessage string `json:"error_message,omitempty"`
+	RecordID     uint   `json:"record_id,omitempty"`
+	Difference   *int   `json:"difference,omitempty"`
+}
+
+// BatchInventoryCheckResponse 表示批量盘点接口的响应数据
+type BatchInventoryCheckResponse struct {
+	Mode         string                     `json:"mode"`
+	Total        int                        `json:"total"`
+	SuccessCount int                        `json:"success_count"`
+	FailureCount int                        `json:"failure_count"`
+	Results      []InventoryCheckLineResult `json:"results"`
+}
+
+// CheckRecordListQuery 表示盘点记录列表的查询参数
+// 时间参数使用 RFC3339 格式，区间为左闭右开 [from, to)
+type CheckRecordListQuery struct {
+	MaterialCode   string    `form:"material_code"`
+	LocationCode   string    `form:"location_code"`
+	CheckerID      string    `form:"checker_id"`
+	From           time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To             time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	IsProcessed    *bool     `form:"is_processed"`
+	ApprovalStatus string    `form:"approval_status" binding:"omitempty,oneof=auto_approved pending approved rejected superseded"`
+	StockTakeID    *uint     `form:"stock_take_id" binding:"omitempty,min=1"`
+	SortBy         string    `form:"sort_by" binding:"omitempty,oneof=check_time id difference"`
+	Order          string    `form:"order" binding:"omitempty,oneof=asc desc"`
+	Cursor         string    `form:"cursor"`
+	Limit          int       `form:"limit" binding:"omitempty,min=1,max=200"`
+}
+
+// VarianceApprovalRequest 表示审批或驳回盘点差异的请求负载
+type VarianceApprovalRequest struct {
+	ApproverID string `json:"approver_id" binding:"required"`
+	Comment    string `json:"comment" binding:"max=500"`
+}
+
+// VarianceToleranceRequest 表示新增或更新差异容差配置的请求负载
+// material_code 与 location_code 留空表示对任意物料/库位生效
+type VarianceToleranceRequest struct {
+	MaterialCode        string   `json:"material_code" binding:"max=100"`
+	LocationCode        string   `json:"location_code" binding:"max=100"`
+	AbsoluteTolerance   *int     `json:"absolute_tolerance" binding:"omitempty,min=0"`
+	PercentageTolerance *float64 `json:"percentage_tolerance" binding:"omitempty,min=0"`
+}
+
+// RecountTaskListQuery 表示复盘任务列表的查询参数
+type RecountTaskListQuery struct {
+	Status     string `form:"status" binding:"omitempty,oneof=open completed cancelled"`
+	AssignedTo string `form:"assigned_to"`
+}
+
+// RecountAssignRequest 表示指派复盘任务的请求负载
+type RecountAssignRequest struct {
+	CheckerID string `json:"checker_id" binding:"required,max=100"`
+}
+
+// StockTakeCreateRequest 表示创建盘点单的请求负载
+// location_codes 与 material_codes 均为空表示全仓盘点；checker_ids 为空表示不限制盘点人
+// blind_count 为 true 时开始盘点会按库位生成盲盘任务并冻结系统库存
+type StockTakeCreateRequest struct {
+	Name          string   `json:"name" binding:"required,max=200"`
+	Description   string   `json:"description" binding:"max=500"`
+	CreatedBy     string   `json:"created_by" binding:"required,max=100"`
+	LocationCodes []string `json:"location_codes" binding:"dive,required,max=100"`
+	MaterialCodes []string `json:"material_codes" binding:"dive,required,max=100"`
+	CheckerIDs    []string `json:"checker_ids" binding:"dive,required,max=100"`
+	BlindCount    bool     `json:"blind_count"`
+}
+
+// StockTakeListQuery 表示盘点单列表的查询参数
+type StockTakeListQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=draft in_progress counting_closed reconciled posted"`
+}
+
+// CountTaskListQuery 表示盲盘任务列表的查询参数
+type CountTaskListQuery struct {
+	CheckerID string `form:"checker_id"`
+}
+
+// StockTakePostRequest 表示过账盘点单的请求负载
+type StockTakePostRequest struct {
+	OperatorID string `json:"operator_id" binding:"required,max=100"`
+}
+
+// CycleCountPolicyRequest 表示更新循环盘点配置的请求负载
+// a_class_share / b_class_share 为累计占比百分比，max_tasks_per_day 为 0 表示不限制
+type CycleCountPolicyRequest struct {
+	Basis          string  `json:"basis" binding:"required,oneof=volume value"`
+	LookbackDays   int     `json:"lookback_days" binding:"required,min=1"`
+	AClassShare    float64 `json:"a_class_share" binding:"required,gt=0,max=100"`
+	BClassShare    float64 `json:"b_class_share" binding:"required,gt=0,max=100"`
+	AFrequencyDays int     `json:"a_frequency_days" binding:"required,min=1"`
+	BFrequencyDays int     `json:"b_frequency_days" binding:"required,min=1"`
+	CFrequencyDays int     `json:"c_frequency_days" binding:"required,min=1"`
+	MaxTasksPerDay int     `json:"max_tasks_per_day" binding:"min=0"`
+	UpdatedBy      string  `json:"updated_by" binding:"required,max=100"`
+}
+
+// MaterialValuationRequest 表示保存物料单位价值的请求负载
+type MaterialValuationRequest struct {
+	MaterialCode string   `json:"material_code" binding:"required,max=100"`
+	UnitCost     *float64 `json:"unit_cost" binding:"required,min=0"`
+}
+
+// CycleCountDateQuery 表示按日期查询循环盘点计划的参数，date 格式为 2006-01-02，为空表示当天
+type CycleCountDateQuery struct {
+	Date string `form:"date" json:"date"`
+}
+
+// CycleCountTaskListQuery 表示循环盘点任务列表的查询参数
+type CycleCountTaskListQuery struct {
+	Date     string `form:"date"`
+	Status   string `form:"status" binding:"omitempty,oneof=open completed"`
+	ABCClass string `form:"abc_class" binding:"omitempty,oneof=A B C"`
+	Source   string `form:"source" binding:"omitempty,oneof=scheduled short_pick"`
+}
+
+// VarianceReasonRequest 表示新增或更新差异原因代码的请求负载
+// active 省略时视为启用
+type VarianceReasonRequest struct {
+	Code            string `json:"code" binding:"required,max=50"`
+	Description     string `json:"description" binding:"required,max=200"`
+	PostingCategory string `json:"posting_category" binding:"required,oneof=shrinkage damage process_error found_stock"`
+	Direction       string `json:"direction" binding:"omitempty,oneof=any gain loss"`
+	Active          *bool  `json:"active"`
+}
+
+// VarianceReasonListQuery 表示差异原因代码列表的查询参数
+type VarianceReasonListQuery struct {
+	ActiveOnly bool `form:"active_only"`
+}
+
+// VarianceReportQuery 表示差异报表的查询参数
+// group_by 为逗号分隔的分组字段，可选 reason_code、posting_category、material_code、location_code
+type VarianceReportQuery struct {
+	From         time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To           time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	GroupBy      string    `form:"group_by"`
+	MaterialCode string    `form:"material_code"`
+	LocationCode string    `form:"location_code"`
+	ReasonCode   string    `form:"reason_code"`
+}
+
+// StockListQuery 表示库存余额列表的查询参数
+type StockListQuery struct {
+	MaterialCode string `form:"material_code"`
+	LocationCode string `form:"location_code"`
+	LotNumber    string `form:"lot_number"`
+}
+
+// LotTraceQuery 表示批次追溯的查询参数，material_code 省略时追溯所有物料的同号批次
+type LotTraceQuery struct {
+	MaterialCode string `form:"material_code"`
+}
+
+// SerialListQuery 表示序列号列表的查询参数
+type SerialListQuery struct {
+	MaterialCode string `form:"material_code"`
+	SerialNumber string `form:"serial_number"`
+	LocationCode string `form:"location_code"`
+	LotNumber    string `form:"lot_number"`
+	Status       string `form:"status" binding:"omitempty,oneof=in_stock shipped missing"`
+}
+
+// ExpiryReportQuery 表示效期预警的查询参数，days 省略时使用默认预警天数
+type ExpiryReportQuery struct {
+	Days *int `form:"days" binding:"omitempty,min=0,max=3650"`
+}
+
+// MovementListQuery 表示库存流水列表的查询参数
+type MovementListQuery struct {
+	MaterialCode string    `form:"material_code"`
+	LocationCode string    `form:"location_code"`
+	LotNumber    string    `form:"lot_number"`
+	MovementType string    `form:"movement_type"`
+	From         time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To           time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Cursor       string    `form:"cursor"`
+	Limit        int       `form:"limit" binding:"omitempty,min=1,max=200"`
+}
+
+// InboundOrderCreateRequest 表示创建入库单据（采购订单或预到货通知）的请求负载
+// receiving_location 与 over_receipt_percent 省略时使用系统默认值；line_no 省略时按顺序编号
+type InboundOrderCreateRequest struct {
+	OrderNo            string                    `json:"order_no" binding:"required,max=50"`
+	OrderType          string                    `json:"order_type" binding:"required,oneof=po asn"`
+	SupplierCode       string                    `json:"supplier_code" binding:"max=100"`
+	PurchaseOrderNo    string                    `json:"purchase_order_no" binding:"max=50"`
+	ExpectedAt         *time.Time                `json:"expected_at"`
+	ReceivingLocation  string                    `json:"receiving_location" binding:"max=100"`
+	OverReceiptPercent *float64                  `json:"over_receipt_percent" binding:"omitempty,min=0"`
+	CreatedBy          string                    `json:"created_by" binding:"required,max=100"`
+	Lines              []InboundOrderLineRequest `json:"lines" binding:"required,min=1,dive"`
+}
+
+// InboundOrderLineRequest 表示入库单据的预期物料行
+type InboundOrderLineRequest struct {
+	LineNo           int    `json:"line_no" binding:"min=0"`
+	MaterialCode     string `json:"material_code" binding:"required,max=100"`
+	ExpectedQuantity int    `json:"expected_quantity" binding:"required,min=1"`
+}
+
+// InboundOrderListQuery 表示入库单据列表的查询参数
+type InboundOrderListQuery struct {
+	Status       string `form:"status" binding:"omitempty,oneof=open receiving closed cancelled"`
+	OrderType    string `form:"order_type" binding:"omitempty,oneof=po asn"`
+	SupplierCode string `form:"supplier_code"`
+}
+
+// InboundReceiveRequest 表示收货的请求负载
+// location_code 省略时收货到单据的收货暂存库位；每行按 line_no 匹配，line_no 省略时按 material_code 匹配
+type InboundReceiveRequest struct {
+	ReceivedBy   string                      `json:"received_by" binding:"required,max=100"`
+	LocationCode string                      `json:"location_code" binding:"max=100"`
+	Note         string                      `json:"note" binding:"max=500"`
+	Lines        []InboundReceiveLineRequest `json:"lines" binding:"required,min=1,dive"`
+}
+
+// InboundReceiveLineRequest 表示收货的一行
+// 启用批次管理的物料须给出 lot_number；同一单据行收到多个批次时按批次分多行提交；
+// 启用序列号管理的物料须在 serial_numbers 中逐件给出序列号，个数与 quantity 一致
+type InboundReceiveLineRequest struct {
+	LineNo          int        `json:"line_no" binding:"min=0"`
+	MaterialCode    string     `json:"material_code" binding:"required_without=LineNo,max=100"`
+	LotNumber       string     `json:"lot_number" binding:"max=100"`
+	ManufactureDate *time.Time `json:"manufacture_date"`
+	ExpiryDate      *time.Time `json:"expiry_date"`
+	Quantity        int        `json:"quantity" binding:"required,min=1"`
+	SerialNumbers   []string   `json:"serial_numbers" binding:"omitempty,dive,required,max=100"`
+}
+
+// InboundOrderActionRequest 表示关闭或取消入库单据的请求负载
+type InboundOrderActionRequest struct {
+	OperatorID string `json:"operator_id" binding:"required,max=100"`
+	Reason     string `json:"reason" binding:"max=500"`
+}
+
+// LocationRequest 表示新增或更新库位的请求负载
+// warehouse、zone、aisle、rack、bin 为库位层级；type 省略时视为存储位（reserve）；
+// putaway_enabled、active 省略时视为允许上架、启用；capacity、max_volume_cm3、max_weight_kg、max_skus 为 0 表示不限制；
+// blocked 为 true 时须给出 block_reason；bay、level 与坐标 x、y（米）描述库位在仓库布局中的位置，省略时库位不参与路线规划
+type LocationRequest struct {
+	Code           string  `json:"code" binding:"required,max=100"`
+	Warehouse      string  `json:"warehouse" binding:"max=50"`
+	Zone           string  `json:"zone" binding:"max=50"`
+	Type           string  `json:"type" binding:"omitempty,oneof=pick_face reserve dock quarantine virtual"`
+	Sequence       int     `json:"sequence"`
+	Capacity       int     `json:"capacity" binding:"min=0"`
+	MaxVolumeCm3   float64 `json:"max_volume_cm3" binding:"min=0"`
+	MaxWeightKg    float64 `json:"max_weight_kg" binding:"min=0"`
+	MaxSkus        int     `json:"max_skus" binding:"min=0"`
+	PutawayEnabled *bool   `json:"putaway_enabled"`
+	Blocked        bool    `json:"blocked"`
+	BlockReason    string  `json:"block_reason" binding:"max=200"`
+	Active         *bool   `json:"active"`
+	Aisle          string  `json:"aisle" binding:"max=20"`
+	Rack           string  `json:"rack" binding:"max=20"`
+	Bin            string  `json:"bin" binding:"max=20"`
+	Bay            int     `json:"bay" binding:"min=0"`
+	Level          int     `json:"level" binding:"min=0"`
+	X              float64 `json:"x"`
+	Y              float64 `json:"y"`
+}
+
+// LocationListQuery 表示库位列表的查询参数
+type LocationListQuery struct {
+	Warehouse string `form:"warehouse"`
+	Zone      string `form:"zone"`
+	Aisle     string `form:"aisle"`
+	Type      string `form:"type"`
+	Blocked   *bool  `form:"blocked"`
+	Active    *bool  `form:"active"`
+}
+
+// LocationGenerateRequest 表示按编码范围批量生成库位的请求负载
+// from、to 形如 A-01-01、A-20-05，各段须同为等宽数字或单个字母，相同的段保持不变；
+// aisle_spacing、bay_spacing 大于 0 时按巷道与货架序号从 (origin_x, origin_y) 起计算坐标；dry_run 为 true 时只返回将生成的编码
+type LocationGenerateRequest struct {
+	From           string  `json:"from" binding:"required,max=100"`
+	To             string  `json:"to" binding:"required,max=100"`
+	Warehouse      string  `json:"warehouse" binding:"max=50"`
+	Zone           string  `json:"zone" binding:"max=50"`
+	Type           string  `json:"type" binding:"omitempty,oneof=pick_face reserve dock quarantine virtual"`
+	Capacity       int     `json:"capacity" binding:"min=0"`
+	MaxVolumeCm3   float64 `json:"max_volume_cm3" binding:"min=0"`
+	MaxWeightKg    float64 `json:"max_weight_kg" binding:"min=0"`
+	MaxSkus        int     `json:"max_skus" binding:"min=0"`
+	PutawayEnabled *bool   `json:"putaway_enabled"`
+	SequenceStart  int     `json:"sequence_start"`
+	OriginX        float64 `json:"origin_x"`
+	OriginY        float64 `json:"origin_y"`
+	AisleSpacing   float64 `json:"aisle_spacing" binding:"min=0"`
+	BaySpacing     float64 `json:"bay_spacing" binding:"min=0"`
+	DryRun         bool    `json:"dry_run"`
+}
+
+// LayoutRequest 表示更新仓库布局设置的请求负载
+// depot_x、depot_y 为拣货与盘点路线的起止位置；front_aisle_y 与 back_aisle_y 为巷道两端前、后横向通道的纵坐标，
+// 二者相等表示未配置横向通道；routing_strategy 为默认的路线策略
+type LayoutRequest struct {
+	DepotX          float64 `json:"depot_x"`
+	DepotY          float64 `json:"depot_y"`
+	FrontAisleY     float64 `json:"front_aisle_y"`
+	BackAisleY      float64 `json:"back_aisle_y"`
+	RoutingStrategy string  `json:"routing_strategy" binding:"required,oneof=s_shape largest_gap tsp"`
+	UpdatedBy       string  `json:"updated_by" binding:"required,max=100"`
+}
+
+// LayoutEdgeRequest 表示新增或更新行走图通路的请求负载，节点为库位编码、DEPOT（出发点）或自定义路口名称
+type LayoutEdgeRequest struct {
+	FromNode string  `json:"from_node" binding:"required,max=100"`
+	ToNode   string  `json:"to_node" binding:"required,max=100"`
+	Distance float64 `json:"distance" binding:"required,gt=0"`
+}
+
+// RouteRequest 表示规划库位行走路线的请求负载，strategy 省略时使用布局的默认策略
+type RouteRequest struct {
+	LocationCodes []string `json:"location_codes" binding:"required,min=1,max=1000,dive,required,max=100"`
+	Strategy      string   `json:"strategy" binding:"omitempty,oneof=s_shape largest_gap tsp"`
+}
+
+// PutawayPolicyRequest 表示更新上架规则的请求负载
+// strategies 按优先级排列，可选 fixed_bin、consolidate、nearest_empty
+type PutawayPolicyRequest struct {
+	Strategies      []string `json:"strategies" binding:"required,min=1,dive,oneof=fixed_bin consolidate nearest_empty"`
+	EnforceCapacity bool     `json:"enforce_capacity"`
+	UpdatedBy       string   `json:"updated_by" binding:"required,max=100"`
+}
+
+// PutawayFixedBinRequest 表示设置物料固定库位的请求负载
+type PutawayFixedBinRequest struct {
+	MaterialCode string `json:"material_code" binding:"required,max=100"`
+	LocationCode string `json:"location_code" binding:"required,max=100"`
+}
+
+// PutawayTaskCreateRequest 表示手工创建上架任务的请求负载
+type PutawayTaskCreateRequest struct {
+	MaterialCode   string `json:"material_code" binding:"required,max=100"`
+	LotNumber      string `json:"lot_number" binding:"max=100"`
+	SourceLocation string `json:"source_location" binding:"required,max=100"`
+	Quantity       int    `json:"quantity" binding:"required,min=1"`
+	CreatedBy      string `json:"created_by" binding:"required,max=100"`
+}
+
+// PutawayTaskListQuery 表示上架任务列表的查询参数
+type PutawayTaskListQuery struct {
+	Status       string `form:"status" binding:"omitempty,oneof=open completed"`
+	AssignedTo   string `form:"assigned_to"`
+	MaterialCode string `form:"material_code"`
+}
+
+// PutawayAssignRequest 表示指派上架任务的请求负载
+type PutawayAssignRequest struct {
+	AssignedTo string `json:"assigned_to" binding:"required,max=100"`
+}
+
+// PutawayOverrideRequest 表示改派上架库位的请求负载
+type PutawayOverrideRequest struct {
+	LocationCode string `json:"location_code" binding:"required,max=100"`
+	OperatorID   string `json:"operator_id" binding:"required,max=100"`
+	Reason       string `json:"reason" binding:"required,max=500"`
+}
+
+// PutawayConfirmRequest 表示确认上架的请求负载
+type PutawayConfirmRequest struct {
+	OperatorID string `json:"operator_id" binding:"required,max=100"`
+}
+
+// StockTransferRequest 表示库位间移库的请求负载，所有行在同一事务中过账
+type StockTransferRequest struct {
+	OperatorID string                     `json:"operator_id" binding:"required,max=100"`
+	Note       string                     `json:"note" binding:"max=500"`
+	Lines      []StockTransferLineRequest `json:"lines" binding:"required,min=1,max=200,dive"`
+}
+
+// StockTransferLineRequest 表示移库的一行，启用序列号管理的物料须在 serial_numbers 中给出移动的序列号
+type StockTransferLineRequest struct {
+	MaterialCode  string   `json:"material_code" binding:"required,max=100"`
+	LotNumber     string   `json:"lot_number" binding:"max=100"`
+	FromLocation  string   `json:"from_location" binding:"required,max=100"`
+	ToLocation    string   `json:"to_location" binding:"required,max=100,nefield=FromLocation"`
+	Quantity      int      `json:"quantity" binding:"required,min=1"`
+	SerialNumbers []string `json:"serial_numbers" binding:"omitempty,dive,required,max=100"`
+}
+
+// OutboundOrderCreateRequest 表示创建出库单据的请求负载
+// allocation_strategy 省略时使用系统默认策略；line_no 省略时按顺序编号
+// carrier 与 carrier_cutoff_at 用于按承运商截单时间组织拣货波次
+type OutboundOrderCreateRequest struct {
+	OrderNo            string                     `json:"order_no" binding:"required,max=50"`
+	CustomerCode       string                     `json:"customer_code" binding:"max=100"`
+	ShipTo             string                     `json:"ship_to" binding:"max=500"`
+	RequiredAt         *time.Time                 `json:"required_at"`
+	Carrier            string                     `json:"carrier" binding:"max=50"`
+	CarrierCutoffAt    *time.Time                 `json:"carrier_cutoff_at"`
+	AllocationStrategy string                     `json:"allocation_strategy" binding:"omitempty,oneof=fifo fewest_locations clear_small_bins"`
+	CreatedBy          string                     `json:"created_by" binding:"required,max=100"`
+	Lines              []OutboundOrderLineRequest `json:"lines" binding:"required,min=1,dive"`
+}
+
+// OutboundOrderLineRequest 表示出库单据的物料行
+type OutboundOrderLineRequest struct {
+	LineNo       int    `json:"line_no" binding:"min=0"`
+	MaterialCode string `json:"material_code" binding:"required,max=100"`
+	Quantity     int    `json:"quantity" binding:"required,min=1"`
+}
+
+// OutboundOrderListQuery 表示出库单据列表的查询参数
+type OutboundOrderListQuery struct {
+	Status       string `form:"status" binding:"omitempty,oneof=open partially_allocated allocated picking picked packed shipped cancelled"`
+	CustomerCode string `form:"customer_code"`
+}
+
+// OutboundAllocateRequest 表示分配库存的请求负载
+type OutboundAllocateRequest struct {
+	OperatorID string `json:"operator_id" binding:"required,max=100"`
+}
+
+// OutboundCancelRequest 表示取消出库单据的请求负载
+type OutboundCancelRequest struct {
+	OperatorID string `json:"operator_id" binding:"required,max=100"`
+	Reason     string `json:"reason" binding:"max=500"`
+}
+
+// WavePlanRequest 表示组织拣货波次的请求负载
+// group_by 为 carrier 时按承运商与截单时间分组，为 zone 时按拣货库位所属库区分组；
+// carrier、cutoff_before 与 order_ids 用于筛选参与组波的已分配单据，省略表示不限制；
+// routing 为排列拣货任务的路线策略（s_shape、largest_gap、tsp），省略时使用仓库布局的默认策略
+type WavePlanRequest struct {
+	GroupBy      string     `json:"group_by" binding:"required,oneof=carrier zone"`
+	Carrier      string     `json:"carrier" binding:"max=50"`
+	CutoffBefore *time.Time `json:"cutoff_before"`
+	OrderIDs     []uint     `json:"order_ids" binding:"max=500"`
+	Routing      string     `json:"routing" binding:"omitempty,oneof=s_shape largest_gap tsp"`
+	CreatedBy    string     `json:"created_by" binding:"required,max=100"`
+}
+
+// WaveListQuery 表示拣货波次列表的查询参数
+type WaveListQuery struct {
+	Status  string `form:"status" binding:"omitempty,oneof=released picking completed"`
+	GroupBy string `form:"group_by" binding:"omitempty,oneof=carrier zone"`
+}
+
+// PickTaskListQuery 表示拣货任务列表的查询参数
+type PickTaskListQuery struct {
+	WaveID     uint   `form:"wave_id"`
+	Status     string `form:"status" binding:"omitempty,oneof=open picked short"`
+	Zone       string `form:"zone"`
+	AssignedTo string `form:"assigned_to"`
+}
+
+// PickAssignRequest 表示指派拣货任务的请求负载
+type PickAssignRequest struct {
+	AssignedTo string `json:"assigned_to" binding:"required,max=100"`
+}
+
+// PickConfirmRequest 表示手持终端确认拣货的请求负载
+// picked_quantity 小于任务数量即为短拣，未拣数量的预留会被释放并对库位生成盘点任务；
+// 启用序列号管理的物料须在 serial_numbers 中给出扫描的序列号，个数与 picked_quantity 一致
+type PickConfirmRequest struct {
+	PickedQuantity *int     `json:"picked_quantity" binding:"required,min=0"`
+	OperatorID     string   `json:"operator_id" binding:"required,max=100"`
+	SerialNumbers  []string `json:"serial_numbers" binding:"omitempty,dive,required,max=100"`
+}
+
+// MaterialRequest 表示新增或更新物料的请求负载
+// base_unit 省略时为 EA；尺寸为单件外形长、宽、高（厘米），weight_kg 为单件重量（千克），用于自动装箱；
+// cross_dock 为 true 的物料收货时优先越库满足等待中的出库单据；serial_tracked 为 true 的物料逐件登记序列号；active 省略时视为启用；
+// barcodes 省略时保留原有条码，给出时替换全部条码（空数组表示清空）
+type MaterialRequest struct {
+	Code          string   `json:"code" binding:"required,max=100"`
+	Description   string   `json:"description" binding:"max=200"`
+	BaseUnit      string   `json:"base_unit" binding:"max=20"`
+	Category      string   `json:"category" binding:"max=50"`
+	LengthCm      float64  `json:"length_cm" binding:"min=0"`
+	WidthCm       float64  `json:"width_cm" binding:"min=0"`
+	HeightCm      float64  `json:"height_cm" binding:"min=0"`
+	WeightKg      float64  `json:"weight_kg" binding:"min=0"`
+	CrossDock     bool     `json:"cross_dock"`
+	LotManaged    bool     `json:"lot_managed"`
+	SerialTracked bool     `json:"serial_tracked"`
+	Active        *bool    `json:"active"`
+	Barcodes      []string `json:"barcodes" binding:"omitempty,dive,required,max=100"`
+}
+
+// MaterialListQuery 表示物料列表的查询参数
+type MaterialListQuery struct {
+	Category string `form:"category"`
+	Active   *bool  `form:"active"`
+	Barcode  string `form:"barcode"`
+}
+
+// MaterialImportRequest 表示批量导入物料的请求负载
+type MaterialImportRequest struct {
+	Materials []MaterialRequest `json:"materials" binding:"required,min=1,dive"`
+}
+
+// CartonTypeRequest 表示新增或更新箱型的请求负载
+// 尺寸为箱子内部长、宽、高（厘米）；max_weight_kg 为 0 表示不限制载重；active 省略时视为启用
+type CartonTypeRequest struct {
+	Code         string  `json:"code" binding:"required,max=50"`
+	Description  string  `json:"description" binding:"max=200"`
+	LengthCm     float64 `json:"length_cm" binding:"required,gt=0"`
+	WidthCm      float64 `json:"width_cm" binding:"required,gt=0"`
+	HeightCm     float64 `json:"height_cm" binding:"required,gt=0"`
+	MaxWeightKg  float64 `json:"max_weight_kg" binding:"min=0"`
+	TareWeightKg float64 `json:"tare_weight_kg" binding:"min=0"`
+	Active       *bool   `json:"active"`
+}
+
+// CartonizeRequest 表示自动装箱的请求负载，dry_run 为 true 时只返回推荐的箱型与装箱数量，不创建包裹
+type CartonizeRequest struct {
+	PackStation string `json:"pack_station" binding:"max=50"`
+	PackedBy    string `json:"packed_by" binding:"max=100"`
+	DryRun      bool   `json:"dry_run"`
+}
+
+// PackLineRequest 表示手工装箱时某个单据行的装箱数量
+type PackLineRequest struct {
+	LineID   uint `json:"line_id" binding:"required"`
+	Quantity int  `json:"quantity" binding:"required,gt=0"`
+}
+
+// PackRequest 表示手工装箱的请求负载
+type PackRequest struct {
+	CartonTypeCode string            `json:"carton_type_code" binding:"required,max=50"`
+	PackStation    string            `json:"pack_station" binding:"max=50"`
+	PackedBy       string            `json:"packed_by" binding:"required,max=100"`
+	Lines          []PackLineRequest `json:"lines" binding:"required,min=1,max=200,dive"`
+}
+
+// ShipRequest 表示发运确认的请求负载
+type ShipRequest struct {
+	TrackingNo string `json:"tracking_no" binding:"max=100"`
+	ShippedBy  string `json:"shipped_by" binding:"required,max=100"`
+}
+
+// ReturnOrderCreateRequest 表示创建客户退货授权（RMA）的请求负载
+// shipment_id 为原出库单据的发运记录；每行 line_no 为原出库单据的行号
+type ReturnOrderCreateRequest struct {
+	RMANo      string                   `json:"rma_no" binding:"required,max=50"`
+	ShipmentID uint                     `json:"shipment_id" binding:"required"`
+	Reason     string                   `json:"reason" binding:"max=500"`
+	CreatedBy  string                   `json:"created_by" binding:"required,max=100"`
+	Lines      []ReturnOrderLineRequest `json:"lines" binding:"required,min=1,dive"`
+}
+
+// ReturnOrderLineRequest 表示退货授权的一行
+type ReturnOrderLineRequest struct {
+	LineNo   int `json:"line_no" binding:"required,min=1"`
+	Quantity int `json:"quantity" binding:"required,min=1"`
+}
+
+// ReturnOrderListQuery 表示退货单据列表的查询参数
+type ReturnOrderListQuery struct {
+	Status       string `form:"status" binding:"omitempty,oneof=open receiving completed cancelled"`
+	OrderID      uint   `form:"order_id"`
+	CustomerCode string `form:"customer_code"`
+}
+
+// ReturnReceiveRequest 表示退货收货检验的请求负载
+// 同一 RMA 行可拆成多行给出不同的处置方式；location_code 省略时使用处置方式对应的默认库位
+type ReturnReceiveRequest struct {
+	ReceivedBy string                     `json:"received_by" binding:"required,max=100"`
+	Lines      []ReturnReceiveLineRequest `json:"lines" binding:"required,min=1,dive"`
+}
+
+// ReturnReceiveLineRequest 表示退货收货的一行，启用序列号管理的物料须在 serial_numbers 中逐件给出序列号
+type ReturnReceiveLineRequest struct {
+	LineNo         int      `json:"line_no" binding:"required,min=1"`
+	LotNumber      string   `json:"lot_number" binding:"max=100"`
+	Quantity       int      `json:"quantity" binding:"required,min=1"`
+	SerialNumbers  []string `json:"serial_numbers" binding:"omitempty,dive,required,max=100"`
+	Disposition    string   `json:"disposition" binding:"required,oneof=restock quarantine scrap return_to_vendor"`
+	LocationCode   string   `json:"location_code" binding:"max=100"`
+	InspectionNote string   `json:"inspection_note" binding:"max=500"`
+}
+
+// ReturnCancelRequest 表示取消退货授权的请求负载
+type ReturnCancelRequest struct {
+	OperatorID string `json:"operator_id" binding:"required,max=100"`
+	Reason     string `json:"reason" binding:"max=500"`
+}
+
+// ReplenishmentRuleRequest 表示新增或更新拣货位补货水位的请求负载
+// 拣货位在库数量加上未完成补货数量低于 min_quantity 时补至 max_quantity；active 省略时视为启用
+type ReplenishmentRuleRequest struct {
+	MaterialCode string `json:"material_code" binding:"required,max=100"`
+	LocationCode string `json:"location_code" binding:"required,max=100"`
+	MinQuantity  *int   `json:"min_quantity" binding:"required,min=0"`
+	MaxQuantity  int    `json:"max_quantity" binding:"required,min=1"`
+	Active       *bool  `json:"active"`
+	UpdatedBy    string `json:"updated_by" binding:"max=100"`
+}
+
+// ReplenishmentRunRequest 表示手工触发补货扫描的请求负载，material_code、location_code 省略时扫描全部启用的水位
+type ReplenishmentRunRequest struct {
+	MaterialCode string `json:"material_code" binding:"max=100"`
+	LocationCode string `json:"location_code" binding:"max=100"`
+	CreatedBy    string `json:"created_by" binding:"required,max=100"`
+}
+
+// ReplenishmentTaskListQuery 表示补货任务列表的查询参数
+type ReplenishmentTaskListQuery struct {
+	Status         string `form:"status" binding:"omitempty,oneof=open completed cancelled"`
+	MaterialCode   string `form:"material_code"`
+	TargetLocation string `form:"target_location"`
+	Trigger        string `form:"trigger" binding:"omitempty,oneof=on_demand scheduled pick stock_take"`
+}
+
+// ReplenishmentConfirmRequest 表示确认补货任务的请求负载
+type ReplenishmentConfirmRequest struct {
+	OperatorID string `json:"operator_id" binding:"required,max=100"`
 }
 
+// ReplenishmentCancelRequest 表示取消补货任务的请求负载
+type ReplenishmentCancelRequest struct {
+	OperatorID string `json:"operator_id" binding:"required,max=100"`
+	Reason     string `json:"reason" binding:"max=500"`
+}
+
+// ListResponse 表示游标分页列表的响应数据
+type ListResponse struct {
+	Items      interface{} `json:"items"`
+	Count      int         `json:"count"`
+	HasMore    bool        `json:"has_more"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+// 错误码定义
+const (
+	// ErrCodeInvalidInput 请求参数无效
+	ErrCodeInvalidInput = "INVALID_INPUT"
+	// ErrCodeNotFound 资源不存在
+	ErrCodeNotFound = "NOT_FOUND"
+	// ErrCodeInvalidState 资源当前状态不允许该操作
+	ErrCodeInvalidState = "INVALID_STATE"
+	// ErrCodeInsufficientStock 库存不足或操作会导致库存为负
+	ErrCodeInsufficientStock = "INSUFFICIENT_STOCK"
+	// ErrCodeUnknownMaterial 物料编码未在物料主数据中登记
+	ErrCodeUnknownMaterial = "UNKNOWN_MATERIAL"
+	// ErrCodeInactiveMaterial 物料已停用
+	ErrCodeInactiveMaterial = "INACTIVE_MATERIAL"
+	// ErrCodeUnknownLocation 库位编码未在库位主数据中登记
+	ErrCodeUnknownLocation = "UNKNOWN_LOCATION"
+	// ErrCodeInactiveLocation 库位已停用
+	ErrCodeInactiveLocation = "INACTIVE_LOCATION"
+	// ErrCodeLocationBlocked 库位已冻结
+	ErrCodeLocationBlocked = "LOCATION_BLOCKED"
+	// ErrCodeLotRequired 物料启用了批次管理，未给出批次号
+	ErrCodeLotRequired = "LOT_REQUIRED"
+	// ErrCodeStockExpired 库存已过有效期
+	ErrCodeStockExpired = "STOCK_EXPIRED"
+	// ErrCodeSerialRequired 物料启用了序列号管理，未给出序列号
+	ErrCodeSerialRequired = "SERIAL_REQUIRED"
+	// ErrCodeSerialMismatch 序列号与系统登记不符
+	ErrCodeSerialMismatch = "SERIAL_MISMATCH"
+	// ErrCodeInternal 服务器内部错误
+	ErrCodeInternal = "INTERNAL_ERROR"
+	// ErrCodeBatchAborted 整批事务已回滚，本行未生效
+	ErrCodeBatchAborted = "BATCH_ABORTED"
+)
+
 // CommonResponse 表示标准的 API 响应结构
 type CommonResponse struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
+	Code      int         `json:"code"`
+	Message   string      `json:"message"`
+	ErrorCode string      `json:"error_code,omitempty"`
+	Data      interface{} `json:"data,omitempty"`
 }
 
 // SuccessResponse 创建成功响应
@@ -22,6 +705,15 @@ func SuccessResponse() CommonResponse {
 	}
 }
 
+// SuccessResponseWithData 创建携带数据的成功响应
+func SuccessResponseWithData(data interface{}) CommonResponse {
+	return CommonResponse{
+		Code:    0,
+		Message: "success",
+		Data:    data,
+	}
+}
+
 // ErrorResponse 创建带自定义消息的错误响应
 func ErrorResponse(message string) CommonResponse {
 	return CommonResponse{
@@ -29,3 +721,12 @@ func ErrorResponse(message string) CommonResponse {
 		Message: message,
 	}
 }
+
+// ErrorResponseWithCode 创建带错误码与自定义消息的错误响应
+func ErrorResponseWithCode(errorCode, message string) CommonResponse {
+	return CommonResponse{
+		Code:      -1,
+		Message:   message,
+		ErrorCode: errorCode,
+	}
+}
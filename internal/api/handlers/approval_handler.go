@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"net/http"
+	"wms/internal/api/dto"
+	"wms/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ListPendingApprovals 查询主管待审批的盘点差异队列
+// @Summary 查询待审批盘点差异
+// @Description 返回超出容差、尚未审批的盘点记录，按盘点时间升序
+// @Tags inventory
+// @Produce json
+// @Success 200 {object} dto.CommonResponse{data=dto.ListResponse}
+// @Failure 500 {object} dto.CommonResponse "服务器内部错误"
+// @Router /api/wms/inventory/check/approvals [get]
+func (h *InventoryHandler) ListPendingApprovals(c *gin.Context) {
+	records, err := h.service.ListPendingApprovals()
+	if err != nil {
+		respondError(c, "Failed to list pending approvals", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(dto.ListResponse{
+		Items: records,
+		Count: len(records),
+	}))
+}
+
+// ApproveVariance 批准盘点差异并过账至库存
+// @Summary 批准盘点差异
+// @Description 将待审批盘点的差异增量过账到当前库存，并记录审批人与审批时间；记录上未完成的复盘任务将被取消
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Param id path int true "盘点记录ID"
+// @Param request body dto.VarianceApprovalRequest true "审批信息"
+// @Success 200 {object} dto.CommonResponse{data=model.InventoryCheckRecord}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 404 {object} dto.CommonResponse "记录不存在"
+// @Failure 409 {object} dto.CommonResponse "记录不处于待审批状态或库存不足"
+// @Router /api/wms/inventory/check/records/{id}/approve [post]
+func (h *InventoryHandler) ApproveVariance(c *gin.Context) {
+	h.decideVariance(c, true)
+}
+
+// RejectVariance 驳回盘点差异
+// @Summary 驳回盘点差异
+// @Description 驳回待审批盘点，库存保持不变，并记录审批人与审批时间；记录上未完成的复盘任务将被取消
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Param id path int true "盘点记录ID"
+// @Param request body dto.VarianceApprovalRequest true "审批信息"
+// @Success 200 {object} dto.CommonResponse{data=model.InventoryCheckRecord}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 404 {object} dto.CommonResponse "记录不存在"
+// @Failure 409 {object} dto.CommonResponse "记录不处于待审批状态"
+// @Router /api/wms/inventory/check/records/{id}/reject [post]
+func (h *InventoryHandler) RejectVariance(c *gin.Context) {
+	h.decideVariance(c, false)
+}
+
+// decideVariance 处理审批与驳回请求的公共流程
+func (h *InventoryHandler) decideVariance(c *gin.Context, approve bool) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.VarianceApprovalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	input := service.ApprovalInput{
+		ApproverID: req.ApproverID,
+		Comment:    req.Comment,
+	}
+
+	action := "reject"
+	decide := h.service.RejectVariance
+	if approve {
+		action = "approve"
+		decide = h.service.ApproveVariance
+	}
+
+	record, err := decide(id, input)
+	if err != nil {
+		h.logger.Warn("Failed to decide inventory variance",
+			zap.Uint("record_id", id),
+			zap.String("action", action),
+			zap.String("approver_id", req.ApproverID),
+			zap.Error(err),
+		)
+		respondError(c, "Failed to "+action+" variance", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(record))
+}
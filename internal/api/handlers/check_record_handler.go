@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"wms/internal/api/dto"
+	"wms/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ListCheckRecords 查询盘点历史记录
+// @Summary 查询盘点记录
+// @Description 按物料、库位、盘点人、时间区间与处理状态过滤盘点记录，支持游标分页与排序
+// @Tags inventory
+// @Produce json
+// @Param material_code query string false "物料代码"
+// @Param location_code query string false "库位代码"
+// @Param checker_id query string false "盘点人员ID"
+// @Param from query string false "起始时间（RFC3339，含）"
+// @Param to query string false "截止时间（RFC3339，不含）"
+// @Param is_processed query bool false "是否已处理"
+// @Param approval_status query string false "审批状态：auto_approved、pending、approved、rejected、superseded"
+// @Param stock_take_id query int false "盘点单ID"
+// @Param sort_by query string false "排序字段：check_time（默认）、id、difference"
+// @Param order query string false "排序方向：desc（默认）或 asc"
+// @Param cursor query string false "上一页返回的 next_cursor"
+// @Param limit query int false "每页数量，默认 50，最大 200"
+// @Success 200 {object} dto.CommonResponse{data=dto.ListResponse}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 500 {object} dto.CommonResponse "服务器内部错误"
+// @Router /api/wms/inventory/check/records [get]
+func (h *InventoryHandler) ListCheckRecords(c *gin.Context) {
+	var req dto.CheckRecordListQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+	h.listCheckRecords(c, req, true)
+}
+
+// ListUnprocessedRecords 查询未处理的盘点记录
+// @Summary 查询未处理盘点记录
+// @Description 返回 is_processed=false 的盘点记录，默认按盘点时间升序（先到先处理）
+// @Tags inventory
+// @Produce json
+// @Param material_code query string false "物料代码"
+// @Param location_code query string false "库位代码"
+// @Param checker_id query string false "盘点人员ID"
+// @Param stock_take_id query int false "盘点单ID"
+// @Param cursor query string false "上一页返回的 next_cursor"
+// @Param limit query int false "每页数量，默认 50，最大 200"
+// @Success 200 {object} dto.CommonResponse{data=dto.ListResponse}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 500 {object} dto.CommonResponse "服务器内部错误"
+// @Router /api/wms/inventory/check/records/unprocessed [get]
+func (h *InventoryHandler) ListUnprocessedRecords(c *gin.Context) {
+	var req dto.CheckRecordListQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+	unprocessed := false
+	req.IsProcessed = &unprocessed
+	h.listCheckRecords(c, req, false)
+}
+
+// GetCheckRecord 查询单条盘点记录
+// @Summary 查询盘点记录详情
+// @Tags inventory
+// @Produce json
+// @Param id path int true "盘点记录ID"
+// @Success 200 {object} dto.CommonResponse{data=model.InventoryCheckRecord}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 404 {object} dto.CommonResponse "记录不存在"
+// @Router /api/wms/inventory/check/records/{id} [get]
+func (h *InventoryHandler) GetCheckRecord(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	record, err := h.service.GetCheckRecord(id)
+	if err != nil {
+		respondError(c, "Failed to get check record", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(record))
+}
+
+// listCheckRecords 执行盘点记录列表查询并输出响应
+// defaultDesc 指定未传入 order 参数时是否倒序
+func (h *InventoryHandler) listCheckRecords(c *gin.Context, req dto.CheckRecordListQuery, defaultDesc bool) {
+	sortDesc := defaultDesc
+	if req.Order != "" {
+		sortDesc = req.Order == "desc"
+	}
+
+	page, err := h.service.ListCheckRecords(service.CheckRecordQuery{
+		MaterialCode:   req.MaterialCode,
+		LocationCode:   req.LocationCode,
+		CheckerID:      req.CheckerID,
+		From:           req.From,
+		To:             req.To,
+		IsProcessed:    req.IsProcessed,
+		ApprovalStatus: req.ApprovalStatus,
+		StockTakeID:    req.StockTakeID,
+		SortBy:         req.SortBy,
+		SortDesc:       sortDesc,
+		Cursor:         req.Cursor,
+		Limit:          req.Limit,
+	})
+	if err != nil {
+		h.logger.Warn("Failed to list inventory check records",
+			zap.String("material_code", req.MaterialCode),
+			zap.String("location_code", req.LocationCode),
+			zap.Error(err),
+		)
+		respondError(c, "Failed to list check records", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(dto.ListResponse{
+		Items:      page.Records,
+		Count:      len(page.Records),
+		HasMore:    page.HasMore,
+		NextCursor: page.NextCursor,
+	}))
+}
+
+// GetVarianceReport 查询盘点差异报表
+// @Summary 盘点差异报表
+// @Description 按原因代码、过账类别、物料、库位汇总时间窗口内已生效（自动批准或审批通过）的盘点差异；
+// @Description 未给出原因的差异以空 reason_code 单独成组
+// @Tags inventory
+// @Produce json
+// @Param from query string false "起始时间（RFC3339，含），默认截止时间前 30 天"
+// @Param to query string false "截止时间（RFC3339，不含），默认当前时间"
+// @Param group_by query string false "逗号分隔的分组字段：reason_code（默认）、posting_category、material_code、location_code"
+// @Param material_code query string false "物料代码"
+// @Param location_code query string false "库位代码"
+// @Param reason_code query string false "原因代码"
+// @Success 200 {object} dto.CommonResponse{data=service.VarianceReport}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 500 {object} dto.CommonResponse "服务器内部错误"
+// @Router /api/wms/inventory/reports/variance [get]
+func (h *InventoryHandler) GetVarianceReport(c *gin.Context) {
+	var req dto.VarianceReportQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	var groupBy []string
+	if req.GroupBy != "" {
+		groupBy = strings.Split(req.GroupBy, ",")
+	}
+	report, err := h.service.VarianceReport(service.VarianceReportQuery{
+		From:         req.From,
+		To:           req.To,
+		MaterialCode: req.MaterialCode,
+		LocationCode: req.LocationCode,
+		ReasonCode:   req.ReasonCode,
+		GroupBy:      groupBy,
+	})
+	if err != nil {
+		respondError(c, "Failed to build variance report", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(report))
+}
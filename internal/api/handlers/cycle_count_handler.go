@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"net/http"
+	"wms/internal/api/dto"
+	"wms/internal/repository"
+	"wms/internal/service"
+	"wms/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CycleCountHandler 负责处理循环盘点相关的 HTTP 请求
+type CycleCountHandler struct {
+	service service.CycleCountService
+	logger  *logger.Logger
+}
+
+// NewCycleCountHandler 创建一个新的 CycleCountHandler 实例
+func NewCycleCountHandler(service service.CycleCountService, log *logger.Logger) *CycleCountHandler {
+	return &CycleCountHandler{
+		service: service,
+		logger:  log,
+	}
+}
+
+// GetPolicy 查询循环盘点配置
+// @Summary 查询循环盘点配置
+// @Description 返回 ABC 分类依据、分类占比与各类盘点频率，尚未配置时返回默认值
+// @Tags cyclecount
+// @Produce json
+// @Success 200 {object} dto.CommonResponse{data=model.CycleCountPolicy}
+// @Failure 500 {object} dto.CommonResponse "服务器内部错误"
+// @Router /api/wms/inventory/cyclecount/policy [get]
+func (h *CycleCountHandler) GetPolicy(c *gin.Context) {
+	policy, err := h.service.GetPolicy()
+	if err != nil {
+		respondError(c, "Failed to get cycle count policy", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(policy))
+}
+
+// UpdatePolicy 更新循环盘点配置
+// @Summary 更新循环盘点配置
+// @Tags cyclecount
+// @Accept json
+// @Produce json
+// @Param request body dto.CycleCountPolicyRequest true "循环盘点配置"
+// @Success 200 {object} dto.CommonResponse{data=model.CycleCountPolicy}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Router /api/wms/inventory/cyclecount/policy [put]
+func (h *CycleCountHandler) UpdatePolicy(c *gin.Context) {
+	var req dto.CycleCountPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	policy, err := h.service.UpdatePolicy(service.CycleCountPolicyInput{
+		Basis:          req.Basis,
+		LookbackDays:   req.LookbackDays,
+		AClassShare:    req.AClassShare,
+		BClassShare:    req.BClassShare,
+		AFrequencyDays: req.AFrequencyDays,
+		BFrequencyDays: req.BFrequencyDays,
+		CFrequencyDays: req.CFrequencyDays,
+		MaxTasksPerDay: req.MaxTasksPerDay,
+		UpdatedBy:      req.UpdatedBy,
+	})
+	if err != nil {
+		respondError(c, "Failed to update cycle count policy", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(policy))
+}
+
+// ListValuations 查询物料单位价值
+// @Summary 查询物料单位价值
+// @Description 按价值进行 ABC 分类时使用的物料单位价值
+// @Tags cyclecount
+// @Produce json
+// @Success 200 {object} dto.CommonResponse{data=dto.ListResponse}
+// @Failure 500 {object} dto.CommonResponse "服务器内部错误"
+// @Router /api/wms/inventory/cyclecount/valuations [get]
+func (h *CycleCountHandler) ListValuations(c *gin.Context) {
+	valuations, err := h.service.ListValuations()
+	if err != nil {
+		respondError(c, "Failed to list material valuations", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(dto.ListResponse{
+		Items: valuations,
+		Count: len(valuations),
+	}))
+}
+
+// UpsertValuation 保存物料单位价值
+// @Summary 保存物料单位价值
+// @Tags cyclecount
+// @Accept json
+// @Produce json
+// @Param request body dto.MaterialValuationRequest true "物料单位价值"
+// @Success 200 {object} dto.CommonResponse{data=model.MaterialValuation}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Router /api/wms/inventory/cyclecount/valuations [put]
+func (h *CycleCountHandler) UpsertValuation(c *gin.Context) {
+	var req dto.MaterialValuationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	valuation, err := h.service.UpsertValuation(req.MaterialCode, *req.UnitCost)
+	if err != nil {
+		respondError(c, "Failed to save material valuation", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(valuation))
+}
+
+// PreviewCycleCount 预览循环盘点计划
+// @Summary 预览循环盘点计划
+// @Description 返回指定日期到期的物料与库位及 ABC 分类结果，不生成任务
+// @Tags cyclecount
+// @Produce json
+// @Param date query string false "日期（YYYY-MM-DD），默认当天"
+// @Success 200 {object} dto.CommonResponse{data=service.CycleCountPlan}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 500 {object} dto.CommonResponse "服务器内部错误"
+// @Router /api/wms/inventory/cyclecount/preview [get]
+func (h *CycleCountHandler) PreviewCycleCount(c *gin.Context) {
+	var req dto.CycleCountDateQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+	date, ok := parseDateValue(c, "date", req.Date)
+	if !ok {
+		return
+	}
+
+	plan, err := h.service.PreviewCycleCount(date)
+	if err != nil {
+		respondError(c, "Failed to preview cycle count", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(plan))
+}
+
+// RunCycleCount 立即生成循环盘点任务
+// @Summary 生成循环盘点任务
+// @Description 按指定日期的计划生成循环盘点任务，已有未完成任务的物料与库位不会重复生成
+// @Tags cyclecount
+// @Accept json
+// @Produce json
+// @Param request body dto.CycleCountDateQuery false "日期（YYYY-MM-DD），默认当天"
+// @Success 200 {object} dto.CommonResponse{data=service.CycleCountPlan}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 500 {object} dto.CommonResponse "服务器内部错误"
+// @Router /api/wms/inventory/cyclecount/run [post]
+func (h *CycleCountHandler) RunCycleCount(c *gin.Context) {
+	var req dto.CycleCountDateQuery
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondBadRequest(c, err)
+			return
+		}
+	}
+	date, ok := parseDateValue(c, "date", req.Date)
+	if !ok {
+		return
+	}
+
+	plan, err := h.service.GenerateCycleCount(date)
+	if err != nil {
+		respondError(c, "Failed to generate cycle count", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(plan))
+}
+
+// ListCycleCountTasks 查询循环盘点任务
+// @Summary 查询循环盘点任务
+// @Description 任意针对该物料与库位的盘点上传都会完成对应任务
+// @Tags cyclecount
+// @Produce json
+// @Param date query string false "计划日期（YYYY-MM-DD）"
+// @Param status query string false "状态：open、completed"
+// @Param abc_class query string false "ABC 分类：A、B、C"
+// @Param source query string false "来源：scheduled 定时生成、short_pick 拣货短拣"
+// @Success 200 {object} dto.CommonResponse{data=dto.ListResponse}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 500 {object} dto.CommonResponse "服务器内部错误"
+// @Router /api/wms/inventory/cyclecount/tasks [get]
+func (h *CycleCountHandler) ListCycleCountTasks(c *gin.Context) {
+	var req dto.CycleCountTaskListQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	filter := repository.CycleCountTaskFilter{
+		Status:   req.Status,
+		ABCClass: req.ABCClass,
+		Source:   req.Source,
+	}
+	if req.Date != "" {
+		date, ok := parseDateValue(c, "date", req.Date)
+		if !ok {
+			return
+		}
+		filter.ScheduledDate = &date
+	}
+
+	tasks, err := h.service.ListCycleCountTasks(filter)
+	if err != nil {
+		respondError(c, "Failed to list cycle count tasks", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(dto.ListResponse{
+		Items: tasks,
+		Count: len(tasks),
+	}))
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"wms/internal/api/dto"
+	"wms/internal/service"
+	"wms/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mockCycleCountService 是用于测试的循环盘点服务模拟实现
+type mockCycleCountService struct {
+	service.CycleCountService
+	generateFunc func(date time.Time) (*service.CycleCountPlan, error)
+}
+
+func (m *mockCycleCountService) GenerateCycleCount(date time.Time) (*service.CycleCountPlan, error) {
+	if m.generateFunc != nil {
+		return m.generateFunc(date)
+	}
+	return &service.CycleCountPlan{Date: date.Format("2006-01-02")}, nil
+}
+
+func setupCycleCountRouter(handler *CycleCountHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/wms/inventory/cyclecount/preview", handler.PreviewCycleCount)
+	router.POST("/api/wms/inventory/cyclecount/run", handler.RunCycleCount)
+	return router
+}
+
+func TestPreviewCycleCount_InvalidDate(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	router := setupCycleCountRouter(NewCycleCountHandler(&mockCycleCountService{}, log))
+
+	req, _ := http.NewRequest("GET", "/api/wms/inventory/cyclecount/preview?date=2026/03/01", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d", w.Code)
+	}
+	var response dto.CommonResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if response.ErrorCode != dto.ErrCodeInvalidInput {
+		t.Errorf("Expected error code %s, got %s", dto.ErrCodeInvalidInput, response.ErrorCode)
+	}
+}
+
+func TestRunCycleCount_UsesRequestedDate(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	var received time.Time
+	router := setupCycleCountRouter(NewCycleCountHandler(&mockCycleCountService{
+		generateFunc: func(date time.Time) (*service.CycleCountPlan, error) {
+			received = date
+			return &service.CycleCountPlan{Date: date.Format("2006-01-02"), Created: 2}, nil
+		},
+	}, log))
+
+	body, _ := json.Marshal(map[string]string{"date": "2026-03-01"})
+	req, _ := http.NewRequest("POST", "/api/wms/inventory/cyclecount/run", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if received.Format("2006-01-02") != "2026-03-01" {
+		t.Errorf("Expected generation for 2026-03-01, got %s", received)
+	}
+}
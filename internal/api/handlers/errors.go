@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+	"wms/internal/api/dto"
+	"wms/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errorMapping 描述业务错误到 HTTP 状态码与错误码的映射
+type errorMapping struct {
+	err    error
+	status int
+	code   string
+}
+
+// errorMappings 按顺序匹配，首个命中的映射生效
+var errorMappings = []errorMapping{
+	{service.ErrInvalidInput, http.StatusBadRequest, dto.ErrCodeInvalidInput},
+	{service.ErrNotFound, http.StatusNotFound, dto.ErrCodeNotFound},
+	{service.ErrInvalidState, http.StatusConflict, dto.ErrCodeInvalidState},
+	{service.ErrInsufficientStock, http.StatusConflict, dto.ErrCodeInsufficientStock},
+	{service.ErrUnknownMaterial, http.StatusUnprocessableEntity, dto.ErrCodeUnknownMaterial},
+	{service.ErrInactiveMaterial, http.StatusUnprocessableEntity, dto.ErrCodeInactiveMaterial},
+	{service.ErrUnknownLocation, http.StatusUnprocessableEntity, dto.ErrCodeUnknownLocation},
+	{service.ErrInactiveLocation, http.StatusUnprocessableEntity, dto.ErrCodeInactiveLocation},
+	{service.ErrLocationBlocked, http.StatusConflict, dto.ErrCodeLocationBlocked},
+	{service.ErrLotRequired, http.StatusUnprocessableEntity, dto.ErrCodeLotRequired},
+	{service.ErrStockExpired, http.StatusConflict, dto.ErrCodeStockExpired},
+	{service.ErrSerialRequired, http.StatusUnprocessableEntity, dto.ErrCodeSerialRequired},
+	{service.ErrSerialMismatch, http.StatusConflict, dto.ErrCodeSerialMismatch},
+}
+
+// classifyError 返回业务错误对应的 HTTP 状态码与错误码
+// 未识别的错误统一视为服务器内部错误
+func classifyError(err error) (int, string) {
+	for _, m := range errorMappings {
+		if errors.Is(err, m.err) {
+			return m.status, m.code
+		}
+	}
+	return http.StatusInternalServerError, dto.ErrCodeInternal
+}
+
+// respondError 根据业务错误输出对应状态码与错误码的响应
+// message 作为错误信息前缀，便于客户端区分失败的操作
+func respondError(c *gin.Context, message string, err error) {
+	status, code := classifyError(err)
+	c.JSON(status, dto.ErrorResponseWithCode(code, message+": "+err.Error()))
+}
+
+// respondBadRequest 输出请求参数无效的响应
+func respondBadRequest(c *gin.Context, err error) {
+	c.JSON(http.StatusBadRequest, dto.ErrorResponseWithCode(dto.ErrCodeInvalidInput, "Invalid request: "+err.Error()))
+}
+
+// parseIDParam 解析路径中的正整数 ID 参数，解析失败时直接输出 400 响应并返回 false
+func parseIDParam(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponseWithCode(dto.ErrCodeInvalidInput, "Invalid request: "+name+" must be a positive integer"))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// parseDateValue 解析 YYYY-MM-DD 格式的日期参数，为空时返回当天，解析失败时直接输出 400 响应并返回 false
+func parseDateValue(c *gin.Context, name, value string) (time.Time, bool) {
+	if value == "" {
+		return time.Now(), true
+	}
+	date, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponseWithCode(dto.ErrCodeInvalidInput, "Invalid request: "+name+" must be in YYYY-MM-DD format"))
+		return time.Time{}, false
+	}
+	return date, true
+}
@@ -0,0 +1,267 @@
+package handlers
+
+import (
+	"net/http"
+	"wms/internal/api/dto"
+	"wms/internal/repository"
+	"wms/internal/service"
+	"wms/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InboundHandler 负责处理入库单据与收货相关的 HTTP 请求
+type InboundHandler struct {
+	service service.InboundService
+	logger  *logger.Logger
+}
+
+// NewInboundHandler 创建一个新的 InboundHandler 实例
+func NewInboundHandler(service service.InboundService, log *logger.Logger) *InboundHandler {
+	return &InboundHandler{
+		service: service,
+		logger:  log,
+	}
+}
+
+// CreateOrder 创建入库单据
+// @Summary 创建入库单据
+// @Description 创建采购订单（po）或预到货通知（asn）及其预期物料行
+// @Tags inbound
+// @Accept json
+// @Produce json
+// @Param request body dto.InboundOrderCreateRequest true "入库单据"
+// @Success 200 {object} dto.CommonResponse{data=model.InboundOrder}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效或单据号重复"
+// @Failure 500 {object} dto.CommonResponse "服务器内部错误"
+// @Router /api/wms/inbound/orders [post]
+func (h *InboundHandler) CreateOrder(c *gin.Context) {
+	var req dto.InboundOrderCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	lines := make([]service.InboundLineInput, 0, len(req.Lines))
+	for _, line := range req.Lines {
+		lines = append(lines, service.InboundLineInput{
+			LineNo:           line.LineNo,
+			MaterialCode:     line.MaterialCode,
+			ExpectedQuantity: line.ExpectedQuantity,
+		})
+	}
+	order, err := h.service.CreateOrder(service.InboundOrderInput{
+		OrderNo:            req.OrderNo,
+		OrderType:          req.OrderType,
+		SupplierCode:       req.SupplierCode,
+		PurchaseOrderNo:    req.PurchaseOrderNo,
+		ExpectedAt:         req.ExpectedAt,
+		ReceivingLocation:  req.ReceivingLocation,
+		OverReceiptPercent: req.OverReceiptPercent,
+		CreatedBy:          req.CreatedBy,
+		Lines:              lines,
+	})
+	if err != nil {
+		respondError(c, "Failed to create inbound order", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(order))
+}
+
+// ListOrders 查询入库单据列表
+// @Summary 查询入库单据列表
+// @Tags inbound
+// @Produce json
+// @Param status query string false "状态：open、receiving、closed、cancelled"
+// @Param order_type query string false "单据类型：po、asn"
+// @Param supplier_code query string false "供应商代码"
+// @Success 200 {object} dto.CommonResponse{data=dto.ListResponse}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 500 {object} dto.CommonResponse "服务器内部错误"
+// @Router /api/wms/inbound/orders [get]
+func (h *InboundHandler) ListOrders(c *gin.Context) {
+	var req dto.InboundOrderListQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	orders, err := h.service.ListOrders(repository.InboundOrderFilter{
+		Status:       req.Status,
+		OrderType:    req.OrderType,
+		SupplierCode: req.SupplierCode,
+	})
+	if err != nil {
+		respondError(c, "Failed to list inbound orders", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(dto.ListResponse{
+		Items: orders,
+		Count: len(orders),
+	}))
+}
+
+// GetOrder 查询入库单据详情
+// @Summary 查询入库单据详情
+// @Tags inbound
+// @Produce json
+// @Param id path int true "入库单据ID"
+// @Success 200 {object} dto.CommonResponse{data=model.InboundOrder}
+// @Failure 404 {object} dto.CommonResponse "单据不存在"
+// @Router /api/wms/inbound/orders/{id} [get]
+func (h *InboundHandler) GetOrder(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	order, err := h.service.GetOrder(id)
+	if err != nil {
+		respondError(c, "Failed to get inbound order", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(order))
+}
+
+// ReceiveOrder 收货
+// @Summary 收货
+// @Description 按单据行收货并在同一事务中将数量过账到收货库位的库存，同时为每条收货记录生成上架任务；标记为越库的物料先移至越库库位并分配给等待中的出库单据，只为剩余数量生成上架任务；超出允许的超收数量或物料不在单据中返回 400，单据已关闭或取消返回 409
+// @Tags inbound
+// @Accept json
+// @Produce json
+// @Param id path int true "入库单据ID"
+// @Param request body dto.InboundReceiveRequest true "收货信息"
+// @Success 200 {object} dto.CommonResponse{data=service.ReceiveResult}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 404 {object} dto.CommonResponse "单据不存在"
+// @Failure 409 {object} dto.CommonResponse "单据状态不允许收货"
+// @Router /api/wms/inbound/orders/{id}/receive [post]
+func (h *InboundHandler) ReceiveOrder(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req dto.InboundReceiveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	lines := make([]service.ReceiveLineInput, 0, len(req.Lines))
+	for _, line := range req.Lines {
+		lines = append(lines, service.ReceiveLineInput{
+			LineNo:          line.LineNo,
+			MaterialCode:    line.MaterialCode,
+			LotNumber:       line.LotNumber,
+			ManufactureDate: line.ManufactureDate,
+			ExpiryDate:      line.ExpiryDate,
+			SerialNumbers:   line.SerialNumbers,
+			Quantity:        line.Quantity,
+		})
+	}
+	result, err := h.service.ReceiveOrder(id, service.ReceiveInput{
+		ReceivedBy:   req.ReceivedBy,
+		LocationCode: req.LocationCode,
+		Note:         req.Note,
+		Lines:        lines,
+	})
+	if err != nil {
+		respondError(c, "Failed to receive inbound order", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(result))
+}
+
+// CloseOrder 关闭入库单据
+// @Summary 关闭入库单据
+// @Description 关闭后不再接受收货；存在未收足的行时必须填写 reason，这些行标记为 short
+// @Tags inbound
+// @Accept json
+// @Produce json
+// @Param id path int true "入库单据ID"
+// @Param request body dto.InboundOrderActionRequest true "操作人与原因"
+// @Success 200 {object} dto.CommonResponse{data=model.InboundOrder}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 409 {object} dto.CommonResponse "单据状态不允许关闭"
+// @Router /api/wms/inbound/orders/{id}/close [post]
+func (h *InboundHandler) CloseOrder(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req dto.InboundOrderActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	order, err := h.service.CloseOrder(id, req.OperatorID, req.Reason)
+	if err != nil {
+		respondError(c, "Failed to close inbound order", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(order))
+}
+
+// CancelOrder 取消入库单据
+// @Summary 取消入库单据
+// @Description 只能取消尚未收货的单据，已收货的单据应关闭
+// @Tags inbound
+// @Accept json
+// @Produce json
+// @Param id path int true "入库单据ID"
+// @Param request body dto.InboundOrderActionRequest true "操作人与原因"
+// @Success 200 {object} dto.CommonResponse{data=model.InboundOrder}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 409 {object} dto.CommonResponse "单据已收货或已结束"
+// @Router /api/wms/inbound/orders/{id}/cancel [post]
+func (h *InboundHandler) CancelOrder(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req dto.InboundOrderActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	order, err := h.service.CancelOrder(id, req.OperatorID, req.Reason)
+	if err != nil {
+		respondError(c, "Failed to cancel inbound order", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(order))
+}
+
+// ListReceipts 查询入库单据的收货记录
+// @Summary 查询收货记录
+// @Tags inbound
+// @Produce json
+// @Param id path int true "入库单据ID"
+// @Success 200 {object} dto.CommonResponse{data=dto.ListResponse}
+// @Failure 404 {object} dto.CommonResponse "单据不存在"
+// @Router /api/wms/inbound/orders/{id}/receipts [get]
+func (h *InboundHandler) ListReceipts(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	receipts, err := h.service.ListReceipts(id)
+	if err != nil {
+		respondError(c, "Failed to list receipts", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(dto.ListResponse{
+		Items: receipts,
+		Count: len(receipts),
+	}))
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wms/internal/api/dto"
+	"wms/internal/model"
+	"wms/internal/service"
+	"wms/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mockInboundService 是用于测试的入库服务模拟实现
+type mockInboundService struct {
+	service.InboundService
+	receiveFunc func(id uint, input service.ReceiveInput) (*service.ReceiveResult, error)
+}
+
+func (m *mockInboundService) ReceiveOrder(id uint, input service.ReceiveInput) (*service.ReceiveResult, error) {
+	if m.receiveFunc != nil {
+		return m.receiveFunc(id, input)
+	}
+	return &service.ReceiveResult{Order: &model.InboundOrder{ID: id}}, nil
+}
+
+func setupInboundRouter(handler *InboundHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/wms/inbound/orders/:id/receive", handler.ReceiveOrder)
+	return router
+}
+
+func TestReceiveOrder_PassesLines(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	var receivedID uint
+	var received service.ReceiveInput
+	router := setupInboundRouter(NewInboundHandler(&mockInboundService{
+		receiveFunc: func(id uint, input service.ReceiveInput) (*service.ReceiveResult, error) {
+			receivedID, received = id, input
+			return &service.ReceiveResult{Order: &model.InboundOrder{ID: id}}, nil
+		},
+	}, log))
+
+	jsonBody, _ := json.Marshal(map[string]interface{}{
+		"received_by": "RECV01",
+		"lines": []map[string]interface{}{
+			{"line_no": 2, "quantity": 5},
+			{"material_code": "MAT001", "quantity": 10},
+		},
+	})
+	req, _ := http.NewRequest("POST", "/api/wms/inbound/orders/7/receive", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if receivedID != 7 || received.ReceivedBy != "RECV01" || len(received.Lines) != 2 {
+		t.Fatalf("Unexpected input passed to service: id=%d %+v", receivedID, received)
+	}
+	if received.Lines[0].LineNo != 2 || received.Lines[1].MaterialCode != "MAT001" || received.Lines[1].Quantity != 10 {
+		t.Errorf("Unexpected receive lines: %+v", received.Lines)
+	}
+}
+
+func TestReceiveOrder_ClosedOrderConflict(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	router := setupInboundRouter(NewInboundHandler(&mockInboundService{
+		receiveFunc: func(id uint, input service.ReceiveInput) (*service.ReceiveResult, error) {
+			return nil, fmt.Errorf("%w: inbound order %d is closed", service.ErrInvalidState, id)
+		},
+	}, log))
+
+	jsonBody, _ := json.Marshal(map[string]interface{}{
+		"received_by": "RECV01",
+		"lines":       []map[string]interface{}{{"line_no": 1, "quantity": 1}},
+	})
+	req, _ := http.NewRequest("POST", "/api/wms/inbound/orders/7/receive", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("Expected status 409, got %d", w.Code)
+	}
+	var response dto.CommonResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if response.ErrorCode != dto.ErrCodeInvalidState {
+		t.Errorf("Expected error code %s, got %s", dto.ErrCodeInvalidState, response.ErrorCode)
+	}
+}
@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"wms/internal/api/dto"
@@ -9,9 +11,13 @@ import (
 	"wms/pkg/logger"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"go.uber.org/zap"
 )
 
+// maxBatchCheckItems 限制单次批量盘点允许提交的最大行数
+const maxBatchCheckItems = 1000
+
 // InventoryHandler 负责处理与库存相关的 HTTP 请求
 type InventoryHandler struct {
 	service service.InventoryService
@@ -28,7 +34,7 @@ func NewInventoryHandler(service service.InventoryService, log *logger.Logger) *
 
 // UploadCheck 处理库存盘点上传接口
 // @Summary 上传库存盘点记录
-// @Description 处理单次库存盘点操作
+// @Description 处理单次库存盘点操作；指定 stock_take_id 时盘点计入该盘点单，待盘点单过账时再调整库存；指定 recount_task_id 时为复盘，须由复盘链以外的盘点人提交
 // @Tags inventory
 // @Accept json
 // @Produce json
@@ -71,21 +77,31 @@ func (h *InventoryHandler) UploadCheck(c *gin.Context) {
 
 	// 将 DTO 转换为服务层输入
 	serviceInput := service.InventoryCheckInput{
-		CheckerID:      req.CheckerID,
-		LocationCode:   req.LocationCode,
-		MaterialCode:   req.MaterialCode,
-		ActualQuantity: req.ActualQuantity,
+		CheckerID:       req.CheckerID,
+		LocationCode:    req.LocationCode,
+		MaterialCode:    req.MaterialCode,
+		LotNumber:       req.LotNumber,
+		ManufactureDate: req.ManufactureDate,
+		ExpiryDate:      req.ExpiryDate,
+		SerialNumbers:   req.SerialNumbers,
+		ActualQuantity:  req.ActualQuantity,
+		StockTakeID:     req.StockTakeID,
+		RecountTaskID:   req.RecountTaskID,
+		ReasonCode:      req.ReasonCode,
+		Note:            req.Note,
 	}
 
 	// 处理库存盘点
-	if err := h.service.ProcessInventoryCheck(serviceInput); err != nil {
+	record, err := h.service.ProcessInventoryCheck(serviceInput)
+	if err != nil {
 		h.logger.Error("Failed to process inventory check",
 			zap.String("checker_id", req.CheckerID),
 			zap.String("location_code", req.LocationCode),
 			zap.String("material_code", req.MaterialCode),
 			zap.Error(err),
 		)
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to process inventory check: "+err.Error()))
+		status, code := classifyError(err)
+		c.JSON(status, dto.ErrorResponseWithCode(code, "Failed to process inventory check: "+err.Error()))
 		return
 	}
 
@@ -95,5 +111,162 @@ func (h *InventoryHandler) UploadCheck(c *gin.Context) {
 		zap.String("location_code", req.LocationCode),
 	)
 
-	c.JSON(http.StatusOK, dto.SuccessResponse())
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(record))
+}
+
+// BatchUploadCheck 处理批量库存盘点上传接口
+// @Summary 批量上传库存盘点记录
+// @Description 一次提交多条盘点，返回逐行处理结果；mode=atomic 时整批要么全部成功要么全部回滚
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Param mode query string false "事务模式：per_item（默认）或 atomic"
+// @Param request body []dto.InventoryCheckRequest true "盘点请求数据数组"
+// @Success 200 {object} dto.CommonResponse{data=dto.BatchInventoryCheckResponse}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 500 {object} dto.CommonResponse "服务器内部错误"
+// @Router /api/wms/inventory/check/batch [post]
+func (h *InventoryHandler) BatchUploadCheck(c *gin.Context) {
+	mode := service.BatchMode(c.DefaultQuery("mode", string(service.BatchModePerItem)))
+	if mode != service.BatchModePerItem && mode != service.BatchModeAtomic {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponseWithCode(dto.ErrCodeInvalidInput, "Invalid request: mode must be per_item or atomic"))
+		return
+	}
+
+	rawData, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponseWithCode(dto.ErrCodeInvalidInput, "Invalid request: "+err.Error()))
+		return
+	}
+
+	var reqs []dto.InventoryCheckRequest
+	if err := json.Unmarshal(rawData, &reqs); err != nil {
+		h.logger.Warn("Invalid batch request payload",
+			zap.Error(err),
+			zap.String("remote_addr", c.ClientIP()),
+		)
+		c.JSON(http.StatusBadRequest, dto.ErrorResponseWithCode(dto.ErrCodeInvalidInput, "Invalid request: "+err.Error()))
+		return
+	}
+	if len(reqs) == 0 {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponseWithCode(dto.ErrCodeInvalidInput, "Invalid request: at least one item is required"))
+		return
+	}
+	if len(reqs) > maxBatchCheckItems {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponseWithCode(dto.ErrCodeInvalidInput,
+			fmt.Sprintf("Invalid request: batch size %d exceeds limit %d", len(reqs), maxBatchCheckItems)))
+		return
+	}
+
+	h.logger.Info("Received batch inventory check request",
+		zap.Int("total_items", len(reqs)),
+		zap.String("mode", string(mode)),
+		zap.String("remote_addr", c.ClientIP()),
+	)
+
+	// 逐行校验，校验失败的行不进入服务层
+	lines := make([]dto.InventoryCheckLineResult, len(reqs))
+	inputs := make([]service.InventoryCheckInput, 0, len(reqs))
+	inputIndexes := make([]int, 0, len(reqs))
+	invalidCount := 0
+	for i := range reqs {
+		lines[i].Index = i
+		if err := binding.Validator.ValidateStruct(&reqs[i]); err != nil {
+			lines[i].Status = string(service.BatchItemFailed)
+			lines[i].ErrorCode = dto.ErrCodeInvalidInput
+			lines[i].ErrorMessage = err.Error()
+			invalidCount++
+			continue
+		}
+		inputs = append(inputs, service.InventoryCheckInput{
+			CheckerID:       reqs[i].CheckerID,
+			LocationCode:    reqs[i].LocationCode,
+			MaterialCode:    reqs[i].MaterialCode,
+			LotNumber:       reqs[i].LotNumber,
+			ManufactureDate: reqs[i].ManufactureDate,
+			ExpiryDate:      reqs[i].ExpiryDate,
+			SerialNumbers:   reqs[i].SerialNumbers,
+			ActualQuantity:  reqs[i].ActualQuantity,
+			StockTakeID:     reqs[i].StockTakeID,
+			RecountTaskID:   reqs[i].RecountTaskID,
+			ReasonCode:      reqs[i].ReasonCode,
+			Note:            reqs[i].Note,
+		})
+		inputIndexes = append(inputIndexes, i)
+	}
+
+	if mode == service.BatchModeAtomic && invalidCount > 0 {
+		// 整批模式下任意一行无效即拒绝整批
+		for _, idx := range inputIndexes {
+			lines[idx].Status = string(service.BatchItemSkipped)
+		}
+	} else {
+		results := h.service.ProcessBatchInventoryCheck(inputs, mode)
+		for k, result := range results {
+			lines[inputIndexes[k]] = toLineResult(inputIndexes[k], result)
+		}
+	}
+
+	resp := dto.BatchInventoryCheckResponse{
+		Mode:    string(mode),
+		Total:   len(lines),
+		Results: lines,
+	}
+	for _, line := range lines {
+		if line.Status == string(service.BatchItemSucceeded) {
+			resp.SuccessCount++
+		} else {
+			resp.FailureCount++
+		}
+	}
+
+	h.logger.Info("Batch inventory check processed",
+		zap.String("mode", string(mode)),
+		zap.Int("total_items", resp.Total),
+		zap.Int("success_count", resp.SuccessCount),
+		zap.Int("failure_count", resp.FailureCount),
+	)
+
+	if mode == service.BatchModeAtomic && resp.FailureCount > 0 {
+		status := http.StatusBadRequest
+		for _, line := range lines {
+			if line.ErrorCode == dto.ErrCodeInternal {
+				status = http.StatusInternalServerError
+				break
+			}
+		}
+		c.JSON(status, dto.CommonResponse{
+			Code:      -1,
+			Message:   "Batch rolled back: one or more items failed",
+			ErrorCode: dto.ErrCodeBatchAborted,
+			Data:      resp,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(resp))
+}
+
+// toLineResult 将服务层单条处理结果转换为响应行
+func toLineResult(index int, result service.InventoryCheckResult) dto.InventoryCheckLineResult {
+	line := dto.InventoryCheckLineResult{
+		Index:  index,
+		Status: string(result.Status),
+	}
+	switch result.Status {
+	case service.BatchItemSucceeded:
+		if result.Record != nil {
+			difference := result.Record.Difference
+			line.RecordID = result.Record.ID
+			line.Difference = &difference
+		}
+	case service.BatchItemFailed:
+		if result.Err != nil {
+			_, line.ErrorCode = classifyError(result.Err)
+			line.ErrorMessage = result.Err.Error()
+		}
+	case service.BatchItemRolledBack, service.BatchItemSkipped:
+		line.ErrorCode = dto.ErrCodeBatchAborted
+	}
+	return line
 }
@@ -3,9 +3,13 @@ package handlers
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"wms/internal/api/dto"
+	"wms/internal/model"
 	"wms/internal/service"
 	"wms/pkg/logger"
 
@@ -13,25 +17,83 @@ import (
 )
 
 // mockInventoryService 是用于测试的模拟实现
+// 嵌入接口以满足未被测试覆盖的方法，调用未实现的方法会直接 panic
 type mockInventoryService struct {
+	service.InventoryService
 	processFunc func(input service.InventoryCheckInput) error
+	batchFunc   func(inputs []service.InventoryCheckInput, mode service.BatchMode) []service.InventoryCheckResult
+	listFunc    func(query service.CheckRecordQuery) (*service.CheckRecordPage, error)
+	approveFunc func(recordID uint, input service.ApprovalInput) (*model.InventoryCheckRecord, error)
+	assignFunc  func(taskID uint, checkerID string) (*model.RecountTask, error)
+	reportFunc  func(query service.VarianceReportQuery) (*service.VarianceReport, error)
 }
 
-func (m *mockInventoryService) ProcessInventoryCheck(input service.InventoryCheckInput) error {
+func (m *mockInventoryService) ProcessInventoryCheck(input service.InventoryCheckInput) (*model.InventoryCheckRecord, error) {
 	if m.processFunc != nil {
-		return m.processFunc(input)
+		if err := m.processFunc(input); err != nil {
+			return nil, err
+		}
 	}
-	return nil
+	return &model.InventoryCheckRecord{
+		CheckerID:      input.CheckerID,
+		LocationCode:   input.LocationCode,
+		MaterialCode:   input.MaterialCode,
+		ActualQuantity: input.ActualQuantity,
+	}, nil
 }
 
-func (m *mockInventoryService) ProcessBatchInventoryCheck(inputs []service.InventoryCheckInput) []error {
-	return nil
+func (m *mockInventoryService) ProcessBatchInventoryCheck(inputs []service.InventoryCheckInput, mode service.BatchMode) []service.InventoryCheckResult {
+	if m.batchFunc != nil {
+		return m.batchFunc(inputs, mode)
+	}
+	results := make([]service.InventoryCheckResult, len(inputs))
+	for i, input := range inputs {
+		results[i] = service.InventoryCheckResult{
+			Status: service.BatchItemSucceeded,
+			Record: &model.InventoryCheckRecord{ID: uint(i + 1), Difference: input.ActualQuantity},
+		}
+	}
+	return results
+}
+
+func (m *mockInventoryService) ListCheckRecords(query service.CheckRecordQuery) (*service.CheckRecordPage, error) {
+	if m.listFunc != nil {
+		return m.listFunc(query)
+	}
+	return &service.CheckRecordPage{Records: []model.InventoryCheckRecord{}}, nil
+}
+
+func (m *mockInventoryService) ApproveVariance(recordID uint, input service.ApprovalInput) (*model.InventoryCheckRecord, error) {
+	if m.approveFunc != nil {
+		return m.approveFunc(recordID, input)
+	}
+	return &model.InventoryCheckRecord{ID: recordID, ApprovalStatus: model.ApprovalStatusApproved}, nil
+}
+
+func (m *mockInventoryService) AssignRecountTask(taskID uint, checkerID string) (*model.RecountTask, error) {
+	if m.assignFunc != nil {
+		return m.assignFunc(taskID, checkerID)
+	}
+	return &model.RecountTask{ID: taskID, AssignedTo: checkerID, Status: model.RecountTaskStatusOpen}, nil
+}
+
+func (m *mockInventoryService) VarianceReport(query service.VarianceReportQuery) (*service.VarianceReport, error) {
+	if m.reportFunc != nil {
+		return m.reportFunc(query)
+	}
+	return &service.VarianceReport{GroupBy: query.GroupBy}, nil
 }
 
 func setupTestRouter(handler *InventoryHandler) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
 	router.POST("/api/wms/inventory/check/upload", handler.UploadCheck)
+	router.POST("/api/wms/inventory/check/batch", handler.BatchUploadCheck)
+	router.GET("/api/wms/inventory/check/records", handler.ListCheckRecords)
+	router.GET("/api/wms/inventory/check/records/unprocessed", handler.ListUnprocessedRecords)
+	router.POST("/api/wms/inventory/check/records/:id/approve", handler.ApproveVariance)
+	router.POST("/api/wms/inventory/check/recounts/:id/assign", handler.AssignRecountTask)
+	router.GET("/api/wms/inventory/reports/variance", handler.GetVarianceReport)
 	return router
 }
 
@@ -184,3 +246,642 @@ func TestUploadCheck_InvalidDataType(t *testing.T) {
 		t.Errorf("Expected code -1, got %v", response["code"])
 	}
 }
+
+func TestUploadCheck_ServiceValidationError(t *testing.T) {
+	// 准备环境
+	log, _ := logger.NewLogger("test")
+	mockService := &mockInventoryService{
+		processFunc: func(input service.InventoryCheckInput) error {
+			return service.ErrInvalidInput
+		},
+	}
+	handler := NewInventoryHandler(mockService, log)
+	router := setupTestRouter(handler)
+
+	requestBody := map[string]interface{}{
+		"checker_id":      "user123",
+		"location_code":   "LOC001",
+		"material_code":   "MAT001",
+		"actual_quantity": 100,
+	}
+	jsonBody, _ := json.Marshal(requestBody)
+
+	req, _ := http.NewRequest("POST", "/api/wms/inventory/check/upload", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// 断言：业务校验错误映射为 400 与 INVALID_INPUT
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	if response["error_code"] != "INVALID_INPUT" {
+		t.Errorf("Expected error_code INVALID_INPUT, got %v", response["error_code"])
+	}
+}
+
+func TestUploadCheck_UnknownMaterial(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	mockService := &mockInventoryService{
+		processFunc: func(input service.InventoryCheckInput) error {
+			return fmt.Errorf("%w: %s", service.ErrUnknownMaterial, input.MaterialCode)
+		},
+	}
+	router := setupTestRouter(NewInventoryHandler(mockService, log))
+
+	body := []byte(`{"checker_id":"user123","location_code":"LOC001","material_code":"MAT0O1","actual_quantity":100}`)
+	req, _ := http.NewRequest("POST", "/api/wms/inventory/check/upload", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// 断言：未登记的物料映射为 422 与 UNKNOWN_MATERIAL，而不是新建库存
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected status 422, got %d", w.Code)
+	}
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if response["error_code"] != "UNKNOWN_MATERIAL" {
+		t.Errorf("Expected error_code UNKNOWN_MATERIAL, got %v", response["error_code"])
+	}
+}
+
+func TestUploadCheck_BlockedLocation(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	mockService := &mockInventoryService{
+		processFunc: func(input service.InventoryCheckInput) error {
+			return fmt.Errorf("%w: %s rack damaged", service.ErrLocationBlocked, input.LocationCode)
+		},
+	}
+	router := setupTestRouter(NewInventoryHandler(mockService, log))
+
+	body := []byte(`{"checker_id":"user123","location_code":"LOC001","material_code":"MAT001","actual_quantity":100}`)
+	req, _ := http.NewRequest("POST", "/api/wms/inventory/check/upload", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// 断言：冻结库位上的盘点映射为 409 与 LOCATION_BLOCKED
+	if w.Code != http.StatusConflict {
+		t.Fatalf("Expected status 409, got %d", w.Code)
+	}
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if response["error_code"] != "LOCATION_BLOCKED" {
+		t.Errorf("Expected error_code LOCATION_BLOCKED, got %v", response["error_code"])
+	}
+}
+
+func TestUploadCheck_LotRequired(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	var captured service.InventoryCheckInput
+	mockService := &mockInventoryService{
+		processFunc: func(input service.InventoryCheckInput) error {
+			captured = input
+			if input.LotNumber == "" {
+				return fmt.Errorf("%w: material %s", service.ErrLotRequired, input.MaterialCode)
+			}
+			return nil
+		},
+	}
+	router := setupTestRouter(NewInventoryHandler(mockService, log))
+
+	body := []byte(`{"checker_id":"user123","location_code":"LOC001","material_code":"MAT001","actual_quantity":100}`)
+	req, _ := http.NewRequest("POST", "/api/wms/inventory/check/upload", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// 断言：批次管理物料缺少批次号映射为 422 与 LOT_REQUIRED
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected status 422, got %d", w.Code)
+	}
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if response["error_code"] != "LOT_REQUIRED" {
+		t.Errorf("Expected error_code LOT_REQUIRED, got %v", response["error_code"])
+	}
+
+	body = []byte(`{"checker_id":"user123","location_code":"LOC001","material_code":"MAT001","actual_quantity":100,"lot_number":"L2026-03","manufacture_date":"2026-03-01T00:00:00Z"}`)
+	req, _ = http.NewRequest("POST", "/api/wms/inventory/check/upload", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if captured.LotNumber != "L2026-03" || captured.ManufactureDate == nil || captured.ManufactureDate.Day() != 1 {
+		t.Errorf("Expected lot and manufacture date to be passed through, got %+v", captured)
+	}
+}
+
+func TestUploadCheck_SerialList(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	var captured service.InventoryCheckInput
+	mockService := &mockInventoryService{
+		processFunc: func(input service.InventoryCheckInput) error {
+			captured = input
+			if input.SerialNumbers == nil {
+				return fmt.Errorf("%w: %s", service.ErrSerialRequired, input.MaterialCode)
+			}
+			return nil
+		},
+	}
+	router := setupTestRouter(NewInventoryHandler(mockService, log))
+
+	// 序列号清单可代替 actual_quantity，空清单表示实盘为 0
+	body := []byte(`{"checker_id":"user123","location_code":"LOC001","material_code":"MAT001","serial_numbers":[]}`)
+	req, _ := http.NewRequest("POST", "/api/wms/inventory/check/upload", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if captured.SerialNumbers == nil || len(captured.SerialNumbers) != 0 {
+		t.Errorf("Expected an empty serial list to be passed through, got %v", captured.SerialNumbers)
+	}
+
+	body = []byte(`{"checker_id":"user123","location_code":"LOC001","material_code":"MAT001","actual_quantity":2}`)
+	req, _ = http.NewRequest("POST", "/api/wms/inventory/check/upload", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// 断言：序列号管理物料只给出数量映射为 422 与 SERIAL_REQUIRED
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected status 422, got %d", w.Code)
+	}
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if response["error_code"] != "SERIAL_REQUIRED" {
+		t.Errorf("Expected error_code SERIAL_REQUIRED, got %v", response["error_code"])
+	}
+}
+
+func TestBatchUploadCheck_PerItemPartialFailure(t *testing.T) {
+	// 准备环境
+	log, _ := logger.NewLogger("test")
+	mockService := &mockInventoryService{
+		batchFunc: func(inputs []service.InventoryCheckInput, mode service.BatchMode) []service.InventoryCheckResult {
+			if mode != service.BatchModePerItem {
+				t.Errorf("Expected mode per_item, got %s", mode)
+			}
+			if len(inputs) != 1 {
+				t.Errorf("Expected 1 valid input forwarded to service, got %d", len(inputs))
+			}
+			return []service.InventoryCheckResult{
+				{Status: service.BatchItemFailed, Err: errors.New("database unavailable")},
+			}
+		},
+	}
+	handler := NewInventoryHandler(mockService, log)
+	router := setupTestRouter(handler)
+
+	// 测试数据——第二行缺少 checker_id
+	requestBody := []map[string]interface{}{
+		{"checker_id": "user123", "location_code": "LOC001", "material_code": "MAT001", "actual_quantity": 10},
+		{"location_code": "LOC002", "material_code": "MAT002", "actual_quantity": 5},
+	}
+	jsonBody, _ := json.Marshal(requestBody)
+
+	req, _ := http.NewRequest("POST", "/api/wms/inventory/check/batch", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// 断言
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	var response struct {
+		Code int `json:"code"`
+		Data struct {
+			FailureCount int `json:"failure_count"`
+			Results      []struct {
+				Index     int    `json:"index"`
+				Status    string `json:"status"`
+				ErrorCode string `json:"error_code"`
+			} `json:"results"`
+		} `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	if response.Data.FailureCount != 2 {
+		t.Errorf("Expected failure_count 2, got %d", response.Data.FailureCount)
+	}
+	if len(response.Data.Results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(response.Data.Results))
+	}
+	if response.Data.Results[0].ErrorCode != "INTERNAL_ERROR" {
+		t.Errorf("Expected INTERNAL_ERROR for line 0, got %s", response.Data.Results[0].ErrorCode)
+	}
+	if response.Data.Results[1].ErrorCode != "INVALID_INPUT" {
+		t.Errorf("Expected INVALID_INPUT for line 1, got %s", response.Data.Results[1].ErrorCode)
+	}
+}
+
+func TestBatchUploadCheck_AtomicRejectsInvalidBatch(t *testing.T) {
+	// 准备环境
+	log, _ := logger.NewLogger("test")
+	mockService := &mockInventoryService{
+		batchFunc: func(inputs []service.InventoryCheckInput, mode service.BatchMode) []service.InventoryCheckResult {
+			t.Errorf("Service should not be called when an atomic batch contains invalid items")
+			return nil
+		},
+	}
+	handler := NewInventoryHandler(mockService, log)
+	router := setupTestRouter(handler)
+
+	requestBody := []map[string]interface{}{
+		{"checker_id": "user123", "location_code": "LOC001", "material_code": "MAT001", "actual_quantity": 10},
+		{"checker_id": "user123", "location_code": "LOC002", "material_code": "MAT002", "actual_quantity": -1},
+	}
+	jsonBody, _ := json.Marshal(requestBody)
+
+	req, _ := http.NewRequest("POST", "/api/wms/inventory/check/batch?mode=atomic", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// 断言
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	if response["error_code"] != "BATCH_ABORTED" {
+		t.Errorf("Expected error_code BATCH_ABORTED, got %v", response["error_code"])
+	}
+}
+
+func TestBatchUploadCheck_EmptyBatch(t *testing.T) {
+	// 准备环境
+	log, _ := logger.NewLogger("test")
+	mockService := &mockInventoryService{}
+	handler := NewInventoryHandler(mockService, log)
+	router := setupTestRouter(handler)
+
+	req, _ := http.NewRequest("POST", "/api/wms/inventory/check/batch", bytes.NewBufferString("[]"))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// 断言
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestListCheckRecords_FiltersAndPagination(t *testing.T) {
+	// 准备环境
+	log, _ := logger.NewLogger("test")
+	var captured service.CheckRecordQuery
+	mockService := &mockInventoryService{
+		listFunc: func(query service.CheckRecordQuery) (*service.CheckRecordPage, error) {
+			captured = query
+			return &service.CheckRecordPage{
+				Records:    []model.InventoryCheckRecord{{ID: 7, MaterialCode: "MAT001"}},
+				HasMore:    true,
+				NextCursor: "next-token",
+			}, nil
+		},
+	}
+	handler := NewInventoryHandler(mockService, log)
+	router := setupTestRouter(handler)
+
+	url := "/api/wms/inventory/check/records?material_code=MAT001&checker_id=user123" +
+		"&from=2026-03-01T00:00:00Z&is_processed=true&sort_by=difference&order=asc&limit=10"
+	req, _ := http.NewRequest("GET", url, nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// 断言
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if captured.MaterialCode != "MAT001" || captured.CheckerID != "user123" {
+		t.Errorf("Filters not forwarded: %+v", captured)
+	}
+	if captured.From.IsZero() || !captured.To.IsZero() {
+		t.Errorf("Expected only from to be set, got from=%v to=%v", captured.From, captured.To)
+	}
+	if captured.IsProcessed == nil || !*captured.IsProcessed {
+		t.Errorf("Expected is_processed=true to be forwarded")
+	}
+	if captured.SortBy != "difference" || captured.SortDesc || captured.Limit != 10 {
+		t.Errorf("Sorting not forwarded: %+v", captured)
+	}
+
+	var response struct {
+		Data struct {
+			Count      int    `json:"count"`
+			HasMore    bool   `json:"has_more"`
+			NextCursor string `json:"next_cursor"`
+		} `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	if response.Data.Count != 1 || !response.Data.HasMore || response.Data.NextCursor != "next-token" {
+		t.Errorf("Unexpected list envelope: %+v", response.Data)
+	}
+}
+
+func TestListUnprocessedRecords_ForcesUnprocessedAscending(t *testing.T) {
+	// 准备环境
+	log, _ := logger.NewLogger("test")
+	var captured service.CheckRecordQuery
+	mockService := &mockInventoryService{
+		listFunc: func(query service.CheckRecordQuery) (*service.CheckRecordPage, error) {
+			captured = query
+			return &service.CheckRecordPage{Records: []model.InventoryCheckRecord{}}, nil
+		},
+	}
+	handler := NewInventoryHandler(mockService, log)
+	router := setupTestRouter(handler)
+
+	req, _ := http.NewRequest("GET", "/api/wms/inventory/check/records/unprocessed?is_processed=true", nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// 断言
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if captured.IsProcessed == nil || *captured.IsProcessed {
+		t.Errorf("Expected is_processed to be forced to false")
+	}
+	if captured.SortDesc {
+		t.Errorf("Expected ascending order by default")
+	}
+}
+
+func TestListCheckRecords_InvalidSortField(t *testing.T) {
+	// 准备环境
+	log, _ := logger.NewLogger("test")
+	mockService := &mockInventoryService{}
+	handler := NewInventoryHandler(mockService, log)
+	router := setupTestRouter(handler)
+
+	req, _ := http.NewRequest("GET", "/api/wms/inventory/check/records?sort_by=checker_id", nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// 断言
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestApproveVariance_Success(t *testing.T) {
+	// 准备环境
+	log, _ := logger.NewLogger("test")
+	var capturedID uint
+	var capturedInput service.ApprovalInput
+	mockService := &mockInventoryService{
+		approveFunc: func(recordID uint, input service.ApprovalInput) (*model.InventoryCheckRecord, error) {
+			capturedID, capturedInput = recordID, input
+			return &model.InventoryCheckRecord{ID: recordID, ApprovalStatus: model.ApprovalStatusApproved}, nil
+		},
+	}
+	handler := NewInventoryHandler(mockService, log)
+	router := setupTestRouter(handler)
+
+	jsonBody, _ := json.Marshal(map[string]interface{}{
+		"approver_id": "supervisor01",
+		"comment":     "recounted by supervisor",
+	})
+	req, _ := http.NewRequest("POST", "/api/wms/inventory/check/records/42/approve", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// 断言
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if capturedID != 42 || capturedInput.ApproverID != "supervisor01" {
+		t.Errorf("Unexpected approval arguments: id=%d input=%+v", capturedID, capturedInput)
+	}
+}
+
+func TestApproveVariance_NotPending(t *testing.T) {
+	// 准备环境
+	log, _ := logger.NewLogger("test")
+	mockService := &mockInventoryService{
+		approveFunc: func(recordID uint, input service.ApprovalInput) (*model.InventoryCheckRecord, error) {
+			return nil, fmt.Errorf("%w: check record %d is approved", service.ErrInvalidState, recordID)
+		},
+	}
+	handler := NewInventoryHandler(mockService, log)
+	router := setupTestRouter(handler)
+
+	jsonBody, _ := json.Marshal(map[string]interface{}{"approver_id": "supervisor01"})
+	req, _ := http.NewRequest("POST", "/api/wms/inventory/check/records/42/approve", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// 断言：状态冲突映射为 409
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409, got %d", w.Code)
+	}
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+
+	if response["error_code"] != "INVALID_STATE" {
+		t.Errorf("Expected error_code INVALID_STATE, got %v", response["error_code"])
+	}
+}
+
+func TestApproveVariance_MissingApprover(t *testing.T) {
+	// 准备环境
+	log, _ := logger.NewLogger("test")
+	mockService := &mockInventoryService{}
+	handler := NewInventoryHandler(mockService, log)
+	router := setupTestRouter(handler)
+
+	req, _ := http.NewRequest("POST", "/api/wms/inventory/check/records/42/approve", bytes.NewBufferString("{}"))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// 断言
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestUploadCheck_PassesStockTakeID(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	var received service.InventoryCheckInput
+	mockService := &mockInventoryService{
+		processFunc: func(input service.InventoryCheckInput) error {
+			received = input
+			return nil
+		},
+	}
+	router := setupTestRouter(NewInventoryHandler(mockService, log))
+
+	body := `{"checker_id":"user123","location_code":"LOC001","material_code":"MAT001","actual_quantity":5,"stock_take_id":7}`
+	req, _ := http.NewRequest("POST", "/api/wms/inventory/check/upload", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if received.StockTakeID == nil || *received.StockTakeID != 7 {
+		t.Errorf("Expected stock_take_id 7 to be passed to service, got %v", received.StockTakeID)
+	}
+}
+
+func TestUploadCheck_PassesRecountTaskID(t *testing.T) {
+	// 准备环境
+	log, _ := logger.NewLogger("test")
+	var received service.InventoryCheckInput
+	mockService := &mockInventoryService{
+		processFunc: func(input service.InventoryCheckInput) error {
+			received = input
+			return nil
+		},
+	}
+	handler := NewInventoryHandler(mockService, log)
+	router := setupTestRouter(handler)
+
+	jsonBody, _ := json.Marshal(map[string]interface{}{
+		"checker_id":      "checker02",
+		"location_code":   "LOC001",
+		"material_code":   "MAT001",
+		"actual_quantity": 95,
+		"recount_task_id": 3,
+	})
+	req, _ := http.NewRequest("POST", "/api/wms/inventory/check/upload", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// 断言
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if received.RecountTaskID == nil || *received.RecountTaskID != 3 {
+		t.Errorf("Expected recount_task_id 3 to be passed to service, got %v", received.RecountTaskID)
+	}
+}
+
+func TestAssignRecountTask_CheckerAlreadyInChain(t *testing.T) {
+	// 准备环境
+	log, _ := logger.NewLogger("test")
+	mockService := &mockInventoryService{
+		assignFunc: func(taskID uint, checkerID string) (*model.RecountTask, error) {
+			return nil, fmt.Errorf("%w: checker %s already counted this item", service.ErrInvalidInput, checkerID)
+		},
+	}
+	handler := NewInventoryHandler(mockService, log)
+	router := setupTestRouter(handler)
+
+	jsonBody, _ := json.Marshal(map[string]interface{}{"checker_id": "checker01"})
+	req, _ := http.NewRequest("POST", "/api/wms/inventory/check/recounts/5/assign", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// 断言
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d", w.Code)
+	}
+	var response dto.CommonResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if response.ErrorCode != dto.ErrCodeInvalidInput {
+		t.Errorf("Expected error code %s, got %s", dto.ErrCodeInvalidInput, response.ErrorCode)
+	}
+}
+
+func TestUploadCheck_PassesReasonCodeAndNote(t *testing.T) {
+	// 准备环境
+	log, _ := logger.NewLogger("test")
+	var received service.InventoryCheckInput
+	mockService := &mockInventoryService{
+		processFunc: func(input service.InventoryCheckInput) error {
+			received = input
+			return nil
+		},
+	}
+	handler := NewInventoryHandler(mockService, log)
+	router := setupTestRouter(handler)
+
+	jsonBody, _ := json.Marshal(map[string]interface{}{
+		"checker_id":      "checker01",
+		"location_code":   "LOC001",
+		"material_code":   "MAT001",
+		"actual_quantity": 90,
+		"reason_code":     "DAMAGED",
+		"note":            "carton crushed",
+	})
+	req, _ := http.NewRequest("POST", "/api/wms/inventory/check/upload", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// 断言
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if received.ReasonCode != "DAMAGED" || received.Note != "carton crushed" {
+		t.Errorf("Expected reason and note to be passed to service, got %q / %q", received.ReasonCode, received.Note)
+	}
+}
+
+func TestGetVarianceReport_SplitsGroupBy(t *testing.T) {
+	// 准备环境
+	log, _ := logger.NewLogger("test")
+	var received service.VarianceReportQuery
+	mockService := &mockInventoryService{
+		reportFunc: func(query service.VarianceReportQuery) (*service.VarianceReport, error) {
+			received = query
+			return &service.VarianceReport{GroupBy: query.GroupBy}, nil
+		},
+	}
+	handler := NewInventoryHandler(mockService, log)
+	router := setupTestRouter(handler)
+
+	req, _ := http.NewRequest("GET", "/api/wms/inventory/reports/variance?group_by=reason_code,location_code&from=2024-01-01T00:00:00Z&to=2024-02-01T00:00:00Z", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// 断言
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if len(received.GroupBy) != 2 || received.GroupBy[0] != "reason_code" || received.GroupBy[1] != "location_code" {
+		t.Errorf("Unexpected group_by passed to service: %v", received.GroupBy)
+	}
+	if received.From.IsZero() || !received.From.Before(received.To) {
+		t.Errorf("Expected time window to be parsed, got %v - %v", received.From, received.To)
+	}
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"net/http"
+	"wms/internal/api/dto"
+	"wms/internal/service"
+	"wms/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LayoutHandler 负责处理仓库布局、行走图与路线规划相关的 HTTP 请求
+type LayoutHandler struct {
+	service service.LayoutService
+	logger  *logger.Logger
+}
+
+// NewLayoutHandler 创建一个新的 LayoutHandler 实例
+func NewLayoutHandler(service service.LayoutService, log *logger.Logger) *LayoutHandler {
+	return &LayoutHandler{
+		service: service,
+		logger:  log,
+	}
+}
+
+// GetLayout 查询仓库布局设置
+// @Summary 查询仓库布局设置
+// @Tags layout
+// @Produce json
+// @Success 200 {object} dto.CommonResponse{data=model.WarehouseLayout}
+// @Failure 500 {object} dto.CommonResponse "服务器内部错误"
+// @Router /api/wms/layout [get]
+func (h *LayoutHandler) GetLayout(c *gin.Context) {
+	layout, err := h.service.GetLayout()
+	if err != nil {
+		respondError(c, "Failed to get warehouse layout", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(layout))
+}
+
+// UpdateLayout 更新仓库布局设置
+// @Summary 更新仓库布局设置
+// @Description routing_strategy 为波次与盘点任务默认使用的路线策略：s_shape 依次完整穿过每条巷道；largest_gap 中间巷道从两端进入并跳过最大空档；tsp 最近邻加 2-opt 的启发式最短路线
+// @Tags layout
+// @Accept json
+// @Produce json
+// @Param request body dto.LayoutRequest true "布局设置"
+// @Success 200 {object} dto.CommonResponse{data=model.WarehouseLayout}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Router /api/wms/layout [put]
+func (h *LayoutHandler) UpdateLayout(c *gin.Context) {
+	var req dto.LayoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	layout, err := h.service.UpdateLayout(service.LayoutInput{
+		DepotX:          req.DepotX,
+		DepotY:          req.DepotY,
+		FrontAisleY:     req.FrontAisleY,
+		BackAisleY:      req.BackAisleY,
+		RoutingStrategy: req.RoutingStrategy,
+		UpdatedBy:       req.UpdatedBy,
+	})
+	if err != nil {
+		respondError(c, "Failed to update warehouse layout", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(layout))
+}
+
+// ListEdges 查询行走图通路
+// @Summary 查询行走图通路
+// @Tags layout
+// @Produce json
+// @Success 200 {object} dto.CommonResponse{data=dto.ListResponse{items=[]model.LayoutEdge}}
+// @Failure 500 {object} dto.CommonResponse "服务器内部错误"
+// @Router /api/wms/layout/edges [get]
+func (h *LayoutHandler) ListEdges(c *gin.Context) {
+	edges, err := h.service.ListEdges()
+	if err != nil {
+		respondError(c, "Failed to list layout edges", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(dto.ListResponse{
+		Items: edges,
+		Count: len(edges),
+	}))
+}
+
+// UpsertEdge 新增或更新行走图通路
+// @Summary 新增或更新行走图通路
+// @Description 通路为双向，A-B 与 B-A 视为同一条。配置了通路的两点之间按图上最短路计算距离，用于描述隔断、单侧通道等不规则布局
+// @Tags layout
+// @Accept json
+// @Produce json
+// @Param request body dto.LayoutEdgeRequest true "通路"
+// @Success 200 {object} dto.CommonResponse{data=model.LayoutEdge}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Router /api/wms/layout/edges [put]
+func (h *LayoutHandler) UpsertEdge(c *gin.Context) {
+	var req dto.LayoutEdgeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	edge, err := h.service.UpsertEdge(service.LayoutEdgeInput{
+		FromNode: req.FromNode,
+		ToNode:   req.ToNode,
+		Distance: req.Distance,
+	})
+	if err != nil {
+		respondError(c, "Failed to save layout edge", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(edge))
+}
+
+// DeleteEdge 删除行走图通路
+// @Summary 删除行走图通路
+// @Tags layout
+// @Produce json
+// @Param id path int true "通路ID"
+// @Success 200 {object} dto.CommonResponse
+// @Failure 404 {object} dto.CommonResponse "通路不存在"
+// @Router /api/wms/layout/edges/{id} [delete]
+func (h *LayoutHandler) DeleteEdge(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteEdge(id); err != nil {
+		respondError(c, "Failed to delete layout edge", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse())
+}
+
+// PlanRoute 规划库位行走路线
+// @Summary 规划库位行走路线
+// @Description 按仓库布局排列一组库位的行走顺序，不创建任何任务。未登记巷道与坐标的库位不参与规划，按远近顺序排在路线之后并在 unrouted 中列出
+// @Tags layout
+// @Accept json
+// @Produce json
+// @Param request body dto.RouteRequest true "库位与路线策略"
+// @Success 200 {object} dto.CommonResponse{data=service.RoutePlan}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Router /api/wms/layout/route [post]
+func (h *LayoutHandler) PlanRoute(c *gin.Context) {
+	var req dto.RouteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	plan, err := h.service.PlanRoute(service.RouteInput{
+		LocationCodes: req.LocationCodes,
+		Strategy:      req.Strategy,
+	})
+	if err != nil {
+		respondError(c, "Failed to plan route", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(plan))
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wms/internal/service"
+	"wms/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mockLayoutService 是用于测试的布局服务模拟实现
+type mockLayoutService struct {
+	service.LayoutService
+	routeFunc      func(input service.RouteInput) (*service.RoutePlan, error)
+	deleteEdgeFunc func(id uint) error
+}
+
+func (m *mockLayoutService) PlanRoute(input service.RouteInput) (*service.RoutePlan, error) {
+	return m.routeFunc(input)
+}
+
+func (m *mockLayoutService) DeleteEdge(id uint) error {
+	return m.deleteEdgeFunc(id)
+}
+
+func setupLayoutRouter(handler *LayoutHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.DELETE("/api/wms/layout/edges/:id", handler.DeleteEdge)
+	router.POST("/api/wms/layout/route", handler.PlanRoute)
+	return router
+}
+
+func TestPlanRoute_ReturnsOrderedLocations(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	var captured service.RouteInput
+	router := setupLayoutRouter(NewLayoutHandler(&mockLayoutService{
+		routeFunc: func(input service.RouteInput) (*service.RoutePlan, error) {
+			captured = input
+			return &service.RoutePlan{
+				Strategy:      input.Strategy,
+				LocationCodes: []string{"A-01", "B-02", "R-01"},
+				Distance:      28,
+				Unrouted:      []string{"R-01"},
+			}, nil
+		},
+	}, log))
+
+	body := []byte(`{"location_codes":["B-02","R-01","A-01"],"strategy":"tsp"}`)
+	req, _ := http.NewRequest("POST", "/api/wms/layout/route", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if captured.Strategy != "tsp" || len(captured.LocationCodes) != 3 {
+		t.Errorf("Unexpected input passed to service: %+v", captured)
+	}
+	var response struct {
+		Data service.RoutePlan `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if fmt.Sprint(response.Data.LocationCodes) != "[A-01 B-02 R-01]" || response.Data.Distance != 28 {
+		t.Errorf("Unexpected route in response: %+v", response.Data)
+	}
+}
+
+func TestPlanRoute_RejectsUnknownStrategy(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	router := setupLayoutRouter(NewLayoutHandler(&mockLayoutService{}, log))
+
+	body := []byte(`{"location_codes":["A-01"],"strategy":"random"}`)
+	req, _ := http.NewRequest("POST", "/api/wms/layout/route", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestDeleteEdge_NotFound(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	router := setupLayoutRouter(NewLayoutHandler(&mockLayoutService{
+		deleteEdgeFunc: func(id uint) error {
+			return fmt.Errorf("%w: layout edge %d", service.ErrNotFound, id)
+		},
+	}, log))
+
+	req, _ := http.NewRequest("DELETE", "/api/wms/layout/edges/9", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("Expected status 404, got %d", w.Code)
+	}
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"net/http"
+	"wms/internal/api/dto"
+	"wms/internal/repository"
+	"wms/internal/service"
+	"wms/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LocationHandler 负责处理库位主数据相关的 HTTP 请求
+type LocationHandler struct {
+	service service.LocationService
+	logger  *logger.Logger
+}
+
+// NewLocationHandler 创建一个新的 LocationHandler 实例
+func NewLocationHandler(service service.LocationService, log *logger.Logger) *LocationHandler {
+	return &LocationHandler{
+		service: service,
+		logger:  log,
+	}
+}
+
+// ListLocations 查询库位列表
+// @Summary 查询库位列表
+// @Tags locations
+// @Produce json
+// @Param warehouse query string false "仓库"
+// @Param zone query string false "库区"
+// @Param aisle query string false "巷道"
+// @Param type query string false "库位类型：pick_face、reserve、dock、quarantine、virtual"
+// @Param blocked query bool false "是否冻结"
+// @Param active query bool false "是否启用"
+// @Success 200 {object} dto.CommonResponse{data=dto.ListResponse{items=[]model.Location}}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 500 {object} dto.CommonResponse "服务器内部错误"
+// @Router /api/wms/locations [get]
+func (h *LocationHandler) ListLocations(c *gin.Context) {
+	var req dto.LocationListQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	locations, err := h.service.ListLocations(repository.LocationFilter{
+		Warehouse: req.Warehouse,
+		Zone:      req.Zone,
+		Aisle:     req.Aisle,
+		Type:      req.Type,
+		Blocked:   req.Blocked,
+		Active:    req.Active,
+	})
+	if err != nil {
+		respondError(c, "Failed to list locations", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(dto.ListResponse{
+		Items: locations,
+		Count: len(locations),
+	}))
+}
+
+// GetLocation 查询库位详情
+// @Summary 查询库位详情
+// @Tags locations
+// @Produce json
+// @Param code path string true "库位编码"
+// @Success 200 {object} dto.CommonResponse{data=model.Location}
+// @Failure 404 {object} dto.CommonResponse "库位不存在"
+// @Router /api/wms/locations/{code} [get]
+func (h *LocationHandler) GetLocation(c *gin.Context) {
+	location, err := h.service.GetLocation(c.Param("code"))
+	if err != nil {
+		respondError(c, "Failed to get location", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(location))
+}
+
+// UpsertLocation 新增或更新库位
+// @Summary 保存库位
+// @Description 按 code 新增或覆盖库位；sequence 越小越靠近收货区，容量限制为 0 表示不限制；
+// @Description 只有启用且未冻结的库位才能发生库存变动与盘点，只有允许上架的拣货位与存储位会被推荐为上架目标
+// @Tags locations
+// @Accept json
+// @Produce json
+// @Param request body dto.LocationRequest true "库位"
+// @Success 200 {object} dto.CommonResponse{data=model.Location}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Router /api/wms/locations [put]
+func (h *LocationHandler) UpsertLocation(c *gin.Context) {
+	var req dto.LocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	location, err := h.service.UpsertLocation(service.LocationInput{
+		Code:           req.Code,
+		Warehouse:      req.Warehouse,
+		Zone:           req.Zone,
+		Type:           req.Type,
+		Sequence:       req.Sequence,
+		Capacity:       req.Capacity,
+		MaxVolumeCm3:   req.MaxVolumeCm3,
+		MaxWeightKg:    req.MaxWeightKg,
+		MaxSkus:        req.MaxSkus,
+		PutawayEnabled: req.PutawayEnabled,
+		Blocked:        req.Blocked,
+		BlockReason:    req.BlockReason,
+		Active:         req.Active,
+		Aisle:          req.Aisle,
+		Rack:           req.Rack,
+		Bin:            req.Bin,
+		Bay:            req.Bay,
+		Level:          req.Level,
+		X:              req.X,
+		Y:              req.Y,
+	})
+	if err != nil {
+		respondError(c, "Failed to save location", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(location))
+}
+
+// GenerateLocations 按编码范围批量生成库位
+// @Summary 批量生成库位
+// @Description 按 from..to 范围（如 A-01-01..A-20-05）生成库位，最后三段依次作为巷道、货架、货位；
+// @Description 已存在的库位保持不变，单次最多生成 5000 个；dry_run 为 true 时只返回将生成的编码
+// @Tags locations
+// @Accept json
+// @Produce json
+// @Param request body dto.LocationGenerateRequest true "生成范围"
+// @Success 200 {object} dto.CommonResponse{data=service.LocationRangeResult}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效或范围无法展开"
+// @Router /api/wms/locations/generate [post]
+func (h *LocationHandler) GenerateLocations(c *gin.Context) {
+	var req dto.LocationGenerateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	result, err := h.service.GenerateLocations(service.LocationRangeInput{
+		From:           req.From,
+		To:             req.To,
+		Warehouse:      req.Warehouse,
+		Zone:           req.Zone,
+		Type:           req.Type,
+		Capacity:       req.Capacity,
+		MaxVolumeCm3:   req.MaxVolumeCm3,
+		MaxWeightKg:    req.MaxWeightKg,
+		MaxSkus:        req.MaxSkus,
+		PutawayEnabled: req.PutawayEnabled,
+		SequenceStart:  req.SequenceStart,
+		OriginX:        req.OriginX,
+		OriginY:        req.OriginY,
+		AisleSpacing:   req.AisleSpacing,
+		BaySpacing:     req.BaySpacing,
+		DryRun:         req.DryRun,
+	})
+	if err != nil {
+		respondError(c, "Failed to generate locations", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(result))
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"net/http"
+	"wms/internal/api/dto"
+	"wms/internal/repository"
+	"wms/internal/service"
+	"wms/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaterialHandler 负责处理物料主数据相关的 HTTP 请求
+type MaterialHandler struct {
+	service service.MaterialService
+	logger  *logger.Logger
+}
+
+// NewMaterialHandler 创建一个新的 MaterialHandler 实例
+func NewMaterialHandler(service service.MaterialService, log *logger.Logger) *MaterialHandler {
+	return &MaterialHandler{
+		service: service,
+		logger:  log,
+	}
+}
+
+// ListMaterials 查询物料列表
+// @Summary 查询物料列表
+// @Tags materials
+// @Produce json
+// @Param category query string false "物料类别"
+// @Param active query bool false "是否启用"
+// @Param barcode query string false "条码，返回登记了该条码的物料"
+// @Success 200 {object} dto.CommonResponse{data=dto.ListResponse{items=[]model.Material}}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 500 {object} dto.CommonResponse "服务器内部错误"
+// @Router /api/wms/materials [get]
+func (h *MaterialHandler) ListMaterials(c *gin.Context) {
+	var req dto.MaterialListQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	materials, err := h.service.ListMaterials(repository.MaterialFilter{
+		Category: req.Category,
+		Active:   req.Active,
+		Barcode:  req.Barcode,
+	})
+	if err != nil {
+		respondError(c, "Failed to list materials", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(dto.ListResponse{
+		Items: materials,
+		Count: len(materials),
+	}))
+}
+
+// GetMaterial 查询物料详情
+// @Summary 查询物料详情
+// @Tags materials
+// @Produce json
+// @Param code path string true "物料编码"
+// @Success 200 {object} dto.CommonResponse{data=model.Material}
+// @Failure 404 {object} dto.CommonResponse "物料不存在"
+// @Router /api/wms/materials/{code} [get]
+func (h *MaterialHandler) GetMaterial(c *gin.Context) {
+	material, err := h.service.GetMaterial(c.Param("code"))
+	if err != nil {
+		respondError(c, "Failed to get material", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(material))
+}
+
+// UpsertMaterial 新增或更新物料
+// @Summary 保存物料
+// @Description 按 code 新增或覆盖物料主数据；只有已登记且启用的物料才能发生库存变动，停用的物料仍可释放已有预留；
+// @Description 尺寸未登记的物料不能自动装箱，cross_dock 的物料收货时优先越库满足等待中的出库单据；条码全局唯一
+// @Tags materials
+// @Accept json
+// @Produce json
+// @Param request body dto.MaterialRequest true "物料"
+// @Success 200 {object} dto.CommonResponse{data=model.Material}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 409 {object} dto.CommonResponse "条码已登记在其他物料下"
+// @Router /api/wms/materials [put]
+func (h *MaterialHandler) UpsertMaterial(c *gin.Context) {
+	var req dto.MaterialRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	material, err := h.service.UpsertMaterial(toMaterialInput(req))
+	if err != nil {
+		respondError(c, "Failed to save material", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(material))
+}
+
+// DeleteMaterial 删除物料
+// @Summary 删除物料
+// @Description 仍有在库或预留库存的物料不能删除，应改为停用；历史单据与流水中的物料编码保持不变
+// @Tags materials
+// @Produce json
+// @Param code path string true "物料编码"
+// @Success 200 {object} dto.CommonResponse
+// @Failure 404 {object} dto.CommonResponse "物料不存在"
+// @Failure 409 {object} dto.CommonResponse "物料仍有库存"
+// @Router /api/wms/materials/{code} [delete]
+func (h *MaterialHandler) DeleteMaterial(c *gin.Context) {
+	if err := h.service.DeleteMaterial(c.Param("code")); err != nil {
+		respondError(c, "Failed to delete material", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse())
+}
+
+// ImportMaterials 批量导入物料
+// @Summary 批量导入物料
+// @Description 按 code 批量新增或覆盖物料，所有行校验通过后在一个事务中写入；
+// @Description 任意一行无效（内容无效、文件内编码或条码重复、条码已登记在其他物料下）时整批不写入，data.errors 按行列出错误
+// @Tags materials
+// @Accept json
+// @Produce json
+// @Param request body dto.MaterialImportRequest true "物料列表"
+// @Success 200 {object} dto.CommonResponse{data=service.MaterialImportResult}
+// @Failure 400 {object} dto.CommonResponse{data=service.MaterialImportResult} "请求参数无效"
+// @Router /api/wms/materials/import [post]
+func (h *MaterialHandler) ImportMaterials(c *gin.Context) {
+	var req dto.MaterialImportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	inputs := make([]service.MaterialInput, 0, len(req.Materials))
+	for _, m := range req.Materials {
+		inputs = append(inputs, toMaterialInput(m))
+	}
+	result, err := h.service.ImportMaterials(inputs)
+	if err != nil {
+		if result != nil {
+			c.JSON(http.StatusBadRequest, dto.CommonResponse{
+				Code:      -1,
+				Message:   "Failed to import materials: " + err.Error(),
+				ErrorCode: dto.ErrCodeInvalidInput,
+				Data:      result,
+			})
+			return
+		}
+		respondError(c, "Failed to import materials", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(result))
+}
+
+// toMaterialInput 将物料请求转换为服务层输入
+func toMaterialInput(req dto.MaterialRequest) service.MaterialInput {
+	return service.MaterialInput{
+		Code:          req.Code,
+		Description:   req.Description,
+		BaseUnit:      req.BaseUnit,
+		Category:      req.Category,
+		LengthCm:      req.LengthCm,
+		WidthCm:       req.WidthCm,
+		HeightCm:      req.HeightCm,
+		WeightKg:      req.WeightKg,
+		CrossDock:     req.CrossDock,
+		LotManaged:    req.LotManaged,
+		SerialTracked: req.SerialTracked,
+		Active:        req.Active,
+		Barcodes:      req.Barcodes,
+	}
+}
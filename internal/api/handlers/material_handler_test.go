@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wms/internal/service"
+	"wms/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mockMaterialService 是用于测试的物料服务模拟实现
+type mockMaterialService struct {
+	service.MaterialService
+	importFunc func(inputs []service.MaterialInput) (*service.MaterialImportResult, error)
+	deleteFunc func(code string) error
+}
+
+func (m *mockMaterialService) ImportMaterials(inputs []service.MaterialInput) (*service.MaterialImportResult, error) {
+	return m.importFunc(inputs)
+}
+
+func (m *mockMaterialService) DeleteMaterial(code string) error {
+	return m.deleteFunc(code)
+}
+
+func setupMaterialRouter(handler *MaterialHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/wms/materials/import", handler.ImportMaterials)
+	router.DELETE("/api/wms/materials/:code", handler.DeleteMaterial)
+	return router
+}
+
+func TestImportMaterials_ReportsRowErrors(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	var captured []service.MaterialInput
+	router := setupMaterialRouter(NewMaterialHandler(&mockMaterialService{
+		importFunc: func(inputs []service.MaterialInput) (*service.MaterialImportResult, error) {
+			captured = inputs
+			return &service.MaterialImportResult{
+				Total:  2,
+				Errors: []service.MaterialImportError{{Row: 1, Code: "M-002", Message: "barcode 690001 duplicates row 0"}},
+			}, fmt.Errorf("%w: 1 of 2 rows are invalid", service.ErrInvalidInput)
+		},
+	}, log))
+
+	body := []byte(`{"materials":[
+		{"code":"M-001","base_unit":"BOX","barcodes":["690001"]},
+		{"code":"M-002","active":false,"barcodes":["690001"]}]}`)
+	req, _ := http.NewRequest("POST", "/api/wms/materials/import", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d: %s", w.Code, w.Body.String())
+	}
+	var response struct {
+		ErrorCode string `json:"error_code"`
+		Data      struct {
+			Errors []struct {
+				Row int `json:"row"`
+			} `json:"errors"`
+		} `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if response.ErrorCode != "INVALID_INPUT" || len(response.Data.Errors) != 1 || response.Data.Errors[0].Row != 1 {
+		t.Errorf("Expected row 1 reported with INVALID_INPUT, got %s", w.Body.String())
+	}
+	if len(captured) != 2 || captured[0].BaseUnit != "BOX" || captured[1].Active == nil || *captured[1].Active {
+		t.Errorf("Unexpected inputs passed to service: %+v", captured)
+	}
+}
+
+func TestDeleteMaterial_StillStocked(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	router := setupMaterialRouter(NewMaterialHandler(&mockMaterialService{
+		deleteFunc: func(code string) error {
+			return fmt.Errorf("%w: material %s still has stock on hand or reserved", service.ErrInvalidState, code)
+		},
+	}, log))
+
+	req, _ := http.NewRequest("DELETE", "/api/wms/materials/M-001", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("Expected status 409, got %d", w.Code)
+	}
+}
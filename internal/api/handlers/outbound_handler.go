@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"net/http"
+	"wms/internal/api/dto"
+	"wms/internal/repository"
+	"wms/internal/service"
+	"wms/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OutboundHandler 负责处理出库单据与库存分配相关的 HTTP 请求
+type OutboundHandler struct {
+	service service.OutboundService
+	logger  *logger.Logger
+}
+
+// NewOutboundHandler 创建一个新的 OutboundHandler 实例
+func NewOutboundHandler(service service.OutboundService, log *logger.Logger) *OutboundHandler {
+	return &OutboundHandler{
+		service: service,
+		logger:  log,
+	}
+}
+
+// CreateOrder 创建出库单据
+// @Summary 创建出库单据
+// @Tags outbound
+// @Accept json
+// @Produce json
+// @Param request body dto.OutboundOrderCreateRequest true "出库单据"
+// @Success 200 {object} dto.CommonResponse{data=model.OutboundOrder}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效或单据号重复"
+// @Failure 500 {object} dto.CommonResponse "服务器内部错误"
+// @Router /api/wms/outbound/orders [post]
+func (h *OutboundHandler) CreateOrder(c *gin.Context) {
+	var req dto.OutboundOrderCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	lines := make([]service.OutboundLineInput, 0, len(req.Lines))
+	for _, line := range req.Lines {
+		lines = append(lines, service.OutboundLineInput{
+			LineNo:       line.LineNo,
+			MaterialCode: line.MaterialCode,
+			Quantity:     line.Quantity,
+		})
+	}
+	order, err := h.service.CreateOrder(service.OutboundOrderInput{
+		OrderNo:            req.OrderNo,
+		CustomerCode:       req.CustomerCode,
+		ShipTo:             req.ShipTo,
+		RequiredAt:         req.RequiredAt,
+		Carrier:            req.Carrier,
+		CarrierCutoffAt:    req.CarrierCutoffAt,
+		AllocationStrategy: req.AllocationStrategy,
+		CreatedBy:          req.CreatedBy,
+		Lines:              lines,
+	})
+	if err != nil {
+		respondError(c, "Failed to create outbound order", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(order))
+}
+
+// ListOrders 查询出库单据列表
+// @Summary 查询出库单据列表
+// @Tags outbound
+// @Produce json
+// @Param status query string false "状态：open、partially_allocated、allocated、cancelled"
+// @Param customer_code query string false "客户代码"
+// @Success 200 {object} dto.CommonResponse{data=dto.ListResponse}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 500 {object} dto.CommonResponse "服务器内部错误"
+// @Router /api/wms/outbound/orders [get]
+func (h *OutboundHandler) ListOrders(c *gin.Context) {
+	var req dto.OutboundOrderListQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	orders, err := h.service.ListOrders(repository.OutboundOrderFilter{
+		Status:       req.Status,
+		CustomerCode: req.CustomerCode,
+	})
+	if err != nil {
+		respondError(c, "Failed to list outbound orders", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(dto.ListResponse{
+		Items: orders,
+		Count: len(orders),
+	}))
+}
+
+// GetOrder 查询出库单据详情
+// @Summary 查询出库单据详情
+// @Tags outbound
+// @Produce json
+// @Param id path int true "出库单据ID"
+// @Success 200 {object} dto.CommonResponse{data=model.OutboundOrder}
+// @Failure 404 {object} dto.CommonResponse "单据不存在"
+// @Router /api/wms/outbound/orders/{id} [get]
+func (h *OutboundHandler) GetOrder(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	order, err := h.service.GetOrder(id)
+	if err != nil {
+		respondError(c, "Failed to get outbound order", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(order))
+}
+
+// AllocateOrder 分配库存
+// @Summary 分配库存
+// @Description 按分配策略为未分配足量的行预留库存；可用库存不足时部分分配，缺口在 shortages 中返回，可稍后再次分配
+// @Tags outbound
+// @Accept json
+// @Produce json
+// @Param id path int true "出库单据ID"
+// @Param request body dto.OutboundAllocateRequest true "操作人"
+// @Success 200 {object} dto.CommonResponse{data=service.AllocationResult}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 404 {object} dto.CommonResponse "单据不存在"
+// @Failure 409 {object} dto.CommonResponse "单据已全部分配或已取消"
+// @Router /api/wms/outbound/orders/{id}/allocate [post]
+func (h *OutboundHandler) AllocateOrder(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req dto.OutboundAllocateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	result, err := h.service.AllocateOrder(id, req.OperatorID)
+	if err != nil {
+		respondError(c, "Failed to allocate outbound order", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(result))
+}
+
+// CancelOrder 取消出库单据
+// @Summary 取消出库单据
+// @Description 取消单据并在同一事务中释放全部预留库存；组波后只有全部短拣、实拣为 0 的已拣货单据可以取消
+// @Tags outbound
+// @Accept json
+// @Produce json
+// @Param id path int true "出库单据ID"
+// @Param request body dto.OutboundCancelRequest true "操作人与原因"
+// @Success 200 {object} dto.CommonResponse{data=model.OutboundOrder}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 404 {object} dto.CommonResponse "单据不存在"
+// @Failure 409 {object} dto.CommonResponse "单据状态不允许取消"
+// @Router /api/wms/outbound/orders/{id}/cancel [post]
+func (h *OutboundHandler) CancelOrder(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req dto.OutboundCancelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	order, err := h.service.CancelOrder(id, req.OperatorID, req.Reason)
+	if err != nil {
+		respondError(c, "Failed to cancel outbound order", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(order))
+}
+
+// ListAllocations 查询出库单据的预留记录
+// @Summary 查询预留记录
+// @Tags outbound
+// @Produce json
+// @Param id path int true "出库单据ID"
+// @Success 200 {object} dto.CommonResponse{data=dto.ListResponse}
+// @Failure 404 {object} dto.CommonResponse "单据不存在"
+// @Router /api/wms/outbound/orders/{id}/allocations [get]
+func (h *OutboundHandler) ListAllocations(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	allocations, err := h.service.ListAllocations(id)
+	if err != nil {
+		respondError(c, "Failed to list allocations", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(dto.ListResponse{
+		Items: allocations,
+		Count: len(allocations),
+	}))
+}
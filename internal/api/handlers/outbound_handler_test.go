@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wms/internal/api/dto"
+	"wms/internal/model"
+	"wms/internal/service"
+	"wms/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mockOutboundService 是用于测试的出库服务模拟实现
+type mockOutboundService struct {
+	service.OutboundService
+	allocateFunc func(id uint, operatorID string) (*service.AllocationResult, error)
+}
+
+func (m *mockOutboundService) AllocateOrder(id uint, operatorID string) (*service.AllocationResult, error) {
+	return m.allocateFunc(id, operatorID)
+}
+
+func setupOutboundRouter(handler *OutboundHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/wms/outbound/orders", handler.CreateOrder)
+	router.POST("/api/wms/outbound/orders/:id/allocate", handler.AllocateOrder)
+	return router
+}
+
+func TestAllocateOrder_ReturnsShortages(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	router := setupOutboundRouter(NewOutboundHandler(&mockOutboundService{
+		allocateFunc: func(id uint, operatorID string) (*service.AllocationResult, error) {
+			return &service.AllocationResult{
+				Order:     &model.OutboundOrder{ID: id, Status: model.OutboundOrderStatusPartiallyAllocated},
+				Shortages: []service.AllocationShortage{{LineNo: 1, MaterialCode: "MAT001", Quantity: 4}},
+			}, nil
+		},
+	}, log))
+
+	body, _ := json.Marshal(dto.OutboundAllocateRequest{OperatorID: "op1"})
+	req, _ := http.NewRequest("POST", "/api/wms/outbound/orders/3/allocate", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var response struct {
+		Data service.AllocationResult `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if len(response.Data.Shortages) != 1 || response.Data.Shortages[0].Quantity != 4 {
+		t.Errorf("Expected one shortage of 4, got %+v", response.Data.Shortages)
+	}
+}
+
+func TestAllocateOrder_CancelledOrderConflict(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	router := setupOutboundRouter(NewOutboundHandler(&mockOutboundService{
+		allocateFunc: func(id uint, operatorID string) (*service.AllocationResult, error) {
+			return nil, fmt.Errorf("%w: outbound order %d is cancelled", service.ErrInvalidState, id)
+		},
+	}, log))
+
+	body, _ := json.Marshal(dto.OutboundAllocateRequest{OperatorID: "op1"})
+	req, _ := http.NewRequest("POST", "/api/wms/outbound/orders/3/allocate", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("Expected status 409, got %d", w.Code)
+	}
+}
+
+func TestCreateOutboundOrder_RejectsUnknownStrategy(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	router := setupOutboundRouter(NewOutboundHandler(&mockOutboundService{}, log))
+
+	body := []byte(`{"order_no":"SO-1","created_by":"u1","allocation_strategy":"lifo","lines":[{"material_code":"MAT001","quantity":1}]}`)
+	req, _ := http.NewRequest("POST", "/api/wms/outbound/orders", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d", w.Code)
+	}
+}
@@ -0,0 +1,277 @@
+package handlers
+
+import (
+	"net/http"
+	"wms/internal/api/dto"
+	"wms/internal/service"
+	"wms/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PackingHandler 负责处理箱型、装箱与发运确认相关的 HTTP 请求
+type PackingHandler struct {
+	service service.PackingService
+	logger  *logger.Logger
+}
+
+// NewPackingHandler 创建一个新的 PackingHandler 实例
+func NewPackingHandler(service service.PackingService, log *logger.Logger) *PackingHandler {
+	return &PackingHandler{
+		service: service,
+		logger:  log,
+	}
+}
+
+// ListCartonTypes 查询箱型列表
+// @Summary 查询箱型列表
+// @Tags packing
+// @Produce json
+// @Success 200 {object} dto.CommonResponse{data=dto.ListResponse{items=[]model.CartonType}}
+// @Failure 500 {object} dto.CommonResponse "服务器内部错误"
+// @Router /api/wms/outbound/cartons [get]
+func (h *PackingHandler) ListCartonTypes(c *gin.Context) {
+	cartons, err := h.service.ListCartonTypes()
+	if err != nil {
+		respondError(c, "Failed to list carton types", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(dto.ListResponse{
+		Items: cartons,
+		Count: len(cartons),
+	}))
+}
+
+// UpsertCartonType 新增或更新箱型
+// @Summary 保存箱型
+// @Description 按 code 新增或覆盖箱型，停用的箱型不参与自动装箱，但仍可用于手工装箱
+// @Tags packing
+// @Accept json
+// @Produce json
+// @Param request body dto.CartonTypeRequest true "箱型"
+// @Success 200 {object} dto.CommonResponse{data=model.CartonType}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Router /api/wms/outbound/cartons [put]
+func (h *PackingHandler) UpsertCartonType(c *gin.Context) {
+	var req dto.CartonTypeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	carton, err := h.service.UpsertCartonType(service.CartonTypeInput{
+		Code:         req.Code,
+		Description:  req.Description,
+		LengthCm:     req.LengthCm,
+		WidthCm:      req.WidthCm,
+		HeightCm:     req.HeightCm,
+		MaxWeightKg:  req.MaxWeightKg,
+		TareWeightKg: req.TareWeightKg,
+		Active:       req.Active,
+	})
+	if err != nil {
+		respondError(c, "Failed to save carton type", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(carton))
+}
+
+// Cartonize 自动装箱
+// @Summary 自动装箱
+// @Description 按物料尺寸、重量与启用的箱型，将已拣未装箱的数量分配到推荐箱型的包裹中。dry_run 为 true 时只返回推荐结果（包裹未保存，没有 ID 与包裹号）
+// @Tags packing
+// @Accept json
+// @Produce json
+// @Param id path int true "出库单ID"
+// @Param request body dto.CartonizeRequest true "装箱参数"
+// @Success 200 {object} dto.CommonResponse{data=dto.ListResponse{items=[]model.Package}}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 404 {object} dto.CommonResponse "出库单不存在"
+// @Failure 409 {object} dto.CommonResponse "出库单状态不允许装箱，或物料无法自动装箱"
+// @Router /api/wms/outbound/orders/{id}/cartonize [post]
+func (h *PackingHandler) Cartonize(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.CartonizeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	packages, err := h.service.Cartonize(id, service.CartonizeInput{
+		PackStation: req.PackStation,
+		PackedBy:    req.PackedBy,
+		DryRun:      req.DryRun,
+	})
+	if err != nil {
+		respondError(c, "Failed to cartonize outbound order", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(dto.ListResponse{
+		Items: packages,
+		Count: len(packages),
+	}))
+}
+
+// PackCarton 手工装箱
+// @Summary 手工装箱
+// @Description 包装台将指定单据行的已拣数量装入一个指定箱型的包裹，装箱数量不能超过该行已拣未装箱的数量。全部已拣数量装箱后出库单变为 packed
+// @Tags packing
+// @Accept json
+// @Produce json
+// @Param id path int true "出库单ID"
+// @Param request body dto.PackRequest true "装箱明细"
+// @Success 200 {object} dto.CommonResponse{data=model.Package}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 404 {object} dto.CommonResponse "出库单、单据行或箱型不存在"
+// @Failure 409 {object} dto.CommonResponse "出库单状态不允许装箱"
+// @Router /api/wms/outbound/orders/{id}/packages [post]
+func (h *PackingHandler) PackCarton(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.PackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	lines := make([]service.PackLineInput, 0, len(req.Lines))
+	for _, line := range req.Lines {
+		lines = append(lines, service.PackLineInput{
+			LineID:   line.LineID,
+			Quantity: line.Quantity,
+		})
+	}
+
+	pkg, err := h.service.PackCarton(id, service.PackInput{
+		CartonTypeCode: req.CartonTypeCode,
+		PackStation:    req.PackStation,
+		PackedBy:       req.PackedBy,
+		Lines:          lines,
+	})
+	if err != nil {
+		respondError(c, "Failed to pack carton", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(pkg))
+}
+
+// ListPackages 查询出库单的包裹
+// @Summary 查询出库单的包裹
+// @Tags packing
+// @Produce json
+// @Param id path int true "出库单ID"
+// @Success 200 {object} dto.CommonResponse{data=dto.ListResponse{items=[]model.Package}}
+// @Failure 404 {object} dto.CommonResponse "出库单不存在"
+// @Router /api/wms/outbound/orders/{id}/packages [get]
+func (h *PackingHandler) ListPackages(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	packages, err := h.service.ListPackages(id)
+	if err != nil {
+		respondError(c, "Failed to list packages", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(dto.ListResponse{
+		Items: packages,
+		Count: len(packages),
+	}))
+}
+
+// Unpack 拆除包裹
+// @Summary 拆除包裹
+// @Description 删除尚未发运的包裹，包裹内数量恢复为已拣未装箱
+// @Tags packing
+// @Produce json
+// @Param id path int true "包裹ID"
+// @Success 200 {object} dto.CommonResponse
+// @Failure 404 {object} dto.CommonResponse "包裹不存在"
+// @Failure 409 {object} dto.CommonResponse "包裹已发运"
+// @Router /api/wms/outbound/packages/{id} [delete]
+func (h *PackingHandler) Unpack(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.Unpack(id); err != nil {
+		respondError(c, "Failed to unpack package", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse())
+}
+
+// ShipOrder 发运确认
+// @Summary 发运确认
+// @Description 已全部装箱（packed）的出库单确认发运：从包装暂存库位扣减在库与预留数量、记录 ship 流水、关闭出库单（shipped），返回发运清单
+// @Tags packing
+// @Accept json
+// @Produce json
+// @Param id path int true "出库单ID"
+// @Param request body dto.ShipRequest true "发运信息"
+// @Success 200 {object} dto.CommonResponse{data=service.ShipmentManifest}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 404 {object} dto.CommonResponse "出库单不存在"
+// @Failure 409 {object} dto.CommonResponse "出库单尚未全部装箱或已发运"
+// @Router /api/wms/outbound/orders/{id}/ship [post]
+func (h *PackingHandler) ShipOrder(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.ShipRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	manifest, err := h.service.ShipOrder(id, service.ShipInput{
+		TrackingNo: req.TrackingNo,
+		ShippedBy:  req.ShippedBy,
+	})
+	if err != nil {
+		respondError(c, "Failed to ship outbound order", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(manifest))
+}
+
+// GetManifest 查询发运清单
+// @Summary 查询发运清单
+// @Tags packing
+// @Produce json
+// @Param id path int true "出库单ID"
+// @Success 200 {object} dto.CommonResponse{data=service.ShipmentManifest}
+// @Failure 404 {object} dto.CommonResponse "出库单不存在或尚未发运"
+// @Router /api/wms/outbound/orders/{id}/manifest [get]
+func (h *PackingHandler) GetManifest(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	manifest, err := h.service.GetManifest(id)
+	if err != nil {
+		respondError(c, "Failed to get shipment manifest", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(manifest))
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wms/internal/service"
+	"wms/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mockPackingService 是用于测试的包装服务模拟实现
+type mockPackingService struct {
+	service.PackingService
+	shipFunc func(orderID uint, input service.ShipInput) (*service.ShipmentManifest, error)
+}
+
+func (m *mockPackingService) ShipOrder(orderID uint, input service.ShipInput) (*service.ShipmentManifest, error) {
+	return m.shipFunc(orderID, input)
+}
+
+func setupPackingRouter(handler *PackingHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/wms/outbound/orders/:id/ship", handler.ShipOrder)
+	router.POST("/api/wms/outbound/orders/:id/packages", handler.PackCarton)
+	return router
+}
+
+func TestShipOrder_ReturnsManifest(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	var capturedID uint
+	router := setupPackingRouter(NewPackingHandler(&mockPackingService{
+		shipFunc: func(orderID uint, input service.ShipInput) (*service.ShipmentManifest, error) {
+			capturedID = orderID
+			return &service.ShipmentManifest{
+				OrderNo:       "SO-001",
+				TrackingNo:    input.TrackingNo,
+				ShippedBy:     input.ShippedBy,
+				PackageCount:  2,
+				TotalQuantity: 7,
+			}, nil
+		},
+	}, log))
+
+	body := []byte(`{"tracking_no":"SF123","shipped_by":"user1"}`)
+	req, _ := http.NewRequest("POST", "/api/wms/outbound/orders/5/ship", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if capturedID != 5 {
+		t.Errorf("Expected order ID 5, got %d", capturedID)
+	}
+	var response struct {
+		Data service.ShipmentManifest `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if response.Data.TrackingNo != "SF123" || response.Data.PackageCount != 2 || response.Data.TotalQuantity != 7 {
+		t.Errorf("Unexpected manifest in response: %+v", response.Data)
+	}
+}
+
+func TestShipOrder_NotPacked(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	router := setupPackingRouter(NewPackingHandler(&mockPackingService{
+		shipFunc: func(orderID uint, input service.ShipInput) (*service.ShipmentManifest, error) {
+			return nil, fmt.Errorf("%w: outbound order %d is picked, not packed", service.ErrInvalidState, orderID)
+		},
+	}, log))
+
+	body := []byte(`{"shipped_by":"user1"}`)
+	req, _ := http.NewRequest("POST", "/api/wms/outbound/orders/5/ship", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("Expected status 409, got %d", w.Code)
+	}
+}
+
+func TestPackCarton_RejectsEmptyLines(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	router := setupPackingRouter(NewPackingHandler(&mockPackingService{}, log))
+
+	body := []byte(`{"carton_type_code":"S","packed_by":"user1","lines":[]}`)
+	req, _ := http.NewRequest("POST", "/api/wms/outbound/orders/5/packages", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d", w.Code)
+	}
+}
@@ -0,0 +1,285 @@
+package handlers
+
+import (
+	"net/http"
+	"wms/internal/api/dto"
+	"wms/internal/repository"
+	"wms/internal/service"
+	"wms/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PutawayHandler 负责处理上架规则与上架任务相关的 HTTP 请求
+type PutawayHandler struct {
+	service service.PutawayService
+	logger  *logger.Logger
+}
+
+// NewPutawayHandler 创建一个新的 PutawayHandler 实例
+func NewPutawayHandler(service service.PutawayService, log *logger.Logger) *PutawayHandler {
+	return &PutawayHandler{
+		service: service,
+		logger:  log,
+	}
+}
+
+// GetPolicy 查询上架规则
+// @Summary 查询上架规则
+// @Tags putaway
+// @Produce json
+// @Success 200 {object} dto.CommonResponse{data=model.PutawayPolicy}
+// @Failure 500 {object} dto.CommonResponse "服务器内部错误"
+// @Router /api/wms/putaway/policy [get]
+func (h *PutawayHandler) GetPolicy(c *gin.Context) {
+	policy, err := h.service.GetPolicy()
+	if err != nil {
+		respondError(c, "Failed to get putaway policy", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(policy))
+}
+
+// UpdatePolicy 更新上架规则
+// @Summary 更新上架规则
+// @Description strategies 按优先级依次尝试：fixed_bin 物料固定库位、consolidate 与已有库存合并存放、nearest_empty 最近的空库位；enforce_capacity 为 true 时跳过并拒绝超出容量的库位
+// @Tags putaway
+// @Accept json
+// @Produce json
+// @Param request body dto.PutawayPolicyRequest true "上架规则"
+// @Success 200 {object} dto.CommonResponse{data=model.PutawayPolicy}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Router /api/wms/putaway/policy [put]
+func (h *PutawayHandler) UpdatePolicy(c *gin.Context) {
+	var req dto.PutawayPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	policy, err := h.service.UpdatePolicy(service.PutawayPolicyInput{
+		Strategies:      req.Strategies,
+		EnforceCapacity: req.EnforceCapacity,
+		UpdatedBy:       req.UpdatedBy,
+	})
+	if err != nil {
+		respondError(c, "Failed to update putaway policy", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(policy))
+}
+
+// ListFixedBins 查询物料固定库位
+// @Summary 查询物料固定库位
+// @Tags putaway
+// @Produce json
+// @Success 200 {object} dto.CommonResponse{data=dto.ListResponse}
+// @Failure 500 {object} dto.CommonResponse "服务器内部错误"
+// @Router /api/wms/putaway/fixed-bins [get]
+func (h *PutawayHandler) ListFixedBins(c *gin.Context) {
+	bins, err := h.service.ListFixedBins()
+	if err != nil {
+		respondError(c, "Failed to list fixed bins", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(dto.ListResponse{
+		Items: bins,
+		Count: len(bins),
+	}))
+}
+
+// UpsertFixedBin 设置物料固定库位
+// @Summary 设置物料固定库位
+// @Tags putaway
+// @Accept json
+// @Produce json
+// @Param request body dto.PutawayFixedBinRequest true "物料固定库位"
+// @Success 200 {object} dto.CommonResponse{data=model.PutawayFixedBin}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Router /api/wms/putaway/fixed-bins [put]
+func (h *PutawayHandler) UpsertFixedBin(c *gin.Context) {
+	var req dto.PutawayFixedBinRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	bin, err := h.service.UpsertFixedBin(req.MaterialCode, req.LocationCode)
+	if err != nil {
+		respondError(c, "Failed to save fixed bin", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(bin))
+}
+
+// CreateTask 手工创建上架任务
+// @Summary 创建上架任务
+// @Description 为已在来源库位的物料创建上架任务并按上架规则推荐目标库位；没有可用库位时目标库位为空，需改派后才能确认
+// @Tags putaway
+// @Accept json
+// @Produce json
+// @Param request body dto.PutawayTaskCreateRequest true "上架任务"
+// @Success 200 {object} dto.CommonResponse{data=model.PutawayTask}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Router /api/wms/putaway/tasks [post]
+func (h *PutawayHandler) CreateTask(c *gin.Context) {
+	var req dto.PutawayTaskCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	task, err := h.service.CreateTask(service.PutawayTaskInput{
+		MaterialCode:   req.MaterialCode,
+		LotNumber:      req.LotNumber,
+		SourceLocation: req.SourceLocation,
+		Quantity:       req.Quantity,
+		CreatedBy:      req.CreatedBy,
+	})
+	if err != nil {
+		respondError(c, "Failed to create putaway task", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(task))
+}
+
+// ListTasks 查询上架任务列表
+// @Summary 查询上架任务列表
+// @Tags putaway
+// @Produce json
+// @Param status query string false "状态：open、completed"
+// @Param assigned_to query string false "作业人员"
+// @Param material_code query string false "物料编码"
+// @Success 200 {object} dto.CommonResponse{data=dto.ListResponse}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 500 {object} dto.CommonResponse "服务器内部错误"
+// @Router /api/wms/putaway/tasks [get]
+func (h *PutawayHandler) ListTasks(c *gin.Context) {
+	var req dto.PutawayTaskListQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	tasks, err := h.service.ListTasks(repository.PutawayTaskFilter{
+		Status:       req.Status,
+		AssignedTo:   req.AssignedTo,
+		MaterialCode: req.MaterialCode,
+	})
+	if err != nil {
+		respondError(c, "Failed to list putaway tasks", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(dto.ListResponse{
+		Items: tasks,
+		Count: len(tasks),
+	}))
+}
+
+// AssignTask 指派上架任务
+// @Summary 指派上架任务
+// @Tags putaway
+// @Accept json
+// @Produce json
+// @Param id path int true "上架任务ID"
+// @Param request body dto.PutawayAssignRequest true "作业人员"
+// @Success 200 {object} dto.CommonResponse{data=model.PutawayTask}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 404 {object} dto.CommonResponse "任务不存在"
+// @Failure 409 {object} dto.CommonResponse "任务已完成"
+// @Router /api/wms/putaway/tasks/{id}/assign [post]
+func (h *PutawayHandler) AssignTask(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req dto.PutawayAssignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	task, err := h.service.AssignTask(id, req.AssignedTo)
+	if err != nil {
+		respondError(c, "Failed to assign putaway task", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(task))
+}
+
+// OverrideTask 改派上架库位
+// @Summary 改派上架库位
+// @Description 确认前将目标库位改为 location_code，须填写原因；推荐库位保留在 suggested_location 中
+// @Tags putaway
+// @Accept json
+// @Produce json
+// @Param id path int true "上架任务ID"
+// @Param request body dto.PutawayOverrideRequest true "目标库位与原因"
+// @Success 200 {object} dto.CommonResponse{data=model.PutawayTask}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效或库位不允许上架"
+// @Failure 404 {object} dto.CommonResponse "任务不存在"
+// @Failure 409 {object} dto.CommonResponse "任务已完成"
+// @Router /api/wms/putaway/tasks/{id}/override [post]
+func (h *PutawayHandler) OverrideTask(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req dto.PutawayOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	task, err := h.service.OverrideTask(id, service.PutawayOverrideInput{
+		LocationCode: req.LocationCode,
+		OperatorID:   req.OperatorID,
+		Reason:       req.Reason,
+	})
+	if err != nil {
+		respondError(c, "Failed to override putaway task", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(task))
+}
+
+// ConfirmTask 确认上架
+// @Summary 确认上架
+// @Description 在同一事务中扣减来源库位库存并增加目标库位库存；来源库位库存不足、任务没有目标库位或目标库位容量不足返回 409
+// @Tags putaway
+// @Accept json
+// @Produce json
+// @Param id path int true "上架任务ID"
+// @Param request body dto.PutawayConfirmRequest true "操作人"
+// @Success 200 {object} dto.CommonResponse{data=model.PutawayTask}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效或任务已指派给其他人"
+// @Failure 404 {object} dto.CommonResponse "任务不存在"
+// @Failure 409 {object} dto.CommonResponse "任务状态、库存或容量不允许上架"
+// @Router /api/wms/putaway/tasks/{id}/confirm [post]
+func (h *PutawayHandler) ConfirmTask(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req dto.PutawayConfirmRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	task, err := h.service.ConfirmTask(id, req.OperatorID)
+	if err != nil {
+		respondError(c, "Failed to confirm putaway task", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(task))
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wms/internal/api/dto"
+	"wms/internal/model"
+	"wms/internal/service"
+	"wms/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mockPutawayService 是用于测试的上架服务模拟实现
+type mockPutawayService struct {
+	service.PutawayService
+	confirmFunc func(id uint, operatorID string) (*model.PutawayTask, error)
+}
+
+func (m *mockPutawayService) ConfirmTask(id uint, operatorID string) (*model.PutawayTask, error) {
+	return m.confirmFunc(id, operatorID)
+}
+
+func setupPutawayRouter(handler *PutawayHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/wms/putaway/tasks/:id/confirm", handler.ConfirmTask)
+	return router
+}
+
+func TestConfirmPutawayTask_PassesOperator(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	var gotID uint
+	var gotOperator string
+	router := setupPutawayRouter(NewPutawayHandler(&mockPutawayService{
+		confirmFunc: func(id uint, operatorID string) (*model.PutawayTask, error) {
+			gotID, gotOperator = id, operatorID
+			return &model.PutawayTask{ID: id, Status: model.PutawayTaskStatusCompleted}, nil
+		},
+	}, log))
+
+	body, _ := json.Marshal(dto.PutawayConfirmRequest{OperatorID: "op1"})
+	req, _ := http.NewRequest("POST", "/api/wms/putaway/tasks/7/confirm", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if gotID != 7 || gotOperator != "op1" {
+		t.Errorf("Expected task 7 confirmed by op1, got %d by %s", gotID, gotOperator)
+	}
+}
+
+func TestConfirmPutawayTask_CapacityConflict(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	router := setupPutawayRouter(NewPutawayHandler(&mockPutawayService{
+		confirmFunc: func(id uint, operatorID string) (*model.PutawayTask, error) {
+			return nil, fmt.Errorf("%w: location A-01 is full", service.ErrInvalidState)
+		},
+	}, log))
+
+	body, _ := json.Marshal(dto.PutawayConfirmRequest{OperatorID: "op1"})
+	req, _ := http.NewRequest("POST", "/api/wms/putaway/tasks/7/confirm", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("Expected status 409, got %d", w.Code)
+	}
+	var response dto.CommonResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if response.ErrorCode != dto.ErrCodeInvalidState {
+		t.Errorf("Expected error code %s, got %s", dto.ErrCodeInvalidState, response.ErrorCode)
+	}
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"net/http"
+	"wms/internal/api/dto"
+	"wms/internal/service"
+	"wms/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReasonHandler 负责处理盘点差异原因代码相关的 HTTP 请求
+type ReasonHandler struct {
+	service service.VarianceReasonService
+	logger  *logger.Logger
+}
+
+// NewReasonHandler 创建一个新的 ReasonHandler 实例
+func NewReasonHandler(service service.VarianceReasonService, log *logger.Logger) *ReasonHandler {
+	return &ReasonHandler{
+		service: service,
+		logger:  log,
+	}
+}
+
+// ListReasons 查询差异原因代码
+// @Summary 查询差异原因代码
+// @Tags inventory
+// @Produce json
+// @Param active_only query bool false "只返回启用的原因"
+// @Success 200 {object} dto.CommonResponse{data=dto.ListResponse}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 500 {object} dto.CommonResponse "服务器内部错误"
+// @Router /api/wms/inventory/reasons [get]
+func (h *ReasonHandler) ListReasons(c *gin.Context) {
+	var req dto.VarianceReasonListQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	reasons, err := h.service.ListReasons(req.ActiveOnly)
+	if err != nil {
+		respondError(c, "Failed to list reasons", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(dto.ListResponse{
+		Items: reasons,
+		Count: len(reasons),
+	}))
+}
+
+// UpsertReason 新增或更新差异原因代码
+// @Summary 保存差异原因代码
+// @Description 按 code 新增或覆盖原因；停用（active=false）的原因不能再用于新的盘点
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Param request body dto.VarianceReasonRequest true "原因代码"
+// @Success 200 {object} dto.CommonResponse{data=model.VarianceReason}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Router /api/wms/inventory/reasons [put]
+func (h *ReasonHandler) UpsertReason(c *gin.Context) {
+	var req dto.VarianceReasonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	reason, err := h.service.UpsertReason(service.VarianceReasonInput{
+		Code:            req.Code,
+		Description:     req.Description,
+		PostingCategory: req.PostingCategory,
+		Direction:       req.Direction,
+		Active:          req.Active,
+	})
+	if err != nil {
+		respondError(c, "Failed to save reason", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(reason))
+}
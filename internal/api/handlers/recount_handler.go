@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"net/http"
+	"wms/internal/api/dto"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ListRecountTasks 查询复盘任务
+// @Summary 查询复盘任务
+// @Description 超出容差的盘点会生成复盘任务，复盘人通过盘点上传接口携带 recount_task_id 提交复盘
+// @Tags inventory
+// @Produce json
+// @Param status query string false "状态：open、completed、cancelled"
+// @Param assigned_to query string false "复盘人ID"
+// @Success 200 {object} dto.CommonResponse{data=dto.ListResponse}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 500 {object} dto.CommonResponse "服务器内部错误"
+// @Router /api/wms/inventory/check/recounts [get]
+func (h *InventoryHandler) ListRecountTasks(c *gin.Context) {
+	var req dto.RecountTaskListQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	tasks, err := h.service.ListRecountTasks(req.Status, req.AssignedTo)
+	if err != nil {
+		respondError(c, "Failed to list recount tasks", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(dto.ListResponse{
+		Items: tasks,
+		Count: len(tasks),
+	}))
+}
+
+// AssignRecountTask 指派复盘任务
+// @Summary 指派复盘任务
+// @Description 将未完成的复盘任务指派给盘点人，盘点人不能是复盘链中已参与过盘点的人
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Param id path int true "复盘任务ID"
+// @Param request body dto.RecountAssignRequest true "指派信息"
+// @Success 200 {object} dto.CommonResponse{data=model.RecountTask}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效或盘点人已参与过该复盘链"
+// @Failure 404 {object} dto.CommonResponse "复盘任务不存在"
+// @Failure 409 {object} dto.CommonResponse "复盘任务已关闭"
+// @Router /api/wms/inventory/check/recounts/{id}/assign [post]
+func (h *InventoryHandler) AssignRecountTask(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.RecountAssignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	task, err := h.service.AssignRecountTask(id, req.CheckerID)
+	if err != nil {
+		h.logger.Warn("Failed to assign recount task",
+			zap.Uint("recount_task_id", id),
+			zap.String("checker_id", req.CheckerID),
+			zap.Error(err),
+		)
+		respondError(c, "Failed to assign recount task", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(task))
+}
+
+// GetRecordChain 查询盘点记录的复盘链
+// @Summary 查询复盘链
+// @Description 返回记录所在复盘链上的全部盘点记录，按复盘序号排序，用于审计最终数量的确认过程
+// @Tags inventory
+// @Produce json
+// @Param id path int true "盘点记录ID"
+// @Success 200 {object} dto.CommonResponse{data=dto.ListResponse}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 404 {object} dto.CommonResponse "记录不存在"
+// @Router /api/wms/inventory/check/records/{id}/chain [get]
+func (h *InventoryHandler) GetRecordChain(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	records, err := h.service.GetRecordChain(id)
+	if err != nil {
+		respondError(c, "Failed to get recount chain", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(dto.ListResponse{
+		Items: records,
+		Count: len(records),
+	}))
+}
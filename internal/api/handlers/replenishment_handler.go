@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"net/http"
+	"wms/internal/api/dto"
+	"wms/internal/model"
+	"wms/internal/repository"
+	"wms/internal/service"
+	"wms/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReplenishmentHandler 负责处理拣货位补货水位与补货任务相关的 HTTP 请求
+type ReplenishmentHandler struct {
+	service service.ReplenishmentService
+	logger  *logger.Logger
+}
+
+// NewReplenishmentHandler 创建一个新的 ReplenishmentHandler 实例
+func NewReplenishmentHandler(service service.ReplenishmentService, log *logger.Logger) *ReplenishmentHandler {
+	return &ReplenishmentHandler{
+		service: service,
+		logger:  log,
+	}
+}
+
+// ListRules 查询补货水位
+// @Summary 查询补货水位
+// @Tags replenishment
+// @Produce json
+// @Success 200 {object} dto.CommonResponse{data=dto.ListResponse{items=[]model.ReplenishmentRule}}
+// @Failure 500 {object} dto.CommonResponse "服务器内部错误"
+// @Router /api/wms/replenishment/rules [get]
+func (h *ReplenishmentHandler) ListRules(c *gin.Context) {
+	rules, err := h.service.ListRules()
+	if err != nil {
+		respondError(c, "Failed to list replenishment rules", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(dto.ListResponse{
+		Items: rules,
+		Count: len(rules),
+	}))
+}
+
+// UpsertRule 新增或更新补货水位
+// @Summary 新增或更新补货水位
+// @Description 按物料与库位唯一，配置了水位的库位即为该物料的拣货位，不再作为其他拣货位的补货来源
+// @Tags replenishment
+// @Accept json
+// @Produce json
+// @Param request body dto.ReplenishmentRuleRequest true "补货水位"
+// @Success 200 {object} dto.CommonResponse{data=model.ReplenishmentRule}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Router /api/wms/replenishment/rules [put]
+func (h *ReplenishmentHandler) UpsertRule(c *gin.Context) {
+	var req dto.ReplenishmentRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	rule, err := h.service.UpsertRule(service.ReplenishmentRuleInput{
+		MaterialCode: req.MaterialCode,
+		LocationCode: req.LocationCode,
+		MinQuantity:  *req.MinQuantity,
+		MaxQuantity:  req.MaxQuantity,
+		Active:       req.Active,
+		UpdatedBy:    req.UpdatedBy,
+	})
+	if err != nil {
+		respondError(c, "Failed to save replenishment rule", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(rule))
+}
+
+// DeleteRule 删除补货水位
+// @Summary 删除补货水位
+// @Description 已生成的补货任务不受影响，仍可确认或取消
+// @Tags replenishment
+// @Produce json
+// @Param id path int true "补货水位ID"
+// @Success 200 {object} dto.CommonResponse
+// @Failure 404 {object} dto.CommonResponse "补货水位不存在"
+// @Router /api/wms/replenishment/rules/{id} [delete]
+func (h *ReplenishmentHandler) DeleteRule(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteRule(id); err != nil {
+		respondError(c, "Failed to delete replenishment rule", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse())
+}
+
+// Run 手工触发补货扫描
+// @Summary 手工触发补货扫描
+// @Description 拣货位在库数量加上未完成补货数量低于最小水位时，从存储库位按先进先出生成补货至最大水位的任务并预留来源库存；
+// @Description 收货暂存区等不允许上架的库位与其他拣货位不作为补货来源，存储库存不足时按可用数量尽量补货
+// @Tags replenishment
+// @Accept json
+// @Produce json
+// @Param request body dto.ReplenishmentRunRequest true "扫描范围"
+// @Success 200 {object} dto.CommonResponse{data=service.ReplenishmentRunResult}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Router /api/wms/replenishment/run [post]
+func (h *ReplenishmentHandler) Run(c *gin.Context) {
+	var req dto.ReplenishmentRunRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	result, err := h.service.Run(service.ReplenishmentRunInput{
+		MaterialCode: req.MaterialCode,
+		LocationCode: req.LocationCode,
+		CreatedBy:    req.CreatedBy,
+	}, model.ReplenishmentTriggerOnDemand)
+	if err != nil {
+		respondError(c, "Failed to run replenishment", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(result))
+}
+
+// ListTasks 查询补货任务
+// @Summary 查询补货任务
+// @Tags replenishment
+// @Produce json
+// @Param status query string false "状态：open、completed、cancelled"
+// @Param material_code query string false "物料编码"
+// @Param target_location query string false "拣货位"
+// @Param trigger query string false "触发来源：on_demand、scheduled、pick、stock_take"
+// @Success 200 {object} dto.CommonResponse{data=dto.ListResponse{items=[]model.ReplenishmentTask}}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Router /api/wms/replenishment/tasks [get]
+func (h *ReplenishmentHandler) ListTasks(c *gin.Context) {
+	var req dto.ReplenishmentTaskListQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	tasks, err := h.service.ListTasks(repository.ReplenishmentTaskFilter{
+		Status:         req.Status,
+		MaterialCode:   req.MaterialCode,
+		TargetLocation: req.TargetLocation,
+		Trigger:        req.Trigger,
+	})
+	if err != nil {
+		respondError(c, "Failed to list replenishment tasks", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(dto.ListResponse{
+		Items: tasks,
+		Count: len(tasks),
+	}))
+}
+
+// ConfirmTask 确认补货
+// @Summary 确认补货
+// @Description 在同一事务中将任务数量从来源库位移至拣货位（replenish 流水）并消耗来源库存的预留
+// @Tags replenishment
+// @Accept json
+// @Produce json
+// @Param id path int true "补货任务ID"
+// @Param request body dto.ReplenishmentConfirmRequest true "操作人"
+// @Success 200 {object} dto.CommonResponse{data=model.ReplenishmentTask}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 404 {object} dto.CommonResponse "任务不存在"
+// @Failure 409 {object} dto.CommonResponse "任务已完成或取消，或来源库存不足"
+// @Router /api/wms/replenishment/tasks/{id}/confirm [post]
+func (h *ReplenishmentHandler) ConfirmTask(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req dto.ReplenishmentConfirmRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	task, err := h.service.ConfirmTask(id, req.OperatorID)
+	if err != nil {
+		respondError(c, "Failed to confirm replenishment task", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(task))
+}
+
+// CancelTask 取消补货任务
+// @Summary 取消补货任务
+// @Description 释放来源库存的预留；下一次扫描或拣货后会按当时的水位重新生成任务
+// @Tags replenishment
+// @Accept json
+// @Produce json
+// @Param id path int true "补货任务ID"
+// @Param request body dto.ReplenishmentCancelRequest true "操作人与原因"
+// @Success 200 {object} dto.CommonResponse{data=model.ReplenishmentTask}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 404 {object} dto.CommonResponse "任务不存在"
+// @Failure 409 {object} dto.CommonResponse "任务已完成或取消"
+// @Router /api/wms/replenishment/tasks/{id}/cancel [post]
+func (h *ReplenishmentHandler) CancelTask(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req dto.ReplenishmentCancelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	task, err := h.service.CancelTask(id, req.OperatorID, req.Reason)
+	if err != nil {
+		respondError(c, "Failed to cancel replenishment task", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(task))
+}
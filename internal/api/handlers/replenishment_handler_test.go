@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wms/internal/model"
+	"wms/internal/service"
+	"wms/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mockReplenishmentService 是用于测试的补货服务模拟实现
+type mockReplenishmentService struct {
+	service.ReplenishmentService
+	runFunc     func(input service.ReplenishmentRunInput, trigger string) (*service.ReplenishmentRunResult, error)
+	confirmFunc func(id uint, operatorID string) (*model.ReplenishmentTask, error)
+}
+
+func (m *mockReplenishmentService) Run(input service.ReplenishmentRunInput, trigger string) (*service.ReplenishmentRunResult, error) {
+	return m.runFunc(input, trigger)
+}
+
+func (m *mockReplenishmentService) ConfirmTask(id uint, operatorID string) (*model.ReplenishmentTask, error) {
+	return m.confirmFunc(id, operatorID)
+}
+
+func setupReplenishmentRouter(handler *ReplenishmentHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.PUT("/api/wms/replenishment/rules", handler.UpsertRule)
+	router.POST("/api/wms/replenishment/run", handler.Run)
+	router.POST("/api/wms/replenishment/tasks/:id/confirm", handler.ConfirmTask)
+	return router
+}
+
+func TestRunReplenishment_OnDemandTrigger(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	var captured service.ReplenishmentRunInput
+	var trigger string
+	router := setupReplenishmentRouter(NewReplenishmentHandler(&mockReplenishmentService{
+		runFunc: func(input service.ReplenishmentRunInput, tr string) (*service.ReplenishmentRunResult, error) {
+			captured, trigger = input, tr
+			return &service.ReplenishmentRunResult{Checked: 1}, nil
+		},
+	}, log))
+
+	body := []byte(`{"location_code":"P-01","created_by":"user1"}`)
+	req, _ := http.NewRequest("POST", "/api/wms/replenishment/run", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if trigger != model.ReplenishmentTriggerOnDemand || captured.LocationCode != "P-01" || captured.CreatedBy != "user1" {
+		t.Errorf("Unexpected run passed to service: %+v, trigger %s", captured, trigger)
+	}
+}
+
+func TestUpsertReplenishmentRule_RequiresMin(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	router := setupReplenishmentRouter(NewReplenishmentHandler(&mockReplenishmentService{}, log))
+
+	body := []byte(`{"material_code":"M-001","location_code":"P-01","max_quantity":50}`)
+	req, _ := http.NewRequest("PUT", "/api/wms/replenishment/rules", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestConfirmReplenishment_TaskClosed(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	router := setupReplenishmentRouter(NewReplenishmentHandler(&mockReplenishmentService{
+		confirmFunc: func(id uint, operatorID string) (*model.ReplenishmentTask, error) {
+			return nil, fmt.Errorf("%w: replenishment task %d is cancelled", service.ErrInvalidState, id)
+		},
+	}, log))
+
+	body := []byte(`{"operator_id":"user1"}`)
+	req, _ := http.NewRequest("POST", "/api/wms/replenishment/tasks/7/confirm", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("Expected status 409, got %d", w.Code)
+	}
+}
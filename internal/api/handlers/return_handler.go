@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"net/http"
+	"wms/internal/api/dto"
+	"wms/internal/repository"
+	"wms/internal/service"
+	"wms/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReturnHandler 负责处理客户退货授权（RMA）与退货收货相关的 HTTP 请求
+type ReturnHandler struct {
+	service service.ReturnService
+	logger  *logger.Logger
+}
+
+// NewReturnHandler 创建一个新的 ReturnHandler 实例
+func NewReturnHandler(service service.ReturnService, log *logger.Logger) *ReturnHandler {
+	return &ReturnHandler{
+		service: service,
+		logger:  log,
+	}
+}
+
+// CreateReturn 创建退货授权
+// @Summary 创建退货授权
+// @Description 根据原出库单据的发运记录创建 RMA，每行授权数量合计（不含已取消的 RMA）不能超过原单据行的已发运数量
+// @Tags returns
+// @Accept json
+// @Produce json
+// @Param request body dto.ReturnOrderCreateRequest true "退货授权"
+// @Success 200 {object} dto.CommonResponse{data=model.ReturnOrder}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效、RMA 号重复或超出可退数量"
+// @Failure 404 {object} dto.CommonResponse "发运记录不存在"
+// @Failure 409 {object} dto.CommonResponse "原单据尚未发运"
+// @Router /api/wms/returns [post]
+func (h *ReturnHandler) CreateReturn(c *gin.Context) {
+	var req dto.ReturnOrderCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	lines := make([]service.ReturnLineInput, 0, len(req.Lines))
+	for _, line := range req.Lines {
+		lines = append(lines, service.ReturnLineInput{
+			LineNo:   line.LineNo,
+			Quantity: line.Quantity,
+		})
+	}
+	rma, err := h.service.CreateReturn(service.ReturnOrderInput{
+		RMANo:      req.RMANo,
+		ShipmentID: req.ShipmentID,
+		Reason:     req.Reason,
+		CreatedBy:  req.CreatedBy,
+		Lines:      lines,
+	})
+	if err != nil {
+		respondError(c, "Failed to create return order", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(rma))
+}
+
+// ListReturns 查询退货单据列表
+// @Summary 查询退货单据列表
+// @Tags returns
+// @Produce json
+// @Param status query string false "状态：open、receiving、completed、cancelled"
+// @Param order_id query int false "原出库单据ID"
+// @Param customer_code query string false "客户代码"
+// @Success 200 {object} dto.CommonResponse{data=dto.ListResponse{items=[]model.ReturnOrder}}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Router /api/wms/returns [get]
+func (h *ReturnHandler) ListReturns(c *gin.Context) {
+	var req dto.ReturnOrderListQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	returns, err := h.service.ListReturns(repository.ReturnOrderFilter{
+		Status:       req.Status,
+		OrderID:      req.OrderID,
+		CustomerCode: req.CustomerCode,
+	})
+	if err != nil {
+		respondError(c, "Failed to list return orders", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(dto.ListResponse{
+		Items: returns,
+		Count: len(returns),
+	}))
+}
+
+// GetReturn 查询退货单据详情
+// @Summary 查询退货单据详情
+// @Tags returns
+// @Produce json
+// @Param id path int true "退货单据ID"
+// @Success 200 {object} dto.CommonResponse{data=model.ReturnOrder}
+// @Failure 404 {object} dto.CommonResponse "单据不存在"
+// @Router /api/wms/returns/{id} [get]
+func (h *ReturnHandler) GetReturn(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	rma, err := h.service.GetReturn(id)
+	if err != nil {
+		respondError(c, "Failed to get return order", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(rma))
+}
+
+// ReceiveReturn 退货收货检验
+// @Summary 退货收货检验
+// @Description 逐行检验退回的货物并给出处置方式：restock 过账到退货暂存库位并生成上架任务，quarantine、scrap、return_to_vendor 过账到对应库位（须登记为不允许上架，不参与出库分配）；每条收货写一条 return 流水
+// @Tags returns
+// @Accept json
+// @Produce json
+// @Param id path int true "退货单据ID"
+// @Param request body dto.ReturnReceiveRequest true "收货检验结果"
+// @Success 200 {object} dto.CommonResponse{data=service.ReturnReceiveResult}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效或超出授权数量"
+// @Failure 404 {object} dto.CommonResponse "单据不存在"
+// @Failure 409 {object} dto.CommonResponse "单据已完成或取消，或处置库位未登记为不允许上架"
+// @Router /api/wms/returns/{id}/receive [post]
+func (h *ReturnHandler) ReceiveReturn(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req dto.ReturnReceiveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	lines := make([]service.ReturnReceiveLineInput, 0, len(req.Lines))
+	for _, line := range req.Lines {
+		lines = append(lines, service.ReturnReceiveLineInput{
+			LineNo:         line.LineNo,
+			LotNumber:      line.LotNumber,
+			Quantity:       line.Quantity,
+			SerialNumbers:  line.SerialNumbers,
+			Disposition:    line.Disposition,
+			LocationCode:   line.LocationCode,
+			InspectionNote: line.InspectionNote,
+		})
+	}
+	result, err := h.service.ReceiveReturn(id, service.ReturnReceiveInput{
+		ReceivedBy: req.ReceivedBy,
+		Lines:      lines,
+	})
+	if err != nil {
+		respondError(c, "Failed to receive return order", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(result))
+}
+
+// CancelReturn 取消退货授权
+// @Summary 取消退货授权
+// @Description 只能取消尚未收货的 RMA，取消后其授权数量不再占用原单据行的可退数量
+// @Tags returns
+// @Accept json
+// @Produce json
+// @Param id path int true "退货单据ID"
+// @Param request body dto.ReturnCancelRequest true "操作人与原因"
+// @Success 200 {object} dto.CommonResponse{data=model.ReturnOrder}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 409 {object} dto.CommonResponse "单据已收货或已结束"
+// @Router /api/wms/returns/{id}/cancel [post]
+func (h *ReturnHandler) CancelReturn(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req dto.ReturnCancelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	rma, err := h.service.CancelReturn(id, req.OperatorID, req.Reason)
+	if err != nil {
+		respondError(c, "Failed to cancel return order", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(rma))
+}
+
+// ListReceipts 查询退货单据的收货记录
+// @Summary 查询退货收货记录
+// @Tags returns
+// @Produce json
+// @Param id path int true "退货单据ID"
+// @Success 200 {object} dto.CommonResponse{data=dto.ListResponse{items=[]model.ReturnReceipt}}
+// @Failure 404 {object} dto.CommonResponse "单据不存在"
+// @Router /api/wms/returns/{id}/receipts [get]
+func (h *ReturnHandler) ListReceipts(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	receipts, err := h.service.ListReceipts(id)
+	if err != nil {
+		respondError(c, "Failed to list return receipts", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(dto.ListResponse{
+		Items: receipts,
+		Count: len(receipts),
+	}))
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wms/internal/model"
+	"wms/internal/service"
+	"wms/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mockReturnService 是用于测试的退货服务模拟实现
+type mockReturnService struct {
+	service.ReturnService
+	receiveFunc func(id uint, input service.ReturnReceiveInput) (*service.ReturnReceiveResult, error)
+}
+
+func (m *mockReturnService) ReceiveReturn(id uint, input service.ReturnReceiveInput) (*service.ReturnReceiveResult, error) {
+	return m.receiveFunc(id, input)
+}
+
+func setupReturnRouter(handler *ReturnHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/wms/returns/:id/receive", handler.ReceiveReturn)
+	return router
+}
+
+func TestReceiveReturn_PassesDispositions(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	var captured service.ReturnReceiveInput
+	router := setupReturnRouter(NewReturnHandler(&mockReturnService{
+		receiveFunc: func(id uint, input service.ReturnReceiveInput) (*service.ReturnReceiveResult, error) {
+			captured = input
+			return &service.ReturnReceiveResult{Return: &model.ReturnOrder{ID: id, Status: model.ReturnStatusCompleted}}, nil
+		},
+	}, log))
+
+	body := []byte(`{"received_by":"user1","lines":[{"line_no":1,"quantity":2,"disposition":"restock"},{"line_no":1,"quantity":1,"disposition":"scrap","inspection_note":"cracked"}]}`)
+	req, _ := http.NewRequest("POST", "/api/wms/returns/3/receive", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(captured.Lines) != 2 || captured.Lines[1].Disposition != model.DispositionScrap || captured.Lines[1].InspectionNote != "cracked" {
+		t.Errorf("Unexpected input passed to service: %+v", captured)
+	}
+}
+
+func TestReceiveReturn_RejectsUnknownDisposition(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	router := setupReturnRouter(NewReturnHandler(&mockReturnService{}, log))
+
+	body := []byte(`{"received_by":"user1","lines":[{"line_no":1,"quantity":1,"disposition":"resell"}]}`)
+	req, _ := http.NewRequest("POST", "/api/wms/returns/3/receive", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestReceiveReturn_HoldLocationNotRegistered(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	router := setupReturnRouter(NewReturnHandler(&mockReturnService{
+		receiveFunc: func(id uint, input service.ReturnReceiveInput) (*service.ReturnReceiveResult, error) {
+			return nil, fmt.Errorf("%w: location QUARANTINE must be registered with putaway disabled to hold quarantine stock", service.ErrInvalidState)
+		},
+	}, log))
+
+	body := []byte(`{"received_by":"user1","lines":[{"line_no":1,"quantity":1,"disposition":"quarantine"}]}`)
+	req, _ := http.NewRequest("POST", "/api/wms/returns/3/receive", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("Expected status 409, got %d", w.Code)
+	}
+}
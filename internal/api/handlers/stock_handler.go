@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"net/http"
+	"wms/internal/api/dto"
+	"wms/internal/repository"
+	"wms/internal/service"
+	"wms/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StockHandler 负责处理库存及库存流水相关的 HTTP 请求
+type StockHandler struct {
+	service service.StockService
+	logger  *logger.Logger
+}
+
+// NewStockHandler 创建一个新的 StockHandler 实例
+func NewStockHandler(service service.StockService, log *logger.Logger) *StockHandler {
+	return &StockHandler{
+		service: service,
+		logger:  log,
+	}
+}
+
+// ListStocks 查询库存余额
+// @Summary 查询库存余额
+// @Description 返回每个 (物料, 库位, 批次) 的在库数量 quantity、已分配给出库单的 reserved_quantity 与可分配数量 available_quantity
+// @Tags stock
+// @Produce json
+// @Param material_code query string false "物料代码"
+// @Param location_code query string false "库位代码"
+// @Param lot_number query string false "批次号"
+// @Success 200 {object} dto.CommonResponse{data=dto.ListResponse}
+// @Failure 500 {object} dto.CommonResponse "服务器内部错误"
+// @Router /api/wms/stock [get]
+func (h *StockHandler) ListStocks(c *gin.Context) {
+	var req dto.StockListQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	balances, err := h.service.ListStocks(repository.StockFilter{
+		MaterialCode: req.MaterialCode,
+		LocationCode: req.LocationCode,
+		LotNumber:    req.LotNumber,
+	})
+	if err != nil {
+		respondError(c, "Failed to list stocks", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(dto.ListResponse{
+		Items: balances,
+		Count: len(balances),
+	}))
+}
+
+// TraceLot 追溯批次
+// @Summary 追溯批次
+// @Description 返回批次当前所在库位与数量、来源（收货、退货、期初余额）、去向（发运）与盘点调整；库内搬运不改变批次总量，不单独列出
+// @Tags stock
+// @Produce json
+// @Param lot_number path string true "批次号"
+// @Param material_code query string false "物料代码"
+// @Success 200 {object} dto.CommonResponse{data=service.LotTrace}
+// @Failure 404 {object} dto.CommonResponse "批次不存在"
+// @Router /api/wms/stock/lots/{lot_number} [get]
+func (h *StockHandler) TraceLot(c *gin.Context) {
+	var req dto.LotTraceQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	trace, err := h.service.TraceLot(req.MaterialCode, c.Param("lot_number"))
+	if err != nil {
+		respondError(c, "Failed to trace lot", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(trace))
+}
+
+// ListSerials 查询序列号
+// @Summary 查询序列号
+// @Description 返回启用序列号管理的物料逐件登记的序列号：在库（in_stock）时给出所在库位与批次，拣货后记录所属出库单据，发运后为 shipped，盘点未找到为 missing
+// @Tags stock
+// @Produce json
+// @Param material_code query string false "物料代码"
+// @Param serial_number query string false "序列号"
+// @Param location_code query string false "库位代码"
+// @Param lot_number query string false "批次号"
+// @Param status query string false "状态：in_stock、shipped、missing"
+// @Success 200 {object} dto.CommonResponse{data=dto.ListResponse}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 500 {object} dto.CommonResponse "服务器内部错误"
+// @Router /api/wms/stock/serials [get]
+func (h *StockHandler) ListSerials(c *gin.Context) {
+	var req dto.SerialListQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	serials, err := h.service.ListSerials(repository.SerialFilter{
+		MaterialCode: req.MaterialCode,
+		SerialNumber: req.SerialNumber,
+		LocationCode: req.LocationCode,
+		LotNumber:    req.LotNumber,
+		Status:       req.Status,
+	})
+	if err != nil {
+		respondError(c, "Failed to list serial numbers", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(dto.ListResponse{
+		Items: serials,
+		Count: len(serials),
+	}))
+}
+
+// ExpiringStocks 查询效期预警
+// @Summary 查询效期预警
+// @Description 按库位汇总 days 天内到期及已过期的在库库存；已过期库存不可分配与拣货，可移库或盘点
+// @Tags stock
+// @Produce json
+// @Param days query int false "预警天数，默认 30"
+// @Success 200 {object} dto.CommonResponse{data=service.ExpiryReport}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 500 {object} dto.CommonResponse "服务器内部错误"
+// @Router /api/wms/stock/expiring [get]
+func (h *StockHandler) ExpiringStocks(c *gin.Context) {
+	var req dto.ExpiryReportQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	days := service.DefaultExpiryAlertDays
+	if req.Days != nil {
+		days = *req.Days
+	}
+	report, err := h.service.ExpiryReport(days)
+	if err != nil {
+		respondError(c, "Failed to build expiry report", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(report))
+}
+
+// ListMovements 查询库存流水
+// @Summary 查询库存流水
+// @Description 按物料、库位、流水类型与时间区间查询库存流水，按时间倒序游标分页
+// @Tags stock
+// @Produce json
+// @Param material_code query string false "物料代码"
+// @Param location_code query string false "库位代码"
+// @Param lot_number query string false "批次号"
+// @Param movement_type query string false "流水类型"
+// @Param from query string false "起始时间（RFC3339，含）"
+// @Param to query string false "截止时间（RFC3339，不含）"
+// @Param cursor query string false "上一页返回的 next_cursor"
+// @Param limit query int false "每页数量，默认 50，最大 200"
+// @Success 200 {object} dto.CommonResponse{data=dto.ListResponse}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 500 {object} dto.CommonResponse "服务器内部错误"
+// @Router /api/wms/stock/movements [get]
+func (h *StockHandler) ListMovements(c *gin.Context) {
+	var req dto.MovementListQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	page, err := h.service.ListMovements(service.MovementQuery{
+		MaterialCode: req.MaterialCode,
+		LocationCode: req.LocationCode,
+		LotNumber:    req.LotNumber,
+		MovementType: req.MovementType,
+		From:         req.From,
+		To:           req.To,
+		Cursor:       req.Cursor,
+		Limit:        req.Limit,
+	})
+	if err != nil {
+		respondError(c, "Failed to list stock movements", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(dto.ListResponse{
+		Items:      page.Movements,
+		Count:      len(page.Movements),
+		HasMore:    page.HasMore,
+		NextCursor: page.NextCursor,
+	}))
+}
@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"net/http"
+	"wms/internal/api/dto"
+	"wms/internal/model"
+	"wms/internal/service"
+	"wms/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// StockTakeHandler 负责处理盘点单相关的 HTTP 请求
+type StockTakeHandler struct {
+	service service.StockTakeService
+	logger  *logger.Logger
+}
+
+// NewStockTakeHandler 创建一个新的 StockTakeHandler 实例
+func NewStockTakeHandler(service service.StockTakeService, log *logger.Logger) *StockTakeHandler {
+	return &StockTakeHandler{
+		service: service,
+		logger:  log,
+	}
+}
+
+// CreateStockTake 创建盘点单
+// @Summary 创建盘点单
+// @Description 创建草稿状态的盘点单，指定盘点范围（库位/物料）与盘点人
+// @Tags stocktake
+// @Accept json
+// @Produce json
+// @Param request body dto.StockTakeCreateRequest true "盘点单信息"
+// @Success 200 {object} dto.CommonResponse{data=model.StockTake}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 500 {object} dto.CommonResponse "服务器内部错误"
+// @Router /api/wms/inventory/stocktakes [post]
+func (h *StockTakeHandler) CreateStockTake(c *gin.Context) {
+	var req dto.StockTakeCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	stockTake, err := h.service.CreateStockTake(service.StockTakeInput{
+		Name:          req.Name,
+		Description:   req.Description,
+		CreatedBy:     req.CreatedBy,
+		LocationCodes: req.LocationCodes,
+		MaterialCodes: req.MaterialCodes,
+		CheckerIDs:    req.CheckerIDs,
+		BlindCount:    req.BlindCount,
+	})
+	if err != nil {
+		respondError(c, "Failed to create stock take", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(stockTake))
+}
+
+// ListStockTakes 查询盘点单列表
+// @Summary 查询盘点单列表
+// @Tags stocktake
+// @Produce json
+// @Param status query string false "状态：draft、in_progress、counting_closed、reconciled、posted"
+// @Success 200 {object} dto.CommonResponse{data=dto.ListResponse}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 500 {object} dto.CommonResponse "服务器内部错误"
+// @Router /api/wms/inventory/stocktakes [get]
+func (h *StockTakeHandler) ListStockTakes(c *gin.Context) {
+	var req dto.StockTakeListQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	stockTakes, err := h.service.ListStockTakes(req.Status)
+	if err != nil {
+		respondError(c, "Failed to list stock takes", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(dto.ListResponse{
+		Items: stockTakes,
+		Count: len(stockTakes),
+	}))
+}
+
+// GetStockTake 查询盘点单详情
+// @Summary 查询盘点单详情
+// @Tags stocktake
+// @Produce json
+// @Param id path int true "盘点单ID"
+// @Success 200 {object} dto.CommonResponse{data=model.StockTake}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 404 {object} dto.CommonResponse "盘点单不存在"
+// @Router /api/wms/inventory/stocktakes/{id} [get]
+func (h *StockTakeHandler) GetStockTake(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	stockTake, err := h.service.GetStockTake(id)
+	if err != nil {
+		respondError(c, "Failed to get stock take", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(stockTake))
+}
+
+// GetStockTakeSummary 查询盘点单汇总
+// @Summary 查询盘点单汇总
+// @Description 返回盘点覆盖率、未盘库位、待审批数量与差异合计
+// @Tags stocktake
+// @Produce json
+// @Param id path int true "盘点单ID"
+// @Success 200 {object} dto.CommonResponse{data=service.StockTakeSummary}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 404 {object} dto.CommonResponse "盘点单不存在"
+// @Router /api/wms/inventory/stocktakes/{id}/summary [get]
+func (h *StockTakeHandler) GetStockTakeSummary(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	summary, err := h.service.GetStockTakeSummary(id)
+	if err != nil {
+		respondError(c, "Failed to get stock take summary", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(summary))
+}
+
+// ListCountTasks 查询盲盘任务
+// @Summary 查询盲盘任务
+// @Description 供移动端拉取盲盘任务，返回库位与待盘物料，不包含系统库存数量
+// @Tags stocktake
+// @Produce json
+// @Param id path int true "盘点单ID"
+// @Param checker_id query string false "盘点人员ID，传入时只返回分配给该盘点人的任务"
+// @Success 200 {object} dto.CommonResponse{data=dto.ListResponse}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 404 {object} dto.CommonResponse "盘点单不存在"
+// @Failure 409 {object} dto.CommonResponse "盘点单不是盲盘"
+// @Router /api/wms/inventory/stocktakes/{id}/tasks [get]
+func (h *StockTakeHandler) ListCountTasks(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.CountTaskListQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	tasks, err := h.service.ListCountTasks(id, req.CheckerID)
+	if err != nil {
+		respondError(c, "Failed to list count tasks", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(dto.ListResponse{
+		Items: tasks,
+		Count: len(tasks),
+	}))
+}
+
+// StartStockTake 开始盘点
+// @Summary 开始盘点
+// @Description draft -> in_progress，之后可通过盘点上传接口携带 stock_take_id 上传盘点；盲盘盘点单此时生成盘点任务并冻结系统库存
+// @Tags stocktake
+// @Produce json
+// @Param id path int true "盘点单ID"
+// @Success 200 {object} dto.CommonResponse{data=model.StockTake}
+// @Failure 404 {object} dto.CommonResponse "盘点单不存在"
+// @Failure 409 {object} dto.CommonResponse "盘点单状态不允许该操作"
+// @Router /api/wms/inventory/stocktakes/{id}/start [post]
+func (h *StockTakeHandler) StartStockTake(c *gin.Context) {
+	h.transition(c, "start", h.service.StartStockTake)
+}
+
+// CloseCounting 截止盘点
+// @Summary 截止盘点
+// @Description in_progress -> counting_closed，之后不再接受该盘点单的盘点上传
+// @Tags stocktake
+// @Produce json
+// @Param id path int true "盘点单ID"
+// @Success 200 {object} dto.CommonResponse{data=model.StockTake}
+// @Failure 404 {object} dto.CommonResponse "盘点单不存在"
+// @Failure 409 {object} dto.CommonResponse "盘点单状态不允许该操作"
+// @Router /api/wms/inventory/stocktakes/{id}/close [post]
+func (h *StockTakeHandler) CloseCounting(c *gin.Context) {
+	h.transition(c, "close counting of", h.service.CloseCounting)
+}
+
+// ReconcileStockTake 确认盘点差异
+// @Summary 确认盘点差异
+// @Description counting_closed -> reconciled，要求盘点单内没有待审批的差异
+// @Tags stocktake
+// @Produce json
+// @Param id path int true "盘点单ID"
+// @Success 200 {object} dto.CommonResponse{data=model.StockTake}
+// @Failure 404 {object} dto.CommonResponse "盘点单不存在"
+// @Failure 409 {object} dto.CommonResponse "盘点单状态不允许该操作或仍有待审批差异"
+// @Router /api/wms/inventory/stocktakes/{id}/reconcile [post]
+func (h *StockTakeHandler) ReconcileStockTake(c *gin.Context) {
+	h.transition(c, "reconcile", h.service.ReconcileStockTake)
+}
+
+// PostStockTake 过账盘点单
+// @Summary 过账盘点单
+// @Description reconciled -> posted，将已批准的差异按增量过账至当前库存并记录流水
+// @Tags stocktake
+// @Accept json
+// @Produce json
+// @Param id path int true "盘点单ID"
+// @Param request body dto.StockTakePostRequest true "过账信息"
+// @Success 200 {object} dto.CommonResponse{data=model.StockTake}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 404 {object} dto.CommonResponse "盘点单不存在"
+// @Failure 409 {object} dto.CommonResponse "盘点单状态不允许该操作或库存不足"
+// @Router /api/wms/inventory/stocktakes/{id}/post [post]
+func (h *StockTakeHandler) PostStockTake(c *gin.Context) {
+	var req dto.StockTakePostRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	h.transition(c, "post", func(id uint) (*model.StockTake, error) {
+		return h.service.PostStockTake(id, req.OperatorID)
+	})
+}
+
+// transition 处理盘点单状态流转请求的公共流程
+func (h *StockTakeHandler) transition(c *gin.Context, action string, fn func(id uint) (*model.StockTake, error)) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	stockTake, err := fn(id)
+	if err != nil {
+		h.logger.Warn("Failed to change stock take status",
+			zap.Uint("stock_take_id", id),
+			zap.String("action", action),
+			zap.Error(err),
+		)
+		respondError(c, "Failed to "+action+" stock take", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(stockTake))
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"wms/internal/api/dto"
+	"wms/internal/model"
+	"wms/internal/service"
+	"wms/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mockStockTakeService 是用于测试的盘点单服务模拟实现
+type mockStockTakeService struct {
+	service.StockTakeService
+	createFunc func(input service.StockTakeInput) (*model.StockTake, error)
+	closeFunc  func(id uint) (*model.StockTake, error)
+	tasksFunc  func(id uint, checkerID string) ([]model.CountTask, error)
+}
+
+func (m *mockStockTakeService) CreateStockTake(input service.StockTakeInput) (*model.StockTake, error) {
+	if m.createFunc != nil {
+		return m.createFunc(input)
+	}
+	return &model.StockTake{ID: 1, Name: input.Name, Status: model.StockTakeStatusDraft}, nil
+}
+
+func (m *mockStockTakeService) CloseCounting(id uint) (*model.StockTake, error) {
+	if m.closeFunc != nil {
+		return m.closeFunc(id)
+	}
+	return &model.StockTake{ID: id, Status: model.StockTakeStatusCountingClosed}, nil
+}
+
+func (m *mockStockTakeService) ListCountTasks(id uint, checkerID string) ([]model.CountTask, error) {
+	if m.tasksFunc != nil {
+		return m.tasksFunc(id, checkerID)
+	}
+	return []model.CountTask{}, nil
+}
+
+func setupStockTakeRouter(handler *StockTakeHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/wms/inventory/stocktakes", handler.CreateStockTake)
+	router.POST("/api/wms/inventory/stocktakes/:id/close", handler.CloseCounting)
+	router.GET("/api/wms/inventory/stocktakes/:id/tasks", handler.ListCountTasks)
+	return router
+}
+
+func TestCreateStockTake_Success(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	var received service.StockTakeInput
+	router := setupStockTakeRouter(NewStockTakeHandler(&mockStockTakeService{
+		createFunc: func(input service.StockTakeInput) (*model.StockTake, error) {
+			received = input
+			return &model.StockTake{ID: 3, Name: input.Name, Status: model.StockTakeStatusDraft}, nil
+		},
+	}, log))
+
+	body := `{"name":"March zone A","created_by":"sup1","location_codes":["A-01","A-02"],"checker_ids":["c1"]}`
+	req, _ := http.NewRequest("POST", "/api/wms/inventory/stocktakes", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(received.LocationCodes) != 2 || len(received.CheckerIDs) != 1 || received.CreatedBy != "sup1" {
+		t.Errorf("Unexpected service input: %+v", received)
+	}
+}
+
+func TestCreateStockTake_EmptyScopeEntry(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	router := setupStockTakeRouter(NewStockTakeHandler(&mockStockTakeService{}, log))
+
+	body := `{"name":"March zone A","created_by":"sup1","location_codes":["A-01",""]}`
+	req, _ := http.NewRequest("POST", "/api/wms/inventory/stocktakes", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestCloseCounting_InvalidState(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	router := setupStockTakeRouter(NewStockTakeHandler(&mockStockTakeService{
+		closeFunc: func(id uint) (*model.StockTake, error) {
+			return nil, fmt.Errorf("%w: stock take %d is draft, expected in_progress", service.ErrInvalidState, id)
+		},
+	}, log))
+
+	req, _ := http.NewRequest("POST", "/api/wms/inventory/stocktakes/5/close", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("Expected status 409, got %d", w.Code)
+	}
+	var response dto.CommonResponse
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if response.ErrorCode != dto.ErrCodeInvalidState {
+		t.Errorf("Expected error code %s, got %s", dto.ErrCodeInvalidState, response.ErrorCode)
+	}
+}
+
+func TestListCountTasks_HidesFrozenQuantity(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	var receivedChecker string
+	router := setupStockTakeRouter(NewStockTakeHandler(&mockStockTakeService{
+		tasksFunc: func(id uint, checkerID string) ([]model.CountTask, error) {
+			receivedChecker = checkerID
+			return []model.CountTask{{
+				ID:           1,
+				StockTakeID:  id,
+				LocationCode: "A-01",
+				Status:       model.CountTaskStatusOpen,
+				Lines:        []model.CountTaskLine{{ID: 1, MaterialCode: "MAT001", FrozenQuantity: 987654}},
+			}}, nil
+		},
+	}, log))
+
+	req, _ := http.NewRequest("GET", "/api/wms/inventory/stocktakes/2/tasks?checker_id=c1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if receivedChecker != "c1" {
+		t.Errorf("Expected checker filter c1, got %q", receivedChecker)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, "MAT001") {
+		t.Errorf("Expected task lines in response, got %s", body)
+	}
+	if strings.Contains(body, "987654") || strings.Contains(body, "frozen_quantity") {
+		t.Errorf("Book quantity must not be exposed to checkers: %s", body)
+	}
+}
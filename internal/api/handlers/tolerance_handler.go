@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"net/http"
+	"wms/internal/api/dto"
+	"wms/internal/service"
+	"wms/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ToleranceHandler 负责处理盘点差异容差配置相关的 HTTP 请求
+type ToleranceHandler struct {
+	service service.VarianceToleranceService
+	logger  *logger.Logger
+}
+
+// NewToleranceHandler 创建一个新的 ToleranceHandler 实例
+func NewToleranceHandler(service service.VarianceToleranceService, log *logger.Logger) *ToleranceHandler {
+	return &ToleranceHandler{
+		service: service,
+		logger:  log,
+	}
+}
+
+// ListTolerances 查询全部差异容差配置
+// @Summary 查询差异容差配置
+// @Tags inventory
+// @Produce json
+// @Success 200 {object} dto.CommonResponse{data=dto.ListResponse}
+// @Failure 500 {object} dto.CommonResponse "服务器内部错误"
+// @Router /api/wms/inventory/tolerances [get]
+func (h *ToleranceHandler) ListTolerances(c *gin.Context) {
+	tolerances, err := h.service.ListTolerances()
+	if err != nil {
+		respondError(c, "Failed to list tolerances", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(dto.ListResponse{
+		Items: tolerances,
+		Count: len(tolerances),
+	}))
+}
+
+// UpsertTolerance 新增或更新差异容差配置
+// @Summary 保存差异容差配置
+// @Description 按 (material_code, location_code) 作用范围新增或覆盖容差配置
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Param request body dto.VarianceToleranceRequest true "容差配置"
+// @Success 200 {object} dto.CommonResponse{data=model.VarianceTolerance}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Router /api/wms/inventory/tolerances [put]
+func (h *ToleranceHandler) UpsertTolerance(c *gin.Context) {
+	var req dto.VarianceToleranceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	tolerance, err := h.service.UpsertTolerance(service.VarianceToleranceInput{
+		MaterialCode:        req.MaterialCode,
+		LocationCode:        req.LocationCode,
+		AbsoluteTolerance:   req.AbsoluteTolerance,
+		PercentageTolerance: req.PercentageTolerance,
+	})
+	if err != nil {
+		respondError(c, "Failed to save tolerance", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(tolerance))
+}
+
+// DeleteTolerance 删除差异容差配置
+// @Summary 删除差异容差配置
+// @Tags inventory
+// @Produce json
+// @Param id path int true "容差配置ID"
+// @Success 200 {object} dto.CommonResponse
+// @Failure 404 {object} dto.CommonResponse "配置不存在"
+// @Router /api/wms/inventory/tolerances/{id} [delete]
+func (h *ToleranceHandler) DeleteTolerance(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteTolerance(id); err != nil {
+		respondError(c, "Failed to delete tolerance", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse())
+}
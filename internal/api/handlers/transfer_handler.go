@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"net/http"
+	"wms/internal/api/dto"
+	"wms/internal/service"
+	"wms/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TransferHandler 负责处理库位间移库相关的 HTTP 请求
+type TransferHandler struct {
+	service service.TransferService
+	logger  *logger.Logger
+}
+
+// NewTransferHandler 创建一个新的 TransferHandler 实例
+func NewTransferHandler(service service.TransferService, log *logger.Logger) *TransferHandler {
+	return &TransferHandler{
+		service: service,
+		logger:  log,
+	}
+}
+
+// CreateTransfer 库位间移库
+// @Summary 库位间移库
+// @Description 在同一事务中按行扣减来源库位库存并增加（必要时创建）目标库位库存，写入 transfer 流水；任一行使来源库存为负时整单回滚并返回 409
+// @Tags stock
+// @Accept json
+// @Produce json
+// @Param request body dto.StockTransferRequest true "移库信息"
+// @Success 200 {object} dto.CommonResponse{data=model.StockTransfer}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 409 {object} dto.CommonResponse "来源库位库存不足或序列号不在来源库位"
+// @Failure 500 {object} dto.CommonResponse "服务器内部错误"
+// @Router /api/wms/stock/transfers [post]
+func (h *TransferHandler) CreateTransfer(c *gin.Context) {
+	var req dto.StockTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	lines := make([]service.TransferLineInput, 0, len(req.Lines))
+	for _, line := range req.Lines {
+		lines = append(lines, service.TransferLineInput{
+			MaterialCode:  line.MaterialCode,
+			LotNumber:     line.LotNumber,
+			FromLocation:  line.FromLocation,
+			ToLocation:    line.ToLocation,
+			Quantity:      line.Quantity,
+			SerialNumbers: line.SerialNumbers,
+		})
+	}
+	transfer, err := h.service.CreateTransfer(service.TransferInput{
+		OperatorID: req.OperatorID,
+		Note:       req.Note,
+		Lines:      lines,
+	})
+	if err != nil {
+		respondError(c, "Failed to transfer stock", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(transfer))
+}
+
+// GetTransfer 查询移库单
+// @Summary 查询移库单
+// @Tags stock
+// @Produce json
+// @Param id path int true "移库单ID"
+// @Success 200 {object} dto.CommonResponse{data=model.StockTransfer}
+// @Failure 404 {object} dto.CommonResponse "移库单不存在"
+// @Router /api/wms/stock/transfers/{id} [get]
+func (h *TransferHandler) GetTransfer(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	transfer, err := h.service.GetTransfer(id)
+	if err != nil {
+		respondError(c, "Failed to get stock transfer", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(transfer))
+}
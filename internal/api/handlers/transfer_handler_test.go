@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wms/internal/model"
+	"wms/internal/service"
+	"wms/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mockTransferService 是用于测试的移库服务模拟实现
+type mockTransferService struct {
+	service.TransferService
+	createFunc func(input service.TransferInput) (*model.StockTransfer, error)
+}
+
+func (m *mockTransferService) CreateTransfer(input service.TransferInput) (*model.StockTransfer, error) {
+	return m.createFunc(input)
+}
+
+func setupTransferRouter(handler *TransferHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/wms/stock/transfers", handler.CreateTransfer)
+	return router
+}
+
+func TestCreateTransfer_MapsLines(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	var captured service.TransferInput
+	router := setupTransferRouter(NewTransferHandler(&mockTransferService{
+		createFunc: func(input service.TransferInput) (*model.StockTransfer, error) {
+			captured = input
+			return &model.StockTransfer{ID: 9, OperatorID: input.OperatorID}, nil
+		},
+	}, log))
+
+	body := []byte(`{"operator_id":"op1","lines":[
+		{"material_code":"MAT001","lot_number":"L1","from_location":"A-01","to_location":"B-01","quantity":2,"serial_numbers":["SN1","SN2"]},
+		{"material_code":"MAT002","from_location":"A-02","to_location":"B-02","quantity":5}]}`)
+	req, _ := http.NewRequest("POST", "/api/wms/stock/transfers", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if captured.OperatorID != "op1" || len(captured.Lines) != 2 {
+		t.Fatalf("Unexpected input: %+v", captured)
+	}
+	first := captured.Lines[0]
+	if first.MaterialCode != "MAT001" || first.LotNumber != "L1" || first.FromLocation != "A-01" || first.ToLocation != "B-01" ||
+		first.Quantity != 2 || len(first.SerialNumbers) != 2 {
+		t.Errorf("Unexpected first line: %+v", first)
+	}
+}
+
+func TestCreateTransfer_InvalidRequest(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	router := setupTransferRouter(NewTransferHandler(&mockTransferService{
+		createFunc: func(input service.TransferInput) (*model.StockTransfer, error) {
+			t.Fatal("service should not be called for an invalid request")
+			return nil, nil
+		},
+	}, log))
+
+	// 来源与目标库位相同、数量为 0 或没有行时都在绑定阶段被拒绝
+	bodies := []string{
+		`{"operator_id":"op1","lines":[{"material_code":"MAT001","from_location":"A-01","to_location":"A-01","quantity":1}]}`,
+		`{"operator_id":"op1","lines":[{"material_code":"MAT001","from_location":"A-01","to_location":"B-01","quantity":0}]}`,
+		`{"operator_id":"op1","lines":[]}`,
+	}
+	for _, body := range bodies {
+		req, _ := http.NewRequest("POST", "/api/wms/stock/transfers", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s, got %d", body, w.Code)
+		}
+	}
+}
+
+func TestCreateTransfer_InsufficientStock(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	router := setupTransferRouter(NewTransferHandler(&mockTransferService{
+		createFunc: func(input service.TransferInput) (*model.StockTransfer, error) {
+			return nil, fmt.Errorf("line 2: %w: MAT002 at A-02 has 3, cannot apply -5", service.ErrInsufficientStock)
+		},
+	}, log))
+
+	body := []byte(`{"operator_id":"op1","lines":[{"material_code":"MAT002","from_location":"A-02","to_location":"B-02","quantity":5}]}`)
+	req, _ := http.NewRequest("POST", "/api/wms/stock/transfers", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("Expected status 409, got %d", w.Code)
+	}
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if response["error_code"] != "INSUFFICIENT_STOCK" {
+		t.Errorf("Expected error_code INSUFFICIENT_STOCK, got %v", response["error_code"])
+	}
+}
@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"net/http"
+	"wms/internal/api/dto"
+	"wms/internal/repository"
+	"wms/internal/service"
+	"wms/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WaveHandler 负责处理拣货波次、拣货任务与拣货确认相关的 HTTP 请求
+type WaveHandler struct {
+	service service.WaveService
+	logger  *logger.Logger
+}
+
+// NewWaveHandler 创建一个新的 WaveHandler 实例
+func NewWaveHandler(service service.WaveService, log *logger.Logger) *WaveHandler {
+	return &WaveHandler{
+		service: service,
+		logger:  log,
+	}
+}
+
+// PlanWaves 组织拣货波次
+// @Summary 组织拣货波次
+// @Description 将已分配足量、尚未组波的出库单据组织为波次并生成拣货任务。group_by=carrier 时每个承运商与截单时间一个波次；group_by=zone 时每个库区一个波次，同一单据可分布在多个波次中。拣货任务按库位与物料合并，并按仓库布局与路线策略（routing）规划的行走顺序编号
+// @Tags waves
+// @Accept json
+// @Produce json
+// @Param request body dto.WavePlanRequest true "组波条件"
+// @Success 201 {object} dto.CommonResponse{data=dto.ListResponse{items=[]service.WaveDetail}}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 409 {object} dto.CommonResponse "没有可组波的单据"
+// @Router /api/wms/outbound/waves [post]
+func (h *WaveHandler) PlanWaves(c *gin.Context) {
+	var req dto.WavePlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	waves, err := h.service.PlanWaves(service.WavePlanInput{
+		GroupBy:      req.GroupBy,
+		Carrier:      req.Carrier,
+		CutoffBefore: req.CutoffBefore,
+		OrderIDs:     req.OrderIDs,
+		Routing:      req.Routing,
+		CreatedBy:    req.CreatedBy,
+	})
+	if err != nil {
+		respondError(c, "Failed to plan waves", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponseWithData(dto.ListResponse{
+		Items: waves,
+		Count: len(waves),
+	}))
+}
+
+// ListWaves 查询拣货波次列表
+// @Summary 查询拣货波次列表
+// @Tags waves
+// @Produce json
+// @Param status query string false "状态：released、picking、completed"
+// @Param group_by query string false "分组方式：carrier、zone"
+// @Success 200 {object} dto.CommonResponse{data=dto.ListResponse{items=[]model.Wave}}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Router /api/wms/outbound/waves [get]
+func (h *WaveHandler) ListWaves(c *gin.Context) {
+	var req dto.WaveListQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	waves, err := h.service.ListWaves(repository.WaveFilter{
+		Status:  req.Status,
+		GroupBy: req.GroupBy,
+	})
+	if err != nil {
+		respondError(c, "Failed to list waves", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(dto.ListResponse{
+		Items: waves,
+		Count: len(waves),
+	}))
+}
+
+// GetWave 查询拣货波次详情
+// @Summary 查询拣货波次详情
+// @Description 返回波次进度（任务数、已确认数、短拣数）及按行走顺序排列的拣货任务
+// @Tags waves
+// @Produce json
+// @Param id path int true "波次ID"
+// @Success 200 {object} dto.CommonResponse{data=service.WaveDetail}
+// @Failure 404 {object} dto.CommonResponse "波次不存在"
+// @Router /api/wms/outbound/waves/{id} [get]
+func (h *WaveHandler) GetWave(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	wave, err := h.service.GetWave(id)
+	if err != nil {
+		respondError(c, "Failed to get wave", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(wave))
+}
+
+// ListPickTasks 查询拣货任务列表
+// @Summary 查询拣货任务列表
+// @Description 按波次与行走顺序排列，供手持终端按路线拣货
+// @Tags waves
+// @Produce json
+// @Param wave_id query int false "波次ID"
+// @Param status query string false "状态：open、picked、short"
+// @Param zone query string false "库区"
+// @Param assigned_to query string false "拣货员"
+// @Success 200 {object} dto.CommonResponse{data=dto.ListResponse{items=[]model.PickTask}}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Router /api/wms/outbound/pick-tasks [get]
+func (h *WaveHandler) ListPickTasks(c *gin.Context) {
+	var req dto.PickTaskListQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	tasks, err := h.service.ListPickTasks(repository.PickTaskFilter{
+		WaveID:     req.WaveID,
+		Status:     req.Status,
+		Zone:       req.Zone,
+		AssignedTo: req.AssignedTo,
+	})
+	if err != nil {
+		respondError(c, "Failed to list pick tasks", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(dto.ListResponse{
+		Items: tasks,
+		Count: len(tasks),
+	}))
+}
+
+// AssignPickTask 指派拣货任务
+// @Summary 指派拣货任务
+// @Tags waves
+// @Accept json
+// @Produce json
+// @Param id path int true "拣货任务ID"
+// @Param request body dto.PickAssignRequest true "拣货员"
+// @Success 200 {object} dto.CommonResponse{data=model.PickTask}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效"
+// @Failure 404 {object} dto.CommonResponse "任务不存在"
+// @Failure 409 {object} dto.CommonResponse "任务已确认"
+// @Router /api/wms/outbound/pick-tasks/{id}/assign [post]
+func (h *WaveHandler) AssignPickTask(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req dto.PickAssignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	task, err := h.service.AssignPickTask(id, req.AssignedTo)
+	if err != nil {
+		respondError(c, "Failed to assign pick task", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(task))
+}
+
+// ConfirmPick 确认拣货
+// @Summary 确认拣货（手持终端）
+// @Description 按实拣数量将货物从拣货库位移至单据的包装暂存库位，并在暂存库位继续为单据预留，发运确认时才扣减。实拣少于任务数量为短拣：未拣数量的预留被释放，并对该物料与库位生成盘点任务，由盘点上传完成并修正在库数量
+// @Tags waves
+// @Accept json
+// @Produce json
+// @Param id path int true "拣货任务ID"
+// @Param request body dto.PickConfirmRequest true "实拣数量与扫描的序列号"
+// @Success 200 {object} dto.CommonResponse{data=service.PickConfirmResult}
+// @Failure 400 {object} dto.CommonResponse "请求参数无效或实拣数量超过任务数量"
+// @Failure 404 {object} dto.CommonResponse "任务不存在"
+// @Failure 409 {object} dto.CommonResponse "任务已确认、在库数量不足或序列号不在拣货库位"
+// @Failure 422 {object} dto.CommonResponse "序列号管理的物料未扫描序列号"
+// @Router /api/wms/outbound/pick-tasks/{id}/confirm [post]
+func (h *WaveHandler) ConfirmPick(c *gin.Context) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req dto.PickConfirmRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, err)
+		return
+	}
+
+	result, err := h.service.ConfirmPick(id, service.PickConfirmInput{
+		PickedQuantity: *req.PickedQuantity,
+		OperatorID:     req.OperatorID,
+		SerialNumbers:  req.SerialNumbers,
+	})
+	if err != nil {
+		respondError(c, "Failed to confirm pick", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponseWithData(result))
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wms/internal/model"
+	"wms/internal/service"
+	"wms/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// mockWaveService 是用于测试的波次服务模拟实现
+type mockWaveService struct {
+	service.WaveService
+	confirmFunc func(id uint, input service.PickConfirmInput) (*service.PickConfirmResult, error)
+}
+
+func (m *mockWaveService) ConfirmPick(id uint, input service.PickConfirmInput) (*service.PickConfirmResult, error) {
+	return m.confirmFunc(id, input)
+}
+
+func setupWaveRouter(handler *WaveHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/wms/outbound/waves", handler.PlanWaves)
+	router.POST("/api/wms/outbound/pick-tasks/:id/confirm", handler.ConfirmPick)
+	return router
+}
+
+func TestConfirmPick_ShortPickReturnsCountTask(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	var captured service.PickConfirmInput
+	router := setupWaveRouter(NewWaveHandler(&mockWaveService{
+		confirmFunc: func(id uint, input service.PickConfirmInput) (*service.PickConfirmResult, error) {
+			captured = input
+			countTaskID := uint(42)
+			return &service.PickConfirmResult{
+				Task:      &model.PickTask{ID: id, Quantity: 5, PickedQuantity: input.PickedQuantity, Status: model.PickTaskStatusShort, CountTaskID: &countTaskID},
+				Wave:      &model.Wave{ID: 1, Status: model.WaveStatusPicking},
+				CountTask: &model.CycleCountTask{ID: countTaskID, Source: model.CycleCountSourceShortPick},
+			}, nil
+		},
+	}, log))
+
+	// 实拣 0 件是合法的短拣，不能被 required 校验拒绝
+	body := []byte(`{"picked_quantity":0,"operator_id":"picker1"}`)
+	req, _ := http.NewRequest("POST", "/api/wms/outbound/pick-tasks/7/confirm", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if captured.PickedQuantity != 0 || captured.OperatorID != "picker1" {
+		t.Errorf("Unexpected input passed to service: %+v", captured)
+	}
+	var response struct {
+		Data service.PickConfirmResult `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	if response.Data.CountTask == nil || response.Data.CountTask.ID != 42 {
+		t.Errorf("Expected count task 42 in response, got %+v", response.Data.CountTask)
+	}
+}
+
+func TestConfirmPick_RequiresPickedQuantity(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	router := setupWaveRouter(NewWaveHandler(&mockWaveService{}, log))
+
+	body := []byte(`{"operator_id":"picker1"}`)
+	req, _ := http.NewRequest("POST", "/api/wms/outbound/pick-tasks/7/confirm", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestPlanWaves_RejectsUnknownGrouping(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	router := setupWaveRouter(NewWaveHandler(&mockWaveService{}, log))
+
+	body := []byte(`{"group_by":"customer","created_by":"u1"}`)
+	req, _ := http.NewRequest("POST", "/api/wms/outbound/waves", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d", w.Code)
+	}
+}
@@ -6,8 +6,29 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// Handlers 汇总注册路由所需的全部处理器
+type Handlers struct {
+	Inventory     *handlers.InventoryHandler
+	Tolerance     *handlers.ToleranceHandler
+	StockTake     *handlers.StockTakeHandler
+	Stock         *handlers.StockHandler
+	CycleCount    *handlers.CycleCountHandler
+	Reason        *handlers.ReasonHandler
+	Inbound       *handlers.InboundHandler
+	Location      *handlers.LocationHandler
+	Putaway       *handlers.PutawayHandler
+	Transfer      *handlers.TransferHandler
+	Outbound      *handlers.OutboundHandler
+	Wave          *handlers.WaveHandler
+	Layout        *handlers.LayoutHandler
+	Packing       *handlers.PackingHandler
+	Material      *handlers.MaterialHandler
+	Return        *handlers.ReturnHandler
+	Replenishment *handlers.ReplenishmentHandler
+}
+
 // SetupRoutes 配置应用的所有路由
-func SetupRoutes(router *gin.Engine, inventoryHandler *handlers.InventoryHandler) {
+func SetupRoutes(router *gin.Engine, h Handlers) {
 	// API v1 路由分组
 	api := router.Group("/api/wms")
 	{
@@ -16,8 +37,196 @@ func SetupRoutes(router *gin.Engine, inventoryHandler *handlers.InventoryHandler
 		{
 			check := inventory.Group("/check")
 			{
-				check.POST("/upload", inventoryHandler.UploadCheck)
+				check.POST("/upload", h.Inventory.UploadCheck)
+				check.POST("/batch", h.Inventory.BatchUploadCheck)
+				check.GET("/records", h.Inventory.ListCheckRecords)
+				check.GET("/records/unprocessed", h.Inventory.ListUnprocessedRecords)
+				check.GET("/records/:id", h.Inventory.GetCheckRecord)
+				check.GET("/records/:id/chain", h.Inventory.GetRecordChain)
+				check.POST("/records/:id/approve", h.Inventory.ApproveVariance)
+				check.POST("/records/:id/reject", h.Inventory.RejectVariance)
+				check.GET("/approvals", h.Inventory.ListPendingApprovals)
+				check.GET("/recounts", h.Inventory.ListRecountTasks)
+				check.POST("/recounts/:id/assign", h.Inventory.AssignRecountTask)
+			}
+
+			tolerances := inventory.Group("/tolerances")
+			{
+				tolerances.GET("", h.Tolerance.ListTolerances)
+				tolerances.PUT("", h.Tolerance.UpsertTolerance)
+				tolerances.DELETE("/:id", h.Tolerance.DeleteTolerance)
+			}
+
+			reasons := inventory.Group("/reasons")
+			{
+				reasons.GET("", h.Reason.ListReasons)
+				reasons.PUT("", h.Reason.UpsertReason)
+			}
+
+			inventory.GET("/reports/variance", h.Inventory.GetVarianceReport)
+
+			stockTakes := inventory.Group("/stocktakes")
+			{
+				stockTakes.POST("", h.StockTake.CreateStockTake)
+				stockTakes.GET("", h.StockTake.ListStockTakes)
+				stockTakes.GET("/:id", h.StockTake.GetStockTake)
+				stockTakes.GET("/:id/summary", h.StockTake.GetStockTakeSummary)
+				stockTakes.GET("/:id/tasks", h.StockTake.ListCountTasks)
+				stockTakes.POST("/:id/start", h.StockTake.StartStockTake)
+				stockTakes.POST("/:id/close", h.StockTake.CloseCounting)
+				stockTakes.POST("/:id/reconcile", h.StockTake.ReconcileStockTake)
+				stockTakes.POST("/:id/post", h.StockTake.PostStockTake)
+			}
+
+			cycleCount := inventory.Group("/cyclecount")
+			{
+				cycleCount.GET("/policy", h.CycleCount.GetPolicy)
+				cycleCount.PUT("/policy", h.CycleCount.UpdatePolicy)
+				cycleCount.GET("/valuations", h.CycleCount.ListValuations)
+				cycleCount.PUT("/valuations", h.CycleCount.UpsertValuation)
+				cycleCount.GET("/preview", h.CycleCount.PreviewCycleCount)
+				cycleCount.POST("/run", h.CycleCount.RunCycleCount)
+				cycleCount.GET("/tasks", h.CycleCount.ListCycleCountTasks)
 			}
 		}
+
+		// 入库收货相关路由
+		inbound := api.Group("/inbound")
+		{
+			orders := inbound.Group("/orders")
+			{
+				orders.POST("", h.Inbound.CreateOrder)
+				orders.GET("", h.Inbound.ListOrders)
+				orders.GET("/:id", h.Inbound.GetOrder)
+				orders.POST("/:id/receive", h.Inbound.ReceiveOrder)
+				orders.POST("/:id/close", h.Inbound.CloseOrder)
+				orders.POST("/:id/cancel", h.Inbound.CancelOrder)
+				orders.GET("/:id/receipts", h.Inbound.ListReceipts)
+			}
+		}
+
+		// 出库相关路由
+		outbound := api.Group("/outbound")
+		{
+			orders := outbound.Group("/orders")
+			{
+				orders.POST("", h.Outbound.CreateOrder)
+				orders.GET("", h.Outbound.ListOrders)
+				orders.GET("/:id", h.Outbound.GetOrder)
+				orders.POST("/:id/allocate", h.Outbound.AllocateOrder)
+				orders.POST("/:id/cancel", h.Outbound.CancelOrder)
+				orders.GET("/:id/allocations", h.Outbound.ListAllocations)
+				orders.POST("/:id/cartonize", h.Packing.Cartonize)
+				orders.POST("/:id/packages", h.Packing.PackCarton)
+				orders.GET("/:id/packages", h.Packing.ListPackages)
+				orders.POST("/:id/ship", h.Packing.ShipOrder)
+				orders.GET("/:id/manifest", h.Packing.GetManifest)
+			}
+
+			cartons := outbound.Group("/cartons")
+			{
+				cartons.GET("", h.Packing.ListCartonTypes)
+				cartons.PUT("", h.Packing.UpsertCartonType)
+			}
+
+			outbound.DELETE("/packages/:id", h.Packing.Unpack)
+
+			waves := outbound.Group("/waves")
+			{
+				waves.POST("", h.Wave.PlanWaves)
+				waves.GET("", h.Wave.ListWaves)
+				waves.GET("/:id", h.Wave.GetWave)
+			}
+
+			pickTasks := outbound.Group("/pick-tasks")
+			{
+				pickTasks.GET("", h.Wave.ListPickTasks)
+				pickTasks.POST("/:id/assign", h.Wave.AssignPickTask)
+				pickTasks.POST("/:id/confirm", h.Wave.ConfirmPick)
+			}
+		}
+
+		// 客户退货相关路由
+		returns := api.Group("/returns")
+		{
+			returns.POST("", h.Return.CreateReturn)
+			returns.GET("", h.Return.ListReturns)
+			returns.GET("/:id", h.Return.GetReturn)
+			returns.POST("/:id/receive", h.Return.ReceiveReturn)
+			returns.POST("/:id/cancel", h.Return.CancelReturn)
+			returns.GET("/:id/receipts", h.Return.ListReceipts)
+		}
+
+		// 库位主数据相关路由
+		locations := api.Group("/locations")
+		{
+			locations.GET("", h.Location.ListLocations)
+			locations.PUT("", h.Location.UpsertLocation)
+			locations.POST("/generate", h.Location.GenerateLocations)
+			locations.GET("/:code", h.Location.GetLocation)
+		}
+
+		// 物料主数据相关路由
+		materials := api.Group("/materials")
+		{
+			materials.GET("", h.Material.ListMaterials)
+			materials.PUT("", h.Material.UpsertMaterial)
+			materials.POST("/import", h.Material.ImportMaterials)
+			materials.GET("/:code", h.Material.GetMaterial)
+			materials.DELETE("/:code", h.Material.DeleteMaterial)
+		}
+
+		// 仓库布局与路线规划相关路由
+		layout := api.Group("/layout")
+		{
+			layout.GET("", h.Layout.GetLayout)
+			layout.PUT("", h.Layout.UpdateLayout)
+			layout.GET("/edges", h.Layout.ListEdges)
+			layout.PUT("/edges", h.Layout.UpsertEdge)
+			layout.DELETE("/edges/:id", h.Layout.DeleteEdge)
+			layout.POST("/route", h.Layout.PlanRoute)
+		}
+
+		// 上架相关路由
+		putaway := api.Group("/putaway")
+		{
+			putaway.GET("/policy", h.Putaway.GetPolicy)
+			putaway.PUT("/policy", h.Putaway.UpdatePolicy)
+			putaway.GET("/fixed-bins", h.Putaway.ListFixedBins)
+			putaway.PUT("/fixed-bins", h.Putaway.UpsertFixedBin)
+
+			tasks := putaway.Group("/tasks")
+			{
+				tasks.POST("", h.Putaway.CreateTask)
+				tasks.GET("", h.Putaway.ListTasks)
+				tasks.POST("/:id/assign", h.Putaway.AssignTask)
+				tasks.POST("/:id/override", h.Putaway.OverrideTask)
+				tasks.POST("/:id/confirm", h.Putaway.ConfirmTask)
+			}
+		}
+
+		// 拣货位补货相关路由
+		replenishment := api.Group("/replenishment")
+		{
+			replenishment.GET("/rules", h.Replenishment.ListRules)
+			replenishment.PUT("/rules", h.Replenishment.UpsertRule)
+			replenishment.DELETE("/rules/:id", h.Replenishment.DeleteRule)
+			replenishment.POST("/run", h.Replenishment.Run)
+			replenishment.GET("/tasks", h.Replenishment.ListTasks)
+			replenishment.POST("/tasks/:id/confirm", h.Replenishment.ConfirmTask)
+			replenishment.POST("/tasks/:id/cancel", h.Replenishment.CancelTask)
+		}
+
+		// 库存流水相关路由
+		stock := api.Group("/stock")
+		{
+			stock.GET("", h.Stock.ListStocks)
+			stock.GET("/movements", h.Stock.ListMovements)
+			stock.GET("/expiring", h.Stock.ExpiringStocks)
+			stock.GET("/serials", h.Stock.ListSerials)
+			stock.GET("/lots/:lot_number", h.Stock.TraceLot)
+			stock.POST("/transfers", h.Transfer.CreateTransfer)
+			stock.GET("/transfers/:id", h.Transfer.GetTransfer)
+		}
 	}
 }
@@ -0,0 +1,53 @@
+package model
+
+import "time"
+
+// CountTask 表示盲盘盘点单中某个库位的盘点任务
+// 任务在盘点单开始时生成，FrozenAt 为冻结时点，各行的系统库存在该时点快照，
+// 盘点差异以快照数量计算，冻结后发生的库存变动不会影响差异；
+// Sequence 为任务在所属盘点人路线中的行走顺序（从 1 开始），按仓库布局的默认路线策略规划
+type CountTask struct {
+	ID           uint            `gorm:"primaryKey;autoIncrement" json:"id"`
+	StockTakeID  uint            `gorm:"not null;uniqueIndex:idx_count_task_location" json:"stock_take_id"`
+	LocationCode string          `gorm:"type:varchar(100);not null;uniqueIndex:idx_count_task_location" json:"location_code"`
+	AssignedTo   string          `gorm:"type:varchar(100);index" json:"assigned_to,omitempty"`
+	Sequence     int             `gorm:"not null;default:0" json:"sequence"`
+	Status       string          `gorm:"type:varchar(20);not null;default:open" json:"status"`
+	FrozenAt     time.Time       `gorm:"type:timestamp;not null" json:"frozen_at"`
+	CompletedAt  *time.Time      `gorm:"type:timestamp" json:"completed_at,omitempty"`
+	Lines        []CountTaskLine `gorm:"foreignKey:CountTaskID;constraint:OnDelete:CASCADE" json:"lines"`
+	CreatedAt    time.Time       `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time       `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// 盘点任务状态
+const (
+	// CountTaskStatusOpen 任务尚有未盘点的物料
+	CountTaskStatusOpen = "open"
+	// CountTaskStatusCompleted 任务内全部物料均已提交盘点
+	CountTaskStatusCompleted = "completed"
+)
+
+// TableName 指定 CountTask 对应的表名
+func (CountTask) TableName() string {
+	return "count_tasks"
+}
+
+// CountTaskLine 表示盘点任务中的一个物料批次
+// FrozenQuantity 为冻结时点的系统库存，不对外输出，避免盘点人在提交前看到账面数量
+type CountTaskLine struct {
+	ID             uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	CountTaskID    uint   `gorm:"not null;uniqueIndex:idx_count_task_line_lot" json:"-"`
+	MaterialCode   string `gorm:"type:varchar(100);not null;uniqueIndex:idx_count_task_line_lot" json:"material_code"`
+	LotNumber      string `gorm:"type:varchar(100);not null;default:'';uniqueIndex:idx_count_task_line_lot" json:"lot_number,omitempty"`
+	FrozenQuantity int    `gorm:"not null" json:"-"`
+	CheckRecordID  *uint  `json:"check_record_id,omitempty"`
+}
+
+// LegacyCountTaskLineUniqueIndex 是启用批次前 (盘点任务, 物料) 唯一索引的名称，迁移时删除
+const LegacyCountTaskLineUniqueIndex = "idx_count_task_line"
+
+// TableName 指定 CountTaskLine 对应的表名
+func (CountTaskLine) TableName() string {
+	return "count_task_lines"
+}
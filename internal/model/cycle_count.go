@@ -0,0 +1,105 @@
+package model
+
+import "time"
+
+// CycleCountPolicy 表示循环盘点的 ABC 分类与盘点频率配置，全局只有一条（ID 固定为 1）
+// 物料按回溯期内的出入库数量（volume）或金额（value）降序排列，累计占比不超过 AClassShare 的为 A 类，
+// 不超过 BClassShare 的为 B 类，其余为 C 类；各类物料的每个库位按对应频率（天）循环盘点
+type CycleCountPolicy struct {
+	ID             uint      `gorm:"primaryKey" json:"-"`
+	Basis          string    `gorm:"type:varchar(10);not null;default:volume" json:"basis"`
+	LookbackDays   int       `gorm:"not null;default:90" json:"lookback_days"`
+	AClassShare    float64   `gorm:"not null;default:80" json:"a_class_share"`
+	BClassShare    float64   `gorm:"not null;default:95" json:"b_class_share"`
+	AFrequencyDays int       `gorm:"not null;default:30" json:"a_frequency_days"`
+	BFrequencyDays int       `gorm:"not null;default:90" json:"b_frequency_days"`
+	CFrequencyDays int       `gorm:"not null;default:180" json:"c_frequency_days"`
+	MaxTasksPerDay int       `gorm:"not null;default:0" json:"max_tasks_per_day"`
+	UpdatedBy      string    `gorm:"type:varchar(100)" json:"updated_by,omitempty"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// 循环盘点 ABC 分类依据
+const (
+	// CycleCountBasisVolume 按出入库数量分类
+	CycleCountBasisVolume = "volume"
+	// CycleCountBasisValue 按出入库数量乘以物料单位价值分类
+	CycleCountBasisValue = "value"
+)
+
+// ABC 分类
+const (
+	ABCClassA = "A"
+	ABCClassB = "B"
+	ABCClassC = "C"
+)
+
+// TableName 指定 CycleCountPolicy 对应的表名
+func (CycleCountPolicy) TableName() string {
+	return "cycle_count_policies"
+}
+
+// FrequencyDays 返回 ABC 分类对应的盘点间隔天数
+func (p *CycleCountPolicy) FrequencyDays(class string) int {
+	switch class {
+	case ABCClassA:
+		return p.AFrequencyDays
+	case ABCClassB:
+		return p.BFrequencyDays
+	default:
+		return p.CFrequencyDays
+	}
+}
+
+// MaterialValuation 表示物料单位价值，按价值（value）进行 ABC 分类时使用
+// 未配置单位价值的物料价值视为 0
+type MaterialValuation struct {
+	ID           uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	MaterialCode string    `gorm:"type:varchar(100);not null;uniqueIndex" json:"material_code"`
+	UnitCost     float64   `gorm:"not null" json:"unit_cost"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName 指定 MaterialValuation 对应的表名
+func (MaterialValuation) TableName() string {
+	return "material_valuations"
+}
+
+// CycleCountTask 表示某物料在某库位的一次循环盘点任务
+// 同一物料与库位同时只有一条未完成的任务；该物料与库位的任意盘点上传都会完成任务
+// Source 标明任务来源：定时生成（scheduled）或拣货短拣触发（short_pick），短拣触发的任务没有 ABC 分类
+type CycleCountTask struct {
+	ID            uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	ScheduledDate time.Time  `gorm:"type:date;not null;index" json:"scheduled_date"`
+	MaterialCode  string     `gorm:"type:varchar(100);not null;uniqueIndex:idx_cycle_count_open,where:status = 'open'" json:"material_code"`
+	LocationCode  string     `gorm:"type:varchar(100);not null;uniqueIndex:idx_cycle_count_open,where:status = 'open'" json:"location_code"`
+	ABCClass      string     `gorm:"column:abc_class;type:varchar(1);not null;index" json:"abc_class"`
+	LastCountedAt *time.Time `gorm:"type:timestamp" json:"last_counted_at,omitempty"`
+	Source        string     `gorm:"type:varchar(20);not null;default:scheduled;index" json:"source"`
+	Status        string     `gorm:"type:varchar(20);not null;default:open;index" json:"status"`
+	CheckRecordID *uint      `json:"check_record_id,omitempty"`
+	CompletedAt   *time.Time `gorm:"type:timestamp" json:"completed_at,omitempty"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// 循环盘点任务状态
+const (
+	// CycleCountTaskStatusOpen 等待盘点
+	CycleCountTaskStatusOpen = "open"
+	// CycleCountTaskStatusCompleted 已有盘点上传
+	CycleCountTaskStatusCompleted = "completed"
+)
+
+// 循环盘点任务来源
+const (
+	// CycleCountSourceScheduled 按 ABC 分类定时生成
+	CycleCountSourceScheduled = "scheduled"
+	// CycleCountSourceShortPick 拣货确认短拣时生成
+	CycleCountSourceShortPick = "short_pick"
+)
+
+// TableName 指定 CycleCountTask 对应的表名
+func (CycleCountTask) TableName() string {
+	return "cycle_count_tasks"
+}
@@ -0,0 +1,105 @@
+package model
+
+import "time"
+
+// InboundOrder 表示一张入库单据：采购订单（po）或预到货通知（asn）
+// 生命周期：open -> receiving -> closed，未收货的单据可取消（cancelled）
+// 收货数量先过账到 ReceivingLocation（收货暂存库位），超收上限为预期数量的 OverReceiptPercent%
+type InboundOrder struct {
+	ID                 uint               `gorm:"primaryKey;autoIncrement" json:"id"`
+	OrderNo            string             `gorm:"type:varchar(50);not null;uniqueIndex" json:"order_no"`
+	OrderType          string             `gorm:"type:varchar(10);not null;index" json:"order_type"`
+	SupplierCode       string             `gorm:"type:varchar(100);index" json:"supplier_code,omitempty"`
+	PurchaseOrderNo    string             `gorm:"type:varchar(50);index" json:"purchase_order_no,omitempty"`
+	ExpectedAt         *time.Time         `gorm:"type:timestamp" json:"expected_at,omitempty"`
+	ReceivingLocation  string             `gorm:"type:varchar(100);not null" json:"receiving_location"`
+	OverReceiptPercent float64            `gorm:"not null;default:0" json:"over_receipt_percent"`
+	Status             string             `gorm:"type:varchar(20);not null;default:open;index" json:"status"`
+	CreatedBy          string             `gorm:"type:varchar(100);not null" json:"created_by"`
+	ClosedBy           string             `gorm:"type:varchar(100)" json:"closed_by,omitempty"`
+	ClosedAt           *time.Time         `gorm:"type:timestamp" json:"closed_at,omitempty"`
+	CloseReason        string             `gorm:"type:varchar(500)" json:"close_reason,omitempty"`
+	Lines              []InboundOrderLine `gorm:"foreignKey:OrderID;constraint:OnDelete:CASCADE" json:"lines"`
+	CreatedAt          time.Time          `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt          time.Time          `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// 入库单据类型
+const (
+	// InboundOrderTypePO 采购订单
+	InboundOrderTypePO = "po"
+	// InboundOrderTypeASN 预到货通知
+	InboundOrderTypeASN = "asn"
+)
+
+// 入库单据状态
+const (
+	// InboundOrderStatusOpen 已创建，尚未收货
+	InboundOrderStatusOpen = "open"
+	// InboundOrderStatusReceiving 已部分或全部收货，仍可继续收货
+	InboundOrderStatusReceiving = "receiving"
+	// InboundOrderStatusClosed 已关闭，不再接受收货
+	InboundOrderStatusClosed = "closed"
+	// InboundOrderStatusCancelled 未收货即取消
+	InboundOrderStatusCancelled = "cancelled"
+)
+
+// TableName 指定 InboundOrder 对应的表名
+func (InboundOrder) TableName() string {
+	return "inbound_orders"
+}
+
+// InboundOrderLine 表示入库单据的预期物料行
+// Status 随收货更新：未收足为 open，收足为 complete，超收为 over；单据关闭时未收足的行标记为 short
+type InboundOrderLine struct {
+	ID               uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	OrderID          uint      `gorm:"not null;uniqueIndex:idx_inbound_order_line" json:"order_id"`
+	LineNo           int       `gorm:"not null;uniqueIndex:idx_inbound_order_line" json:"line_no"`
+	MaterialCode     string    `gorm:"type:varchar(100);not null;index" json:"material_code"`
+	ExpectedQuantity int       `gorm:"not null" json:"expected_quantity"`
+	ReceivedQuantity int       `gorm:"not null;default:0" json:"received_quantity"`
+	Status           string    `gorm:"type:varchar(20);not null;default:open" json:"status"`
+	UpdatedAt        time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// 入库单据行状态
+const (
+	// InboundLineStatusOpen 未收足
+	InboundLineStatusOpen = "open"
+	// InboundLineStatusComplete 已按预期数量收足
+	InboundLineStatusComplete = "complete"
+	// InboundLineStatusOver 超收（在允许的超收范围内）
+	InboundLineStatusOver = "over"
+	// InboundLineStatusShort 单据关闭时仍未收足
+	InboundLineStatusShort = "short"
+)
+
+// TableName 指定 InboundOrderLine 对应的表名
+func (InboundOrderLine) TableName() string {
+	return "inbound_order_lines"
+}
+
+// InboundReceipt 表示一次收货过账，每条对应一条 receipt 库存流水
+// CrossDockQuantity 为收货后直接越库分配给出库单据的数量，其余数量生成上架任务；
+// LotNumber、ManufactureDate、ExpiryDate 为收到的批次及其生产日期与有效期，同一单据行的不同批次分别记录
+type InboundReceipt struct {
+	ID                uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	OrderID           uint       `gorm:"not null;index" json:"order_id"`
+	LineID            uint       `gorm:"not null;index" json:"line_id"`
+	MaterialCode      string     `gorm:"type:varchar(100);not null;index" json:"material_code"`
+	LocationCode      string     `gorm:"type:varchar(100);not null" json:"location_code"`
+	LotNumber         string     `gorm:"type:varchar(100);not null;default:'';index" json:"lot_number,omitempty"`
+	ManufactureDate   *time.Time `gorm:"type:date" json:"manufacture_date,omitempty"`
+	ExpiryDate        *time.Time `gorm:"type:date" json:"expiry_date,omitempty"`
+	Quantity          int        `gorm:"not null" json:"quantity"`
+	CrossDockQuantity int        `gorm:"not null;default:0" json:"cross_dock_quantity"`
+	ReceivedBy        string     `gorm:"type:varchar(100);not null" json:"received_by"`
+	Note              string     `gorm:"type:varchar(500)" json:"note,omitempty"`
+	ReceivedAt        time.Time  `gorm:"type:timestamp;not null;index" json:"received_at"`
+	CreatedAt         time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName 指定 InboundReceipt 对应的表名
+func (InboundReceipt) TableName() string {
+	return "inbound_receipts"
+}
@@ -4,21 +4,93 @@ import "time"
 
 // InventoryCheckRecord 表示单条库存盘点记录
 // 该结构保存实盘数量与系统库存的对比信息
+// 超出差异容差的盘点以 ApprovalStatus=pending、IsProcessed=false 保存，待主管审批后才调整库存
+// StockTakeID 非空的盘点属于某个盘点单，在盘点单过账前保持 IsProcessed=false
+// 复盘记录通过 ParentRecordID 指向被复盘的记录、RootRecordID 指向最初的盘点，形成复盘链；
+// 复盘链中只有最新一条记录有效，之前的记录标记为 superseded
+// ReasonCode 为盘点人给出的差异原因，PostingCategory 为盘点时该原因的过账类别快照
+// LotNumber、ManufactureDate 为所盘批次及其生产日期，启用批次管理的物料必须给出批次号；
+// ExpiryDate 为盘点时库存记录的有效期（库存没有记录时取盘点给出的有效期），Expired 标记盘点时已过有效期的物料；
+// 启用序列号管理的物料按序列号清单盘点，SerialVariances 为清单与系统登记的序列号之间的差异
 type InventoryCheckRecord struct {
-	ID             uint      `gorm:"primaryKey;autoIncrement" json:"id"`
-	CheckerID      string    `gorm:"type:varchar(100);not null;index" json:"checker_id"`
-	LocationCode   string    `gorm:"type:varchar(100);not null;index" json:"location_code"`
-	MaterialCode   string    `gorm:"type:varchar(100);not null;index" json:"material_code"`
-	ActualQuantity int       `gorm:"not null" json:"actual_quantity"`
-	StockQuantity  int       `gorm:"not null" json:"stock_quantity"`
-	Difference     int       `gorm:"not null" json:"difference"`
-	CheckTime      time.Time `gorm:"type:timestamp;not null;index" json:"check_time"`
-	IsProcessed    bool      `gorm:"default:false;not null" json:"is_processed"`
-	CreatedAt      time.Time `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt      time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+	ID              uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	StockTakeID     *uint      `gorm:"index" json:"stock_take_id,omitempty"`
+	ParentRecordID  *uint      `gorm:"index" json:"parent_record_id,omitempty"`
+	RootRecordID    *uint      `gorm:"index" json:"root_record_id,omitempty"`
+	RecountSequence int        `gorm:"not null;default:0" json:"recount_sequence"`
+	CheckerID       string     `gorm:"type:varchar(100);not null;index" json:"checker_id"`
+	LocationCode    string     `gorm:"type:varchar(100);not null;index" json:"location_code"`
+	MaterialCode    string     `gorm:"type:varchar(100);not null;index" json:"material_code"`
+	LotNumber       string     `gorm:"type:varchar(100);not null;default:'';index" json:"lot_number,omitempty"`
+	ManufactureDate *time.Time `gorm:"type:date" json:"manufacture_date,omitempty"`
+	ExpiryDate      *time.Time `gorm:"type:date" json:"expiry_date,omitempty"`
+	Expired         bool       `gorm:"not null;default:false;index" json:"expired"`
+	ActualQuantity  int        `gorm:"not null" json:"actual_quantity"`
+	StockQuantity   int        `gorm:"not null" json:"stock_quantity"`
+	Difference      int        `gorm:"not null" json:"difference"`
+	ReasonCode      string     `gorm:"type:varchar(50);index" json:"reason_code,omitempty"`
+	PostingCategory string     `gorm:"type:varchar(30);index" json:"posting_category,omitempty"`
+	Note            string     `gorm:"type:varchar(500)" json:"note,omitempty"`
+	CheckTime       time.Time  `gorm:"type:timestamp;not null;index" json:"check_time"`
+	IsProcessed     bool       `gorm:"default:false;not null" json:"is_processed"`
+	ApprovalStatus  string     `gorm:"type:varchar(20);not null;default:auto_approved;index" json:"approval_status"`
+	ApprovedBy      string     `gorm:"type:varchar(100)" json:"approved_by,omitempty"`
+	ApprovedAt      *time.Time `gorm:"type:timestamp" json:"approved_at,omitempty"`
+	ApprovalComment string     `gorm:"type:varchar(500)" json:"approval_comment,omitempty"`
+	CreatedAt       time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+
+	SerialVariances []InventoryCheckSerial `gorm:"foreignKey:RecordID" json:"serial_variances,omitempty"`
 }
 
+// 盘点差异审批状态
+const (
+	// ApprovalStatusAutoApproved 差异在容差范围内，已自动调整库存
+	ApprovalStatusAutoApproved = "auto_approved"
+	// ApprovalStatusPending 差异超出容差，等待主管审批
+	ApprovalStatusPending = "pending"
+	// ApprovalStatusApproved 主管已批准，差异已过账至库存
+	ApprovalStatusApproved = "approved"
+	// ApprovalStatusRejected 主管已驳回，库存保持不变
+	ApprovalStatusRejected = "rejected"
+	// ApprovalStatusSuperseded 已被后续复盘取代，不再参与审批与过账
+	ApprovalStatusSuperseded = "superseded"
+)
+
 // TableName 指定 InventoryCheckRecord 对应的表名
 func (InventoryCheckRecord) TableName() string {
 	return "inventory_check_records"
 }
+
+// 序列号盘点差异类型
+const (
+	// SerialVarianceMissing 系统登记在该库位批次但未盘点到的序列号
+	SerialVarianceMissing = "missing"
+	// SerialVarianceUnexpected 盘点到但系统未登记在该库位批次的序列号
+	SerialVarianceUnexpected = "unexpected"
+)
+
+// InventoryCheckSerial 表示序列号盘点中的一条序列号差异
+// 差异随盘点记录过账：missing 的序列号标记为缺失，unexpected 的序列号登记到所盘库位与批次
+type InventoryCheckSerial struct {
+	ID           uint   `gorm:"primaryKey;autoIncrement" json:"-"`
+	RecordID     uint   `gorm:"not null;index" json:"-"`
+	SerialNumber string `gorm:"type:varchar(100);not null" json:"serial_number"`
+	Variance     string `gorm:"type:varchar(20);not null" json:"variance"`
+}
+
+// TableName 指定 InventoryCheckSerial 对应的表名
+func (InventoryCheckSerial) TableName() string {
+	return "inventory_check_serials"
+}
+
+// SerialsByVariance 返回指定差异类型的序列号
+func (r *InventoryCheckRecord) SerialsByVariance(variance string) []string {
+	serials := make([]string, 0)
+	for _, v := range r.SerialVariances {
+		if v.Variance == variance {
+			serials = append(serials, v.SerialNumber)
+		}
+	}
+	return serials
+}
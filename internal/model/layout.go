@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+// WarehouseLayout 表示仓库布局的全局设置，全局只有一条（ID 固定为 1）
+// 出发点（DepotX, DepotY）为拣货与盘点路线的起止位置；FrontAisleY 与 BackAisleY 为巷道两端
+// 前、后横向通道的纵坐标，二者相等表示未配置横向通道；RoutingStrategy 为默认的路线策略
+type WarehouseLayout struct {
+	ID              uint      `gorm:"primaryKey" json:"-"`
+	DepotX          float64   `gorm:"not null;default:0" json:"depot_x"`
+	DepotY          float64   `gorm:"not null;default:0" json:"depot_y"`
+	FrontAisleY     float64   `gorm:"not null;default:0" json:"front_aisle_y"`
+	BackAisleY      float64   `gorm:"not null;default:0" json:"back_aisle_y"`
+	RoutingStrategy string    `gorm:"type:varchar(20);not null" json:"routing_strategy"`
+	UpdatedBy       string    `gorm:"type:varchar(100)" json:"updated_by,omitempty"`
+	UpdatedAt       time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName 指定 WarehouseLayout 对应的表名
+func (WarehouseLayout) TableName() string {
+	return "warehouse_layouts"
+}
+
+// LayoutEdge 表示行走图中的一条双向通路
+// 节点为库位编码、出发点（DEPOT）或自定义的路口名称；配置了通路的两点之间按图上最短路计算距离，
+// 适用于有隔断、单侧通道等不规则布局，其余点之间仍按巷道与横向通道估算
+type LayoutEdge struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	FromNode  string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_layout_edge" json:"from_node"`
+	ToNode    string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_layout_edge" json:"to_node"`
+	Distance  float64   `gorm:"not null" json:"distance"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName 指定 LayoutEdge 对应的表名
+func (LayoutEdge) TableName() string {
+	return "layout_edges"
+}
@@ -0,0 +1,77 @@
+package model
+
+import "time"
+
+// Location 表示仓库中的一个库位，所有库存作业只接受已登记、启用且未冻结的库位
+// Warehouse、Zone、Aisle、Rack、Bin 构成库位的层级（仓库 → 库区 → 巷道 → 货架 → 货位），Zone 也用于按库区组织拣货波次；
+// Type 为库位类型，隔离库位中的库存不参与出库分配与补货；
+// Sequence 为库位距收货区的远近顺序（越小越近），用于上架推荐最近的库位，也作为拣货行走顺序；
+// Capacity 为库位可容纳的最大数量（各物料合计），MaxVolumeCm3、MaxWeightKg、MaxSkus 为体积、重量与物料种类上限，0 表示不限制；
+// PutawayEnabled 为 false 的库位（如收货暂存区、月台）不会被推荐为上架目标；
+// Blocked 为 true 的库位被冻结（如货架损坏、盘点差异调查），不能发生任何库存变动，停用（Active 为 false）的库位同样不能作业；
+// Aisle、Bay、Level 与坐标 X、Y（米）描述库位在仓库布局中的位置，用于规划拣货与盘点的行走路线，
+// 未填写巷道与坐标的库位不参与路线规划，排在路线之后按 Sequence 访问
+type Location struct {
+	ID             uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Code           string    `gorm:"type:varchar(100);not null;uniqueIndex" json:"code"`
+	Warehouse      string    `gorm:"type:varchar(50);not null;default:'';index" json:"warehouse"`
+	Zone           string    `gorm:"type:varchar(50);index" json:"zone,omitempty"`
+	Type           string    `gorm:"type:varchar(20);not null;default:reserve;index" json:"type"`
+	Sequence       int       `gorm:"not null;default:0;index" json:"sequence"`
+	Capacity       int       `gorm:"not null;default:0" json:"capacity"`
+	MaxVolumeCm3   float64   `gorm:"not null;default:0" json:"max_volume_cm3"`
+	MaxWeightKg    float64   `gorm:"not null;default:0" json:"max_weight_kg"`
+	MaxSkus        int       `gorm:"not null;default:0" json:"max_skus"`
+	PutawayEnabled bool      `gorm:"not null" json:"putaway_enabled"`
+	Blocked        bool      `gorm:"not null;default:false" json:"blocked"`
+	BlockReason    string    `gorm:"type:varchar(200);not null;default:''" json:"block_reason,omitempty"`
+	Active         bool      `gorm:"not null;default:true" json:"active"`
+	Aisle          string    `gorm:"type:varchar(20);index" json:"aisle,omitempty"`
+	Rack           string    `gorm:"type:varchar(20);not null;default:''" json:"rack,omitempty"`
+	Bin            string    `gorm:"type:varchar(20);not null;default:''" json:"bin,omitempty"`
+	Bay            int       `gorm:"not null;default:0" json:"bay"`
+	Level          int       `gorm:"not null;default:0" json:"level"`
+	X              float64   `gorm:"not null;default:0" json:"x"`
+	Y              float64   `gorm:"not null;default:0" json:"y"`
+	CreatedAt      time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// 库位类型
+const (
+	// LocationTypePickFace 拣货位
+	LocationTypePickFace = "pick_face"
+	// LocationTypeReserve 存储位
+	LocationTypeReserve = "reserve"
+	// LocationTypeDock 月台与收发货暂存区
+	LocationTypeDock = "dock"
+	// LocationTypeQuarantine 隔离区，库存不参与出库分配与补货
+	LocationTypeQuarantine = "quarantine"
+	// LocationTypeVirtual 虚拟库位（如包装、越库暂存、报废），不校验容量
+	LocationTypeVirtual = "virtual"
+)
+
+// TableName 指定 Location 对应的表名
+func (Location) TableName() string {
+	return "locations"
+}
+
+// Fits 判断库位在已有 load 数量的基础上能否再放入 quantity
+func (l *Location) Fits(load, quantity int) bool {
+	return l.Capacity <= 0 || load+quantity <= l.Capacity
+}
+
+// HasLayout 判断库位是否登记了布局位置
+func (l *Location) HasLayout() bool {
+	return l.Aisle != "" || l.X != 0 || l.Y != 0
+}
+
+// IsStorage 判断库位是否为可存放上架货物的拣货位或存储位
+func (l *Location) IsStorage() bool {
+	return l.Type == LocationTypePickFace || l.Type == LocationTypeReserve
+}
+
+// Operable 判断库位是否允许发生库存变动
+func (l *Location) Operable() bool {
+	return l.Active && !l.Blocked
+}
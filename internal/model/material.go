@@ -0,0 +1,60 @@
+package model
+
+import "time"
+
+// Material 表示物料主数据，所有库存作业只接受已登记且启用的物料编码
+// 尺寸为单件物料的外形长、宽、高（厘米），WeightKg 为单件重量（千克），用于装箱时推荐箱型；
+// 尺寸未登记（任一为 0）的物料不能自动装箱，需手工指定箱型；
+// CrossDock 为 true 的物料（快速周转品）收货时优先越库满足等待中的出库单据；
+// LotManaged 为 true 的物料按批次分开记录库存，入库与盘点时必须给出批次号；
+// SerialTracked 为 true 的物料（高价值物料）逐件登记序列号，收货、移库与拣货时须给出序列号，盘点时提交序列号清单；
+// 停用的物料不能再发生库存变动，但历史库存、流水与单据保持不变
+type Material struct {
+	ID            uint              `gorm:"primaryKey;autoIncrement" json:"id"`
+	Code          string            `gorm:"type:varchar(100);not null;uniqueIndex" json:"code"`
+	Description   string            `gorm:"type:varchar(200);not null;default:''" json:"description"`
+	BaseUnit      string            `gorm:"type:varchar(20);not null;default:EA" json:"base_unit"`
+	Category      string            `gorm:"type:varchar(50);not null;default:'';index" json:"category"`
+	LengthCm      float64           `gorm:"not null;default:0" json:"length_cm"`
+	WidthCm       float64           `gorm:"not null;default:0" json:"width_cm"`
+	HeightCm      float64           `gorm:"not null;default:0" json:"height_cm"`
+	WeightKg      float64           `gorm:"not null;default:0" json:"weight_kg"`
+	CrossDock     bool              `gorm:"not null;default:false" json:"cross_dock"`
+	LotManaged    bool              `gorm:"not null;default:false" json:"lot_managed"`
+	SerialTracked bool              `gorm:"not null;default:false" json:"serial_tracked"`
+	Active        bool              `gorm:"not null;default:true" json:"active"`
+	Barcodes      []MaterialBarcode `gorm:"foreignKey:MaterialCode;references:Code" json:"barcodes"`
+	CreatedAt     time.Time         `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time         `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// DefaultBaseUnit 是未指定基本计量单位时使用的单位
+const DefaultBaseUnit = "EA"
+
+// TableName 指定 Material 对应的表名
+func (Material) TableName() string {
+	return "materials"
+}
+
+// HasDimensions 判断物料是否登记了完整的外形尺寸
+func (m *Material) HasDimensions() bool {
+	return m.LengthCm > 0 && m.WidthCm > 0 && m.HeightCm > 0
+}
+
+// Volume 返回单件物料的体积（立方厘米）
+func (m *Material) Volume() float64 {
+	return m.LengthCm * m.WidthCm * m.HeightCm
+}
+
+// MaterialBarcode 表示物料的条码，一个物料可有多个条码（如不同包装或供应商的条码），条码全局唯一
+type MaterialBarcode struct {
+	ID           uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	MaterialCode string    `gorm:"type:varchar(100);not null;index" json:"material_code"`
+	Barcode      string    `gorm:"type:varchar(100);not null;uniqueIndex" json:"barcode"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName 指定 MaterialBarcode 对应的表名
+func (MaterialBarcode) TableName() string {
+	return "material_barcodes"
+}
@@ -0,0 +1,142 @@
+package model
+
+import "time"
+
+// OutboundOrder 表示一张出库（销售）单据
+// 生命周期：open -> partially_allocated / allocated -> picking（已排入波次）-> picked -> packed（已全部装箱）-> shipped；
+// 拣货开始前可取消（cancelled），取消时释放全部预留
+// AllocationStrategy 为空时使用系统默认的分配策略；Carrier 与 CarrierCutoffAt 用于按承运商截单时间组波次；
+// StagingLocation 为组波时确定的包装暂存库位，已拣货物在该库位为本单据预留，发运确认时从该库位扣减
+type OutboundOrder struct {
+	ID                 uint                `gorm:"primaryKey;autoIncrement" json:"id"`
+	OrderNo            string              `gorm:"type:varchar(50);not null;uniqueIndex" json:"order_no"`
+	CustomerCode       string              `gorm:"type:varchar(100);index" json:"customer_code,omitempty"`
+	ShipTo             string              `gorm:"type:varchar(500)" json:"ship_to,omitempty"`
+	RequiredAt         *time.Time          `gorm:"type:timestamp" json:"required_at,omitempty"`
+	Carrier            string              `gorm:"type:varchar(50);index" json:"carrier,omitempty"`
+	CarrierCutoffAt    *time.Time          `gorm:"type:timestamp;index" json:"carrier_cutoff_at,omitempty"`
+	AllocationStrategy string              `gorm:"type:varchar(30)" json:"allocation_strategy,omitempty"`
+	StagingLocation    string              `gorm:"type:varchar(100)" json:"staging_location,omitempty"`
+	Status             string              `gorm:"type:varchar(30);not null;default:open;index" json:"status"`
+	CreatedBy          string              `gorm:"type:varchar(100);not null" json:"created_by"`
+	CancelledBy        string              `gorm:"type:varchar(100)" json:"cancelled_by,omitempty"`
+	CancelledAt        *time.Time          `gorm:"type:timestamp" json:"cancelled_at,omitempty"`
+	CancelReason       string              `gorm:"type:varchar(500)" json:"cancel_reason,omitempty"`
+	ShippedAt          *time.Time          `gorm:"type:timestamp" json:"shipped_at,omitempty"`
+	Lines              []OutboundOrderLine `gorm:"foreignKey:OrderID;constraint:OnDelete:CASCADE" json:"lines"`
+	CreatedAt          time.Time           `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt          time.Time           `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// 出库单据状态
+const (
+	// OutboundOrderStatusOpen 已创建，尚未分配
+	OutboundOrderStatusOpen = "open"
+	// OutboundOrderStatusPartiallyAllocated 部分行未分配足量，可再次分配
+	OutboundOrderStatusPartiallyAllocated = "partially_allocated"
+	// OutboundOrderStatusAllocated 全部行已分配足量
+	OutboundOrderStatusAllocated = "allocated"
+	// OutboundOrderStatusPicking 已排入波次，拣货中
+	OutboundOrderStatusPicking = "picking"
+	// OutboundOrderStatusPicked 全部拣货任务已确认
+	OutboundOrderStatusPicked = "picked"
+	// OutboundOrderStatusPacked 已拣数量全部装箱，等待发运
+	OutboundOrderStatusPacked = "packed"
+	// OutboundOrderStatusShipped 已发运，暂存库存已扣减
+	OutboundOrderStatusShipped = "shipped"
+	// OutboundOrderStatusCancelled 已取消，预留已释放
+	OutboundOrderStatusCancelled = "cancelled"
+)
+
+// 出库分配策略
+const (
+	// AllocationStrategyFIFO 按库存入库时间先进先出
+	AllocationStrategyFIFO = "fifo"
+	// AllocationStrategyFEFO 按库存有效期先到期先出，没有有效期的库存排在最后，有效期相同时先进先出
+	AllocationStrategyFEFO = "fefo"
+	// AllocationStrategyFewestLocations 尽量少的库位：优先整单由一个库位满足，否则从可用量最大的库位开始
+	AllocationStrategyFewestLocations = "fewest_locations"
+	// AllocationStrategyClearSmallBins 优先清空零散库位：从可用量最小的库位开始
+	AllocationStrategyClearSmallBins = "clear_small_bins"
+	// AllocationStrategyCrossDock 越库：收货时直接将到货分配给等待中的单据，仅用于记录预留来源，不能作为单据的分配策略
+	AllocationStrategyCrossDock = "cross_dock"
+)
+
+// TableName 指定 OutboundOrder 对应的表名
+func (OutboundOrder) TableName() string {
+	return "outbound_orders"
+}
+
+// OutboundOrderLine 表示出库单据的物料行
+// Status 随分配更新：未分配为 open，部分分配为 partial，分配足量为 allocated；
+// 拣货完成后拣足为 picked，短拣为 short（短拣数量从 AllocatedQuantity 中扣除）；
+// PackedQuantity 为已装箱数量，ShippedQuantity 为发运确认时出库的数量
+type OutboundOrderLine struct {
+	ID                uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	OrderID           uint      `gorm:"not null;uniqueIndex:idx_outbound_order_line" json:"order_id"`
+	LineNo            int       `gorm:"not null;uniqueIndex:idx_outbound_order_line" json:"line_no"`
+	MaterialCode      string    `gorm:"type:varchar(100);not null;index" json:"material_code"`
+	OrderedQuantity   int       `gorm:"not null" json:"ordered_quantity"`
+	AllocatedQuantity int       `gorm:"not null;default:0" json:"allocated_quantity"`
+	PickedQuantity    int       `gorm:"not null;default:0" json:"picked_quantity"`
+	PackedQuantity    int       `gorm:"not null;default:0" json:"packed_quantity"`
+	ShippedQuantity   int       `gorm:"not null;default:0" json:"shipped_quantity"`
+	Status            string    `gorm:"type:varchar(20);not null;default:open" json:"status"`
+	UpdatedAt         time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// 出库单据行状态
+const (
+	// OutboundLineStatusOpen 尚未分配
+	OutboundLineStatusOpen = "open"
+	// OutboundLineStatusPartial 部分分配
+	OutboundLineStatusPartial = "partial"
+	// OutboundLineStatusAllocated 已分配足量
+	OutboundLineStatusAllocated = "allocated"
+	// OutboundLineStatusPicked 已拣足
+	OutboundLineStatusPicked = "picked"
+	// OutboundLineStatusShort 拣货完成但未拣足
+	OutboundLineStatusShort = "short"
+)
+
+// TableName 指定 OutboundOrderLine 对应的表名
+func (OutboundOrderLine) TableName() string {
+	return "outbound_order_lines"
+}
+
+// StockAllocation 表示出库单据行对某个库存行（物料、库位、批次）的预留
+// 预留期间对应数量计入 Stock.ReservedQuantity；排入波次后关联到拣货任务（PickTaskID），
+// 拣货确认时将实拣数量移至包装暂存库位（在暂存库位继续为单据预留）并改为 picked，取消单据时状态改为 released 并扣回预留数量
+type StockAllocation struct {
+	ID             uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	OrderID        uint       `gorm:"not null;index" json:"order_id"`
+	LineID         uint       `gorm:"not null;index" json:"line_id"`
+	StockID        uint       `gorm:"not null;index" json:"stock_id"`
+	MaterialCode   string     `gorm:"type:varchar(100);not null;index" json:"material_code"`
+	LocationCode   string     `gorm:"type:varchar(100);not null;index" json:"location_code"`
+	LotNumber      string     `gorm:"type:varchar(100);not null;default:'';index" json:"lot_number,omitempty"`
+	Quantity       int        `gorm:"not null" json:"quantity"`
+	PickTaskID     *uint      `gorm:"index" json:"pick_task_id,omitempty"`
+	PickedQuantity int        `gorm:"not null;default:0" json:"picked_quantity"`
+	Strategy       string     `gorm:"type:varchar(30);not null" json:"strategy"`
+	Status         string     `gorm:"type:varchar(20);not null;default:active;index" json:"status"`
+	AllocatedBy    string     `gorm:"type:varchar(100);not null" json:"allocated_by"`
+	PickedAt       *time.Time `gorm:"type:timestamp" json:"picked_at,omitempty"`
+	ReleasedAt     *time.Time `gorm:"type:timestamp" json:"released_at,omitempty"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// 库存预留状态
+const (
+	// AllocationStatusActive 预留中
+	AllocationStatusActive = "active"
+	// AllocationStatusPicked 已拣货确认
+	AllocationStatusPicked = "picked"
+	// AllocationStatusReleased 已释放
+	AllocationStatusReleased = "released"
+)
+
+// TableName 指定 StockAllocation 对应的表名
+func (StockAllocation) TableName() string {
+	return "stock_allocations"
+}
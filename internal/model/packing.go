@@ -0,0 +1,99 @@
+package model
+
+import "time"
+
+// CartonType 表示箱型主数据
+// 尺寸为箱子的内部长、宽、高（厘米）；MaxWeightKg 为可装载的最大重量，0 表示不限制；
+// TareWeightKg 为空箱重量，计入包裹毛重；停用（Active 为 false）的箱型不参与推荐也不能手工使用
+type CartonType struct {
+	ID           uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Code         string    `gorm:"type:varchar(50);not null;uniqueIndex" json:"code"`
+	Description  string    `gorm:"type:varchar(200)" json:"description,omitempty"`
+	LengthCm     float64   `gorm:"not null" json:"length_cm"`
+	WidthCm      float64   `gorm:"not null" json:"width_cm"`
+	HeightCm     float64   `gorm:"not null" json:"height_cm"`
+	MaxWeightKg  float64   `gorm:"not null;default:0" json:"max_weight_kg"`
+	TareWeightKg float64   `gorm:"not null;default:0" json:"tare_weight_kg"`
+	Active       bool      `gorm:"not null" json:"active"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName 指定 CartonType 对应的表名
+func (CartonType) TableName() string {
+	return "carton_types"
+}
+
+// Volume 返回箱子的内部容积（立方厘米）
+func (c *CartonType) Volume() float64 {
+	return c.LengthCm * c.WidthCm * c.HeightCm
+}
+
+// Package 表示包装台上为出库单据装好的一个包裹
+// PackageNo 由单据号与包裹序号（Sequence）组成（如 SO001-002），贴在箱外用于发运扫描；
+// 长、宽、高为装箱时箱型的尺寸快照，GrossWeightKg 为内装物料重量与空箱重量之和（物料未登记重量时按 0 计）；
+// 发运确认后关联到发运记录（ShipmentID），不能再拆箱
+type Package struct {
+	ID             uint          `gorm:"primaryKey;autoIncrement" json:"id"`
+	PackageNo      string        `gorm:"type:varchar(80);not null;uniqueIndex" json:"package_no"`
+	OrderID        uint          `gorm:"not null;uniqueIndex:idx_package_order_seq" json:"order_id"`
+	Sequence       int           `gorm:"not null;uniqueIndex:idx_package_order_seq" json:"sequence"`
+	CartonTypeCode string        `gorm:"type:varchar(50);not null" json:"carton_type_code"`
+	LengthCm       float64       `gorm:"not null;default:0" json:"length_cm"`
+	WidthCm        float64       `gorm:"not null;default:0" json:"width_cm"`
+	HeightCm       float64       `gorm:"not null;default:0" json:"height_cm"`
+	GrossWeightKg  float64       `gorm:"not null;default:0" json:"gross_weight_kg"`
+	Status         string        `gorm:"type:varchar(20);not null;default:packed;index" json:"status"`
+	PackStation    string        `gorm:"type:varchar(50)" json:"pack_station,omitempty"`
+	PackedBy       string        `gorm:"type:varchar(100);not null" json:"packed_by"`
+	ShipmentID     *uint         `gorm:"index" json:"shipment_id,omitempty"`
+	Lines          []PackageLine `gorm:"foreignKey:PackageID;constraint:OnDelete:CASCADE" json:"lines"`
+	CreatedAt      time.Time     `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time     `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// 包裹状态
+const (
+	// PackageStatusPacked 已装箱，等待发运
+	PackageStatusPacked = "packed"
+	// PackageStatusShipped 已发运
+	PackageStatusShipped = "shipped"
+)
+
+// TableName 指定 Package 对应的表名
+func (Package) TableName() string {
+	return "packages"
+}
+
+// PackageLine 表示包裹内某个出库单据行的装箱数量
+type PackageLine struct {
+	ID           uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	PackageID    uint   `gorm:"not null;index" json:"package_id"`
+	OrderLineID  uint   `gorm:"not null;index" json:"order_line_id"`
+	MaterialCode string `gorm:"type:varchar(100);not null" json:"material_code"`
+	Quantity     int    `gorm:"not null" json:"quantity"`
+}
+
+// TableName 指定 PackageLine 对应的表名
+func (PackageLine) TableName() string {
+	return "package_lines"
+}
+
+// Shipment 表示一张出库单据的发运记录
+// 发运确认时从包装暂存库位扣减已拣数量并关闭单据，每张单据只发运一次
+type Shipment struct {
+	ID            uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	OrderID       uint      `gorm:"not null;uniqueIndex" json:"order_id"`
+	Carrier       string    `gorm:"type:varchar(50);index" json:"carrier,omitempty"`
+	TrackingNo    string    `gorm:"type:varchar(100);index" json:"tracking_no,omitempty"`
+	PackageCount  int       `gorm:"not null" json:"package_count"`
+	TotalWeightKg float64   `gorm:"not null;default:0" json:"total_weight_kg"`
+	ShippedBy     string    `gorm:"type:varchar(100);not null" json:"shipped_by"`
+	ShippedAt     time.Time `gorm:"type:timestamp;not null" json:"shipped_at"`
+	CreatedAt     time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName 指定 Shipment 对应的表名
+func (Shipment) TableName() string {
+	return "shipments"
+}
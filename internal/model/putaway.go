@@ -0,0 +1,83 @@
+package model
+
+import "time"
+
+// PutawayPolicy 表示上架推荐规则，全局只有一条（ID 固定为 1）
+// Strategies 为逗号分隔的推荐策略，按顺序尝试，首个找到目标库位的策略生效；
+// EnforceCapacity 为 true 时推荐与确认上架都不允许超出库位容量
+type PutawayPolicy struct {
+	ID              uint      `gorm:"primaryKey" json:"-"`
+	Strategies      string    `gorm:"type:varchar(200);not null" json:"strategies"`
+	EnforceCapacity bool      `gorm:"not null" json:"enforce_capacity"`
+	UpdatedBy       string    `gorm:"type:varchar(100)" json:"updated_by,omitempty"`
+	UpdatedAt       time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// 上架推荐策略
+const (
+	// PutawayStrategyFixedBin 物料的固定库位
+	PutawayStrategyFixedBin = "fixed_bin"
+	// PutawayStrategyConsolidate 与该物料已有库存合并存放
+	PutawayStrategyConsolidate = "consolidate"
+	// PutawayStrategyNearestEmpty 最近的空库位
+	PutawayStrategyNearestEmpty = "nearest_empty"
+)
+
+// TableName 指定 PutawayPolicy 对应的表名
+func (PutawayPolicy) TableName() string {
+	return "putaway_policies"
+}
+
+// PutawayFixedBin 表示物料的固定上架库位
+type PutawayFixedBin struct {
+	ID           uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	MaterialCode string    `gorm:"type:varchar(100);not null;uniqueIndex" json:"material_code"`
+	LocationCode string    `gorm:"type:varchar(100);not null" json:"location_code"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName 指定 PutawayFixedBin 对应的表名
+func (PutawayFixedBin) TableName() string {
+	return "putaway_fixed_bins"
+}
+
+// PutawayTask 表示将物料从收货库位搬到存储库位的上架任务
+// ReceiptID 与 ReturnReceiptID 分别关联生成任务的入库收货记录与退货收货记录，手工创建的任务两者均为空；
+// SuggestedLocation 为创建任务时系统推荐的库位（没有可用库位时为空），TargetLocation 为实际上架库位，
+// 默认等于推荐库位，可在确认前改派（override）；确认时源库位与目标库位的库存在同一事务内变更，
+// LotNumber 为搬运的批次，未启用批次管理的物料为空
+type PutawayTask struct {
+	ID                uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	ReceiptID         *uint      `gorm:"index" json:"receipt_id,omitempty"`
+	ReturnReceiptID   *uint      `gorm:"index" json:"return_receipt_id,omitempty"`
+	MaterialCode      string     `gorm:"type:varchar(100);not null;index" json:"material_code"`
+	LotNumber         string     `gorm:"type:varchar(100);not null;default:''" json:"lot_number,omitempty"`
+	SourceLocation    string     `gorm:"type:varchar(100);not null;index" json:"source_location"`
+	SuggestedLocation string     `gorm:"type:varchar(100)" json:"suggested_location,omitempty"`
+	Strategy          string     `gorm:"type:varchar(30)" json:"strategy,omitempty"`
+	TargetLocation    string     `gorm:"type:varchar(100);index" json:"target_location,omitempty"`
+	Quantity          int        `gorm:"not null" json:"quantity"`
+	Status            string     `gorm:"type:varchar(20);not null;default:open;index" json:"status"`
+	AssignedTo        string     `gorm:"type:varchar(100);index" json:"assigned_to,omitempty"`
+	OverriddenBy      string     `gorm:"type:varchar(100)" json:"overridden_by,omitempty"`
+	OverrideReason    string     `gorm:"type:varchar(500)" json:"override_reason,omitempty"`
+	ConfirmedBy       string     `gorm:"type:varchar(100)" json:"confirmed_by,omitempty"`
+	ConfirmedAt       *time.Time `gorm:"type:timestamp" json:"confirmed_at,omitempty"`
+	CreatedBy         string     `gorm:"type:varchar(100);not null" json:"created_by"`
+	CreatedAt         time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt         time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// 上架任务状态
+const (
+	// PutawayTaskStatusOpen 等待上架
+	PutawayTaskStatusOpen = "open"
+	// PutawayTaskStatusCompleted 已确认上架
+	PutawayTaskStatusCompleted = "completed"
+)
+
+// TableName 指定 PutawayTask 对应的表名
+func (PutawayTask) TableName() string {
+	return "putaway_tasks"
+}
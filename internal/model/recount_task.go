@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// RecountTask 表示超出容差的盘点触发的复盘任务
+// 复盘必须由复盘链中未参与过盘点的盘点人执行；RecordID 为待复盘的记录（复盘链的最新记录）
+// AssignedTo 为空时任何符合条件的盘点人均可领取；LotNumber 为待复盘记录的批次，复盘须盘点同一批次
+type RecountTask struct {
+	ID             uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	RecordID       uint       `gorm:"not null;index" json:"record_id"`
+	RootRecordID   uint       `gorm:"not null;index" json:"root_record_id"`
+	StockTakeID    *uint      `gorm:"index" json:"stock_take_id,omitempty"`
+	MaterialCode   string     `gorm:"type:varchar(100);not null" json:"material_code"`
+	LocationCode   string     `gorm:"type:varchar(100);not null" json:"location_code"`
+	LotNumber      string     `gorm:"type:varchar(100);not null;default:''" json:"lot_number,omitempty"`
+	AssignedTo     string     `gorm:"type:varchar(100);index" json:"assigned_to,omitempty"`
+	Status         string     `gorm:"type:varchar(20);not null;default:open;index" json:"status"`
+	ResultRecordID *uint      `json:"result_record_id,omitempty"`
+	ClosedAt       *time.Time `gorm:"type:timestamp" json:"closed_at,omitempty"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// 复盘任务状态
+const (
+	// RecountTaskStatusOpen 等待复盘
+	RecountTaskStatusOpen = "open"
+	// RecountTaskStatusCompleted 已提交复盘
+	RecountTaskStatusCompleted = "completed"
+	// RecountTaskStatusCancelled 主管已直接审批或驳回差异，复盘不再需要
+	RecountTaskStatusCancelled = "cancelled"
+)
+
+// TableName 指定 RecountTask 对应的表名
+func (RecountTask) TableName() string {
+	return "recount_tasks"
+}
@@ -0,0 +1,71 @@
+package model
+
+import "time"
+
+// ReplenishmentRule 表示拣货位（pick-face）上某物料的最小/最大库存水位
+// 拣货位的在库数量加上未完成补货任务的数量低于 MinQuantity 时，从存储区补货至 MaxQuantity
+type ReplenishmentRule struct {
+	ID           uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	MaterialCode string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_replenishment_rule" json:"material_code"`
+	LocationCode string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_replenishment_rule;index" json:"location_code"`
+	MinQuantity  int       `gorm:"not null" json:"min_quantity"`
+	MaxQuantity  int       `gorm:"not null" json:"max_quantity"`
+	Active       bool      `gorm:"not null" json:"active"`
+	UpdatedBy    string    `gorm:"type:varchar(100)" json:"updated_by,omitempty"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName 指定 ReplenishmentRule 对应的表名
+func (ReplenishmentRule) TableName() string {
+	return "replenishment_rules"
+}
+
+// ReplenishmentTask 表示一条从存储库位搬到拣货位的补货任务
+// 创建任务时预留来源库存，确认时按任务数量移库并消耗预留，取消时释放预留；LotNumber 为来源库存的批次
+type ReplenishmentTask struct {
+	ID             uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	RuleID         uint       `gorm:"not null;index" json:"rule_id"`
+	MaterialCode   string     `gorm:"type:varchar(100);not null;index:idx_replenishment_target" json:"material_code"`
+	SourceLocation string     `gorm:"type:varchar(100);not null;index" json:"source_location"`
+	LotNumber      string     `gorm:"type:varchar(100);not null;default:''" json:"lot_number,omitempty"`
+	TargetLocation string     `gorm:"type:varchar(100);not null;index:idx_replenishment_target" json:"target_location"`
+	Quantity       int        `gorm:"not null" json:"quantity"`
+	Trigger        string     `gorm:"type:varchar(20);not null" json:"trigger"`
+	Status         string     `gorm:"type:varchar(20);not null;default:open;index" json:"status"`
+	CreatedBy      string     `gorm:"type:varchar(100);not null" json:"created_by"`
+	ConfirmedBy    string     `gorm:"type:varchar(100)" json:"confirmed_by,omitempty"`
+	ConfirmedAt    *time.Time `gorm:"type:timestamp" json:"confirmed_at,omitempty"`
+	CancelledBy    string     `gorm:"type:varchar(100)" json:"cancelled_by,omitempty"`
+	CancelledAt    *time.Time `gorm:"type:timestamp" json:"cancelled_at,omitempty"`
+	CancelReason   string     `gorm:"type:varchar(500)" json:"cancel_reason,omitempty"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// 补货任务状态
+const (
+	// ReplenishmentStatusOpen 等待补货
+	ReplenishmentStatusOpen = "open"
+	// ReplenishmentStatusCompleted 已确认补货
+	ReplenishmentStatusCompleted = "completed"
+	// ReplenishmentStatusCancelled 已取消，来源库存的预留已释放
+	ReplenishmentStatusCancelled = "cancelled"
+)
+
+// 补货任务的触发来源
+const (
+	// ReplenishmentTriggerOnDemand 手工触发的补货扫描
+	ReplenishmentTriggerOnDemand = "on_demand"
+	// ReplenishmentTriggerScheduled 定时补货扫描
+	ReplenishmentTriggerScheduled = "scheduled"
+	// ReplenishmentTriggerPick 拣货确认后拣货位低于最小水位
+	ReplenishmentTriggerPick = "pick"
+	// ReplenishmentTriggerStockTake 盘点调整后拣货位低于最小水位
+	ReplenishmentTriggerStockTake = "stock_take"
+)
+
+// TableName 指定 ReplenishmentTask 对应的表名
+func (ReplenishmentTask) TableName() string {
+	return "replenishment_tasks"
+}
@@ -0,0 +1,104 @@
+package model
+
+import "time"
+
+// ReturnOrder 表示一张客户退货授权（RMA），引用原出库单据的发运记录
+// 生命周期：open -> receiving -> completed（全部授权数量已收回），未收货的 RMA 可取消（cancelled）
+// 每行的授权数量合计（不含已取消的 RMA）不能超过原出库单据行的已发运数量
+type ReturnOrder struct {
+	ID           uint              `gorm:"primaryKey;autoIncrement" json:"id"`
+	RMANo        string            `gorm:"column:rma_no;type:varchar(50);not null;uniqueIndex" json:"rma_no"`
+	ShipmentID   uint              `gorm:"not null;index" json:"shipment_id"`
+	OrderID      uint              `gorm:"not null;index" json:"order_id"`
+	OrderNo      string            `gorm:"type:varchar(50);not null;index" json:"order_no"`
+	CustomerCode string            `gorm:"type:varchar(100);index" json:"customer_code,omitempty"`
+	Reason       string            `gorm:"type:varchar(500)" json:"reason,omitempty"`
+	Status       string            `gorm:"type:varchar(20);not null;default:open;index" json:"status"`
+	CreatedBy    string            `gorm:"type:varchar(100);not null" json:"created_by"`
+	CancelledBy  string            `gorm:"type:varchar(100)" json:"cancelled_by,omitempty"`
+	CancelledAt  *time.Time        `gorm:"type:timestamp" json:"cancelled_at,omitempty"`
+	CancelReason string            `gorm:"type:varchar(500)" json:"cancel_reason,omitempty"`
+	Lines        []ReturnOrderLine `gorm:"foreignKey:ReturnID;constraint:OnDelete:CASCADE" json:"lines"`
+	CreatedAt    time.Time         `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time         `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// 退货单据状态
+const (
+	// ReturnStatusOpen 已授权，尚未收货
+	ReturnStatusOpen = "open"
+	// ReturnStatusReceiving 已部分收货
+	ReturnStatusReceiving = "receiving"
+	// ReturnStatusCompleted 全部授权数量已收回
+	ReturnStatusCompleted = "completed"
+	// ReturnStatusCancelled 未收货即取消
+	ReturnStatusCancelled = "cancelled"
+)
+
+// TableName 指定 ReturnOrder 对应的表名
+func (ReturnOrder) TableName() string {
+	return "return_orders"
+}
+
+// ReturnOrderLine 表示退货授权的物料行，对应原出库单据的一行
+// Status 随收货更新：未收足为 open，收足为 complete
+type ReturnOrderLine struct {
+	ID                 uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	ReturnID           uint      `gorm:"not null;uniqueIndex:idx_return_order_line" json:"return_id"`
+	LineNo             int       `gorm:"not null;uniqueIndex:idx_return_order_line" json:"line_no"`
+	OrderLineID        uint      `gorm:"not null;index" json:"order_line_id"`
+	MaterialCode       string    `gorm:"type:varchar(100);not null;index" json:"material_code"`
+	AuthorizedQuantity int       `gorm:"not null" json:"authorized_quantity"`
+	ReceivedQuantity   int       `gorm:"not null;default:0" json:"received_quantity"`
+	Status             string    `gorm:"type:varchar(20);not null;default:open" json:"status"`
+	UpdatedAt          time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// 退货单据行状态
+const (
+	// ReturnLineStatusOpen 未收足
+	ReturnLineStatusOpen = "open"
+	// ReturnLineStatusComplete 已按授权数量收足
+	ReturnLineStatusComplete = "complete"
+)
+
+// TableName 指定 ReturnOrderLine 对应的表名
+func (ReturnOrderLine) TableName() string {
+	return "return_order_lines"
+}
+
+// ReturnReceipt 表示一次退货收货检验，每条对应一条 return 库存流水
+// Disposition 决定数量过账的库位：restock 过账到退货暂存库位并生成上架任务，
+// quarantine、scrap、return_to_vendor 过账到对应的隔离、报废、退供应商库位，这些库位不参与出库分配；
+// LotNumber 为退回的批次，启用批次管理的物料必须给出
+type ReturnReceipt struct {
+	ID             uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	ReturnID       uint      `gorm:"not null;index" json:"return_id"`
+	LineID         uint      `gorm:"not null;index" json:"line_id"`
+	MaterialCode   string    `gorm:"type:varchar(100);not null;index" json:"material_code"`
+	LotNumber      string    `gorm:"type:varchar(100);not null;default:'';index" json:"lot_number,omitempty"`
+	Quantity       int       `gorm:"not null" json:"quantity"`
+	Disposition    string    `gorm:"type:varchar(30);not null;index" json:"disposition"`
+	LocationCode   string    `gorm:"type:varchar(100);not null" json:"location_code"`
+	InspectionNote string    `gorm:"type:varchar(500)" json:"inspection_note,omitempty"`
+	ReceivedBy     string    `gorm:"type:varchar(100);not null" json:"received_by"`
+	ReceivedAt     time.Time `gorm:"type:timestamp;not null;index" json:"received_at"`
+	CreatedAt      time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// 退货处置方式
+const (
+	// DispositionRestock 检验合格，重新上架可售
+	DispositionRestock = "restock"
+	// DispositionQuarantine 待进一步检验，隔离存放
+	DispositionQuarantine = "quarantine"
+	// DispositionScrap 报废
+	DispositionScrap = "scrap"
+	// DispositionReturnToVendor 退回供应商
+	DispositionReturnToVendor = "return_to_vendor"
+)
+
+// TableName 指定 ReturnReceipt 对应的表名
+func (ReturnReceipt) TableName() string {
+	return "return_receipts"
+}
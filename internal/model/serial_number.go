@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+// 序列号状态
+const (
+	SerialStatusInStock = "in_stock"
+	SerialStatusShipped = "shipped"
+	SerialStatusMissing = "missing"
+)
+
+// SerialNumber 表示启用序列号管理的物料的单件序列号，序列号按物料唯一
+// Status 为 in_stock 时 LocationCode、LotNumber 为该件当前所在的库位与批次；
+// 拣货后 OutboundOrderID 记录该件所属的出库单据，发运后 Status 为 shipped；
+// 盘点未找到的序列号在差异过账时标记为 missing，之后再次收货或盘点到时恢复为 in_stock；
+// LastReferenceType、LastReferenceID 为最近一次改变该件位置或状态的单据
+type SerialNumber struct {
+	ID                uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	MaterialCode      string     `gorm:"type:varchar(100);not null;uniqueIndex:idx_serial_material" json:"material_code"`
+	SerialNumber      string     `gorm:"type:varchar(100);not null;uniqueIndex:idx_serial_material" json:"serial_number"`
+	LocationCode      string     `gorm:"type:varchar(100);not null;default:'';index" json:"location_code"`
+	LotNumber         string     `gorm:"type:varchar(100);not null;default:''" json:"lot_number,omitempty"`
+	Status            string     `gorm:"type:varchar(20);not null;index" json:"status"`
+	OutboundOrderID   *uint      `gorm:"index" json:"outbound_order_id,omitempty"`
+	LastReferenceType string     `gorm:"type:varchar(50)" json:"last_reference_type,omitempty"`
+	LastReferenceID   string     `gorm:"type:varchar(100)" json:"last_reference_id,omitempty"`
+	ReceivedAt        *time.Time `gorm:"type:timestamp" json:"received_at,omitempty"`
+	CreatedAt         time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt         time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName 指定 SerialNumber 对应的表名
+func (SerialNumber) TableName() string {
+	return "serial_numbers"
+}
@@ -2,17 +2,52 @@ package model
 
 import "time"
 
-// Stock 表示特定库位中某物料的当前库存
+// Stock 表示特定库位中某物料某批次的当前库存
+// 库存按 (物料, 库位, 批次) 唯一，未启用批次管理的物料批次号为空字符串；ManufactureDate 为批次的生产日期；
+// Quantity 为在库数量（盘点以此为准）；ReservedQuantity 为已分配给出库单、尚未拣货的数量，
+// 两者分开记录，盘点调整只改变在库数量；ReceivedAt 为该库存中最早一批货物的入库时间，用于先进先出分配；
+// ExpiryDate 为有效期（当天仍可使用），同一库存行收到多个有效期时记录最早的一个，用于先到期先出分配与过期冻结
 type Stock struct {
-	ID           uint      `gorm:"primaryKey;autoIncrement" json:"id"`
-	MaterialCode string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_material_location" json:"material_code"`
-	LocationCode string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_material_location" json:"location_code"`
-	Quantity     int       `gorm:"not null;default:0" json:"quantity"`
-	CreatedAt    time.Time `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt    time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+	ID               uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	MaterialCode     string     `gorm:"type:varchar(100);not null;uniqueIndex:idx_material_location_lot" json:"material_code"`
+	LocationCode     string     `gorm:"type:varchar(100);not null;uniqueIndex:idx_material_location_lot" json:"location_code"`
+	LotNumber        string     `gorm:"type:varchar(100);not null;default:'';uniqueIndex:idx_material_location_lot;index" json:"lot_number,omitempty"`
+	ManufactureDate  *time.Time `gorm:"type:date" json:"manufacture_date,omitempty"`
+	ExpiryDate       *time.Time `gorm:"type:date;index" json:"expiry_date,omitempty"`
+	Quantity         int        `gorm:"not null;default:0" json:"quantity"`
+	ReservedQuantity int        `gorm:"not null;default:0" json:"reserved_quantity"`
+	ReceivedAt       *time.Time `gorm:"type:timestamp" json:"received_at,omitempty"`
+	CreatedAt        time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt        time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
 }
 
+// LegacyStockUniqueIndex 是启用批次前 (物料, 库位) 唯一索引的名称，迁移时删除
+const LegacyStockUniqueIndex = "idx_material_location"
+
 // TableName 指定 Stock 对应的表名
 func (Stock) TableName() string {
 	return "stocks"
 }
+
+// Available 返回可分配数量（在库减已预留，不小于 0）
+func (s *Stock) Available() int {
+	if available := s.Quantity - s.ReservedQuantity; available > 0 {
+		return available
+	}
+	return 0
+}
+
+// Expired 判断库存在 now 所在日期是否已过有效期：有效期当天仍可使用，次日起视为过期；没有有效期的库存不会过期
+func (s *Stock) Expired(now time.Time) bool {
+	return ExpiredOn(s.ExpiryDate, now)
+}
+
+// ExpiredOn 判断有效期 expiry 在 now 所在日期是否已过，只比较日期部分
+func ExpiredOn(expiry *time.Time, now time.Time) bool {
+	if expiry == nil {
+		return false
+	}
+	ey, em, ed := expiry.Date()
+	ny, nm, nd := now.Date()
+	return time.Date(ey, em, ed, 0, 0, 0, 0, time.UTC).Before(time.Date(ny, nm, nd, 0, 0, 0, 0, time.UTC))
+}
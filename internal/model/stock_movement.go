@@ -0,0 +1,74 @@
+package model
+
+import "time"
+
+// StockMovement 表示一条库存流水
+// 每次库存数量变化都会在同一事务内追加一条流水，流水只增不改，
+// 因此任意 (物料, 库位, 批次) 的流水 delta 之和应等于 stocks.quantity
+// 盘点调整流水的 ReasonCode 记录差异原因代码
+type StockMovement struct {
+	ID            uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	MovementType  string    `gorm:"type:varchar(30);not null;index" json:"movement_type"`
+	ReferenceType string    `gorm:"type:varchar(50);index:idx_movement_reference" json:"reference_type"`
+	ReferenceID   string    `gorm:"type:varchar(100);index:idx_movement_reference" json:"reference_id"`
+	MaterialCode  string    `gorm:"type:varchar(100);not null;index:idx_movement_material_location" json:"material_code"`
+	LocationCode  string    `gorm:"type:varchar(100);not null;index:idx_movement_material_location" json:"location_code"`
+	LotNumber     string    `gorm:"type:varchar(100);not null;default:'';index" json:"lot_number,omitempty"`
+	FromLocation  string    `gorm:"type:varchar(100)" json:"from_location,omitempty"`
+	ToLocation    string    `gorm:"type:varchar(100)" json:"to_location,omitempty"`
+	Delta         int       `gorm:"not null" json:"delta"`
+	BalanceAfter  int       `gorm:"not null" json:"balance_after"`
+	ReasonCode    string    `gorm:"type:varchar(50)" json:"reason_code,omitempty"`
+	OperatorID    string    `gorm:"type:varchar(100)" json:"operator_id"`
+	MovedAt       time.Time `gorm:"type:timestamp;not null;index" json:"moved_at"`
+	CreatedAt     time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// 库存流水类型
+const (
+	// MovementTypeOpeningBalance 期初余额（启用流水前已存在的库存）
+	MovementTypeOpeningBalance = "opening_balance"
+	// MovementTypeStockTakeAdjustment 盘点差异调整
+	MovementTypeStockTakeAdjustment = "stocktake_adjustment"
+	// MovementTypeReceipt 入库收货
+	MovementTypeReceipt = "receipt"
+	// MovementTypePutaway 上架（收货库位移至存储库位）
+	MovementTypePutaway = "putaway"
+	// MovementTypeTransfer 库位间移库
+	MovementTypeTransfer = "transfer"
+	// MovementTypePick 拣货（拣货库位移至包装暂存库位）
+	MovementTypePick = "pick"
+	// MovementTypeShip 发运出库
+	MovementTypeShip = "ship"
+	// MovementTypeReturn 客户退货收货
+	MovementTypeReturn = "return"
+	// MovementTypeReplenish 补货（存储库位移至拣货位）
+	MovementTypeReplenish = "replenish"
+	// MovementTypeCrossDock 越库（收货库位直接移至越库暂存库位，不经上架）
+	MovementTypeCrossDock = "cross_dock"
+)
+
+// 库存流水关联的单据类型
+const (
+	// ReferenceTypeInventoryCheck 盘点记录
+	ReferenceTypeInventoryCheck = "inventory_check"
+	// ReferenceTypeInboundReceipt 入库收货记录
+	ReferenceTypeInboundReceipt = "inbound_receipt"
+	// ReferenceTypePutawayTask 上架任务
+	ReferenceTypePutawayTask = "putaway_task"
+	// ReferenceTypeStockTransfer 移库单
+	ReferenceTypeStockTransfer = "stock_transfer"
+	// ReferenceTypePickTask 拣货任务
+	ReferenceTypePickTask = "pick_task"
+	// ReferenceTypeShipment 发运记录
+	ReferenceTypeShipment = "shipment"
+	// ReferenceTypeReturnReceipt 退货收货记录
+	ReferenceTypeReturnReceipt = "return_receipt"
+	// ReferenceTypeReplenishmentTask 补货任务
+	ReferenceTypeReplenishmentTask = "replenishment_task"
+)
+
+// TableName 指定 StockMovement 对应的表名
+func (StockMovement) TableName() string {
+	return "stock_movements"
+}
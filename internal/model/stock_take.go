@@ -0,0 +1,121 @@
+package model
+
+import "time"
+
+// StockTake 表示一次盘点计划（盘点单），用于将多条盘点记录归入同一次盘点
+// 生命周期：draft -> in_progress -> counting_closed -> reconciled -> posted
+// 作用范围由 Locations 与 Materials 限定，均为空时表示全仓盘点；Checkers 为空时不限制盘点人
+// 盘点单内的盘点不会立即调整库存，而是在过账 (posted) 时统一按差异增量过账
+// BlindCount 为 true 时为盲盘：开始盘点时按库位生成盘点任务并冻结系统库存，盘点人看不到账面数量
+type StockTake struct {
+	ID               uint                `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name             string              `gorm:"type:varchar(200);not null" json:"name"`
+	Description      string              `gorm:"type:varchar(500)" json:"description,omitempty"`
+	Status           string              `gorm:"type:varchar(20);not null;default:draft;index" json:"status"`
+	BlindCount       bool                `gorm:"not null;default:false" json:"blind_count"`
+	CreatedBy        string              `gorm:"type:varchar(100);not null" json:"created_by"`
+	StartedAt        *time.Time          `gorm:"type:timestamp" json:"started_at,omitempty"`
+	CountingClosedAt *time.Time          `gorm:"type:timestamp" json:"counting_closed_at,omitempty"`
+	ReconciledAt     *time.Time          `gorm:"type:timestamp" json:"reconciled_at,omitempty"`
+	PostedAt         *time.Time          `gorm:"type:timestamp" json:"posted_at,omitempty"`
+	PostedBy         string              `gorm:"type:varchar(100)" json:"posted_by,omitempty"`
+	Locations        []StockTakeLocation `gorm:"foreignKey:StockTakeID;constraint:OnDelete:CASCADE" json:"locations"`
+	Materials        []StockTakeMaterial `gorm:"foreignKey:StockTakeID;constraint:OnDelete:CASCADE" json:"materials"`
+	Checkers         []StockTakeChecker  `gorm:"foreignKey:StockTakeID;constraint:OnDelete:CASCADE" json:"checkers"`
+	CreatedAt        time.Time           `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt        time.Time           `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// 盘点单状态
+const (
+	// StockTakeStatusDraft 草稿，可调整范围，尚不接受盘点
+	StockTakeStatusDraft = "draft"
+	// StockTakeStatusInProgress 盘点中，接受盘点上传
+	StockTakeStatusInProgress = "in_progress"
+	// StockTakeStatusCountingClosed 已截止盘点，不再接受上传，等待差异审批
+	StockTakeStatusCountingClosed = "counting_closed"
+	// StockTakeStatusReconciled 差异均已审批完毕，等待过账
+	StockTakeStatusReconciled = "reconciled"
+	// StockTakeStatusPosted 差异已过账至库存
+	StockTakeStatusPosted = "posted"
+)
+
+// TableName 指定 StockTake 对应的表名
+func (StockTake) TableName() string {
+	return "stock_takes"
+}
+
+// StockTakeLocation 表示盘点单范围内的库位
+type StockTakeLocation struct {
+	ID           uint   `gorm:"primaryKey;autoIncrement" json:"-"`
+	StockTakeID  uint   `gorm:"not null;uniqueIndex:idx_stock_take_location" json:"-"`
+	LocationCode string `gorm:"type:varchar(100);not null;uniqueIndex:idx_stock_take_location" json:"location_code"`
+}
+
+// TableName 指定 StockTakeLocation 对应的表名
+func (StockTakeLocation) TableName() string {
+	return "stock_take_locations"
+}
+
+// StockTakeMaterial 表示盘点单范围内的物料
+type StockTakeMaterial struct {
+	ID           uint   `gorm:"primaryKey;autoIncrement" json:"-"`
+	StockTakeID  uint   `gorm:"not null;uniqueIndex:idx_stock_take_material" json:"-"`
+	MaterialCode string `gorm:"type:varchar(100);not null;uniqueIndex:idx_stock_take_material" json:"material_code"`
+}
+
+// TableName 指定 StockTakeMaterial 对应的表名
+func (StockTakeMaterial) TableName() string {
+	return "stock_take_materials"
+}
+
+// StockTakeChecker 表示分配到盘点单的盘点人
+type StockTakeChecker struct {
+	ID          uint   `gorm:"primaryKey;autoIncrement" json:"-"`
+	StockTakeID uint   `gorm:"not null;uniqueIndex:idx_stock_take_checker" json:"-"`
+	CheckerID   string `gorm:"type:varchar(100);not null;uniqueIndex:idx_stock_take_checker" json:"checker_id"`
+}
+
+// TableName 指定 StockTakeChecker 对应的表名
+func (StockTakeChecker) TableName() string {
+	return "stock_take_checkers"
+}
+
+// CoversLocation 判断库位是否在盘点范围内
+func (st *StockTake) CoversLocation(locationCode string) bool {
+	if len(st.Locations) == 0 {
+		return true
+	}
+	for _, l := range st.Locations {
+		if l.LocationCode == locationCode {
+			return true
+		}
+	}
+	return false
+}
+
+// CoversMaterial 判断物料是否在盘点范围内
+func (st *StockTake) CoversMaterial(materialCode string) bool {
+	if len(st.Materials) == 0 {
+		return true
+	}
+	for _, m := range st.Materials {
+		if m.MaterialCode == materialCode {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAssigned 判断盘点人是否被分配到该盘点单
+func (st *StockTake) IsAssigned(checkerID string) bool {
+	if len(st.Checkers) == 0 {
+		return true
+	}
+	for _, c := range st.Checkers {
+		if c.CheckerID == checkerID {
+			return true
+		}
+	}
+	return false
+}
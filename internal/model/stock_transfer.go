@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+// StockTransfer 表示一次库位间移库，一次可包含多行
+// 所有行在同一事务中过账，任一行失败时整单不生效
+type StockTransfer struct {
+	ID         uint                `gorm:"primaryKey;autoIncrement" json:"id"`
+	OperatorID string              `gorm:"type:varchar(100);not null;index" json:"operator_id"`
+	Note       string              `gorm:"type:varchar(500)" json:"note,omitempty"`
+	Lines      []StockTransferLine `gorm:"foreignKey:TransferID;constraint:OnDelete:CASCADE" json:"lines"`
+	CreatedAt  time.Time           `gorm:"autoCreateTime;index" json:"created_at"`
+}
+
+// TableName 指定 StockTransfer 对应的表名
+func (StockTransfer) TableName() string {
+	return "stock_transfers"
+}
+
+// StockTransferLine 表示移库单的一行：将 LotNumber 批次的 Quantity 从 FromLocation 移到 ToLocation
+type StockTransferLine struct {
+	ID           uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	TransferID   uint   `gorm:"not null;index" json:"transfer_id"`
+	LineNo       int    `gorm:"not null" json:"line_no"`
+	MaterialCode string `gorm:"type:varchar(100);not null;index" json:"material_code"`
+	LotNumber    string `gorm:"type:varchar(100);not null;default:''" json:"lot_number,omitempty"`
+	FromLocation string `gorm:"type:varchar(100);not null" json:"from_location"`
+	ToLocation   string `gorm:"type:varchar(100);not null" json:"to_location"`
+	Quantity     int    `gorm:"not null" json:"quantity"`
+}
+
+// TableName 指定 StockTransferLine 对应的表名
+func (StockTransferLine) TableName() string {
+	return "stock_transfer_lines"
+}
@@ -0,0 +1,56 @@
+package model
+
+import "time"
+
+// VarianceReason 表示盘点差异原因代码
+// PostingCategory 决定差异过账时归入的调整类别；Direction 限制原因适用的差异方向（盘盈/盘亏）
+// 停用的原因代码不能再用于新的盘点，但历史记录保持不变
+type VarianceReason struct {
+	ID              uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Code            string    `gorm:"type:varchar(50);not null;uniqueIndex" json:"code"`
+	Description     string    `gorm:"type:varchar(200);not null" json:"description"`
+	PostingCategory string    `gorm:"type:varchar(30);not null" json:"posting_category"`
+	Direction       string    `gorm:"type:varchar(10);not null;default:any" json:"direction"`
+	Active          bool      `gorm:"not null;default:true" json:"active"`
+	CreatedAt       time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// 差异调整过账类别
+const (
+	// PostingCategoryShrinkage 损耗（被盗、原因不明的短少）
+	PostingCategoryShrinkage = "shrinkage"
+	// PostingCategoryDamage 损坏报废
+	PostingCategoryDamage = "damage"
+	// PostingCategoryProcessError 作业差错（错拣、计量单位错误），不影响资产价值
+	PostingCategoryProcessError = "process_error"
+	// PostingCategoryFoundStock 盘盈找回
+	PostingCategoryFoundStock = "found_stock"
+)
+
+// 原因代码适用的差异方向
+const (
+	// ReasonDirectionAny 盘盈与盘亏均可使用
+	ReasonDirectionAny = "any"
+	// ReasonDirectionGain 仅用于盘盈（difference > 0）
+	ReasonDirectionGain = "gain"
+	// ReasonDirectionLoss 仅用于盘亏（difference < 0）
+	ReasonDirectionLoss = "loss"
+)
+
+// TableName 指定 VarianceReason 对应的表名
+func (VarianceReason) TableName() string {
+	return "variance_reasons"
+}
+
+// Allows 判断原因代码是否适用于指定差异
+func (r *VarianceReason) Allows(difference int) bool {
+	switch r.Direction {
+	case ReasonDirectionGain:
+		return difference > 0
+	case ReasonDirectionLoss:
+		return difference < 0
+	default:
+		return true
+	}
+}
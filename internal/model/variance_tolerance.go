@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// VarianceTolerance 表示盘点差异容差配置
+// MaterialCode 与 LocationCode 为空字符串时表示对任意物料/库位生效，
+// 匹配时优先级为：物料+库位 > 物料 > 库位 > 系统默认值
+// AbsoluteTolerance 为允许的最大绝对差异数量，PercentageTolerance 为相对系统库存的最大差异百分比，
+// 两者为空时表示该维度不限制
+type VarianceTolerance struct {
+	ID                  uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	MaterialCode        string    `gorm:"type:varchar(100);not null;default:'';uniqueIndex:idx_tolerance_scope" json:"material_code"`
+	LocationCode        string    `gorm:"type:varchar(100);not null;default:'';uniqueIndex:idx_tolerance_scope" json:"location_code"`
+	AbsoluteTolerance   *int      `json:"absolute_tolerance"`
+	PercentageTolerance *float64  `json:"percentage_tolerance"`
+	CreatedAt           time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt           time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName 指定 VarianceTolerance 对应的表名
+func (VarianceTolerance) TableName() string {
+	return "variance_tolerances"
+}
@@ -0,0 +1,88 @@
+package model
+
+import "time"
+
+// Wave 表示一个拣货波次
+// 按承运商截单时间（carrier）组波时，一个波次包含同一承运商、同一截单时间的全部单据；
+// 按库区（zone）组波时，一个波次包含所选单据在同一库区内的全部拣货任务，同一单据可能分布在多个波次中
+// 生命周期：released（任务已下发）-> picking（已有任务确认）-> completed（全部任务已确认）
+// RoutingStrategy 为排列拣货任务所用的路线策略，PlannedDistance 为按该路线估算的行走距离（米，不含未登记布局位置的库位）
+type Wave struct {
+	ID              uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	GroupBy         string     `gorm:"type:varchar(20);not null" json:"group_by"`
+	Carrier         string     `gorm:"type:varchar(50);index" json:"carrier,omitempty"`
+	CutoffAt        *time.Time `gorm:"type:timestamp" json:"cutoff_at,omitempty"`
+	Zone            string     `gorm:"type:varchar(50);index" json:"zone,omitempty"`
+	Status          string     `gorm:"type:varchar(20);not null;default:released;index" json:"status"`
+	OrderCount      int        `gorm:"not null;default:0" json:"order_count"`
+	TaskCount       int        `gorm:"not null;default:0" json:"task_count"`
+	CompletedTasks  int        `gorm:"not null;default:0" json:"completed_tasks"`
+	ShortTasks      int        `gorm:"not null;default:0" json:"short_tasks"`
+	RoutingStrategy string     `gorm:"type:varchar(20)" json:"routing_strategy"`
+	PlannedDistance float64    `gorm:"not null;default:0" json:"planned_distance"`
+	CreatedBy       string     `gorm:"type:varchar(100);not null" json:"created_by"`
+	StartedAt       *time.Time `gorm:"type:timestamp" json:"started_at,omitempty"`
+	CompletedAt     *time.Time `gorm:"type:timestamp" json:"completed_at,omitempty"`
+	CreatedAt       time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// 波次分组方式
+const (
+	// WaveGroupByCarrier 按承运商与截单时间分组
+	WaveGroupByCarrier = "carrier"
+	// WaveGroupByZone 按拣货库位所属库区分组
+	WaveGroupByZone = "zone"
+)
+
+// 波次状态
+const (
+	// WaveStatusReleased 拣货任务已下发，尚未开始拣货
+	WaveStatusReleased = "released"
+	// WaveStatusPicking 拣货中
+	WaveStatusPicking = "picking"
+	// WaveStatusCompleted 全部拣货任务已确认
+	WaveStatusCompleted = "completed"
+)
+
+// TableName 指定 Wave 对应的表名
+func (Wave) TableName() string {
+	return "waves"
+}
+
+// PickTask 表示波次内在一个库位拣取一种物料一个批次的任务，合并了波次内该库位该物料批次的全部库存预留
+// Sequence 为任务在波次内的行走顺序；确认时按实拣数量扣减在库与预留，
+// 实拣少于任务数量为短拣（short），未拣数量的预留被释放，并对该库位生成一条盘点任务（CountTaskID）
+type PickTask struct {
+	ID             uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	WaveID         uint       `gorm:"not null;index" json:"wave_id"`
+	Sequence       int        `gorm:"not null" json:"sequence"`
+	MaterialCode   string     `gorm:"type:varchar(100);not null;index" json:"material_code"`
+	LocationCode   string     `gorm:"type:varchar(100);not null;index" json:"location_code"`
+	LotNumber      string     `gorm:"type:varchar(100);not null;default:''" json:"lot_number,omitempty"`
+	Zone           string     `gorm:"type:varchar(50);index" json:"zone,omitempty"`
+	Quantity       int        `gorm:"not null" json:"quantity"`
+	PickedQuantity int        `gorm:"not null;default:0" json:"picked_quantity"`
+	Status         string     `gorm:"type:varchar(20);not null;default:open;index" json:"status"`
+	AssignedTo     string     `gorm:"type:varchar(100);index" json:"assigned_to,omitempty"`
+	ConfirmedBy    string     `gorm:"type:varchar(100)" json:"confirmed_by,omitempty"`
+	ConfirmedAt    *time.Time `gorm:"type:timestamp" json:"confirmed_at,omitempty"`
+	CountTaskID    *uint      `json:"count_task_id,omitempty"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// 拣货任务状态
+const (
+	// PickTaskStatusOpen 待拣货
+	PickTaskStatusOpen = "open"
+	// PickTaskStatusPicked 已拣足
+	PickTaskStatusPicked = "picked"
+	// PickTaskStatusShort 短拣
+	PickTaskStatusShort = "short"
+)
+
+// TableName 指定 PickTask 对应的表名
+func (PickTask) TableName() string {
+	return "pick_tasks"
+}
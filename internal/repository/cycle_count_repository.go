@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"time"
+	"wms/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MaterialMovementVolume 表示物料在一段时间内的出入库数量合计
+type MaterialMovementVolume struct {
+	MaterialCode string
+	Volume       int64
+}
+
+// PairLastCount 表示物料在库位上最近一次盘点的时间
+type PairLastCount struct {
+	MaterialCode string
+	LocationCode string
+	LastCheck    time.Time
+}
+
+// CycleCountTaskFilter 表示循环盘点任务的查询条件，零值字段表示不过滤
+type CycleCountTaskFilter struct {
+	ScheduledDate *time.Time
+	Status        string
+	ABCClass      string
+	Source        string
+}
+
+// CycleCountRepository 定义循环盘点配置与任务的数据访问接口
+type CycleCountRepository interface {
+	// GetPolicy 查询循环盘点配置，尚未配置时返回 nil
+	GetPolicy() (*model.CycleCountPolicy, error)
+
+	// SavePolicy 保存循环盘点配置
+	SavePolicy(policy *model.CycleCountPolicy) error
+
+	// ListValuations 查询全部物料单位价值
+	ListValuations() ([]model.MaterialValuation, error)
+
+	// UpsertValuation 按物料新增或更新单位价值
+	UpsertValuation(valuation *model.MaterialValuation) error
+
+	// SumMovementVolume 统计 since 之后各物料的出入库数量合计（按 |delta| 累加）
+	// 盘点调整与期初余额不是实际出入库，不计入
+	SumMovementVolume(since time.Time) ([]MaterialMovementVolume, error)
+
+	// ListStockedPairs 查询数量大于 0 的全部物料与库位
+	ListStockedPairs() ([]model.Stock, error)
+
+	// ListLastCounts 查询 since 之后有盘点的物料与库位及其最近一次盘点时间
+	ListLastCounts(since time.Time) ([]PairLastCount, error)
+
+	// ListOpenTasks 查询全部未完成的循环盘点任务
+	ListOpenTasks() ([]model.CycleCountTask, error)
+
+	// CreateTasks 批量创建循环盘点任务，已有未完成任务的物料与库位被跳过，返回实际创建数量
+	CreateTasks(tasks []model.CycleCountTask) (int64, error)
+
+	// EnsureOpenTask 在事务中为物料与库位创建未完成的盘点任务；已有未完成任务时不新建，并将其读入 task
+	EnsureOpenTask(tx *gorm.DB, task *model.CycleCountTask) error
+
+	// ListTasks 按过滤条件查询循环盘点任务
+	ListTasks(filter CycleCountTaskFilter) ([]model.CycleCountTask, error)
+
+	// CompleteOpenTasks 在事务中将物料与库位上未完成的循环盘点任务标记为完成
+	CompleteOpenTasks(tx *gorm.DB, materialCode, locationCode string, recordID uint, completedAt time.Time) error
+}
+
+// cycleCountRepository 是 CycleCountRepository 的具体实现
+type cycleCountRepository struct {
+	db *gorm.DB
+}
+
+// NewCycleCountRepository 创建新的 CycleCountRepository 实例
+func NewCycleCountRepository(db *gorm.DB) CycleCountRepository {
+	return &cycleCountRepository{
+		db: db,
+	}
+}
+
+// GetPolicy 查询循环盘点配置
+// 若尚未保存过配置则返回 nil（不视为错误）
+func (r *cycleCountRepository) GetPolicy() (*model.CycleCountPolicy, error) {
+	var policy model.CycleCountPolicy
+	err := r.db.First(&policy, 1).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// SavePolicy 以固定 ID 保存唯一的循环盘点配置
+func (r *cycleCountRepository) SavePolicy(policy *model.CycleCountPolicy) error {
+	policy.ID = 1
+	return r.db.Save(policy).Error
+}
+
+// ListValuations 查询全部物料单位价值，按物料排序
+func (r *cycleCountRepository) ListValuations() ([]model.MaterialValuation, error) {
+	var valuations []model.MaterialValuation
+	err := r.db.Order("material_code").Find(&valuations).Error
+	return valuations, err
+}
+
+// UpsertValuation 按 material_code 唯一约束新增或更新单位价值
+func (r *cycleCountRepository) UpsertValuation(valuation *model.MaterialValuation) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "material_code"}},
+		DoUpdates: clause.AssignmentColumns([]string{"unit_cost", "updated_at"}),
+	}).Create(valuation).Error
+}
+
+// SumMovementVolume 统计各物料的出入库数量合计
+func (r *cycleCountRepository) SumMovementVolume(since time.Time) ([]MaterialMovementVolume, error) {
+	var volumes []MaterialMovementVolume
+	err := r.db.Model(&model.StockMovement{}).
+		Select("material_code, SUM(ABS(delta)) AS volume").
+		Where("moved_at >= ? AND movement_type NOT IN ?", since,
+			[]string{model.MovementTypeStockTakeAdjustment, model.MovementTypeOpeningBalance}).
+		Group("material_code").Scan(&volumes).Error
+	return volumes, err
+}
+
+// ListStockedPairs 查询有库存的物料与库位，按物料、库位排序
+func (r *cycleCountRepository) ListStockedPairs() ([]model.Stock, error) {
+	var stocks []model.Stock
+	err := r.db.Where("quantity > 0").Order("material_code, location_code").Find(&stocks).Error
+	return stocks, err
+}
+
+// ListLastCounts 查询物料与库位最近一次盘点时间
+func (r *cycleCountRepository) ListLastCounts(since time.Time) ([]PairLastCount, error) {
+	var counts []PairLastCount
+	err := r.db.Model(&model.InventoryCheckRecord{}).
+		Select("material_code, location_code, MAX(check_time) AS last_check").
+		Where("check_time >= ?", since).
+		Group("material_code, location_code").Scan(&counts).Error
+	return counts, err
+}
+
+// ListOpenTasks 查询全部未完成的循环盘点任务
+func (r *cycleCountRepository) ListOpenTasks() ([]model.CycleCountTask, error) {
+	var tasks []model.CycleCountTask
+	err := r.db.Where("status = ?", model.CycleCountTaskStatusOpen).Find(&tasks).Error
+	return tasks, err
+}
+
+// CreateTasks 批量创建循环盘点任务
+// 依赖未完成任务上的部分唯一索引，并发生成时同一物料与库位也只会保留一条未完成任务
+func (r *cycleCountRepository) CreateTasks(tasks []model.CycleCountTask) (int64, error) {
+	if len(tasks) == 0 {
+		return 0, nil
+	}
+	result := r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "material_code"}, {Name: "location_code"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{
+			clause.Eq{Column: clause.Column{Name: "status"}, Value: model.CycleCountTaskStatusOpen},
+		}},
+		DoNothing: true,
+	}).CreateInBatches(tasks, 100)
+	return result.RowsAffected, result.Error
+}
+
+// EnsureOpenTask 创建未完成的盘点任务，与 CreateTasks 共用未完成任务上的部分唯一索引
+func (r *cycleCountRepository) EnsureOpenTask(tx *gorm.DB, task *model.CycleCountTask) error {
+	result := tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "material_code"}, {Name: "location_code"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{
+			clause.Eq{Column: clause.Column{Name: "status"}, Value: model.CycleCountTaskStatusOpen},
+		}},
+		DoNothing: true,
+	}).Create(task)
+	if result.Error != nil || result.RowsAffected > 0 {
+		return result.Error
+	}
+	return tx.Where("material_code = ? AND location_code = ? AND status = ?",
+		task.MaterialCode, task.LocationCode, model.CycleCountTaskStatusOpen).First(task).Error
+}
+
+// ListTasks 查询循环盘点任务，A 类优先，同类按物料、库位排序
+func (r *cycleCountRepository) ListTasks(filter CycleCountTaskFilter) ([]model.CycleCountTask, error) {
+	query := r.db.Model(&model.CycleCountTask{})
+	if filter.ScheduledDate != nil {
+		query = query.Where("scheduled_date = ?", filter.ScheduledDate.Format("2006-01-02"))
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.ABCClass != "" {
+		query = query.Where("abc_class = ?", filter.ABCClass)
+	}
+	if filter.Source != "" {
+		query = query.Where("source = ?", filter.Source)
+	}
+
+	var tasks []model.CycleCountTask
+	err := query.Order("abc_class, material_code, location_code, id").Find(&tasks).Error
+	return tasks, err
+}
+
+// CompleteOpenTasks 完成物料与库位上未完成的循环盘点任务
+func (r *cycleCountRepository) CompleteOpenTasks(tx *gorm.DB, materialCode, locationCode string, recordID uint, completedAt time.Time) error {
+	return tx.Model(&model.CycleCountTask{}).
+		Where("material_code = ? AND location_code = ? AND status = ?",
+			materialCode, locationCode, model.CycleCountTaskStatusOpen).
+		Updates(map[string]interface{}{
+			"status":          model.CycleCountTaskStatusCompleted,
+			"check_record_id": recordID,
+			"completed_at":    completedAt,
+		}).Error
+}
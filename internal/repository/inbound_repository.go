@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"wms/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// InboundOrderFilter 表示入库单据的查询条件，零值字段表示不过滤
+type InboundOrderFilter struct {
+	Status       string
+	OrderType    string
+	SupplierCode string
+}
+
+// InboundRepository 定义入库单据与收货记录的数据访问接口
+type InboundRepository interface {
+	// Create 创建入库单据及其物料行
+	Create(order *model.InboundOrder) error
+
+	// GetByID 按 ID 查询入库单据（含物料行），不存在时返回 nil
+	GetByID(id uint) (*model.InboundOrder, error)
+
+	// GetByOrderNo 按单据号查询入库单据（不含物料行），不存在时返回 nil
+	GetByOrderNo(orderNo string) (*model.InboundOrder, error)
+
+	// List 按过滤条件查询入库单据（含物料行）
+	List(filter InboundOrderFilter) ([]model.InboundOrder, error)
+
+	// GetForUpdate 在事务中锁定入库单据（含物料行），不存在时返回 nil
+	GetForUpdate(tx *gorm.DB, id uint) (*model.InboundOrder, error)
+
+	// UpdateStatus 在事务中保存入库单据的状态与关闭信息（不包括物料行）
+	UpdateStatus(tx *gorm.DB, order *model.InboundOrder) error
+
+	// UpdateLine 在事务中保存物料行的收货数量与状态
+	UpdateLine(tx *gorm.DB, line *model.InboundOrderLine) error
+
+	// CreateReceipt 在事务中创建收货记录
+	CreateReceipt(tx *gorm.DB, receipt *model.InboundReceipt) error
+
+	// UpdateReceipt 在事务中保存收货记录的越库数量
+	UpdateReceipt(tx *gorm.DB, receipt *model.InboundReceipt) error
+
+	// ListReceipts 查询入库单据的全部收货记录
+	ListReceipts(orderID uint) ([]model.InboundReceipt, error)
+
+	// BeginTransaction 开启新的数据库事务
+	BeginTransaction() *gorm.DB
+
+	// CommitTransaction 提交当前事务
+	CommitTransaction(tx *gorm.DB) error
+
+	// RollbackTransaction 回滚当前事务
+	RollbackTransaction(tx *gorm.DB) error
+}
+
+// inboundRepository 是 InboundRepository 的具体实现
+type inboundRepository struct {
+	db *gorm.DB
+}
+
+// NewInboundRepository 创建新的 InboundRepository 实例
+func NewInboundRepository(db *gorm.DB) InboundRepository {
+	return &inboundRepository{
+		db: db,
+	}
+}
+
+// Create 创建入库单据，物料行随主记录一并写入
+func (r *inboundRepository) Create(order *model.InboundOrder) error {
+	return r.db.Create(order).Error
+}
+
+// GetByID 按 ID 查询入库单据
+// 若单据不存在则返回 nil（不视为错误）
+func (r *inboundRepository) GetByID(id uint) (*model.InboundOrder, error) {
+	return r.find(r.db, id)
+}
+
+// GetByOrderNo 按单据号查询入库单据
+func (r *inboundRepository) GetByOrderNo(orderNo string) (*model.InboundOrder, error) {
+	var order model.InboundOrder
+	err := r.db.Where("order_no = ?", orderNo).First(&order).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &order, nil
+}
+
+// List 查询入库单据，按创建时间倒序
+func (r *inboundRepository) List(filter InboundOrderFilter) ([]model.InboundOrder, error) {
+	query := r.db.Model(&model.InboundOrder{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.OrderType != "" {
+		query = query.Where("order_type = ?", filter.OrderType)
+	}
+	if filter.SupplierCode != "" {
+		query = query.Where("supplier_code = ?", filter.SupplierCode)
+	}
+
+	var orders []model.InboundOrder
+	err := query.Preload("Lines", orderLinesByNo).Order("id DESC").Find(&orders).Error
+	return orders, err
+}
+
+// GetForUpdate 以排他锁读取入库单据
+func (r *inboundRepository) GetForUpdate(tx *gorm.DB, id uint) (*model.InboundOrder, error) {
+	return r.find(tx.Clauses(clause.Locking{Strength: "UPDATE"}), id)
+}
+
+// UpdateStatus 保存入库单据状态字段，不级联更新物料行
+func (r *inboundRepository) UpdateStatus(tx *gorm.DB, order *model.InboundOrder) error {
+	return tx.Model(order).Select("status", "closed_by", "closed_at", "close_reason", "updated_at").Updates(order).Error
+}
+
+// UpdateLine 保存物料行的收货数量与状态
+func (r *inboundRepository) UpdateLine(tx *gorm.DB, line *model.InboundOrderLine) error {
+	return tx.Model(line).Select("received_quantity", "status", "updated_at").Updates(line).Error
+}
+
+// CreateReceipt 新增收货记录
+func (r *inboundRepository) CreateReceipt(tx *gorm.DB, receipt *model.InboundReceipt) error {
+	return tx.Create(receipt).Error
+}
+
+// UpdateReceipt 保存收货记录的越库数量
+func (r *inboundRepository) UpdateReceipt(tx *gorm.DB, receipt *model.InboundReceipt) error {
+	return tx.Model(receipt).Select("cross_dock_quantity").Updates(receipt).Error
+}
+
+// ListReceipts 查询收货记录，按收货顺序排列
+func (r *inboundRepository) ListReceipts(orderID uint) ([]model.InboundReceipt, error) {
+	var receipts []model.InboundReceipt
+	err := r.db.Where("order_id = ?", orderID).Order("id").Find(&receipts).Error
+	return receipts, err
+}
+
+// BeginTransaction 开启新的数据库事务
+func (r *inboundRepository) BeginTransaction() *gorm.DB {
+	return r.db.Begin()
+}
+
+// CommitTransaction 提交当前事务
+func (r *inboundRepository) CommitTransaction(tx *gorm.DB) error {
+	return tx.Commit().Error
+}
+
+// RollbackTransaction 回滚当前事务
+func (r *inboundRepository) RollbackTransaction(tx *gorm.DB) error {
+	return tx.Rollback().Error
+}
+
+// find 查询入库单据并预加载物料行
+// 锁只作用于主表查询，物料行由单据锁间接保护
+func (r *inboundRepository) find(query *gorm.DB, id uint) (*model.InboundOrder, error) {
+	var order model.InboundOrder
+	err := query.Preload("Lines", orderLinesByNo).First(&order, id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &order, nil
+}
+
+// orderLinesByNo 按行号排序预加载的物料行
+func orderLinesByNo(db *gorm.DB) *gorm.DB {
+	return db.Order("line_no")
+}
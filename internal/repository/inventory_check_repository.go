@@ -1,22 +1,84 @@
 package repository
 
 import (
+	"fmt"
+	"strings"
+	"time"
 	"wms/internal/model"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// CheckRecordSortField 表示盘点记录列表允许的排序字段
+type CheckRecordSortField string
+
+const (
+	// SortByCheckTime 按盘点时间排序
+	SortByCheckTime CheckRecordSortField = "check_time"
+	// SortByID 按记录 ID 排序
+	SortByID CheckRecordSortField = "id"
+	// SortByDifference 按差异数量排序
+	SortByDifference CheckRecordSortField = "difference"
+)
+
+// CheckRecordCursor 表示键集分页的游标位置
+// SortValue 为上一页最后一条记录的排序字段值，ID 用于排序值相同时的稳定排序
+type CheckRecordCursor struct {
+	SortValue interface{}
+	ID        uint
+}
+
+// CheckRecordFilter 表示盘点记录的查询条件
+// 零值字段表示不过滤
+type CheckRecordFilter struct {
+	MaterialCode   string
+	LocationCode   string
+	CheckerID      string
+	From           time.Time
+	To             time.Time
+	IsProcessed    *bool
+	ApprovalStatus string
+	StockTakeID    *uint
+	RootRecordID   *uint
+	SortBy         CheckRecordSortField
+	SortDesc       bool
+	After          *CheckRecordCursor
+	Limit          int
+}
+
+// VarianceGroupColumns 是差异报表允许的分组字段
+var VarianceGroupColumns = []string{"reason_code", "posting_category", "material_code", "location_code"}
+
+// VarianceAggregateFilter 表示差异报表的统计条件
+// 统计 [From, To) 内已生效（自动批准或审批通过）且差异不为 0 的盘点记录，GroupBy 须取自 VarianceGroupColumns
+type VarianceAggregateFilter struct {
+	From         time.Time
+	To           time.Time
+	MaterialCode string
+	LocationCode string
+	ReasonCode   string
+	GroupBy      []string
+}
+
+// VarianceAggregate 表示差异报表中的一行，未参与分组的字段为空
+type VarianceAggregate struct {
+	ReasonCode       string `json:"reason_code,omitempty"`
+	PostingCategory  string `json:"posting_category,omitempty"`
+	MaterialCode     string `json:"material_code,omitempty"`
+	LocationCode     string `json:"location_code,omitempty"`
+	RecordCount      int64  `json:"record_count"`
+	NetVariance      int64  `json:"net_variance"`
+	GainQuantity     int64  `json:"gain_quantity"`
+	LossQuantity     int64  `json:"loss_quantity"`
+	AbsoluteVariance int64  `json:"absolute_variance"`
+}
+
 // InventoryCheckRepository 定义库存盘点数据访问接口
 type InventoryCheckRepository interface {
 	// CreateCheckRecord 在事务中创建新的盘点记录
 	CreateCheckRecord(tx *gorm.DB, record *model.InventoryCheckRecord) error
 
-	// GetStockByMaterialAndLocation 查询指定库位与物料的库存信息
-	GetStockByMaterialAndLocation(tx *gorm.DB, materialCode, locationCode string) (*model.Stock, error)
-
-	// UpdateStock 更新指定库位物料的库存数量
-	UpdateStock(tx *gorm.DB, stock *model.Stock) error
-
 	// BeginTransaction 开启新的数据库事务
 	BeginTransaction() *gorm.DB
 
@@ -31,6 +93,45 @@ type InventoryCheckRepository interface {
 
 	// FindUnprocessedRecords 查询所有未处理的盘点记录
 	FindUnprocessedRecords() ([]model.InventoryCheckRecord, error)
+
+	// GetCheckRecordByID 按 ID 查询盘点记录，不存在时返回 nil
+	GetCheckRecordByID(id uint) (*model.InventoryCheckRecord, error)
+
+	// ListCheckRecords 按过滤条件分页查询盘点记录
+	ListCheckRecords(filter CheckRecordFilter) ([]model.InventoryCheckRecord, error)
+
+	// GetCheckRecordForUpdate 在事务中按 ID 查询并锁定盘点记录，不存在时返回 nil
+	GetCheckRecordForUpdate(tx *gorm.DB, id uint) (*model.InventoryCheckRecord, error)
+
+	// UpdateCheckRecord 在事务中保存盘点记录的变更
+	UpdateCheckRecord(tx *gorm.DB, record *model.InventoryCheckRecord) error
+
+	// FindRecordsByStockTake 在事务中查询盘点单下的全部盘点记录，按物料、库位排序
+	FindRecordsByStockTake(tx *gorm.DB, stockTakeID uint) ([]model.InventoryCheckRecord, error)
+
+	// CountActiveStockTakeRecords 在事务中统计盘点单内某物料、库位与批次未被驳回或取代的盘点记录数
+	CountActiveStockTakeRecords(tx *gorm.DB, stockTakeID uint, materialCode, locationCode, lotNumber string) (int64, error)
+
+	// FindRecordChain 在事务中查询复盘链上的全部记录（含最初的盘点），按复盘序号排序
+	FindRecordChain(tx *gorm.DB, rootRecordID uint) ([]model.InventoryCheckRecord, error)
+
+	// CreateRecountTask 在事务中创建复盘任务
+	CreateRecountTask(tx *gorm.DB, task *model.RecountTask) error
+
+	// GetRecountTaskForUpdate 在事务中锁定复盘任务，不存在时返回 nil
+	GetRecountTaskForUpdate(tx *gorm.DB, id uint) (*model.RecountTask, error)
+
+	// FindOpenRecountTaskForUpdate 在事务中锁定指定记录的未完成复盘任务，不存在时返回 nil
+	FindOpenRecountTaskForUpdate(tx *gorm.DB, recordID uint) (*model.RecountTask, error)
+
+	// UpdateRecountTask 在事务中保存复盘任务的变更
+	UpdateRecountTask(tx *gorm.DB, task *model.RecountTask) error
+
+	// ListRecountTasks 查询复盘任务，status / assignedTo 为空时不过滤
+	ListRecountTasks(status, assignedTo string) ([]model.RecountTask, error)
+
+	// AggregateVariances 按分组字段汇总时间窗口内已生效的盘点差异
+	AggregateVariances(filter VarianceAggregateFilter) ([]VarianceAggregate, error)
 }
 
 // inventoryCheckRepository 是 InventoryCheckRepository 的具体实现
@@ -50,25 +151,6 @@ func (r *inventoryCheckRepository) CreateCheckRecord(tx *gorm.DB, record *model.
 	return tx.Create(record).Error
 }
 
-// GetStockByMaterialAndLocation 查询指定库位的库存
-// 若库存不存在则返回 nil（不视为错误）
-func (r *inventoryCheckRepository) GetStockByMaterialAndLocation(tx *gorm.DB, materialCode, locationCode string) (*model.Stock, error) {
-	var stock model.Stock
-	err := tx.Where("material_code = ? AND location_code = ?", materialCode, locationCode).First(&stock).Error
-	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, nil
-		}
-		return nil, err
-	}
-	return &stock, nil
-}
-
-// UpdateStock 更新指定库位的库存数量
-func (r *inventoryCheckRepository) UpdateStock(tx *gorm.DB, stock *model.Stock) error {
-	return tx.Save(stock).Error
-}
-
 // BeginTransaction 开启新的数据库事务
 func (r *inventoryCheckRepository) BeginTransaction() *gorm.DB {
 	return r.db.Begin()
@@ -97,3 +179,230 @@ func (r *inventoryCheckRepository) FindUnprocessedRecords() ([]model.InventoryCh
 	err := r.db.Where("is_processed = ?", false).Order("check_time ASC").Find(&records).Error
 	return records, err
 }
+
+// GetCheckRecordByID 按 ID 查询盘点记录
+// 若记录不存在则返回 nil（不视为错误）
+func (r *inventoryCheckRepository) GetCheckRecordByID(id uint) (*model.InventoryCheckRecord, error) {
+	var record model.InventoryCheckRecord
+	err := r.db.Preload("SerialVariances").First(&record, id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &record, nil
+}
+
+// ListCheckRecords 按过滤条件与键集游标查询盘点记录
+func (r *inventoryCheckRepository) ListCheckRecords(filter CheckRecordFilter) ([]model.InventoryCheckRecord, error) {
+	query := r.db.Model(&model.InventoryCheckRecord{})
+
+	if filter.MaterialCode != "" {
+		query = query.Where("material_code = ?", filter.MaterialCode)
+	}
+	if filter.LocationCode != "" {
+		query = query.Where("location_code = ?", filter.LocationCode)
+	}
+	if filter.CheckerID != "" {
+		query = query.Where("checker_id = ?", filter.CheckerID)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("check_time >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("check_time < ?", filter.To)
+	}
+	if filter.IsProcessed != nil {
+		query = query.Where("is_processed = ?", *filter.IsProcessed)
+	}
+	if filter.ApprovalStatus != "" {
+		query = query.Where("approval_status = ?", filter.ApprovalStatus)
+	}
+	if filter.StockTakeID != nil {
+		query = query.Where("stock_take_id = ?", *filter.StockTakeID)
+	}
+	if filter.RootRecordID != nil {
+		query = query.Where("id = ? OR root_record_id = ?", *filter.RootRecordID, *filter.RootRecordID)
+	}
+
+	sortBy := filter.SortBy
+	if sortBy == "" {
+		sortBy = SortByCheckTime
+	}
+	direction, comparator := "ASC", ">"
+	if filter.SortDesc {
+		direction, comparator = "DESC", "<"
+	}
+
+	if filter.After != nil {
+		if sortBy == SortByID {
+			query = query.Where(fmt.Sprintf("id %s ?", comparator), filter.After.ID)
+		} else {
+			query = query.Where(fmt.Sprintf("(%s, id) %s (?, ?)", sortBy, comparator), filter.After.SortValue, filter.After.ID)
+		}
+	}
+
+	if sortBy == SortByID {
+		query = query.Order("id " + direction)
+	} else {
+		query = query.Order(fmt.Sprintf("%s %s, id %s", sortBy, direction, direction))
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var records []model.InventoryCheckRecord
+	err := query.Preload("SerialVariances").Find(&records).Error
+	return records, err
+}
+
+// GetCheckRecordForUpdate 使用 SELECT ... FOR UPDATE 锁定盘点记录
+// 若记录不存在则返回 nil（不视为错误）
+func (r *inventoryCheckRepository) GetCheckRecordForUpdate(tx *gorm.DB, id uint) (*model.InventoryCheckRecord, error) {
+	var record model.InventoryCheckRecord
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("SerialVariances").First(&record, id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &record, nil
+}
+
+// UpdateCheckRecord 保存盘点记录的变更，序列号差异在创建后不再改变
+func (r *inventoryCheckRepository) UpdateCheckRecord(tx *gorm.DB, record *model.InventoryCheckRecord) error {
+	return tx.Omit(clause.Associations).Save(record).Error
+}
+
+// FindRecordsByStockTake 查询盘点单下的全部盘点记录
+// 按物料、库位排序，过账时以一致的顺序锁定库存行，避免并发过账死锁
+func (r *inventoryCheckRepository) FindRecordsByStockTake(tx *gorm.DB, stockTakeID uint) ([]model.InventoryCheckRecord, error) {
+	var records []model.InventoryCheckRecord
+	err := tx.Where("stock_take_id = ?", stockTakeID).Preload("SerialVariances").
+		Order("material_code, location_code, id").Find(&records).Error
+	return records, err
+}
+
+// CountActiveStockTakeRecords 统计盘点单内某物料、库位与批次未被驳回或取代的盘点记录数
+func (r *inventoryCheckRepository) CountActiveStockTakeRecords(tx *gorm.DB, stockTakeID uint, materialCode, locationCode, lotNumber string) (int64, error) {
+	var count int64
+	err := tx.Model(&model.InventoryCheckRecord{}).
+		Where("stock_take_id = ? AND material_code = ? AND location_code = ? AND lot_number = ? AND approval_status NOT IN ?",
+			stockTakeID, materialCode, locationCode, lotNumber,
+			[]string{model.ApprovalStatusRejected, model.ApprovalStatusSuperseded}).
+		Count(&count).Error
+	return count, err
+}
+
+// FindRecordChain 查询复盘链上的全部记录
+func (r *inventoryCheckRepository) FindRecordChain(tx *gorm.DB, rootRecordID uint) ([]model.InventoryCheckRecord, error) {
+	var records []model.InventoryCheckRecord
+	err := tx.Where("id = ? OR root_record_id = ?", rootRecordID, rootRecordID).
+		Order("recount_sequence, id").Find(&records).Error
+	return records, err
+}
+
+// CreateRecountTask 创建复盘任务
+func (r *inventoryCheckRepository) CreateRecountTask(tx *gorm.DB, task *model.RecountTask) error {
+	return tx.Create(task).Error
+}
+
+// GetRecountTaskForUpdate 以排他锁读取复盘任务
+// 若任务不存在则返回 nil（不视为错误）
+func (r *inventoryCheckRepository) GetRecountTaskForUpdate(tx *gorm.DB, id uint) (*model.RecountTask, error) {
+	var task model.RecountTask
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&task, id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &task, nil
+}
+
+// FindOpenRecountTaskForUpdate 以排他锁读取指定记录的未完成复盘任务
+// 若任务不存在则返回 nil（不视为错误）
+func (r *inventoryCheckRepository) FindOpenRecountTaskForUpdate(tx *gorm.DB, recordID uint) (*model.RecountTask, error) {
+	var task model.RecountTask
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("record_id = ? AND status = ?", recordID, model.RecountTaskStatusOpen).
+		First(&task).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &task, nil
+}
+
+// UpdateRecountTask 保存复盘任务的变更
+func (r *inventoryCheckRepository) UpdateRecountTask(tx *gorm.DB, task *model.RecountTask) error {
+	return tx.Save(task).Error
+}
+
+// ListRecountTasks 查询复盘任务，按创建顺序排列
+func (r *inventoryCheckRepository) ListRecountTasks(status, assignedTo string) ([]model.RecountTask, error) {
+	query := r.db.Model(&model.RecountTask{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if assignedTo != "" {
+		query = query.Where("assigned_to = ?", assignedTo)
+	}
+
+	var tasks []model.RecountTask
+	err := query.Order("id").Find(&tasks).Error
+	return tasks, err
+}
+
+// AggregateVariances 汇总盘点差异，按绝对差异降序排列
+// 分组字段由调用方从 VarianceGroupColumns 中选取，此处再次校验以免拼接任意列名
+func (r *inventoryCheckRepository) AggregateVariances(filter VarianceAggregateFilter) ([]VarianceAggregate, error) {
+	allowed := make(map[string]bool, len(VarianceGroupColumns))
+	for _, col := range VarianceGroupColumns {
+		allowed[col] = true
+	}
+	for _, col := range filter.GroupBy {
+		if !allowed[col] {
+			return nil, fmt.Errorf("unsupported group column %q", col)
+		}
+	}
+
+	selects := append([]string{}, filter.GroupBy...)
+	selects = append(selects,
+		"COUNT(*) AS record_count",
+		"SUM(difference) AS net_variance",
+		"SUM(CASE WHEN difference > 0 THEN difference ELSE 0 END) AS gain_quantity",
+		"SUM(CASE WHEN difference < 0 THEN -difference ELSE 0 END) AS loss_quantity",
+		"SUM(ABS(difference)) AS absolute_variance",
+	)
+
+	query := r.db.Model(&model.InventoryCheckRecord{}).
+		Select(strings.Join(selects, ", ")).
+		Where("check_time >= ? AND check_time < ?", filter.From, filter.To).
+		Where("approval_status IN ?", []string{model.ApprovalStatusAutoApproved, model.ApprovalStatusApproved}).
+		Where("difference <> 0")
+	if filter.MaterialCode != "" {
+		query = query.Where("material_code = ?", filter.MaterialCode)
+	}
+	if filter.LocationCode != "" {
+		query = query.Where("location_code = ?", filter.LocationCode)
+	}
+	if filter.ReasonCode != "" {
+		query = query.Where("reason_code = ?", filter.ReasonCode)
+	}
+	order := "absolute_variance DESC"
+	if len(filter.GroupBy) > 0 {
+		group := strings.Join(filter.GroupBy, ", ")
+		query = query.Group(group)
+		order += ", " + group
+	}
+
+	var rows []VarianceAggregate
+	err := query.Order(order).Scan(&rows).Error
+	return rows, err
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"wms/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LayoutRepository 定义仓库布局设置与行走图的数据访问接口
+type LayoutRepository interface {
+	// GetLayout 在事务中查询布局设置，尚未配置时返回 nil
+	GetLayout(tx *gorm.DB) (*model.WarehouseLayout, error)
+
+	// SaveLayout 保存布局设置
+	SaveLayout(layout *model.WarehouseLayout) error
+
+	// ListEdges 在事务中查询行走图的全部通路
+	ListEdges(tx *gorm.DB) ([]model.LayoutEdge, error)
+
+	// UpsertEdge 按两端节点新增或更新通路
+	UpsertEdge(edge *model.LayoutEdge) error
+
+	// DeleteEdge 删除通路，返回是否删除了记录
+	DeleteEdge(id uint) (bool, error)
+
+	// BeginTransaction 开启新的数据库事务
+	BeginTransaction() *gorm.DB
+
+	// CommitTransaction 提交当前事务
+	CommitTransaction(tx *gorm.DB) error
+
+	// RollbackTransaction 回滚当前事务
+	RollbackTransaction(tx *gorm.DB) error
+}
+
+// layoutRepository 是 LayoutRepository 的具体实现
+type layoutRepository struct {
+	db *gorm.DB
+}
+
+// NewLayoutRepository 创建新的 LayoutRepository 实例
+func NewLayoutRepository(db *gorm.DB) LayoutRepository {
+	return &layoutRepository{
+		db: db,
+	}
+}
+
+// GetLayout 查询布局设置
+// 若尚未保存过设置则返回 nil（不视为错误）
+func (r *layoutRepository) GetLayout(tx *gorm.DB) (*model.WarehouseLayout, error) {
+	var layout model.WarehouseLayout
+	err := tx.First(&layout, 1).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &layout, nil
+}
+
+// SaveLayout 以固定 ID 保存唯一的布局设置
+func (r *layoutRepository) SaveLayout(layout *model.WarehouseLayout) error {
+	layout.ID = 1
+	return r.db.Save(layout).Error
+}
+
+// ListEdges 查询全部通路，按节点排序
+func (r *layoutRepository) ListEdges(tx *gorm.DB) ([]model.LayoutEdge, error) {
+	var edges []model.LayoutEdge
+	err := tx.Order("from_node, to_node").Find(&edges).Error
+	return edges, err
+}
+
+// UpsertEdge 按 (from_node, to_node) 唯一约束新增或更新通路
+func (r *layoutRepository) UpsertEdge(edge *model.LayoutEdge) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "from_node"}, {Name: "to_node"}},
+		DoUpdates: clause.AssignmentColumns([]string{"distance", "updated_at"}),
+	}).Create(edge).Error
+}
+
+// DeleteEdge 删除通路
+func (r *layoutRepository) DeleteEdge(id uint) (bool, error) {
+	result := r.db.Delete(&model.LayoutEdge{}, id)
+	return result.RowsAffected > 0, result.Error
+}
+
+// BeginTransaction 开启新的数据库事务
+func (r *layoutRepository) BeginTransaction() *gorm.DB {
+	return r.db.Begin()
+}
+
+// CommitTransaction 提交当前事务
+func (r *layoutRepository) CommitTransaction(tx *gorm.DB) error {
+	return tx.Commit().Error
+}
+
+// RollbackTransaction 回滚当前事务
+func (r *layoutRepository) RollbackTransaction(tx *gorm.DB) error {
+	return tx.Rollback().Error
+}
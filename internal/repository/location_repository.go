@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"wms/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// heldLocationCondition 排除不能作为出库分配或补货来源的库位中的库存：
+// 不允许上架（收货暂存、隔离、报废等）、已冻结、已停用或类型为隔离区的库位；参数依次为 false 与隔离区类型
+const heldLocationCondition = "NOT EXISTS (SELECT 1 FROM locations WHERE locations.code = stocks.location_code AND " +
+	"(locations.putaway_enabled = ? OR locations.blocked OR NOT locations.active OR locations.type = ?))"
+
+// unexpiredCondition 排除已过有效期的库存，参数为当天日期（YYYY-MM-DD），有效期当天仍可使用
+const unexpiredCondition = "stocks.expiry_date IS NULL OR stocks.expiry_date >= ?"
+
+// LocationFilter 表示库位的查询条件，零值字段表示不过滤
+type LocationFilter struct {
+	Warehouse string
+	Zone      string
+	Aisle     string
+	Type      string
+	Blocked   *bool
+	Active    *bool
+}
+
+// LocationRepository 定义库位主数据的数据访问接口
+type LocationRepository interface {
+	// List 按过滤条件查询库位
+	List(filter LocationFilter) ([]model.Location, error)
+
+	// Get 按编码查询库位，不存在时返回 nil
+	Get(code string) (*model.Location, error)
+
+	// Upsert 按库位编码新增或更新库位
+	Upsert(location *model.Location) error
+
+	// CreateMissing 批量创建尚不存在的库位，已存在的编码保持不变，返回新建的数量
+	CreateMissing(locations []model.Location) (int64, error)
+
+	// CreateMissingFromStocks 为库存中出现但尚未登记的库位编码补建存储位，返回补建的数量
+	CreateMissingFromStocks() (int64, error)
+
+	// GetByCode 在事务中按编码查询库位，不存在时返回 nil
+	GetByCode(tx *gorm.DB, code string) (*model.Location, error)
+
+	// GetForUpdate 在事务中按编码锁定库位，不存在时返回 nil
+	// 用于串行化同一库位的容量校验
+	GetForUpdate(tx *gorm.DB, code string) (*model.Location, error)
+
+	// ListAll 在事务中查询全部库位，按远近顺序排列
+	ListAll(tx *gorm.DB) ([]model.Location, error)
+}
+
+// locationRepository 是 LocationRepository 的具体实现
+type locationRepository struct {
+	db *gorm.DB
+}
+
+// NewLocationRepository 创建新的 LocationRepository 实例
+func NewLocationRepository(db *gorm.DB) LocationRepository {
+	return &locationRepository{
+		db: db,
+	}
+}
+
+// List 按过滤条件查询库位，按远近顺序与编码排序
+func (r *locationRepository) List(filter LocationFilter) ([]model.Location, error) {
+	query := r.db
+	if filter.Warehouse != "" {
+		query = query.Where("warehouse = ?", filter.Warehouse)
+	}
+	if filter.Zone != "" {
+		query = query.Where("zone = ?", filter.Zone)
+	}
+	if filter.Aisle != "" {
+		query = query.Where("aisle = ?", filter.Aisle)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.Blocked != nil {
+		query = query.Where("blocked = ?", *filter.Blocked)
+	}
+	if filter.Active != nil {
+		query = query.Where("active = ?", *filter.Active)
+	}
+
+	var locations []model.Location
+	err := query.Order("sequence, code").Find(&locations).Error
+	return locations, err
+}
+
+// Get 按编码查询库位
+func (r *locationRepository) Get(code string) (*model.Location, error) {
+	return r.find(r.db, code)
+}
+
+// Upsert 按 code 唯一约束新增或更新库位
+// active 列带有数据库默认值，新增时 false 会被忽略，因此停用的库位在写入后单独更新该列
+func (r *locationRepository) Upsert(location *model.Location) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "code"}},
+			DoUpdates: clause.AssignmentColumns([]string{"warehouse", "zone", "type", "sequence", "capacity",
+				"max_volume_cm3", "max_weight_kg", "max_skus", "putaway_enabled", "blocked", "block_reason", "active",
+				"aisle", "rack", "bin", "bay", "level", "x", "y", "updated_at"}),
+		}).Create(location).Error
+		if err != nil || location.Active {
+			return err
+		}
+		return tx.Model(&model.Location{}).Where("code = ?", location.Code).Update("active", false).Error
+	})
+}
+
+// CreateMissing 批量创建库位，与已有编码冲突的行被忽略
+func (r *locationRepository) CreateMissing(locations []model.Location) (int64, error) {
+	if len(locations) == 0 {
+		return 0, nil
+	}
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "code"}},
+		DoNothing: true,
+	}).CreateInBatches(&locations, 500)
+	return result.RowsAffected, result.Error
+}
+
+// CreateMissingFromStocks 以存储位类型为库存表中未登记的库位编码创建允许上架的库位，已登记的库位保持不变
+func (r *locationRepository) CreateMissingFromStocks() (int64, error) {
+	result := r.db.Exec(`
+		INSERT INTO locations (code, type, putaway_enabled, created_at, updated_at)
+		SELECT DISTINCT s.location_code, ?, TRUE, NOW(), NOW() FROM stocks s
+		WHERE NOT EXISTS (SELECT 1 FROM locations l WHERE l.code = s.location_code)
+		ON CONFLICT (code) DO NOTHING
+	`, model.LocationTypeReserve)
+	return result.RowsAffected, result.Error
+}
+
+// GetByCode 按编码查询库位
+func (r *locationRepository) GetByCode(tx *gorm.DB, code string) (*model.Location, error) {
+	return r.find(tx, code)
+}
+
+// GetForUpdate 以排他锁读取库位
+func (r *locationRepository) GetForUpdate(tx *gorm.DB, code string) (*model.Location, error) {
+	return r.find(tx.Clauses(clause.Locking{Strength: "UPDATE"}), code)
+}
+
+// ListAll 在事务中查询全部库位
+func (r *locationRepository) ListAll(tx *gorm.DB) ([]model.Location, error) {
+	var locations []model.Location
+	err := tx.Order("sequence, code").Find(&locations).Error
+	return locations, err
+}
+
+// find 按编码查询库位，不存在时返回 nil（不视为错误）
+func (r *locationRepository) find(query *gorm.DB, code string) (*model.Location, error) {
+	var location model.Location
+	err := query.Where("code = ?", code).First(&location).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &location, nil
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"wms/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MaterialFilter 表示物料的查询条件，零值字段表示不过滤
+type MaterialFilter struct {
+	Category string
+	Active   *bool
+	Barcode  string
+}
+
+// MaterialRepository 定义物料主数据的数据访问接口
+type MaterialRepository interface {
+	// List 按过滤条件查询物料，包含条码
+	List(filter MaterialFilter) ([]model.Material, error)
+
+	// GetByCode 按编码查询物料，包含条码，不存在时返回 nil
+	GetByCode(code string) (*model.Material, error)
+
+	// Upsert 在事务中按物料编码新增或更新物料
+	Upsert(tx *gorm.DB, material *model.Material) error
+
+	// ListBarcodes 在事务中查询给定条码的登记记录，未登记的条码不出现在结果中
+	ListBarcodes(tx *gorm.DB, barcodes []string) ([]model.MaterialBarcode, error)
+
+	// ReplaceBarcodes 在事务中用 barcodes 替换物料的全部条码
+	ReplaceBarcodes(tx *gorm.DB, materialCode string, barcodes []string) error
+
+	// HasStock 在事务中判断物料是否仍有在库或已预留的库存
+	HasStock(tx *gorm.DB, materialCode string) (bool, error)
+
+	// Delete 在事务中删除物料及其条码
+	Delete(tx *gorm.DB, code string) (bool, error)
+
+	// ListByCodes 在事务中按编码批量查询物料，不存在的编码不出现在结果中
+	ListByCodes(tx *gorm.DB, codes []string) ([]model.Material, error)
+
+	// CreateMissingFromStocks 为库存中出现但尚未登记的物料编码补建主数据，返回补建的数量
+	CreateMissingFromStocks() (int64, error)
+
+	// BeginTransaction 开启新的数据库事务
+	BeginTransaction() *gorm.DB
+
+	// CommitTransaction 提交当前事务
+	CommitTransaction(tx *gorm.DB) error
+
+	// RollbackTransaction 回滚当前事务
+	RollbackTransaction(tx *gorm.DB) error
+}
+
+// materialRepository 是 MaterialRepository 的具体实现
+type materialRepository struct {
+	db *gorm.DB
+}
+
+// NewMaterialRepository 创建新的 MaterialRepository 实例
+func NewMaterialRepository(db *gorm.DB) MaterialRepository {
+	return &materialRepository{
+		db: db,
+	}
+}
+
+// List 按过滤条件查询物料，按编码排序
+func (r *materialRepository) List(filter MaterialFilter) ([]model.Material, error) {
+	query := r.db.Preload("Barcodes", func(db *gorm.DB) *gorm.DB { return db.Order("barcode") })
+	if filter.Category != "" {
+		query = query.Where("category = ?", filter.Category)
+	}
+	if filter.Active != nil {
+		query = query.Where("active = ?", *filter.Active)
+	}
+	if filter.Barcode != "" {
+		query = query.Where("code IN (?)", r.db.Model(&model.MaterialBarcode{}).Select("material_code").Where("barcode = ?", filter.Barcode))
+	}
+
+	var materials []model.Material
+	err := query.Order("code").Find(&materials).Error
+	return materials, err
+}
+
+// GetByCode 按编码查询物料
+// 若物料不存在则返回 nil（不视为错误）
+func (r *materialRepository) GetByCode(code string) (*model.Material, error) {
+	var material model.Material
+	err := r.db.Preload("Barcodes", func(db *gorm.DB) *gorm.DB { return db.Order("barcode") }).
+		Where("code = ?", code).First(&material).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &material, nil
+}
+
+// Upsert 按 code 唯一约束新增或更新物料
+// active 列带有数据库默认值，新增时 false 会被忽略，因此停用的物料在写入后单独更新该列
+func (r *materialRepository) Upsert(tx *gorm.DB, material *model.Material) error {
+	err := tx.Omit("Barcodes").Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "code"}},
+		DoUpdates: clause.AssignmentColumns([]string{"description", "base_unit", "category",
+			"length_cm", "width_cm", "height_cm", "weight_kg", "cross_dock", "lot_managed", "serial_tracked", "active", "updated_at"}),
+	}).Create(material).Error
+	if err != nil || material.Active {
+		return err
+	}
+	return tx.Model(&model.Material{}).Where("code = ?", material.Code).Update("active", false).Error
+}
+
+// ListBarcodes 按条码批量查询条码登记记录
+func (r *materialRepository) ListBarcodes(tx *gorm.DB, barcodes []string) ([]model.MaterialBarcode, error) {
+	var records []model.MaterialBarcode
+	if len(barcodes) == 0 {
+		return records, nil
+	}
+	err := tx.Where("barcode IN ?", barcodes).Order("barcode").Find(&records).Error
+	return records, err
+}
+
+// ReplaceBarcodes 删除物料原有条码后写入新的条码
+func (r *materialRepository) ReplaceBarcodes(tx *gorm.DB, materialCode string, barcodes []string) error {
+	if err := tx.Where("material_code = ?", materialCode).Delete(&model.MaterialBarcode{}).Error; err != nil {
+		return err
+	}
+	if len(barcodes) == 0 {
+		return nil
+	}
+	records := make([]model.MaterialBarcode, 0, len(barcodes))
+	for _, barcode := range barcodes {
+		records = append(records, model.MaterialBarcode{MaterialCode: materialCode, Barcode: barcode})
+	}
+	return tx.Create(&records).Error
+}
+
+// HasStock 判断物料是否存在数量或预留数量大于 0 的库存
+func (r *materialRepository) HasStock(tx *gorm.DB, materialCode string) (bool, error) {
+	var count int64
+	err := tx.Model(&model.Stock{}).
+		Where("material_code = ? AND (quantity > 0 OR reserved_quantity > 0)", materialCode).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Delete 删除物料及其条码，物料不存在时返回 false
+func (r *materialRepository) Delete(tx *gorm.DB, code string) (bool, error) {
+	if err := tx.Where("material_code = ?", code).Delete(&model.MaterialBarcode{}).Error; err != nil {
+		return false, err
+	}
+	result := tx.Where("code = ?", code).Delete(&model.Material{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ListByCodes 按编码批量查询物料
+func (r *materialRepository) ListByCodes(tx *gorm.DB, codes []string) ([]model.Material, error) {
+	var materials []model.Material
+	if len(codes) == 0 {
+		return materials, nil
+	}
+	err := tx.Where("code IN ?", codes).Order("code").Find(&materials).Error
+	return materials, err
+}
+
+// CreateMissingFromStocks 以默认属性为库存表中未登记的物料编码创建启用的主数据，已登记的物料保持不变
+func (r *materialRepository) CreateMissingFromStocks() (int64, error) {
+	result := r.db.Exec(`
+		INSERT INTO materials (code, created_at, updated_at)
+		SELECT DISTINCT s.material_code, NOW(), NOW() FROM stocks s
+		WHERE NOT EXISTS (SELECT 1 FROM materials m WHERE m.code = s.material_code)
+		ON CONFLICT (code) DO NOTHING
+	`)
+	return result.RowsAffected, result.Error
+}
+
+// BeginTransaction 开启新的数据库事务
+func (r *materialRepository) BeginTransaction() *gorm.DB {
+	return r.db.Begin()
+}
+
+// CommitTransaction 提交当前事务
+func (r *materialRepository) CommitTransaction(tx *gorm.DB) error {
+	return tx.Commit().Error
+}
+
+// RollbackTransaction 回滚当前事务
+func (r *materialRepository) RollbackTransaction(tx *gorm.DB) error {
+	return tx.Rollback().Error
+}
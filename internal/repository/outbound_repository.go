@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"time"
+	"wms/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OutboundOrderFilter 表示出库单据的查询条件，零值字段表示不过滤
+type OutboundOrderFilter struct {
+	Status       string
+	CustomerCode string
+}
+
+// OutboundRepository 定义出库单据与库存预留的数据访问接口
+type OutboundRepository interface {
+	// Create 创建出库单据及其物料行
+	Create(order *model.OutboundOrder) error
+
+	// GetByID 按 ID 查询出库单据（含物料行），不存在时返回 nil
+	GetByID(id uint) (*model.OutboundOrder, error)
+
+	// GetByOrderNo 按单据号查询出库单据（不含物料行），不存在时返回 nil
+	GetByOrderNo(orderNo string) (*model.OutboundOrder, error)
+
+	// List 按过滤条件查询出库单据（含物料行）
+	List(filter OutboundOrderFilter) ([]model.OutboundOrder, error)
+
+	// GetForUpdate 在事务中锁定出库单据（含物料行），不存在时返回 nil
+	GetForUpdate(tx *gorm.DB, id uint) (*model.OutboundOrder, error)
+
+	// UpdateStatus 在事务中保存出库单据的状态、暂存库位、取消与发运信息（不包括物料行）
+	UpdateStatus(tx *gorm.DB, order *model.OutboundOrder) error
+
+	// UpdateLine 在事务中保存物料行的分配、拣货、装箱、发运数量与状态
+	UpdateLine(tx *gorm.DB, line *model.OutboundOrderLine) error
+
+	// ListWaitingOrdersForUpdate 在事务中按 ID 顺序锁定有指定物料未分配足量的 open / partially_allocated 单据（含物料行）
+	ListWaitingOrdersForUpdate(tx *gorm.DB, materialCodes []string) ([]model.OutboundOrder, error)
+
+	// ListAllocatableStocks 在事务中锁定物料有可分配数量的库存行（每个批次一行），按库位编码、批次号排序
+	// 登记为不允许上架的库位（收货暂存区、月台等）中的库存与 today 时已过有效期的库存不参与分配
+	ListAllocatableStocks(tx *gorm.DB, materialCode string, today time.Time) ([]model.Stock, error)
+
+	// CreateAllocation 在事务中创建库存预留记录
+	CreateAllocation(tx *gorm.DB, allocation *model.StockAllocation) error
+
+	// ListAllocations 查询出库单据的全部预留记录
+	ListAllocations(orderID uint) ([]model.StockAllocation, error)
+
+	// ListActiveAllocations 在事务中查询出库单据预留中的记录，按物料、库位、批次排序
+	ListActiveAllocations(tx *gorm.DB, orderID uint) ([]model.StockAllocation, error)
+
+	// ListPickedAllocations 在事务中查询出库单据已拣货确认且实拣数量大于 0 的预留记录，按物料、批次排序
+	ListPickedAllocations(tx *gorm.DB, orderID uint) ([]model.StockAllocation, error)
+
+	// ReleaseAllocations 在事务中将预留记录标记为已释放
+	ReleaseAllocations(tx *gorm.DB, ids []uint, at time.Time) error
+
+	// BeginTransaction 开启新的数据库事务
+	BeginTransaction() *gorm.DB
+
+	// CommitTransaction 提交当前事务
+	CommitTransaction(tx *gorm.DB) error
+
+	// RollbackTransaction 回滚当前事务
+	RollbackTransaction(tx *gorm.DB) error
+}
+
+// outboundRepository 是 OutboundRepository 的具体实现
+type outboundRepository struct {
+	db *gorm.DB
+}
+
+// NewOutboundRepository 创建新的 OutboundRepository 实例
+func NewOutboundRepository(db *gorm.DB) OutboundRepository {
+	return &outboundRepository{
+		db: db,
+	}
+}
+
+// Create 创建出库单据，物料行随主记录一并写入
+func (r *outboundRepository) Create(order *model.OutboundOrder) error {
+	return r.db.Create(order).Error
+}
+
+// GetByID 按 ID 查询出库单据
+// 若单据不存在则返回 nil（不视为错误）
+func (r *outboundRepository) GetByID(id uint) (*model.OutboundOrder, error) {
+	return r.find(r.db, id)
+}
+
+// GetByOrderNo 按单据号查询出库单据
+func (r *outboundRepository) GetByOrderNo(orderNo string) (*model.OutboundOrder, error) {
+	var order model.OutboundOrder
+	err := r.db.Where("order_no = ?", orderNo).First(&order).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &order, nil
+}
+
+// List 查询出库单据，按创建时间倒序
+func (r *outboundRepository) List(filter OutboundOrderFilter) ([]model.OutboundOrder, error) {
+	query := r.db.Model(&model.OutboundOrder{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.CustomerCode != "" {
+		query = query.Where("customer_code = ?", filter.CustomerCode)
+	}
+
+	var orders []model.OutboundOrder
+	err := query.Preload("Lines", orderLinesByNo).Order("id DESC").Find(&orders).Error
+	return orders, err
+}
+
+// GetForUpdate 以排他锁读取出库单据
+func (r *outboundRepository) GetForUpdate(tx *gorm.DB, id uint) (*model.OutboundOrder, error) {
+	return r.find(tx.Clauses(clause.Locking{Strength: "UPDATE"}), id)
+}
+
+// UpdateStatus 保存出库单据状态字段，不级联更新物料行
+func (r *outboundRepository) UpdateStatus(tx *gorm.DB, order *model.OutboundOrder) error {
+	return tx.Model(order).Select("status", "staging_location", "cancelled_by", "cancelled_at", "cancel_reason", "shipped_at", "updated_at").Updates(order).Error
+}
+
+// UpdateLine 保存物料行的分配、拣货、装箱、发运数量与状态
+func (r *outboundRepository) UpdateLine(tx *gorm.DB, line *model.OutboundOrderLine) error {
+	return tx.Model(line).Select("allocated_quantity", "picked_quantity", "packed_quantity", "shipped_quantity", "status", "updated_at").Updates(line).Error
+}
+
+// ListWaitingOrdersForUpdate 以排他锁读取等待分配指定物料的出库单据
+func (r *outboundRepository) ListWaitingOrdersForUpdate(tx *gorm.DB, materialCodes []string) ([]model.OutboundOrder, error) {
+	var orders []model.OutboundOrder
+	if len(materialCodes) == 0 {
+		return orders, nil
+	}
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("status IN ?", []string{model.OutboundOrderStatusOpen, model.OutboundOrderStatusPartiallyAllocated}).
+		Where("EXISTS (SELECT 1 FROM outbound_order_lines WHERE outbound_order_lines.order_id = outbound_orders.id AND outbound_order_lines.material_code IN ? AND outbound_order_lines.allocated_quantity < outbound_order_lines.ordered_quantity)", materialCodes).
+		Preload("Lines", orderLinesByNo).
+		Order("id").
+		Find(&orders).Error
+	return orders, err
+}
+
+// ListAllocatableStocks 以排他锁读取可分配的库存行
+func (r *outboundRepository) ListAllocatableStocks(tx *gorm.DB, materialCode string, today time.Time) ([]model.Stock, error) {
+	var stocks []model.Stock
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("material_code = ? AND quantity > reserved_quantity", materialCode).
+		Where(heldLocationCondition, false, model.LocationTypeQuarantine).
+		Where(unexpiredCondition, today.Format("2006-01-02")).
+		Order("location_code, lot_number").
+		Find(&stocks).Error
+	return stocks, err
+}
+
+// CreateAllocation 新增库存预留记录
+func (r *outboundRepository) CreateAllocation(tx *gorm.DB, allocation *model.StockAllocation) error {
+	return tx.Create(allocation).Error
+}
+
+// ListAllocations 查询预留记录，按创建顺序排列
+func (r *outboundRepository) ListAllocations(orderID uint) ([]model.StockAllocation, error) {
+	var allocations []model.StockAllocation
+	err := r.db.Where("order_id = ?", orderID).Order("id").Find(&allocations).Error
+	return allocations, err
+}
+
+// ListActiveAllocations 查询预留中的记录
+// 按物料、库位、批次排序，使释放预留时与分配以相同顺序锁定库存行
+func (r *outboundRepository) ListActiveAllocations(tx *gorm.DB, orderID uint) ([]model.StockAllocation, error) {
+	var allocations []model.StockAllocation
+	err := tx.Where("order_id = ? AND status = ?", orderID, model.AllocationStatusActive).
+		Order("material_code, location_code, lot_number, id").
+		Find(&allocations).Error
+	return allocations, err
+}
+
+// ListPickedAllocations 查询已拣货确认的预留记录
+func (r *outboundRepository) ListPickedAllocations(tx *gorm.DB, orderID uint) ([]model.StockAllocation, error) {
+	var allocations []model.StockAllocation
+	err := tx.Where("order_id = ? AND status = ? AND picked_quantity > 0", orderID, model.AllocationStatusPicked).
+		Order("material_code, lot_number, id").
+		Find(&allocations).Error
+	return allocations, err
+}
+
+// ReleaseAllocations 批量释放预留记录
+func (r *outboundRepository) ReleaseAllocations(tx *gorm.DB, ids []uint, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return tx.Model(&model.StockAllocation{}).Where("id IN ?", ids).
+		Updates(map[string]interface{}{"status": model.AllocationStatusReleased, "released_at": at}).Error
+}
+
+// BeginTransaction 开启新的数据库事务
+func (r *outboundRepository) BeginTransaction() *gorm.DB {
+	return r.db.Begin()
+}
+
+// CommitTransaction 提交当前事务
+func (r *outboundRepository) CommitTransaction(tx *gorm.DB) error {
+	return tx.Commit().Error
+}
+
+// RollbackTransaction 回滚当前事务
+func (r *outboundRepository) RollbackTransaction(tx *gorm.DB) error {
+	return tx.Rollback().Error
+}
+
+// find 查询出库单据并预加载物料行
+// 锁只作用于主表查询，物料行由单据锁间接保护
+func (r *outboundRepository) find(query *gorm.DB, id uint) (*model.OutboundOrder, error) {
+	var order model.OutboundOrder
+	err := query.Preload("Lines", orderLinesByNo).First(&order, id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &order, nil
+}
@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"time"
+	"wms/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PackingRepository 定义箱型、包裹与发运记录的数据访问接口
+type PackingRepository interface {
+	// ListCartonTypes 查询全部箱型
+	ListCartonTypes() ([]model.CartonType, error)
+
+	// UpsertCartonType 按箱型编码新增或更新箱型
+	UpsertCartonType(carton *model.CartonType) error
+
+	// ListActiveCartonTypes 在事务中查询启用的箱型，按容积从小到大排序
+	ListActiveCartonTypes(tx *gorm.DB) ([]model.CartonType, error)
+
+	// CreatePackage 在事务中创建包裹及其装箱行
+	CreatePackage(tx *gorm.DB, pkg *model.Package) error
+
+	// NextPackageSequence 在事务中返回单据下一个包裹序号
+	NextPackageSequence(tx *gorm.DB, orderID uint) (int, error)
+
+	// GetPackage 在事务中查询包裹（含装箱行），不存在时返回 nil
+	GetPackage(tx *gorm.DB, id uint) (*model.Package, error)
+
+	// DeletePackage 在事务中删除包裹及其装箱行
+	DeletePackage(tx *gorm.DB, id uint) error
+
+	// ListPackages 在事务中查询单据的全部包裹（含装箱行），按包裹序号排序
+	ListPackages(tx *gorm.DB, orderID uint) ([]model.Package, error)
+
+	// MarkPackagesShipped 在事务中将单据的包裹关联到发运记录并标记为已发运
+	MarkPackagesShipped(tx *gorm.DB, orderID, shipmentID uint, at time.Time) error
+
+	// CreateShipment 在事务中创建发运记录
+	CreateShipment(tx *gorm.DB, shipment *model.Shipment) error
+
+	// GetShipmentByOrder 在事务中查询单据的发运记录，未发运时返回 nil
+	GetShipmentByOrder(tx *gorm.DB, orderID uint) (*model.Shipment, error)
+
+	// GetShipment 在事务中按 ID 查询发运记录，不存在时返回 nil
+	GetShipment(tx *gorm.DB, id uint) (*model.Shipment, error)
+
+	// BeginTransaction 开启新的数据库事务
+	BeginTransaction() *gorm.DB
+
+	// CommitTransaction 提交当前事务
+	CommitTransaction(tx *gorm.DB) error
+
+	// RollbackTransaction 回滚当前事务
+	RollbackTransaction(tx *gorm.DB) error
+}
+
+// packingRepository 是 PackingRepository 的具体实现
+type packingRepository struct {
+	db *gorm.DB
+}
+
+// NewPackingRepository 创建新的 PackingRepository 实例
+func NewPackingRepository(db *gorm.DB) PackingRepository {
+	return &packingRepository{
+		db: db,
+	}
+}
+
+// ListCartonTypes 查询全部箱型，按编码排序
+func (r *packingRepository) ListCartonTypes() ([]model.CartonType, error) {
+	var cartons []model.CartonType
+	err := r.db.Order("code").Find(&cartons).Error
+	return cartons, err
+}
+
+// UpsertCartonType 按 code 唯一约束新增或更新箱型
+func (r *packingRepository) UpsertCartonType(carton *model.CartonType) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "code"}},
+		DoUpdates: clause.AssignmentColumns([]string{"description", "length_cm", "width_cm", "height_cm",
+			"max_weight_kg", "tare_weight_kg", "active", "updated_at"}),
+	}).Create(carton).Error
+}
+
+// ListActiveCartonTypes 查询启用的箱型
+func (r *packingRepository) ListActiveCartonTypes(tx *gorm.DB) ([]model.CartonType, error) {
+	var cartons []model.CartonType
+	err := tx.Where("active = ?", true).
+		Order("length_cm * width_cm * height_cm, code").
+		Find(&cartons).Error
+	return cartons, err
+}
+
+// CreatePackage 创建包裹，装箱行随主记录一并写入
+func (r *packingRepository) CreatePackage(tx *gorm.DB, pkg *model.Package) error {
+	return tx.Create(pkg).Error
+}
+
+// NextPackageSequence 取单据现有包裹的最大序号加一
+// 调用方须已锁定出库单据，保证同一单据的包裹序号不会并发分配
+func (r *packingRepository) NextPackageSequence(tx *gorm.DB, orderID uint) (int, error) {
+	var max int
+	err := tx.Model(&model.Package{}).
+		Where("order_id = ?", orderID).
+		Select("COALESCE(MAX(sequence), 0)").
+		Scan(&max).Error
+	return max + 1, err
+}
+
+// GetPackage 查询包裹
+func (r *packingRepository) GetPackage(tx *gorm.DB, id uint) (*model.Package, error) {
+	var pkg model.Package
+	err := tx.Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).First(&pkg, id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &pkg, nil
+}
+
+// DeletePackage 删除包裹，装箱行先于主记录删除
+func (r *packingRepository) DeletePackage(tx *gorm.DB, id uint) error {
+	if err := tx.Where("package_id = ?", id).Delete(&model.PackageLine{}).Error; err != nil {
+		return err
+	}
+	return tx.Delete(&model.Package{}, id).Error
+}
+
+// ListPackages 查询单据的全部包裹
+func (r *packingRepository) ListPackages(tx *gorm.DB, orderID uint) ([]model.Package, error) {
+	var packages []model.Package
+	err := tx.Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).Where("order_id = ?", orderID).Order("sequence").Find(&packages).Error
+	return packages, err
+}
+
+// MarkPackagesShipped 批量更新单据包裹的发运信息
+func (r *packingRepository) MarkPackagesShipped(tx *gorm.DB, orderID, shipmentID uint, at time.Time) error {
+	return tx.Model(&model.Package{}).
+		Where("order_id = ?", orderID).
+		Updates(map[string]interface{}{
+			"status":      model.PackageStatusShipped,
+			"shipment_id": shipmentID,
+			"updated_at":  at,
+		}).Error
+}
+
+// CreateShipment 创建发运记录
+func (r *packingRepository) CreateShipment(tx *gorm.DB, shipment *model.Shipment) error {
+	return tx.Create(shipment).Error
+}
+
+// GetShipmentByOrder 查询单据的发运记录
+func (r *packingRepository) GetShipmentByOrder(tx *gorm.DB, orderID uint) (*model.Shipment, error) {
+	return r.findShipment(tx.Where("order_id = ?", orderID))
+}
+
+// GetShipment 按 ID 查询发运记录
+func (r *packingRepository) GetShipment(tx *gorm.DB, id uint) (*model.Shipment, error) {
+	return r.findShipment(tx.Where("id = ?", id))
+}
+
+// findShipment 查询一条发运记录，不存在时返回 nil（不视为错误）
+func (r *packingRepository) findShipment(query *gorm.DB) (*model.Shipment, error) {
+	var shipment model.Shipment
+	err := query.First(&shipment).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &shipment, nil
+}
+
+// BeginTransaction 开启新的数据库事务
+func (r *packingRepository) BeginTransaction() *gorm.DB {
+	return r.db.Begin()
+}
+
+// CommitTransaction 提交当前事务
+func (r *packingRepository) CommitTransaction(tx *gorm.DB) error {
+	return tx.Commit().Error
+}
+
+// RollbackTransaction 回滚当前事务
+func (r *packingRepository) RollbackTransaction(tx *gorm.DB) error {
+	return tx.Rollback().Error
+}
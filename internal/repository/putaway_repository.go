@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"wms/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LocationLoad 表示库位上一种物料的数量合计
+// VolumeCm3、WeightKg 按物料主数据的单件尺寸与重量折算，未登记尺寸或重量的物料计为 0
+type LocationLoad struct {
+	LocationCode string
+	MaterialCode string
+	Quantity     int
+	VolumeCm3    float64
+	WeightKg     float64
+}
+
+// PutawayTaskFilter 表示上架任务的查询条件，零值字段表示不过滤
+type PutawayTaskFilter struct {
+	Status       string
+	AssignedTo   string
+	MaterialCode string
+}
+
+// PutawayRepository 定义上架规则与上架任务的数据访问接口
+type PutawayRepository interface {
+	// GetPolicy 在事务中查询上架规则，尚未配置时返回 nil
+	GetPolicy(tx *gorm.DB) (*model.PutawayPolicy, error)
+
+	// SavePolicy 保存上架规则
+	SavePolicy(policy *model.PutawayPolicy) error
+
+	// ListFixedBins 查询全部物料固定库位
+	ListFixedBins() ([]model.PutawayFixedBin, error)
+
+	// UpsertFixedBin 按物料新增或更新固定库位
+	UpsertFixedBin(bin *model.PutawayFixedBin) error
+
+	// GetFixedBin 在事务中查询物料的固定库位，未配置时返回 nil
+	GetFixedBin(tx *gorm.DB, materialCode string) (*model.PutawayFixedBin, error)
+
+	// StockLoads 在事务中按库位与物料统计库存数量合计（只含数量大于 0 的库存），locationCode 非空时只统计该库位
+	StockLoads(tx *gorm.DB, locationCode string) ([]LocationLoad, error)
+
+	// IncomingLoads 在事务中按库位与物料统计未完成上架任务的待入数量
+	IncomingLoads(tx *gorm.DB) ([]LocationLoad, error)
+
+	// ListMaterialLocations 在事务中查询物料数量大于 0 的库存
+	ListMaterialLocations(tx *gorm.DB, materialCode string) ([]model.Stock, error)
+
+	// CreateTask 在事务中创建上架任务
+	CreateTask(tx *gorm.DB, task *model.PutawayTask) error
+
+	// GetTaskForUpdate 在事务中锁定上架任务，不存在时返回 nil
+	GetTaskForUpdate(tx *gorm.DB, id uint) (*model.PutawayTask, error)
+
+	// UpdateTask 在事务中保存上架任务的变更
+	UpdateTask(tx *gorm.DB, task *model.PutawayTask) error
+
+	// ListTasks 按过滤条件查询上架任务
+	ListTasks(filter PutawayTaskFilter) ([]model.PutawayTask, error)
+
+	// BeginTransaction 开启新的数据库事务
+	BeginTransaction() *gorm.DB
+
+	// CommitTransaction 提交当前事务
+	CommitTransaction(tx *gorm.DB) error
+
+	// RollbackTransaction 回滚当前事务
+	RollbackTransaction(tx *gorm.DB) error
+}
+
+// putawayRepository 是 PutawayRepository 的具体实现
+type putawayRepository struct {
+	db *gorm.DB
+}
+
+// NewPutawayRepository 创建新的 PutawayRepository 实例
+func NewPutawayRepository(db *gorm.DB) PutawayRepository {
+	return &putawayRepository{
+		db: db,
+	}
+}
+
+// GetPolicy 查询上架规则
+// 若尚未保存过规则则返回 nil（不视为错误）
+func (r *putawayRepository) GetPolicy(tx *gorm.DB) (*model.PutawayPolicy, error) {
+	var policy model.PutawayPolicy
+	err := tx.First(&policy, 1).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// SavePolicy 以固定 ID 保存唯一的上架规则
+func (r *putawayRepository) SavePolicy(policy *model.PutawayPolicy) error {
+	policy.ID = 1
+	return r.db.Save(policy).Error
+}
+
+// ListFixedBins 查询全部物料固定库位，按物料排序
+func (r *putawayRepository) ListFixedBins() ([]model.PutawayFixedBin, error) {
+	var bins []model.PutawayFixedBin
+	err := r.db.Order("material_code").Find(&bins).Error
+	return bins, err
+}
+
+// UpsertFixedBin 按 material_code 唯一约束新增或更新固定库位
+func (r *putawayRepository) UpsertFixedBin(bin *model.PutawayFixedBin) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "material_code"}},
+		DoUpdates: clause.AssignmentColumns([]string{"location_code", "updated_at"}),
+	}).Create(bin).Error
+}
+
+// GetFixedBin 查询物料的固定库位
+func (r *putawayRepository) GetFixedBin(tx *gorm.DB, materialCode string) (*model.PutawayFixedBin, error) {
+	var bin model.PutawayFixedBin
+	err := tx.Where("material_code = ?", materialCode).First(&bin).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &bin, nil
+}
+
+// loadColumns 是按库位与物料统计占用时的查询列，quantity 列由调用方的表提供
+const loadColumns = "SUM(quantity) AS quantity, " +
+	"COALESCE(SUM(quantity * materials.length_cm * materials.width_cm * materials.height_cm), 0) AS volume_cm3, " +
+	"COALESCE(SUM(quantity * materials.weight_kg), 0) AS weight_kg"
+
+// StockLoads 按库位与物料统计库存数量、体积与重量
+func (r *putawayRepository) StockLoads(tx *gorm.DB, locationCode string) ([]LocationLoad, error) {
+	var loads []LocationLoad
+	query := tx.Model(&model.Stock{}).
+		Select("stocks.location_code, stocks.material_code, " + loadColumns).
+		Joins("LEFT JOIN materials ON materials.code = stocks.material_code").
+		Where("stocks.quantity > 0")
+	if locationCode != "" {
+		query = query.Where("stocks.location_code = ?", locationCode)
+	}
+	err := query.Group("stocks.location_code, stocks.material_code").Scan(&loads).Error
+	return loads, err
+}
+
+// IncomingLoads 按库位与物料统计未完成上架任务的待入数量、体积与重量
+func (r *putawayRepository) IncomingLoads(tx *gorm.DB) ([]LocationLoad, error) {
+	var loads []LocationLoad
+	err := tx.Model(&model.PutawayTask{}).
+		Select("putaway_tasks.target_location AS location_code, putaway_tasks.material_code, "+loadColumns).
+		Joins("LEFT JOIN materials ON materials.code = putaway_tasks.material_code").
+		Where("putaway_tasks.status = ? AND putaway_tasks.target_location <> ''", model.PutawayTaskStatusOpen).
+		Group("putaway_tasks.target_location, putaway_tasks.material_code").Scan(&loads).Error
+	return loads, err
+}
+
+// ListMaterialLocations 查询物料有库存的库位
+func (r *putawayRepository) ListMaterialLocations(tx *gorm.DB, materialCode string) ([]model.Stock, error) {
+	var stocks []model.Stock
+	err := tx.Where("material_code = ? AND quantity > 0", materialCode).Order("location_code").Find(&stocks).Error
+	return stocks, err
+}
+
+// CreateTask 新增上架任务
+func (r *putawayRepository) CreateTask(tx *gorm.DB, task *model.PutawayTask) error {
+	return tx.Create(task).Error
+}
+
+// GetTaskForUpdate 以排他锁读取上架任务
+func (r *putawayRepository) GetTaskForUpdate(tx *gorm.DB, id uint) (*model.PutawayTask, error) {
+	var task model.PutawayTask
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&task, id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &task, nil
+}
+
+// UpdateTask 保存上架任务的变更
+func (r *putawayRepository) UpdateTask(tx *gorm.DB, task *model.PutawayTask) error {
+	return tx.Save(task).Error
+}
+
+// ListTasks 查询上架任务，按创建顺序排列
+func (r *putawayRepository) ListTasks(filter PutawayTaskFilter) ([]model.PutawayTask, error) {
+	query := r.db.Model(&model.PutawayTask{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.AssignedTo != "" {
+		query = query.Where("assigned_to = ?", filter.AssignedTo)
+	}
+	if filter.MaterialCode != "" {
+		query = query.Where("material_code = ?", filter.MaterialCode)
+	}
+
+	var tasks []model.PutawayTask
+	err := query.Order("id").Find(&tasks).Error
+	return tasks, err
+}
+
+// BeginTransaction 开启新的数据库事务
+func (r *putawayRepository) BeginTransaction() *gorm.DB {
+	return r.db.Begin()
+}
+
+// CommitTransaction 提交当前事务
+func (r *putawayRepository) CommitTransaction(tx *gorm.DB) error {
+	return tx.Commit().Error
+}
+
+// RollbackTransaction 回滚当前事务
+func (r *putawayRepository) RollbackTransaction(tx *gorm.DB) error {
+	return tx.Rollback().Error
+}
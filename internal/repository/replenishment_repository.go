@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"time"
+	"wms/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReplenishmentRuleFilter 表示补货扫描的范围，零值字段表示不过滤
+type ReplenishmentRuleFilter struct {
+	MaterialCode string
+	LocationCode string
+}
+
+// ReplenishmentTaskFilter 表示补货任务的查询条件，零值字段表示不过滤
+type ReplenishmentTaskFilter struct {
+	Status         string
+	MaterialCode   string
+	TargetLocation string
+	Trigger        string
+}
+
+// ReplenishmentRepository 定义补货水位与补货任务的数据访问接口
+type ReplenishmentRepository interface {
+	// ListRules 查询全部补货水位
+	ListRules() ([]model.ReplenishmentRule, error)
+
+	// UpsertRule 按 (物料, 库位) 新增或更新补货水位
+	UpsertRule(rule *model.ReplenishmentRule) error
+
+	// DeleteRule 删除补货水位，返回是否删除了记录
+	DeleteRule(id uint) (bool, error)
+
+	// ListActiveRules 按范围查询启用的补货水位，按库位、物料排序
+	ListActiveRules(filter ReplenishmentRuleFilter) ([]model.ReplenishmentRule, error)
+
+	// GetRuleForUpdate 在事务中锁定物料在库位上启用的补货水位，未配置或已停用时返回 nil
+	GetRuleForUpdate(tx *gorm.DB, materialCode, locationCode string) (*model.ReplenishmentRule, error)
+
+	// PendingQuantity 在事务中统计补往某拣货位的未完成补货任务数量合计
+	PendingQuantity(tx *gorm.DB, materialCode, locationCode string) (int, error)
+
+	// OnHandQuantity 在事务中统计物料在拣货位上各批次的在库数量合计
+	OnHandQuantity(tx *gorm.DB, materialCode, locationCode string) (int, error)
+
+	// ListReserveStocks 在事务中锁定可作为补货来源的库存行：有可用数量、不是该物料的拣货位、
+	// 不在不允许上架的库位（暂存区、隔离区等）、today 时未过有效期，按库位编码、批次排序
+	ListReserveStocks(tx *gorm.DB, materialCode string, today time.Time) ([]model.Stock, error)
+
+	// CreateTask 在事务中创建补货任务
+	CreateTask(tx *gorm.DB, task *model.ReplenishmentTask) error
+
+	// GetTaskForUpdate 在事务中锁定补货任务，不存在时返回 nil
+	GetTaskForUpdate(tx *gorm.DB, id uint) (*model.ReplenishmentTask, error)
+
+	// UpdateTask 在事务中保存补货任务的状态与确认、取消信息
+	UpdateTask(tx *gorm.DB, task *model.ReplenishmentTask) error
+
+	// ListTasks 按过滤条件查询补货任务
+	ListTasks(filter ReplenishmentTaskFilter) ([]model.ReplenishmentTask, error)
+
+	// BeginTransaction 开启新的数据库事务
+	BeginTransaction() *gorm.DB
+
+	// CommitTransaction 提交当前事务
+	CommitTransaction(tx *gorm.DB) error
+
+	// RollbackTransaction 回滚当前事务
+	RollbackTransaction(tx *gorm.DB) error
+}
+
+// replenishmentRepository 是 ReplenishmentRepository 的具体实现
+type replenishmentRepository struct {
+	db *gorm.DB
+}
+
+// NewReplenishmentRepository 创建新的 ReplenishmentRepository 实例
+func NewReplenishmentRepository(db *gorm.DB) ReplenishmentRepository {
+	return &replenishmentRepository{
+		db: db,
+	}
+}
+
+// ListRules 查询全部补货水位，按库位、物料排序
+func (r *replenishmentRepository) ListRules() ([]model.ReplenishmentRule, error) {
+	var rules []model.ReplenishmentRule
+	err := r.db.Order("location_code, material_code").Find(&rules).Error
+	return rules, err
+}
+
+// UpsertRule 按 (material_code, location_code) 唯一约束新增或更新补货水位
+func (r *replenishmentRepository) UpsertRule(rule *model.ReplenishmentRule) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "material_code"}, {Name: "location_code"}},
+		DoUpdates: clause.AssignmentColumns([]string{"min_quantity", "max_quantity", "active", "updated_by", "updated_at"}),
+	}).Create(rule).Error
+}
+
+// DeleteRule 删除补货水位
+func (r *replenishmentRepository) DeleteRule(id uint) (bool, error) {
+	result := r.db.Delete(&model.ReplenishmentRule{}, id)
+	return result.RowsAffected > 0, result.Error
+}
+
+// ListActiveRules 按范围查询启用的补货水位
+func (r *replenishmentRepository) ListActiveRules(filter ReplenishmentRuleFilter) ([]model.ReplenishmentRule, error) {
+	query := r.db.Where("active = ?", true)
+	if filter.MaterialCode != "" {
+		query = query.Where("material_code = ?", filter.MaterialCode)
+	}
+	if filter.LocationCode != "" {
+		query = query.Where("location_code = ?", filter.LocationCode)
+	}
+
+	var rules []model.ReplenishmentRule
+	err := query.Order("location_code, material_code").Find(&rules).Error
+	return rules, err
+}
+
+// GetRuleForUpdate 以排他锁读取启用的补货水位
+func (r *replenishmentRepository) GetRuleForUpdate(tx *gorm.DB, materialCode, locationCode string) (*model.ReplenishmentRule, error) {
+	var rule model.ReplenishmentRule
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("material_code = ? AND location_code = ? AND active = ?", materialCode, locationCode, true).
+		First(&rule).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// PendingQuantity 统计补往拣货位的未完成补货数量
+func (r *replenishmentRepository) PendingQuantity(tx *gorm.DB, materialCode, locationCode string) (int, error) {
+	var quantity int
+	err := tx.Model(&model.ReplenishmentTask{}).
+		Where("material_code = ? AND target_location = ? AND status = ?", materialCode, locationCode, model.ReplenishmentStatusOpen).
+		Select("COALESCE(SUM(quantity), 0)").
+		Scan(&quantity).Error
+	return quantity, err
+}
+
+// OnHandQuantity 统计拣货位各批次的在库数量
+func (r *replenishmentRepository) OnHandQuantity(tx *gorm.DB, materialCode, locationCode string) (int, error) {
+	var quantity int
+	err := tx.Model(&model.Stock{}).
+		Where("material_code = ? AND location_code = ?", materialCode, locationCode).
+		Select("COALESCE(SUM(quantity), 0)").
+		Scan(&quantity).Error
+	return quantity, err
+}
+
+// ListReserveStocks 以排他锁读取物料可作为补货来源的存储库存
+func (r *replenishmentRepository) ListReserveStocks(tx *gorm.DB, materialCode string, today time.Time) ([]model.Stock, error) {
+	var stocks []model.Stock
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("material_code = ? AND quantity > reserved_quantity", materialCode).
+		Where(heldLocationCondition, false, model.LocationTypeQuarantine).
+		Where(unexpiredCondition, today.Format("2006-01-02")).
+		Where("NOT EXISTS (SELECT 1 FROM replenishment_rules WHERE replenishment_rules.material_code = stocks.material_code AND replenishment_rules.location_code = stocks.location_code)").
+		Order("location_code, lot_number").
+		Find(&stocks).Error
+	return stocks, err
+}
+
+// CreateTask 新增补货任务
+func (r *replenishmentRepository) CreateTask(tx *gorm.DB, task *model.ReplenishmentTask) error {
+	return tx.Create(task).Error
+}
+
+// GetTaskForUpdate 以排他锁读取补货任务
+func (r *replenishmentRepository) GetTaskForUpdate(tx *gorm.DB, id uint) (*model.ReplenishmentTask, error) {
+	var task model.ReplenishmentTask
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&task, id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &task, nil
+}
+
+// UpdateTask 保存补货任务的状态字段
+func (r *replenishmentRepository) UpdateTask(tx *gorm.DB, task *model.ReplenishmentTask) error {
+	return tx.Model(task).
+		Select("status", "confirmed_by", "confirmed_at", "cancelled_by", "cancelled_at", "cancel_reason", "updated_at").
+		Updates(task).Error
+}
+
+// ListTasks 查询补货任务，未完成的任务按创建顺序排列在前
+func (r *replenishmentRepository) ListTasks(filter ReplenishmentTaskFilter) ([]model.ReplenishmentTask, error) {
+	query := r.db.Model(&model.ReplenishmentTask{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.MaterialCode != "" {
+		query = query.Where("material_code = ?", filter.MaterialCode)
+	}
+	if filter.TargetLocation != "" {
+		query = query.Where("target_location = ?", filter.TargetLocation)
+	}
+	if filter.Trigger != "" {
+		query = query.Where("trigger = ?", filter.Trigger)
+	}
+
+	var tasks []model.ReplenishmentTask
+	err := query.Order("CASE WHEN status = 'open' THEN 0 ELSE 1 END, id").Find(&tasks).Error
+	return tasks, err
+}
+
+// BeginTransaction 开启新的数据库事务
+func (r *replenishmentRepository) BeginTransaction() *gorm.DB {
+	return r.db.Begin()
+}
+
+// CommitTransaction 提交当前事务
+func (r *replenishmentRepository) CommitTransaction(tx *gorm.DB) error {
+	return tx.Commit().Error
+}
+
+// RollbackTransaction 回滚当前事务
+func (r *replenishmentRepository) RollbackTransaction(tx *gorm.DB) error {
+	return tx.Rollback().Error
+}
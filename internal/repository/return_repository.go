@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"wms/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReturnOrderFilter 表示退货单据的查询条件，零值字段表示不过滤
+type ReturnOrderFilter struct {
+	Status       string
+	OrderID      uint
+	CustomerCode string
+}
+
+// ReturnRepository 定义退货授权与退货收货记录的数据访问接口
+type ReturnRepository interface {
+	// Create 在事务中创建退货单据及其物料行
+	Create(tx *gorm.DB, order *model.ReturnOrder) error
+
+	// GetByID 按 ID 查询退货单据（含物料行），不存在时返回 nil
+	GetByID(id uint) (*model.ReturnOrder, error)
+
+	// GetByRMANo 在事务中按 RMA 号查询退货单据（不含物料行），不存在时返回 nil
+	GetByRMANo(tx *gorm.DB, rmaNo string) (*model.ReturnOrder, error)
+
+	// List 按过滤条件查询退货单据（含物料行）
+	List(filter ReturnOrderFilter) ([]model.ReturnOrder, error)
+
+	// GetForUpdate 在事务中锁定退货单据（含物料行），不存在时返回 nil
+	GetForUpdate(tx *gorm.DB, id uint) (*model.ReturnOrder, error)
+
+	// AuthorizedQuantities 在事务中汇总出库单据各行已授权退货的数量（不含已取消的 RMA），按出库单据行 ID 索引
+	AuthorizedQuantities(tx *gorm.DB, orderID uint) (map[uint]int, error)
+
+	// UpdateStatus 在事务中保存退货单据的状态与取消信息（不包括物料行）
+	UpdateStatus(tx *gorm.DB, order *model.ReturnOrder) error
+
+	// UpdateLine 在事务中保存物料行的收货数量与状态
+	UpdateLine(tx *gorm.DB, line *model.ReturnOrderLine) error
+
+	// CreateReceipt 在事务中创建退货收货记录
+	CreateReceipt(tx *gorm.DB, receipt *model.ReturnReceipt) error
+
+	// ListReceipts 查询退货单据的全部收货记录
+	ListReceipts(returnID uint) ([]model.ReturnReceipt, error)
+
+	// BeginTransaction 开启新的数据库事务
+	BeginTransaction() *gorm.DB
+
+	// CommitTransaction 提交当前事务
+	CommitTransaction(tx *gorm.DB) error
+
+	// RollbackTransaction 回滚当前事务
+	RollbackTransaction(tx *gorm.DB) error
+}
+
+// returnRepository 是 ReturnRepository 的具体实现
+type returnRepository struct {
+	db *gorm.DB
+}
+
+// NewReturnRepository 创建新的 ReturnRepository 实例
+func NewReturnRepository(db *gorm.DB) ReturnRepository {
+	return &returnRepository{
+		db: db,
+	}
+}
+
+// Create 创建退货单据，物料行随主记录一并写入
+func (r *returnRepository) Create(tx *gorm.DB, order *model.ReturnOrder) error {
+	return tx.Create(order).Error
+}
+
+// GetByID 按 ID 查询退货单据
+func (r *returnRepository) GetByID(id uint) (*model.ReturnOrder, error) {
+	return r.find(r.db, id)
+}
+
+// GetByRMANo 按 RMA 号查询退货单据
+func (r *returnRepository) GetByRMANo(tx *gorm.DB, rmaNo string) (*model.ReturnOrder, error) {
+	var order model.ReturnOrder
+	err := tx.Where("rma_no = ?", rmaNo).First(&order).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &order, nil
+}
+
+// List 查询退货单据，按创建时间倒序
+func (r *returnRepository) List(filter ReturnOrderFilter) ([]model.ReturnOrder, error) {
+	query := r.db.Model(&model.ReturnOrder{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.OrderID != 0 {
+		query = query.Where("order_id = ?", filter.OrderID)
+	}
+	if filter.CustomerCode != "" {
+		query = query.Where("customer_code = ?", filter.CustomerCode)
+	}
+
+	var orders []model.ReturnOrder
+	err := query.Preload("Lines", orderLinesByNo).Order("id DESC").Find(&orders).Error
+	return orders, err
+}
+
+// GetForUpdate 以排他锁读取退货单据
+func (r *returnRepository) GetForUpdate(tx *gorm.DB, id uint) (*model.ReturnOrder, error) {
+	return r.find(tx.Clauses(clause.Locking{Strength: "UPDATE"}), id)
+}
+
+// AuthorizedQuantities 汇总出库单据各行在未取消 RMA 中的授权数量
+func (r *returnRepository) AuthorizedQuantities(tx *gorm.DB, orderID uint) (map[uint]int, error) {
+	var rows []struct {
+		OrderLineID uint
+		Quantity    int
+	}
+	err := tx.Model(&model.ReturnOrderLine{}).
+		Select("return_order_lines.order_line_id, SUM(return_order_lines.authorized_quantity) AS quantity").
+		Joins("JOIN return_orders ON return_orders.id = return_order_lines.return_id").
+		Where("return_orders.order_id = ? AND return_orders.status <> ?", orderID, model.ReturnStatusCancelled).
+		Group("return_order_lines.order_line_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	quantities := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		quantities[row.OrderLineID] = row.Quantity
+	}
+	return quantities, nil
+}
+
+// UpdateStatus 保存退货单据状态字段，不级联更新物料行
+func (r *returnRepository) UpdateStatus(tx *gorm.DB, order *model.ReturnOrder) error {
+	return tx.Model(order).Select("status", "cancelled_by", "cancelled_at", "cancel_reason", "updated_at").Updates(order).Error
+}
+
+// UpdateLine 保存物料行的收货数量与状态
+func (r *returnRepository) UpdateLine(tx *gorm.DB, line *model.ReturnOrderLine) error {
+	return tx.Model(line).Select("received_quantity", "status", "updated_at").Updates(line).Error
+}
+
+// CreateReceipt 新增退货收货记录
+func (r *returnRepository) CreateReceipt(tx *gorm.DB, receipt *model.ReturnReceipt) error {
+	return tx.Create(receipt).Error
+}
+
+// ListReceipts 查询退货收货记录，按收货顺序排列
+func (r *returnRepository) ListReceipts(returnID uint) ([]model.ReturnReceipt, error) {
+	var receipts []model.ReturnReceipt
+	err := r.db.Where("return_id = ?", returnID).Order("id").Find(&receipts).Error
+	return receipts, err
+}
+
+// BeginTransaction 开启新的数据库事务
+func (r *returnRepository) BeginTransaction() *gorm.DB {
+	return r.db.Begin()
+}
+
+// CommitTransaction 提交当前事务
+func (r *returnRepository) CommitTransaction(tx *gorm.DB) error {
+	return tx.Commit().Error
+}
+
+// RollbackTransaction 回滚当前事务
+func (r *returnRepository) RollbackTransaction(tx *gorm.DB) error {
+	return tx.Rollback().Error
+}
+
+// find 查询退货单据并预加载物料行
+// 锁只作用于主表查询，物料行由单据锁间接保护
+func (r *returnRepository) find(query *gorm.DB, id uint) (*model.ReturnOrder, error) {
+	var order model.ReturnOrder
+	err := query.Preload("Lines", orderLinesByNo).First(&order, id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &order, nil
+}
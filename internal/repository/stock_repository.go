@@ -0,0 +1,442 @@
+package repository
+
+import (
+	"time"
+	"wms/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StockFilter 表示库存余额的查询条件，零值字段表示不过滤
+type StockFilter struct {
+	MaterialCode string
+	LocationCode string
+	LotNumber    string
+}
+
+// MovementFilter 表示库存流水的查询条件
+// 零值字段表示不过滤，结果按 ID 倒序返回
+type MovementFilter struct {
+	MaterialCode string
+	LocationCode string
+	LotNumber    string
+	MovementType string
+	From         time.Time
+	To           time.Time
+	BeforeID     uint
+	Limit        int
+}
+
+// SerialFilter 表示序列号的查询条件，零值字段表示不过滤，结果按物料、序列号排序
+type SerialFilter struct {
+	MaterialCode string
+	SerialNumber string
+	LocationCode string
+	LotNumber    string
+	Status       string
+}
+
+// LedgerMismatch 表示库存数量与流水合计不一致的 (物料, 库位, 批次)
+type LedgerMismatch struct {
+	MaterialCode   string `json:"material_code"`
+	LocationCode   string `json:"location_code"`
+	LotNumber      string `json:"lot_number,omitempty"`
+	StockQuantity  int    `json:"stock_quantity"`
+	LedgerQuantity int    `json:"ledger_quantity"`
+}
+
+// LotMovementSummary 表示某批次按物料、流水类型与关联单据汇总的流水数量
+type LotMovementSummary struct {
+	MaterialCode  string    `json:"material_code"`
+	MovementType  string    `json:"movement_type"`
+	ReferenceType string    `json:"reference_type"`
+	ReferenceID   string    `json:"reference_id"`
+	Quantity      int       `json:"quantity"`
+	FirstMovedAt  time.Time `json:"first_moved_at"`
+	LastMovedAt   time.Time `json:"last_moved_at"`
+}
+
+// StockRepository 定义库存及库存流水的数据访问接口
+type StockRepository interface {
+	// GetStockByMaterialAndLocation 查询指定库位、物料与批次的库存信息
+	GetStockByMaterialAndLocation(tx *gorm.DB, materialCode, locationCode, lotNumber string) (*model.Stock, error)
+
+	// GetStockForUpdate 在事务中锁定指定库位、物料与批次的库存行（SELECT ... FOR UPDATE）
+	// 库存不存在时先以 ON CONFLICT DO NOTHING 插入数量为 0 的记录再加锁，
+	// 从而避免并发首次写入在唯一索引上冲突，并保证同一库存行的变更串行执行
+	GetStockForUpdate(tx *gorm.DB, materialCode, locationCode, lotNumber string) (*model.Stock, error)
+
+	// GetMaterial 在事务中查询库存所属物料的主数据，物料未登记时返回 nil
+	GetMaterial(tx *gorm.DB, materialCode string) (*model.Material, error)
+
+	// GetLocation 在事务中查询库存所在库位的主数据，库位未登记时返回 nil
+	GetLocation(tx *gorm.DB, locationCode string) (*model.Location, error)
+
+	// SaveStock 保存库存记录（新增或更新）
+	SaveStock(tx *gorm.DB, stock *model.Stock) error
+
+	// CreateMovement 在事务中追加一条库存流水
+	CreateMovement(tx *gorm.DB, movement *model.StockMovement) error
+
+	// ListMovements 按过滤条件查询库存流水
+	ListMovements(filter MovementFilter) ([]model.StockMovement, error)
+
+	// FindLedgerMismatches 查询库存数量与流水合计不一致的记录
+	FindLedgerMismatches() ([]LedgerMismatch, error)
+
+	// FindStocksWithoutMovements 查询数量非零但没有任何流水的库存记录
+	FindStocksWithoutMovements(tx *gorm.DB) ([]model.Stock, error)
+
+	// ListStocksInScope 在事务中查询数量大于 0 的库存，locationCodes / materialCodes 非空时按其过滤
+	ListStocksInScope(tx *gorm.DB, locationCodes, materialCodes []string) ([]model.Stock, error)
+
+	// GetBalanceAt 在事务中根据流水查询指定批次在指定时点的库存余额，该时点前没有流水时返回 0
+	GetBalanceAt(tx *gorm.DB, materialCode, locationCode, lotNumber string, at time.Time) (int, error)
+
+	// ListStocks 按过滤条件查询在库或已预留数量大于 0 的库存
+	ListStocks(filter StockFilter) ([]model.Stock, error)
+
+	// ListExpiringStocks 查询有效期不晚于 cutoff 所在日期且在库数量大于 0 的库存（含已过期的库存），
+	// 按库位、有效期、物料、批次排序
+	ListExpiringStocks(cutoff time.Time) ([]model.Stock, error)
+
+	// SummarizeLotMovements 按物料、流水类型与关联单据汇总指定批次的流水，materialCode 为空时不过滤物料
+	SummarizeLotMovements(materialCode, lotNumber string) ([]LotMovementSummary, error)
+
+	// GetSerialsForUpdate 在事务中锁定物料的指定序列号，未登记的序列号不在结果中
+	GetSerialsForUpdate(tx *gorm.DB, materialCode string, serialNumbers []string) ([]model.SerialNumber, error)
+
+	// ListSerialsForUpdate 在事务中锁定指定库位与批次上在库的序列号，按收货时间与 ID 排序；
+	// outboundOrderID 非 0 时只返回拣货给该出库单据的序列号，limit 大于 0 时最多返回 limit 个
+	ListSerialsForUpdate(tx *gorm.DB, materialCode, locationCode, lotNumber string, outboundOrderID uint, limit int) ([]model.SerialNumber, error)
+
+	// SaveSerial 在事务中保存序列号（新增或更新）
+	SaveSerial(tx *gorm.DB, serial *model.SerialNumber) error
+
+	// ListSerials 按过滤条件查询序列号
+	ListSerials(filter SerialFilter) ([]model.SerialNumber, error)
+
+	// ListStockedLocations 查询有库存（数量大于 0）的库位编码，materialCodes 非空时仅统计这些物料
+	ListStockedLocations(materialCodes []string) ([]string, error)
+
+	// BeginTransaction 开启新的数据库事务
+	BeginTransaction() *gorm.DB
+
+	// CommitTransaction 提交当前事务
+	CommitTransaction(tx *gorm.DB) error
+
+	// RollbackTransaction 回滚当前事务
+	RollbackTransaction(tx *gorm.DB) error
+}
+
+// stockRepository 是 StockRepository 的具体实现
+type stockRepository struct {
+	db *gorm.DB
+}
+
+// NewStockRepository 创建新的 StockRepository 实例
+func NewStockRepository(db *gorm.DB) StockRepository {
+	return &stockRepository{
+		db: db,
+	}
+}
+
+// GetStockByMaterialAndLocation 查询指定库位、物料与批次的库存
+// 若库存不存在则返回 nil（不视为错误）
+func (r *stockRepository) GetStockByMaterialAndLocation(tx *gorm.DB, materialCode, locationCode, lotNumber string) (*model.Stock, error) {
+	var stock model.Stock
+	err := tx.Where("material_code = ? AND location_code = ? AND lot_number = ?", materialCode, locationCode, lotNumber).First(&stock).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &stock, nil
+}
+
+// GetStockForUpdate 插入缺失的库存行并以行锁读取
+func (r *stockRepository) GetStockForUpdate(tx *gorm.DB, materialCode, locationCode, lotNumber string) (*model.Stock, error) {
+	placeholder := model.Stock{
+		MaterialCode: materialCode,
+		LocationCode: locationCode,
+		LotNumber:    lotNumber,
+	}
+	err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "material_code"}, {Name: "location_code"}, {Name: "lot_number"}},
+		DoNothing: true,
+	}).Create(&placeholder).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var stock model.Stock
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("material_code = ? AND location_code = ? AND lot_number = ?", materialCode, locationCode, lotNumber).
+		First(&stock).Error
+	if err != nil {
+		return nil, err
+	}
+	return &stock, nil
+}
+
+// GetMaterial 按编码查询物料主数据
+// 若物料不存在则返回 nil（不视为错误）
+func (r *stockRepository) GetMaterial(tx *gorm.DB, materialCode string) (*model.Material, error) {
+	var material model.Material
+	err := tx.Where("code = ?", materialCode).First(&material).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &material, nil
+}
+
+// GetLocation 按编码查询库位主数据
+// 若库位不存在则返回 nil（不视为错误）
+func (r *stockRepository) GetLocation(tx *gorm.DB, locationCode string) (*model.Location, error) {
+	var location model.Location
+	err := tx.Where("code = ?", locationCode).First(&location).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &location, nil
+}
+
+// SaveStock 保存库存记录
+func (r *stockRepository) SaveStock(tx *gorm.DB, stock *model.Stock) error {
+	return tx.Save(stock).Error
+}
+
+// CreateMovement 追加库存流水
+func (r *stockRepository) CreateMovement(tx *gorm.DB, movement *model.StockMovement) error {
+	return tx.Create(movement).Error
+}
+
+// ListMovements 按过滤条件查询库存流水
+func (r *stockRepository) ListMovements(filter MovementFilter) ([]model.StockMovement, error) {
+	query := r.db.Model(&model.StockMovement{})
+
+	if filter.MaterialCode != "" {
+		query = query.Where("material_code = ?", filter.MaterialCode)
+	}
+	if filter.LocationCode != "" {
+		query = query.Where("location_code = ?", filter.LocationCode)
+	}
+	if filter.LotNumber != "" {
+		query = query.Where("lot_number = ?", filter.LotNumber)
+	}
+	if filter.MovementType != "" {
+		query = query.Where("movement_type = ?", filter.MovementType)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("moved_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("moved_at < ?", filter.To)
+	}
+	if filter.BeforeID > 0 {
+		query = query.Where("id < ?", filter.BeforeID)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var movements []model.StockMovement
+	err := query.Order("id DESC").Find(&movements).Error
+	return movements, err
+}
+
+// FindLedgerMismatches 对比 stocks.quantity 与流水 delta 合计
+// 使用 FULL OUTER JOIN 同时发现"有库存无流水"与"有流水无库存"两类不一致
+func (r *stockRepository) FindLedgerMismatches() ([]LedgerMismatch, error) {
+	var mismatches []LedgerMismatch
+	err := r.db.Raw(`
+		SELECT
+			COALESCE(s.material_code, m.material_code) AS material_code,
+			COALESCE(s.location_code, m.location_code) AS location_code,
+			COALESCE(s.lot_number, m.lot_number) AS lot_number,
+			COALESCE(s.quantity, 0) AS stock_quantity,
+			COALESCE(m.total, 0) AS ledger_quantity
+		FROM stocks s
+		FULL OUTER JOIN (
+			SELECT material_code, location_code, lot_number, SUM(delta) AS total
+			FROM stock_movements
+			GROUP BY material_code, location_code, lot_number
+		) m ON s.material_code = m.material_code AND s.location_code = m.location_code AND s.lot_number = m.lot_number
+		WHERE COALESCE(s.quantity, 0) <> COALESCE(m.total, 0)
+		ORDER BY 1, 2, 3`).Scan(&mismatches).Error
+	return mismatches, err
+}
+
+// FindStocksWithoutMovements 查询数量非零但没有任何流水的库存记录
+func (r *stockRepository) FindStocksWithoutMovements(tx *gorm.DB) ([]model.Stock, error) {
+	var stocks []model.Stock
+	err := tx.Where(`quantity <> 0 AND NOT EXISTS (
+		SELECT 1 FROM stock_movements m
+		WHERE m.material_code = stocks.material_code AND m.location_code = stocks.location_code
+			AND m.lot_number = stocks.lot_number)`).
+		Order("id").Find(&stocks).Error
+	return stocks, err
+}
+
+// ListStocksInScope 查询范围内数量大于 0 的库存，按库位、物料、批次排序
+func (r *stockRepository) ListStocksInScope(tx *gorm.DB, locationCodes, materialCodes []string) ([]model.Stock, error) {
+	query := tx.Where("quantity > 0")
+	if len(locationCodes) > 0 {
+		query = query.Where("location_code IN ?", locationCodes)
+	}
+	if len(materialCodes) > 0 {
+		query = query.Where("material_code IN ?", materialCodes)
+	}
+
+	var stocks []model.Stock
+	err := query.Order("location_code, material_code, lot_number").Find(&stocks).Error
+	return stocks, err
+}
+
+// GetBalanceAt 取指定批次在指定时点之前最后一条流水的变动后余额
+func (r *stockRepository) GetBalanceAt(tx *gorm.DB, materialCode, locationCode, lotNumber string, at time.Time) (int, error) {
+	var balances []int
+	err := tx.Model(&model.StockMovement{}).
+		Where("material_code = ? AND location_code = ? AND lot_number = ? AND moved_at <= ?", materialCode, locationCode, lotNumber, at).
+		Order("id DESC").Limit(1).Pluck("balance_after", &balances).Error
+	if err != nil || len(balances) == 0 {
+		return 0, err
+	}
+	return balances[0], nil
+}
+
+// ListStocks 查询库存，按物料、库位、批次排序
+func (r *stockRepository) ListStocks(filter StockFilter) ([]model.Stock, error) {
+	query := r.db.Where("quantity > 0 OR reserved_quantity > 0")
+	if filter.MaterialCode != "" {
+		query = query.Where("material_code = ?", filter.MaterialCode)
+	}
+	if filter.LocationCode != "" {
+		query = query.Where("location_code = ?", filter.LocationCode)
+	}
+	if filter.LotNumber != "" {
+		query = query.Where("lot_number = ?", filter.LotNumber)
+	}
+
+	var stocks []model.Stock
+	err := query.Order("material_code, location_code, lot_number").Find(&stocks).Error
+	return stocks, err
+}
+
+// ListExpiringStocks 查询临期与已过期的库存
+func (r *stockRepository) ListExpiringStocks(cutoff time.Time) ([]model.Stock, error) {
+	var stocks []model.Stock
+	err := r.db.Where("quantity > 0 AND expiry_date IS NOT NULL AND expiry_date <= ?", cutoff.Format("2006-01-02")).
+		Order("location_code, expiry_date, material_code, lot_number").
+		Find(&stocks).Error
+	return stocks, err
+}
+
+// SummarizeLotMovements 按物料、流水类型与关联单据汇总批次流水，按首次发生时间排序
+func (r *stockRepository) SummarizeLotMovements(materialCode, lotNumber string) ([]LotMovementSummary, error) {
+	query := r.db.Model(&model.StockMovement{}).
+		Select("material_code, movement_type, reference_type, reference_id, SUM(delta) AS quantity, "+
+			"MIN(moved_at) AS first_moved_at, MAX(moved_at) AS last_moved_at").
+		Where("lot_number = ?", lotNumber)
+	if materialCode != "" {
+		query = query.Where("material_code = ?", materialCode)
+	}
+
+	var summaries []LotMovementSummary
+	err := query.Group("material_code, movement_type, reference_type, reference_id").
+		Order("first_moved_at, material_code").Scan(&summaries).Error
+	return summaries, err
+}
+
+// GetSerialsForUpdate 以行锁读取物料的指定序列号
+func (r *stockRepository) GetSerialsForUpdate(tx *gorm.DB, materialCode string, serialNumbers []string) ([]model.SerialNumber, error) {
+	var serials []model.SerialNumber
+	if len(serialNumbers) == 0 {
+		return serials, nil
+	}
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("material_code = ? AND serial_number IN ?", materialCode, serialNumbers).
+		Order("serial_number").Find(&serials).Error
+	return serials, err
+}
+
+// ListSerialsForUpdate 以行锁读取库位与批次上在库的序列号
+func (r *stockRepository) ListSerialsForUpdate(tx *gorm.DB, materialCode, locationCode, lotNumber string, outboundOrderID uint, limit int) ([]model.SerialNumber, error) {
+	query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("material_code = ? AND location_code = ? AND lot_number = ? AND status = ?",
+			materialCode, locationCode, lotNumber, model.SerialStatusInStock)
+	if outboundOrderID != 0 {
+		query = query.Where("outbound_order_id = ?", outboundOrderID)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var serials []model.SerialNumber
+	err := query.Order("received_at, id").Find(&serials).Error
+	return serials, err
+}
+
+// SaveSerial 保存序列号
+func (r *stockRepository) SaveSerial(tx *gorm.DB, serial *model.SerialNumber) error {
+	return tx.Save(serial).Error
+}
+
+// ListSerials 按过滤条件查询序列号
+func (r *stockRepository) ListSerials(filter SerialFilter) ([]model.SerialNumber, error) {
+	query := r.db.Model(&model.SerialNumber{})
+	if filter.MaterialCode != "" {
+		query = query.Where("material_code = ?", filter.MaterialCode)
+	}
+	if filter.SerialNumber != "" {
+		query = query.Where("serial_number = ?", filter.SerialNumber)
+	}
+	if filter.LocationCode != "" {
+		query = query.Where("location_code = ?", filter.LocationCode)
+	}
+	if filter.LotNumber != "" {
+		query = query.Where("lot_number = ?", filter.LotNumber)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var serials []model.SerialNumber
+	err := query.Order("material_code, serial_number").Find(&serials).Error
+	return serials, err
+}
+
+// ListStockedLocations 查询有库存的库位编码（去重、升序）
+func (r *stockRepository) ListStockedLocations(materialCodes []string) ([]string, error) {
+	query := r.db.Model(&model.Stock{}).Where("quantity > 0")
+	if len(materialCodes) > 0 {
+		query = query.Where("material_code IN ?", materialCodes)
+	}
+
+	var locations []string
+	err := query.Distinct().Order("location_code").Pluck("location_code", &locations).Error
+	return locations, err
+}
+
+// BeginTransaction 开启新的数据库事务
+func (r *stockRepository) BeginTransaction() *gorm.DB {
+	return r.db.Begin()
+}
+
+// CommitTransaction 提交当前事务
+func (r *stockRepository) CommitTransaction(tx *gorm.DB) error {
+	return tx.Commit().Error
+}
+
+// RollbackTransaction 回滚当前事务
+func (r *stockRepository) RollbackTransaction(tx *gorm.DB) error {
+	return tx.Rollback().Error
+}
@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"wms/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StockTakeRepository 定义盘点单的数据访问接口
+type StockTakeRepository interface {
+	// Create 创建盘点单及其范围与盘点人
+	Create(stockTake *model.StockTake) error
+
+	// GetByID 按 ID 查询盘点单（含范围与盘点人），不存在时返回 nil
+	GetByID(id uint) (*model.StockTake, error)
+
+	// List 查询盘点单列表，status 为空时返回全部
+	List(status string) ([]model.StockTake, error)
+
+	// GetForUpdate 在事务中锁定盘点单（SELECT ... FOR UPDATE），不存在时返回 nil
+	// 用于状态流转，与上传盘点时持有的共享锁互斥
+	GetForUpdate(tx *gorm.DB, id uint) (*model.StockTake, error)
+
+	// GetForShare 在事务中以共享锁读取盘点单（SELECT ... FOR SHARE），不存在时返回 nil
+	// 用于上传盘点，保证盘点期间盘点单状态不被并发修改
+	GetForShare(tx *gorm.DB, id uint) (*model.StockTake, error)
+
+	// UpdateStatus 在事务中保存盘点单状态及时间戳字段（不包括范围与盘点人）
+	UpdateStatus(tx *gorm.DB, stockTake *model.StockTake) error
+
+	// CreateCountTasks 在事务中批量创建盘点任务及其物料行
+	CreateCountTasks(tx *gorm.DB, tasks []model.CountTask) error
+
+	// ListCountTasks 查询盘点单的盘点任务（含物料行），按盘点人与行走顺序排列，assignedTo 非空时只返回分配给该盘点人的任务
+	ListCountTasks(stockTakeID uint, assignedTo string) ([]model.CountTask, error)
+
+	// GetCountTaskForUpdate 在事务中锁定盘点单内指定库位的盘点任务（含物料行），不存在时返回 nil
+	GetCountTaskForUpdate(tx *gorm.DB, stockTakeID uint, locationCode string) (*model.CountTask, error)
+
+	// SaveCountTaskLine 在事务中新增或更新盘点任务物料行
+	SaveCountTaskLine(tx *gorm.DB, line *model.CountTaskLine) error
+
+	// UpdateCountTaskStatus 在事务中保存盘点任务的状态与完成时间
+	UpdateCountTaskStatus(tx *gorm.DB, task *model.CountTask) error
+
+	// BeginTransaction 开启新的数据库事务
+	BeginTransaction() *gorm.DB
+
+	// CommitTransaction 提交当前事务
+	CommitTransaction(tx *gorm.DB) error
+
+	// RollbackTransaction 回滚当前事务
+	RollbackTransaction(tx *gorm.DB) error
+}
+
+// stockTakeRepository 是 StockTakeRepository 的具体实现
+type stockTakeRepository struct {
+	db *gorm.DB
+}
+
+// NewStockTakeRepository 创建新的 StockTakeRepository 实例
+func NewStockTakeRepository(db *gorm.DB) StockTakeRepository {
+	return &stockTakeRepository{
+		db: db,
+	}
+}
+
+// Create 创建盘点单，关联的范围与盘点人随主记录一并写入
+func (r *stockTakeRepository) Create(stockTake *model.StockTake) error {
+	return r.db.Create(stockTake).Error
+}
+
+// GetByID 按 ID 查询盘点单
+// 若盘点单不存在则返回 nil（不视为错误）
+func (r *stockTakeRepository) GetByID(id uint) (*model.StockTake, error) {
+	return r.find(r.db, id)
+}
+
+// List 查询盘点单列表，按创建时间倒序
+func (r *stockTakeRepository) List(status string) ([]model.StockTake, error) {
+	query := r.db.Model(&model.StockTake{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var stockTakes []model.StockTake
+	err := query.Preload("Locations").Preload("Materials").Preload("Checkers").
+		Order("id DESC").Find(&stockTakes).Error
+	return stockTakes, err
+}
+
+// GetForUpdate 以排他锁读取盘点单
+func (r *stockTakeRepository) GetForUpdate(tx *gorm.DB, id uint) (*model.StockTake, error) {
+	return r.find(tx.Clauses(clause.Locking{Strength: "UPDATE"}), id)
+}
+
+// GetForShare 以共享锁读取盘点单
+func (r *stockTakeRepository) GetForShare(tx *gorm.DB, id uint) (*model.StockTake, error) {
+	return r.find(tx.Clauses(clause.Locking{Strength: "SHARE"}), id)
+}
+
+// UpdateStatus 保存盘点单状态字段，不级联更新关联记录
+func (r *stockTakeRepository) UpdateStatus(tx *gorm.DB, stockTake *model.StockTake) error {
+	return tx.Model(stockTake).Select(
+		"status", "started_at", "counting_closed_at", "reconciled_at", "posted_at", "posted_by", "updated_at",
+	).Updates(stockTake).Error
+}
+
+// find 查询盘点单并预加载范围与盘点人
+// 锁只作用于主表查询，预加载的关联表不加锁
+func (r *stockTakeRepository) find(query *gorm.DB, id uint) (*model.StockTake, error) {
+	var stockTake model.StockTake
+	err := query.Preload("Locations").Preload("Materials").Preload("Checkers").First(&stockTake, id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &stockTake, nil
+}
+
+// CreateCountTasks 批量创建盘点任务，物料行随任务一并写入
+func (r *stockTakeRepository) CreateCountTasks(tx *gorm.DB, tasks []model.CountTask) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	return tx.CreateInBatches(tasks, 100).Error
+}
+
+// ListCountTasks 查询盘点单的盘点任务，按盘点人与行走顺序排序
+func (r *stockTakeRepository) ListCountTasks(stockTakeID uint, assignedTo string) ([]model.CountTask, error) {
+	query := r.db.Where("stock_take_id = ?", stockTakeID)
+	if assignedTo != "" {
+		query = query.Where("assigned_to = ?", assignedTo)
+	}
+
+	var tasks []model.CountTask
+	err := query.Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("material_code")
+	}).Order("assigned_to, sequence, location_code").Find(&tasks).Error
+	return tasks, err
+}
+
+// GetCountTaskForUpdate 以排他锁读取盘点任务
+// 若任务不存在则返回 nil（不视为错误）
+func (r *stockTakeRepository) GetCountTaskForUpdate(tx *gorm.DB, stockTakeID uint, locationCode string) (*model.CountTask, error) {
+	var task model.CountTask
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Lines").
+		Where("stock_take_id = ? AND location_code = ?", stockTakeID, locationCode).
+		First(&task).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &task, nil
+}
+
+// SaveCountTaskLine 新增或更新盘点任务物料行
+func (r *stockTakeRepository) SaveCountTaskLine(tx *gorm.DB, line *model.CountTaskLine) error {
+	return tx.Save(line).Error
+}
+
+// UpdateCountTaskStatus 保存盘点任务状态字段，不级联更新物料行
+func (r *stockTakeRepository) UpdateCountTaskStatus(tx *gorm.DB, task *model.CountTask) error {
+	return tx.Model(task).Select("status", "completed_at", "updated_at").Updates(task).Error
+}
+
+// BeginTransaction 开启新的数据库事务
+func (r *stockTakeRepository) BeginTransaction() *gorm.DB {
+	return r.db.Begin()
+}
+
+// CommitTransaction 提交当前事务
+func (r *stockTakeRepository) CommitTransaction(tx *gorm.DB) error {
+	return tx.Commit().Error
+}
+
+// RollbackTransaction 回滚当前事务
+func (r *stockTakeRepository) RollbackTransaction(tx *gorm.DB) error {
+	return tx.Rollback().Error
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"wms/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// TransferRepository 定义移库单的数据访问接口
+type TransferRepository interface {
+	// Create 在事务中创建移库单，明细行随主记录一并写入
+	Create(tx *gorm.DB, transfer *model.StockTransfer) error
+
+	// GetByID 按 ID 查询移库单（含明细行），不存在时返回 nil
+	GetByID(id uint) (*model.StockTransfer, error)
+
+	// BeginTransaction 开启新的数据库事务
+	BeginTransaction() *gorm.DB
+
+	// CommitTransaction 提交当前事务
+	CommitTransaction(tx *gorm.DB) error
+
+	// RollbackTransaction 回滚当前事务
+	RollbackTransaction(tx *gorm.DB) error
+}
+
+// transferRepository 是 TransferRepository 的具体实现
+type transferRepository struct {
+	db *gorm.DB
+}
+
+// NewTransferRepository 创建新的 TransferRepository 实例
+func NewTransferRepository(db *gorm.DB) TransferRepository {
+	return &transferRepository{
+		db: db,
+	}
+}
+
+// Create 创建移库单
+func (r *transferRepository) Create(tx *gorm.DB, transfer *model.StockTransfer) error {
+	return tx.Create(transfer).Error
+}
+
+// GetByID 按 ID 查询移库单，明细行按行号排序
+// 若移库单不存在则返回 nil（不视为错误）
+func (r *transferRepository) GetByID(id uint) (*model.StockTransfer, error) {
+	var transfer model.StockTransfer
+	err := r.db.Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("line_no")
+	}).First(&transfer, id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// BeginTransaction 开启新的数据库事务
+func (r *transferRepository) BeginTransaction() *gorm.DB {
+	return r.db.Begin()
+}
+
+// CommitTransaction 提交当前事务
+func (r *transferRepository) CommitTransaction(tx *gorm.DB) error {
+	return tx.Commit().Error
+}
+
+// RollbackTransaction 回滚当前事务
+func (r *transferRepository) RollbackTransaction(tx *gorm.DB) error {
+	return tx.Rollback().Error
+}
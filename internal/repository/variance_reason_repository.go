@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"wms/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// VarianceReasonRepository 定义盘点差异原因代码的数据访问接口
+type VarianceReasonRepository interface {
+	// GetByCode 在事务中按代码查询原因，不存在时返回 nil
+	GetByCode(tx *gorm.DB, code string) (*model.VarianceReason, error)
+
+	// List 查询原因代码，activeOnly 为 true 时只返回启用的原因
+	List(activeOnly bool) ([]model.VarianceReason, error)
+
+	// Upsert 按代码新增或更新原因
+	Upsert(reason *model.VarianceReason) error
+
+	// CreateMissing 批量创建尚不存在的原因代码，已存在的代码保持不变
+	CreateMissing(reasons []model.VarianceReason) error
+}
+
+// varianceReasonRepository 是 VarianceReasonRepository 的具体实现
+type varianceReasonRepository struct {
+	db *gorm.DB
+}
+
+// NewVarianceReasonRepository 创建新的 VarianceReasonRepository 实例
+func NewVarianceReasonRepository(db *gorm.DB) VarianceReasonRepository {
+	return &varianceReasonRepository{
+		db: db,
+	}
+}
+
+// GetByCode 按代码查询原因
+func (r *varianceReasonRepository) GetByCode(tx *gorm.DB, code string) (*model.VarianceReason, error) {
+	var reason model.VarianceReason
+	err := tx.Where("code = ?", code).First(&reason).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &reason, nil
+}
+
+// List 查询原因代码，按代码排序
+func (r *varianceReasonRepository) List(activeOnly bool) ([]model.VarianceReason, error) {
+	query := r.db.Model(&model.VarianceReason{})
+	if activeOnly {
+		query = query.Where("active = ?", true)
+	}
+
+	var reasons []model.VarianceReason
+	err := query.Order("code").Find(&reasons).Error
+	return reasons, err
+}
+
+// Upsert 按 code 唯一约束新增或更新原因
+func (r *varianceReasonRepository) Upsert(reason *model.VarianceReason) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "code"}},
+		DoUpdates: clause.AssignmentColumns([]string{"description", "posting_category", "direction", "active", "updated_at"}),
+	}).Create(reason).Error
+}
+
+// CreateMissing 批量创建原因代码，与已有代码冲突的行被忽略
+func (r *varianceReasonRepository) CreateMissing(reasons []model.VarianceReason) error {
+	if len(reasons) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "code"}},
+		DoNothing: true,
+	}).Create(&reasons).Error
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"wms/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// VarianceToleranceRepository 定义盘点差异容差配置的数据访问接口
+type VarianceToleranceRepository interface {
+	// FindApplicable 在事务中查询可作用于指定物料与库位的全部容差配置
+	// 包括精确匹配以及物料或库位为空（通配）的配置
+	FindApplicable(tx *gorm.DB, materialCode, locationCode string) ([]model.VarianceTolerance, error)
+
+	// List 查询全部容差配置
+	List() ([]model.VarianceTolerance, error)
+
+	// Upsert 按物料与库位作用范围新增或更新容差配置
+	Upsert(tolerance *model.VarianceTolerance) error
+
+	// Delete 删除指定容差配置，返回是否实际删除了记录
+	Delete(id uint) (bool, error)
+}
+
+// varianceToleranceRepository 是 VarianceToleranceRepository 的具体实现
+type varianceToleranceRepository struct {
+	db *gorm.DB
+}
+
+// NewVarianceToleranceRepository 创建新的 VarianceToleranceRepository 实例
+func NewVarianceToleranceRepository(db *gorm.DB) VarianceToleranceRepository {
+	return &varianceToleranceRepository{
+		db: db,
+	}
+}
+
+// FindApplicable 查询可作用于指定物料与库位的容差配置
+func (r *varianceToleranceRepository) FindApplicable(tx *gorm.DB, materialCode, locationCode string) ([]model.VarianceTolerance, error) {
+	var tolerances []model.VarianceTolerance
+	err := tx.Where("material_code IN (?, '') AND location_code IN (?, '')", materialCode, locationCode).
+		Find(&tolerances).Error
+	return tolerances, err
+}
+
+// List 查询全部容差配置
+func (r *varianceToleranceRepository) List() ([]model.VarianceTolerance, error) {
+	var tolerances []model.VarianceTolerance
+	err := r.db.Order("material_code, location_code").Find(&tolerances).Error
+	return tolerances, err
+}
+
+// Upsert 按 (material_code, location_code) 唯一约束新增或更新容差配置
+func (r *varianceToleranceRepository) Upsert(tolerance *model.VarianceTolerance) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "material_code"}, {Name: "location_code"}},
+		DoUpdates: clause.AssignmentColumns([]string{"absolute_tolerance", "percentage_tolerance", "updated_at"}),
+	}).Create(tolerance).Error
+}
+
+// Delete 删除指定容差配置
+func (r *varianceToleranceRepository) Delete(id uint) (bool, error) {
+	result := r.db.Delete(&model.VarianceTolerance{}, id)
+	return result.RowsAffected > 0, result.Error
+}
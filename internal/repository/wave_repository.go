@@ -0,0 +1,266 @@
+package repository
+
+import (
+	"time"
+	"wms/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WaveFilter 表示拣货波次的查询条件，零值字段表示不过滤
+type WaveFilter struct {
+	Status  string
+	GroupBy string
+}
+
+// WaveCandidateFilter 表示参与组波的出库单据筛选条件，零值字段表示不过滤
+type WaveCandidateFilter struct {
+	Carrier      string
+	CutoffBefore *time.Time
+	OrderIDs     []uint
+}
+
+// PickTaskFilter 表示拣货任务的查询条件，零值字段表示不过滤
+type PickTaskFilter struct {
+	WaveID     uint
+	Status     string
+	Zone       string
+	AssignedTo string
+}
+
+// WaveRepository 定义拣货波次与拣货任务的数据访问接口
+type WaveRepository interface {
+	// ListWaveCandidates 在事务中锁定已分配足量、尚未组波的出库单据（不含物料行），按 ID 排序
+	ListWaveCandidates(tx *gorm.DB, filter WaveCandidateFilter) ([]model.OutboundOrder, error)
+
+	// ListUnwavedAllocations 在事务中查询单据预留中且尚未关联拣货任务的预留记录
+	ListUnwavedAllocations(tx *gorm.DB, orderIDs []uint) ([]model.StockAllocation, error)
+
+	// CreateWave 在事务中创建波次
+	CreateWave(tx *gorm.DB, wave *model.Wave) error
+
+	// GetWave 按 ID 查询波次，不存在时返回 nil
+	GetWave(id uint) (*model.Wave, error)
+
+	// GetWaveForUpdate 在事务中锁定波次，不存在时返回 nil
+	GetWaveForUpdate(tx *gorm.DB, id uint) (*model.Wave, error)
+
+	// UpdateWave 在事务中保存波次的变更
+	UpdateWave(tx *gorm.DB, wave *model.Wave) error
+
+	// ListWaves 按过滤条件查询波次
+	ListWaves(filter WaveFilter) ([]model.Wave, error)
+
+	// CreatePickTask 在事务中创建拣货任务，并将 allocationIDs 对应的预留记录关联到该任务
+	CreatePickTask(tx *gorm.DB, task *model.PickTask, allocationIDs []uint) error
+
+	// GetPickTaskForUpdate 在事务中锁定拣货任务，不存在时返回 nil
+	GetPickTaskForUpdate(tx *gorm.DB, id uint) (*model.PickTask, error)
+
+	// UpdatePickTask 在事务中保存拣货任务的变更
+	UpdatePickTask(tx *gorm.DB, task *model.PickTask) error
+
+	// ListPickTasks 按过滤条件查询拣货任务
+	ListPickTasks(filter PickTaskFilter) ([]model.PickTask, error)
+
+	// ListTaskAllocations 在事务中查询拣货任务关联的预留中记录，按单据、ID 排序
+	ListTaskAllocations(tx *gorm.DB, taskID uint) ([]model.StockAllocation, error)
+
+	// UpdateAllocation 在事务中保存预留记录的拣货数量与状态
+	UpdateAllocation(tx *gorm.DB, allocation *model.StockAllocation) error
+
+	// CountActiveAllocations 在事务中统计出库单据仍在预留中的记录数
+	CountActiveAllocations(tx *gorm.DB, orderID uint) (int64, error)
+
+	// BeginTransaction 开启新的数据库事务
+	BeginTransaction() *gorm.DB
+
+	// CommitTransaction 提交当前事务
+	CommitTransaction(tx *gorm.DB) error
+
+	// RollbackTransaction 回滚当前事务
+	RollbackTransaction(tx *gorm.DB) error
+}
+
+// waveRepository 是 WaveRepository 的具体实现
+type waveRepository struct {
+	db *gorm.DB
+}
+
+// NewWaveRepository 创建新的 WaveRepository 实例
+func NewWaveRepository(db *gorm.DB) WaveRepository {
+	return &waveRepository{
+		db: db,
+	}
+}
+
+// ListWaveCandidates 以排他锁读取待组波的出库单据
+func (r *waveRepository) ListWaveCandidates(tx *gorm.DB, filter WaveCandidateFilter) ([]model.OutboundOrder, error) {
+	query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("status = ?", model.OutboundOrderStatusAllocated)
+	if filter.Carrier != "" {
+		query = query.Where("carrier = ?", filter.Carrier)
+	}
+	if filter.CutoffBefore != nil {
+		query = query.Where("carrier_cutoff_at <= ?", *filter.CutoffBefore)
+	}
+	if len(filter.OrderIDs) > 0 {
+		query = query.Where("id IN ?", filter.OrderIDs)
+	}
+
+	var orders []model.OutboundOrder
+	err := query.Order("id").Find(&orders).Error
+	return orders, err
+}
+
+// ListUnwavedAllocations 查询未关联拣货任务的预留记录，按物料、库位排序
+func (r *waveRepository) ListUnwavedAllocations(tx *gorm.DB, orderIDs []uint) ([]model.StockAllocation, error) {
+	var allocations []model.StockAllocation
+	if len(orderIDs) == 0 {
+		return allocations, nil
+	}
+	err := tx.Where("order_id IN ? AND status = ? AND pick_task_id IS NULL", orderIDs, model.AllocationStatusActive).
+		Order("material_code, location_code, order_id, id").
+		Find(&allocations).Error
+	return allocations, err
+}
+
+// CreateWave 新增波次
+func (r *waveRepository) CreateWave(tx *gorm.DB, wave *model.Wave) error {
+	return tx.Create(wave).Error
+}
+
+// GetWave 按 ID 查询波次
+// 若波次不存在则返回 nil（不视为错误）
+func (r *waveRepository) GetWave(id uint) (*model.Wave, error) {
+	return r.findWave(r.db, id)
+}
+
+// GetWaveForUpdate 以排他锁读取波次
+func (r *waveRepository) GetWaveForUpdate(tx *gorm.DB, id uint) (*model.Wave, error) {
+	return r.findWave(tx.Clauses(clause.Locking{Strength: "UPDATE"}), id)
+}
+
+// UpdateWave 保存波次的变更
+func (r *waveRepository) UpdateWave(tx *gorm.DB, wave *model.Wave) error {
+	return tx.Save(wave).Error
+}
+
+// ListWaves 查询波次，按创建时间倒序
+func (r *waveRepository) ListWaves(filter WaveFilter) ([]model.Wave, error) {
+	query := r.db.Model(&model.Wave{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.GroupBy != "" {
+		query = query.Where("group_by = ?", filter.GroupBy)
+	}
+
+	var waves []model.Wave
+	err := query.Order("id DESC").Find(&waves).Error
+	return waves, err
+}
+
+// CreatePickTask 新增拣货任务并关联预留记录
+func (r *waveRepository) CreatePickTask(tx *gorm.DB, task *model.PickTask, allocationIDs []uint) error {
+	if err := tx.Create(task).Error; err != nil {
+		return err
+	}
+	if len(allocationIDs) == 0 {
+		return nil
+	}
+	return tx.Model(&model.StockAllocation{}).Where("id IN ?", allocationIDs).
+		Update("pick_task_id", task.ID).Error
+}
+
+// GetPickTaskForUpdate 以排他锁读取拣货任务
+func (r *waveRepository) GetPickTaskForUpdate(tx *gorm.DB, id uint) (*model.PickTask, error) {
+	var task model.PickTask
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&task, id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &task, nil
+}
+
+// UpdatePickTask 保存拣货任务的变更
+func (r *waveRepository) UpdatePickTask(tx *gorm.DB, task *model.PickTask) error {
+	return tx.Save(task).Error
+}
+
+// ListPickTasks 查询拣货任务，按波次与行走顺序排列
+func (r *waveRepository) ListPickTasks(filter PickTaskFilter) ([]model.PickTask, error) {
+	query := r.db.Model(&model.PickTask{})
+	if filter.WaveID != 0 {
+		query = query.Where("wave_id = ?", filter.WaveID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Zone != "" {
+		query = query.Where("zone = ?", filter.Zone)
+	}
+	if filter.AssignedTo != "" {
+		query = query.Where("assigned_to = ?", filter.AssignedTo)
+	}
+
+	var tasks []model.PickTask
+	err := query.Order("wave_id, sequence, id").Find(&tasks).Error
+	return tasks, err
+}
+
+// ListTaskAllocations 查询拣货任务关联的预留中记录
+// 按单据顺序排列，确认拣货时先满足较早组入的单据
+func (r *waveRepository) ListTaskAllocations(tx *gorm.DB, taskID uint) ([]model.StockAllocation, error) {
+	var allocations []model.StockAllocation
+	err := tx.Where("pick_task_id = ? AND status = ?", taskID, model.AllocationStatusActive).
+		Order("order_id, id").
+		Find(&allocations).Error
+	return allocations, err
+}
+
+// UpdateAllocation 保存预留记录的拣货数量与状态
+func (r *waveRepository) UpdateAllocation(tx *gorm.DB, allocation *model.StockAllocation) error {
+	return tx.Model(allocation).Select("picked_quantity", "status", "picked_at").Updates(allocation).Error
+}
+
+// CountActiveAllocations 统计出库单据预留中的记录数
+func (r *waveRepository) CountActiveAllocations(tx *gorm.DB, orderID uint) (int64, error) {
+	var count int64
+	err := tx.Model(&model.StockAllocation{}).
+		Where("order_id = ? AND status = ?", orderID, model.AllocationStatusActive).
+		Count(&count).Error
+	return count, err
+}
+
+// BeginTransaction 开启新的数据库事务
+func (r *waveRepository) BeginTransaction() *gorm.DB {
+	return r.db.Begin()
+}
+
+// CommitTransaction 提交当前事务
+func (r *waveRepository) CommitTransaction(tx *gorm.DB) error {
+	return tx.Commit().Error
+}
+
+// RollbackTransaction 回滚当前事务
+func (r *waveRepository) RollbackTransaction(tx *gorm.DB) error {
+	return tx.Rollback().Error
+}
+
+// findWave 按 ID 查询波次，不存在时返回 nil（不视为错误）
+func (r *waveRepository) findWave(query *gorm.DB, id uint) (*model.Wave, error) {
+	var wave model.Wave
+	err := query.First(&wave, id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &wave, nil
+}
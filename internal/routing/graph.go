@@ -0,0 +1,110 @@
+package routing
+
+import "container/heap"
+
+// Graph 表示无向的行走图，节点为库位编码、出发点（DepotID）或自定义的路口
+// 最短路结果按起点缓存，Graph 不是并发安全的，应在每次计算路线时单独构造
+type Graph struct {
+	edges map[string]map[string]float64
+	cache map[string]map[string]float64
+}
+
+// NewGraph 创建空的行走图
+func NewGraph() *Graph {
+	return &Graph{
+		edges: make(map[string]map[string]float64),
+		cache: make(map[string]map[string]float64),
+	}
+}
+
+// AddEdge 添加一条双向通路，同一对节点重复添加时保留较短的距离
+func (g *Graph) AddEdge(a, b string, distance float64) {
+	if a == b || distance < 0 {
+		return
+	}
+	g.link(a, b, distance)
+	g.link(b, a, distance)
+	g.cache = make(map[string]map[string]float64)
+}
+
+// link 添加一条单向边
+func (g *Graph) link(from, to string, distance float64) {
+	if g.edges[from] == nil {
+		g.edges[from] = make(map[string]float64)
+	}
+	if existing, ok := g.edges[from][to]; !ok || distance < existing {
+		g.edges[from][to] = distance
+	}
+}
+
+// Empty 判断行走图是否没有任何通路
+func (g *Graph) Empty() bool {
+	return len(g.edges) == 0
+}
+
+// Distance 返回两节点之间的最短路距离，任一节点不在图中或不连通时 ok 为 false
+func (g *Graph) Distance(from, to string) (distance float64, ok bool) {
+	if from == to {
+		_, ok = g.edges[from]
+		return 0, ok
+	}
+	if _, exists := g.edges[from]; !exists {
+		return 0, false
+	}
+	distances, cached := g.cache[from]
+	if !cached {
+		distances = g.shortestPaths(from)
+		g.cache[from] = distances
+	}
+	distance, ok = distances[to]
+	return distance, ok
+}
+
+// shortestPaths 用 Dijkstra 算法计算起点到所有可达节点的最短距离
+func (g *Graph) shortestPaths(source string) map[string]float64 {
+	distances := map[string]float64{source: 0}
+	queue := &distanceQueue{{node: source}}
+	for queue.Len() > 0 {
+		current := heap.Pop(queue).(distanceItem)
+		if current.distance > distances[current.node] {
+			continue
+		}
+		for next, weight := range g.edges[current.node] {
+			candidate := current.distance + weight
+			if known, ok := distances[next]; !ok || candidate < known {
+				distances[next] = candidate
+				heap.Push(queue, distanceItem{node: next, distance: candidate})
+			}
+		}
+	}
+	return distances
+}
+
+// distanceItem 表示优先队列中的节点及其当前距离
+type distanceItem struct {
+	node     string
+	distance float64
+}
+
+// distanceQueue 是按距离排序的最小堆
+type distanceQueue []distanceItem
+
+func (q distanceQueue) Len() int { return len(q) }
+
+func (q distanceQueue) Less(i, j int) bool {
+	if q[i].distance != q[j].distance {
+		return q[i].distance < q[j].distance
+	}
+	return q[i].node < q[j].node
+}
+
+func (q distanceQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *distanceQueue) Push(x interface{}) { *q = append(*q, x.(distanceItem)) }
+
+func (q *distanceQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,318 @@
+// Package routing 根据仓库布局为拣货、盘点等任务排列行走路线
+// 布局由平行的巷道（aisle）与巷道两端的前、后横向通道组成，出发点（depot）通常位于前通道；
+// 也可以提供行走图（Graph）描述实际可通行的路径，此时点间距离按图上的最短路计算
+package routing
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// 路线策略
+const (
+	// StrategySShape S 形：依次完整穿过每条有任务的巷道，相邻巷道方向交替
+	StrategySShape = "s_shape"
+	// StrategyLargestGap 最大间隙：首尾巷道完整穿过，中间巷道从前后通道各自进入，跳过巷道内最大的空档
+	StrategyLargestGap = "largest_gap"
+	// StrategyTSP 旅行商启发式：最近邻构造初始路线后用 2-opt 改进
+	StrategyTSP = "tsp"
+)
+
+// DepotID 为出发点在行走图中的节点名
+const DepotID = "DEPOT"
+
+// maxTwoOptPasses 限制 2-opt 的改进轮数，避免大规模任务时耗时过长
+const maxTwoOptPasses = 50
+
+// Point 表示平面坐标
+type Point struct {
+	X float64
+	Y float64
+}
+
+// Stop 表示路线上的一个停靠点（通常是库位）
+// Aisle 为空的停靠点不属于任何巷道，S 形与最大间隙策略将其视为单独的一条巷道
+type Stop struct {
+	ID    string
+	Aisle string
+	Point
+}
+
+// Layout 表示计算路线所需的仓库布局
+// FrontY 与 BackY 为前、后横向通道的纵坐标，二者相等表示未配置横向通道，点间距离退化为直角距离
+type Layout struct {
+	Depot  Point
+	FrontY float64
+	BackY  float64
+	Graph  *Graph
+}
+
+// Plan 表示一条路线：按行走顺序排列的停靠点，以及从出发点出发经过全部停靠点再返回的估算距离
+type Plan struct {
+	Stops    []Stop
+	Distance float64
+}
+
+// ValidStrategy 判断路线策略名称是否受支持
+func ValidStrategy(strategy string) bool {
+	switch strategy {
+	case StrategySShape, StrategyLargestGap, StrategyTSP:
+		return true
+	}
+	return false
+}
+
+// Route 按策略排列停靠点
+func Route(strategy string, layout Layout, stops []Stop) (Plan, error) {
+	var ordered []Stop
+	switch strategy {
+	case StrategySShape:
+		ordered = sShape(layout, stops)
+	case StrategyLargestGap:
+		ordered = largestGap(layout, stops)
+	case StrategyTSP:
+		ordered = tsp(layout, stops)
+	default:
+		return Plan{}, fmt.Errorf("unsupported routing strategy %q", strategy)
+	}
+	return Plan{Stops: ordered, Distance: layout.TourLength(ordered)}, nil
+}
+
+// Distance 计算两个停靠点之间的行走距离
+// 行走图中两点均存在且连通时取图上最短路；否则同一巷道内按直角距离，不同巷道须经前通道或后通道中较近的一条
+func (l Layout) Distance(a, b Stop) float64 {
+	if l.Graph != nil {
+		if d, ok := l.Graph.Distance(a.ID, b.ID); ok {
+			return d
+		}
+	}
+	dx := math.Abs(a.X - b.X)
+	if !l.hasCrossAisles() || (a.Aisle != "" && a.Aisle == b.Aisle) {
+		return dx + math.Abs(a.Y-b.Y)
+	}
+	viaFront := math.Abs(a.Y-l.FrontY) + math.Abs(b.Y-l.FrontY)
+	viaBack := math.Abs(a.Y-l.BackY) + math.Abs(b.Y-l.BackY)
+	return dx + math.Min(viaFront, viaBack)
+}
+
+// TourLength 计算从出发点依次经过 stops 再返回出发点的距离
+func (l Layout) TourLength(stops []Stop) float64 {
+	if len(stops) == 0 {
+		return 0
+	}
+	depot := l.depotStop()
+	total := l.Distance(depot, stops[0])
+	for i := 1; i < len(stops); i++ {
+		total += l.Distance(stops[i-1], stops[i])
+	}
+	return total + l.Distance(stops[len(stops)-1], depot)
+}
+
+// hasCrossAisles 判断是否配置了前后横向通道
+func (l Layout) hasCrossAisles() bool {
+	return l.FrontY != l.BackY
+}
+
+// depotStop 返回出发点对应的停靠点
+func (l Layout) depotStop() Stop {
+	return Stop{ID: DepotID, Point: l.Depot}
+}
+
+// depth 返回停靠点距前通道的深度
+func (l Layout) depth(s Stop) float64 {
+	return math.Abs(s.Y - l.FrontY)
+}
+
+// aisleLength 返回巷道长度（前后通道之间的距离），未配置横向通道时取停靠点的最大深度
+func (l Layout) aisleLength(stops []Stop) float64 {
+	if l.hasCrossAisles() {
+		return math.Abs(l.BackY - l.FrontY)
+	}
+	length := 0.0
+	for _, s := range stops {
+		length = math.Max(length, l.depth(s))
+	}
+	return length
+}
+
+// aisleGroup 表示一条有停靠点的巷道
+type aisleGroup struct {
+	key   string
+	x     float64
+	stops []Stop
+}
+
+// groupAisles 按巷道分组停靠点并按横坐标从出发点一侧开始排列，组内按深度由浅到深排序
+func (l Layout) groupAisles(stops []Stop) []aisleGroup {
+	index := make(map[string]int)
+	groups := make([]aisleGroup, 0)
+	for _, s := range stops {
+		key := s.Aisle
+		if key == "" {
+			key = "\x00" + s.ID
+		}
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, aisleGroup{key: key})
+		}
+		groups[i].stops = append(groups[i].stops, s)
+	}
+	for i := range groups {
+		sum := 0.0
+		for _, s := range groups[i].stops {
+			sum += s.X
+		}
+		groups[i].x = sum / float64(len(groups[i].stops))
+		sort.SliceStable(groups[i].stops, func(a, b int) bool {
+			sa, sb := groups[i].stops[a], groups[i].stops[b]
+			if da, db := l.depth(sa), l.depth(sb); da != db {
+				return da < db
+			}
+			return sa.ID < sb.ID
+		})
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].x != groups[j].x {
+			return groups[i].x < groups[j].x
+		}
+		return groups[i].key < groups[j].key
+	})
+	// 出发点更靠近最右侧的巷道时从右向左行走
+	if n := len(groups); n > 1 && math.Abs(groups[n-1].x-l.Depot.X) < math.Abs(groups[0].x-l.Depot.X) {
+		for i, j := 0, n-1; i < j; i, j = i+1, j-1 {
+			groups[i], groups[j] = groups[j], groups[i]
+		}
+	}
+	return groups
+}
+
+// sShape 依次穿过每条巷道，奇数条巷道由前向后，偶数条巷道由后向前
+func sShape(l Layout, stops []Stop) []Stop {
+	ordered := make([]Stop, 0, len(stops))
+	for i, group := range l.groupAisles(stops) {
+		if i%2 == 0 {
+			ordered = append(ordered, group.stops...)
+		} else {
+			ordered = append(ordered, reversed(group.stops)...)
+		}
+	}
+	return ordered
+}
+
+// largestGap 首条巷道由前向后穿过，沿后通道访问中间巷道的后半段，末条巷道由后向前穿过，
+// 再沿前通道返回访问中间巷道的前半段；中间巷道以最大空档为界分为前后两段，空档本身不走
+func largestGap(l Layout, stops []Stop) []Stop {
+	groups := l.groupAisles(stops)
+	switch len(groups) {
+	case 0:
+		return stops
+	case 1:
+		return groups[0].stops
+	}
+
+	length := l.aisleLength(stops)
+	fronts := make([][]Stop, len(groups))
+	backs := make([][]Stop, len(groups))
+	for i := 1; i < len(groups)-1; i++ {
+		fronts[i], backs[i] = splitAtLargestGap(l, groups[i].stops, length)
+	}
+
+	ordered := make([]Stop, 0, len(stops))
+	ordered = append(ordered, groups[0].stops...)
+	for i := 1; i < len(groups)-1; i++ {
+		ordered = append(ordered, reversed(backs[i])...)
+	}
+	ordered = append(ordered, reversed(groups[len(groups)-1].stops)...)
+	for i := len(groups) - 2; i >= 1; i-- {
+		ordered = append(ordered, fronts[i]...)
+	}
+	return ordered
+}
+
+// splitAtLargestGap 按最大空档将巷道内（已按深度排序的）停靠点分为从前通道进入与从后通道进入的两段
+// 空档包括前通道到第一个停靠点、相邻停靠点之间、最后一个停靠点到后通道
+func splitAtLargestGap(l Layout, stops []Stop, length float64) (front, back []Stop) {
+	cut, largest := 0, l.depth(stops[0])
+	for i := 1; i < len(stops); i++ {
+		if gap := l.depth(stops[i]) - l.depth(stops[i-1]); gap > largest {
+			cut, largest = i, gap
+		}
+	}
+	if gap := length - l.depth(stops[len(stops)-1]); gap > largest {
+		cut = len(stops)
+	}
+	return stops[:cut], stops[cut:]
+}
+
+// tsp 以最近邻法从出发点构造路线，再用 2-opt 反转路段直到无法缩短
+func tsp(l Layout, stops []Stop) []Stop {
+	n := len(stops)
+	if n <= 1 {
+		return append([]Stop(nil), stops...)
+	}
+
+	// 节点 0 为出发点，1..n 为停靠点
+	nodes := append([]Stop{l.depotStop()}, stops...)
+	dist := make([][]float64, n+1)
+	for i := range dist {
+		dist[i] = make([]float64, n+1)
+		for j := range dist[i] {
+			if i != j {
+				dist[i][j] = l.Distance(nodes[i], nodes[j])
+			}
+		}
+	}
+
+	tour := make([]int, 0, n+2)
+	tour = append(tour, 0)
+	visited := make([]bool, n+1)
+	visited[0] = true
+	for current := 0; len(tour) <= n; {
+		next := -1
+		for j := 1; j <= n; j++ {
+			if !visited[j] && (next < 0 || dist[current][j] < dist[current][next]) {
+				next = j
+			}
+		}
+		visited[next] = true
+		tour = append(tour, next)
+		current = next
+	}
+	tour = append(tour, 0)
+
+	for pass := 0; pass < maxTwoOptPasses; pass++ {
+		improved := false
+		for i := 1; i < len(tour)-2; i++ {
+			for j := i + 1; j < len(tour)-1; j++ {
+				a, b, c, d := tour[i-1], tour[i], tour[j], tour[j+1]
+				if dist[a][c]+dist[b][d] < dist[a][b]+dist[c][d]-1e-9 {
+					for x, y := i, j; x < y; x, y = x+1, y-1 {
+						tour[x], tour[y] = tour[y], tour[x]
+					}
+					improved = true
+				}
+			}
+		}
+		if !improved {
+			break
+		}
+	}
+
+	ordered := make([]Stop, 0, n)
+	for _, node := range tour[1 : len(tour)-1] {
+		ordered = append(ordered, nodes[node])
+	}
+	return ordered
+}
+
+// reversed 返回倒序的副本
+func reversed(stops []Stop) []Stop {
+	result := make([]Stop, len(stops))
+	for i, s := range stops {
+		result[len(stops)-1-i] = s
+	}
+	return result
+}
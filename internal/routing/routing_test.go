@@ -0,0 +1,141 @@
+package routing
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func ids(stops []Stop) string {
+	result := make([]string, 0, len(stops))
+	for _, s := range stops {
+		result = append(result, s.ID)
+	}
+	return fmt.Sprint(result)
+}
+
+// sampleLayout 为四条巷道（x = 0、5、10、15），前通道 y = 0，后通道 y = 10，出发点在左前角
+func sampleLayout() (Layout, []Stop) {
+	layout := Layout{Depot: Point{X: 0, Y: 0}, FrontY: 0, BackY: 10}
+	stops := []Stop{
+		{ID: "d1", Aisle: "D", Point: Point{X: 15, Y: 5}},
+		{ID: "b2", Aisle: "B", Point: Point{X: 5, Y: 9}},
+		{ID: "a1", Aisle: "A", Point: Point{X: 0, Y: 2}},
+		{ID: "c1", Aisle: "C", Point: Point{X: 10, Y: 3}},
+		{ID: "b1", Aisle: "B", Point: Point{X: 5, Y: 1}},
+		{ID: "a2", Aisle: "A", Point: Point{X: 0, Y: 8}},
+	}
+	return layout, stops
+}
+
+func TestRoute_SShape(t *testing.T) {
+	layout, stops := sampleLayout()
+	plan, err := Route(StrategySShape, layout, stops)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := ids(plan.Stops), "[a1 a2 b2 b1 c1 d1]"; got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+
+	// 出发点靠近右侧时从右向左行走
+	layout.Depot = Point{X: 16, Y: 0}
+	plan, _ = Route(StrategySShape, layout, stops)
+	if got, want := ids(plan.Stops), "[d1 c1 b1 b2 a2 a1]"; got != want {
+		t.Errorf("expected %s from the right-hand depot, got %s", want, got)
+	}
+}
+
+func TestRoute_LargestGap(t *testing.T) {
+	layout, stops := sampleLayout()
+	plan, err := Route(StrategyLargestGap, layout, stops)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// B 巷道最大空档在 1 与 9 之间：b2 从后通道取，b1 从前通道取；C 巷道只有浅处的 c1，从前通道取
+	if got, want := ids(plan.Stops), "[a1 a2 b2 d1 c1 b1]"; got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestRoute_TSPNotWorseThanHeuristics(t *testing.T) {
+	layout, stops := sampleLayout()
+	plan, err := Route(StrategyTSP, layout, stops)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(plan.Stops) != len(stops) {
+		t.Fatalf("expected %d stops, got %s", len(stops), ids(plan.Stops))
+	}
+	for _, strategy := range []string{StrategySShape, StrategyLargestGap} {
+		other, _ := Route(strategy, layout, stops)
+		if plan.Distance > other.Distance+1e-9 {
+			t.Errorf("tsp distance %.1f is longer than %s distance %.1f", plan.Distance, strategy, other.Distance)
+		}
+	}
+}
+
+func TestRoute_TSPUntanglesCrossingPath(t *testing.T) {
+	layout := Layout{Depot: Point{X: 0, Y: 0}}
+	stops := []Stop{
+		{ID: "p1", Point: Point{X: 0, Y: 4}},
+		{ID: "p2", Point: Point{X: 4, Y: 0}},
+		{ID: "p3", Point: Point{X: 4, Y: 4}},
+	}
+	plan, _ := Route(StrategyTSP, layout, stops)
+	if math.Abs(plan.Distance-16) > 1e-9 {
+		t.Errorf("expected perimeter tour of 16, got %.1f via %s", plan.Distance, ids(plan.Stops))
+	}
+}
+
+func TestRoute_EmptyInput(t *testing.T) {
+	layout, _ := sampleLayout()
+	for _, strategy := range []string{StrategySShape, StrategyLargestGap, StrategyTSP} {
+		// 调用方过滤掉全部未登记布局位置的库位后可能得到空的停靠点列表
+		for _, stops := range [][]Stop{nil, {}} {
+			plan, err := Route(strategy, layout, stops)
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", strategy, err)
+			}
+			if len(plan.Stops) != 0 || plan.Distance != 0 {
+				t.Errorf("%s: expected empty plan, got %s (%.1f)", strategy, ids(plan.Stops), plan.Distance)
+			}
+		}
+	}
+}
+
+func TestRoute_RejectsUnknownStrategy(t *testing.T) {
+	if _, err := Route("random", Layout{}, nil); err == nil {
+		t.Error("expected error for unknown strategy")
+	}
+}
+
+func TestLayoutDistance(t *testing.T) {
+	layout := Layout{FrontY: 0, BackY: 10}
+	a := Stop{ID: "a", Aisle: "A", Point: Point{X: 0, Y: 8}}
+	b := Stop{ID: "b", Aisle: "B", Point: Point{X: 5, Y: 7}}
+	c := Stop{ID: "c", Aisle: "A", Point: Point{X: 0, Y: 2}}
+
+	// 不同巷道经后通道：(10-8) + (10-7) + 5
+	if d := layout.Distance(a, b); d != 10 {
+		t.Errorf("expected 10 via the back cross aisle, got %.1f", d)
+	}
+	// 同一巷道直接行走
+	if d := layout.Distance(a, c); d != 6 {
+		t.Errorf("expected 6 within the aisle, got %.1f", d)
+	}
+
+	// 行走图中两点均存在时按最短路
+	graph := NewGraph()
+	graph.AddEdge("a", "x", 1)
+	graph.AddEdge("x", "b", 1.5)
+	graph.AddEdge("a", "b", 9)
+	layout.Graph = graph
+	if d := layout.Distance(a, b); d != 2.5 {
+		t.Errorf("expected graph distance 2.5, got %.1f", d)
+	}
+	// 不在图中的点回退到布局距离
+	if d := layout.Distance(a, c); d != 6 {
+		t.Errorf("expected fallback distance 6, got %.1f", d)
+	}
+}
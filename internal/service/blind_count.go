@@ -0,0 +1,199 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"time"
+	"wms/internal/model"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ListCountTasks 查询盲盘盘点单的盘点任务
+// 返回的任务不包含冻结的系统库存，checkerID 非空时只返回分配给该盘点人的任务
+func (s *stockTakeService) ListCountTasks(stockTakeID uint, checkerID string) ([]model.CountTask, error) {
+	stockTake, err := s.GetStockTake(stockTakeID)
+	if err != nil {
+		return nil, err
+	}
+	if !stockTake.BlindCount {
+		return nil, fmt.Errorf("%w: stock take %d is not a blind count", ErrInvalidState, stockTakeID)
+	}
+
+	tasks, err := s.repo.ListCountTasks(stockTakeID, checkerID)
+	if err != nil {
+		s.logger.Error("Failed to list count tasks",
+			zap.Uint("stock_take_id", stockTakeID),
+			zap.String("checker_id", checkerID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to list count tasks: %w", err)
+	}
+	return tasks, nil
+}
+
+// generateCountTasks 在事务中为盲盘盘点单生成盘点任务并冻结系统库存
+func (s *stockTakeService) generateCountTasks(tx *gorm.DB, st *model.StockTake, frozenAt time.Time) error {
+	locations := make([]string, 0, len(st.Locations))
+	for _, l := range st.Locations {
+		locations = append(locations, l.LocationCode)
+	}
+	materials := make([]string, 0, len(st.Materials))
+	for _, m := range st.Materials {
+		materials = append(materials, m.MaterialCode)
+	}
+
+	stocks, err := s.stockRepo.ListStocksInScope(tx, locations, materials)
+	if err != nil {
+		return fmt.Errorf("failed to snapshot stock: %w", err)
+	}
+
+	tasks := buildCountTasks(st, stocks, frozenAt)
+	route, err := s.router.load(tx, "")
+	if err != nil {
+		return err
+	}
+	sequenceCountTasks(tasks, route)
+	if err := s.repo.CreateCountTasks(tx, tasks); err != nil {
+		return fmt.Errorf("failed to create count tasks: %w", err)
+	}
+
+	s.logger.Info("Blind count tasks generated",
+		zap.Uint("stock_take_id", st.ID),
+		zap.Int("task_count", len(tasks)),
+		zap.Int("line_count", len(stocks)),
+		zap.String("routing_strategy", route.strategy),
+	)
+	return nil
+}
+
+// buildCountTasks 按库位生成盘点任务，每个库位一条任务，任务行为该库位在冻结时点的库存快照
+// 范围指定了库位时即使库位当前无库存也生成任务；盘点人按库位顺序轮流分配
+func buildCountTasks(st *model.StockTake, stocks []model.Stock, frozenAt time.Time) []model.CountTask {
+	linesByLocation := make(map[string][]model.CountTaskLine)
+	var locations []string
+	for _, l := range st.Locations {
+		if _, ok := linesByLocation[l.LocationCode]; !ok {
+			linesByLocation[l.LocationCode] = nil
+			locations = append(locations, l.LocationCode)
+		}
+	}
+	for _, stock := range stocks {
+		if _, ok := linesByLocation[stock.LocationCode]; !ok {
+			locations = append(locations, stock.LocationCode)
+		}
+		linesByLocation[stock.LocationCode] = append(linesByLocation[stock.LocationCode], model.CountTaskLine{
+			MaterialCode:   stock.MaterialCode,
+			LotNumber:      stock.LotNumber,
+			FrozenQuantity: stock.Quantity,
+		})
+	}
+	sort.Strings(locations)
+
+	checkers := make([]string, 0, len(st.Checkers))
+	for _, c := range st.Checkers {
+		checkers = append(checkers, c.CheckerID)
+	}
+	sort.Strings(checkers)
+
+	tasks := make([]model.CountTask, 0, len(locations))
+	for i, location := range locations {
+		task := model.CountTask{
+			StockTakeID:  st.ID,
+			LocationCode: location,
+			Status:       model.CountTaskStatusOpen,
+			FrozenAt:     frozenAt,
+			Lines:        linesByLocation[location],
+		}
+		if len(checkers) > 0 {
+			task.AssignedTo = checkers[i%len(checkers)]
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks
+}
+
+// sequenceCountTasks 为每个盘点人（未分配的任务视为同一组）规划库位行走路线，并按路线为任务编号
+func sequenceCountTasks(tasks []model.CountTask, route *routeContext) {
+	byChecker := make(map[string][]int)
+	var checkers []string
+	for i, task := range tasks {
+		if _, ok := byChecker[task.AssignedTo]; !ok {
+			checkers = append(checkers, task.AssignedTo)
+		}
+		byChecker[task.AssignedTo] = append(byChecker[task.AssignedTo], i)
+	}
+	for _, checker := range checkers {
+		indexes := byChecker[checker]
+		codes := make([]string, 0, len(indexes))
+		for _, i := range indexes {
+			codes = append(codes, tasks[i].LocationCode)
+		}
+		positions := route.route(codes).positions()
+		for _, i := range indexes {
+			tasks[i].Sequence = positions[tasks[i].LocationCode]
+		}
+	}
+}
+
+// blindCountLine 在事务中锁定盲盘任务并返回本次盘点对应的任务行
+// 冻结时点库存中没有该物料批次时（盘点时发现的意外物料或批次），按流水回溯冻结时点的余额补建任务行
+func (s *inventoryService) blindCountLine(tx *gorm.DB, stockTake *model.StockTake, input InventoryCheckInput) (*model.CountTask, *model.CountTaskLine, error) {
+	task, err := s.stockTakeRepo.GetCountTaskForUpdate(tx, stockTake.ID, input.LocationCode)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch count task: %w", err)
+	}
+	if task == nil {
+		return nil, nil, fmt.Errorf("%w: no count task for location %s in stock take %d", ErrInvalidInput, input.LocationCode, stockTake.ID)
+	}
+	if task.AssignedTo != "" && task.AssignedTo != input.CheckerID {
+		return nil, nil, fmt.Errorf("%w: count task for location %s is assigned to another checker", ErrInvalidInput, input.LocationCode)
+	}
+
+	for i := range task.Lines {
+		if task.Lines[i].MaterialCode == input.MaterialCode && task.Lines[i].LotNumber == input.LotNumber {
+			return task, &task.Lines[i], nil
+		}
+	}
+
+	frozen, err := s.ledger.balanceAt(tx, input.MaterialCode, input.LocationCode, input.LotNumber, task.FrozenAt)
+	if err != nil {
+		return nil, nil, err
+	}
+	line := model.CountTaskLine{
+		CountTaskID:    task.ID,
+		MaterialCode:   input.MaterialCode,
+		LotNumber:      input.LotNumber,
+		FrozenQuantity: frozen,
+	}
+	if err := s.stockTakeRepo.SaveCountTaskLine(tx, &line); err != nil {
+		return nil, nil, fmt.Errorf("failed to create count task line: %w", err)
+	}
+	task.Lines = append(task.Lines, line)
+	return task, &task.Lines[len(task.Lines)-1], nil
+}
+
+// completeBlindCountLine 将盘点记录关联到任务行，任务内全部物料均已盘点时标记任务完成
+func (s *inventoryService) completeBlindCountLine(tx *gorm.DB, task *model.CountTask, line *model.CountTaskLine, record *model.InventoryCheckRecord) error {
+	line.CheckRecordID = &record.ID
+	if err := s.stockTakeRepo.SaveCountTaskLine(tx, line); err != nil {
+		return fmt.Errorf("failed to update count task line: %w", err)
+	}
+
+	if task.Status == model.CountTaskStatusCompleted {
+		return nil
+	}
+	for _, l := range task.Lines {
+		if l.CheckRecordID == nil {
+			return nil
+		}
+	}
+	now := time.Now()
+	task.Status = model.CountTaskStatusCompleted
+	task.CompletedAt = &now
+	if err := s.stockTakeRepo.UpdateCountTaskStatus(tx, task); err != nil {
+		return fmt.Errorf("failed to update count task: %w", err)
+	}
+	return nil
+}
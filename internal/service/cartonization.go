@@ -0,0 +1,186 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"wms/internal/model"
+)
+
+// cartonItem 表示待装箱的一个出库单据行
+type cartonItem struct {
+	lineID   uint
+	material model.Material
+	quantity int
+}
+
+// cartonPlan 表示一个推荐的包裹：箱型与装入的单据行数量
+type cartonPlan struct {
+	carton model.CartonType
+	lines  []model.PackageLine
+	volume float64
+	weight float64
+}
+
+// add 向包裹装入 quantity 件单据行物料，同一单据行合并为一行
+func (p *cartonPlan) add(item cartonItem, quantity int) {
+	p.volume += item.material.Volume() * float64(quantity)
+	p.weight += item.material.WeightKg * float64(quantity)
+	for i := range p.lines {
+		if p.lines[i].OrderLineID == item.lineID {
+			p.lines[i].Quantity += quantity
+			return
+		}
+	}
+	p.lines = append(p.lines, model.PackageLine{
+		OrderLineID:  item.lineID,
+		MaterialCode: item.material.Code,
+		Quantity:     quantity,
+	})
+}
+
+// cartonize 为待装箱的单据行推荐箱型并分配装箱数量
+// 按体积与重量估算（不做三维摆放）：物料按单件体积从大到小依次装入已开的包裹，装不下时以能容纳该物料的最大箱型开新包裹；
+// 全部装完后将每个包裹换成能容纳其内容的最小箱型。单件外形放不进任何启用箱型或超过箱型载重时返回 ErrInvalidState
+func cartonize(items []cartonItem, cartons []model.CartonType) ([]cartonPlan, error) {
+	sorted := make([]model.CartonType, 0, len(cartons))
+	for _, carton := range cartons {
+		if carton.Active && carton.Volume() > 0 {
+			sorted = append(sorted, carton)
+		}
+	}
+	if len(sorted) == 0 {
+		return nil, fmt.Errorf("%w: no active carton types are configured", ErrInvalidState)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if vi, vj := sorted[i].Volume(), sorted[j].Volume(); vi != vj {
+			return vi < vj
+		}
+		return sorted[i].Code < sorted[j].Code
+	})
+
+	queue := make([]cartonItem, 0, len(items))
+	for _, item := range items {
+		if item.quantity <= 0 {
+			continue
+		}
+		if !item.material.HasDimensions() {
+			return nil, fmt.Errorf("%w: material %s has no dimensions, pack it manually", ErrInvalidState, item.material.Code)
+		}
+		if largestFitting(sorted, item.material) == nil {
+			return nil, fmt.Errorf("%w: material %s does not fit any active carton type", ErrInvalidState, item.material.Code)
+		}
+		queue = append(queue, item)
+	}
+	sort.SliceStable(queue, func(i, j int) bool {
+		if vi, vj := queue[i].material.Volume(), queue[j].material.Volume(); vi != vj {
+			return vi > vj
+		}
+		return queue[i].lineID < queue[j].lineID
+	})
+
+	var plans []cartonPlan
+	for _, item := range queue {
+		remaining := item.quantity
+		for i := range plans {
+			if remaining == 0 {
+				break
+			}
+			if !fitsCarton(plans[i].carton, item.material) {
+				continue
+			}
+			if n := unitsThatFit(&plans[i], item.material, remaining); n > 0 {
+				plans[i].add(item, n)
+				remaining -= n
+			}
+		}
+		for remaining > 0 {
+			plan := cartonPlan{carton: *largestFitting(sorted, item.material)}
+			n := unitsThatFit(&plan, item.material, remaining)
+			plan.add(item, n)
+			remaining -= n
+			plans = append(plans, plan)
+		}
+	}
+
+	for i := range plans {
+		plans[i].carton = smallestFor(sorted, &plans[i], items)
+	}
+	return plans, nil
+}
+
+// unitsThatFit 返回包裹按剩余容积与载重还能装入的物料件数，不超过 limit
+func unitsThatFit(plan *cartonPlan, material model.Material, limit int) int {
+	n := limit
+	if byVolume := int(math.Floor((plan.carton.Volume()-plan.volume)/material.Volume() + 1e-9)); byVolume < n {
+		n = byVolume
+	}
+	if plan.carton.MaxWeightKg > 0 && material.WeightKg > 0 {
+		if byWeight := int(math.Floor((plan.carton.MaxWeightKg-plan.weight)/material.WeightKg + 1e-9)); byWeight < n {
+			n = byWeight
+		}
+	}
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// largestFitting 返回能放下单件物料的最大箱型，没有时返回 nil；cartons 须按容积从小到大排序
+func largestFitting(cartons []model.CartonType, material model.Material) *model.CartonType {
+	for i := len(cartons) - 1; i >= 0; i-- {
+		if fitsCarton(cartons[i], material) {
+			return &cartons[i]
+		}
+	}
+	return nil
+}
+
+// smallestFor 返回能容纳包裹全部内容的最小箱型；cartons 须按容积从小到大排序，找不到时保留原箱型
+func smallestFor(cartons []model.CartonType, plan *cartonPlan, items []cartonItem) model.CartonType {
+	materials := make(map[uint]model.Material, len(items))
+	for _, item := range items {
+		materials[item.lineID] = item.material
+	}
+	for _, carton := range cartons {
+		if carton.Volume()+1e-9 < plan.volume {
+			continue
+		}
+		if carton.MaxWeightKg > 0 && carton.MaxWeightKg+1e-9 < plan.weight {
+			continue
+		}
+		fits := true
+		for _, line := range plan.lines {
+			if !fitsCarton(carton, materials[line.OrderLineID]) {
+				fits = false
+				break
+			}
+		}
+		if fits {
+			return carton
+		}
+	}
+	return plan.carton
+}
+
+// fitsCarton 判断单件物料能否放入箱型：外形尺寸按从大到小逐一比较（允许旋转），且单件重量不超过载重
+func fitsCarton(carton model.CartonType, material model.Material) bool {
+	if carton.MaxWeightKg > 0 && material.WeightKg > carton.MaxWeightKg {
+		return false
+	}
+	box := sortedDimensions(carton.LengthCm, carton.WidthCm, carton.HeightCm)
+	item := sortedDimensions(material.LengthCm, material.WidthCm, material.HeightCm)
+	for i := range box {
+		if item[i] > box[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// sortedDimensions 将三个尺寸从大到小排序
+func sortedDimensions(a, b, c float64) [3]float64 {
+	dims := []float64{a, b, c}
+	sort.Sort(sort.Reverse(sort.Float64Slice(dims)))
+	return [3]float64{dims[0], dims[1], dims[2]}
+}
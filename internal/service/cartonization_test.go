@@ -0,0 +1,117 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"wms/internal/model"
+)
+
+func testCartons() []model.CartonType {
+	return []model.CartonType{
+		{Code: "L", LengthCm: 60, WidthCm: 40, HeightCm: 40, MaxWeightKg: 20, Active: true},
+		{Code: "S", LengthCm: 20, WidthCm: 20, HeightCm: 10, MaxWeightKg: 5, Active: true},
+		{Code: "M", LengthCm: 40, WidthCm: 30, HeightCm: 20, MaxWeightKg: 10, Active: true},
+		{Code: "XL", LengthCm: 100, WidthCm: 80, HeightCm: 60, Active: false},
+	}
+}
+
+func TestCartonize_UsesSmallestFittingCarton(t *testing.T) {
+	items := []cartonItem{
+		{lineID: 1, material: model.Material{Code: "M-001", LengthCm: 10, WidthCm: 10, HeightCm: 5, WeightKg: 0.5}, quantity: 3},
+		{lineID: 2, material: model.Material{Code: "M-002", LengthCm: 5, WidthCm: 5, HeightCm: 5, WeightKg: 0.1}, quantity: 2},
+	}
+
+	plans, err := cartonize(items, testCartons())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(plans) != 1 {
+		t.Fatalf("Expected 1 package, got %d", len(plans))
+	}
+	if plans[0].carton.Code != "S" {
+		t.Errorf("Expected carton S, got %s", plans[0].carton.Code)
+	}
+	if len(plans[0].lines) != 2 || plans[0].lines[0].Quantity != 3 || plans[0].lines[1].Quantity != 2 {
+		t.Errorf("Unexpected package lines: %+v", plans[0].lines)
+	}
+}
+
+func TestCartonize_SplitsByWeight(t *testing.T) {
+	// 单件 4kg，最大箱型载重 20kg，12 件需拆成 5 + 5 + 2 件
+	items := []cartonItem{
+		{lineID: 1, material: model.Material{Code: "HEAVY", LengthCm: 10, WidthCm: 10, HeightCm: 10, WeightKg: 4}, quantity: 12},
+	}
+
+	plans, err := cartonize(items, testCartons())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(plans) != 3 {
+		t.Fatalf("Expected 3 packages, got %d", len(plans))
+	}
+	total := 0
+	for _, plan := range plans {
+		if plan.carton.MaxWeightKg > 0 && plan.weight > plan.carton.MaxWeightKg {
+			t.Errorf("Package in %s exceeds max weight: %.1f", plan.carton.Code, plan.weight)
+		}
+		total += plan.lines[0].Quantity
+	}
+	if total != 12 {
+		t.Errorf("Expected 12 units packed, got %d", total)
+	}
+	if plans[2].carton.Code != "M" {
+		t.Errorf("Expected the last package of 2 units to be downsized to M, got %s", plans[2].carton.Code)
+	}
+}
+
+func TestCartonize_RotatesItemsToFit(t *testing.T) {
+	// 35 x 15 x 15 的物料旋转后可放入 40 x 30 x 20 的 M 箱
+	items := []cartonItem{
+		{lineID: 1, material: model.Material{Code: "LONG", LengthCm: 15, WidthCm: 35, HeightCm: 15, WeightKg: 1}, quantity: 1},
+	}
+
+	plans, err := cartonize(items, testCartons())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(plans) != 1 || plans[0].carton.Code != "M" {
+		t.Fatalf("Expected a single M package, got %+v", plans)
+	}
+}
+
+func TestCartonize_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		items   []cartonItem
+		cartons []model.CartonType
+	}{
+		{
+			name:    "no dimensions",
+			items:   []cartonItem{{lineID: 1, material: model.Material{Code: "M-001"}, quantity: 1}},
+			cartons: testCartons(),
+		},
+		{
+			name:    "oversized material",
+			items:   []cartonItem{{lineID: 1, material: model.Material{Code: "BIG", LengthCm: 90, WidthCm: 10, HeightCm: 10}, quantity: 1}},
+			cartons: testCartons(),
+		},
+		{
+			name:    "overweight material",
+			items:   []cartonItem{{lineID: 1, material: model.Material{Code: "LEAD", LengthCm: 10, WidthCm: 10, HeightCm: 10, WeightKg: 25}, quantity: 1}},
+			cartons: testCartons(),
+		},
+		{
+			name:    "no active cartons",
+			items:   []cartonItem{{lineID: 1, material: model.Material{Code: "M-001", LengthCm: 1, WidthCm: 1, HeightCm: 1}, quantity: 1}},
+			cartons: testCartons()[3:],
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := cartonize(tt.items, tt.cartons); !errors.Is(err, ErrInvalidState) {
+				t.Errorf("Expected ErrInvalidState, got %v", err)
+			}
+		})
+	}
+}
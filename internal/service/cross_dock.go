@@ -0,0 +1,214 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+	"wms/internal/model"
+	"wms/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// crossDockPick 表示越库分配给一个出库单据行的数量
+type crossDockPick struct {
+	order    *model.OutboundOrder
+	line     *model.OutboundOrderLine
+	quantity int
+}
+
+// crossDockDemand 表示一次收货中可越库满足的出库需求
+// orders 已锁定并按优先级排列；收货过程中直接累加各行的已分配数量，最后由 save 统一保存
+type crossDockDemand struct {
+	orders  []model.OutboundOrder
+	touched map[uint]bool
+}
+
+// crossDocker 在收货事务中把越库物料的到货直接移到越库暂存库位并分配给等待中的出库单据
+type crossDocker struct {
+	outboundRepo repository.OutboundRepository
+	materialRepo repository.MaterialRepository
+	ledger       *stockLedger
+	location     string
+}
+
+// newCrossDocker 创建越库分配器，location 为空时不做越库
+func newCrossDocker(outboundRepo repository.OutboundRepository, materialRepo repository.MaterialRepository, stockRepo repository.StockRepository, location string) *crossDocker {
+	return &crossDocker{
+		outboundRepo: outboundRepo,
+		materialRepo: materialRepo,
+		ledger:       newStockLedger(stockRepo),
+		location:     location,
+	}
+}
+
+// demand 在事务中锁定等待越库物料的出库单据，须在变动任何库存之前调用
+// 未配置越库库位、物料均未标记越库或没有等待中的单据时返回 nil
+func (c *crossDocker) demand(tx *gorm.DB, materialCodes []string) (*crossDockDemand, error) {
+	if c.location == "" || len(materialCodes) == 0 {
+		return nil, nil
+	}
+	materials, err := c.materialRepo.ListByCodes(tx, materialCodes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch materials: %w", err)
+	}
+	var codes []string
+	for _, m := range materials {
+		if m.CrossDock {
+			codes = append(codes, m.Code)
+		}
+	}
+	if len(codes) == 0 {
+		return nil, nil
+	}
+
+	orders, err := c.outboundRepo.ListWaitingOrdersForUpdate(tx, codes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch waiting outbound orders: %w", err)
+	}
+	if len(orders) == 0 {
+		return nil, nil
+	}
+	sortCrossDockOrders(orders)
+	return &crossDockDemand{orders: orders, touched: make(map[uint]bool)}, nil
+}
+
+// allocate 将收货记录中可越库的数量从收货库位移到越库库位，并为等待中的单据行创建预留
+// 返回创建的预留记录，并在 receipt.CrossDockQuantity 中记录越库数量；到货时已过有效期的收货不越库；
+// serialNumbers 为本次收货登记的序列号，越库移动其中的前若干件
+func (c *crossDocker) allocate(tx *gorm.DB, d *crossDockDemand, receipt *model.InboundReceipt, serialNumbers []string, operatorID string) ([]model.StockAllocation, error) {
+	if d == nil || model.ExpiredOn(receipt.ExpiryDate, time.Now()) {
+		return nil, nil
+	}
+	picks := planCrossDock(d.orders, receipt.MaterialCode, receipt.Quantity)
+	total := 0
+	for _, p := range picks {
+		total += p.quantity
+	}
+	if total == 0 {
+		return nil, nil
+	}
+
+	if receipt.LocationCode == c.location {
+		if _, err := c.ledger.reserve(tx, receipt.MaterialCode, c.location, receipt.LotNumber, total); err != nil {
+			return nil, err
+		}
+	} else {
+		var serials []string
+		if len(serialNumbers) >= total {
+			serials = serialNumbers[:total]
+		}
+		err := c.ledger.move(tx, StockMove{
+			MaterialCode:  receipt.MaterialCode,
+			LotNumber:     receipt.LotNumber,
+			FromLocation:  receipt.LocationCode,
+			ToLocation:    c.location,
+			Quantity:      total,
+			KeepReserved:  true,
+			MovementType:  model.MovementTypeCrossDock,
+			ReferenceType: model.ReferenceTypeInboundReceipt,
+			ReferenceID:   strconv.FormatUint(uint64(receipt.ID), 10),
+			OperatorID:    operatorID,
+			SerialNumbers: serials,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	stock, err := c.ledger.lock(tx, receipt.MaterialCode, c.location, receipt.LotNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	allocations := make([]model.StockAllocation, 0, len(picks))
+	for _, p := range picks {
+		allocation := model.StockAllocation{
+			OrderID:      p.order.ID,
+			LineID:       p.line.ID,
+			StockID:      stock.ID,
+			MaterialCode: receipt.MaterialCode,
+			LocationCode: c.location,
+			LotNumber:    receipt.LotNumber,
+			Quantity:     p.quantity,
+			Strategy:     model.AllocationStrategyCrossDock,
+			Status:       model.AllocationStatusActive,
+			AllocatedBy:  operatorID,
+		}
+		if err := c.outboundRepo.CreateAllocation(tx, &allocation); err != nil {
+			return nil, fmt.Errorf("failed to create allocation: %w", err)
+		}
+		p.line.AllocatedQuantity += p.quantity
+		p.line.Status = allocatedLineStatus(p.line)
+		d.touched[p.order.ID] = true
+		allocations = append(allocations, allocation)
+	}
+	receipt.CrossDockQuantity = total
+	return allocations, nil
+}
+
+// save 保存越库分配涉及的单据行与单据状态
+func (c *crossDocker) save(tx *gorm.DB, d *crossDockDemand) error {
+	if d == nil {
+		return nil
+	}
+	for i := range d.orders {
+		order := &d.orders[i]
+		if !d.touched[order.ID] {
+			continue
+		}
+		for j := range order.Lines {
+			if err := c.outboundRepo.UpdateLine(tx, &order.Lines[j]); err != nil {
+				return fmt.Errorf("failed to update outbound order line: %w", err)
+			}
+		}
+		order.Status = allocatedOrderStatus(order)
+		if err := c.outboundRepo.UpdateStatus(tx, order); err != nil {
+			return fmt.Errorf("failed to update outbound order: %w", err)
+		}
+	}
+	return nil
+}
+
+// sortCrossDockOrders 按要求发货时间从早到晚排列等待中的单据，没有要求时间的排在最后，同时间按创建顺序
+func sortCrossDockOrders(orders []model.OutboundOrder) {
+	sort.SliceStable(orders, func(i, j int) bool {
+		a, b := orders[i].RequiredAt, orders[j].RequiredAt
+		if a != nil && b != nil && !a.Equal(*b) {
+			return a.Before(*b)
+		}
+		if (a == nil) != (b == nil) {
+			return a != nil
+		}
+		return orders[i].ID < orders[j].ID
+	})
+}
+
+// planCrossDock 按单据顺序把到货数量分配给物料未分配足量的单据行，同一单据内按行号顺序
+// 返回的分配合计不超过 quantity，到货多于需求的部分由调用方按常规上架处理
+func planCrossDock(orders []model.OutboundOrder, materialCode string, quantity int) []crossDockPick {
+	var picks []crossDockPick
+	for i := range orders {
+		order := &orders[i]
+		for j := range order.Lines {
+			if quantity <= 0 {
+				return picks
+			}
+			line := &order.Lines[j]
+			if line.MaterialCode != materialCode {
+				continue
+			}
+			open := line.OrderedQuantity - line.AllocatedQuantity
+			if open <= 0 {
+				continue
+			}
+			take := open
+			if take > quantity {
+				take = quantity
+			}
+			picks = append(picks, crossDockPick{order: order, line: line, quantity: take})
+			quantity -= take
+		}
+	}
+	return picks
+}
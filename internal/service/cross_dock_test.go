@@ -0,0 +1,73 @@
+package service
+
+import (
+	"testing"
+	"time"
+	"wms/internal/model"
+)
+
+func waitingOrders() []model.OutboundOrder {
+	early := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	late := early.Add(24 * time.Hour)
+	return []model.OutboundOrder{
+		{ID: 1, OrderNo: "SO-001", Lines: []model.OutboundOrderLine{
+			{ID: 11, LineNo: 1, MaterialCode: "M-001", OrderedQuantity: 10, AllocatedQuantity: 10},
+		}},
+		{ID: 2, OrderNo: "SO-002", RequiredAt: &late, Lines: []model.OutboundOrderLine{
+			{ID: 21, LineNo: 1, MaterialCode: "M-001", OrderedQuantity: 8, AllocatedQuantity: 2},
+		}},
+		{ID: 3, OrderNo: "SO-003", RequiredAt: &early, Lines: []model.OutboundOrderLine{
+			{ID: 31, LineNo: 1, MaterialCode: "M-002", OrderedQuantity: 5},
+			{ID: 32, LineNo: 2, MaterialCode: "M-001", OrderedQuantity: 4},
+		}},
+		{ID: 4, OrderNo: "SO-004", Lines: []model.OutboundOrderLine{
+			{ID: 41, LineNo: 1, MaterialCode: "M-001", OrderedQuantity: 5},
+		}},
+	}
+}
+
+func TestSortCrossDockOrders(t *testing.T) {
+	orders := waitingOrders()
+	sortCrossDockOrders(orders)
+
+	want := []uint{3, 2, 1, 4}
+	for i, id := range want {
+		if orders[i].ID != id {
+			t.Fatalf("Expected order %v, got position %d = %d", want, i, orders[i].ID)
+		}
+	}
+}
+
+func TestPlanCrossDock(t *testing.T) {
+	orders := waitingOrders()
+	sortCrossDockOrders(orders)
+
+	picks := planCrossDock(orders, "M-001", 12)
+	if len(picks) != 3 {
+		t.Fatalf("Expected 3 picks, got %d", len(picks))
+	}
+	if picks[0].line.ID != 32 || picks[0].quantity != 4 {
+		t.Errorf("Expected earliest order line 32 to take 4, got line %d quantity %d", picks[0].line.ID, picks[0].quantity)
+	}
+	if picks[1].line.ID != 21 || picks[1].quantity != 6 {
+		t.Errorf("Expected line 21 to take its open 6, got line %d quantity %d", picks[1].line.ID, picks[1].quantity)
+	}
+	if picks[2].line.ID != 41 || picks[2].quantity != 2 {
+		t.Errorf("Expected line 41 to take the remaining 2, got line %d quantity %d", picks[2].line.ID, picks[2].quantity)
+	}
+}
+
+func TestPlanCrossDock_SurplusLeftForPutaway(t *testing.T) {
+	orders := waitingOrders()
+
+	total := 0
+	for _, p := range planCrossDock(orders, "M-001", 100) {
+		total += p.quantity
+	}
+	if total != 15 {
+		t.Errorf("Expected only the open demand of 15 to be cross-docked, got %d", total)
+	}
+	if picks := planCrossDock(orders, "M-003", 10); len(picks) != 0 {
+		t.Errorf("Expected no picks for a material without demand, got %d", len(picks))
+	}
+}
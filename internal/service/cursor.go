@@ -0,0 +1,26 @@
+package service
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+)
+
+// encodeIDCursor 将记录 ID 编码为不透明游标
+// 适用于按 ID 倒序分页的列表
+func encodeIDCursor(id uint) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatUint(uint64(id), 10)))
+}
+
+// decodeIDCursor 解析 encodeIDCursor 生成的游标
+func decodeIDCursor(cursor string) (uint, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("malformed cursor")
+	}
+	id, err := strconv.ParseUint(string(data), 10, 64)
+	if err != nil || id == 0 {
+		return 0, fmt.Errorf("malformed cursor")
+	}
+	return uint(id), nil
+}
@@ -0,0 +1,434 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"time"
+	"wms/internal/model"
+	"wms/internal/repository"
+	"wms/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// CycleCountPolicyInput 表示更新循环盘点配置的输入
+type CycleCountPolicyInput struct {
+	Basis          string
+	LookbackDays   int
+	AClassShare    float64
+	BClassShare    float64
+	AFrequencyDays int
+	BFrequencyDays int
+	CFrequencyDays int
+	MaxTasksPerDay int
+	UpdatedBy      string
+}
+
+// CycleCountCandidate 表示到期需要循环盘点的物料与库位
+type CycleCountCandidate struct {
+	MaterialCode  string     `json:"material_code"`
+	LocationCode  string     `json:"location_code"`
+	ABCClass      string     `json:"abc_class"`
+	LastCountedAt *time.Time `json:"last_counted_at,omitempty"`
+}
+
+// CycleCountPlan 表示某一天的循环盘点计划
+// MaterialClasses 为各 ABC 分类的物料数；Deferred 为因每日任务上限顺延的到期数量
+type CycleCountPlan struct {
+	Date            string                `json:"date"`
+	Basis           string                `json:"basis"`
+	MaterialClasses map[string]int        `json:"material_classes"`
+	Due             []CycleCountCandidate `json:"due"`
+	SkippedRecent   int                   `json:"skipped_recent"`
+	SkippedOpen     int                   `json:"skipped_open"`
+	Deferred        int                   `json:"deferred"`
+	Created         int64                 `json:"created"`
+}
+
+// CycleCountService 定义循环盘点的业务接口
+type CycleCountService interface {
+	// GetPolicy 查询循环盘点配置，尚未配置时返回默认配置
+	GetPolicy() (*model.CycleCountPolicy, error)
+
+	// UpdatePolicy 更新循环盘点配置
+	UpdatePolicy(input CycleCountPolicyInput) (*model.CycleCountPolicy, error)
+
+	// ListValuations 查询物料单位价值
+	ListValuations() ([]model.MaterialValuation, error)
+
+	// UpsertValuation 新增或更新物料单位价值
+	UpsertValuation(materialCode string, unitCost float64) (*model.MaterialValuation, error)
+
+	// PreviewCycleCount 计算指定日期的循环盘点计划，不生成任务
+	PreviewCycleCount(date time.Time) (*CycleCountPlan, error)
+
+	// GenerateCycleCount 计算指定日期的循环盘点计划并生成任务
+	// 已有未完成任务的物料与库位不会重复生成，可在同一天重复执行
+	GenerateCycleCount(date time.Time) (*CycleCountPlan, error)
+
+	// ListCycleCountTasks 按过滤条件查询循环盘点任务
+	ListCycleCountTasks(filter repository.CycleCountTaskFilter) ([]model.CycleCountTask, error)
+}
+
+// cycleCountService 是 CycleCountService 的具体实现
+type cycleCountService struct {
+	repo   repository.CycleCountRepository
+	logger *logger.Logger
+}
+
+// NewCycleCountService 创建新的 CycleCountService 实例
+func NewCycleCountService(repo repository.CycleCountRepository, log *logger.Logger) CycleCountService {
+	return &cycleCountService{
+		repo:   repo,
+		logger: log,
+	}
+}
+
+// DefaultCycleCountPolicy 返回未配置时使用的循环盘点配置
+// 按出入库数量分类，A/B/C 类分别占累计数量的前 80%、80%-95%、其余，盘点间隔为 30/90/180 天
+func DefaultCycleCountPolicy() model.CycleCountPolicy {
+	return model.CycleCountPolicy{
+		Basis:          model.CycleCountBasisVolume,
+		LookbackDays:   90,
+		AClassShare:    80,
+		BClassShare:    95,
+		AFrequencyDays: 30,
+		BFrequencyDays: 90,
+		CFrequencyDays: 180,
+	}
+}
+
+// GetPolicy 查询循环盘点配置
+func (s *cycleCountService) GetPolicy() (*model.CycleCountPolicy, error) {
+	policy, err := s.repo.GetPolicy()
+	if err != nil {
+		s.logger.Error("Failed to fetch cycle count policy", zap.Error(err))
+		return nil, fmt.Errorf("failed to fetch cycle count policy: %w", err)
+	}
+	if policy == nil {
+		defaults := DefaultCycleCountPolicy()
+		policy = &defaults
+	}
+	return policy, nil
+}
+
+// UpdatePolicy 校验并保存循环盘点配置
+func (s *cycleCountService) UpdatePolicy(input CycleCountPolicyInput) (*model.CycleCountPolicy, error) {
+	if input.Basis != model.CycleCountBasisVolume && input.Basis != model.CycleCountBasisValue {
+		return nil, fmt.Errorf("%w: basis must be volume or value", ErrInvalidInput)
+	}
+	if input.LookbackDays < 1 {
+		return nil, fmt.Errorf("%w: lookback_days must be at least 1", ErrInvalidInput)
+	}
+	if input.AClassShare <= 0 || input.AClassShare > input.BClassShare || input.BClassShare > 100 {
+		return nil, fmt.Errorf("%w: class shares must satisfy 0 < a_class_share <= b_class_share <= 100", ErrInvalidInput)
+	}
+	if input.AFrequencyDays < 1 || input.BFrequencyDays < 1 || input.CFrequencyDays < 1 {
+		return nil, fmt.Errorf("%w: count frequencies must be at least 1 day", ErrInvalidInput)
+	}
+	if input.MaxTasksPerDay < 0 {
+		return nil, fmt.Errorf("%w: max_tasks_per_day cannot be negative", ErrInvalidInput)
+	}
+
+	policy := &model.CycleCountPolicy{
+		Basis:          input.Basis,
+		LookbackDays:   input.LookbackDays,
+		AClassShare:    input.AClassShare,
+		BClassShare:    input.BClassShare,
+		AFrequencyDays: input.AFrequencyDays,
+		BFrequencyDays: input.BFrequencyDays,
+		CFrequencyDays: input.CFrequencyDays,
+		MaxTasksPerDay: input.MaxTasksPerDay,
+		UpdatedBy:      input.UpdatedBy,
+	}
+	if err := s.repo.SavePolicy(policy); err != nil {
+		s.logger.Error("Failed to save cycle count policy", zap.Error(err))
+		return nil, fmt.Errorf("failed to save cycle count policy: %w", err)
+	}
+
+	s.logger.Info("Cycle count policy updated",
+		zap.String("basis", policy.Basis),
+		zap.String("updated_by", policy.UpdatedBy),
+	)
+	return policy, nil
+}
+
+// ListValuations 查询物料单位价值
+func (s *cycleCountService) ListValuations() ([]model.MaterialValuation, error) {
+	valuations, err := s.repo.ListValuations()
+	if err != nil {
+		s.logger.Error("Failed to list material valuations", zap.Error(err))
+		return nil, fmt.Errorf("failed to list material valuations: %w", err)
+	}
+	return valuations, nil
+}
+
+// UpsertValuation 新增或更新物料单位价值
+func (s *cycleCountService) UpsertValuation(materialCode string, unitCost float64) (*model.MaterialValuation, error) {
+	if materialCode == "" {
+		return nil, fmt.Errorf("%w: material_code is required", ErrInvalidInput)
+	}
+	if unitCost < 0 {
+		return nil, fmt.Errorf("%w: unit_cost cannot be negative", ErrInvalidInput)
+	}
+
+	valuation := &model.MaterialValuation{MaterialCode: materialCode, UnitCost: unitCost}
+	if err := s.repo.UpsertValuation(valuation); err != nil {
+		s.logger.Error("Failed to save material valuation", zap.String("material_code", materialCode), zap.Error(err))
+		return nil, fmt.Errorf("failed to save material valuation: %w", err)
+	}
+	return valuation, nil
+}
+
+// PreviewCycleCount 计算循环盘点计划
+func (s *cycleCountService) PreviewCycleCount(date time.Time) (*CycleCountPlan, error) {
+	return s.buildPlan(startOfDay(date))
+}
+
+// GenerateCycleCount 计算循环盘点计划并生成任务
+func (s *cycleCountService) GenerateCycleCount(date time.Time) (*CycleCountPlan, error) {
+	day := startOfDay(date)
+	plan, err := s.buildPlan(day)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]model.CycleCountTask, 0, len(plan.Due))
+	for _, c := range plan.Due {
+		tasks = append(tasks, model.CycleCountTask{
+			ScheduledDate: day,
+			MaterialCode:  c.MaterialCode,
+			LocationCode:  c.LocationCode,
+			ABCClass:      c.ABCClass,
+			Source:        model.CycleCountSourceScheduled,
+			LastCountedAt: c.LastCountedAt,
+			Status:        model.CycleCountTaskStatusOpen,
+		})
+	}
+	created, err := s.repo.CreateTasks(tasks)
+	if err != nil {
+		s.logger.Error("Failed to create cycle count tasks", zap.String("date", plan.Date), zap.Error(err))
+		return nil, fmt.Errorf("failed to create cycle count tasks: %w", err)
+	}
+	plan.Created = created
+
+	s.logger.Info("Cycle count tasks generated",
+		zap.String("date", plan.Date),
+		zap.Int("due", len(plan.Due)),
+		zap.Int64("created", created),
+		zap.Int("skipped_recent", plan.SkippedRecent),
+		zap.Int("skipped_open", plan.SkippedOpen),
+		zap.Int("deferred", plan.Deferred),
+	)
+	return plan, nil
+}
+
+// ListCycleCountTasks 查询循环盘点任务
+func (s *cycleCountService) ListCycleCountTasks(filter repository.CycleCountTaskFilter) ([]model.CycleCountTask, error) {
+	tasks, err := s.repo.ListTasks(filter)
+	if err != nil {
+		s.logger.Error("Failed to list cycle count tasks", zap.Error(err))
+		return nil, fmt.Errorf("failed to list cycle count tasks: %w", err)
+	}
+	return tasks, nil
+}
+
+// buildPlan 汇总分类依据、库存与盘点历史，计算指定日期的循环盘点计划
+func (s *cycleCountService) buildPlan(day time.Time) (*CycleCountPlan, error) {
+	policy, err := s.GetPolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	volumes, err := s.repo.SumMovementVolume(day.AddDate(0, 0, -policy.LookbackDays))
+	if err != nil {
+		s.logger.Error("Failed to sum movement volume", zap.Error(err))
+		return nil, fmt.Errorf("failed to sum movement volume: %w", err)
+	}
+	unitCosts := map[string]float64{}
+	if policy.Basis == model.CycleCountBasisValue {
+		valuations, err := s.ListValuations()
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range valuations {
+			unitCosts[v.MaterialCode] = v.UnitCost
+		}
+	}
+	scores := make(map[string]float64, len(volumes))
+	for _, v := range volumes {
+		if policy.Basis == model.CycleCountBasisValue {
+			scores[v.MaterialCode] = float64(v.Volume) * unitCosts[v.MaterialCode]
+		} else {
+			scores[v.MaterialCode] = float64(v.Volume)
+		}
+	}
+
+	stocks, err := s.repo.ListStockedPairs()
+	if err != nil {
+		s.logger.Error("Failed to list stocked locations", zap.Error(err))
+		return nil, fmt.Errorf("failed to list stock: %w", err)
+	}
+	materials := make([]string, 0, len(stocks))
+	for _, stock := range stocks {
+		materials = append(materials, stock.MaterialCode)
+	}
+	classes := classifyABC(scores, materials, policy.AClassShare, policy.BClassShare)
+
+	longest := policy.AFrequencyDays
+	if policy.BFrequencyDays > longest {
+		longest = policy.BFrequencyDays
+	}
+	if policy.CFrequencyDays > longest {
+		longest = policy.CFrequencyDays
+	}
+	counts, err := s.repo.ListLastCounts(day.AddDate(0, 0, -longest))
+	if err != nil {
+		s.logger.Error("Failed to list last counts", zap.Error(err))
+		return nil, fmt.Errorf("failed to list last counts: %w", err)
+	}
+	lastCounted := make(map[stockKey]time.Time, len(counts))
+	for _, c := range counts {
+		lastCounted[stockKey{materialCode: c.MaterialCode, locationCode: c.LocationCode}] = c.LastCheck
+	}
+
+	openTasks, err := s.repo.ListOpenTasks()
+	if err != nil {
+		s.logger.Error("Failed to list open cycle count tasks", zap.Error(err))
+		return nil, fmt.Errorf("failed to list open cycle count tasks: %w", err)
+	}
+	open := make(map[stockKey]bool, len(openTasks))
+	for _, t := range openTasks {
+		open[stockKey{materialCode: t.MaterialCode, locationCode: t.LocationCode}] = true
+	}
+
+	return planCycleCounts(policy, classes, stocks, lastCounted, open, day), nil
+}
+
+// stockKey 标识一个物料与库位，需要区分批次时同时给出批次号
+type stockKey struct {
+	materialCode string
+	locationCode string
+	lotNumber    string
+}
+
+// classifyABC 按得分降序累计占比将物料分为 A/B/C 三类
+// 物料进入前的累计占比低于 aShare 为 A 类、低于 bShare 为 B 类，因此得分最高的物料总是 A 类；
+// 没有得分（回溯期内无出入库）的物料为 C 类
+func classifyABC(scores map[string]float64, materials []string, aShare, bShare float64) map[string]string {
+	classes := make(map[string]string, len(materials)+len(scores))
+	for _, m := range materials {
+		classes[m] = model.ABCClassC
+	}
+
+	ranked := make([]string, 0, len(scores))
+	total := 0.0
+	for m, score := range scores {
+		if score > 0 {
+			ranked = append(ranked, m)
+			total += score
+		}
+		classes[m] = model.ABCClassC
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if scores[ranked[i]] != scores[ranked[j]] {
+			return scores[ranked[i]] > scores[ranked[j]]
+		}
+		return ranked[i] < ranked[j]
+	})
+
+	cumulative := 0.0
+	for _, m := range ranked {
+		share := cumulative / total * 100
+		switch {
+		case share < aShare:
+			classes[m] = model.ABCClassA
+		case share < bShare:
+			classes[m] = model.ABCClassB
+		}
+		cumulative += scores[m]
+	}
+	return classes
+}
+
+// planCycleCounts 找出指定日期到期的物料与库位
+// 同一库位的多个批次只计一次；距最近一次盘点不足该分类盘点间隔的跳过，已有未完成任务的跳过；
+// 结果按 A/B/C、从未盘点、最久未盘点的顺序排列，超出每日上限的部分顺延
+func planCycleCounts(policy *model.CycleCountPolicy, classes map[string]string, stocks []model.Stock, lastCounted map[stockKey]time.Time, open map[stockKey]bool, day time.Time) *CycleCountPlan {
+	plan := &CycleCountPlan{
+		Date:            day.Format("2006-01-02"),
+		Basis:           policy.Basis,
+		MaterialClasses: map[string]int{model.ABCClassA: 0, model.ABCClassB: 0, model.ABCClassC: 0},
+		Due:             []CycleCountCandidate{},
+	}
+
+	seen := make(map[string]bool)
+	planned := make(map[stockKey]bool)
+	for _, stock := range stocks {
+		class := classes[stock.MaterialCode]
+		if class == "" {
+			class = model.ABCClassC
+		}
+		if !seen[stock.MaterialCode] {
+			seen[stock.MaterialCode] = true
+			plan.MaterialClasses[class]++
+		}
+
+		key := stockKey{materialCode: stock.MaterialCode, locationCode: stock.LocationCode}
+		if planned[key] {
+			continue
+		}
+		planned[key] = true
+		if open[key] {
+			plan.SkippedOpen++
+			continue
+		}
+		candidate := CycleCountCandidate{
+			MaterialCode: stock.MaterialCode,
+			LocationCode: stock.LocationCode,
+			ABCClass:     class,
+		}
+		if last, ok := lastCounted[key]; ok {
+			if daysBetween(last, day) < policy.FrequencyDays(class) {
+				plan.SkippedRecent++
+				continue
+			}
+			candidate.LastCountedAt = &last
+		}
+		plan.Due = append(plan.Due, candidate)
+	}
+
+	sort.SliceStable(plan.Due, func(i, j int) bool {
+		a, b := plan.Due[i], plan.Due[j]
+		if a.ABCClass != b.ABCClass {
+			return a.ABCClass < b.ABCClass
+		}
+		if (a.LastCountedAt == nil) != (b.LastCountedAt == nil) {
+			return a.LastCountedAt == nil
+		}
+		if a.LastCountedAt != nil && !a.LastCountedAt.Equal(*b.LastCountedAt) {
+			return a.LastCountedAt.Before(*b.LastCountedAt)
+		}
+		if a.MaterialCode != b.MaterialCode {
+			return a.MaterialCode < b.MaterialCode
+		}
+		return a.LocationCode < b.LocationCode
+	})
+
+	if policy.MaxTasksPerDay > 0 && len(plan.Due) > policy.MaxTasksPerDay {
+		plan.Deferred = len(plan.Due) - policy.MaxTasksPerDay
+		plan.Due = plan.Due[:policy.MaxTasksPerDay]
+	}
+	return plan
+}
+
+// startOfDay 返回时间所在日期的零点
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// daysBetween 返回 from 所在日期到 day 的天数
+func daysBetween(from, day time.Time) int {
+	return int(day.Sub(startOfDay(from.In(day.Location()))).Hours() / 24)
+}
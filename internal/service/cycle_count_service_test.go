@@ -0,0 +1,93 @@
+package service
+
+import (
+	"testing"
+	"time"
+	"wms/internal/model"
+)
+
+func TestClassifyABC_ByCumulativeShare(t *testing.T) {
+	scores := map[string]float64{"M1": 700, "M2": 200, "M3": 60, "M4": 40}
+	materials := []string{"M1", "M2", "M3", "M4", "M5"}
+
+	classes := classifyABC(scores, materials, 80, 95)
+
+	expected := map[string]string{
+		"M1": model.ABCClassA, // 进入前累计 0%
+		"M2": model.ABCClassA, // 进入前累计 70%
+		"M3": model.ABCClassB, // 进入前累计 90%
+		"M4": model.ABCClassC, // 进入前累计 96%
+		"M5": model.ABCClassC, // 回溯期内无出入库
+	}
+	for m, want := range expected {
+		if classes[m] != want {
+			t.Errorf("Expected %s to be class %s, got %s", m, want, classes[m])
+		}
+	}
+}
+
+func TestClassifyABC_NoMovementsAllC(t *testing.T) {
+	classes := classifyABC(map[string]float64{}, []string{"M1", "M2"}, 80, 95)
+	if classes["M1"] != model.ABCClassC || classes["M2"] != model.ABCClassC {
+		t.Errorf("Expected all materials to be class C, got %v", classes)
+	}
+}
+
+func TestPlanCycleCounts_SkipsRecentAndOpen(t *testing.T) {
+	policy := DefaultCycleCountPolicy()
+	policy.MaxTasksPerDay = 3
+	day := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+	classes := map[string]string{"FAST": model.ABCClassA, "SLOW": model.ABCClassC}
+	stocks := []model.Stock{
+		{MaterialCode: "FAST", LocationCode: "A-01"},
+		{MaterialCode: "FAST", LocationCode: "A-02"},
+		{MaterialCode: "FAST", LocationCode: "A-03"},
+		{MaterialCode: "FAST", LocationCode: "A-04"},
+		{MaterialCode: "SLOW", LocationCode: "B-01"},
+		{MaterialCode: "SLOW", LocationCode: "B-02"},
+	}
+	lastCounted := map[stockKey]time.Time{
+		{"FAST", "A-01", ""}: day.AddDate(0, 0, -10),                      // A 类 30 天内已盘
+		{"FAST", "A-02", ""}: day.AddDate(0, 0, -45),                      // A 类已到期
+		{"SLOW", "B-01", ""}: day.AddDate(0, 0, -100).Add(15 * time.Hour), // C 类 180 天内已盘
+	}
+	open := map[stockKey]bool{{"FAST", "A-04", ""}: true}
+
+	plan := planCycleCounts(&policy, classes, stocks, lastCounted, open, day)
+
+	if plan.SkippedRecent != 2 || plan.SkippedOpen != 1 {
+		t.Errorf("Expected 2 recent and 1 open skipped, got %d and %d", plan.SkippedRecent, plan.SkippedOpen)
+	}
+	var got []string
+	for _, c := range plan.Due {
+		got = append(got, c.MaterialCode+"@"+c.LocationCode)
+	}
+	// A 类优先，从未盘点的优先于最久未盘点的
+	want := []string{"FAST@A-03", "FAST@A-02", "SLOW@B-02"}
+	if len(got) != len(want) {
+		t.Fatalf("Expected due %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Expected due %v, got %v", want, got)
+		}
+	}
+	if plan.MaterialClasses[model.ABCClassA] != 1 || plan.MaterialClasses[model.ABCClassC] != 1 {
+		t.Errorf("Unexpected class counts: %v", plan.MaterialClasses)
+	}
+}
+
+func TestPlanCycleCounts_DefersBeyondDailyLimit(t *testing.T) {
+	policy := DefaultCycleCountPolicy()
+	policy.MaxTasksPerDay = 1
+	stocks := []model.Stock{
+		{MaterialCode: "M1", LocationCode: "A-01"},
+		{MaterialCode: "M1", LocationCode: "A-02"},
+	}
+
+	plan := planCycleCounts(&policy, map[string]string{}, stocks, nil, nil, time.Now())
+
+	if len(plan.Due) != 1 || plan.Deferred != 1 {
+		t.Errorf("Expected 1 due and 1 deferred, got %d and %d", len(plan.Due), plan.Deferred)
+	}
+}
@@ -0,0 +1,46 @@
+package service
+
+import "errors"
+
+// 业务错误定义
+// 处理器层通过 errors.Is 将这些错误映射为 HTTP 状态码与错误码
+var (
+	// ErrInvalidInput 表示输入参数校验失败
+	ErrInvalidInput = errors.New("input validation failed")
+
+	// ErrNotFound 表示请求的资源不存在
+	ErrNotFound = errors.New("resource not found")
+
+	// ErrInvalidState 表示资源当前状态不允许执行该操作
+	ErrInvalidState = errors.New("invalid state for operation")
+
+	// ErrInsufficientStock 表示操作会导致库存为负
+	ErrInsufficientStock = errors.New("insufficient stock")
+
+	// ErrUnknownMaterial 表示物料编码未在物料主数据中登记
+	ErrUnknownMaterial = errors.New("unknown material")
+
+	// ErrInactiveMaterial 表示物料已停用，不能再发生库存变动
+	ErrInactiveMaterial = errors.New("inactive material")
+
+	// ErrUnknownLocation 表示库位编码未在库位主数据中登记
+	ErrUnknownLocation = errors.New("unknown location")
+
+	// ErrInactiveLocation 表示库位已停用，不能再发生库存变动
+	ErrInactiveLocation = errors.New("inactive location")
+
+	// ErrLocationBlocked 表示库位已被冻结，暂时不能发生库存变动
+	ErrLocationBlocked = errors.New("location blocked")
+
+	// ErrLotRequired 表示物料启用了批次管理，入库或盘点时必须给出批次号
+	ErrLotRequired = errors.New("lot number required")
+
+	// ErrStockExpired 表示库存已过有效期，不能再分配、补货或按预留拣货
+	ErrStockExpired = errors.New("stock expired")
+
+	// ErrSerialRequired 表示物料启用了序列号管理，收货、移库、拣货或盘点时必须给出序列号
+	ErrSerialRequired = errors.New("serial numbers required")
+
+	// ErrSerialMismatch 表示给出的序列号与系统登记不符（不在来源库位，或收货时已在库）
+	ErrSerialMismatch = errors.New("serial number mismatch")
+)
@@ -0,0 +1,597 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"wms/internal/model"
+	"wms/internal/repository"
+	"wms/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// InboundOrderInput 表示创建入库单据的输入数据
+// ReceivingLocation 为空时使用 InboundOptions.ReceivingLocation；OverReceiptPercent 为空时使用默认超收比例
+type InboundOrderInput struct {
+	OrderNo            string
+	OrderType          string
+	SupplierCode       string
+	PurchaseOrderNo    string
+	ExpectedAt         *time.Time
+	ReceivingLocation  string
+	OverReceiptPercent *float64
+	CreatedBy          string
+	Lines              []InboundLineInput
+}
+
+// InboundLineInput 表示入库单据的一条预期物料行，LineNo 为 0 时按顺序自动编号
+type InboundLineInput struct {
+	LineNo           int
+	MaterialCode     string
+	ExpectedQuantity int
+}
+
+// ReceiveInput 表示一次收货操作
+// LocationCode 为空时收货到单据的收货暂存库位
+type ReceiveInput struct {
+	ReceivedBy   string
+	LocationCode string
+	Note         string
+	Lines        []ReceiveLineInput
+}
+
+// ReceiveLineInput 表示收货的一行，按 LineNo 匹配单据行；LineNo 为 0 时按物料匹配，物料须在单据中唯一
+// LotNumber、ManufactureDate、ExpiryDate 为收到的批次及其生产日期与有效期，启用批次管理的物料必须给出批次号；
+// 同一单据行收到多个批次时按批次分多行给出；SerialNumbers 为逐件登记的序列号，启用序列号管理的物料必须给出且与数量一致
+type ReceiveLineInput struct {
+	LineNo          int
+	MaterialCode    string
+	LotNumber       string
+	ManufactureDate *time.Time
+	ExpiryDate      *time.Time
+	Quantity        int
+	SerialNumbers   []string
+}
+
+// ReceiveResult 表示收货操作的结果，CrossDockAllocations 为越库直接分配给出库单据的预留
+type ReceiveResult struct {
+	Order                *model.InboundOrder     `json:"order"`
+	Receipts             []model.InboundReceipt  `json:"receipts"`
+	PutawayTasks         []model.PutawayTask     `json:"putaway_tasks"`
+	CrossDockAllocations []model.StockAllocation `json:"cross_dock_allocations"`
+}
+
+// InboundOptions 表示入库服务的可配置项
+type InboundOptions struct {
+	// ReceivingLocation 单据未指定收货暂存库位时使用的库位
+	ReceivingLocation string
+	// OverReceiptPercent 单据未指定超收比例时允许超出预期数量的百分比
+	OverReceiptPercent float64
+	// CrossDockLocation 越库暂存库位，标记为越库的物料到货后直接移至该库位并分配给等待中的出库单据；为空时不做越库
+	CrossDockLocation string
+	// Retry 事务遇到序列化失败或死锁时的重试策略
+	Retry RetryPolicy
+}
+
+// InboundService 定义入库收货的业务接口
+type InboundService interface {
+	// CreateOrder 创建采购订单或预到货通知
+	CreateOrder(input InboundOrderInput) (*model.InboundOrder, error)
+
+	// GetOrder 查询入库单据，不存在时返回 ErrNotFound
+	GetOrder(id uint) (*model.InboundOrder, error)
+
+	// ListOrders 按过滤条件查询入库单据
+	ListOrders(filter repository.InboundOrderFilter) ([]model.InboundOrder, error)
+
+	// ReceiveOrder 收货并在同一事务中将数量过账到收货库位的库存；越库物料优先分配给等待中的出库单据，
+	// 其余数量为每条收货记录生成上架任务
+	// 单据已关闭或取消时返回 ErrInvalidState；物料不在单据中或超出允许的超收数量时返回 ErrInvalidInput
+	ReceiveOrder(id uint, input ReceiveInput) (*ReceiveResult, error)
+
+	// CloseOrder 关闭入库单据，之后不再接受收货；存在未收足的行时必须给出原因，这些行标记为 short
+	CloseOrder(id uint, operatorID, reason string) (*model.InboundOrder, error)
+
+	// CancelOrder 取消尚未收货的入库单据
+	CancelOrder(id uint, operatorID, reason string) (*model.InboundOrder, error)
+
+	// ListReceipts 查询入库单据的收货记录
+	ListReceipts(id uint) ([]model.InboundReceipt, error)
+}
+
+// inboundService 是 InboundService 的具体实现
+type inboundService struct {
+	repo      repository.InboundRepository
+	ledger    *stockLedger
+	putaway   *putawayPlanner
+	crossDock *crossDocker
+	options   InboundOptions
+	logger    *logger.Logger
+}
+
+// NewInboundService 创建新的 InboundService 实例
+func NewInboundService(repo repository.InboundRepository, stockRepo repository.StockRepository, putawayRepo repository.PutawayRepository, locationRepo repository.LocationRepository,
+	outboundRepo repository.OutboundRepository, materialRepo repository.MaterialRepository, options InboundOptions, log *logger.Logger) InboundService {
+	return &inboundService{
+		repo:      repo,
+		ledger:    newStockLedger(stockRepo),
+		putaway:   newPutawayPlanner(putawayRepo, locationRepo, stockRepo),
+		crossDock: newCrossDocker(outboundRepo, materialRepo, stockRepo, options.CrossDockLocation),
+		options:   options,
+		logger:    log,
+	}
+}
+
+// CreateOrder 创建入库单据
+func (s *inboundService) CreateOrder(input InboundOrderInput) (*model.InboundOrder, error) {
+	order, err := s.buildOrder(input)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.GetByOrderNo(order.OrderNo)
+	if err != nil {
+		s.logger.Error("Failed to check inbound order number", zap.String("order_no", order.OrderNo), zap.Error(err))
+		return nil, fmt.Errorf("failed to check order number: %w", err)
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("%w: order_no %s already exists", ErrInvalidInput, order.OrderNo)
+	}
+
+	if err := s.repo.Create(order); err != nil {
+		s.logger.Error("Failed to create inbound order", zap.String("order_no", order.OrderNo), zap.Error(err))
+		return nil, fmt.Errorf("failed to create inbound order: %w", err)
+	}
+
+	s.logger.Info("Inbound order created",
+		zap.Uint("order_id", order.ID),
+		zap.String("order_no", order.OrderNo),
+		zap.String("order_type", order.OrderType),
+		zap.String("receiving_location", order.ReceivingLocation),
+		zap.Int("line_count", len(order.Lines)),
+	)
+	return order, nil
+}
+
+// buildOrder 校验输入并构造入库单据
+func (s *inboundService) buildOrder(input InboundOrderInput) (*model.InboundOrder, error) {
+	orderNo := strings.TrimSpace(input.OrderNo)
+	if orderNo == "" {
+		return nil, fmt.Errorf("%w: order_no is required", ErrInvalidInput)
+	}
+	if input.OrderType != model.InboundOrderTypePO && input.OrderType != model.InboundOrderTypeASN {
+		return nil, fmt.Errorf("%w: unsupported order_type %q", ErrInvalidInput, input.OrderType)
+	}
+	if strings.TrimSpace(input.CreatedBy) == "" {
+		return nil, fmt.Errorf("%w: created_by is required", ErrInvalidInput)
+	}
+	if len(input.Lines) == 0 {
+		return nil, fmt.Errorf("%w: at least one line is required", ErrInvalidInput)
+	}
+
+	location := strings.TrimSpace(input.ReceivingLocation)
+	if location == "" {
+		location = s.options.ReceivingLocation
+	}
+	if location == "" {
+		return nil, fmt.Errorf("%w: receiving_location is required", ErrInvalidInput)
+	}
+	overReceipt := s.options.OverReceiptPercent
+	if input.OverReceiptPercent != nil {
+		overReceipt = *input.OverReceiptPercent
+	}
+	if overReceipt < 0 {
+		return nil, fmt.Errorf("%w: over_receipt_percent cannot be negative", ErrInvalidInput)
+	}
+
+	order := &model.InboundOrder{
+		OrderNo:            orderNo,
+		OrderType:          input.OrderType,
+		SupplierCode:       input.SupplierCode,
+		PurchaseOrderNo:    input.PurchaseOrderNo,
+		ExpectedAt:         input.ExpectedAt,
+		ReceivingLocation:  location,
+		OverReceiptPercent: overReceipt,
+		Status:             model.InboundOrderStatusOpen,
+		CreatedBy:          input.CreatedBy,
+		Lines:              make([]model.InboundOrderLine, 0, len(input.Lines)),
+	}
+	seen := make(map[int]bool, len(input.Lines))
+	for i, line := range input.Lines {
+		lineNo := line.LineNo
+		if lineNo == 0 {
+			lineNo = i + 1
+		}
+		if lineNo < 0 || seen[lineNo] {
+			return nil, fmt.Errorf("%w: duplicate or invalid line_no %d", ErrInvalidInput, lineNo)
+		}
+		seen[lineNo] = true
+		material := strings.TrimSpace(line.MaterialCode)
+		if material == "" {
+			return nil, fmt.Errorf("%w: line %d material_code is required", ErrInvalidInput, lineNo)
+		}
+		if line.ExpectedQuantity <= 0 {
+			return nil, fmt.Errorf("%w: line %d expected_quantity must be positive", ErrInvalidInput, lineNo)
+		}
+		order.Lines = append(order.Lines, model.InboundOrderLine{
+			LineNo:           lineNo,
+			MaterialCode:     material,
+			ExpectedQuantity: line.ExpectedQuantity,
+			Status:           model.InboundLineStatusOpen,
+		})
+	}
+	return order, nil
+}
+
+// GetOrder 查询入库单据
+func (s *inboundService) GetOrder(id uint) (*model.InboundOrder, error) {
+	order, err := s.repo.GetByID(id)
+	if err != nil {
+		s.logger.Error("Failed to fetch inbound order", zap.Uint("order_id", id), zap.Error(err))
+		return nil, fmt.Errorf("failed to fetch inbound order: %w", err)
+	}
+	if order == nil {
+		return nil, fmt.Errorf("%w: inbound order %d", ErrNotFound, id)
+	}
+	return order, nil
+}
+
+// ListOrders 查询入库单据列表
+func (s *inboundService) ListOrders(filter repository.InboundOrderFilter) ([]model.InboundOrder, error) {
+	orders, err := s.repo.List(filter)
+	if err != nil {
+		s.logger.Error("Failed to list inbound orders", zap.Error(err))
+		return nil, fmt.Errorf("failed to list inbound orders: %w", err)
+	}
+	return orders, nil
+}
+
+// receiptPosting 表示一行待过账的收货
+type receiptPosting struct {
+	line            *model.InboundOrderLine
+	lotNumber       string
+	manufactureDate *time.Time
+	expiryDate      *time.Time
+	quantity        int
+	serialNumbers   []string
+}
+
+// ReceiveOrder 收货过账
+// 锁定单据后按物料顺序逐行写收货记录、过账库存并生成上架任务，任一行失败时整次收货回滚；
+// 越库物料的到货先满足等待中的出库单据（按要求发货时间），越库数量不再生成上架任务
+// 锁定顺序：入库单据 -> 等待越库的出库单据（按 ID）-> 库存行
+func (s *inboundService) ReceiveOrder(id uint, input ReceiveInput) (*ReceiveResult, error) {
+	if strings.TrimSpace(input.ReceivedBy) == "" {
+		return nil, fmt.Errorf("%w: received_by is required", ErrInvalidInput)
+	}
+	if len(input.Lines) == 0 {
+		return nil, fmt.Errorf("%w: at least one line is required", ErrInvalidInput)
+	}
+
+	var result *ReceiveResult
+	err := s.options.Retry.run(s.logger, "inbound_receive", func() error {
+		return runInTransaction(s.repo, s.logger, "inbound_receive", func(tx *gorm.DB) error {
+			order, err := s.repo.GetForUpdate(tx, id)
+			if err != nil {
+				return fmt.Errorf("failed to fetch inbound order: %w", err)
+			}
+			if order == nil {
+				return fmt.Errorf("%w: inbound order %d", ErrNotFound, id)
+			}
+			if order.Status != model.InboundOrderStatusOpen && order.Status != model.InboundOrderStatusReceiving {
+				return fmt.Errorf("%w: inbound order %d is %s", ErrInvalidState, id, order.Status)
+			}
+
+			postings, err := matchReceiptLines(order, input.Lines)
+			if err != nil {
+				return err
+			}
+			location := strings.TrimSpace(input.LocationCode)
+			if location == "" {
+				location = order.ReceivingLocation
+			}
+			materialCodes := make([]string, 0, len(postings))
+			for _, p := range postings {
+				materialCodes = append(materialCodes, p.line.MaterialCode)
+			}
+			demand, err := s.crossDock.demand(tx, materialCodes)
+			if err != nil {
+				return err
+			}
+
+			now := time.Now()
+			receipts := make([]model.InboundReceipt, 0, len(postings))
+			tasks := make([]model.PutawayTask, 0, len(postings))
+			crossDocked := []model.StockAllocation{}
+			for _, p := range postings {
+				receipt := model.InboundReceipt{
+					OrderID:         order.ID,
+					LineID:          p.line.ID,
+					MaterialCode:    p.line.MaterialCode,
+					LocationCode:    location,
+					LotNumber:       p.lotNumber,
+					ManufactureDate: p.manufactureDate,
+					ExpiryDate:      p.expiryDate,
+					Quantity:        p.quantity,
+					ReceivedBy:      input.ReceivedBy,
+					Note:            input.Note,
+					ReceivedAt:      now,
+				}
+				if err := s.repo.CreateReceipt(tx, &receipt); err != nil {
+					return fmt.Errorf("failed to create receipt: %w", err)
+				}
+				if _, err := s.ledger.apply(tx, StockChange{
+					MaterialCode:    receipt.MaterialCode,
+					LocationCode:    location,
+					LotNumber:       receipt.LotNumber,
+					ManufactureDate: receipt.ManufactureDate,
+					ExpiryDate:      receipt.ExpiryDate,
+					Delta:           receipt.Quantity,
+					MovementType:    model.MovementTypeReceipt,
+					ReferenceType:   model.ReferenceTypeInboundReceipt,
+					ReferenceID:     strconv.FormatUint(uint64(receipt.ID), 10),
+					ToLocation:      location,
+					OperatorID:      input.ReceivedBy,
+					SerialNumbers:   p.serialNumbers,
+				}); err != nil {
+					return err
+				}
+				allocations, err := s.crossDock.allocate(tx, demand, &receipt, p.serialNumbers, input.ReceivedBy)
+				if err != nil {
+					return err
+				}
+				if receipt.CrossDockQuantity > 0 {
+					if err := s.repo.UpdateReceipt(tx, &receipt); err != nil {
+						return fmt.Errorf("failed to update receipt: %w", err)
+					}
+					crossDocked = append(crossDocked, allocations...)
+				}
+				receipts = append(receipts, receipt)
+				if receipt.Quantity == receipt.CrossDockQuantity {
+					continue
+				}
+
+				receiptID := receipt.ID
+				task := model.PutawayTask{
+					ReceiptID:      &receiptID,
+					MaterialCode:   receipt.MaterialCode,
+					LotNumber:      receipt.LotNumber,
+					SourceLocation: location,
+					Quantity:       receipt.Quantity - receipt.CrossDockQuantity,
+					CreatedBy:      input.ReceivedBy,
+				}
+				if err := s.putaway.createTask(tx, &task); err != nil {
+					return err
+				}
+				tasks = append(tasks, task)
+			}
+			for _, p := range postings {
+				if err := s.repo.UpdateLine(tx, p.line); err != nil {
+					return fmt.Errorf("failed to update inbound order line: %w", err)
+				}
+			}
+			if err := s.crossDock.save(tx, demand); err != nil {
+				return err
+			}
+
+			order.Status = model.InboundOrderStatusReceiving
+			if err := s.repo.UpdateStatus(tx, order); err != nil {
+				return fmt.Errorf("failed to update inbound order: %w", err)
+			}
+			result = &ReceiveResult{Order: order, Receipts: receipts, PutawayTasks: tasks, CrossDockAllocations: crossDocked}
+			return nil
+		})
+	})
+	if err != nil {
+		s.logger.Warn("Inbound receipt failed", zap.Uint("order_id", id), zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.Info("Inbound receipt posted",
+		zap.Uint("order_id", id),
+		zap.String("received_by", input.ReceivedBy),
+		zap.Int("receipt_count", len(result.Receipts)),
+		zap.Int("cross_dock_allocations", len(result.CrossDockAllocations)),
+	)
+	return result, nil
+}
+
+// matchReceiptLines 将收货行匹配到单据行并累加收货数量
+// 同一单据行可出现多次；返回的过账按物料、行号排序，使并发收货以相同顺序锁定库存行
+func matchReceiptLines(order *model.InboundOrder, inputs []ReceiveLineInput) ([]receiptPosting, error) {
+	byNo := make(map[int]*model.InboundOrderLine, len(order.Lines))
+	byMaterial := make(map[string][]*model.InboundOrderLine, len(order.Lines))
+	for i := range order.Lines {
+		line := &order.Lines[i]
+		byNo[line.LineNo] = line
+		byMaterial[line.MaterialCode] = append(byMaterial[line.MaterialCode], line)
+	}
+
+	postings := make([]receiptPosting, 0, len(inputs))
+	for _, in := range inputs {
+		if in.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: receipt quantity must be positive", ErrInvalidInput)
+		}
+		var line *model.InboundOrderLine
+		if in.LineNo != 0 {
+			line = byNo[in.LineNo]
+			if line == nil {
+				return nil, fmt.Errorf("%w: line %d is not on inbound order %s", ErrInvalidInput, in.LineNo, order.OrderNo)
+			}
+			if in.MaterialCode != "" && in.MaterialCode != line.MaterialCode {
+				return nil, fmt.Errorf("%w: line %d is for material %s, not %s", ErrInvalidInput, in.LineNo, line.MaterialCode, in.MaterialCode)
+			}
+		} else {
+			candidates := byMaterial[in.MaterialCode]
+			switch len(candidates) {
+			case 0:
+				return nil, fmt.Errorf("%w: material %s is not on inbound order %s", ErrInvalidInput, in.MaterialCode, order.OrderNo)
+			case 1:
+				line = candidates[0]
+			default:
+				return nil, fmt.Errorf("%w: material %s appears on several lines, line_no is required", ErrInvalidInput, in.MaterialCode)
+			}
+		}
+
+		limit := maxReceivable(line.ExpectedQuantity, order.OverReceiptPercent)
+		if line.ReceivedQuantity+in.Quantity > limit {
+			return nil, fmt.Errorf("%w: line %d would receive %d, exceeding the allowed %d (expected %d, over-receipt %.2f%%)",
+				ErrInvalidInput, line.LineNo, line.ReceivedQuantity+in.Quantity, limit, line.ExpectedQuantity, order.OverReceiptPercent)
+		}
+		line.ReceivedQuantity += in.Quantity
+		line.Status = receivedLineStatus(line)
+		postings = append(postings, receiptPosting{
+			line:            line,
+			lotNumber:       strings.TrimSpace(in.LotNumber),
+			manufactureDate: in.ManufactureDate,
+			expiryDate:      in.ExpiryDate,
+			quantity:        in.Quantity,
+			serialNumbers:   in.SerialNumbers,
+		})
+	}
+
+	sort.SliceStable(postings, func(i, j int) bool {
+		if postings[i].line.MaterialCode != postings[j].line.MaterialCode {
+			return postings[i].line.MaterialCode < postings[j].line.MaterialCode
+		}
+		if postings[i].line.LineNo != postings[j].line.LineNo {
+			return postings[i].line.LineNo < postings[j].line.LineNo
+		}
+		return postings[i].lotNumber < postings[j].lotNumber
+	})
+	return postings, nil
+}
+
+// maxReceivable 返回单据行允许收货的最大数量，超收部分向下取整
+func maxReceivable(expected int, overReceiptPercent float64) int {
+	return expected + int(math.Floor(float64(expected)*overReceiptPercent/100+1e-9))
+}
+
+// receivedLineStatus 根据已收数量返回单据行在收货过程中的状态
+func receivedLineStatus(line *model.InboundOrderLine) string {
+	switch {
+	case line.ReceivedQuantity > line.ExpectedQuantity:
+		return model.InboundLineStatusOver
+	case line.ReceivedQuantity == line.ExpectedQuantity:
+		return model.InboundLineStatusComplete
+	default:
+		return model.InboundLineStatusOpen
+	}
+}
+
+// CloseOrder 关闭入库单据
+func (s *inboundService) CloseOrder(id uint, operatorID, reason string) (*model.InboundOrder, error) {
+	if strings.TrimSpace(operatorID) == "" {
+		return nil, fmt.Errorf("%w: operator_id is required", ErrInvalidInput)
+	}
+
+	short := 0
+	order, err := s.finish(id, model.InboundOrderStatusClosed, operatorID, reason, func(tx *gorm.DB, order *model.InboundOrder) error {
+		if order.Status != model.InboundOrderStatusOpen && order.Status != model.InboundOrderStatusReceiving {
+			return fmt.Errorf("%w: inbound order %d is %s", ErrInvalidState, id, order.Status)
+		}
+		short = 0
+		for i := range order.Lines {
+			line := &order.Lines[i]
+			if line.ReceivedQuantity >= line.ExpectedQuantity {
+				continue
+			}
+			short++
+			line.Status = model.InboundLineStatusShort
+			if err := s.repo.UpdateLine(tx, line); err != nil {
+				return fmt.Errorf("failed to update inbound order line: %w", err)
+			}
+		}
+		if short > 0 && strings.TrimSpace(reason) == "" {
+			return fmt.Errorf("%w: reason is required to close inbound order %d with %d short lines", ErrInvalidInput, id, short)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Inbound order closed",
+		zap.Uint("order_id", id),
+		zap.String("operator_id", operatorID),
+		zap.Int("short_lines", short),
+	)
+	return order, nil
+}
+
+// CancelOrder 取消入库单据，已有收货的单据只能关闭
+func (s *inboundService) CancelOrder(id uint, operatorID, reason string) (*model.InboundOrder, error) {
+	if strings.TrimSpace(operatorID) == "" {
+		return nil, fmt.Errorf("%w: operator_id is required", ErrInvalidInput)
+	}
+
+	order, err := s.finish(id, model.InboundOrderStatusCancelled, operatorID, reason, func(tx *gorm.DB, order *model.InboundOrder) error {
+		if order.Status != model.InboundOrderStatusOpen {
+			return fmt.Errorf("%w: inbound order %d is %s, only open orders without receipts can be cancelled", ErrInvalidState, id, order.Status)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Inbound order cancelled", zap.Uint("order_id", id), zap.String("operator_id", operatorID))
+	return order, nil
+}
+
+// finish 在事务中锁定单据、执行 check 后将单据置为终态并记录关闭信息
+func (s *inboundService) finish(id uint, to, operatorID, reason string, check func(tx *gorm.DB, order *model.InboundOrder) error) (*model.InboundOrder, error) {
+	var result *model.InboundOrder
+	err := s.options.Retry.run(s.logger, "inbound_"+to, func() error {
+		return runInTransaction(s.repo, s.logger, "inbound_"+to, func(tx *gorm.DB) error {
+			order, err := s.repo.GetForUpdate(tx, id)
+			if err != nil {
+				return fmt.Errorf("failed to fetch inbound order: %w", err)
+			}
+			if order == nil {
+				return fmt.Errorf("%w: inbound order %d", ErrNotFound, id)
+			}
+			if err := check(tx, order); err != nil {
+				return err
+			}
+
+			now := time.Now()
+			order.Status = to
+			order.ClosedBy = operatorID
+			order.ClosedAt = &now
+			order.CloseReason = reason
+			if err := s.repo.UpdateStatus(tx, order); err != nil {
+				return fmt.Errorf("failed to update inbound order: %w", err)
+			}
+			result = order
+			return nil
+		})
+	})
+	if err != nil {
+		s.logger.Warn("Inbound order transition failed",
+			zap.Uint("order_id", id),
+			zap.String("target_status", to),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	return result, nil
+}
+
+// ListReceipts 查询入库单据的收货记录
+func (s *inboundService) ListReceipts(id uint) ([]model.InboundReceipt, error) {
+	if _, err := s.GetOrder(id); err != nil {
+		return nil, err
+	}
+	receipts, err := s.repo.ListReceipts(id)
+	if err != nil {
+		s.logger.Error("Failed to list inbound receipts", zap.Uint("order_id", id), zap.Error(err))
+		return nil, fmt.Errorf("failed to list receipts: %w", err)
+	}
+	return receipts, nil
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"wms/internal/model"
+)
+
+func TestMaxReceivable(t *testing.T) {
+	cases := []struct {
+		expected int
+		percent  float64
+		want     int
+	}{
+		{100, 0, 100},
+		{100, 10, 110},
+		{7, 10, 7},
+		{10, 10, 11},
+		{3, 33.34, 4},
+	}
+	for _, tc := range cases {
+		if got := maxReceivable(tc.expected, tc.percent); got != tc.want {
+			t.Errorf("maxReceivable(%d, %v) = %d, want %d", tc.expected, tc.percent, got, tc.want)
+		}
+	}
+}
+
+func TestMatchReceiptLines(t *testing.T) {
+	newOrder := func() *model.InboundOrder {
+		return &model.InboundOrder{
+			OrderNo:            "PO-1",
+			OverReceiptPercent: 10,
+			Lines: []model.InboundOrderLine{
+				{ID: 1, LineNo: 1, MaterialCode: "MAT-B", ExpectedQuantity: 10},
+				{ID: 2, LineNo: 2, MaterialCode: "MAT-A", ExpectedQuantity: 5, ReceivedQuantity: 2},
+				{ID: 3, LineNo: 3, MaterialCode: "MAT-C", ExpectedQuantity: 4},
+				{ID: 4, LineNo: 4, MaterialCode: "MAT-C", ExpectedQuantity: 4},
+			},
+		}
+	}
+
+	order := newOrder()
+	postings, err := matchReceiptLines(order, []ReceiveLineInput{
+		{MaterialCode: "MAT-B", Quantity: 6},
+		{LineNo: 2, Quantity: 3},
+		{LineNo: 1, Quantity: 5},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(postings) != 3 || postings[0].line.MaterialCode != "MAT-A" {
+		t.Fatalf("Expected postings sorted by material, got %+v", postings)
+	}
+	if order.Lines[0].ReceivedQuantity != 11 || order.Lines[0].Status != model.InboundLineStatusOver {
+		t.Errorf("Expected line 1 over-received to 11, got %d (%s)", order.Lines[0].ReceivedQuantity, order.Lines[0].Status)
+	}
+	if order.Lines[1].ReceivedQuantity != 5 || order.Lines[1].Status != model.InboundLineStatusComplete {
+		t.Errorf("Expected line 2 complete, got %d (%s)", order.Lines[1].ReceivedQuantity, order.Lines[1].Status)
+	}
+
+	rejected := []struct {
+		name   string
+		inputs []ReceiveLineInput
+	}{
+		{"beyond over-receipt allowance", []ReceiveLineInput{{LineNo: 1, Quantity: 12}}},
+		{"unknown material", []ReceiveLineInput{{MaterialCode: "MAT-X", Quantity: 1}}},
+		{"ambiguous material", []ReceiveLineInput{{MaterialCode: "MAT-C", Quantity: 1}}},
+		{"material does not match line", []ReceiveLineInput{{LineNo: 1, MaterialCode: "MAT-A", Quantity: 1}}},
+	}
+	for _, tc := range rejected {
+		if _, err := matchReceiptLines(newOrder(), tc.inputs); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("%s: expected ErrInvalidInput, got %v", tc.name, err)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+	"wms/internal/model"
+	"wms/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultCheckRecordPageSize 未指定分页大小时的默认值
+	defaultCheckRecordPageSize = 50
+	// maxCheckRecordPageSize 单页允许返回的最大记录数
+	maxCheckRecordPageSize = 200
+)
+
+// CheckRecordQuery 表示盘点记录列表查询参数
+type CheckRecordQuery struct {
+	MaterialCode   string
+	LocationCode   string
+	CheckerID      string
+	From           time.Time
+	To             time.Time
+	IsProcessed    *bool
+	ApprovalStatus string
+	StockTakeID    *uint
+	SortBy         string
+	SortDesc       bool
+	Cursor         string
+	Limit          int
+}
+
+// CheckRecordPage 表示一页盘点记录
+type CheckRecordPage struct {
+	Records    []model.InventoryCheckRecord
+	NextCursor string
+	HasMore    bool
+}
+
+// checkRecordCursor 是游标的序列化格式
+// 游标与排序方式绑定，更换排序字段或方向后旧游标失效
+type checkRecordCursor struct {
+	SortBy   string `json:"s"`
+	SortDesc bool   `json:"d"`
+	Value    string `json:"v"`
+	ID       uint   `json:"id"`
+}
+
+// GetCheckRecord 查询单条盘点记录
+func (s *inventoryService) GetCheckRecord(id uint) (*model.InventoryCheckRecord, error) {
+	record, err := s.repo.GetCheckRecordByID(id)
+	if err != nil {
+		s.logger.Error("Failed to fetch inventory check record",
+			zap.Uint("record_id", id),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to fetch check record: %w", err)
+	}
+	if record == nil {
+		return nil, fmt.Errorf("%w: check record %d", ErrNotFound, id)
+	}
+	return record, nil
+}
+
+// ListCheckRecords 按过滤条件以游标分页方式查询盘点记录
+func (s *inventoryService) ListCheckRecords(query CheckRecordQuery) (*CheckRecordPage, error) {
+	sortBy := repository.CheckRecordSortField(query.SortBy)
+	switch sortBy {
+	case "":
+		sortBy = repository.SortByCheckTime
+	case repository.SortByCheckTime, repository.SortByID, repository.SortByDifference:
+	default:
+		return nil, fmt.Errorf("%w: unsupported sort field %q", ErrInvalidInput, query.SortBy)
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultCheckRecordPageSize
+	}
+	if limit > maxCheckRecordPageSize {
+		limit = maxCheckRecordPageSize
+	}
+
+	if !query.From.IsZero() && !query.To.IsZero() && !query.From.Before(query.To) {
+		return nil, fmt.Errorf("%w: from must be earlier than to", ErrInvalidInput)
+	}
+
+	filter := repository.CheckRecordFilter{
+		MaterialCode:   query.MaterialCode,
+		LocationCode:   query.LocationCode,
+		CheckerID:      query.CheckerID,
+		From:           query.From,
+		To:             query.To,
+		IsProcessed:    query.IsProcessed,
+		ApprovalStatus: query.ApprovalStatus,
+		StockTakeID:    query.StockTakeID,
+		SortBy:         sortBy,
+		SortDesc:       query.SortDesc,
+		Limit:          limit + 1,
+	}
+
+	if query.Cursor != "" {
+		after, err := decodeCheckRecordCursor(query.Cursor, sortBy, query.SortDesc)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+		}
+		filter.After = after
+	}
+
+	records, err := s.repo.ListCheckRecords(filter)
+	if err != nil {
+		s.logger.Error("Failed to list inventory check records",
+			zap.String("material_code", query.MaterialCode),
+			zap.String("location_code", query.LocationCode),
+			zap.String("checker_id", query.CheckerID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to list check records: %w", err)
+	}
+
+	page := &CheckRecordPage{Records: records}
+	if len(records) > limit {
+		page.Records = records[:limit]
+		page.HasMore = true
+		page.NextCursor = encodeCheckRecordCursor(page.Records[limit-1], sortBy, query.SortDesc)
+	}
+	if page.Records == nil {
+		page.Records = []model.InventoryCheckRecord{}
+	}
+
+	return page, nil
+}
+
+// encodeCheckRecordCursor 根据记录生成指向其后一页的游标
+func encodeCheckRecordCursor(record model.InventoryCheckRecord, sortBy repository.CheckRecordSortField, desc bool) string {
+	cursor := checkRecordCursor{SortBy: string(sortBy), SortDesc: desc, ID: record.ID}
+	switch sortBy {
+	case repository.SortByCheckTime:
+		cursor.Value = record.CheckTime.Format(time.RFC3339Nano)
+	case repository.SortByDifference:
+		cursor.Value = strconv.Itoa(record.Difference)
+	}
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCheckRecordCursor 解析游标并校验其与当前排序方式一致
+func decodeCheckRecordCursor(raw string, sortBy repository.CheckRecordSortField, desc bool) (*repository.CheckRecordCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+	var cursor checkRecordCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+	if cursor.SortBy != string(sortBy) || cursor.SortDesc != desc {
+		return nil, fmt.Errorf("cursor does not match current sort order")
+	}
+
+	after := &repository.CheckRecordCursor{ID: cursor.ID}
+	switch sortBy {
+	case repository.SortByCheckTime:
+		t, err := time.Parse(time.RFC3339Nano, cursor.Value)
+		if err != nil {
+			return nil, fmt.Errorf("malformed cursor")
+		}
+		after.SortValue = t
+	case repository.SortByDifference:
+		v, err := strconv.Atoi(cursor.Value)
+		if err != nil {
+			return nil, fmt.Errorf("malformed cursor")
+		}
+		after.SortValue = v
+	}
+	return after, nil
+}
@@ -2,20 +2,33 @@ package service
 
 import (
 	"fmt"
+	"strconv"
 	"time"
 	"wms/internal/model"
 	"wms/internal/repository"
 	"wms/pkg/logger"
 
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 // InventoryCheckInput 表示盘点处理所需的输入数据
+// LotNumber 为所盘批次，启用批次管理的物料必须给出；ManufactureDate、ExpiryDate 为批次的生产日期与有效期，
+// 盘点到新批次时记入库存；库存已记录有效期时以库存记录为准；
+// SerialNumbers 为盘点到的序列号清单，启用序列号管理的物料必须给出（空清单表示实盘为 0），实盘数量取清单中的序列号数
 type InventoryCheckInput struct {
-	CheckerID      string `json:"checker_id"`
-	LocationCode   string `json:"location_code"`
-	MaterialCode   string `json:"material_code"`
-	ActualQuantity int    `json:"actual_quantity"`
+	CheckerID       string     `json:"checker_id"`
+	LocationCode    string     `json:"location_code"`
+	MaterialCode    string     `json:"material_code"`
+	LotNumber       string     `json:"lot_number,omitempty"`
+	ManufactureDate *time.Time `json:"manufacture_date,omitempty"`
+	ExpiryDate      *time.Time `json:"expiry_date,omitempty"`
+	ActualQuantity  int        `json:"actual_quantity"`
+	SerialNumbers   []string   `json:"serial_numbers,omitempty"`
+	StockTakeID     *uint      `json:"stock_take_id,omitempty"`
+	RecountTaskID   *uint      `json:"recount_task_id,omitempty"`
+	ReasonCode      string     `json:"reason_code,omitempty"`
+	Note            string     `json:"note,omitempty"`
 }
 
 // InventoryService 定义库存业务逻辑接口
@@ -24,31 +37,125 @@ type InventoryService interface {
 	// 在一个事务中依次执行以下步骤：
 	// 1. 获取指定库位的当前库存
 	// 2. 计算差异（difference = actual_quantity - stock_quantity）
-	// 3. 生成盘点记录
-	// 4. 将库存数量更新为实盘数量
-	ProcessInventoryCheck(input InventoryCheckInput) error
+	// 3. 按物料/库位匹配差异容差
+	// 4. 生成盘点记录
+	// 5. 差异在容差内时将库存数量更新为实盘数量并记录盘点调整流水；超出容差时记录待审批并生成复盘任务，不修改库存
+	// 指定 RecountTaskID 时为复盘：须由复盘链以外的盘点人完成，与链上任一盘点结果一致时差异自动批准；
+	// 指定 StockTakeID 时盘点计入该盘点单，差异在盘点单过账时才调整库存；
+	// 盲盘盘点单以盘点任务冻结时点的库存快照代替当前库存计算差异；
+	// ReasonCode 须为启用且适用于差异方向的原因代码，差异超过 ReasonRequiredAbove 时必须给出
+	ProcessInventoryCheck(input InventoryCheckInput) (*model.InventoryCheckRecord, error)
 
 	// ProcessBatchInventoryCheck 处理多条盘点任务
-	// 每条盘点都使用独立事务单独处理
-	ProcessBatchInventoryCheck(inputs []InventoryCheckInput) []error
+	// BatchModePerItem 下每条盘点使用独立事务单独处理；
+	// BatchModeAtomic 下所有盘点共用一个事务，任意一条失败则整批回滚
+	ProcessBatchInventoryCheck(inputs []InventoryCheckInput, mode BatchMode) []InventoryCheckResult
+
+	// GetCheckRecord 查询单条盘点记录，不存在时返回 ErrNotFound
+	GetCheckRecord(id uint) (*model.InventoryCheckRecord, error)
+
+	// ListCheckRecords 按过滤条件以游标分页方式查询盘点记录
+	ListCheckRecords(query CheckRecordQuery) (*CheckRecordPage, error)
+
+	// ListPendingApprovals 返回待主管审批的盘点记录（按盘点时间升序）
+	ListPendingApprovals() ([]model.InventoryCheckRecord, error)
+
+	// ApproveVariance 批准超出容差的盘点差异，并将差异过账至当前库存
+	ApproveVariance(recordID uint, input ApprovalInput) (*model.InventoryCheckRecord, error)
+
+	// RejectVariance 驳回超出容差的盘点差异，库存保持不变
+	RejectVariance(recordID uint, input ApprovalInput) (*model.InventoryCheckRecord, error)
+
+	// ListRecountTasks 查询复盘任务，status 与 assignedTo 为空时不过滤
+	ListRecountTasks(status, assignedTo string) ([]model.RecountTask, error)
+
+	// AssignRecountTask 将未完成的复盘任务指派给未参与过该复盘链的盘点人
+	AssignRecountTask(taskID uint, checkerID string) (*model.RecountTask, error)
+
+	// GetRecordChain 查询盘点记录所在复盘链上的全部记录，不存在时返回 ErrNotFound
+	GetRecordChain(recordID uint) ([]model.InventoryCheckRecord, error)
+
+	// VarianceReport 按原因代码、过账类别、物料、库位汇总时间窗口内已生效的盘点差异
+	VarianceReport(query VarianceReportQuery) (*VarianceReport, error)
+}
+
+// ApprovalInput 表示差异审批操作的输入数据
+type ApprovalInput struct {
+	ApproverID string
+	Comment    string
+}
+
+// BatchMode 表示批量盘点的事务模式
+type BatchMode string
+
+const (
+	// BatchModePerItem 每条盘点独立提交（默认模式）
+	BatchModePerItem BatchMode = "per_item"
+	// BatchModeAtomic 整批盘点要么全部成功，要么全部回滚
+	BatchModeAtomic BatchMode = "atomic"
+)
+
+// BatchItemStatus 表示批量盘点中单条记录的处理状态
+type BatchItemStatus string
+
+const (
+	// BatchItemSucceeded 盘点已成功处理并提交
+	BatchItemSucceeded BatchItemStatus = "success"
+	// BatchItemFailed 盘点处理失败
+	BatchItemFailed BatchItemStatus = "failed"
+	// BatchItemRolledBack 盘点本身处理成功，但因整批回滚未生效
+	BatchItemRolledBack BatchItemStatus = "rolled_back"
+	// BatchItemSkipped 因整批已失败而未被处理
+	BatchItemSkipped BatchItemStatus = "skipped"
+)
+
+// InventoryCheckResult 表示批量盘点中单条记录的处理结果
+type InventoryCheckResult struct {
+	Status BatchItemStatus
+	Record *model.InventoryCheckRecord
+	Err    error
 }
 
 // inventoryService 是 InventoryService 的具体实现
 type inventoryService struct {
-	repo   repository.InventoryCheckRepository
-	logger *logger.Logger
+	repo           repository.InventoryCheckRepository
+	ledger         *stockLedger
+	replenisher    *replenisher
+	toleranceRepo  repository.VarianceToleranceRepository
+	stockTakeRepo  repository.StockTakeRepository
+	cycleCountRepo repository.CycleCountRepository
+	reasonRepo     repository.VarianceReasonRepository
+	options        InventoryOptions
+	logger         *logger.Logger
+}
+
+// InventoryOptions 表示库存盘点服务的可配置项
+type InventoryOptions struct {
+	// DefaultTolerance 在没有匹配的容差配置时生效
+	DefaultTolerance Tolerance
+	// Retry 事务遇到序列化失败或死锁时的重试策略
+	Retry RetryPolicy
+	// ReasonRequiredAbove 绝对差异超过该值的盘点必须给出原因代码，为空时原因始终可选
+	ReasonRequiredAbove *int
 }
 
 // NewInventoryService 创建一个新的 InventoryService 实例
-func NewInventoryService(repo repository.InventoryCheckRepository, log *logger.Logger) InventoryService {
+func NewInventoryService(repo repository.InventoryCheckRepository, stockRepo repository.StockRepository, toleranceRepo repository.VarianceToleranceRepository, stockTakeRepo repository.StockTakeRepository, cycleCountRepo repository.CycleCountRepository, reasonRepo repository.VarianceReasonRepository, replenishmentRepo repository.ReplenishmentRepository, options InventoryOptions, log *logger.Logger) InventoryService {
 	return &inventoryService{
-		repo:   repo,
-		logger: log,
+		repo:           repo,
+		ledger:         newStockLedger(stockRepo),
+		replenisher:    newReplenisher(replenishmentRepo, stockRepo),
+		toleranceRepo:  toleranceRepo,
+		stockTakeRepo:  stockTakeRepo,
+		cycleCountRepo: cycleCountRepo,
+		reasonRepo:     reasonRepo,
+		options:        options,
+		logger:         log,
 	}
 }
 
 // ProcessInventoryCheck 在事务安全前提下处理单条盘点操作
-func (s *inventoryService) ProcessInventoryCheck(input InventoryCheckInput) error {
+func (s *inventoryService) ProcessInventoryCheck(input InventoryCheckInput) (*model.InventoryCheckRecord, error) {
 	// 输入校验
 	if err := s.validateInput(input); err != nil {
 		s.logger.Error("Invalid inventory check input",
@@ -57,7 +164,7 @@ func (s *inventoryService) ProcessInventoryCheck(input InventoryCheckInput) erro
 			zap.String("material_code", input.MaterialCode),
 			zap.Error(err),
 		)
-		return fmt.Errorf("input validation failed: %w", err)
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
 	}
 
 	s.logger.Info("Starting inventory check process",
@@ -67,6 +174,31 @@ func (s *inventoryService) ProcessInventoryCheck(input InventoryCheckInput) erro
 		zap.Int("actual_quantity", input.ActualQuantity),
 	)
 
+	var checkRecord *model.InventoryCheckRecord
+	err := s.options.Retry.run(s.logger, "inventory_check", func() error {
+		var err error
+		checkRecord, err = s.processInventoryCheckOnce(input)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Inventory check completed successfully",
+		zap.String("checker_id", input.CheckerID),
+		zap.String("material_code", input.MaterialCode),
+		zap.String("location_code", input.LocationCode),
+		zap.Int("previous_quantity", checkRecord.StockQuantity),
+		zap.Int("actual_quantity", input.ActualQuantity),
+		zap.Int("variance", checkRecord.Difference),
+		zap.String("approval_status", checkRecord.ApprovalStatus),
+	)
+
+	return checkRecord, nil
+}
+
+// processInventoryCheckOnce 在独立事务中执行一次盘点处理
+func (s *inventoryService) processInventoryCheckOnce(input InventoryCheckInput) (checkRecord *model.InventoryCheckRecord, err error) {
 	// 开启事务
 	tx := s.repo.BeginTransaction()
 	if tx.Error != nil {
@@ -75,7 +207,7 @@ func (s *inventoryService) ProcessInventoryCheck(input InventoryCheckInput) erro
 			zap.String("location_code", input.LocationCode),
 			zap.Error(tx.Error),
 		)
-		return fmt.Errorf("failed to begin transaction: %w", tx.Error)
+		return nil, fmt.Errorf("failed to begin transaction: %w", tx.Error)
 	}
 
 	// 确保事务被正确处理
@@ -86,37 +218,109 @@ func (s *inventoryService) ProcessInventoryCheck(input InventoryCheckInput) erro
 				zap.String("material_code", input.MaterialCode),
 				zap.Any("panic", r),
 			)
+			checkRecord, err = nil, fmt.Errorf("panic during inventory check: %v", r)
 		}
 	}()
 
-	// 查询当前库存
-	stock, err := s.repo.GetStockByMaterialAndLocation(tx, input.MaterialCode, input.LocationCode)
+	checkRecord, err = s.processInventoryCheckTx(tx, input)
 	if err != nil {
 		s.repo.RollbackTransaction(tx)
-		s.logger.Error("Failed to fetch stock information",
+		return nil, err
+	}
+
+	// 提交事务
+	if err := s.repo.CommitTransaction(tx); err != nil {
+		s.repo.RollbackTransaction(tx)
+		s.logger.Error("Failed to commit transaction",
 			zap.String("material_code", input.MaterialCode),
 			zap.String("location_code", input.LocationCode),
 			zap.Error(err),
 		)
-		return fmt.Errorf("failed to fetch stock: %w", err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
-	// 若库存不存在则创建初始库存
-	var stockQuantity int
-	if stock == nil {
-		s.logger.Info("Stock record not found, creating new stock entry",
+	return checkRecord, nil
+}
+
+// processInventoryCheckTx 在调用方提供的事务中执行盘点的核心步骤
+// 出错时由调用方负责回滚事务
+func (s *inventoryService) processInventoryCheckTx(tx *gorm.DB, input InventoryCheckInput) (*model.InventoryCheckRecord, error) {
+	if input.SerialNumbers != nil {
+		input.ActualQuantity = len(input.SerialNumbers)
+	}
+
+	// 复盘先锁定复盘任务与被复盘的记录，并沿用任务所属的盘点单
+	var recount *recountContext
+	if input.RecountTaskID != nil {
+		var err error
+		recount, err = s.lockRecount(tx, &input)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 以共享锁读取盘点单，防止盘点期间盘点单被截止或过账
+	var stockTake *model.StockTake
+	if input.StockTakeID != nil {
+		var err error
+		stockTake, err = s.stockTakeRepo.GetForShare(tx, *input.StockTakeID)
+		if err != nil {
+			s.logger.Error("Failed to fetch stock take",
+				zap.Uint("stock_take_id", *input.StockTakeID),
+				zap.Error(err),
+			)
+			return nil, fmt.Errorf("failed to fetch stock take: %w", err)
+		}
+		if stockTake == nil {
+			return nil, fmt.Errorf("%w: stock take %d", ErrNotFound, *input.StockTakeID)
+		}
+	}
+
+	// 锁定当前批次的库存，保证差异基于最新库存计算且不会与并发变更交错
+	// 库存不存在时会创建数量为 0 的记录，视为系统库存为 0；启用批次管理的物料未给出批次时拒绝
+	stock, err := s.ledger.lockLot(tx, input.MaterialCode, input.LocationCode, input.LotNumber)
+	if err != nil {
+		s.logger.Error("Failed to fetch stock information",
 			zap.String("material_code", input.MaterialCode),
 			zap.String("location_code", input.LocationCode),
+			zap.String("lot_number", input.LotNumber),
+			zap.Error(err),
 		)
-		stockQuantity = 0
-		stock = &model.Stock{
-			MaterialCode: input.MaterialCode,
-			LocationCode: input.LocationCode,
-			Quantity:     input.ActualQuantity,
+		return nil, err
+	}
+	stockQuantity := stock.Quantity
+	manufactureDate := stock.ManufactureDate
+	if manufactureDate == nil {
+		manufactureDate = input.ManufactureDate
+	}
+	expiryDate := stock.ExpiryDate
+	if expiryDate == nil {
+		expiryDate = input.ExpiryDate
+	}
+	serialVariances, err := s.countSerials(tx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	// 盲盘以任务冻结时点的库存快照计算差异，冻结后的库存变动不影响差异
+	var countTask *model.CountTask
+	var countLine *model.CountTaskLine
+	if stockTake != nil {
+		if err := s.checkStockTakeCount(tx, stockTake, input, recount != nil); err != nil {
+			return nil, err
+		}
+		if stockTake.BlindCount && recount == nil {
+			countTask, countLine, err = s.blindCountLine(tx, stockTake, input)
+			if err != nil {
+				return nil, err
+			}
+			stockQuantity = countLine.FrozenQuantity
 		}
-	} else {
-		stockQuantity = stock.Quantity
-		stock.Quantity = input.ActualQuantity
+	}
+
+	// 复盘与首次盘点使用同一系统库存计算差异，各次盘点结果才可相互比较
+	if recount != nil {
+		stockQuantity = recount.root().StockQuantity
 	}
 
 	// 计算差异
@@ -130,89 +334,375 @@ func (s *inventoryService) ProcessInventoryCheck(input InventoryCheckInput) erro
 		zap.Int("difference", difference),
 	)
 
+	reason, err := s.resolveVarianceReason(tx, input.ReasonCode, difference)
+	if err != nil {
+		return nil, err
+	}
+
+	// 匹配差异容差
+	candidates, err := s.toleranceRepo.FindApplicable(tx, input.MaterialCode, input.LocationCode)
+	if err != nil {
+		s.logger.Error("Failed to fetch variance tolerances",
+			zap.String("material_code", input.MaterialCode),
+			zap.String("location_code", input.LocationCode),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to fetch tolerances: %w", err)
+	}
+	exceeded := selectTolerance(candidates, s.options.DefaultTolerance).Exceeded(stockQuantity, difference)
+
 	// 创建盘点记录
+	now := time.Now()
 	checkRecord := &model.InventoryCheckRecord{
-		CheckerID:      input.CheckerID,
-		LocationCode:   input.LocationCode,
-		MaterialCode:   input.MaterialCode,
-		ActualQuantity: input.ActualQuantity,
-		StockQuantity:  stockQuantity,
-		Difference:     difference,
-		CheckTime:      time.Now(),
-		IsProcessed:    true,
+		StockTakeID:     input.StockTakeID,
+		CheckerID:       input.CheckerID,
+		LocationCode:    input.LocationCode,
+		MaterialCode:    input.MaterialCode,
+		LotNumber:       input.LotNumber,
+		ManufactureDate: manufactureDate,
+		ExpiryDate:      expiryDate,
+		Expired:         model.ExpiredOn(expiryDate, now),
+		ActualQuantity:  input.ActualQuantity,
+		StockQuantity:   stockQuantity,
+		Difference:      difference,
+		Note:            input.Note,
+		CheckTime:       now,
+		ApprovalStatus:  model.ApprovalStatusAutoApproved,
+		SerialVariances: serialVariances,
+	}
+	if reason != nil {
+		checkRecord.ReasonCode = reason.Code
+		checkRecord.PostingCategory = reason.PostingCategory
+	}
+	if exceeded {
+		checkRecord.ApprovalStatus = model.ApprovalStatusPending
+	}
+	if checkRecord.Expired {
+		s.logger.Warn("Counted stock is past its expiry date",
+			zap.String("material_code", input.MaterialCode),
+			zap.String("location_code", input.LocationCode),
+			zap.String("lot_number", input.LotNumber),
+			zap.Time("expiry_date", *expiryDate),
+			zap.Int("actual_quantity", input.ActualQuantity),
+		)
 	}
 
+	// 复盘结果与复盘链上任一独立盘点一致时视为差异已确认，自动批准
+	if recount != nil {
+		checkRecord.ParentRecordID = &recount.head.ID
+		checkRecord.RootRecordID = &recount.root().ID
+		checkRecord.RecountSequence = recount.head.RecountSequence + 1
+		if agreed := findAgreeingCount(recount.chain, input.ActualQuantity); agreed != nil && exceeded {
+			checkRecord.ApprovalStatus = model.ApprovalStatusApproved
+			checkRecord.ApprovedBy = recountApprover
+			checkRecord.ApprovedAt = &now
+			checkRecord.ApprovalComment = fmt.Sprintf("independent counts agree with record %d", agreed.ID)
+		}
+	}
+	pending := checkRecord.ApprovalStatus == model.ApprovalStatusPending
+	checkRecord.IsProcessed = !pending && stockTake == nil
+
 	if err := s.repo.CreateCheckRecord(tx, checkRecord); err != nil {
-		s.repo.RollbackTransaction(tx)
 		s.logger.Error("Failed to create inventory check record",
 			zap.String("material_code", input.MaterialCode),
 			zap.String("location_code", input.LocationCode),
 			zap.Error(err),
 		)
-		return fmt.Errorf("failed to create check record: %w", err)
+		return nil, fmt.Errorf("failed to create check record: %w", err)
 	}
 
-	// 更新库存数量
-	if err := s.repo.UpdateStock(tx, stock); err != nil {
-		s.repo.RollbackTransaction(tx)
-		s.logger.Error("Failed to update stock quantity",
+	if countLine != nil {
+		if err := s.completeBlindCountLine(tx, countTask, countLine, checkRecord); err != nil {
+			return nil, err
+		}
+	}
+
+	// 任意盘点都视为完成该物料与库位的循环盘点
+	if err := s.cycleCountRepo.CompleteOpenTasks(tx, input.MaterialCode, input.LocationCode, checkRecord.ID, now); err != nil {
+		return nil, fmt.Errorf("failed to complete cycle count task: %w", err)
+	}
+
+	excluded := map[string]bool{input.CheckerID: true}
+	if recount != nil {
+		if err := s.completeRecount(tx, recount, checkRecord); err != nil {
+			return nil, err
+		}
+		excluded = chainCheckers(recount.chain)
+		excluded[input.CheckerID] = true
+	}
+
+	// 超出容差的盘点生成复盘任务并等待复盘或审批，不修改库存
+	if pending {
+		task, err := s.createRecountTask(tx, checkRecord, stockTake, excluded)
+		if err != nil {
+			return nil, err
+		}
+		s.logger.Warn("Inventory variance exceeds tolerance, recount requested",
+			zap.Uint("record_id", checkRecord.ID),
+			zap.Uint("recount_task_id", task.ID),
+			zap.String("assigned_to", task.AssignedTo),
 			zap.String("material_code", input.MaterialCode),
 			zap.String("location_code", input.LocationCode),
-			zap.Error(err),
+			zap.Int("stock_quantity", stockQuantity),
+			zap.Int("difference", difference),
 		)
-		return fmt.Errorf("failed to update stock: %w", err)
+		return checkRecord, nil
 	}
 
-	// 提交事务
-	if err := s.repo.CommitTransaction(tx); err != nil {
-		s.repo.RollbackTransaction(tx)
-		s.logger.Error("Failed to commit transaction",
+	// 盘点单内的盘点在盘点单过账时统一调整库存
+	if stockTake != nil {
+		return checkRecord, nil
+	}
+
+	// 按差异调整库存并记录流水
+	if _, err := s.ledger.apply(tx, stockTakeChange(checkRecord, input.CheckerID)); err != nil {
+		s.logger.Error("Failed to update stock quantity",
 			zap.String("material_code", input.MaterialCode),
 			zap.String("location_code", input.LocationCode),
 			zap.Error(err),
 		)
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		return nil, err
+	}
+	if err := s.replenishAfterCount(tx, checkRecord, input.CheckerID); err != nil {
+		return nil, err
 	}
 
-	s.logger.Info("Inventory check completed successfully",
-		zap.String("checker_id", input.CheckerID),
-		zap.String("material_code", input.MaterialCode),
-		zap.String("location_code", input.LocationCode),
-		zap.Int("previous_quantity", stockQuantity),
-		zap.Int("new_quantity", input.ActualQuantity),
-		zap.Int("variance", difference),
-	)
-
-	return nil
+	return checkRecord, nil
 }
 
-// ProcessBatchInventoryCheck 独立处理多条盘点任务
-func (s *inventoryService) ProcessBatchInventoryCheck(inputs []InventoryCheckInput) []error {
+// ProcessBatchInventoryCheck 按指定事务模式处理多条盘点任务
+func (s *inventoryService) ProcessBatchInventoryCheck(inputs []InventoryCheckInput, mode BatchMode) []InventoryCheckResult {
 	s.logger.Info("Starting batch inventory check process",
 		zap.Int("total_items", len(inputs)),
+		zap.String("mode", string(mode)),
 	)
 
-	errors := make([]error, len(inputs))
+	var results []InventoryCheckResult
+	if mode == BatchModeAtomic {
+		results = s.processBatchAtomic(inputs)
+	} else {
+		results = s.processBatchPerItem(inputs)
+	}
+
 	successCount := 0
 	failureCount := 0
-
-	for i, input := range inputs {
-		err := s.ProcessInventoryCheck(input)
-		errors[i] = err
-		if err != nil {
-			failureCount++
-		} else {
+	for _, result := range results {
+		if result.Status == BatchItemSucceeded {
 			successCount++
+		} else {
+			failureCount++
 		}
 	}
 
 	s.logger.Info("Batch inventory check completed",
 		zap.Int("total_items", len(inputs)),
+		zap.String("mode", string(mode)),
 		zap.Int("success_count", successCount),
 		zap.Int("failure_count", failureCount),
 	)
 
-	return errors
+	return results
+}
+
+// processBatchPerItem 使用独立事务逐条处理盘点
+func (s *inventoryService) processBatchPerItem(inputs []InventoryCheckInput) []InventoryCheckResult {
+	results := make([]InventoryCheckResult, len(inputs))
+	for i, input := range inputs {
+		record, err := s.ProcessInventoryCheck(input)
+		if err != nil {
+			results[i] = InventoryCheckResult{Status: BatchItemFailed, Err: err}
+			continue
+		}
+		results[i] = InventoryCheckResult{Status: BatchItemSucceeded, Record: record}
+	}
+	return results
+}
+
+// processBatchAtomic 在单个事务中处理全部盘点
+// 任意一条失败时回滚整个事务，之前成功的记录标记为 rolled_back，之后的记录标记为 skipped
+func (s *inventoryService) processBatchAtomic(inputs []InventoryCheckInput) []InventoryCheckResult {
+	results := make([]InventoryCheckResult, len(inputs))
+	for i := range results {
+		results[i].Status = BatchItemSkipped
+	}
+
+	// 先整体校验，避免无效数据导致开启无意义的事务
+	invalid := false
+	for i, input := range inputs {
+		if err := s.validateInput(input); err != nil {
+			results[i] = InventoryCheckResult{Status: BatchItemFailed, Err: fmt.Errorf("%w: %v", ErrInvalidInput, err)}
+			invalid = true
+		}
+	}
+	if invalid {
+		s.logger.Warn("Atomic batch rejected due to invalid items",
+			zap.Int("total_items", len(inputs)),
+		)
+		return results
+	}
+
+	err := s.options.Retry.run(s.logger, "inventory_check_batch", func() error {
+		var err error
+		results, err = s.processBatchAtomicOnce(inputs)
+		return err
+	})
+	if err != nil {
+		s.logger.Warn("Atomic batch rolled back", zap.Error(err))
+	}
+	return results
+}
+
+// processBatchAtomicOnce 在单个事务中尝试处理整批盘点
+// 返回的 error 为导致整批回滚的错误，供重试策略判断是否可重试
+func (s *inventoryService) processBatchAtomicOnce(inputs []InventoryCheckInput) (results []InventoryCheckResult, err error) {
+	results = make([]InventoryCheckResult, len(inputs))
+	for i := range results {
+		results[i].Status = BatchItemSkipped
+	}
+
+	failAll := func(err error) {
+		for i := range results {
+			results[i] = InventoryCheckResult{Status: BatchItemFailed, Err: err}
+		}
+	}
+
+	tx := s.repo.BeginTransaction()
+	if tx.Error != nil {
+		s.logger.Error("Failed to begin batch transaction", zap.Error(tx.Error))
+		err = fmt.Errorf("failed to begin transaction: %w", tx.Error)
+		failAll(err)
+		return results, err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			s.repo.RollbackTransaction(tx)
+			s.logger.Error("Panic during batch inventory check, transaction rolled back",
+				zap.Any("panic", r),
+			)
+			err = fmt.Errorf("panic during batch inventory check: %v", r)
+			failAll(err)
+		}
+	}()
+
+	for i, input := range inputs {
+		record, err := s.processInventoryCheckTx(tx, input)
+		if err != nil {
+			s.repo.RollbackTransaction(tx)
+			s.logger.Warn("Atomic batch item failed",
+				zap.Int("failed_index", i),
+				zap.String("material_code", input.MaterialCode),
+				zap.String("location_code", input.LocationCode),
+				zap.Error(err),
+			)
+			for j := 0; j < i; j++ {
+				results[j] = InventoryCheckResult{Status: BatchItemRolledBack}
+			}
+			results[i] = InventoryCheckResult{Status: BatchItemFailed, Err: err}
+			return results, err
+		}
+		results[i] = InventoryCheckResult{Status: BatchItemSucceeded, Record: record}
+	}
+
+	if err := s.repo.CommitTransaction(tx); err != nil {
+		s.repo.RollbackTransaction(tx)
+		s.logger.Error("Failed to commit batch transaction", zap.Error(err))
+		err = fmt.Errorf("failed to commit transaction: %w", err)
+		failAll(err)
+		return results, err
+	}
+
+	return results, nil
+}
+
+// stockTakeChange 根据盘点记录构造盘点调整的库存变动
+func stockTakeChange(record *model.InventoryCheckRecord, operatorID string) StockChange {
+	return StockChange{
+		MaterialCode:    record.MaterialCode,
+		LocationCode:    record.LocationCode,
+		LotNumber:       record.LotNumber,
+		ManufactureDate: record.ManufactureDate,
+		ExpiryDate:      record.ExpiryDate,
+		SerialNumbers:   record.SerialsByVariance(model.SerialVarianceUnexpected),
+		MissingSerials:  record.SerialsByVariance(model.SerialVarianceMissing),
+		Delta:           record.Difference,
+		Counted:         true,
+		MovementType:    model.MovementTypeStockTakeAdjustment,
+		ReferenceType:   model.ReferenceTypeInventoryCheck,
+		ReferenceID:     strconv.FormatUint(uint64(record.ID), 10),
+		ReasonCode:      record.ReasonCode,
+		OperatorID:      operatorID,
+	}
+}
+
+// countSerials 比较盘点到的序列号清单与库位批次上登记的序列号，返回序列号差异
+// 启用序列号管理的物料未给出清单且实盘数量不为 0 时返回 ErrSerialRequired，未启用的物料给出清单时返回 ErrInvalidInput；
+// 盘点到的序列号在其他库位或批次上在库时返回 ErrSerialMismatch
+func (s *inventoryService) countSerials(tx *gorm.DB, input InventoryCheckInput) ([]model.InventoryCheckSerial, error) {
+	tracked, err := s.ledger.serialTracked(tx, input.MaterialCode)
+	if err != nil {
+		return nil, err
+	}
+	if !tracked {
+		if len(input.SerialNumbers) > 0 {
+			return nil, fmt.Errorf("%w: material %s is not serial tracked", ErrInvalidInput, input.MaterialCode)
+		}
+		return nil, nil
+	}
+	if input.SerialNumbers == nil && input.ActualQuantity > 0 {
+		return nil, fmt.Errorf("%w: %s must be counted by serial number", ErrSerialRequired, input.MaterialCode)
+	}
+	if err := checkSerialList(input.SerialNumbers); err != nil {
+		return nil, err
+	}
+
+	registered, err := s.ledger.registeredSerials(tx, input.MaterialCode, input.LocationCode, input.LotNumber)
+	if err != nil {
+		return nil, err
+	}
+	missing, unexpected := compareSerials(registered, input.SerialNumbers)
+	if err := s.ledger.checkCountedSerials(tx, input.MaterialCode, input.LocationCode, input.LotNumber, unexpected); err != nil {
+		return nil, err
+	}
+	if len(missing) > 0 || len(unexpected) > 0 {
+		s.logger.Warn("Serial numbers differ from stock records",
+			zap.String("material_code", input.MaterialCode),
+			zap.String("location_code", input.LocationCode),
+			zap.String("lot_number", input.LotNumber),
+			zap.Strings("missing", missing),
+			zap.Strings("unexpected", unexpected),
+		)
+	}
+	return serialVarianceRecords(missing, unexpected), nil
+}
+
+// serialVarianceRecords 将缺失与多出的序列号转换为盘点记录的序列号差异
+func serialVarianceRecords(missing, unexpected []string) []model.InventoryCheckSerial {
+	variances := make([]model.InventoryCheckSerial, 0, len(missing)+len(unexpected))
+	for _, number := range missing {
+		variances = append(variances, model.InventoryCheckSerial{SerialNumber: number, Variance: model.SerialVarianceMissing})
+	}
+	for _, number := range unexpected {
+		variances = append(variances, model.InventoryCheckSerial{SerialNumber: number, Variance: model.SerialVarianceUnexpected})
+	}
+	return variances
+}
+
+// replenishAfterCount 盘点调整过账后检查拣货位的补货水位，低于最小水位时生成补货任务
+func (s *inventoryService) replenishAfterCount(tx *gorm.DB, record *model.InventoryCheckRecord, operatorID string) error {
+	tasks, err := s.replenisher.check(tx, record.MaterialCode, record.LocationCode, model.ReplenishmentTriggerStockTake, operatorID)
+	if err != nil {
+		return err
+	}
+	if len(tasks) > 0 {
+		s.logger.Info("Replenishment raised after stock take adjustment",
+			zap.Uint("record_id", record.ID),
+			zap.String("material_code", record.MaterialCode),
+			zap.String("location_code", record.LocationCode),
+			zap.Int("task_count", len(tasks)),
+		)
+	}
+	return nil
 }
 
 // validateInput 校验盘点输入参数
@@ -229,5 +719,8 @@ func (s *inventoryService) validateInput(input InventoryCheckInput) error {
 	if input.ActualQuantity < 0 {
 		return fmt.Errorf("actual_quantity cannot be negative: %d", input.ActualQuantity)
 	}
+	if input.SerialNumbers != nil && input.ActualQuantity != 0 && input.ActualQuantity != len(input.SerialNumbers) {
+		return fmt.Errorf("actual_quantity %d does not match %d serial numbers", input.ActualQuantity, len(input.SerialNumbers))
+	}
 	return nil
 }
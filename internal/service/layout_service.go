@@ -0,0 +1,203 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"wms/internal/model"
+	"wms/internal/repository"
+	"wms/internal/routing"
+	"wms/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// LayoutInput 表示更新仓库布局设置的输入
+type LayoutInput struct {
+	DepotX          float64
+	DepotY          float64
+	FrontAisleY     float64
+	BackAisleY      float64
+	RoutingStrategy string
+	UpdatedBy       string
+}
+
+// LayoutEdgeInput 表示新增或更新行走图通路的输入，通路为双向，两端节点顺序无关
+type LayoutEdgeInput struct {
+	FromNode string
+	ToNode   string
+	Distance float64
+}
+
+// RouteInput 表示规划库位行走路线的输入，Strategy 为空时使用布局的默认策略
+type RouteInput struct {
+	LocationCodes []string
+	Strategy      string
+}
+
+// LayoutService 定义仓库布局、行走图与路线规划的业务接口
+type LayoutService interface {
+	// GetLayout 查询布局设置，尚未配置时返回默认设置
+	GetLayout() (*model.WarehouseLayout, error)
+
+	// UpdateLayout 更新布局设置
+	UpdateLayout(input LayoutInput) (*model.WarehouseLayout, error)
+
+	// ListEdges 查询行走图的全部通路
+	ListEdges() ([]model.LayoutEdge, error)
+
+	// UpsertEdge 按两端节点新增或更新通路
+	UpsertEdge(input LayoutEdgeInput) (*model.LayoutEdge, error)
+
+	// DeleteEdge 删除通路，不存在时返回 ErrNotFound
+	DeleteEdge(id uint) error
+
+	// PlanRoute 按布局为一组库位规划行走顺序，不创建任何任务
+	PlanRoute(input RouteInput) (*RoutePlan, error)
+}
+
+// layoutService 是 LayoutService 的具体实现
+type layoutService struct {
+	repo   repository.LayoutRepository
+	router *taskRouter
+	logger *logger.Logger
+}
+
+// NewLayoutService 创建新的 LayoutService 实例
+func NewLayoutService(repo repository.LayoutRepository, locationRepo repository.LocationRepository, log *logger.Logger) LayoutService {
+	return &layoutService{
+		repo:   repo,
+		router: newTaskRouter(repo, locationRepo),
+		logger: log,
+	}
+}
+
+// GetLayout 查询布局设置
+func (s *layoutService) GetLayout() (*model.WarehouseLayout, error) {
+	var layout *model.WarehouseLayout
+	err := runInTransaction(s.repo, s.logger, "layout_get", func(tx *gorm.DB) error {
+		var err error
+		layout, err = s.router.layout(tx)
+		return err
+	})
+	if err != nil {
+		s.logger.Error("Failed to fetch warehouse layout", zap.Error(err))
+		return nil, err
+	}
+	return layout, nil
+}
+
+// UpdateLayout 校验并保存布局设置
+func (s *layoutService) UpdateLayout(input LayoutInput) (*model.WarehouseLayout, error) {
+	if !routing.ValidStrategy(input.RoutingStrategy) {
+		return nil, fmt.Errorf("%w: unsupported routing strategy %q", ErrInvalidInput, input.RoutingStrategy)
+	}
+
+	layout := &model.WarehouseLayout{
+		DepotX:          input.DepotX,
+		DepotY:          input.DepotY,
+		FrontAisleY:     input.FrontAisleY,
+		BackAisleY:      input.BackAisleY,
+		RoutingStrategy: input.RoutingStrategy,
+		UpdatedBy:       input.UpdatedBy,
+	}
+	if err := s.repo.SaveLayout(layout); err != nil {
+		s.logger.Error("Failed to save warehouse layout", zap.Error(err))
+		return nil, fmt.Errorf("failed to save warehouse layout: %w", err)
+	}
+
+	s.logger.Info("Warehouse layout updated",
+		zap.Float64("depot_x", layout.DepotX),
+		zap.Float64("depot_y", layout.DepotY),
+		zap.Float64("front_aisle_y", layout.FrontAisleY),
+		zap.Float64("back_aisle_y", layout.BackAisleY),
+		zap.String("routing_strategy", layout.RoutingStrategy),
+		zap.String("updated_by", input.UpdatedBy),
+	)
+	return layout, nil
+}
+
+// ListEdges 查询行走图的全部通路
+func (s *layoutService) ListEdges() ([]model.LayoutEdge, error) {
+	var edges []model.LayoutEdge
+	err := runInTransaction(s.repo, s.logger, "layout_edges", func(tx *gorm.DB) error {
+		var err error
+		edges, err = s.repo.ListEdges(tx)
+		return err
+	})
+	if err != nil {
+		s.logger.Error("Failed to list layout edges", zap.Error(err))
+		return nil, fmt.Errorf("failed to list layout edges: %w", err)
+	}
+	return edges, nil
+}
+
+// UpsertEdge 新增或更新通路
+// 两端节点按字典序存储，使 A-B 与 B-A 视为同一条通路
+func (s *layoutService) UpsertEdge(input LayoutEdgeInput) (*model.LayoutEdge, error) {
+	from := strings.TrimSpace(input.FromNode)
+	to := strings.TrimSpace(input.ToNode)
+	if from == "" || to == "" {
+		return nil, fmt.Errorf("%w: from_node and to_node are required", ErrInvalidInput)
+	}
+	if from == to {
+		return nil, fmt.Errorf("%w: an edge must connect two different nodes", ErrInvalidInput)
+	}
+	if input.Distance <= 0 || math.IsInf(input.Distance, 0) || math.IsNaN(input.Distance) {
+		return nil, fmt.Errorf("%w: distance must be positive", ErrInvalidInput)
+	}
+	if to < from {
+		from, to = to, from
+	}
+
+	edge := &model.LayoutEdge{FromNode: from, ToNode: to, Distance: input.Distance}
+	if err := s.repo.UpsertEdge(edge); err != nil {
+		s.logger.Error("Failed to save layout edge", zap.String("from_node", from), zap.String("to_node", to), zap.Error(err))
+		return nil, fmt.Errorf("failed to save layout edge: %w", err)
+	}
+
+	s.logger.Info("Layout edge saved",
+		zap.String("from_node", from),
+		zap.String("to_node", to),
+		zap.Float64("distance", edge.Distance),
+	)
+	return edge, nil
+}
+
+// DeleteEdge 删除通路
+func (s *layoutService) DeleteEdge(id uint) error {
+	deleted, err := s.repo.DeleteEdge(id)
+	if err != nil {
+		s.logger.Error("Failed to delete layout edge", zap.Uint("id", id), zap.Error(err))
+		return fmt.Errorf("failed to delete layout edge: %w", err)
+	}
+	if !deleted {
+		return fmt.Errorf("%w: layout edge %d", ErrNotFound, id)
+	}
+
+	s.logger.Info("Layout edge deleted", zap.Uint("id", id))
+	return nil
+}
+
+// PlanRoute 按布局为一组库位规划行走顺序
+func (s *layoutService) PlanRoute(input RouteInput) (*RoutePlan, error) {
+	if len(input.LocationCodes) == 0 {
+		return nil, fmt.Errorf("%w: location_codes is required", ErrInvalidInput)
+	}
+
+	var plan RoutePlan
+	err := runInTransaction(s.repo, s.logger, "layout_route", func(tx *gorm.DB) error {
+		route, err := s.router.load(tx, input.Strategy)
+		if err != nil {
+			return err
+		}
+		plan = route.route(input.LocationCodes)
+		return nil
+	})
+	if err != nil {
+		s.logger.Warn("Route planning failed", zap.String("strategy", input.Strategy), zap.Error(err))
+		return nil, err
+	}
+	return &plan, nil
+}
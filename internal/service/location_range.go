@@ -0,0 +1,139 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MaxGeneratedLocations 是一次批量生成允许的最大库位数
+const MaxGeneratedLocations = 5000
+
+// rangedLocation 表示按编码范围展开的一个库位
+// segments 为编码按 "-" 拆分后的各段，offsets 为各段在其范围内的序号（从 0 开始）
+type rangedLocation struct {
+	code     string
+	segments []string
+	offsets  []int
+}
+
+// segmentRange 表示编码中一段的取值范围
+type segmentRange struct {
+	values []string
+}
+
+// expandLocationRange 展开 A-01-01..A-20-05 形式的库位编码范围
+// from 与 to 的段数必须相同；每段要么相同，要么同为等宽数字（按数值递增并保留前导零），要么同为单个字母；
+// 结果按左侧段变化最慢的顺序排列，数量超过 MaxGeneratedLocations 时返回 ErrInvalidInput
+func expandLocationRange(from, to string) ([]rangedLocation, error) {
+	fromParts := strings.Split(strings.TrimSpace(from), "-")
+	toParts := strings.Split(strings.TrimSpace(to), "-")
+	if len(fromParts) != len(toParts) {
+		return nil, fmt.Errorf("%w: %s and %s have different numbers of segments", ErrInvalidInput, from, to)
+	}
+
+	ranges := make([]segmentRange, len(fromParts))
+	total := 1
+	for i := range fromParts {
+		r, err := parseSegmentRange(fromParts[i], toParts[i])
+		if err != nil {
+			return nil, err
+		}
+		ranges[i] = r
+		total *= len(r.values)
+		if total > MaxGeneratedLocations {
+			return nil, fmt.Errorf("%w: range %s..%s exceeds %d locations", ErrInvalidInput, from, to, MaxGeneratedLocations)
+		}
+	}
+
+	result := make([]rangedLocation, 0, total)
+	offsets := make([]int, len(ranges))
+	for {
+		segments := make([]string, len(ranges))
+		for i, r := range ranges {
+			segments[i] = r.values[offsets[i]]
+		}
+		result = append(result, rangedLocation{
+			code:     strings.Join(segments, "-"),
+			segments: segments,
+			offsets:  append([]int(nil), offsets...),
+		})
+
+		// 从最右侧的段开始进位
+		i := len(ranges) - 1
+		for ; i >= 0; i-- {
+			offsets[i]++
+			if offsets[i] < len(ranges[i].values) {
+				break
+			}
+			offsets[i] = 0
+		}
+		if i < 0 {
+			return result, nil
+		}
+	}
+}
+
+// parseSegmentRange 解析编码中一段的起止值
+// 一段的取值超过 MaxGeneratedLocations 个时在展开前返回 ErrInvalidInput
+func parseSegmentRange(from, to string) (segmentRange, error) {
+	if from == "" || to == "" {
+		return segmentRange{}, fmt.Errorf("%w: location code segments cannot be empty", ErrInvalidInput)
+	}
+	if from == to {
+		return segmentRange{values: []string{from}}, nil
+	}
+
+	if isDigits(from) && isDigits(to) {
+		if len(from) != len(to) {
+			return segmentRange{}, fmt.Errorf("%w: numeric segments %s and %s must have the same width", ErrInvalidInput, from, to)
+		}
+		start, err := strconv.Atoi(from)
+		if err != nil {
+			return segmentRange{}, fmt.Errorf("%w: numeric segment %s is out of range", ErrInvalidInput, from)
+		}
+		end, err := strconv.Atoi(to)
+		if err != nil {
+			return segmentRange{}, fmt.Errorf("%w: numeric segment %s is out of range", ErrInvalidInput, to)
+		}
+		if start > end {
+			return segmentRange{}, fmt.Errorf("%w: segment %s is after %s", ErrInvalidInput, from, to)
+		}
+		if end-start >= MaxGeneratedLocations {
+			return segmentRange{}, fmt.Errorf("%w: segment range %s..%s exceeds %d locations", ErrInvalidInput, from, to, MaxGeneratedLocations)
+		}
+		values := make([]string, 0, end-start+1)
+		for n := start; n <= end; n++ {
+			values = append(values, fmt.Sprintf("%0*d", len(from), n))
+		}
+		return segmentRange{values: values}, nil
+	}
+
+	if isLetter(from) && isLetter(to) {
+		if from[0] > to[0] {
+			return segmentRange{}, fmt.Errorf("%w: segment %s is after %s", ErrInvalidInput, from, to)
+		}
+		values := make([]string, 0, to[0]-from[0]+1)
+		for c := from[0]; c <= to[0]; c++ {
+			values = append(values, string(c))
+		}
+		return segmentRange{values: values}, nil
+	}
+
+	return segmentRange{}, fmt.Errorf("%w: cannot build a range from %s to %s, use equal-width numbers or single letters", ErrInvalidInput, from, to)
+}
+
+// isDigits 判断字符串是否只由数字组成
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// isLetter 判断字符串是否为单个英文字母
+func isLetter(s string) bool {
+	return len(s) == 1 && (s[0] >= 'A' && s[0] <= 'Z' || s[0] >= 'a' && s[0] <= 'z')
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestExpandLocationRange(t *testing.T) {
+	locations, err := expandLocationRange("A-01-01", "B-02-03")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(locations) != 12 {
+		t.Fatalf("Expected 2*2*3 = 12 locations, got %d", len(locations))
+	}
+	if locations[0].code != "A-01-01" || locations[1].code != "A-01-02" || locations[3].code != "A-02-01" || locations[11].code != "B-02-03" {
+		t.Errorf("Unexpected order: %s, %s, %s, %s", locations[0].code, locations[1].code, locations[3].code, locations[11].code)
+	}
+	last := locations[11]
+	if last.offsets[0] != 1 || last.offsets[1] != 1 || last.offsets[2] != 2 || last.segments[1] != "02" {
+		t.Errorf("Unexpected segments/offsets for %s: %v %v", last.code, last.segments, last.offsets)
+	}
+
+	locations, err = expandLocationRange("A-09", "A-10")
+	if err != nil || len(locations) != 2 || locations[1].code != "A-10" {
+		t.Errorf("Expected zero-padded carry A-09, A-10, got %v, %v", locations, err)
+	}
+}
+
+func TestExpandLocationRange_Invalid(t *testing.T) {
+	cases := [][2]string{
+		{"A-01-01", "A-01"},
+		{"A-1", "A-10"},
+		{"A-05", "A-01"},
+		{"AA-01", "AB-01"},
+		{"A--01", "A--02"},
+		{"A-0001-01", "A-9999-99"},
+		// 单段超出上限时在展开前拒绝，不会先生成全部取值
+		{"A-0000000", "A-9999999"},
+		{"A-0000000000", "A-9999999999"},
+		// 超出整数范围的数字段
+		{"A-00000000000000000000", "A-99999999999999999999"},
+		{"A-99999999999999999998", "A-99999999999999999999"},
+	}
+	for _, c := range cases {
+		if _, err := expandLocationRange(c[0], c[1]); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("Expected ErrInvalidInput for %s..%s, got %v", c[0], c[1], err)
+		}
+	}
+}
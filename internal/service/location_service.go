@@ -0,0 +1,319 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"wms/internal/model"
+	"wms/internal/repository"
+	"wms/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// LocationInput 表示新增或更新库位的输入
+// Type 为空时视为存储位；PutawayEnabled、Active 为空时视为允许上架、启用；冻结库位（Blocked）须给出原因；
+// Warehouse、Zone、Aisle、Rack、Bin 为库位层级；Aisle、Bay、Level、X、Y 为库位在仓库布局中的位置
+type LocationInput struct {
+	Code           string
+	Warehouse      string
+	Zone           string
+	Type           string
+	Sequence       int
+	Capacity       int
+	MaxVolumeCm3   float64
+	MaxWeightKg    float64
+	MaxSkus        int
+	PutawayEnabled *bool
+	Blocked        bool
+	BlockReason    string
+	Active         *bool
+	Aisle          string
+	Rack           string
+	Bin            string
+	Bay            int
+	Level          int
+	X              float64
+	Y              float64
+}
+
+// LocationRangeInput 表示按编码范围批量生成库位的输入
+// From、To 为 A-01-01、A-20-05 形式的起止编码；生成的库位共用层级、类型、容量与上架设置，
+// 编码最后三段依次作为巷道、货架、货位（两段时为巷道、货位），Sequence 从 SequenceStart 起依次递增；
+// AisleSpacing、BaySpacing 大于 0 时按巷道与货架在范围中的序号从 (OriginX, OriginY) 起计算布局坐标；
+// 已存在的编码保持不变；DryRun 为 true 时只返回将生成的编码
+type LocationRangeInput struct {
+	From           string
+	To             string
+	Warehouse      string
+	Zone           string
+	Type           string
+	Capacity       int
+	MaxVolumeCm3   float64
+	MaxWeightKg    float64
+	MaxSkus        int
+	PutawayEnabled *bool
+	SequenceStart  int
+	OriginX        float64
+	OriginY        float64
+	AisleSpacing   float64
+	BaySpacing     float64
+	DryRun         bool
+}
+
+// LocationRangeResult 表示批量生成库位的结果，Skipped 为已存在而未改动的库位数
+type LocationRangeResult struct {
+	Total   int      `json:"total"`
+	Created int64    `json:"created"`
+	Skipped int64    `json:"skipped"`
+	DryRun  bool     `json:"dry_run"`
+	Codes   []string `json:"codes"`
+}
+
+// LocationService 定义库位主数据的业务接口
+type LocationService interface {
+	// ListLocations 按过滤条件查询库位
+	ListLocations(filter repository.LocationFilter) ([]model.Location, error)
+
+	// GetLocation 按编码查询库位
+	GetLocation(code string) (*model.Location, error)
+
+	// UpsertLocation 按库位编码新增或更新库位
+	UpsertLocation(input LocationInput) (*model.Location, error)
+
+	// GenerateLocations 按编码范围批量生成库位
+	GenerateLocations(input LocationRangeInput) (*LocationRangeResult, error)
+
+	// RegisterKnownLocations 登记系统使用的暂存库位与库存中已出现的库位，已登记的库位保持不变
+	RegisterKnownLocations(staging []model.Location) (int64, error)
+}
+
+// locationService 是 LocationService 的具体实现
+type locationService struct {
+	repo   repository.LocationRepository
+	logger *logger.Logger
+}
+
+// NewLocationService 创建新的 LocationService 实例
+func NewLocationService(repo repository.LocationRepository, log *logger.Logger) LocationService {
+	return &locationService{
+		repo:   repo,
+		logger: log,
+	}
+}
+
+// ValidLocationType 判断库位类型是否有效
+func ValidLocationType(locationType string) bool {
+	switch locationType {
+	case model.LocationTypePickFace, model.LocationTypeReserve, model.LocationTypeDock,
+		model.LocationTypeQuarantine, model.LocationTypeVirtual:
+		return true
+	}
+	return false
+}
+
+// ListLocations 按过滤条件查询库位
+func (s *locationService) ListLocations(filter repository.LocationFilter) ([]model.Location, error) {
+	if filter.Type != "" && !ValidLocationType(filter.Type) {
+		return nil, fmt.Errorf("%w: unknown location type %s", ErrInvalidInput, filter.Type)
+	}
+	locations, err := s.repo.List(filter)
+	if err != nil {
+		s.logger.Error("Failed to list locations", zap.Error(err))
+		return nil, fmt.Errorf("failed to list locations: %w", err)
+	}
+	return locations, nil
+}
+
+// GetLocation 按编码查询库位
+func (s *locationService) GetLocation(code string) (*model.Location, error) {
+	location, err := s.repo.Get(code)
+	if err != nil {
+		s.logger.Error("Failed to fetch location", zap.String("code", code), zap.Error(err))
+		return nil, fmt.Errorf("failed to fetch location: %w", err)
+	}
+	if location == nil {
+		return nil, fmt.Errorf("%w: location %s", ErrNotFound, code)
+	}
+	return location, nil
+}
+
+// UpsertLocation 按库位编码新增或更新库位
+func (s *locationService) UpsertLocation(input LocationInput) (*model.Location, error) {
+	if input.Code == "" {
+		return nil, fmt.Errorf("%w: code is required", ErrInvalidInput)
+	}
+	if input.Type == "" {
+		input.Type = model.LocationTypeReserve
+	}
+	if err := validateLocationSettings(input.Type, input.Capacity, input.MaxVolumeCm3, input.MaxWeightKg, input.MaxSkus); err != nil {
+		return nil, err
+	}
+	if input.Bay < 0 || input.Level < 0 {
+		return nil, fmt.Errorf("%w: bay and level cannot be negative", ErrInvalidInput)
+	}
+	if input.Blocked && strings.TrimSpace(input.BlockReason) == "" {
+		return nil, fmt.Errorf("%w: block_reason is required to block a location", ErrInvalidInput)
+	}
+	if !input.Blocked {
+		input.BlockReason = ""
+	}
+
+	location := &model.Location{
+		Code:           input.Code,
+		Warehouse:      input.Warehouse,
+		Zone:           input.Zone,
+		Type:           input.Type,
+		Sequence:       input.Sequence,
+		Capacity:       input.Capacity,
+		MaxVolumeCm3:   input.MaxVolumeCm3,
+		MaxWeightKg:    input.MaxWeightKg,
+		MaxSkus:        input.MaxSkus,
+		PutawayEnabled: input.PutawayEnabled == nil || *input.PutawayEnabled,
+		Blocked:        input.Blocked,
+		BlockReason:    input.BlockReason,
+		Active:         input.Active == nil || *input.Active,
+		Aisle:          input.Aisle,
+		Rack:           input.Rack,
+		Bin:            input.Bin,
+		Bay:            input.Bay,
+		Level:          input.Level,
+		X:              input.X,
+		Y:              input.Y,
+	}
+	if err := s.repo.Upsert(location); err != nil {
+		s.logger.Error("Failed to upsert location", zap.String("code", input.Code), zap.Error(err))
+		return nil, fmt.Errorf("failed to save location: %w", err)
+	}
+
+	s.logger.Info("Location saved",
+		zap.String("code", location.Code),
+		zap.String("warehouse", location.Warehouse),
+		zap.String("zone", location.Zone),
+		zap.String("type", location.Type),
+		zap.Int("sequence", location.Sequence),
+		zap.Int("capacity", location.Capacity),
+		zap.Bool("putaway_enabled", location.PutawayEnabled),
+		zap.Bool("blocked", location.Blocked),
+		zap.Bool("active", location.Active),
+		zap.String("aisle", location.Aisle),
+	)
+	return location, nil
+}
+
+// GenerateLocations 按编码范围批量生成库位
+func (s *locationService) GenerateLocations(input LocationRangeInput) (*LocationRangeResult, error) {
+	if input.Type == "" {
+		input.Type = model.LocationTypeReserve
+	}
+	if err := validateLocationSettings(input.Type, input.Capacity, input.MaxVolumeCm3, input.MaxWeightKg, input.MaxSkus); err != nil {
+		return nil, err
+	}
+	if input.AisleSpacing < 0 || input.BaySpacing < 0 {
+		return nil, fmt.Errorf("%w: aisle_spacing and bay_spacing cannot be negative", ErrInvalidInput)
+	}
+	ranged, err := expandLocationRange(input.From, input.To)
+	if err != nil {
+		return nil, err
+	}
+
+	locations := make([]model.Location, 0, len(ranged))
+	codes := make([]string, 0, len(ranged))
+	for i, r := range ranged {
+		locations = append(locations, newRangedLocation(input, r, input.SequenceStart+i))
+		codes = append(codes, r.code)
+	}
+	result := &LocationRangeResult{Total: len(locations), DryRun: input.DryRun, Codes: codes}
+	if input.DryRun {
+		return result, nil
+	}
+
+	created, err := s.repo.CreateMissing(locations)
+	if err != nil {
+		s.logger.Error("Failed to generate locations", zap.String("from", input.From), zap.String("to", input.To), zap.Error(err))
+		return nil, fmt.Errorf("failed to generate locations: %w", err)
+	}
+	result.Created = created
+	result.Skipped = int64(len(locations)) - created
+
+	s.logger.Info("Locations generated",
+		zap.String("from", input.From),
+		zap.String("to", input.To),
+		zap.String("type", input.Type),
+		zap.Int("total", result.Total),
+		zap.Int64("created", result.Created),
+		zap.Int64("skipped", result.Skipped),
+	)
+	return result, nil
+}
+
+// RegisterKnownLocations 登记系统使用的暂存库位与库存中已出现的库位
+// 暂存库位按给定的类型与上架设置登记；库存中出现但未登记的库位补建为允许上架的存储位，
+// 启用库位校验前已有的库存不会因此无法作业
+func (s *locationService) RegisterKnownLocations(staging []model.Location) (int64, error) {
+	created, err := s.repo.CreateMissing(staging)
+	if err != nil {
+		return 0, fmt.Errorf("failed to register staging locations: %w", err)
+	}
+	stocked, err := s.repo.CreateMissingFromStocks()
+	if err != nil {
+		return 0, fmt.Errorf("failed to register stocked locations: %w", err)
+	}
+	if created+stocked > 0 {
+		s.logger.Warn("Registered locations without master data",
+			zap.Int64("staging", created),
+			zap.Int64("from_stock", stocked),
+		)
+	}
+	return created + stocked, nil
+}
+
+// validateLocationSettings 校验库位类型与容量设置
+func validateLocationSettings(locationType string, capacity int, maxVolume, maxWeight float64, maxSkus int) error {
+	if !ValidLocationType(locationType) {
+		return fmt.Errorf("%w: type must be one of pick_face, reserve, dock, quarantine, virtual, got %s", ErrInvalidInput, locationType)
+	}
+	if capacity < 0 || maxVolume < 0 || maxWeight < 0 || maxSkus < 0 {
+		return fmt.Errorf("%w: capacity limits cannot be negative", ErrInvalidInput)
+	}
+	return nil
+}
+
+// newRangedLocation 按批量生成的设置构造一个库位
+func newRangedLocation(input LocationRangeInput, r rangedLocation, sequence int) model.Location {
+	location := model.Location{
+		Code:           r.code,
+		Warehouse:      input.Warehouse,
+		Zone:           input.Zone,
+		Type:           input.Type,
+		Sequence:       sequence,
+		Capacity:       input.Capacity,
+		MaxVolumeCm3:   input.MaxVolumeCm3,
+		MaxWeightKg:    input.MaxWeightKg,
+		MaxSkus:        input.MaxSkus,
+		PutawayEnabled: input.PutawayEnabled == nil || *input.PutawayEnabled,
+		Active:         true,
+	}
+
+	n := len(r.segments)
+	aisle, rack := -1, -1
+	location.Bin = r.segments[n-1]
+	switch {
+	case n == 2:
+		aisle = 0
+	case n >= 3:
+		aisle, rack = n-3, n-2
+	}
+	if aisle >= 0 {
+		location.Aisle = r.segments[aisle]
+		location.X = input.OriginX + float64(r.offsets[aisle])*input.AisleSpacing
+		location.Y = input.OriginY
+	}
+	if rack >= 0 {
+		location.Rack = r.segments[rack]
+		location.Bay, _ = strconv.Atoi(location.Rack)
+		location.Y = input.OriginY + float64(r.offsets[rack])*input.BaySpacing
+	}
+	location.Level, _ = strconv.Atoi(location.Bin)
+	return location
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"testing"
+	"wms/internal/model"
+)
+
+func TestNewRangedLocation(t *testing.T) {
+	ranged, err := expandLocationRange("A-01-01", "B-20-05")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	input := LocationRangeInput{
+		Warehouse:    "WH1",
+		Type:         model.LocationTypePickFace,
+		Capacity:     50,
+		OriginX:      2,
+		OriginY:      1,
+		AisleSpacing: 3,
+		BaySpacing:   1.5,
+	}
+	// B-03-04：第 2 条巷道、第 3 个货架
+	r := ranged[1*20*5+2*5+3]
+	location := newRangedLocation(input, r, 7)
+	if location.Code != "B-03-04" || location.Aisle != "B" || location.Rack != "03" || location.Bin != "04" {
+		t.Errorf("Unexpected hierarchy: %+v", location)
+	}
+	if location.Bay != 3 || location.Level != 4 || location.Sequence != 7 {
+		t.Errorf("Expected bay 3, level 4, sequence 7, got %d, %d, %d", location.Bay, location.Level, location.Sequence)
+	}
+	if location.X != 5 || location.Y != 4 {
+		t.Errorf("Expected coordinates (5, 4), got (%v, %v)", location.X, location.Y)
+	}
+	if location.Warehouse != "WH1" || location.Type != model.LocationTypePickFace || location.Capacity != 50 || !location.PutawayEnabled || !location.Active {
+		t.Errorf("Expected shared settings to be applied, got %+v", location)
+	}
+
+	ranged, err = expandLocationRange("R1-01", "R1-03")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	location = newRangedLocation(LocationRangeInput{Type: model.LocationTypeReserve}, ranged[2], 0)
+	if location.Aisle != "R1" || location.Rack != "" || location.Bin != "03" || location.Level != 3 {
+		t.Errorf("Expected two-segment code to map to aisle and bin, got %+v", location)
+	}
+}
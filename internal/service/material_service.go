@@ -0,0 +1,350 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"wms/internal/model"
+	"wms/internal/repository"
+	"wms/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// MaxMaterialImportRows 是一次批量导入允许的最大物料行数
+const MaxMaterialImportRows = 1000
+
+// MaterialInput 表示新增或更新物料的输入，CrossDock 标记收货时优先越库的快速周转品，LotManaged 启用批次管理，
+// SerialTracked 启用序列号管理
+// BaseUnit 为空时使用 model.DefaultBaseUnit；Active 为空时视为启用；
+// Barcodes 为 nil 时保留物料原有条码，非 nil 时以其替换全部条码（空切片表示清空）
+type MaterialInput struct {
+	Code          string
+	Description   string
+	BaseUnit      string
+	Category      string
+	LengthCm      float64
+	WidthCm       float64
+	HeightCm      float64
+	WeightKg      float64
+	CrossDock     bool
+	LotManaged    bool
+	SerialTracked bool
+	Active        *bool
+	Barcodes      []string
+}
+
+// MaterialImportError 表示批量导入中一行物料的校验错误，Row 为从 0 开始的行序号
+type MaterialImportError struct {
+	Row     int    `json:"row"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// MaterialImportResult 表示批量导入的结果
+// Errors 非空时整批未写入，Created 与 Updated 均为 0
+type MaterialImportResult struct {
+	Total   int                   `json:"total"`
+	Created int                   `json:"created"`
+	Updated int                   `json:"updated"`
+	Errors  []MaterialImportError `json:"errors,omitempty"`
+}
+
+// MaterialService 定义物料主数据的业务接口
+type MaterialService interface {
+	// ListMaterials 按过滤条件查询物料
+	ListMaterials(filter repository.MaterialFilter) ([]model.Material, error)
+
+	// GetMaterial 按编码查询物料
+	GetMaterial(code string) (*model.Material, error)
+
+	// UpsertMaterial 按物料编码新增或更新物料
+	UpsertMaterial(input MaterialInput) (*model.Material, error)
+
+	// DeleteMaterial 删除没有在库或预留库存的物料
+	DeleteMaterial(code string) error
+
+	// ImportMaterials 批量新增或更新物料，所有行校验通过后在一个事务中写入，任意一行无效则整批不写入
+	ImportMaterials(inputs []MaterialInput) (*MaterialImportResult, error)
+
+	// RegisterStockedMaterials 为库存中已出现但尚未登记的物料编码补建主数据
+	RegisterStockedMaterials() (int64, error)
+}
+
+// materialService 是 MaterialService 的具体实现
+type materialService struct {
+	repo   repository.MaterialRepository
+	logger *logger.Logger
+}
+
+// NewMaterialService 创建新的 MaterialService 实例
+func NewMaterialService(repo repository.MaterialRepository, log *logger.Logger) MaterialService {
+	return &materialService{
+		repo:   repo,
+		logger: log,
+	}
+}
+
+// ListMaterials 按过滤条件查询物料
+func (s *materialService) ListMaterials(filter repository.MaterialFilter) ([]model.Material, error) {
+	materials, err := s.repo.List(filter)
+	if err != nil {
+		s.logger.Error("Failed to list materials", zap.Error(err))
+		return nil, fmt.Errorf("failed to list materials: %w", err)
+	}
+	return materials, nil
+}
+
+// GetMaterial 按编码查询物料
+func (s *materialService) GetMaterial(code string) (*model.Material, error) {
+	material, err := s.repo.GetByCode(code)
+	if err != nil {
+		s.logger.Error("Failed to fetch material", zap.String("code", code), zap.Error(err))
+		return nil, fmt.Errorf("failed to fetch material: %w", err)
+	}
+	if material == nil {
+		return nil, fmt.Errorf("%w: material %s", ErrNotFound, code)
+	}
+	return material, nil
+}
+
+// UpsertMaterial 按物料编码新增或更新物料
+// 条码已登记在其他物料下时返回 ErrInvalidState
+func (s *materialService) UpsertMaterial(input MaterialInput) (*model.Material, error) {
+	material, err := newMaterialRecord(input)
+	if err != nil {
+		return nil, err
+	}
+
+	err = runInTransaction(s.repo, s.logger, "upsert_material", func(tx *gorm.DB) error {
+		return s.saveMaterial(tx, material, input.Barcodes)
+	})
+	if err != nil {
+		s.logger.Warn("Material save failed", zap.String("code", input.Code), zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.Info("Material saved",
+		zap.String("code", material.Code),
+		zap.String("category", material.Category),
+		zap.Float64("volume_cm3", material.Volume()),
+		zap.Float64("weight_kg", material.WeightKg),
+		zap.Bool("cross_dock", material.CrossDock),
+		zap.Bool("serial_tracked", material.SerialTracked),
+		zap.Bool("active", material.Active),
+	)
+	return s.GetMaterial(material.Code)
+}
+
+// DeleteMaterial 删除物料及其条码
+// 仍有在库或预留库存的物料返回 ErrInvalidState，应先出清库存或改为停用；
+// 历史单据、流水与盘点记录中的物料编码保持不变
+func (s *materialService) DeleteMaterial(code string) error {
+	err := runInTransaction(s.repo, s.logger, "delete_material", func(tx *gorm.DB) error {
+		stocked, err := s.repo.HasStock(tx, code)
+		if err != nil {
+			return fmt.Errorf("failed to check material stock: %w", err)
+		}
+		if stocked {
+			return fmt.Errorf("%w: material %s still has stock on hand or reserved, deactivate it instead", ErrInvalidState, code)
+		}
+		deleted, err := s.repo.Delete(tx, code)
+		if err != nil {
+			return fmt.Errorf("failed to delete material: %w", err)
+		}
+		if !deleted {
+			return fmt.Errorf("%w: material %s", ErrNotFound, code)
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.Warn("Material deletion failed", zap.String("code", code), zap.Error(err))
+		return err
+	}
+
+	s.logger.Info("Material deleted", zap.String("code", code))
+	return nil
+}
+
+// ImportMaterials 批量新增或更新物料
+// 行内容无效、文件内物料编码或条码重复、条码已登记在其他物料下时，错误按行记录在结果中并返回 ErrInvalidInput
+func (s *materialService) ImportMaterials(inputs []MaterialInput) (*MaterialImportResult, error) {
+	if len(inputs) == 0 {
+		return nil, fmt.Errorf("%w: at least one material is required", ErrInvalidInput)
+	}
+	if len(inputs) > MaxMaterialImportRows {
+		return nil, fmt.Errorf("%w: import size %d exceeds limit %d", ErrInvalidInput, len(inputs), MaxMaterialImportRows)
+	}
+
+	result := &MaterialImportResult{Total: len(inputs)}
+	materials := make([]*model.Material, len(inputs))
+	for i, input := range inputs {
+		material, err := newMaterialRecord(input)
+		if err != nil {
+			result.Errors = append(result.Errors, MaterialImportError{Row: i, Code: input.Code, Message: err.Error()})
+			continue
+		}
+		materials[i] = material
+	}
+	result.Errors = append(result.Errors, findImportDuplicates(inputs)...)
+	if len(result.Errors) > 0 {
+		return result, fmt.Errorf("%w: %d of %d rows are invalid", ErrInvalidInput, len(result.Errors), len(inputs))
+	}
+
+	codes := make([]string, len(inputs))
+	for i, input := range inputs {
+		codes[i] = input.Code
+	}
+	var created, updated int
+	err := runInTransaction(s.repo, s.logger, "import_materials", func(tx *gorm.DB) error {
+		existing, err := s.repo.ListByCodes(tx, codes)
+		if err != nil {
+			return fmt.Errorf("failed to fetch materials: %w", err)
+		}
+		known := make(map[string]bool, len(existing))
+		for _, m := range existing {
+			known[m.Code] = true
+		}
+
+		created, updated = 0, 0
+		for i, material := range materials {
+			if err := s.saveMaterial(tx, material, inputs[i].Barcodes); err != nil {
+				if errors.Is(err, ErrInvalidState) {
+					result.Errors = append(result.Errors, MaterialImportError{Row: i, Code: material.Code, Message: err.Error()})
+					continue
+				}
+				return err
+			}
+			if known[material.Code] {
+				updated++
+			} else {
+				created++
+			}
+		}
+		if len(result.Errors) > 0 {
+			return fmt.Errorf("%w: %d of %d rows are invalid", ErrInvalidInput, len(result.Errors), len(inputs))
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.Warn("Material import failed", zap.Int("rows", len(inputs)), zap.Error(err))
+		if errors.Is(err, ErrInvalidInput) {
+			return result, err
+		}
+		return nil, err
+	}
+
+	result.Created, result.Updated = created, updated
+	s.logger.Info("Materials imported",
+		zap.Int("total", result.Total),
+		zap.Int("created", result.Created),
+		zap.Int("updated", result.Updated),
+	)
+	return result, nil
+}
+
+// RegisterStockedMaterials 为库存中已出现但尚未登记的物料编码补建启用的主数据
+// 启用物料校验前已存在的库存不会因此无法作业；补建的物料只有编码与默认属性，需要后续维护
+func (s *materialService) RegisterStockedMaterials() (int64, error) {
+	count, err := s.repo.CreateMissingFromStocks()
+	if err != nil {
+		return 0, fmt.Errorf("failed to register stocked materials: %w", err)
+	}
+	if count > 0 {
+		s.logger.Warn("Registered materials found in stock without master data", zap.Int64("count", count))
+	}
+	return count, nil
+}
+
+// saveMaterial 在事务中写入物料，barcodes 非 nil 时替换其全部条码
+func (s *materialService) saveMaterial(tx *gorm.DB, material *model.Material, barcodes []string) error {
+	if barcodes != nil {
+		owners, err := s.repo.ListBarcodes(tx, barcodes)
+		if err != nil {
+			return fmt.Errorf("failed to fetch barcodes: %w", err)
+		}
+		for _, owner := range owners {
+			if owner.MaterialCode != material.Code {
+				return fmt.Errorf("%w: barcode %s is already assigned to material %s",
+					ErrInvalidState, owner.Barcode, owner.MaterialCode)
+			}
+		}
+	}
+	if err := s.repo.Upsert(tx, material); err != nil {
+		return fmt.Errorf("failed to save material: %w", err)
+	}
+	if barcodes != nil {
+		if err := s.repo.ReplaceBarcodes(tx, material.Code, barcodes); err != nil {
+			return fmt.Errorf("failed to save barcodes: %w", err)
+		}
+	}
+	return nil
+}
+
+// newMaterialRecord 校验输入并构造物料记录
+func newMaterialRecord(input MaterialInput) (*model.Material, error) {
+	if strings.TrimSpace(input.Code) == "" {
+		return nil, fmt.Errorf("%w: code is required", ErrInvalidInput)
+	}
+	if input.LengthCm < 0 || input.WidthCm < 0 || input.HeightCm < 0 || input.WeightKg < 0 {
+		return nil, fmt.Errorf("%w: dimensions and weight cannot be negative", ErrInvalidInput)
+	}
+	seen := make(map[string]bool, len(input.Barcodes))
+	for _, barcode := range input.Barcodes {
+		if strings.TrimSpace(barcode) == "" {
+			return nil, fmt.Errorf("%w: barcode cannot be empty", ErrInvalidInput)
+		}
+		if seen[barcode] {
+			return nil, fmt.Errorf("%w: duplicate barcode %s", ErrInvalidInput, barcode)
+		}
+		seen[barcode] = true
+	}
+
+	baseUnit := input.BaseUnit
+	if baseUnit == "" {
+		baseUnit = model.DefaultBaseUnit
+	}
+	active := true
+	if input.Active != nil {
+		active = *input.Active
+	}
+	return &model.Material{
+		Code:          input.Code,
+		Description:   input.Description,
+		BaseUnit:      baseUnit,
+		Category:      input.Category,
+		LengthCm:      input.LengthCm,
+		WidthCm:       input.WidthCm,
+		HeightCm:      input.HeightCm,
+		WeightKg:      input.WeightKg,
+		CrossDock:     input.CrossDock,
+		LotManaged:    input.LotManaged,
+		SerialTracked: input.SerialTracked,
+		Active:        active,
+	}, nil
+}
+
+// findImportDuplicates 查找导入文件内重复的物料编码与跨行重复的条码，重复项记在后出现的行上
+func findImportDuplicates(inputs []MaterialInput) []MaterialImportError {
+	var errs []MaterialImportError
+	codeRows := make(map[string]int, len(inputs))
+	barcodeRows := make(map[string]int)
+	for i, input := range inputs {
+		if first, ok := codeRows[input.Code]; ok {
+			errs = append(errs, MaterialImportError{Row: i, Code: input.Code,
+				Message: fmt.Sprintf("material code duplicates row %d", first)})
+			continue
+		}
+		codeRows[input.Code] = i
+		for _, barcode := range input.Barcodes {
+			if first, ok := barcodeRows[barcode]; ok && first != i {
+				errs = append(errs, MaterialImportError{Row: i, Code: input.Code,
+					Message: fmt.Sprintf("barcode %s duplicates row %d", barcode, first)})
+				continue
+			}
+			barcodeRows[barcode] = i
+		}
+	}
+	return errs
+}
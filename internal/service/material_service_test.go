@@ -0,0 +1,71 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"wms/internal/model"
+	"wms/pkg/logger"
+)
+
+func TestNewMaterialRecord_Defaults(t *testing.T) {
+	material, err := newMaterialRecord(MaterialInput{Code: "M-001"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if material.BaseUnit != model.DefaultBaseUnit || !material.Active {
+		t.Errorf("Expected base unit %s and active by default, got %+v", model.DefaultBaseUnit, material)
+	}
+
+	inactive := false
+	material, _ = newMaterialRecord(MaterialInput{Code: "M-001", BaseUnit: "BOX", Active: &inactive})
+	if material.BaseUnit != "BOX" || material.Active {
+		t.Errorf("Expected explicit base unit and inactive, got %+v", material)
+	}
+}
+
+func TestNewMaterialRecord_RejectsInvalidInput(t *testing.T) {
+	cases := []MaterialInput{
+		{Code: " "},
+		{Code: "M-001", WeightKg: -1},
+		{Code: "M-001", Barcodes: []string{""}},
+		{Code: "M-001", Barcodes: []string{"690001", "690001"}},
+	}
+	for _, input := range cases {
+		if _, err := newMaterialRecord(input); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("Expected ErrInvalidInput for %+v, got %v", input, err)
+		}
+	}
+}
+
+func TestFindImportDuplicates(t *testing.T) {
+	errs := findImportDuplicates([]MaterialInput{
+		{Code: "M-001", Barcodes: []string{"690001"}},
+		{Code: "M-002", Barcodes: []string{"690002"}},
+		{Code: "M-001"},
+		{Code: "M-003", Barcodes: []string{"690002"}},
+	})
+	if len(errs) != 2 {
+		t.Fatalf("Expected 2 duplicate errors, got %+v", errs)
+	}
+	if errs[0].Row != 2 || errs[1].Row != 3 {
+		t.Errorf("Expected duplicates reported on rows 2 and 3, got %+v", errs)
+	}
+}
+
+func TestImportMaterials_InvalidRowsAreNotWritten(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	// 仓储为 nil：校验失败时不应开启事务
+	svc := NewMaterialService(nil, log)
+
+	result, err := svc.ImportMaterials([]MaterialInput{
+		{Code: "M-001"},
+		{Code: "M-002", HeightCm: -5},
+		{Code: "M-001"},
+	})
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Fatalf("Expected ErrInvalidInput, got %v", err)
+	}
+	if result == nil || result.Total != 3 || len(result.Errors) != 2 || result.Created != 0 {
+		t.Errorf("Expected 2 row errors and nothing written, got %+v", result)
+	}
+}
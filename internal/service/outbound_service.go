@@ -0,0 +1,547 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"wms/internal/model"
+	"wms/internal/repository"
+	"wms/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// OutboundOrderInput 表示创建出库单据的输入数据
+// AllocationStrategy 为空时使用 OutboundOptions.AllocationStrategy
+type OutboundOrderInput struct {
+	OrderNo            string
+	CustomerCode       string
+	ShipTo             string
+	RequiredAt         *time.Time
+	Carrier            string
+	CarrierCutoffAt    *time.Time
+	AllocationStrategy string
+	CreatedBy          string
+	Lines              []OutboundLineInput
+}
+
+// OutboundLineInput 表示出库单据的一条物料行，LineNo 为 0 时按顺序自动编号
+type OutboundLineInput struct {
+	LineNo       int
+	MaterialCode string
+	Quantity     int
+}
+
+// AllocationShortage 表示分配后仍未满足的数量
+type AllocationShortage struct {
+	LineNo       int    `json:"line_no"`
+	MaterialCode string `json:"material_code"`
+	Quantity     int    `json:"quantity"`
+}
+
+// AllocationResult 表示一次分配的结果
+type AllocationResult struct {
+	Order       *model.OutboundOrder    `json:"order"`
+	Allocations []model.StockAllocation `json:"allocations"`
+	Shortages   []AllocationShortage    `json:"shortages"`
+}
+
+// OutboundOptions 表示出库服务的可配置项
+type OutboundOptions struct {
+	// AllocationStrategy 单据未指定分配策略时使用的策略
+	AllocationStrategy string
+	// Retry 事务遇到序列化失败或死锁时的重试策略
+	Retry RetryPolicy
+}
+
+// OutboundService 定义出库单据与库存分配的业务接口
+type OutboundService interface {
+	// CreateOrder 创建出库单据
+	CreateOrder(input OutboundOrderInput) (*model.OutboundOrder, error)
+
+	// GetOrder 查询出库单据，不存在时返回 ErrNotFound
+	GetOrder(id uint) (*model.OutboundOrder, error)
+
+	// ListOrders 按过滤条件查询出库单据
+	ListOrders(filter repository.OutboundOrderFilter) ([]model.OutboundOrder, error)
+
+	// AllocateOrder 按分配策略为未分配足量的行预留库存，可用库存不足时部分分配并在结果中列出缺口
+	// 单据已全部分配或已取消时返回 ErrInvalidState
+	AllocateOrder(id uint, operatorID string) (*AllocationResult, error)
+
+	// CancelOrder 取消出库单据并释放全部预留；全部短拣、实拣为 0 的已拣货单据同样可以取消
+	CancelOrder(id uint, operatorID, reason string) (*model.OutboundOrder, error)
+
+	// ListAllocations 查询出库单据的预留记录
+	ListAllocations(id uint) ([]model.StockAllocation, error)
+}
+
+// outboundService 是 OutboundService 的具体实现
+type outboundService struct {
+	repo    repository.OutboundRepository
+	ledger  *stockLedger
+	options OutboundOptions
+	logger  *logger.Logger
+}
+
+// NewOutboundService 创建新的 OutboundService 实例
+func NewOutboundService(repo repository.OutboundRepository, stockRepo repository.StockRepository, options OutboundOptions, log *logger.Logger) OutboundService {
+	return &outboundService{
+		repo:    repo,
+		ledger:  newStockLedger(stockRepo),
+		options: options,
+		logger:  log,
+	}
+}
+
+// ValidAllocationStrategy 判断分配策略名称是否受支持
+func ValidAllocationStrategy(strategy string) bool {
+	switch strategy {
+	case model.AllocationStrategyFIFO, model.AllocationStrategyFEFO, model.AllocationStrategyFewestLocations, model.AllocationStrategyClearSmallBins:
+		return true
+	}
+	return false
+}
+
+// CreateOrder 创建出库单据
+func (s *outboundService) CreateOrder(input OutboundOrderInput) (*model.OutboundOrder, error) {
+	order, err := buildOutboundOrder(input)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.GetByOrderNo(order.OrderNo)
+	if err != nil {
+		s.logger.Error("Failed to check outbound order number", zap.String("order_no", order.OrderNo), zap.Error(err))
+		return nil, fmt.Errorf("failed to check order number: %w", err)
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("%w: order_no %s already exists", ErrInvalidInput, order.OrderNo)
+	}
+
+	if err := s.repo.Create(order); err != nil {
+		s.logger.Error("Failed to create outbound order", zap.String("order_no", order.OrderNo), zap.Error(err))
+		return nil, fmt.Errorf("failed to create outbound order: %w", err)
+	}
+
+	s.logger.Info("Outbound order created",
+		zap.Uint("order_id", order.ID),
+		zap.String("order_no", order.OrderNo),
+		zap.String("customer_code", order.CustomerCode),
+		zap.Int("line_count", len(order.Lines)),
+	)
+	return order, nil
+}
+
+// buildOutboundOrder 校验输入并构造出库单据
+func buildOutboundOrder(input OutboundOrderInput) (*model.OutboundOrder, error) {
+	orderNo := strings.TrimSpace(input.OrderNo)
+	if orderNo == "" {
+		return nil, fmt.Errorf("%w: order_no is required", ErrInvalidInput)
+	}
+	if strings.TrimSpace(input.CreatedBy) == "" {
+		return nil, fmt.Errorf("%w: created_by is required", ErrInvalidInput)
+	}
+	if input.AllocationStrategy != "" && !ValidAllocationStrategy(input.AllocationStrategy) {
+		return nil, fmt.Errorf("%w: unsupported allocation_strategy %q", ErrInvalidInput, input.AllocationStrategy)
+	}
+	if len(input.Lines) == 0 {
+		return nil, fmt.Errorf("%w: at least one line is required", ErrInvalidInput)
+	}
+
+	order := &model.OutboundOrder{
+		OrderNo:            orderNo,
+		CustomerCode:       input.CustomerCode,
+		ShipTo:             input.ShipTo,
+		RequiredAt:         input.RequiredAt,
+		Carrier:            strings.TrimSpace(input.Carrier),
+		CarrierCutoffAt:    input.CarrierCutoffAt,
+		AllocationStrategy: input.AllocationStrategy,
+		Status:             model.OutboundOrderStatusOpen,
+		CreatedBy:          input.CreatedBy,
+		Lines:              make([]model.OutboundOrderLine, 0, len(input.Lines)),
+	}
+	seen := make(map[int]bool, len(input.Lines))
+	for i, line := range input.Lines {
+		lineNo := line.LineNo
+		if lineNo == 0 {
+			lineNo = i + 1
+		}
+		if lineNo < 0 || seen[lineNo] {
+			return nil, fmt.Errorf("%w: duplicate or invalid line_no %d", ErrInvalidInput, lineNo)
+		}
+		seen[lineNo] = true
+		material := strings.TrimSpace(line.MaterialCode)
+		if material == "" {
+			return nil, fmt.Errorf("%w: line %d material_code is required", ErrInvalidInput, lineNo)
+		}
+		if line.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: line %d quantity must be positive", ErrInvalidInput, lineNo)
+		}
+		order.Lines = append(order.Lines, model.OutboundOrderLine{
+			LineNo:          lineNo,
+			MaterialCode:    material,
+			OrderedQuantity: line.Quantity,
+			Status:          model.OutboundLineStatusOpen,
+		})
+	}
+	return order, nil
+}
+
+// GetOrder 查询出库单据
+func (s *outboundService) GetOrder(id uint) (*model.OutboundOrder, error) {
+	order, err := s.repo.GetByID(id)
+	if err != nil {
+		s.logger.Error("Failed to fetch outbound order", zap.Uint("order_id", id), zap.Error(err))
+		return nil, fmt.Errorf("failed to fetch outbound order: %w", err)
+	}
+	if order == nil {
+		return nil, fmt.Errorf("%w: outbound order %d", ErrNotFound, id)
+	}
+	return order, nil
+}
+
+// ListOrders 查询出库单据列表
+func (s *outboundService) ListOrders(filter repository.OutboundOrderFilter) ([]model.OutboundOrder, error) {
+	orders, err := s.repo.List(filter)
+	if err != nil {
+		s.logger.Error("Failed to list outbound orders", zap.Error(err))
+		return nil, fmt.Errorf("failed to list outbound orders: %w", err)
+	}
+	return orders, nil
+}
+
+// AllocateOrder 为出库单据分配库存
+// 锁定单据后按物料、行号顺序逐行锁定可分配的库存行并预留，与其他库存变动以相同的 (物料, 库位) 顺序加锁
+func (s *outboundService) AllocateOrder(id uint, operatorID string) (*AllocationResult, error) {
+	if strings.TrimSpace(operatorID) == "" {
+		return nil, fmt.Errorf("%w: operator_id is required", ErrInvalidInput)
+	}
+
+	var result *AllocationResult
+	err := s.options.Retry.run(s.logger, "outbound_allocate", func() error {
+		return runInTransaction(s.repo, s.logger, "outbound_allocate", func(tx *gorm.DB) error {
+			order, err := s.repo.GetForUpdate(tx, id)
+			if err != nil {
+				return fmt.Errorf("failed to fetch outbound order: %w", err)
+			}
+			if order == nil {
+				return fmt.Errorf("%w: outbound order %d", ErrNotFound, id)
+			}
+			if order.Status != model.OutboundOrderStatusOpen && order.Status != model.OutboundOrderStatusPartiallyAllocated {
+				return fmt.Errorf("%w: outbound order %d is %s", ErrInvalidState, id, order.Status)
+			}
+			strategy := order.AllocationStrategy
+			if strategy == "" {
+				strategy = s.options.AllocationStrategy
+			}
+
+			lines := make([]*model.OutboundOrderLine, 0, len(order.Lines))
+			for i := range order.Lines {
+				if order.Lines[i].AllocatedQuantity < order.Lines[i].OrderedQuantity {
+					lines = append(lines, &order.Lines[i])
+				}
+			}
+			sort.Slice(lines, func(i, j int) bool {
+				if lines[i].MaterialCode != lines[j].MaterialCode {
+					return lines[i].MaterialCode < lines[j].MaterialCode
+				}
+				return lines[i].LineNo < lines[j].LineNo
+			})
+
+			result = &AllocationResult{Order: order, Allocations: []model.StockAllocation{}, Shortages: []AllocationShortage{}}
+			for _, line := range lines {
+				stocks, err := s.repo.ListAllocatableStocks(tx, line.MaterialCode, time.Now())
+				if err != nil {
+					return fmt.Errorf("failed to fetch allocatable stock: %w", err)
+				}
+				remaining := line.OrderedQuantity - line.AllocatedQuantity
+				for _, pick := range planAllocation(strategy, stocks, remaining) {
+					if _, err := s.ledger.reserve(tx, line.MaterialCode, pick.stock.LocationCode, pick.stock.LotNumber, pick.quantity); err != nil {
+						return err
+					}
+					allocation := model.StockAllocation{
+						OrderID:      order.ID,
+						LineID:       line.ID,
+						StockID:      pick.stock.ID,
+						MaterialCode: line.MaterialCode,
+						LocationCode: pick.stock.LocationCode,
+						LotNumber:    pick.stock.LotNumber,
+						Quantity:     pick.quantity,
+						Strategy:     strategy,
+						Status:       model.AllocationStatusActive,
+						AllocatedBy:  operatorID,
+					}
+					if err := s.repo.CreateAllocation(tx, &allocation); err != nil {
+						return fmt.Errorf("failed to create allocation: %w", err)
+					}
+					result.Allocations = append(result.Allocations, allocation)
+					line.AllocatedQuantity += pick.quantity
+					remaining -= pick.quantity
+				}
+				if remaining > 0 {
+					result.Shortages = append(result.Shortages, AllocationShortage{
+						LineNo:       line.LineNo,
+						MaterialCode: line.MaterialCode,
+						Quantity:     remaining,
+					})
+				}
+				line.Status = allocatedLineStatus(line)
+				if err := s.repo.UpdateLine(tx, line); err != nil {
+					return fmt.Errorf("failed to update outbound order line: %w", err)
+				}
+			}
+
+			order.Status = allocatedOrderStatus(order)
+			if err := s.repo.UpdateStatus(tx, order); err != nil {
+				return fmt.Errorf("failed to update outbound order: %w", err)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		s.logger.Warn("Outbound allocation failed", zap.Uint("order_id", id), zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.Info("Outbound order allocated",
+		zap.Uint("order_id", id),
+		zap.String("status", result.Order.Status),
+		zap.Int("allocation_count", len(result.Allocations)),
+		zap.Int("short_lines", len(result.Shortages)),
+		zap.String("operator_id", operatorID),
+	)
+	return result, nil
+}
+
+// allocationPick 表示从一个库存行分配的数量
+type allocationPick struct {
+	stock    model.Stock
+	quantity int
+}
+
+// planAllocation 按策略从可分配库存中选取库存行，返回的分配合计不超过 quantity
+// 库存不足时尽量分配，调用方根据合计判断缺口
+func planAllocation(strategy string, stocks []model.Stock, quantity int) []allocationPick {
+	candidates := make([]model.Stock, 0, len(stocks))
+	for _, stock := range stocks {
+		if stock.Available() > 0 {
+			candidates = append(candidates, stock)
+		}
+	}
+
+	switch strategy {
+	case model.AllocationStrategyFewestLocations:
+		// 能由单个库位满足时选可用量最接近需求的库位，避免拆零大库位；否则从可用量最大的库位开始
+		best := -1
+		for i, stock := range candidates {
+			if stock.Available() >= quantity && (best < 0 || stock.Available() < candidates[best].Available()) {
+				best = i
+			}
+		}
+		if best >= 0 {
+			return []allocationPick{{stock: candidates[best], quantity: quantity}}
+		}
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return candidates[i].Available() > candidates[j].Available()
+		})
+	case model.AllocationStrategyClearSmallBins:
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return candidates[i].Available() < candidates[j].Available()
+		})
+	case model.AllocationStrategyFEFO:
+		// 先到期先出：没有有效期的库存排在最后，有效期相同时先进先出
+		sort.SliceStable(candidates, func(i, j int) bool {
+			if c := compareDates(candidates[i].ExpiryDate, candidates[j].ExpiryDate); c != 0 {
+				return c < 0
+			}
+			return compareDates(candidates[i].ReceivedAt, candidates[j].ReceivedAt) < 0
+		})
+	default:
+		// 先进先出：没有入库时间的库存排在最后
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return compareDates(candidates[i].ReceivedAt, candidates[j].ReceivedAt) < 0
+		})
+	}
+
+	picks := make([]allocationPick, 0, len(candidates))
+	for _, stock := range candidates {
+		if quantity <= 0 {
+			break
+		}
+		take := stock.Available()
+		if take > quantity {
+			take = quantity
+		}
+		picks = append(picks, allocationPick{stock: stock, quantity: take})
+		quantity -= take
+	}
+	return picks
+}
+
+// compareDates 比较两个可为空的时间，空值排在最后；a 早于 b 时返回 -1，晚于时返回 1，相同或均为空时返回 0
+func compareDates(a, b *time.Time) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	case a.Before(*b):
+		return -1
+	case b.Before(*a):
+		return 1
+	}
+	return 0
+}
+
+// allocatedLineStatus 根据已分配数量返回出库单据行的状态
+func allocatedLineStatus(line *model.OutboundOrderLine) string {
+	switch {
+	case line.AllocatedQuantity >= line.OrderedQuantity:
+		return model.OutboundLineStatusAllocated
+	case line.AllocatedQuantity > 0:
+		return model.OutboundLineStatusPartial
+	default:
+		return model.OutboundLineStatusOpen
+	}
+}
+
+// allocatedOrderStatus 根据各行分配情况返回出库单据的状态
+func allocatedOrderStatus(order *model.OutboundOrder) string {
+	allocated, some := true, false
+	for _, line := range order.Lines {
+		if line.AllocatedQuantity < line.OrderedQuantity {
+			allocated = false
+		}
+		if line.AllocatedQuantity > 0 {
+			some = true
+		}
+	}
+	switch {
+	case allocated:
+		return model.OutboundOrderStatusAllocated
+	case some:
+		return model.OutboundOrderStatusPartiallyAllocated
+	default:
+		return model.OutboundOrderStatusOpen
+	}
+}
+
+// CancelOrder 取消出库单据并释放预留
+func (s *outboundService) CancelOrder(id uint, operatorID, reason string) (*model.OutboundOrder, error) {
+	if strings.TrimSpace(operatorID) == "" {
+		return nil, fmt.Errorf("%w: operator_id is required", ErrInvalidInput)
+	}
+
+	var result *model.OutboundOrder
+	released := 0
+	err := s.options.Retry.run(s.logger, "outbound_cancel", func() error {
+		return runInTransaction(s.repo, s.logger, "outbound_cancel", func(tx *gorm.DB) error {
+			order, err := s.repo.GetForUpdate(tx, id)
+			if err != nil {
+				return fmt.Errorf("failed to fetch outbound order: %w", err)
+			}
+			if order == nil {
+				return fmt.Errorf("%w: outbound order %d", ErrNotFound, id)
+			}
+			if err := checkCancellable(order); err != nil {
+				return err
+			}
+
+			released, err = s.releaseAllocations(tx, order)
+			if err != nil {
+				return err
+			}
+
+			now := time.Now()
+			order.Status = model.OutboundOrderStatusCancelled
+			order.CancelledBy = operatorID
+			order.CancelledAt = &now
+			order.CancelReason = reason
+			if err := s.repo.UpdateStatus(tx, order); err != nil {
+				return fmt.Errorf("failed to update outbound order: %w", err)
+			}
+			result = order
+			return nil
+		})
+	})
+	if err != nil {
+		s.logger.Warn("Outbound order cancel failed", zap.Uint("order_id", id), zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.Info("Outbound order cancelled",
+		zap.Uint("order_id", id),
+		zap.String("operator_id", operatorID),
+		zap.Int("released_allocations", released),
+	)
+	return result, nil
+}
+
+// checkCancellable 校验出库单据可以取消
+// 组波前的单据可以取消；已拣货（picked）但全部短拣、没有拣到任何数量的单据无货可装箱发运，也可以取消；
+// 其余状态返回 ErrInvalidState
+func checkCancellable(order *model.OutboundOrder) error {
+	switch order.Status {
+	case model.OutboundOrderStatusOpen, model.OutboundOrderStatusPartiallyAllocated, model.OutboundOrderStatusAllocated:
+		return nil
+	case model.OutboundOrderStatusPicked:
+		picked := 0
+		for _, line := range order.Lines {
+			picked += line.PickedQuantity
+		}
+		if picked == 0 {
+			return nil
+		}
+		return fmt.Errorf("%w: outbound order %d has %d picked and must be packed and shipped", ErrInvalidState, order.ID, picked)
+	}
+	return fmt.Errorf("%w: outbound order %d is %s", ErrInvalidState, order.ID, order.Status)
+}
+
+// releaseAllocations 在事务中释放单据全部预留中的库存并清零各行的分配数量，返回释放的预留条数
+func (s *outboundService) releaseAllocations(tx *gorm.DB, order *model.OutboundOrder) (int, error) {
+	allocations, err := s.repo.ListActiveAllocations(tx, order.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch allocations: %w", err)
+	}
+	ids := make([]uint, 0, len(allocations))
+	for _, allocation := range allocations {
+		if _, err := s.ledger.release(tx, allocation.MaterialCode, allocation.LocationCode, allocation.LotNumber, allocation.Quantity); err != nil {
+			return 0, err
+		}
+		ids = append(ids, allocation.ID)
+	}
+	if err := s.repo.ReleaseAllocations(tx, ids, time.Now()); err != nil {
+		return 0, fmt.Errorf("failed to release allocations: %w", err)
+	}
+
+	for i := range order.Lines {
+		line := &order.Lines[i]
+		if line.AllocatedQuantity == 0 {
+			continue
+		}
+		line.AllocatedQuantity = 0
+		line.Status = model.OutboundLineStatusOpen
+		if err := s.repo.UpdateLine(tx, line); err != nil {
+			return 0, fmt.Errorf("failed to update outbound order line: %w", err)
+		}
+	}
+	return len(allocations), nil
+}
+
+// ListAllocations 查询出库单据的预留记录
+func (s *outboundService) ListAllocations(id uint) ([]model.StockAllocation, error) {
+	if _, err := s.GetOrder(id); err != nil {
+		return nil, err
+	}
+	allocations, err := s.repo.ListAllocations(id)
+	if err != nil {
+		s.logger.Error("Failed to list allocations", zap.Uint("order_id", id), zap.Error(err))
+		return nil, fmt.Errorf("failed to list allocations: %w", err)
+	}
+	return allocations, nil
+}
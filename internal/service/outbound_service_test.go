@@ -0,0 +1,162 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+	"wms/internal/model"
+	"wms/internal/repository"
+	"wms/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+func TestPlanAllocation(t *testing.T) {
+	day := func(d int) *time.Time {
+		at := time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC)
+		return &at
+	}
+	stocks := []model.Stock{
+		{LocationCode: "A-01", Quantity: 10, ReservedQuantity: 2, ReceivedAt: day(5), ExpiryDate: day(25)},
+		{LocationCode: "A-02", Quantity: 3, ReceivedAt: day(9), ExpiryDate: day(20)},
+		{LocationCode: "A-03", Quantity: 20, ReceivedAt: day(1)},
+		{LocationCode: "A-04", Quantity: 6, ExpiryDate: day(15)},
+		{LocationCode: "A-05", Quantity: 4, ReservedQuantity: 4, ReceivedAt: day(1)},
+	}
+
+	cases := []struct {
+		name     string
+		strategy string
+		quantity int
+		want     []string
+	}{
+		{"fifo oldest first", model.AllocationStrategyFIFO, 25, []string{"A-03:20", "A-01:5"}},
+		{"fifo undated last", model.AllocationStrategyFIFO, 40, []string{"A-03:20", "A-01:8", "A-02:3", "A-04:6"}},
+		{"fefo earliest expiry first", model.AllocationStrategyFEFO, 12, []string{"A-04:6", "A-02:3", "A-01:3"}},
+		{"fefo undated by receipt", model.AllocationStrategyFEFO, 20, []string{"A-04:6", "A-02:3", "A-01:8", "A-03:3"}},
+		{"fewest locations best single fit", model.AllocationStrategyFewestLocations, 7, []string{"A-01:7"}},
+		{"fewest locations largest first", model.AllocationStrategyFewestLocations, 30, []string{"A-03:20", "A-01:8", "A-04:2"}},
+		{"clear small bins", model.AllocationStrategyClearSmallBins, 10, []string{"A-02:3", "A-04:6", "A-01:1"}},
+	}
+	for _, tc := range cases {
+		picks := planAllocation(tc.strategy, stocks, tc.quantity)
+		got := make([]string, 0, len(picks))
+		for _, p := range picks {
+			got = append(got, fmt.Sprintf("%s:%d", p.stock.LocationCode, p.quantity))
+		}
+		if len(got) != len(tc.want) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Errorf("%s: expected %v, got %v", tc.name, tc.want, got)
+				break
+			}
+		}
+	}
+}
+
+func TestAllocatedOrderStatus(t *testing.T) {
+	order := &model.OutboundOrder{Lines: []model.OutboundOrderLine{
+		{OrderedQuantity: 5},
+		{OrderedQuantity: 3},
+	}}
+	if got := allocatedOrderStatus(order); got != model.OutboundOrderStatusOpen {
+		t.Errorf("Expected open, got %s", got)
+	}
+	order.Lines[0].AllocatedQuantity = 5
+	if got := allocatedOrderStatus(order); got != model.OutboundOrderStatusPartiallyAllocated {
+		t.Errorf("Expected partially_allocated, got %s", got)
+	}
+	order.Lines[1].AllocatedQuantity = 3
+	if got := allocatedOrderStatus(order); got != model.OutboundOrderStatusAllocated {
+		t.Errorf("Expected allocated, got %s", got)
+	}
+}
+
+// memoryOutboundRepository 是只保存一张出库单据的 OutboundRepository 测试替身
+type memoryOutboundRepository struct {
+	repository.OutboundRepository
+	order *model.OutboundOrder
+}
+
+func (r *memoryOutboundRepository) BeginTransaction() *gorm.DB            { return &gorm.DB{} }
+func (r *memoryOutboundRepository) CommitTransaction(tx *gorm.DB) error   { return nil }
+func (r *memoryOutboundRepository) RollbackTransaction(tx *gorm.DB) error { return nil }
+
+func (r *memoryOutboundRepository) GetForUpdate(tx *gorm.DB, id uint) (*model.OutboundOrder, error) {
+	copied := *r.order
+	copied.Lines = append([]model.OutboundOrderLine(nil), r.order.Lines...)
+	return &copied, nil
+}
+
+func (r *memoryOutboundRepository) ListActiveAllocations(tx *gorm.DB, orderID uint) ([]model.StockAllocation, error) {
+	return nil, nil
+}
+
+func (r *memoryOutboundRepository) ReleaseAllocations(tx *gorm.DB, ids []uint, releasedAt time.Time) error {
+	return nil
+}
+
+func (r *memoryOutboundRepository) UpdateLine(tx *gorm.DB, line *model.OutboundOrderLine) error {
+	return nil
+}
+
+func (r *memoryOutboundRepository) UpdateStatus(tx *gorm.DB, order *model.OutboundOrder) error {
+	r.order.Status = order.Status
+	return nil
+}
+
+func TestCancelOrder_ClosesFullyShortPickedOrder(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	repo := &memoryOutboundRepository{order: &model.OutboundOrder{
+		ID:     1,
+		Status: model.OutboundOrderStatusPicked,
+		Lines: []model.OutboundOrderLine{
+			{MaterialCode: "MAT-1", OrderedQuantity: 5, Status: model.OutboundLineStatusShort},
+			{MaterialCode: "MAT-2", OrderedQuantity: 2, Status: model.OutboundLineStatusShort},
+		},
+	}}
+	svc := &outboundService{repo: repo, ledger: newStockLedger(newMemoryStockRepository()), options: OutboundOptions{Retry: RetryPolicy{MaxAttempts: 1}}, logger: log}
+
+	// 全部短拣、没有拣到任何数量的单据无法装箱发运，只能取消关闭
+	order, err := svc.CancelOrder(1, "op1", "nothing picked")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if order.Status != model.OutboundOrderStatusCancelled || repo.order.Status != model.OutboundOrderStatusCancelled {
+		t.Errorf("Expected order cancelled, got %s", order.Status)
+	}
+
+	// 拣到了货的单据须装箱发运，不能取消
+	repo.order.Status = model.OutboundOrderStatusPicked
+	repo.order.Lines[1].PickedQuantity = 1
+	if _, err := svc.CancelOrder(1, "op1", "partial"); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("Expected ErrInvalidState for an order with picked quantity, got %v", err)
+	}
+}
+
+func TestCheckCancellable(t *testing.T) {
+	cases := []struct {
+		status string
+		picked int
+		want   bool
+	}{
+		{model.OutboundOrderStatusOpen, 0, true},
+		{model.OutboundOrderStatusAllocated, 0, true},
+		{model.OutboundOrderStatusPicking, 0, false},
+		{model.OutboundOrderStatusPicked, 0, true},
+		{model.OutboundOrderStatusPicked, 2, false},
+		{model.OutboundOrderStatusPacked, 2, false},
+		{model.OutboundOrderStatusShipped, 2, false},
+		{model.OutboundOrderStatusCancelled, 0, false},
+	}
+	for _, tc := range cases {
+		order := &model.OutboundOrder{ID: 1, Status: tc.status, Lines: []model.OutboundOrderLine{{PickedQuantity: tc.picked}}}
+		if got := checkCancellable(order) == nil; got != tc.want {
+			t.Errorf("%s with %d picked: cancellable = %v, want %v", tc.status, tc.picked, got, tc.want)
+		}
+	}
+}
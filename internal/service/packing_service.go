@@ -0,0 +1,740 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"wms/internal/model"
+	"wms/internal/repository"
+	"wms/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// CartonTypeInput 表示新增或更新箱型的输入，Active 为空时视为启用
+type CartonTypeInput struct {
+	Code         string
+	Description  string
+	LengthCm     float64
+	WidthCm      float64
+	HeightCm     float64
+	MaxWeightKg  float64
+	TareWeightKg float64
+	Active       *bool
+}
+
+// CartonizeInput 表示自动装箱的输入，DryRun 为 true 时只返回推荐结果，不创建包裹
+type CartonizeInput struct {
+	PackStation string
+	PackedBy    string
+	DryRun      bool
+}
+
+// PackLineInput 表示手工装箱时某个单据行的装箱数量
+type PackLineInput struct {
+	LineID   uint
+	Quantity int
+}
+
+// PackInput 表示手工装箱的输入
+type PackInput struct {
+	CartonTypeCode string
+	PackStation    string
+	PackedBy       string
+	Lines          []PackLineInput
+}
+
+// ShipInput 表示发运确认的输入
+type ShipInput struct {
+	TrackingNo string
+	ShippedBy  string
+}
+
+// ManifestItem 表示发运清单中包裹内的一种物料
+type ManifestItem struct {
+	LineNo       int    `json:"line_no"`
+	MaterialCode string `json:"material_code"`
+	Quantity     int    `json:"quantity"`
+}
+
+// ManifestPackage 表示发运清单中的一个包裹
+type ManifestPackage struct {
+	PackageNo      string         `json:"package_no"`
+	CartonTypeCode string         `json:"carton_type_code"`
+	LengthCm       float64        `json:"length_cm"`
+	WidthCm        float64        `json:"width_cm"`
+	HeightCm       float64        `json:"height_cm"`
+	GrossWeightKg  float64        `json:"gross_weight_kg"`
+	Items          []ManifestItem `json:"items"`
+}
+
+// ShipmentManifest 表示交给承运商的发运清单
+type ShipmentManifest struct {
+	ShipmentID    uint              `json:"shipment_id"`
+	OrderNo       string            `json:"order_no"`
+	CustomerCode  string            `json:"customer_code,omitempty"`
+	ShipTo        string            `json:"ship_to,omitempty"`
+	Carrier       string            `json:"carrier,omitempty"`
+	TrackingNo    string            `json:"tracking_no,omitempty"`
+	ShippedBy     string            `json:"shipped_by"`
+	ShippedAt     time.Time         `json:"shipped_at"`
+	PackageCount  int               `json:"package_count"`
+	TotalQuantity int               `json:"total_quantity"`
+	TotalWeightKg float64           `json:"total_weight_kg"`
+	Packages      []ManifestPackage `json:"packages"`
+}
+
+// PackingService 定义箱型、装箱与发运确认的业务接口
+type PackingService interface {
+	// ListCartonTypes 查询全部箱型
+	ListCartonTypes() ([]model.CartonType, error)
+
+	// UpsertCartonType 按箱型编码新增或更新箱型
+	UpsertCartonType(input CartonTypeInput) (*model.CartonType, error)
+
+	// Cartonize 按物料尺寸与重量为单据尚未装箱的已拣数量推荐箱型并创建包裹
+	Cartonize(orderID uint, input CartonizeInput) ([]model.Package, error)
+
+	// PackCarton 按指定箱型与数量手工装箱
+	PackCarton(orderID uint, input PackInput) (*model.Package, error)
+
+	// ListPackages 查询单据的全部包裹
+	ListPackages(orderID uint) ([]model.Package, error)
+
+	// Unpack 拆除尚未发运的包裹，装箱数量退回待装箱
+	Unpack(packageID uint) error
+
+	// ShipOrder 发运确认：从包装暂存库位扣减已拣数量并关闭单据，返回发运清单
+	ShipOrder(orderID uint, input ShipInput) (*ShipmentManifest, error)
+
+	// GetManifest 查询已发运单据的发运清单，未发运时返回 ErrNotFound
+	GetManifest(orderID uint) (*ShipmentManifest, error)
+}
+
+// packingService 是 PackingService 的具体实现
+type packingService struct {
+	repo         repository.PackingRepository
+	outboundRepo repository.OutboundRepository
+	materialRepo repository.MaterialRepository
+	ledger       *stockLedger
+	retry        RetryPolicy
+	logger       *logger.Logger
+}
+
+// NewPackingService 创建新的 PackingService 实例
+func NewPackingService(repo repository.PackingRepository, outboundRepo repository.OutboundRepository, materialRepo repository.MaterialRepository,
+	stockRepo repository.StockRepository, retry RetryPolicy, log *logger.Logger) PackingService {
+	return &packingService{
+		repo:         repo,
+		outboundRepo: outboundRepo,
+		materialRepo: materialRepo,
+		ledger:       newStockLedger(stockRepo),
+		retry:        retry,
+		logger:       log,
+	}
+}
+
+// ListCartonTypes 查询全部箱型
+func (s *packingService) ListCartonTypes() ([]model.CartonType, error) {
+	cartons, err := s.repo.ListCartonTypes()
+	if err != nil {
+		s.logger.Error("Failed to list carton types", zap.Error(err))
+		return nil, fmt.Errorf("failed to list carton types: %w", err)
+	}
+	return cartons, nil
+}
+
+// UpsertCartonType 校验并保存箱型
+func (s *packingService) UpsertCartonType(input CartonTypeInput) (*model.CartonType, error) {
+	if strings.TrimSpace(input.Code) == "" {
+		return nil, fmt.Errorf("%w: code is required", ErrInvalidInput)
+	}
+	if input.LengthCm <= 0 || input.WidthCm <= 0 || input.HeightCm <= 0 {
+		return nil, fmt.Errorf("%w: carton dimensions must be positive", ErrInvalidInput)
+	}
+	if input.MaxWeightKg < 0 || input.TareWeightKg < 0 {
+		return nil, fmt.Errorf("%w: weights cannot be negative", ErrInvalidInput)
+	}
+
+	carton := &model.CartonType{
+		Code:         input.Code,
+		Description:  input.Description,
+		LengthCm:     input.LengthCm,
+		WidthCm:      input.WidthCm,
+		HeightCm:     input.HeightCm,
+		MaxWeightKg:  input.MaxWeightKg,
+		TareWeightKg: input.TareWeightKg,
+		Active:       input.Active == nil || *input.Active,
+	}
+	if err := s.repo.UpsertCartonType(carton); err != nil {
+		s.logger.Error("Failed to save carton type", zap.String("code", input.Code), zap.Error(err))
+		return nil, fmt.Errorf("failed to save carton type: %w", err)
+	}
+
+	s.logger.Info("Carton type saved",
+		zap.String("code", carton.Code),
+		zap.Float64("volume_cm3", carton.Volume()),
+		zap.Float64("max_weight_kg", carton.MaxWeightKg),
+		zap.Bool("active", carton.Active),
+	)
+	return carton, nil
+}
+
+// packableOrder 在事务中锁定可装箱的单据：拣货已完成（picked）且尚未全部装箱
+func (s *packingService) packableOrder(tx *gorm.DB, orderID uint) (*model.OutboundOrder, error) {
+	order, err := s.outboundRepo.GetForUpdate(tx, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch outbound order: %w", err)
+	}
+	if order == nil {
+		return nil, fmt.Errorf("%w: outbound order %d", ErrNotFound, orderID)
+	}
+	if order.Status != model.OutboundOrderStatusPicked {
+		return nil, fmt.Errorf("%w: outbound order %s is %s, only picked orders can be packed", ErrInvalidState, order.OrderNo, order.Status)
+	}
+	return order, nil
+}
+
+// Cartonize 自动装箱
+// 待装箱数量为各行已拣数量减去已装箱数量；物料尺寸未登记时返回 ErrInvalidState，须改为手工装箱
+func (s *packingService) Cartonize(orderID uint, input CartonizeInput) ([]model.Package, error) {
+	if !input.DryRun && strings.TrimSpace(input.PackedBy) == "" {
+		return nil, fmt.Errorf("%w: packed_by is required", ErrInvalidInput)
+	}
+
+	var packages []model.Package
+	err := s.retry.run(s.logger, "cartonize", func() error {
+		return runInTransaction(s.repo, s.logger, "cartonize", func(tx *gorm.DB) error {
+			order, err := s.packableOrder(tx, orderID)
+			if err != nil {
+				return err
+			}
+			items, err := s.unpackedItems(tx, order)
+			if err != nil {
+				return err
+			}
+			if len(items) == 0 {
+				return fmt.Errorf("%w: outbound order %s has nothing left to pack", ErrInvalidState, order.OrderNo)
+			}
+			cartons, err := s.repo.ListActiveCartonTypes(tx)
+			if err != nil {
+				return fmt.Errorf("failed to fetch carton types: %w", err)
+			}
+			plans, err := cartonize(items, cartons)
+			if err != nil {
+				return err
+			}
+
+			weights := make(map[uint]float64, len(items))
+			for _, item := range items {
+				weights[item.lineID] = item.material.WeightKg
+			}
+			packages = packages[:0]
+			for _, plan := range plans {
+				pkg := newPackage(order, plan.carton, plan.lines, weights, input.PackStation, input.PackedBy)
+				if !input.DryRun {
+					if err := s.createPackage(tx, order, pkg); err != nil {
+						return err
+					}
+				}
+				packages = append(packages, *pkg)
+			}
+			if input.DryRun {
+				return nil
+			}
+			return s.savePacking(tx, order)
+		})
+	})
+	if err != nil {
+		s.logger.Warn("Cartonization failed", zap.Uint("order_id", orderID), zap.Error(err))
+		return nil, err
+	}
+
+	if !input.DryRun {
+		s.logger.Info("Order cartonized",
+			zap.Uint("order_id", orderID),
+			zap.Int("package_count", len(packages)),
+			zap.String("pack_station", input.PackStation),
+			zap.String("packed_by", input.PackedBy),
+		)
+	}
+	return packages, nil
+}
+
+// unpackedItems 返回单据各行待装箱的数量及物料尺寸，未登记的物料尺寸为零值
+func (s *packingService) unpackedItems(tx *gorm.DB, order *model.OutboundOrder) ([]cartonItem, error) {
+	codes := make([]string, 0, len(order.Lines))
+	for _, line := range order.Lines {
+		codes = append(codes, line.MaterialCode)
+	}
+	materials, err := s.materialIndex(tx, codes)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]cartonItem, 0, len(order.Lines))
+	for _, line := range order.Lines {
+		if quantity := line.PickedQuantity - line.PackedQuantity; quantity > 0 {
+			material, ok := materials[line.MaterialCode]
+			if !ok {
+				material = model.Material{Code: line.MaterialCode}
+			}
+			items = append(items, cartonItem{lineID: line.ID, material: material, quantity: quantity})
+		}
+	}
+	return items, nil
+}
+
+// materialIndex 在事务中按编码索引物料
+func (s *packingService) materialIndex(tx *gorm.DB, codes []string) (map[string]model.Material, error) {
+	materials, err := s.materialRepo.ListByCodes(tx, codes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch materials: %w", err)
+	}
+	index := make(map[string]model.Material, len(materials))
+	for _, material := range materials {
+		index[material.Code] = material
+	}
+	return index, nil
+}
+
+// newPackage 按箱型与装箱行构造包裹，毛重为空箱重量加物料重量
+func newPackage(order *model.OutboundOrder, carton model.CartonType, lines []model.PackageLine, weights map[uint]float64,
+	packStation, packedBy string) *model.Package {
+	pkg := &model.Package{
+		OrderID:        order.ID,
+		CartonTypeCode: carton.Code,
+		LengthCm:       carton.LengthCm,
+		WidthCm:        carton.WidthCm,
+		HeightCm:       carton.HeightCm,
+		GrossWeightKg:  carton.TareWeightKg,
+		Status:         model.PackageStatusPacked,
+		PackStation:    packStation,
+		PackedBy:       packedBy,
+		Lines:          lines,
+	}
+	for _, line := range lines {
+		pkg.GrossWeightKg += weights[line.OrderLineID] * float64(line.Quantity)
+	}
+	return pkg
+}
+
+// createPackage 分配包裹序号与包裹号后创建包裹，并累加单据行的装箱数量
+func (s *packingService) createPackage(tx *gorm.DB, order *model.OutboundOrder, pkg *model.Package) error {
+	sequence, err := s.repo.NextPackageSequence(tx, order.ID)
+	if err != nil {
+		return fmt.Errorf("failed to allocate package number: %w", err)
+	}
+	pkg.Sequence = sequence
+	pkg.PackageNo = fmt.Sprintf("%s-%03d", order.OrderNo, sequence)
+	if err := s.repo.CreatePackage(tx, pkg); err != nil {
+		return fmt.Errorf("failed to create package: %w", err)
+	}
+	for _, packed := range pkg.Lines {
+		line := findOutboundLine(order, packed.OrderLineID)
+		if line == nil {
+			return fmt.Errorf("outbound order %d has no line %d", order.ID, packed.OrderLineID)
+		}
+		line.PackedQuantity += packed.Quantity
+	}
+	return nil
+}
+
+// savePacking 保存单据各行的装箱数量；已拣数量全部装箱时单据状态改为 packed，否则为 picked
+func (s *packingService) savePacking(tx *gorm.DB, order *model.OutboundOrder) error {
+	packed := true
+	for i := range order.Lines {
+		line := &order.Lines[i]
+		if line.PackedQuantity < line.PickedQuantity {
+			packed = false
+		}
+		if err := s.outboundRepo.UpdateLine(tx, line); err != nil {
+			return fmt.Errorf("failed to update outbound order line: %w", err)
+		}
+	}
+	status := model.OutboundOrderStatusPicked
+	if packed {
+		status = model.OutboundOrderStatusPacked
+	}
+	if order.Status != status {
+		order.Status = status
+		if err := s.outboundRepo.UpdateStatus(tx, order); err != nil {
+			return fmt.Errorf("failed to update outbound order: %w", err)
+		}
+	}
+	return nil
+}
+
+// PackCarton 手工装箱
+// 每行装箱数量不能超过该行待装箱数量；物料登记了重量时，内装重量不能超过箱型载重
+func (s *packingService) PackCarton(orderID uint, input PackInput) (*model.Package, error) {
+	if strings.TrimSpace(input.PackedBy) == "" {
+		return nil, fmt.Errorf("%w: packed_by is required", ErrInvalidInput)
+	}
+	if len(input.Lines) == 0 {
+		return nil, fmt.Errorf("%w: at least one line is required", ErrInvalidInput)
+	}
+
+	var pkg *model.Package
+	err := s.retry.run(s.logger, "pack_carton", func() error {
+		return runInTransaction(s.repo, s.logger, "pack_carton", func(tx *gorm.DB) error {
+			order, err := s.packableOrder(tx, orderID)
+			if err != nil {
+				return err
+			}
+			cartons, err := s.repo.ListActiveCartonTypes(tx)
+			if err != nil {
+				return fmt.Errorf("failed to fetch carton types: %w", err)
+			}
+			var carton *model.CartonType
+			for i := range cartons {
+				if cartons[i].Code == input.CartonTypeCode {
+					carton = &cartons[i]
+				}
+			}
+			if carton == nil {
+				return fmt.Errorf("%w: carton type %s does not exist or is inactive", ErrInvalidInput, input.CartonTypeCode)
+			}
+
+			lines, err := packLines(order, input.Lines)
+			if err != nil {
+				return err
+			}
+			codes := make([]string, 0, len(lines))
+			for _, line := range lines {
+				codes = append(codes, line.MaterialCode)
+			}
+			materials, err := s.materialIndex(tx, codes)
+			if err != nil {
+				return err
+			}
+			weights := make(map[uint]float64, len(lines))
+			contents := 0.0
+			for _, line := range lines {
+				weights[line.OrderLineID] = materials[line.MaterialCode].WeightKg
+				contents += weights[line.OrderLineID] * float64(line.Quantity)
+			}
+			if carton.MaxWeightKg > 0 && contents > carton.MaxWeightKg {
+				return fmt.Errorf("%w: contents weigh %.2f kg, carton %s holds at most %.2f kg",
+					ErrInvalidInput, contents, carton.Code, carton.MaxWeightKg)
+			}
+
+			pkg = newPackage(order, *carton, lines, weights, input.PackStation, input.PackedBy)
+			if err := s.createPackage(tx, order, pkg); err != nil {
+				return err
+			}
+			return s.savePacking(tx, order)
+		})
+	})
+	if err != nil {
+		s.logger.Warn("Packing failed", zap.Uint("order_id", orderID), zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.Info("Package packed",
+		zap.Uint("order_id", orderID),
+		zap.String("package_no", pkg.PackageNo),
+		zap.String("carton_type", pkg.CartonTypeCode),
+		zap.Float64("gross_weight_kg", pkg.GrossWeightKg),
+		zap.String("packed_by", input.PackedBy),
+	)
+	return pkg, nil
+}
+
+// packLines 校验手工装箱的数量并转换为装箱行，同一单据行的数量合并
+func packLines(order *model.OutboundOrder, inputs []PackLineInput) ([]model.PackageLine, error) {
+	quantities := make(map[uint]int)
+	var ids []uint
+	for _, input := range inputs {
+		if input.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: quantity must be positive for line %d", ErrInvalidInput, input.LineID)
+		}
+		if _, ok := quantities[input.LineID]; !ok {
+			ids = append(ids, input.LineID)
+		}
+		quantities[input.LineID] += input.Quantity
+	}
+
+	lines := make([]model.PackageLine, 0, len(ids))
+	for _, id := range ids {
+		line := findOutboundLine(order, id)
+		if line == nil {
+			return nil, fmt.Errorf("%w: outbound order %s has no line %d", ErrInvalidInput, order.OrderNo, id)
+		}
+		if open := line.PickedQuantity - line.PackedQuantity; quantities[id] > open {
+			return nil, fmt.Errorf("%w: line %d has %d left to pack, cannot pack %d", ErrInvalidInput, line.LineNo, open, quantities[id])
+		}
+		lines = append(lines, model.PackageLine{OrderLineID: id, MaterialCode: line.MaterialCode, Quantity: quantities[id]})
+	}
+	return lines, nil
+}
+
+// ListPackages 查询单据的全部包裹
+func (s *packingService) ListPackages(orderID uint) ([]model.Package, error) {
+	var packages []model.Package
+	err := runInTransaction(s.repo, s.logger, "list_packages", func(tx *gorm.DB) error {
+		var err error
+		packages, err = s.repo.ListPackages(tx, orderID)
+		return err
+	})
+	if err != nil {
+		s.logger.Error("Failed to list packages", zap.Uint("order_id", orderID), zap.Error(err))
+		return nil, fmt.Errorf("failed to list packages: %w", err)
+	}
+	return packages, nil
+}
+
+// Unpack 拆除包裹
+// 先读取包裹确定所属单据，锁定单据后再次读取包裹；已发运的包裹返回 ErrInvalidState
+func (s *packingService) Unpack(packageID uint) error {
+	var pkg *model.Package
+	err := s.retry.run(s.logger, "unpack", func() error {
+		return runInTransaction(s.repo, s.logger, "unpack", func(tx *gorm.DB) error {
+			var err error
+			pkg, err = s.repo.GetPackage(tx, packageID)
+			if err != nil {
+				return fmt.Errorf("failed to fetch package: %w", err)
+			}
+			if pkg == nil {
+				return fmt.Errorf("%w: package %d", ErrNotFound, packageID)
+			}
+			order, err := s.outboundRepo.GetForUpdate(tx, pkg.OrderID)
+			if err != nil {
+				return fmt.Errorf("failed to fetch outbound order: %w", err)
+			}
+			if order == nil {
+				return fmt.Errorf("%w: outbound order %d", ErrNotFound, pkg.OrderID)
+			}
+			// 锁定单据后重新读取，避免并发拆箱重复退回装箱数量
+			if pkg, err = s.repo.GetPackage(tx, packageID); err != nil {
+				return fmt.Errorf("failed to fetch package: %w", err)
+			}
+			if pkg == nil {
+				return fmt.Errorf("%w: package %d", ErrNotFound, packageID)
+			}
+			if pkg.Status != model.PackageStatusPacked || order.Status == model.OutboundOrderStatusShipped {
+				return fmt.Errorf("%w: package %s has already shipped", ErrInvalidState, pkg.PackageNo)
+			}
+
+			for _, packed := range pkg.Lines {
+				line := findOutboundLine(order, packed.OrderLineID)
+				if line == nil {
+					return fmt.Errorf("outbound order %d has no line %d", order.ID, packed.OrderLineID)
+				}
+				line.PackedQuantity -= packed.Quantity
+			}
+			if err := s.repo.DeletePackage(tx, pkg.ID); err != nil {
+				return fmt.Errorf("failed to delete package: %w", err)
+			}
+			return s.savePacking(tx, order)
+		})
+	})
+	if err != nil {
+		s.logger.Warn("Unpack failed", zap.Uint("package_id", packageID), zap.Error(err))
+		return err
+	}
+
+	s.logger.Info("Package unpacked", zap.Uint("package_id", packageID), zap.String("package_no", pkg.PackageNo))
+	return nil
+}
+
+// ShipOrder 发运确认
+// 单据须已全部装箱（packed）；按物料从包装暂存库位扣减已拣数量并消耗预留，写入 ship 流水，
+// 单据状态改为 shipped，包裹关联到发运记录。锁定顺序：出库单据 -> 库存行（按物料）
+func (s *packingService) ShipOrder(orderID uint, input ShipInput) (*ShipmentManifest, error) {
+	if strings.TrimSpace(input.ShippedBy) == "" {
+		return nil, fmt.Errorf("%w: shipped_by is required", ErrInvalidInput)
+	}
+
+	var manifest *ShipmentManifest
+	err := s.retry.run(s.logger, "ship_order", func() error {
+		return runInTransaction(s.repo, s.logger, "ship_order", func(tx *gorm.DB) error {
+			order, err := s.outboundRepo.GetForUpdate(tx, orderID)
+			if err != nil {
+				return fmt.Errorf("failed to fetch outbound order: %w", err)
+			}
+			if order == nil {
+				return fmt.Errorf("%w: outbound order %d", ErrNotFound, orderID)
+			}
+			if order.Status != model.OutboundOrderStatusPacked {
+				return fmt.Errorf("%w: outbound order %s is %s, only packed orders can be shipped", ErrInvalidState, order.OrderNo, order.Status)
+			}
+			packages, err := s.repo.ListPackages(tx, order.ID)
+			if err != nil {
+				return fmt.Errorf("failed to fetch packages: %w", err)
+			}
+
+			now := time.Now()
+			shipment := &model.Shipment{
+				OrderID:      order.ID,
+				Carrier:      order.Carrier,
+				TrackingNo:   input.TrackingNo,
+				PackageCount: len(packages),
+				ShippedBy:    input.ShippedBy,
+				ShippedAt:    now,
+			}
+			for _, pkg := range packages {
+				shipment.TotalWeightKg += pkg.GrossWeightKg
+			}
+			if err := s.repo.CreateShipment(tx, shipment); err != nil {
+				return fmt.Errorf("failed to create shipment: %w", err)
+			}
+
+			if err := s.deductStaged(tx, order, shipment, input.ShippedBy); err != nil {
+				return err
+			}
+			for i := range order.Lines {
+				line := &order.Lines[i]
+				line.ShippedQuantity = line.PickedQuantity
+				if err := s.outboundRepo.UpdateLine(tx, line); err != nil {
+					return fmt.Errorf("failed to update outbound order line: %w", err)
+				}
+			}
+			order.Status = model.OutboundOrderStatusShipped
+			order.ShippedAt = &now
+			if err := s.outboundRepo.UpdateStatus(tx, order); err != nil {
+				return fmt.Errorf("failed to update outbound order: %w", err)
+			}
+			if err := s.repo.MarkPackagesShipped(tx, order.ID, shipment.ID, now); err != nil {
+				return fmt.Errorf("failed to update packages: %w", err)
+			}
+
+			manifest = buildManifest(order, shipment, packages)
+			return nil
+		})
+	})
+	if err != nil {
+		s.logger.Warn("Shipment confirmation failed", zap.Uint("order_id", orderID), zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.Info("Order shipped",
+		zap.Uint("order_id", orderID),
+		zap.String("order_no", manifest.OrderNo),
+		zap.Uint("shipment_id", manifest.ShipmentID),
+		zap.String("carrier", manifest.Carrier),
+		zap.String("tracking_no", manifest.TrackingNo),
+		zap.Int("package_count", manifest.PackageCount),
+		zap.Int("total_quantity", manifest.TotalQuantity),
+		zap.String("shipped_by", input.ShippedBy),
+	)
+	return manifest, nil
+}
+
+// deductStaged 按物料与批次从单据的包装暂存库位扣减已拣数量并消耗对应预留
+// 各批次的已拣数量取自已拣货确认的预留记录，序列号管理的物料发出拣货时为该单据扫描的序列号；
+// 未记录暂存库位的单据在启用包装暂存前已完成拣货，拣货时已扣减库存，这里不再扣减
+func (s *packingService) deductStaged(tx *gorm.DB, order *model.OutboundOrder, shipment *model.Shipment, operatorID string) error {
+	if order.StagingLocation == "" {
+		return nil
+	}
+	allocations, err := s.outboundRepo.ListPickedAllocations(tx, order.ID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch picked allocations: %w", err)
+	}
+	quantities := make(map[stockKey]int)
+	keys := make([]stockKey, 0)
+	for _, allocation := range allocations {
+		key := stockKey{materialCode: allocation.MaterialCode, locationCode: order.StagingLocation, lotNumber: allocation.LotNumber}
+		if _, ok := quantities[key]; !ok {
+			keys = append(keys, key)
+		}
+		quantities[key] += allocation.PickedQuantity
+	}
+
+	for _, key := range keys {
+		if _, err := s.ledger.apply(tx, StockChange{
+			MaterialCode:    key.materialCode,
+			LocationCode:    key.locationCode,
+			LotNumber:       key.lotNumber,
+			Delta:           -quantities[key],
+			ReservedDelta:   -quantities[key],
+			MovementType:    model.MovementTypeShip,
+			ReferenceType:   model.ReferenceTypeShipment,
+			ReferenceID:     strconv.FormatUint(uint64(shipment.ID), 10),
+			FromLocation:    order.StagingLocation,
+			OperatorID:      operatorID,
+			OutboundOrderID: order.ID,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetManifest 查询发运清单
+func (s *packingService) GetManifest(orderID uint) (*ShipmentManifest, error) {
+	var manifest *ShipmentManifest
+	err := runInTransaction(s.repo, s.logger, "shipment_manifest", func(tx *gorm.DB) error {
+		shipment, err := s.repo.GetShipmentByOrder(tx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to fetch shipment: %w", err)
+		}
+		if shipment == nil {
+			return fmt.Errorf("%w: outbound order %d has not shipped", ErrNotFound, orderID)
+		}
+		order, err := s.outboundRepo.GetByID(orderID)
+		if err != nil {
+			return fmt.Errorf("failed to fetch outbound order: %w", err)
+		}
+		if order == nil {
+			return fmt.Errorf("%w: outbound order %d", ErrNotFound, orderID)
+		}
+		packages, err := s.repo.ListPackages(tx, orderID)
+		if err != nil {
+			return fmt.Errorf("failed to fetch packages: %w", err)
+		}
+		manifest = buildManifest(order, shipment, packages)
+		return nil
+	})
+	if err != nil {
+		s.logger.Warn("Failed to fetch shipment manifest", zap.Uint("order_id", orderID), zap.Error(err))
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// buildManifest 根据单据、发运记录与包裹生成发运清单
+func buildManifest(order *model.OutboundOrder, shipment *model.Shipment, packages []model.Package) *ShipmentManifest {
+	lineNos := make(map[uint]int, len(order.Lines))
+	for _, line := range order.Lines {
+		lineNos[line.ID] = line.LineNo
+	}
+
+	manifest := &ShipmentManifest{
+		ShipmentID:    shipment.ID,
+		OrderNo:       order.OrderNo,
+		CustomerCode:  order.CustomerCode,
+		ShipTo:        order.ShipTo,
+		Carrier:       shipment.Carrier,
+		TrackingNo:    shipment.TrackingNo,
+		ShippedBy:     shipment.ShippedBy,
+		ShippedAt:     shipment.ShippedAt,
+		PackageCount:  len(packages),
+		TotalWeightKg: shipment.TotalWeightKg,
+		Packages:      make([]ManifestPackage, 0, len(packages)),
+	}
+	for _, pkg := range packages {
+		entry := ManifestPackage{
+			PackageNo:      pkg.PackageNo,
+			CartonTypeCode: pkg.CartonTypeCode,
+			LengthCm:       pkg.LengthCm,
+			WidthCm:        pkg.WidthCm,
+			HeightCm:       pkg.HeightCm,
+			GrossWeightKg:  pkg.GrossWeightKg,
+			Items:          make([]ManifestItem, 0, len(pkg.Lines)),
+		}
+		for _, line := range pkg.Lines {
+			entry.Items = append(entry.Items, ManifestItem{
+				LineNo:       lineNos[line.OrderLineID],
+				MaterialCode: line.MaterialCode,
+				Quantity:     line.Quantity,
+			})
+			manifest.TotalQuantity += line.Quantity
+		}
+		manifest.Packages = append(manifest.Packages, entry)
+	}
+	return manifest
+}
@@ -0,0 +1,655 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"wms/internal/model"
+	"wms/internal/repository"
+	"wms/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// DefaultPutawayPolicy 返回尚未配置上架规则时使用的默认规则
+func DefaultPutawayPolicy() model.PutawayPolicy {
+	return model.PutawayPolicy{
+		ID: 1,
+		Strategies: strings.Join([]string{
+			model.PutawayStrategyFixedBin,
+			model.PutawayStrategyConsolidate,
+			model.PutawayStrategyNearestEmpty,
+		}, ","),
+		EnforceCapacity: true,
+	}
+}
+
+// PutawayPolicyInput 表示更新上架规则的输入
+type PutawayPolicyInput struct {
+	Strategies      []string
+	EnforceCapacity bool
+	UpdatedBy       string
+}
+
+// PutawayTaskInput 表示手工创建上架任务的输入，LotNumber 为搬运的批次
+type PutawayTaskInput struct {
+	MaterialCode   string
+	LotNumber      string
+	SourceLocation string
+	Quantity       int
+	CreatedBy      string
+}
+
+// PutawayOverrideInput 表示改派上架库位的输入
+type PutawayOverrideInput struct {
+	LocationCode string
+	OperatorID   string
+	Reason       string
+}
+
+// PutawayService 定义上架规则与上架任务的业务接口
+type PutawayService interface {
+	// GetPolicy 查询上架规则，尚未配置时返回默认规则
+	GetPolicy() (*model.PutawayPolicy, error)
+
+	// UpdatePolicy 更新上架规则
+	UpdatePolicy(input PutawayPolicyInput) (*model.PutawayPolicy, error)
+
+	// ListFixedBins 查询全部物料固定库位
+	ListFixedBins() ([]model.PutawayFixedBin, error)
+
+	// UpsertFixedBin 设置物料的固定库位
+	UpsertFixedBin(materialCode, locationCode string) (*model.PutawayFixedBin, error)
+
+	// CreateTask 为已在收货库位的物料创建上架任务并推荐目标库位
+	CreateTask(input PutawayTaskInput) (*model.PutawayTask, error)
+
+	// ListTasks 按过滤条件查询上架任务
+	ListTasks(filter repository.PutawayTaskFilter) ([]model.PutawayTask, error)
+
+	// AssignTask 将未完成的上架任务指派给作业人员
+	AssignTask(id uint, assignee string) (*model.PutawayTask, error)
+
+	// OverrideTask 在确认前改派上架库位，须给出原因
+	OverrideTask(id uint, input PutawayOverrideInput) (*model.PutawayTask, error)
+
+	// ConfirmTask 确认上架，在同一事务中扣减来源库位库存并增加目标库位库存
+	// 任务没有目标库位或目标库位容量不足时返回 ErrInvalidState
+	ConfirmTask(id uint, operatorID string) (*model.PutawayTask, error)
+}
+
+// putawayPlanner 按上架规则为新任务推荐目标库位
+// 收货与手工创建任务共用，在调用方事务中执行
+type putawayPlanner struct {
+	repo         repository.PutawayRepository
+	locationRepo repository.LocationRepository
+	stockRepo    repository.StockRepository
+}
+
+// newPutawayPlanner 创建上架推荐器
+func newPutawayPlanner(repo repository.PutawayRepository, locationRepo repository.LocationRepository, stockRepo repository.StockRepository) *putawayPlanner {
+	return &putawayPlanner{repo: repo, locationRepo: locationRepo, stockRepo: stockRepo}
+}
+
+// material 在事务中读取物料主数据，用于按体积与重量校验库位容量
+func (p *putawayPlanner) material(tx *gorm.DB, code string) (*model.Material, error) {
+	material, err := p.stockRepo.GetMaterial(tx, code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch material: %w", err)
+	}
+	return material, nil
+}
+
+// policy 在事务中读取上架规则，尚未配置时返回默认规则
+func (p *putawayPlanner) policy(tx *gorm.DB) (*model.PutawayPolicy, error) {
+	policy, err := p.repo.GetPolicy(tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch putaway policy: %w", err)
+	}
+	if policy == nil {
+		defaults := DefaultPutawayPolicy()
+		policy = &defaults
+	}
+	return policy, nil
+}
+
+// createTask 推荐目标库位并创建上架任务
+// 没有可用库位时任务仍会创建，目标库位为空，需改派后才能确认
+func (p *putawayPlanner) createTask(tx *gorm.DB, task *model.PutawayTask) error {
+	policy, err := p.policy(tx)
+	if err != nil {
+		return err
+	}
+	locations, err := p.locationRepo.ListAll(tx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch locations: %w", err)
+	}
+	loads, err := p.loads(tx)
+	if err != nil {
+		return err
+	}
+	material, err := p.material(tx, task.MaterialCode)
+	if err != nil {
+		return err
+	}
+	fixedBin := ""
+	bin, err := p.repo.GetFixedBin(tx, task.MaterialCode)
+	if err != nil {
+		return fmt.Errorf("failed to fetch fixed bin: %w", err)
+	}
+	if bin != nil {
+		fixedBin = bin.LocationCode
+	}
+	stocks, err := p.repo.ListMaterialLocations(tx, task.MaterialCode)
+	if err != nil {
+		return fmt.Errorf("failed to fetch material locations: %w", err)
+	}
+	holding := make([]string, 0, len(stocks))
+	for _, stock := range stocks {
+		holding = append(holding, stock.LocationCode)
+	}
+
+	task.SuggestedLocation, task.Strategy = suggestPutaway(putawayRequest{
+		strategies:      parseStrategies(policy.Strategies),
+		enforceCapacity: policy.EnforceCapacity,
+		source:          task.SourceLocation,
+		material:        material,
+		quantity:        task.Quantity,
+		fixedBin:        fixedBin,
+		holding:         holding,
+		locations:       locations,
+		loads:           loads,
+	})
+	task.TargetLocation = task.SuggestedLocation
+	task.Status = model.PutawayTaskStatusOpen
+	if err := p.repo.CreateTask(tx, task); err != nil {
+		return fmt.Errorf("failed to create putaway task: %w", err)
+	}
+	return nil
+}
+
+// loads 统计各库位现有库存与未完成上架任务待入的占用之和
+func (p *putawayPlanner) loads(tx *gorm.DB) (map[string]*locationUsage, error) {
+	stock, err := p.repo.StockLoads(tx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch location loads: %w", err)
+	}
+	incoming, err := p.repo.IncomingLoads(tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch incoming putaway loads: %w", err)
+	}
+	return locationUsages(stock, incoming), nil
+}
+
+// locationUsage 表示库位的占用：数量、体积、重量合计与存放的物料
+type locationUsage struct {
+	quantity  int
+	volume    float64
+	weight    float64
+	materials map[string]bool
+}
+
+// locationUsages 按库位汇总各物料的占用
+func locationUsages(loads ...[]repository.LocationLoad) map[string]*locationUsage {
+	usages := make(map[string]*locationUsage)
+	for _, list := range loads {
+		for _, l := range list {
+			u := usages[l.LocationCode]
+			if u == nil {
+				u = &locationUsage{materials: make(map[string]bool)}
+				usages[l.LocationCode] = u
+			}
+			u.quantity += l.Quantity
+			u.volume += l.VolumeCm3
+			u.weight += l.WeightKg
+			u.materials[l.MaterialCode] = true
+		}
+	}
+	return usages
+}
+
+// capacityShortfall 返回库位在现有占用基础上放入 quantity 件物料后超出的容量限制，能放下时返回空字符串
+// 虚拟库位不校验容量；物料未登记尺寸或重量时不计入体积与重量
+func capacityShortfall(loc *model.Location, usage *locationUsage, material *model.Material, quantity int) string {
+	if loc.Type == model.LocationTypeVirtual {
+		return ""
+	}
+	if usage == nil {
+		usage = &locationUsage{}
+	}
+	if !loc.Fits(usage.quantity, quantity) {
+		return fmt.Sprintf("holds %d of %d units, cannot take %d more", usage.quantity, loc.Capacity, quantity)
+	}
+	var volume, weight float64
+	if material != nil {
+		volume = material.Volume() * float64(quantity)
+		weight = material.WeightKg * float64(quantity)
+	}
+	if loc.MaxVolumeCm3 > 0 && usage.volume+volume > loc.MaxVolumeCm3 {
+		return fmt.Sprintf("holds %.0f of %.0f cm3, cannot take %.0f more", usage.volume, loc.MaxVolumeCm3, volume)
+	}
+	if loc.MaxWeightKg > 0 && usage.weight+weight > loc.MaxWeightKg {
+		return fmt.Sprintf("holds %.2f of %.2f kg, cannot take %.2f more", usage.weight, loc.MaxWeightKg, weight)
+	}
+	if loc.MaxSkus > 0 && material != nil && !usage.materials[material.Code] && len(usage.materials)+1 > loc.MaxSkus {
+		return fmt.Sprintf("already holds %d of %d materials", len(usage.materials), loc.MaxSkus)
+	}
+	return ""
+}
+
+// putawayRequest 是上架推荐的输入
+// locations 为库位主数据（按远近顺序），loads 为各库位已占用情况，holding 为该物料有库存的库位；
+// material 为上架物料的主数据，用于校验体积、重量与物料种类上限
+type putawayRequest struct {
+	strategies      []string
+	enforceCapacity bool
+	source          string
+	material        *model.Material
+	quantity        int
+	fixedBin        string
+	holding         []string
+	locations       []model.Location
+	loads           map[string]*locationUsage
+}
+
+// suggestPutaway 按策略顺序推荐目标库位，返回库位与生效的策略，没有可用库位时均为空
+// 目标库位须登记在库位主数据中、为启用且未冻结的拣货位或存储位、允许上架，启用容量限制时还须放得下。
+// 最近空库位只在库位主数据中按远近顺序查找
+func suggestPutaway(req putawayRequest) (string, string) {
+	byCode := make(map[string]*model.Location, len(req.locations))
+	for i := range req.locations {
+		byCode[req.locations[i].Code] = &req.locations[i]
+	}
+	usable := func(code string) bool {
+		if code == "" || code == req.source {
+			return false
+		}
+		loc, ok := byCode[code]
+		if !ok || !loc.Operable() || !loc.IsStorage() || !loc.PutawayEnabled {
+			return false
+		}
+		return !req.enforceCapacity || capacityShortfall(loc, req.loads[code], req.material, req.quantity) == ""
+	}
+
+	for _, strategy := range req.strategies {
+		switch strategy {
+		case model.PutawayStrategyFixedBin:
+			if usable(req.fixedBin) {
+				return req.fixedBin, strategy
+			}
+		case model.PutawayStrategyConsolidate:
+			held := make(map[string]bool, len(req.holding))
+			for _, code := range req.holding {
+				held[code] = true
+			}
+			// 按库位主数据的远近顺序选择
+			for _, loc := range req.locations {
+				if held[loc.Code] && usable(loc.Code) {
+					return loc.Code, strategy
+				}
+			}
+		case model.PutawayStrategyNearestEmpty:
+			for _, loc := range req.locations {
+				if u := req.loads[loc.Code]; (u == nil || u.quantity == 0) && usable(loc.Code) {
+					return loc.Code, strategy
+				}
+			}
+		}
+	}
+	return "", ""
+}
+
+// parseStrategies 解析逗号分隔的策略列表
+func parseStrategies(value string) []string {
+	var strategies []string
+	for _, s := range strings.Split(value, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			strategies = append(strategies, s)
+		}
+	}
+	return strategies
+}
+
+// putawayService 是 PutawayService 的具体实现
+type putawayService struct {
+	repo         repository.PutawayRepository
+	locationRepo repository.LocationRepository
+	planner      *putawayPlanner
+	ledger       *stockLedger
+	retry        RetryPolicy
+	logger       *logger.Logger
+}
+
+// NewPutawayService 创建新的 PutawayService 实例
+func NewPutawayService(repo repository.PutawayRepository, locationRepo repository.LocationRepository, stockRepo repository.StockRepository, retry RetryPolicy, log *logger.Logger) PutawayService {
+	return &putawayService{
+		repo:         repo,
+		locationRepo: locationRepo,
+		planner:      newPutawayPlanner(repo, locationRepo, stockRepo),
+		ledger:       newStockLedger(stockRepo),
+		retry:        retry,
+		logger:       log,
+	}
+}
+
+// GetPolicy 查询上架规则
+func (s *putawayService) GetPolicy() (*model.PutawayPolicy, error) {
+	var policy *model.PutawayPolicy
+	err := runInTransaction(s.repo, s.logger, "putaway_policy", func(tx *gorm.DB) error {
+		var err error
+		policy, err = s.planner.policy(tx)
+		return err
+	})
+	if err != nil {
+		s.logger.Error("Failed to fetch putaway policy", zap.Error(err))
+		return nil, err
+	}
+	return policy, nil
+}
+
+// UpdatePolicy 校验并保存上架规则
+func (s *putawayService) UpdatePolicy(input PutawayPolicyInput) (*model.PutawayPolicy, error) {
+	if len(input.Strategies) == 0 {
+		return nil, fmt.Errorf("%w: at least one strategy is required", ErrInvalidInput)
+	}
+	seen := make(map[string]bool, len(input.Strategies))
+	for _, strategy := range input.Strategies {
+		switch strategy {
+		case model.PutawayStrategyFixedBin, model.PutawayStrategyConsolidate, model.PutawayStrategyNearestEmpty:
+		default:
+			return nil, fmt.Errorf("%w: unsupported strategy %q", ErrInvalidInput, strategy)
+		}
+		if seen[strategy] {
+			return nil, fmt.Errorf("%w: duplicate strategy %q", ErrInvalidInput, strategy)
+		}
+		seen[strategy] = true
+	}
+
+	policy := &model.PutawayPolicy{
+		Strategies:      strings.Join(input.Strategies, ","),
+		EnforceCapacity: input.EnforceCapacity,
+		UpdatedBy:       input.UpdatedBy,
+	}
+	if err := s.repo.SavePolicy(policy); err != nil {
+		s.logger.Error("Failed to save putaway policy", zap.Error(err))
+		return nil, fmt.Errorf("failed to save putaway policy: %w", err)
+	}
+
+	s.logger.Info("Putaway policy updated",
+		zap.String("strategies", policy.Strategies),
+		zap.Bool("enforce_capacity", policy.EnforceCapacity),
+		zap.String("updated_by", input.UpdatedBy),
+	)
+	return policy, nil
+}
+
+// ListFixedBins 查询全部物料固定库位
+func (s *putawayService) ListFixedBins() ([]model.PutawayFixedBin, error) {
+	bins, err := s.repo.ListFixedBins()
+	if err != nil {
+		s.logger.Error("Failed to list fixed bins", zap.Error(err))
+		return nil, fmt.Errorf("failed to list fixed bins: %w", err)
+	}
+	return bins, nil
+}
+
+// UpsertFixedBin 设置物料的固定库位
+func (s *putawayService) UpsertFixedBin(materialCode, locationCode string) (*model.PutawayFixedBin, error) {
+	if materialCode == "" || locationCode == "" {
+		return nil, fmt.Errorf("%w: material_code and location_code are required", ErrInvalidInput)
+	}
+
+	bin := &model.PutawayFixedBin{MaterialCode: materialCode, LocationCode: locationCode}
+	if err := s.repo.UpsertFixedBin(bin); err != nil {
+		s.logger.Error("Failed to save fixed bin",
+			zap.String("material_code", materialCode),
+			zap.String("location_code", locationCode),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to save fixed bin: %w", err)
+	}
+
+	s.logger.Info("Fixed bin saved",
+		zap.String("material_code", materialCode),
+		zap.String("location_code", locationCode),
+	)
+	return bin, nil
+}
+
+// CreateTask 手工创建上架任务
+func (s *putawayService) CreateTask(input PutawayTaskInput) (*model.PutawayTask, error) {
+	if input.MaterialCode == "" || input.SourceLocation == "" {
+		return nil, fmt.Errorf("%w: material_code and source_location are required", ErrInvalidInput)
+	}
+	if input.Quantity <= 0 {
+		return nil, fmt.Errorf("%w: quantity must be positive", ErrInvalidInput)
+	}
+	if input.CreatedBy == "" {
+		return nil, fmt.Errorf("%w: created_by is required", ErrInvalidInput)
+	}
+
+	var task *model.PutawayTask
+	err := s.retry.run(s.logger, "putaway_create", func() error {
+		return runInTransaction(s.repo, s.logger, "putaway_create", func(tx *gorm.DB) error {
+			task = &model.PutawayTask{
+				MaterialCode:   input.MaterialCode,
+				LotNumber:      input.LotNumber,
+				SourceLocation: input.SourceLocation,
+				Quantity:       input.Quantity,
+				CreatedBy:      input.CreatedBy,
+			}
+			return s.planner.createTask(tx, task)
+		})
+	})
+	if err != nil {
+		s.logger.Error("Failed to create putaway task",
+			zap.String("material_code", input.MaterialCode),
+			zap.String("source_location", input.SourceLocation),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	s.logger.Info("Putaway task created",
+		zap.Uint("task_id", task.ID),
+		zap.String("material_code", task.MaterialCode),
+		zap.String("suggested_location", task.SuggestedLocation),
+		zap.String("strategy", task.Strategy),
+	)
+	return task, nil
+}
+
+// ListTasks 查询上架任务
+func (s *putawayService) ListTasks(filter repository.PutawayTaskFilter) ([]model.PutawayTask, error) {
+	tasks, err := s.repo.ListTasks(filter)
+	if err != nil {
+		s.logger.Error("Failed to list putaway tasks", zap.Error(err))
+		return nil, fmt.Errorf("failed to list putaway tasks: %w", err)
+	}
+	return tasks, nil
+}
+
+// AssignTask 指派上架任务
+func (s *putawayService) AssignTask(id uint, assignee string) (*model.PutawayTask, error) {
+	if assignee == "" {
+		return nil, fmt.Errorf("%w: assignee is required", ErrInvalidInput)
+	}
+	task, err := s.updateOpenTask(id, "putaway_assign", func(tx *gorm.DB, task *model.PutawayTask) error {
+		task.AssignedTo = assignee
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Putaway task assigned", zap.Uint("task_id", id), zap.String("assigned_to", assignee))
+	return task, nil
+}
+
+// OverrideTask 改派上架库位
+// 目标库位已登记在库位主数据中时须允许上架；容量在确认时校验
+func (s *putawayService) OverrideTask(id uint, input PutawayOverrideInput) (*model.PutawayTask, error) {
+	if input.LocationCode == "" || input.OperatorID == "" {
+		return nil, fmt.Errorf("%w: location_code and operator_id are required", ErrInvalidInput)
+	}
+	if strings.TrimSpace(input.Reason) == "" {
+		return nil, fmt.Errorf("%w: reason is required to override the suggested location", ErrInvalidInput)
+	}
+
+	task, err := s.updateOpenTask(id, "putaway_override", func(tx *gorm.DB, task *model.PutawayTask) error {
+		if input.LocationCode == task.SourceLocation {
+			return fmt.Errorf("%w: target location cannot be the source location %s", ErrInvalidInput, task.SourceLocation)
+		}
+		location, err := s.locationRepo.GetByCode(tx, input.LocationCode)
+		if err != nil {
+			return fmt.Errorf("failed to fetch location: %w", err)
+		}
+		switch {
+		case location == nil:
+			return fmt.Errorf("%w: %s", ErrUnknownLocation, input.LocationCode)
+		case !location.Active:
+			return fmt.Errorf("%w: %s", ErrInactiveLocation, input.LocationCode)
+		case location.Blocked:
+			return fmt.Errorf("%w: %s %s", ErrLocationBlocked, input.LocationCode, location.BlockReason)
+		case !location.PutawayEnabled || !location.IsStorage():
+			return fmt.Errorf("%w: location %s does not accept putaway", ErrInvalidInput, input.LocationCode)
+		}
+		task.TargetLocation = input.LocationCode
+		task.OverriddenBy = input.OperatorID
+		task.OverrideReason = input.Reason
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Putaway task overridden",
+		zap.Uint("task_id", id),
+		zap.String("suggested_location", task.SuggestedLocation),
+		zap.String("target_location", task.TargetLocation),
+		zap.String("operator_id", input.OperatorID),
+	)
+	return task, nil
+}
+
+// ConfirmTask 确认上架
+// 锁定顺序：上架任务 -> 目标库位 -> 库存行（按库位编码顺序）
+func (s *putawayService) ConfirmTask(id uint, operatorID string) (*model.PutawayTask, error) {
+	if operatorID == "" {
+		return nil, fmt.Errorf("%w: operator_id is required", ErrInvalidInput)
+	}
+
+	task, err := s.updateOpenTask(id, "putaway_confirm", func(tx *gorm.DB, task *model.PutawayTask) error {
+		if task.TargetLocation == "" {
+			return fmt.Errorf("%w: putaway task %d has no target location, override it first", ErrInvalidState, task.ID)
+		}
+		if task.AssignedTo != "" && task.AssignedTo != operatorID {
+			return fmt.Errorf("%w: putaway task %d is assigned to %s", ErrInvalidInput, task.ID, task.AssignedTo)
+		}
+		if err := s.checkCapacity(tx, task); err != nil {
+			return err
+		}
+
+		if err := s.ledger.move(tx, StockMove{
+			MaterialCode:  task.MaterialCode,
+			LotNumber:     task.LotNumber,
+			FromLocation:  task.SourceLocation,
+			ToLocation:    task.TargetLocation,
+			Quantity:      task.Quantity,
+			MovementType:  model.MovementTypePutaway,
+			ReferenceType: model.ReferenceTypePutawayTask,
+			ReferenceID:   strconv.FormatUint(uint64(task.ID), 10),
+			OperatorID:    operatorID,
+		}); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		task.Status = model.PutawayTaskStatusCompleted
+		task.ConfirmedBy = operatorID
+		task.ConfirmedAt = &now
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Putaway task confirmed",
+		zap.Uint("task_id", id),
+		zap.String("material_code", task.MaterialCode),
+		zap.String("from", task.SourceLocation),
+		zap.String("to", task.TargetLocation),
+		zap.Int("quantity", task.Quantity),
+		zap.String("operator_id", operatorID),
+	)
+	return task, nil
+}
+
+// checkCapacity 在启用容量限制时锁定目标库位并校验上架后不超出数量、体积、重量与物料种类上限
+// 目标库位未登记时不在此校验，由库存变动拒绝
+func (s *putawayService) checkCapacity(tx *gorm.DB, task *model.PutawayTask) error {
+	policy, err := s.planner.policy(tx)
+	if err != nil {
+		return err
+	}
+	if !policy.EnforceCapacity {
+		return nil
+	}
+	location, err := s.locationRepo.GetForUpdate(tx, task.TargetLocation)
+	if err != nil {
+		return fmt.Errorf("failed to lock location: %w", err)
+	}
+	if location == nil {
+		return nil
+	}
+	loads, err := s.repo.StockLoads(tx, task.TargetLocation)
+	if err != nil {
+		return fmt.Errorf("failed to fetch location load: %w", err)
+	}
+	material, err := s.planner.material(tx, task.MaterialCode)
+	if err != nil {
+		return err
+	}
+	if shortfall := capacityShortfall(location, locationUsages(loads)[location.Code], material, task.Quantity); shortfall != "" {
+		return fmt.Errorf("%w: location %s %s", ErrInvalidState, location.Code, shortfall)
+	}
+	return nil
+}
+
+// updateOpenTask 在事务中锁定未完成的上架任务，执行 fn 后保存
+func (s *putawayService) updateOpenTask(id uint, operation string, fn func(tx *gorm.DB, task *model.PutawayTask) error) (*model.PutawayTask, error) {
+	var result *model.PutawayTask
+	err := s.retry.run(s.logger, operation, func() error {
+		return runInTransaction(s.repo, s.logger, operation, func(tx *gorm.DB) error {
+			task, err := s.repo.GetTaskForUpdate(tx, id)
+			if err != nil {
+				return fmt.Errorf("failed to fetch putaway task: %w", err)
+			}
+			if task == nil {
+				return fmt.Errorf("%w: putaway task %d", ErrNotFound, id)
+			}
+			if task.Status != model.PutawayTaskStatusOpen {
+				return fmt.Errorf("%w: putaway task %d is %s", ErrInvalidState, id, task.Status)
+			}
+			if err := fn(tx, task); err != nil {
+				return err
+			}
+			if err := s.repo.UpdateTask(tx, task); err != nil {
+				return fmt.Errorf("failed to update putaway task: %w", err)
+			}
+			result = task
+			return nil
+		})
+	})
+	if err != nil {
+		s.logger.Warn("Putaway task update failed",
+			zap.Uint("task_id", id),
+			zap.String("operation", operation),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	return result, nil
+}
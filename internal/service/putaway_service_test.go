@@ -0,0 +1,98 @@
+package service
+
+import (
+	"testing"
+	"wms/internal/model"
+	"wms/internal/repository"
+)
+
+// quantityUsages 按库位数量构造占用，物料编码统一为 M-OTHER
+func quantityUsages(loads map[string]int) map[string]*locationUsage {
+	rows := make([]repository.LocationLoad, 0, len(loads))
+	for code, quantity := range loads {
+		rows = append(rows, repository.LocationLoad{LocationCode: code, MaterialCode: "M-OTHER", Quantity: quantity})
+	}
+	return locationUsages(rows)
+}
+
+func TestSuggestPutaway(t *testing.T) {
+	locations := []model.Location{
+		{Code: "RECEIVING", Type: model.LocationTypeDock, Sequence: 0, PutawayEnabled: false, Active: true},
+		{Code: "A-01", Type: model.LocationTypeReserve, Sequence: 1, Capacity: 10, PutawayEnabled: true, Active: true},
+		{Code: "A-02", Type: model.LocationTypeReserve, Sequence: 2, Capacity: 10, PutawayEnabled: true, Active: true},
+		{Code: "A-03", Type: model.LocationTypePickFace, Sequence: 3, PutawayEnabled: true, Active: true},
+		{Code: "B-01", Type: model.LocationTypeReserve, Sequence: 4, PutawayEnabled: true, Active: true, Blocked: true, BlockReason: "damaged rack"},
+		{Code: "B-02", Type: model.LocationTypeReserve, Sequence: 5, PutawayEnabled: true, Active: false},
+		{Code: "QC", Type: model.LocationTypeQuarantine, Sequence: 6, PutawayEnabled: true, Active: true},
+	}
+	all := []string{model.PutawayStrategyFixedBin, model.PutawayStrategyConsolidate, model.PutawayStrategyNearestEmpty}
+
+	cases := []struct {
+		name         string
+		strategies   []string
+		enforce      bool
+		quantity     int
+		fixedBin     string
+		holding      []string
+		loads        map[string]int
+		wantLocation string
+		wantStrategy string
+	}{
+		{"fixed bin first", all, true, 5, "A-02", []string{"A-01"}, map[string]int{"A-01": 3}, "A-02", model.PutawayStrategyFixedBin},
+		{"full fixed bin falls through to consolidate", all, true, 5, "A-02", []string{"A-01"}, map[string]int{"A-01": 3, "A-02": 8}, "A-01", model.PutawayStrategyConsolidate},
+		{"consolidate over capacity picks nearest empty", all, true, 8, "", []string{"A-01"}, map[string]int{"A-01": 3}, "A-02", model.PutawayStrategyNearestEmpty},
+		{"capacity ignored when not enforced", all, false, 8, "", []string{"A-01"}, map[string]int{"A-01": 3}, "A-01", model.PutawayStrategyConsolidate},
+		{"unregistered fixed bin skipped", all, true, 50, "Z-99", nil, nil, "A-03", model.PutawayStrategyNearestEmpty},
+		{"disabled fixed bin skipped", all, true, 5, "RECEIVING", nil, nil, "A-01", model.PutawayStrategyNearestEmpty},
+		{"blocked fixed bin skipped", all, true, 5, "B-01", nil, nil, "A-01", model.PutawayStrategyNearestEmpty},
+		{"inactive fixed bin skipped", all, true, 5, "B-02", nil, nil, "A-01", model.PutawayStrategyNearestEmpty},
+		{"quarantine fixed bin skipped", all, true, 5, "QC", nil, nil, "A-01", model.PutawayStrategyNearestEmpty},
+		{"no usable location", []string{model.PutawayStrategyNearestEmpty}, true, 5, "", nil, map[string]int{"A-01": 1, "A-02": 1, "A-03": 1}, "", ""},
+	}
+	for _, tc := range cases {
+		location, strategy := suggestPutaway(putawayRequest{
+			strategies:      tc.strategies,
+			enforceCapacity: tc.enforce,
+			source:          "RECEIVING",
+			quantity:        tc.quantity,
+			fixedBin:        tc.fixedBin,
+			holding:         tc.holding,
+			locations:       locations,
+			loads:           quantityUsages(tc.loads),
+		})
+		if location != tc.wantLocation || strategy != tc.wantStrategy {
+			t.Errorf("%s: got (%q, %q), want (%q, %q)", tc.name, location, strategy, tc.wantLocation, tc.wantStrategy)
+		}
+	}
+}
+
+func TestCapacityShortfall(t *testing.T) {
+	material := &model.Material{Code: "M-001", LengthCm: 10, WidthCm: 10, HeightCm: 10, WeightKg: 2}
+	usage := locationUsages([]repository.LocationLoad{
+		{LocationCode: "A-01", MaterialCode: "M-002", Quantity: 4, VolumeCm3: 4000, WeightKg: 8},
+	})["A-01"]
+
+	cases := []struct {
+		name     string
+		location model.Location
+		material *model.Material
+		quantity int
+		wantFits bool
+	}{
+		{"no limits", model.Location{Type: model.LocationTypeReserve}, material, 100, true},
+		{"within every limit", model.Location{Type: model.LocationTypeReserve, Capacity: 10, MaxVolumeCm3: 10000, MaxWeightKg: 20, MaxSkus: 2}, material, 6, true},
+		{"over quantity", model.Location{Type: model.LocationTypeReserve, Capacity: 10}, material, 7, false},
+		{"over volume", model.Location{Type: model.LocationTypeReserve, MaxVolumeCm3: 10000}, material, 7, false},
+		{"over weight", model.Location{Type: model.LocationTypeReserve, MaxWeightKg: 20}, material, 7, false},
+		{"new material over sku limit", model.Location{Type: model.LocationTypeReserve, MaxSkus: 1}, material, 1, false},
+		{"held material within sku limit", model.Location{Type: model.LocationTypeReserve, MaxSkus: 1}, &model.Material{Code: "M-002"}, 1, true},
+		{"unknown dimensions not counted", model.Location{Type: model.LocationTypeReserve, MaxVolumeCm3: 4000, MaxWeightKg: 8}, nil, 5, true},
+		{"virtual location unlimited", model.Location{Type: model.LocationTypeVirtual, Capacity: 1}, material, 100, true},
+	}
+	for _, tc := range cases {
+		shortfall := capacityShortfall(&tc.location, usage, tc.material, tc.quantity)
+		if (shortfall == "") != tc.wantFits {
+			t.Errorf("%s: got shortfall %q, want fits %v", tc.name, shortfall, tc.wantFits)
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"time"
+	"wms/internal/model"
+	"wms/internal/repository"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// recountApprover 是复盘结果一致时自动批准差异所记录的审批人
+const recountApprover = "system"
+
+// recountContext 表示一次复盘提交所需的上下文
+type recountContext struct {
+	task  *model.RecountTask
+	head  *model.InventoryCheckRecord
+	chain []model.InventoryCheckRecord
+}
+
+// root 返回复盘链上最初的盘点记录
+func (rc *recountContext) root() *model.InventoryCheckRecord {
+	return &rc.chain[0]
+}
+
+// ListRecountTasks 查询复盘任务
+func (s *inventoryService) ListRecountTasks(status, assignedTo string) ([]model.RecountTask, error) {
+	tasks, err := s.repo.ListRecountTasks(status, assignedTo)
+	if err != nil {
+		s.logger.Error("Failed to list recount tasks",
+			zap.String("status", status),
+			zap.String("assigned_to", assignedTo),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to list recount tasks: %w", err)
+	}
+	return tasks, nil
+}
+
+// AssignRecountTask 将未完成的复盘任务指派给盘点人
+// 盘点人不能是复盘链中已参与过盘点的人
+func (s *inventoryService) AssignRecountTask(taskID uint, checkerID string) (*model.RecountTask, error) {
+	if checkerID == "" {
+		return nil, fmt.Errorf("%w: checker_id is required", ErrInvalidInput)
+	}
+
+	var task *model.RecountTask
+	err := s.options.Retry.run(s.logger, "recount_assign", func() error {
+		return runInTransaction(s.repo, s.logger, "recount_assign", func(tx *gorm.DB) error {
+			var err error
+			task, err = s.repo.GetRecountTaskForUpdate(tx, taskID)
+			if err != nil {
+				return fmt.Errorf("failed to fetch recount task: %w", err)
+			}
+			if task == nil {
+				return fmt.Errorf("%w: recount task %d", ErrNotFound, taskID)
+			}
+			if task.Status != model.RecountTaskStatusOpen {
+				return fmt.Errorf("%w: recount task %d is %s", ErrInvalidState, taskID, task.Status)
+			}
+
+			chain, err := s.repo.FindRecordChain(tx, task.RootRecordID)
+			if err != nil {
+				return fmt.Errorf("failed to fetch recount chain: %w", err)
+			}
+			if chainCheckers(chain)[checkerID] {
+				return fmt.Errorf("%w: checker %s already counted this item", ErrInvalidInput, checkerID)
+			}
+
+			task.AssignedTo = checkerID
+			if err := s.repo.UpdateRecountTask(tx, task); err != nil {
+				return fmt.Errorf("failed to update recount task: %w", err)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Recount task assigned",
+		zap.Uint("recount_task_id", taskID),
+		zap.String("checker_id", checkerID),
+	)
+	return task, nil
+}
+
+// GetRecordChain 查询盘点记录所在的复盘链，按复盘序号排序
+func (s *inventoryService) GetRecordChain(recordID uint) ([]model.InventoryCheckRecord, error) {
+	record, err := s.GetCheckRecord(recordID)
+	if err != nil {
+		return nil, err
+	}
+
+	rootID := record.ID
+	if record.RootRecordID != nil {
+		rootID = *record.RootRecordID
+	}
+	records, err := s.repo.ListCheckRecords(repository.CheckRecordFilter{
+		RootRecordID: &rootID,
+		SortBy:       repository.SortByID,
+	})
+	if err != nil {
+		s.logger.Error("Failed to fetch recount chain", zap.Uint("record_id", recordID), zap.Error(err))
+		return nil, fmt.Errorf("failed to fetch recount chain: %w", err)
+	}
+	return records, nil
+}
+
+// lockRecount 在事务中锁定复盘任务与被复盘的记录并校验本次复盘
+// 复盘沿用任务所属的盘点单；请求中指定了其他盘点单时返回 ErrInvalidInput
+func (s *inventoryService) lockRecount(tx *gorm.DB, input *InventoryCheckInput) (*recountContext, error) {
+	task, err := s.repo.GetRecountTaskForUpdate(tx, *input.RecountTaskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch recount task: %w", err)
+	}
+	if task == nil {
+		return nil, fmt.Errorf("%w: recount task %d", ErrNotFound, *input.RecountTaskID)
+	}
+	if task.Status != model.RecountTaskStatusOpen {
+		return nil, fmt.Errorf("%w: recount task %d is %s", ErrInvalidState, task.ID, task.Status)
+	}
+	if task.MaterialCode != input.MaterialCode || task.LocationCode != input.LocationCode {
+		return nil, fmt.Errorf("%w: recount task %d is for %s at %s",
+			ErrInvalidInput, task.ID, task.MaterialCode, task.LocationCode)
+	}
+	if task.AssignedTo != "" && task.AssignedTo != input.CheckerID {
+		return nil, fmt.Errorf("%w: recount task %d is assigned to another checker", ErrInvalidInput, task.ID)
+	}
+	switch {
+	case input.StockTakeID == nil:
+		input.StockTakeID = task.StockTakeID
+	case task.StockTakeID == nil || *task.StockTakeID != *input.StockTakeID:
+		return nil, fmt.Errorf("%w: recount task %d does not belong to stock take %d",
+			ErrInvalidInput, task.ID, *input.StockTakeID)
+	}
+
+	head, err := s.repo.GetCheckRecordForUpdate(tx, task.RecordID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch check record: %w", err)
+	}
+	if head == nil || head.ApprovalStatus != model.ApprovalStatusPending {
+		return nil, fmt.Errorf("%w: record %d is no longer pending recount", ErrInvalidState, task.RecordID)
+	}
+	if head.LotNumber != input.LotNumber {
+		return nil, fmt.Errorf("%w: recount task %d is for lot %q", ErrInvalidInput, task.ID, head.LotNumber)
+	}
+
+	chain, err := s.repo.FindRecordChain(tx, task.RootRecordID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch recount chain: %w", err)
+	}
+	if chainCheckers(chain)[input.CheckerID] {
+		return nil, fmt.Errorf("%w: recount must be performed by a different checker", ErrInvalidInput)
+	}
+
+	return &recountContext{task: task, head: head, chain: chain}, nil
+}
+
+// completeRecount 将被复盘的记录标记为已被取代，并关闭复盘任务
+func (s *inventoryService) completeRecount(tx *gorm.DB, rc *recountContext, record *model.InventoryCheckRecord) error {
+	rc.head.ApprovalStatus = model.ApprovalStatusSuperseded
+	rc.head.IsProcessed = true
+	if err := s.repo.UpdateCheckRecord(tx, rc.head); err != nil {
+		return fmt.Errorf("failed to update check record: %w", err)
+	}
+
+	now := time.Now()
+	rc.task.Status = model.RecountTaskStatusCompleted
+	rc.task.ResultRecordID = &record.ID
+	rc.task.ClosedAt = &now
+	if err := s.repo.UpdateRecountTask(tx, rc.task); err != nil {
+		return fmt.Errorf("failed to update recount task: %w", err)
+	}
+	return nil
+}
+
+// createRecountTask 为超出容差的盘点创建复盘任务
+// 盘点单指定了盘点人时从中选择未参与过该复盘链的盘点人，否则任务不指派
+func (s *inventoryService) createRecountTask(tx *gorm.DB, record *model.InventoryCheckRecord, stockTake *model.StockTake, excluded map[string]bool) (*model.RecountTask, error) {
+	rootID := record.ID
+	if record.RootRecordID != nil {
+		rootID = *record.RootRecordID
+	}
+
+	var pool []string
+	if stockTake != nil {
+		for _, c := range stockTake.Checkers {
+			pool = append(pool, c.CheckerID)
+		}
+	}
+
+	task := &model.RecountTask{
+		RecordID:     record.ID,
+		RootRecordID: rootID,
+		StockTakeID:  record.StockTakeID,
+		MaterialCode: record.MaterialCode,
+		LocationCode: record.LocationCode,
+		LotNumber:    record.LotNumber,
+		AssignedTo:   pickRecountChecker(pool, excluded),
+		Status:       model.RecountTaskStatusOpen,
+	}
+	if err := s.repo.CreateRecountTask(tx, task); err != nil {
+		return nil, fmt.Errorf("failed to create recount task: %w", err)
+	}
+	return task, nil
+}
+
+// cancelRecountTask 取消未完成的复盘任务
+func (s *inventoryService) cancelRecountTask(tx *gorm.DB, task *model.RecountTask) error {
+	now := time.Now()
+	task.Status = model.RecountTaskStatusCancelled
+	task.ClosedAt = &now
+	if err := s.repo.UpdateRecountTask(tx, task); err != nil {
+		return fmt.Errorf("failed to update recount task: %w", err)
+	}
+	return nil
+}
+
+// chainCheckers 返回复盘链上已参与盘点的盘点人集合
+func chainCheckers(chain []model.InventoryCheckRecord) map[string]bool {
+	checkers := make(map[string]bool, len(chain))
+	for _, record := range chain {
+		checkers[record.CheckerID] = true
+	}
+	return checkers
+}
+
+// findAgreeingCount 返回复盘链中实盘数量与本次复盘一致的最早记录，没有时返回 nil
+func findAgreeingCount(chain []model.InventoryCheckRecord, actualQuantity int) *model.InventoryCheckRecord {
+	for i := range chain {
+		if chain[i].ActualQuantity == actualQuantity {
+			return &chain[i]
+		}
+	}
+	return nil
+}
+
+// pickRecountChecker 从候选盘点人中按字典序选出第一个未被排除的盘点人，没有可选时返回空
+func pickRecountChecker(pool []string, excluded map[string]bool) string {
+	candidates := append([]string(nil), pool...)
+	sort.Strings(candidates)
+	for _, checker := range candidates {
+		if !excluded[checker] {
+			return checker
+		}
+	}
+	return ""
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"testing"
+	"wms/internal/model"
+)
+
+func TestPickRecountChecker_SkipsCheckersInChain(t *testing.T) {
+	pool := []string{"c3", "c1", "c2"}
+
+	if got := pickRecountChecker(pool, map[string]bool{"c1": true}); got != "c2" {
+		t.Errorf("Expected c2, got %q", got)
+	}
+	if got := pickRecountChecker(pool, map[string]bool{"c1": true, "c2": true, "c3": true}); got != "" {
+		t.Errorf("Expected no checker when pool is exhausted, got %q", got)
+	}
+	if got := pickRecountChecker(nil, nil); got != "" {
+		t.Errorf("Expected no checker for empty pool, got %q", got)
+	}
+	if pool[0] != "c3" {
+		t.Errorf("Pool must not be reordered in place, got %v", pool)
+	}
+}
+
+func TestFindAgreeingCount(t *testing.T) {
+	chain := []model.InventoryCheckRecord{
+		{ID: 1, CheckerID: "c1", ActualQuantity: 80},
+		{ID: 2, CheckerID: "c2", ActualQuantity: 95, RecountSequence: 1},
+	}
+
+	if agreed := findAgreeingCount(chain, 95); agreed == nil || agreed.ID != 2 {
+		t.Errorf("Expected agreement with record 2, got %+v", agreed)
+	}
+	if agreed := findAgreeingCount(chain, 90); agreed != nil {
+		t.Errorf("Expected no agreement, got record %d", agreed.ID)
+	}
+
+	checkers := chainCheckers(chain)
+	if !checkers["c1"] || !checkers["c2"] || len(checkers) != 2 {
+		t.Errorf("Unexpected chain checkers: %v", checkers)
+	}
+}
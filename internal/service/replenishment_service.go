@@ -0,0 +1,404 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"wms/internal/model"
+	"wms/internal/repository"
+	"wms/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ReplenishmentRuleInput 表示新增或更新补货水位的输入
+// Active 为空时视为启用
+type ReplenishmentRuleInput struct {
+	MaterialCode string
+	LocationCode string
+	MinQuantity  int
+	MaxQuantity  int
+	Active       *bool
+	UpdatedBy    string
+}
+
+// ReplenishmentRunInput 表示一次补货扫描的范围，MaterialCode、LocationCode 为空时扫描全部启用的水位
+type ReplenishmentRunInput struct {
+	MaterialCode string
+	LocationCode string
+	CreatedBy    string
+}
+
+// ReplenishmentRunResult 表示补货扫描的结果
+// Checked 为扫描的水位数量，Failed 为扫描出错的水位数量（已记录日志，不影响其他水位）
+type ReplenishmentRunResult struct {
+	Checked int                       `json:"checked"`
+	Failed  int                       `json:"failed"`
+	Tasks   []model.ReplenishmentTask `json:"tasks"`
+}
+
+// ReplenishmentService 定义最小/最大水位补货的业务接口
+type ReplenishmentService interface {
+	// ListRules 查询全部补货水位
+	ListRules() ([]model.ReplenishmentRule, error)
+
+	// UpsertRule 按 (物料, 库位) 新增或更新补货水位
+	UpsertRule(input ReplenishmentRuleInput) (*model.ReplenishmentRule, error)
+
+	// DeleteRule 删除补货水位，不存在时返回 ErrNotFound；已生成的补货任务不受影响
+	DeleteRule(id uint) error
+
+	// Run 扫描拣货位库存，对低于最小水位的拣货位从存储库位生成补货任务，trigger 为 on_demand 或 scheduled
+	Run(input ReplenishmentRunInput, trigger string) (*ReplenishmentRunResult, error)
+
+	// ListTasks 按过滤条件查询补货任务
+	ListTasks(filter repository.ReplenishmentTaskFilter) ([]model.ReplenishmentTask, error)
+
+	// ConfirmTask 确认补货：将任务数量从来源库位移至拣货位并消耗来源库存的预留
+	ConfirmTask(id uint, operatorID string) (*model.ReplenishmentTask, error)
+
+	// CancelTask 取消补货任务并释放来源库存的预留
+	CancelTask(id uint, operatorID, reason string) (*model.ReplenishmentTask, error)
+}
+
+// replenishmentService 是 ReplenishmentService 的具体实现
+type replenishmentService struct {
+	repo        repository.ReplenishmentRepository
+	ledger      *stockLedger
+	replenisher *replenisher
+	retry       RetryPolicy
+	logger      *logger.Logger
+}
+
+// NewReplenishmentService 创建新的 ReplenishmentService 实例
+func NewReplenishmentService(repo repository.ReplenishmentRepository, stockRepo repository.StockRepository, retry RetryPolicy, log *logger.Logger) ReplenishmentService {
+	return &replenishmentService{
+		repo:        repo,
+		ledger:      newStockLedger(stockRepo),
+		replenisher: newReplenisher(repo, stockRepo),
+		retry:       retry,
+		logger:      log,
+	}
+}
+
+// ValidReplenishmentRunTrigger 判断补货扫描的触发方式是否受支持
+func ValidReplenishmentRunTrigger(trigger string) bool {
+	return trigger == model.ReplenishmentTriggerOnDemand || trigger == model.ReplenishmentTriggerScheduled
+}
+
+// ListRules 查询全部补货水位
+func (s *replenishmentService) ListRules() ([]model.ReplenishmentRule, error) {
+	rules, err := s.repo.ListRules()
+	if err != nil {
+		s.logger.Error("Failed to list replenishment rules", zap.Error(err))
+		return nil, fmt.Errorf("failed to list replenishment rules: %w", err)
+	}
+	return rules, nil
+}
+
+// UpsertRule 按 (物料, 库位) 新增或更新补货水位
+func (s *replenishmentService) UpsertRule(input ReplenishmentRuleInput) (*model.ReplenishmentRule, error) {
+	materialCode := strings.TrimSpace(input.MaterialCode)
+	locationCode := strings.TrimSpace(input.LocationCode)
+	if materialCode == "" || locationCode == "" {
+		return nil, fmt.Errorf("%w: material_code and location_code are required", ErrInvalidInput)
+	}
+	if input.MinQuantity < 0 {
+		return nil, fmt.Errorf("%w: min_quantity cannot be negative", ErrInvalidInput)
+	}
+	if input.MaxQuantity <= 0 || input.MaxQuantity < input.MinQuantity {
+		return nil, fmt.Errorf("%w: max_quantity must be positive and not less than min_quantity", ErrInvalidInput)
+	}
+
+	rule := &model.ReplenishmentRule{
+		MaterialCode: materialCode,
+		LocationCode: locationCode,
+		MinQuantity:  input.MinQuantity,
+		MaxQuantity:  input.MaxQuantity,
+		Active:       input.Active == nil || *input.Active,
+		UpdatedBy:    input.UpdatedBy,
+	}
+	if err := s.repo.UpsertRule(rule); err != nil {
+		s.logger.Error("Failed to upsert replenishment rule",
+			zap.String("material_code", materialCode),
+			zap.String("location_code", locationCode),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to save replenishment rule: %w", err)
+	}
+
+	s.logger.Info("Replenishment rule saved",
+		zap.String("material_code", rule.MaterialCode),
+		zap.String("location_code", rule.LocationCode),
+		zap.Int("min_quantity", rule.MinQuantity),
+		zap.Int("max_quantity", rule.MaxQuantity),
+		zap.Bool("active", rule.Active),
+	)
+	return rule, nil
+}
+
+// DeleteRule 删除补货水位
+func (s *replenishmentService) DeleteRule(id uint) error {
+	deleted, err := s.repo.DeleteRule(id)
+	if err != nil {
+		s.logger.Error("Failed to delete replenishment rule", zap.Uint("id", id), zap.Error(err))
+		return fmt.Errorf("failed to delete replenishment rule: %w", err)
+	}
+	if !deleted {
+		return fmt.Errorf("%w: replenishment rule %d", ErrNotFound, id)
+	}
+
+	s.logger.Info("Replenishment rule deleted", zap.Uint("id", id))
+	return nil
+}
+
+// Run 逐个水位在独立事务中检查拣货位并生成补货任务
+// 单个水位失败只记录日志并计入 Failed，不影响其他水位；已有未完成任务覆盖缺口的拣货位不会重复生成
+func (s *replenishmentService) Run(input ReplenishmentRunInput, trigger string) (*ReplenishmentRunResult, error) {
+	if !ValidReplenishmentRunTrigger(trigger) {
+		return nil, fmt.Errorf("%w: unsupported replenishment trigger %q", ErrInvalidInput, trigger)
+	}
+	if strings.TrimSpace(input.CreatedBy) == "" {
+		return nil, fmt.Errorf("%w: created_by is required", ErrInvalidInput)
+	}
+
+	rules, err := s.repo.ListActiveRules(repository.ReplenishmentRuleFilter{
+		MaterialCode: input.MaterialCode,
+		LocationCode: input.LocationCode,
+	})
+	if err != nil {
+		s.logger.Error("Failed to list replenishment rules", zap.Error(err))
+		return nil, fmt.Errorf("failed to list replenishment rules: %w", err)
+	}
+
+	result := &ReplenishmentRunResult{Tasks: []model.ReplenishmentTask{}}
+	for _, rule := range rules {
+		result.Checked++
+		var tasks []model.ReplenishmentTask
+		err := s.retry.run(s.logger, "replenishment_run", func() error {
+			return runInTransaction(s.repo, s.logger, "replenishment_run", func(tx *gorm.DB) error {
+				var err error
+				tasks, err = s.replenisher.check(tx, rule.MaterialCode, rule.LocationCode, trigger, input.CreatedBy)
+				return err
+			})
+		})
+		if err != nil {
+			result.Failed++
+			s.logger.Error("Replenishment check failed",
+				zap.String("material_code", rule.MaterialCode),
+				zap.String("location_code", rule.LocationCode),
+				zap.Error(err),
+			)
+			continue
+		}
+		result.Tasks = append(result.Tasks, tasks...)
+	}
+
+	s.logger.Info("Replenishment run completed",
+		zap.String("trigger", trigger),
+		zap.Int("checked", result.Checked),
+		zap.Int("failed", result.Failed),
+		zap.Int("task_count", len(result.Tasks)),
+		zap.String("created_by", input.CreatedBy),
+	)
+	return result, nil
+}
+
+// ListTasks 按过滤条件查询补货任务
+func (s *replenishmentService) ListTasks(filter repository.ReplenishmentTaskFilter) ([]model.ReplenishmentTask, error) {
+	tasks, err := s.repo.ListTasks(filter)
+	if err != nil {
+		s.logger.Error("Failed to list replenishment tasks", zap.Error(err))
+		return nil, fmt.Errorf("failed to list replenishment tasks: %w", err)
+	}
+	return tasks, nil
+}
+
+// ConfirmTask 确认补货任务
+// 锁定顺序：补货任务 -> 库存行（按库位编码）
+func (s *replenishmentService) ConfirmTask(id uint, operatorID string) (*model.ReplenishmentTask, error) {
+	if strings.TrimSpace(operatorID) == "" {
+		return nil, fmt.Errorf("%w: operator_id is required", ErrInvalidInput)
+	}
+
+	var result *model.ReplenishmentTask
+	err := s.retry.run(s.logger, "replenishment_confirm", func() error {
+		return runInTransaction(s.repo, s.logger, "replenishment_confirm", func(tx *gorm.DB) error {
+			task, err := s.openTask(tx, id)
+			if err != nil {
+				return err
+			}
+
+			err = s.ledger.move(tx, StockMove{
+				MaterialCode:    task.MaterialCode,
+				LotNumber:       task.LotNumber,
+				FromLocation:    task.SourceLocation,
+				ToLocation:      task.TargetLocation,
+				Quantity:        task.Quantity,
+				ConsumeReserved: task.Quantity,
+				MovementType:    model.MovementTypeReplenish,
+				ReferenceType:   model.ReferenceTypeReplenishmentTask,
+				ReferenceID:     strconv.FormatUint(uint64(task.ID), 10),
+				OperatorID:      operatorID,
+			})
+			if err != nil {
+				return err
+			}
+
+			now := time.Now()
+			task.Status = model.ReplenishmentStatusCompleted
+			task.ConfirmedBy = operatorID
+			task.ConfirmedAt = &now
+			if err := s.repo.UpdateTask(tx, task); err != nil {
+				return fmt.Errorf("failed to update replenishment task: %w", err)
+			}
+			result = task
+			return nil
+		})
+	})
+	if err != nil {
+		s.logger.Warn("Replenishment confirmation failed", zap.Uint("task_id", id), zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.Info("Replenishment confirmed",
+		zap.Uint("task_id", id),
+		zap.String("material_code", result.MaterialCode),
+		zap.String("source_location", result.SourceLocation),
+		zap.String("target_location", result.TargetLocation),
+		zap.Int("quantity", result.Quantity),
+		zap.String("operator_id", operatorID),
+	)
+	return result, nil
+}
+
+// CancelTask 取消补货任务
+func (s *replenishmentService) CancelTask(id uint, operatorID, reason string) (*model.ReplenishmentTask, error) {
+	if strings.TrimSpace(operatorID) == "" {
+		return nil, fmt.Errorf("%w: operator_id is required", ErrInvalidInput)
+	}
+
+	var result *model.ReplenishmentTask
+	err := s.retry.run(s.logger, "replenishment_cancel", func() error {
+		return runInTransaction(s.repo, s.logger, "replenishment_cancel", func(tx *gorm.DB) error {
+			task, err := s.openTask(tx, id)
+			if err != nil {
+				return err
+			}
+			if _, err := s.ledger.release(tx, task.MaterialCode, task.SourceLocation, task.LotNumber, task.Quantity); err != nil {
+				return err
+			}
+
+			now := time.Now()
+			task.Status = model.ReplenishmentStatusCancelled
+			task.CancelledBy = operatorID
+			task.CancelledAt = &now
+			task.CancelReason = reason
+			if err := s.repo.UpdateTask(tx, task); err != nil {
+				return fmt.Errorf("failed to update replenishment task: %w", err)
+			}
+			result = task
+			return nil
+		})
+	})
+	if err != nil {
+		s.logger.Warn("Replenishment cancellation failed", zap.Uint("task_id", id), zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.Info("Replenishment cancelled", zap.Uint("task_id", id), zap.String("operator_id", operatorID))
+	return result, nil
+}
+
+// openTask 锁定未完成的补货任务，不存在时返回 ErrNotFound，已完成或取消时返回 ErrInvalidState
+func (s *replenishmentService) openTask(tx *gorm.DB, id uint) (*model.ReplenishmentTask, error) {
+	task, err := s.repo.GetTaskForUpdate(tx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch replenishment task: %w", err)
+	}
+	if task == nil {
+		return nil, fmt.Errorf("%w: replenishment task %d", ErrNotFound, id)
+	}
+	if task.Status != model.ReplenishmentStatusOpen {
+		return nil, fmt.Errorf("%w: replenishment task %d is %s", ErrInvalidState, id, task.Status)
+	}
+	return task, nil
+}
+
+// replenisher 检查拣货位水位并生成补货任务
+// 由补货扫描、拣货确认与盘点过账在各自的事务中调用
+type replenisher struct {
+	repo   repository.ReplenishmentRepository
+	ledger *stockLedger
+}
+
+// newReplenisher 创建补货检查器
+func newReplenisher(repo repository.ReplenishmentRepository, stockRepo repository.StockRepository) *replenisher {
+	return &replenisher{repo: repo, ledger: newStockLedger(stockRepo)}
+}
+
+// check 在事务中检查物料在库位上的补货水位，低于最小水位时从存储库位先到期先出地生成补货任务并预留来源库存，
+// 已过有效期的库存不作为补货来源
+// 库位没有启用的水位时不做任何事；存储库存不足时按可用数量尽量补货
+// 拣货位在库数量按各批次合计；锁定顺序：补货水位 -> 来源库存行（按库位编码、批次），
+// 同一拣货位的补货检查由补货水位的行锁串行化
+func (r *replenisher) check(tx *gorm.DB, materialCode, locationCode, trigger, operatorID string) ([]model.ReplenishmentTask, error) {
+	rule, err := r.repo.GetRuleForUpdate(tx, materialCode, locationCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch replenishment rule: %w", err)
+	}
+	if rule == nil {
+		return nil, nil
+	}
+	pending, err := r.repo.PendingQuantity(tx, materialCode, locationCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum pending replenishment: %w", err)
+	}
+	onHand, err := r.repo.OnHandQuantity(tx, materialCode, locationCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum pick face stock: %w", err)
+	}
+	need := replenishmentNeed(rule, onHand, pending)
+	if need <= 0 {
+		return nil, nil
+	}
+
+	sources, err := r.repo.ListReserveStocks(tx, materialCode, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch reserve stocks: %w", err)
+	}
+	var tasks []model.ReplenishmentTask
+	for _, pick := range planAllocation(model.AllocationStrategyFEFO, sources, need) {
+		if _, err := r.ledger.reserve(tx, materialCode, pick.stock.LocationCode, pick.stock.LotNumber, pick.quantity); err != nil {
+			return nil, err
+		}
+		task := model.ReplenishmentTask{
+			RuleID:         rule.ID,
+			MaterialCode:   materialCode,
+			SourceLocation: pick.stock.LocationCode,
+			LotNumber:      pick.stock.LotNumber,
+			TargetLocation: locationCode,
+			Quantity:       pick.quantity,
+			Trigger:        trigger,
+			Status:         model.ReplenishmentStatusOpen,
+			CreatedBy:      operatorID,
+		}
+		if err := r.repo.CreateTask(tx, &task); err != nil {
+			return nil, fmt.Errorf("failed to create replenishment task: %w", err)
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+// replenishmentNeed 返回拣货位需要补货的数量
+// 在库数量加上未完成补货的数量低于最小水位时补至最大水位，否则返回 0
+func replenishmentNeed(rule *model.ReplenishmentRule, onHand, pending int) int {
+	projected := onHand + pending
+	if projected >= rule.MinQuantity {
+		return 0
+	}
+	return rule.MaxQuantity - projected
+}
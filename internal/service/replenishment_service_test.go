@@ -0,0 +1,75 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"wms/internal/model"
+	"wms/pkg/logger"
+)
+
+func TestReplenishmentNeed(t *testing.T) {
+	rule := &model.ReplenishmentRule{MinQuantity: 10, MaxQuantity: 50}
+	tests := []struct {
+		name    string
+		onHand  int
+		pending int
+		want    int
+	}{
+		{name: "above min", onHand: 12, want: 0},
+		{name: "at min", onHand: 10, want: 0},
+		{name: "below min fills to max", onHand: 4, want: 46},
+		{name: "empty pick face", onHand: 0, want: 50},
+		{name: "pending covers gap", onHand: 4, pending: 20, want: 0},
+		{name: "pending counts toward max", onHand: 2, pending: 5, want: 43},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := replenishmentNeed(rule, tt.onHand, tt.pending); got != tt.want {
+				t.Errorf("Expected need %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestReplenishmentSources_FIFO(t *testing.T) {
+	older := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.AddDate(0, 1, 0)
+	sources := []model.Stock{
+		{LocationCode: "R-01", Quantity: 30, ReservedQuantity: 0, ReceivedAt: &newer},
+		{LocationCode: "R-02", Quantity: 20, ReservedQuantity: 15, ReceivedAt: &older},
+		{LocationCode: "R-03", Quantity: 10},
+	}
+
+	picks := planAllocation(model.AllocationStrategyFIFO, sources, 25)
+	if len(picks) != 2 {
+		t.Fatalf("Expected 2 source locations, got %+v", picks)
+	}
+	if picks[0].stock.LocationCode != "R-02" || picks[0].quantity != 5 {
+		t.Errorf("Expected oldest stock R-02 to supply its 5 available first, got %+v", picks[0])
+	}
+	if picks[1].stock.LocationCode != "R-01" || picks[1].quantity != 20 {
+		t.Errorf("Expected R-01 to supply the remaining 20, got %+v", picks[1])
+	}
+}
+
+func TestUpsertReplenishmentRule_Rejects(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	svc := NewReplenishmentService(nil, nil, DefaultRetryPolicy(), log)
+	tests := []struct {
+		name  string
+		input ReplenishmentRuleInput
+	}{
+		{name: "missing location", input: ReplenishmentRuleInput{MaterialCode: "M-001", MinQuantity: 1, MaxQuantity: 5}},
+		{name: "negative min", input: ReplenishmentRuleInput{MaterialCode: "M-001", LocationCode: "P-01", MinQuantity: -1, MaxQuantity: 5}},
+		{name: "max below min", input: ReplenishmentRuleInput{MaterialCode: "M-001", LocationCode: "P-01", MinQuantity: 10, MaxQuantity: 5}},
+		{name: "zero max", input: ReplenishmentRuleInput{MaterialCode: "M-001", LocationCode: "P-01"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.UpsertRule(tt.input); !errors.Is(err, ErrInvalidInput) {
+				t.Errorf("Expected ErrInvalidInput, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"errors"
+	"time"
+	"wms/pkg/logger"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+)
+
+// PostgreSQL 可安全重试的事务错误码
+const (
+	pgSerializationFailure = "40001"
+	pgDeadlockDetected     = "40P01"
+)
+
+// RetryPolicy 表示事务因并发冲突失败时的重试策略
+// 仅对序列化失败与死锁进行重试，每次重试等待时间指数增长且不超过 MaxDelay
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// DefaultRetryPolicy 返回默认的重试策略
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   20 * time.Millisecond,
+		MaxDelay:    500 * time.Millisecond,
+	}
+}
+
+// run 执行 fn，并在遇到可重试的事务错误时按策略重试
+// fn 必须是完整的事务（开启、执行、提交/回滚），以便每次重试都从头开始
+func (p RetryPolicy) run(log *logger.Logger, operation string, fn func() error) error {
+	attempts := p.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	delay := p.BaseDelay
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		err = fn()
+		if err == nil || !isRetryableTxError(err) || attempt == attempts {
+			return err
+		}
+
+		log.Warn("Transaction conflict, retrying",
+			zap.String("operation", operation),
+			zap.Int("attempt", attempt),
+			zap.Duration("backoff", delay),
+			zap.Error(err),
+		)
+		time.Sleep(delay)
+		delay *= 2
+		if p.MaxDelay > 0 && delay > p.MaxDelay {
+			delay = p.MaxDelay
+		}
+	}
+	return err
+}
+
+// isRetryableTxError 判断错误是否为可重试的序列化失败或死锁
+func isRetryableTxError(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == pgSerializationFailure || pgErr.Code == pgDeadlockDetected
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+	"wms/pkg/logger"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+func TestIsRetryableTxError(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"serialization failure", &pgconn.PgError{Code: "40001"}, true},
+		{"deadlock", fmt.Errorf("failed to update stock: %w", &pgconn.PgError{Code: "40P01"}), true},
+		{"unique violation", &pgconn.PgError{Code: "23505"}, false},
+		{"business error", ErrInsufficientStock, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isRetryableTxError(tc.err); got != tc.want {
+				t.Errorf("isRetryableTxError(%v) = %v, want %v", tc.err, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyRun(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+
+	// 可重试错误在达到上限前成功
+	attempts := 0
+	err := policy.run(log, "test", func() error {
+		attempts++
+		if attempts < 3 {
+			return &pgconn.PgError{Code: "40001"}
+		}
+		return nil
+	})
+	if err != nil || attempts != 3 {
+		t.Errorf("Expected success after 3 attempts, got err=%v attempts=%d", err, attempts)
+	}
+
+	// 不可重试错误立即返回
+	attempts = 0
+	err = policy.run(log, "test", func() error {
+		attempts++
+		return ErrInvalidState
+	})
+	if !errors.Is(err, ErrInvalidState) || attempts != 1 {
+		t.Errorf("Expected immediate failure, got err=%v attempts=%d", err, attempts)
+	}
+
+	// 超过最大尝试次数后返回最后一次错误
+	attempts = 0
+	err = policy.run(log, "test", func() error {
+		attempts++
+		return &pgconn.PgError{Code: "40P01"}
+	})
+	if !isRetryableTxError(err) || attempts != 3 {
+		t.Errorf("Expected retryable error after 3 attempts, got err=%v attempts=%d", err, attempts)
+	}
+}
+
+// scriptedTransactor 记录事务的开启、提交与回滚次数，commitErrs 依次作为各次提交的结果
+type scriptedTransactor struct {
+	begun, committed, rolledBack int
+	commitErrs                   []error
+}
+
+func (t *scriptedTransactor) BeginTransaction() *gorm.DB {
+	t.begun++
+	return &gorm.DB{}
+}
+
+func (t *scriptedTransactor) CommitTransaction(tx *gorm.DB) error {
+	var err error
+	if len(t.commitErrs) > 0 {
+		err, t.commitErrs = t.commitErrs[0], t.commitErrs[1:]
+	}
+	if err == nil {
+		t.committed++
+	}
+	return err
+}
+
+func (t *scriptedTransactor) RollbackTransaction(tx *gorm.DB) error {
+	t.rolledBack++
+	return nil
+}
+
+func TestRetryPolicyRun_RetriesWholeTransaction(t *testing.T) {
+	log, _ := logger.NewLogger("test")
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+
+	// 第一次执行遇到死锁，第二次提交时串行化失败，第三次成功；每次失败都回滚并从开启事务重新开始
+	transactor := &scriptedTransactor{commitErrs: []error{&pgconn.PgError{Code: "40001"}}}
+	attempts := 0
+	err := policy.run(log, "test", func() error {
+		return runInTransaction(transactor, log, "test", func(tx *gorm.DB) error {
+			attempts++
+			if attempts == 1 {
+				return fmt.Errorf("failed to update stock: %w", &pgconn.PgError{Code: "40P01"})
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatalf("Expected success after retries, got %v", err)
+	}
+	if attempts != 3 || transactor.begun != 3 || transactor.rolledBack != 2 || transactor.committed != 1 {
+		t.Errorf("Expected 3 attempts, 3 begins, 2 rollbacks and 1 commit, got %d/%d/%d/%d",
+			attempts, transactor.begun, transactor.rolledBack, transactor.committed)
+	}
+
+	// 业务错误回滚后不重试
+	transactor = &scriptedTransactor{}
+	err = policy.run(log, "test", func() error {
+		return runInTransaction(transactor, log, "test", func(tx *gorm.DB) error {
+			return ErrInsufficientStock
+		})
+	})
+	if !errors.Is(err, ErrInsufficientStock) || transactor.begun != 1 || transactor.rolledBack != 1 || transactor.committed != 0 {
+		t.Errorf("Expected a single rolled back attempt, got err=%v begun=%d rolledBack=%d", err, transactor.begun, transactor.rolledBack)
+	}
+}
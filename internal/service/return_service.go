@@ -0,0 +1,553 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"wms/internal/model"
+	"wms/internal/repository"
+	"wms/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ReturnOrderInput 表示创建退货授权（RMA）的输入数据
+type ReturnOrderInput struct {
+	RMANo      string
+	ShipmentID uint
+	Reason     string
+	CreatedBy  string
+	Lines      []ReturnLineInput
+}
+
+// ReturnLineInput 表示退货授权的一行，LineNo 为原出库单据的行号
+type ReturnLineInput struct {
+	LineNo   int
+	Quantity int
+}
+
+// ReturnReceiveInput 表示一次退货收货检验
+type ReturnReceiveInput struct {
+	ReceivedBy string
+	Lines      []ReturnReceiveLineInput
+}
+
+// ReturnReceiveLineInput 表示退货收货的一行
+// 同一 RMA 行可拆成多行分别给出不同的处置方式；LocationCode 为空时使用处置方式对应的默认库位；
+// SerialNumbers 为退回件的序列号，启用序列号管理的物料必须给出且与数量一致
+type ReturnReceiveLineInput struct {
+	LineNo         int
+	LotNumber      string
+	Quantity       int
+	SerialNumbers  []string
+	Disposition    string
+	LocationCode   string
+	InspectionNote string
+}
+
+// ReturnReceiveResult 表示退货收货的结果，restock 的收货会生成上架任务
+type ReturnReceiveResult struct {
+	Return       *model.ReturnOrder    `json:"return"`
+	Receipts     []model.ReturnReceipt `json:"receipts"`
+	PutawayTasks []model.PutawayTask   `json:"putaway_tasks"`
+}
+
+// ReturnOptions 表示退货服务的可配置项
+type ReturnOptions struct {
+	// RestockLocation 可重新上架的退货暂存库位，收货后生成上架任务
+	RestockLocation string
+	// QuarantineLocation 隔离待检库位
+	QuarantineLocation string
+	// ScrapLocation 报废库位
+	ScrapLocation string
+	// VendorReturnLocation 待退供应商库位
+	VendorReturnLocation string
+	// Retry 事务遇到序列化失败或死锁时的重试策略
+	Retry RetryPolicy
+}
+
+// location 返回处置方式对应的默认库位，处置方式不受支持时返回空字符串
+func (o ReturnOptions) location(disposition string) string {
+	switch disposition {
+	case model.DispositionRestock:
+		return o.RestockLocation
+	case model.DispositionQuarantine:
+		return o.QuarantineLocation
+	case model.DispositionScrap:
+		return o.ScrapLocation
+	case model.DispositionReturnToVendor:
+		return o.VendorReturnLocation
+	}
+	return ""
+}
+
+// ReturnService 定义客户退货授权与退货收货的业务接口
+type ReturnService interface {
+	// CreateReturn 根据发运记录创建退货授权，每行授权数量合计不能超过原单据行的已发运数量
+	CreateReturn(input ReturnOrderInput) (*model.ReturnOrder, error)
+
+	// GetReturn 查询退货单据，不存在时返回 ErrNotFound
+	GetReturn(id uint) (*model.ReturnOrder, error)
+
+	// ListReturns 按过滤条件查询退货单据
+	ListReturns(filter repository.ReturnOrderFilter) ([]model.ReturnOrder, error)
+
+	// ReceiveReturn 退货收货检验，按处置方式将数量过账到对应库位并写入 return 流水，restock 的数量生成上架任务
+	// RMA 已完成或取消时返回 ErrInvalidState；行不在 RMA 中或超出授权数量时返回 ErrInvalidInput
+	ReceiveReturn(id uint, input ReturnReceiveInput) (*ReturnReceiveResult, error)
+
+	// CancelReturn 取消尚未收货的退货授权
+	CancelReturn(id uint, operatorID, reason string) (*model.ReturnOrder, error)
+
+	// ListReceipts 查询退货单据的收货记录
+	ListReceipts(id uint) ([]model.ReturnReceipt, error)
+}
+
+// returnService 是 ReturnService 的具体实现
+type returnService struct {
+	repo         repository.ReturnRepository
+	outboundRepo repository.OutboundRepository
+	packingRepo  repository.PackingRepository
+	locationRepo repository.LocationRepository
+	ledger       *stockLedger
+	putaway      *putawayPlanner
+	options      ReturnOptions
+	logger       *logger.Logger
+}
+
+// NewReturnService 创建新的 ReturnService 实例
+func NewReturnService(repo repository.ReturnRepository, outboundRepo repository.OutboundRepository, packingRepo repository.PackingRepository,
+	stockRepo repository.StockRepository, putawayRepo repository.PutawayRepository, locationRepo repository.LocationRepository,
+	options ReturnOptions, log *logger.Logger) ReturnService {
+	return &returnService{
+		repo:         repo,
+		outboundRepo: outboundRepo,
+		packingRepo:  packingRepo,
+		locationRepo: locationRepo,
+		ledger:       newStockLedger(stockRepo),
+		putaway:      newPutawayPlanner(putawayRepo, locationRepo, stockRepo),
+		options:      options,
+		logger:       log,
+	}
+}
+
+// CreateReturn 创建退货授权
+// 锁定原出库单据后汇总已授权数量，使同一单据的并发 RMA 不会超额授权
+func (s *returnService) CreateReturn(input ReturnOrderInput) (*model.ReturnOrder, error) {
+	rmaNo := strings.TrimSpace(input.RMANo)
+	if rmaNo == "" {
+		return nil, fmt.Errorf("%w: rma_no is required", ErrInvalidInput)
+	}
+	if input.ShipmentID == 0 {
+		return nil, fmt.Errorf("%w: shipment_id is required", ErrInvalidInput)
+	}
+	if strings.TrimSpace(input.CreatedBy) == "" {
+		return nil, fmt.Errorf("%w: created_by is required", ErrInvalidInput)
+	}
+	if len(input.Lines) == 0 {
+		return nil, fmt.Errorf("%w: at least one line is required", ErrInvalidInput)
+	}
+
+	var result *model.ReturnOrder
+	err := s.options.Retry.run(s.logger, "return_create", func() error {
+		return runInTransaction(s.repo, s.logger, "return_create", func(tx *gorm.DB) error {
+			existing, err := s.repo.GetByRMANo(tx, rmaNo)
+			if err != nil {
+				return fmt.Errorf("failed to check rma number: %w", err)
+			}
+			if existing != nil {
+				return fmt.Errorf("%w: rma_no %s already exists", ErrInvalidInput, rmaNo)
+			}
+
+			shipment, err := s.packingRepo.GetShipment(tx, input.ShipmentID)
+			if err != nil {
+				return fmt.Errorf("failed to fetch shipment: %w", err)
+			}
+			if shipment == nil {
+				return fmt.Errorf("%w: shipment %d", ErrNotFound, input.ShipmentID)
+			}
+			order, err := s.outboundRepo.GetForUpdate(tx, shipment.OrderID)
+			if err != nil {
+				return fmt.Errorf("failed to fetch outbound order: %w", err)
+			}
+			if order == nil {
+				return fmt.Errorf("%w: outbound order %d", ErrNotFound, shipment.OrderID)
+			}
+			authorized, err := s.repo.AuthorizedQuantities(tx, order.ID)
+			if err != nil {
+				return fmt.Errorf("failed to sum authorized return quantities: %w", err)
+			}
+
+			lines, err := buildReturnLines(order, input.Lines, authorized)
+			if err != nil {
+				return err
+			}
+			rma := &model.ReturnOrder{
+				RMANo:        rmaNo,
+				ShipmentID:   shipment.ID,
+				OrderID:      order.ID,
+				OrderNo:      order.OrderNo,
+				CustomerCode: order.CustomerCode,
+				Reason:       input.Reason,
+				Status:       model.ReturnStatusOpen,
+				CreatedBy:    input.CreatedBy,
+				Lines:        lines,
+			}
+			if err := s.repo.Create(tx, rma); err != nil {
+				return fmt.Errorf("failed to create return order: %w", err)
+			}
+			result = rma
+			return nil
+		})
+	})
+	if err != nil {
+		s.logger.Warn("Return authorization failed", zap.String("rma_no", rmaNo), zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.Info("Return authorized",
+		zap.Uint("return_id", result.ID),
+		zap.String("rma_no", result.RMANo),
+		zap.String("order_no", result.OrderNo),
+		zap.Int("line_count", len(result.Lines)),
+	)
+	return result, nil
+}
+
+// buildReturnLines 校验退货授权行并构造 RMA 物料行
+// 可退数量为原单据行的已发运数量减去其他未取消 RMA 已授权的数量
+func buildReturnLines(order *model.OutboundOrder, inputs []ReturnLineInput, authorized map[uint]int) ([]model.ReturnOrderLine, error) {
+	if order.Status != model.OutboundOrderStatusShipped {
+		return nil, fmt.Errorf("%w: outbound order %s is %s, only shipped orders can be returned", ErrInvalidState, order.OrderNo, order.Status)
+	}
+
+	byNo := make(map[int]*model.OutboundOrderLine, len(order.Lines))
+	for i := range order.Lines {
+		byNo[order.Lines[i].LineNo] = &order.Lines[i]
+	}
+	lines := make([]model.ReturnOrderLine, 0, len(inputs))
+	seen := make(map[int]bool, len(inputs))
+	for _, in := range inputs {
+		if seen[in.LineNo] {
+			return nil, fmt.Errorf("%w: duplicate line_no %d", ErrInvalidInput, in.LineNo)
+		}
+		seen[in.LineNo] = true
+		line := byNo[in.LineNo]
+		if line == nil {
+			return nil, fmt.Errorf("%w: line %d is not on outbound order %s", ErrInvalidInput, in.LineNo, order.OrderNo)
+		}
+		if in.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: line %d quantity must be positive", ErrInvalidInput, in.LineNo)
+		}
+		if returnable := line.ShippedQuantity - authorized[line.ID]; in.Quantity > returnable {
+			return nil, fmt.Errorf("%w: line %d can return at most %d (shipped %d, already authorized %d)",
+				ErrInvalidInput, in.LineNo, returnable, line.ShippedQuantity, authorized[line.ID])
+		}
+		lines = append(lines, model.ReturnOrderLine{
+			LineNo:             line.LineNo,
+			OrderLineID:        line.ID,
+			MaterialCode:       line.MaterialCode,
+			AuthorizedQuantity: in.Quantity,
+			Status:             model.ReturnLineStatusOpen,
+		})
+	}
+	sort.Slice(lines, func(i, j int) bool { return lines[i].LineNo < lines[j].LineNo })
+	return lines, nil
+}
+
+// GetReturn 查询退货单据
+func (s *returnService) GetReturn(id uint) (*model.ReturnOrder, error) {
+	order, err := s.repo.GetByID(id)
+	if err != nil {
+		s.logger.Error("Failed to fetch return order", zap.Uint("return_id", id), zap.Error(err))
+		return nil, fmt.Errorf("failed to fetch return order: %w", err)
+	}
+	if order == nil {
+		return nil, fmt.Errorf("%w: return order %d", ErrNotFound, id)
+	}
+	return order, nil
+}
+
+// ListReturns 查询退货单据列表
+func (s *returnService) ListReturns(filter repository.ReturnOrderFilter) ([]model.ReturnOrder, error) {
+	orders, err := s.repo.List(filter)
+	if err != nil {
+		s.logger.Error("Failed to list return orders", zap.Error(err))
+		return nil, fmt.Errorf("failed to list return orders: %w", err)
+	}
+	return orders, nil
+}
+
+// returnPosting 表示一行待过账的退货收货
+type returnPosting struct {
+	line           *model.ReturnOrderLine
+	lotNumber      string
+	quantity       int
+	serialNumbers  []string
+	disposition    string
+	location       string
+	inspectionNote string
+}
+
+// ReceiveReturn 退货收货检验
+// 锁定 RMA 后按物料顺序逐行写收货记录、过账库存，restock 的收货生成上架任务，任一行失败时整次收货回滚
+func (s *returnService) ReceiveReturn(id uint, input ReturnReceiveInput) (*ReturnReceiveResult, error) {
+	if strings.TrimSpace(input.ReceivedBy) == "" {
+		return nil, fmt.Errorf("%w: received_by is required", ErrInvalidInput)
+	}
+	if len(input.Lines) == 0 {
+		return nil, fmt.Errorf("%w: at least one line is required", ErrInvalidInput)
+	}
+
+	var result *ReturnReceiveResult
+	err := s.options.Retry.run(s.logger, "return_receive", func() error {
+		return runInTransaction(s.repo, s.logger, "return_receive", func(tx *gorm.DB) error {
+			rma, err := s.repo.GetForUpdate(tx, id)
+			if err != nil {
+				return fmt.Errorf("failed to fetch return order: %w", err)
+			}
+			if rma == nil {
+				return fmt.Errorf("%w: return order %d", ErrNotFound, id)
+			}
+			if rma.Status != model.ReturnStatusOpen && rma.Status != model.ReturnStatusReceiving {
+				return fmt.Errorf("%w: return order %s is %s", ErrInvalidState, rma.RMANo, rma.Status)
+			}
+
+			postings, err := matchReturnLines(rma, input.Lines, s.options)
+			if err != nil {
+				return err
+			}
+			if err := s.checkHoldLocations(tx, postings); err != nil {
+				return err
+			}
+
+			now := time.Now()
+			receipts := make([]model.ReturnReceipt, 0, len(postings))
+			tasks := make([]model.PutawayTask, 0)
+			for _, p := range postings {
+				receipt := model.ReturnReceipt{
+					ReturnID:       rma.ID,
+					LineID:         p.line.ID,
+					MaterialCode:   p.line.MaterialCode,
+					LotNumber:      p.lotNumber,
+					Quantity:       p.quantity,
+					Disposition:    p.disposition,
+					LocationCode:   p.location,
+					InspectionNote: p.inspectionNote,
+					ReceivedBy:     input.ReceivedBy,
+					ReceivedAt:     now,
+				}
+				if err := s.repo.CreateReceipt(tx, &receipt); err != nil {
+					return fmt.Errorf("failed to create return receipt: %w", err)
+				}
+				if _, err := s.ledger.apply(tx, StockChange{
+					MaterialCode:  receipt.MaterialCode,
+					LocationCode:  receipt.LocationCode,
+					LotNumber:     receipt.LotNumber,
+					Delta:         receipt.Quantity,
+					MovementType:  model.MovementTypeReturn,
+					ReferenceType: model.ReferenceTypeReturnReceipt,
+					ReferenceID:   strconv.FormatUint(uint64(receipt.ID), 10),
+					ToLocation:    receipt.LocationCode,
+					OperatorID:    input.ReceivedBy,
+					SerialNumbers: p.serialNumbers,
+				}); err != nil {
+					return err
+				}
+				receipts = append(receipts, receipt)
+
+				if receipt.Disposition != model.DispositionRestock {
+					continue
+				}
+				receiptID := receipt.ID
+				task := model.PutawayTask{
+					ReturnReceiptID: &receiptID,
+					MaterialCode:    receipt.MaterialCode,
+					LotNumber:       receipt.LotNumber,
+					SourceLocation:  receipt.LocationCode,
+					Quantity:        receipt.Quantity,
+					CreatedBy:       input.ReceivedBy,
+				}
+				if err := s.putaway.createTask(tx, &task); err != nil {
+					return err
+				}
+				tasks = append(tasks, task)
+			}
+
+			complete := true
+			for i := range rma.Lines {
+				line := &rma.Lines[i]
+				if line.ReceivedQuantity < line.AuthorizedQuantity {
+					complete = false
+				}
+			}
+			for _, p := range postings {
+				if err := s.repo.UpdateLine(tx, p.line); err != nil {
+					return fmt.Errorf("failed to update return order line: %w", err)
+				}
+			}
+
+			rma.Status = model.ReturnStatusReceiving
+			if complete {
+				rma.Status = model.ReturnStatusCompleted
+			}
+			if err := s.repo.UpdateStatus(tx, rma); err != nil {
+				return fmt.Errorf("failed to update return order: %w", err)
+			}
+			result = &ReturnReceiveResult{Return: rma, Receipts: receipts, PutawayTasks: tasks}
+			return nil
+		})
+	})
+	if err != nil {
+		s.logger.Warn("Return receipt failed", zap.Uint("return_id", id), zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.Info("Return receipt posted",
+		zap.Uint("return_id", id),
+		zap.String("received_by", input.ReceivedBy),
+		zap.Int("receipt_count", len(result.Receipts)),
+		zap.Int("putaway_tasks", len(result.PutawayTasks)),
+		zap.String("status", result.Return.Status),
+	)
+	return result, nil
+}
+
+// matchReturnLines 将收货行匹配到 RMA 行、确定处置库位并累加收货数量
+// 同一 RMA 行可出现多次；返回的过账按物料、库位、行号排序，使并发收货以相同顺序锁定库存行
+func matchReturnLines(rma *model.ReturnOrder, inputs []ReturnReceiveLineInput, options ReturnOptions) ([]returnPosting, error) {
+	byNo := make(map[int]*model.ReturnOrderLine, len(rma.Lines))
+	for i := range rma.Lines {
+		byNo[rma.Lines[i].LineNo] = &rma.Lines[i]
+	}
+
+	postings := make([]returnPosting, 0, len(inputs))
+	for _, in := range inputs {
+		line := byNo[in.LineNo]
+		if line == nil {
+			return nil, fmt.Errorf("%w: line %d is not on return order %s", ErrInvalidInput, in.LineNo, rma.RMANo)
+		}
+		if in.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: line %d quantity must be positive", ErrInvalidInput, in.LineNo)
+		}
+		switch in.Disposition {
+		case model.DispositionRestock, model.DispositionQuarantine, model.DispositionScrap, model.DispositionReturnToVendor:
+		default:
+			return nil, fmt.Errorf("%w: unsupported disposition %q", ErrInvalidInput, in.Disposition)
+		}
+		location := strings.TrimSpace(in.LocationCode)
+		if location == "" {
+			location = options.location(in.Disposition)
+		}
+		if location == "" {
+			return nil, fmt.Errorf("%w: no location is configured for disposition %s", ErrInvalidInput, in.Disposition)
+		}
+		if line.ReceivedQuantity+in.Quantity > line.AuthorizedQuantity {
+			return nil, fmt.Errorf("%w: line %d would receive %d, exceeding the authorized %d",
+				ErrInvalidInput, line.LineNo, line.ReceivedQuantity+in.Quantity, line.AuthorizedQuantity)
+		}
+		line.ReceivedQuantity += in.Quantity
+		if line.ReceivedQuantity == line.AuthorizedQuantity {
+			line.Status = model.ReturnLineStatusComplete
+		}
+		postings = append(postings, returnPosting{
+			line:           line,
+			lotNumber:      strings.TrimSpace(in.LotNumber),
+			quantity:       in.Quantity,
+			serialNumbers:  in.SerialNumbers,
+			disposition:    in.Disposition,
+			location:       location,
+			inspectionNote: in.InspectionNote,
+		})
+	}
+
+	sort.SliceStable(postings, func(i, j int) bool {
+		a, b := postings[i], postings[j]
+		if a.line.MaterialCode != b.line.MaterialCode {
+			return a.line.MaterialCode < b.line.MaterialCode
+		}
+		if a.location != b.location {
+			return a.location < b.location
+		}
+		return a.line.LineNo < b.line.LineNo
+	})
+	return postings, nil
+}
+
+// checkHoldLocations 校验隔离、报废、退供应商的过账库位已登记为不允许上架
+// 出库分配会跳过这类库位，未登记的库位中的库存会被当作可售库存分配出去
+func (s *returnService) checkHoldLocations(tx *gorm.DB, postings []returnPosting) error {
+	checked := make(map[string]bool)
+	for _, p := range postings {
+		if p.disposition == model.DispositionRestock || checked[p.location] {
+			continue
+		}
+		checked[p.location] = true
+		location, err := s.locationRepo.GetByCode(tx, p.location)
+		if err != nil {
+			return fmt.Errorf("failed to fetch location: %w", err)
+		}
+		if location == nil || location.PutawayEnabled {
+			return fmt.Errorf("%w: location %s must be registered with putaway disabled to hold %s stock",
+				ErrInvalidState, p.location, p.disposition)
+		}
+	}
+	return nil
+}
+
+// CancelReturn 取消退货授权，已有收货的 RMA 不能取消
+func (s *returnService) CancelReturn(id uint, operatorID, reason string) (*model.ReturnOrder, error) {
+	if strings.TrimSpace(operatorID) == "" {
+		return nil, fmt.Errorf("%w: operator_id is required", ErrInvalidInput)
+	}
+
+	var result *model.ReturnOrder
+	err := s.options.Retry.run(s.logger, "return_cancel", func() error {
+		return runInTransaction(s.repo, s.logger, "return_cancel", func(tx *gorm.DB) error {
+			rma, err := s.repo.GetForUpdate(tx, id)
+			if err != nil {
+				return fmt.Errorf("failed to fetch return order: %w", err)
+			}
+			if rma == nil {
+				return fmt.Errorf("%w: return order %d", ErrNotFound, id)
+			}
+			if rma.Status != model.ReturnStatusOpen {
+				return fmt.Errorf("%w: return order %s is %s, only open returns without receipts can be cancelled", ErrInvalidState, rma.RMANo, rma.Status)
+			}
+
+			now := time.Now()
+			rma.Status = model.ReturnStatusCancelled
+			rma.CancelledBy = operatorID
+			rma.CancelledAt = &now
+			rma.CancelReason = reason
+			if err := s.repo.UpdateStatus(tx, rma); err != nil {
+				return fmt.Errorf("failed to update return order: %w", err)
+			}
+			result = rma
+			return nil
+		})
+	})
+	if err != nil {
+		s.logger.Warn("Return cancellation failed", zap.Uint("return_id", id), zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.Info("Return cancelled", zap.Uint("return_id", id), zap.String("operator_id", operatorID))
+	return result, nil
+}
+
+// ListReceipts 查询退货单据的收货记录
+func (s *returnService) ListReceipts(id uint) ([]model.ReturnReceipt, error) {
+	if _, err := s.GetReturn(id); err != nil {
+		return nil, err
+	}
+	receipts, err := s.repo.ListReceipts(id)
+	if err != nil {
+		s.logger.Error("Failed to list return receipts", zap.Uint("return_id", id), zap.Error(err))
+		return nil, fmt.Errorf("failed to list receipts: %w", err)
+	}
+	return receipts, nil
+}
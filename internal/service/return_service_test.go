@@ -0,0 +1,143 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"wms/internal/model"
+)
+
+func shippedOrder() *model.OutboundOrder {
+	return &model.OutboundOrder{
+		ID:      1,
+		OrderNo: "SO-001",
+		Status:  model.OutboundOrderStatusShipped,
+		Lines: []model.OutboundOrderLine{
+			{ID: 11, LineNo: 1, MaterialCode: "M-001", ShippedQuantity: 5},
+			{ID: 12, LineNo: 2, MaterialCode: "M-002", ShippedQuantity: 3},
+		},
+	}
+}
+
+func TestBuildReturnLines(t *testing.T) {
+	lines, err := buildReturnLines(shippedOrder(), []ReturnLineInput{
+		{LineNo: 2, Quantity: 3},
+		{LineNo: 1, Quantity: 2},
+	}, map[uint]int{11: 3})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(lines) != 2 || lines[0].LineNo != 1 || lines[0].OrderLineID != 11 || lines[0].MaterialCode != "M-001" {
+		t.Fatalf("Unexpected return lines: %+v", lines)
+	}
+	if lines[0].AuthorizedQuantity != 2 || lines[1].AuthorizedQuantity != 3 {
+		t.Errorf("Unexpected authorized quantities: %+v", lines)
+	}
+}
+
+func TestBuildReturnLines_Rejects(t *testing.T) {
+	tests := []struct {
+		name       string
+		order      func() *model.OutboundOrder
+		inputs     []ReturnLineInput
+		authorized map[uint]int
+		want       error
+	}{
+		{
+			name: "order not shipped",
+			order: func() *model.OutboundOrder {
+				order := shippedOrder()
+				order.Status = model.OutboundOrderStatusPacked
+				return order
+			},
+			inputs: []ReturnLineInput{{LineNo: 1, Quantity: 1}},
+			want:   ErrInvalidState,
+		},
+		{
+			name:   "unknown line",
+			order:  shippedOrder,
+			inputs: []ReturnLineInput{{LineNo: 9, Quantity: 1}},
+			want:   ErrInvalidInput,
+		},
+		{
+			name:   "duplicate line",
+			order:  shippedOrder,
+			inputs: []ReturnLineInput{{LineNo: 1, Quantity: 1}, {LineNo: 1, Quantity: 1}},
+			want:   ErrInvalidInput,
+		},
+		{
+			name:       "exceeds shipped less already authorized",
+			order:      shippedOrder,
+			inputs:     []ReturnLineInput{{LineNo: 1, Quantity: 3}},
+			authorized: map[uint]int{11: 3},
+			want:       ErrInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := buildReturnLines(tt.order(), tt.inputs, tt.authorized); !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func testReturnOrder() *model.ReturnOrder {
+	return &model.ReturnOrder{
+		ID:    1,
+		RMANo: "RMA-001",
+		Lines: []model.ReturnOrderLine{
+			{ID: 21, LineNo: 1, MaterialCode: "M-002", AuthorizedQuantity: 4, Status: model.ReturnLineStatusOpen},
+			{ID: 22, LineNo: 2, MaterialCode: "M-001", AuthorizedQuantity: 2, ReceivedQuantity: 1, Status: model.ReturnLineStatusOpen},
+		},
+	}
+}
+
+func TestMatchReturnLines_SplitsDispositions(t *testing.T) {
+	options := ReturnOptions{RestockLocation: "RETURNS", QuarantineLocation: "QUARANTINE", ScrapLocation: "SCRAP"}
+	rma := testReturnOrder()
+
+	postings, err := matchReturnLines(rma, []ReturnReceiveLineInput{
+		{LineNo: 1, Quantity: 3, Disposition: model.DispositionRestock},
+		{LineNo: 1, Quantity: 1, Disposition: model.DispositionScrap, InspectionNote: "broken seal"},
+		{LineNo: 2, Quantity: 1, Disposition: model.DispositionQuarantine, LocationCode: "QC-01"},
+	}, options)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(postings) != 3 {
+		t.Fatalf("Expected 3 postings, got %d", len(postings))
+	}
+	// 按物料、库位排序
+	got := []string{postings[0].location, postings[1].location, postings[2].location}
+	if got[0] != "QC-01" || got[1] != "RETURNS" || got[2] != "SCRAP" {
+		t.Errorf("Unexpected posting order or locations: %v", got)
+	}
+	if rma.Lines[0].ReceivedQuantity != 4 || rma.Lines[0].Status != model.ReturnLineStatusComplete {
+		t.Errorf("Expected line 1 complete with 4 received, got %+v", rma.Lines[0])
+	}
+	if rma.Lines[1].ReceivedQuantity != 2 || rma.Lines[1].Status != model.ReturnLineStatusComplete {
+		t.Errorf("Expected line 2 complete with 2 received, got %+v", rma.Lines[1])
+	}
+}
+
+func TestMatchReturnLines_Rejects(t *testing.T) {
+	options := ReturnOptions{RestockLocation: "RETURNS"}
+	tests := []struct {
+		name  string
+		input ReturnReceiveLineInput
+	}{
+		{name: "unknown line", input: ReturnReceiveLineInput{LineNo: 9, Quantity: 1, Disposition: model.DispositionRestock}},
+		{name: "over authorized", input: ReturnReceiveLineInput{LineNo: 2, Quantity: 2, Disposition: model.DispositionRestock}},
+		{name: "unknown disposition", input: ReturnReceiveLineInput{LineNo: 1, Quantity: 1, Disposition: "resell"}},
+		{name: "no location configured", input: ReturnReceiveLineInput{LineNo: 1, Quantity: 1, Disposition: model.DispositionScrap}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := matchReturnLines(testReturnOrder(), []ReturnReceiveLineInput{tt.input}, options); !errors.Is(err, ErrInvalidInput) {
+				t.Errorf("Expected ErrInvalidInput, got %v", err)
+			}
+		})
+	}
+}
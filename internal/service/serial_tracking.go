@@ -0,0 +1,281 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"wms/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// applySerials 按库存变动登记、发出或按盘点结果调整序列号
+// 入库逐件登记到库位与批次，已在库的序列号返回 ErrSerialMismatch；
+// 出库给出的序列号须在该库位与批次上，未给出时按单据或收货先后选取；
+// 盘点调整以实盘为准，盘点到的已发出或缺失的序列号重新登记到所盘库位，未盘点到的标记为缺失；
+// 盘点不改变序列号所属的出库单据
+func (l *stockLedger) applySerials(tx *gorm.DB, change StockChange) error {
+	if err := checkSerialList(change.SerialNumbers); err != nil {
+		return err
+	}
+	now := time.Now()
+	switch {
+	case change.Counted:
+		if err := l.registerSerials(tx, change, true, now); err != nil {
+			return err
+		}
+		return l.markSerialsMissing(tx, change)
+	case change.Delta > 0:
+		if err := checkSerialCount(change.MaterialCode, change.SerialNumbers, change.Delta, true); err != nil {
+			return err
+		}
+		return l.registerSerials(tx, change, false, now)
+	default:
+		serials, err := l.lockSourceSerials(tx, change.MaterialCode, change.LocationCode, change.LotNumber,
+			change.SerialNumbers, change.OutboundOrderID, -change.Delta)
+		if err != nil {
+			return err
+		}
+		for i := range serials {
+			serial := &serials[i]
+			serial.Status = model.SerialStatusShipped
+			serial.LocationCode = ""
+			serial.LastReferenceType, serial.LastReferenceID = change.ReferenceType, change.ReferenceID
+			if err := l.repo.SaveSerial(tx, serial); err != nil {
+				return fmt.Errorf("failed to update serial number: %w", err)
+			}
+		}
+		return nil
+	}
+}
+
+// registerSerials 将序列号登记到变动的库位与批次
+// 在其他库位或批次上在库的序列号返回 ErrSerialMismatch，须先移库，避免两处的在库数量与序列号不一致；
+// counted 为 false 时（收货）已在该库位在库的序列号同样返回 ErrSerialMismatch，为 true 时（盘点）保持不变；
+// 收货重新入库的序列号不再属于原出库单据，盘点登记不改变所属的出库单据
+func (l *stockLedger) registerSerials(tx *gorm.DB, change StockChange, counted bool, now time.Time) error {
+	existing, err := l.repo.GetSerialsForUpdate(tx, change.MaterialCode, change.SerialNumbers)
+	if err != nil {
+		return fmt.Errorf("failed to lock serial numbers: %w", err)
+	}
+	known := make(map[string]*model.SerialNumber, len(existing))
+	for i := range existing {
+		known[existing[i].SerialNumber] = &existing[i]
+	}
+
+	for _, number := range change.SerialNumbers {
+		serial, ok := known[number]
+		if !ok {
+			serial = &model.SerialNumber{MaterialCode: change.MaterialCode, SerialNumber: number}
+		} else if serial.Status == model.SerialStatusInStock {
+			if err := checkSerialAt(serial, change.LocationCode, change.LotNumber); err != nil {
+				return err
+			}
+			if counted {
+				continue
+			}
+			return fmt.Errorf("%w: serial %s of %s is already in stock at %s",
+				ErrSerialMismatch, number, change.MaterialCode, serial.LocationCode)
+		}
+		serial.ReceivedAt = &now
+		serial.Status = model.SerialStatusInStock
+		serial.LocationCode, serial.LotNumber = change.LocationCode, change.LotNumber
+		if !counted {
+			serial.OutboundOrderID = nil
+		}
+		serial.LastReferenceType, serial.LastReferenceID = change.ReferenceType, change.ReferenceID
+		if err := l.repo.SaveSerial(tx, serial); err != nil {
+			return fmt.Errorf("failed to save serial number: %w", err)
+		}
+	}
+	return nil
+}
+
+// markSerialsMissing 将盘点未找到的序列号标记为缺失，盘点后已移走的序列号保持不变
+func (l *stockLedger) markSerialsMissing(tx *gorm.DB, change StockChange) error {
+	serials, err := l.repo.GetSerialsForUpdate(tx, change.MaterialCode, change.MissingSerials)
+	if err != nil {
+		return fmt.Errorf("failed to lock serial numbers: %w", err)
+	}
+	for i := range serials {
+		serial := &serials[i]
+		if serial.Status != model.SerialStatusInStock || serial.LocationCode != change.LocationCode || serial.LotNumber != change.LotNumber {
+			continue
+		}
+		serial.Status = model.SerialStatusMissing
+		serial.LocationCode = ""
+		serial.LastReferenceType, serial.LastReferenceID = change.ReferenceType, change.ReferenceID
+		if err := l.repo.SaveSerial(tx, serial); err != nil {
+			return fmt.Errorf("failed to update serial number: %w", err)
+		}
+	}
+	return nil
+}
+
+// moveSerials 将移库的序列号改登记到目标库位
+func (l *stockLedger) moveSerials(tx *gorm.DB, m StockMove) error {
+	if err := checkSerialList(m.SerialNumbers); err != nil {
+		return err
+	}
+	if err := checkSerialCount(m.MaterialCode, m.SerialNumbers, m.Quantity, m.RequireSerials); err != nil {
+		return err
+	}
+	serials, err := l.lockSourceSerials(tx, m.MaterialCode, m.FromLocation, m.LotNumber, m.SerialNumbers, 0, m.Quantity)
+	if err != nil {
+		return err
+	}
+
+	var orderID *uint
+	if m.OutboundOrderID != 0 {
+		id := m.OutboundOrderID
+		orderID = &id
+	}
+	for i := range serials {
+		serial := &serials[i]
+		serial.LocationCode = m.ToLocation
+		serial.OutboundOrderID = orderID
+		serial.LastReferenceType, serial.LastReferenceID = m.ReferenceType, m.ReferenceID
+		if err := l.repo.SaveSerial(tx, serial); err != nil {
+			return fmt.Errorf("failed to update serial number: %w", err)
+		}
+	}
+	return nil
+}
+
+// lockSourceSerials 锁定从库位与批次移出的序列号
+// 给出序列号时每个都须在该库位与批次上在库，否则返回 ErrSerialMismatch；
+// 未给出时按 outboundOrderID（非 0 时）与收货先后选取最多 quantity 个，启用序列号管理前入库、未登记序列号的数量不受影响
+func (l *stockLedger) lockSourceSerials(tx *gorm.DB, materialCode, locationCode, lotNumber string, numbers []string, outboundOrderID uint, quantity int) ([]model.SerialNumber, error) {
+	if len(numbers) == 0 {
+		serials, err := l.repo.ListSerialsForUpdate(tx, materialCode, locationCode, lotNumber, outboundOrderID, quantity)
+		if err != nil {
+			return nil, fmt.Errorf("failed to lock serial numbers: %w", err)
+		}
+		return serials, nil
+	}
+
+	serials, err := l.repo.GetSerialsForUpdate(tx, materialCode, numbers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock serial numbers: %w", err)
+	}
+	found := make(map[string]bool, len(serials))
+	for _, serial := range serials {
+		if serial.Status != model.SerialStatusInStock || serial.LocationCode != locationCode || serial.LotNumber != lotNumber {
+			return nil, fmt.Errorf("%w: serial %s of %s is not in stock at %s",
+				ErrSerialMismatch, serial.SerialNumber, materialCode, locationCode)
+		}
+		found[serial.SerialNumber] = true
+	}
+	for _, number := range numbers {
+		if !found[number] {
+			return nil, fmt.Errorf("%w: serial %s of %s is not registered", ErrSerialMismatch, number, materialCode)
+		}
+	}
+	return serials, nil
+}
+
+// checkCountedSerials 校验盘点到但未登记在所盘库位与批次的序列号没有在其他库位或批次上在库
+// 这类序列号须先移库到所盘库位，否则返回 ErrSerialMismatch
+func (l *stockLedger) checkCountedSerials(tx *gorm.DB, materialCode, locationCode, lotNumber string, numbers []string) error {
+	if len(numbers) == 0 {
+		return nil
+	}
+	serials, err := l.repo.GetSerialsForUpdate(tx, materialCode, numbers)
+	if err != nil {
+		return fmt.Errorf("failed to lock serial numbers: %w", err)
+	}
+	for i := range serials {
+		if serials[i].Status != model.SerialStatusInStock {
+			continue
+		}
+		if err := checkSerialAt(&serials[i], locationCode, lotNumber); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkSerialAt 校验在库的序列号登记在指定库位与批次上，否则返回 ErrSerialMismatch
+func checkSerialAt(serial *model.SerialNumber, locationCode, lotNumber string) error {
+	if serial.LocationCode != locationCode || serial.LotNumber != lotNumber {
+		return fmt.Errorf("%w: serial %s of %s is in stock at %s lot %q, transfer it to %s first",
+			ErrSerialMismatch, serial.SerialNumber, serial.MaterialCode, serial.LocationCode, serial.LotNumber, locationCode)
+	}
+	return nil
+}
+
+// serialTracked 判断物料是否启用了序列号管理
+func (l *stockLedger) serialTracked(tx *gorm.DB, materialCode string) (bool, error) {
+	material, err := l.checkMaterial(tx, materialCode)
+	if err != nil {
+		return false, err
+	}
+	return material.SerialTracked, nil
+}
+
+// registeredSerials 锁定并返回登记在库位与批次上在库的全部序列号
+func (l *stockLedger) registeredSerials(tx *gorm.DB, materialCode, locationCode, lotNumber string) ([]string, error) {
+	serials, err := l.repo.ListSerialsForUpdate(tx, materialCode, locationCode, lotNumber, 0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock serial numbers: %w", err)
+	}
+	numbers := make([]string, 0, len(serials))
+	for _, serial := range serials {
+		numbers = append(numbers, serial.SerialNumber)
+	}
+	return numbers, nil
+}
+
+// checkSerialCount 校验逐件作业给出的序列号个数与数量一致
+// required 为 true 时（收货与操作人扫描的拣货、移库）未给出序列号返回 ErrSerialRequired
+func checkSerialCount(materialCode string, numbers []string, quantity int, required bool) error {
+	if required && len(numbers) == 0 {
+		return fmt.Errorf("%w: %s needs %d serial numbers", ErrSerialRequired, materialCode, quantity)
+	}
+	if len(numbers) > 0 && len(numbers) != quantity {
+		return fmt.Errorf("%w: %d serial numbers given for quantity %d of %s",
+			ErrInvalidInput, len(numbers), quantity, materialCode)
+	}
+	return nil
+}
+
+// checkSerialList 校验序列号清单中没有空值与重复项
+func checkSerialList(numbers []string) error {
+	seen := make(map[string]bool, len(numbers))
+	for _, number := range numbers {
+		if strings.TrimSpace(number) == "" {
+			return fmt.Errorf("%w: serial number cannot be empty", ErrInvalidInput)
+		}
+		if seen[number] {
+			return fmt.Errorf("%w: serial number %s is listed twice", ErrInvalidInput, number)
+		}
+		seen[number] = true
+	}
+	return nil
+}
+
+// compareSerials 比较盘点到的序列号与系统登记的序列号
+// 返回登记在库但未盘点到的 missing 与盘点到但未登记在该库位的 unexpected，均按序列号排序
+func compareSerials(registered, counted []string) (missing, unexpected []string) {
+	countedSet := make(map[string]bool, len(counted))
+	for _, number := range counted {
+		countedSet[number] = true
+	}
+	registeredSet := make(map[string]bool, len(registered))
+	missing, unexpected = []string{}, []string{}
+	for _, number := range registered {
+		registeredSet[number] = true
+		if !countedSet[number] {
+			missing = append(missing, number)
+		}
+	}
+	for _, number := range counted {
+		if !registeredSet[number] {
+			unexpected = append(unexpected, number)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(unexpected)
+	return missing, unexpected
+}
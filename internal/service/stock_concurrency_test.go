@@ -0,0 +1,249 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+	"wms/internal/model"
+	"wms/internal/repository"
+	"wms/pkg/logger"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// openTestDB 连接 WMS_TEST_DATABASE_DSN 指定的 PostgreSQL 测试库
+// 未设置该环境变量时跳过测试
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("WMS_TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("WMS_TEST_DATABASE_DSN not set, skipping PostgreSQL integration test")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&model.Stock{}, &model.InventoryCheckRecord{}, &model.VarianceTolerance{}, &model.StockMovement{},
+		&model.RecountTask{}, &model.CycleCountTask{}, &model.VarianceReason{},
+		&model.StockTransfer{}, &model.StockTransferLine{}, &model.ReplenishmentRule{}, &model.ReplenishmentTask{},
+		&model.Material{}, &model.MaterialBarcode{}, &model.Location{}, &model.SerialNumber{}, &model.InventoryCheckSerial{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+	// 登记测试使用的库位，已登记的库位保持不变
+	if _, err := repository.NewLocationRepository(db).CreateMissing([]model.Location{
+		{Code: "CONC-01", Type: model.LocationTypeReserve, Active: true},
+		{Code: "CONC-02", Type: model.LocationTypeReserve, Active: true},
+		{Code: "XFER-A", Type: model.LocationTypeReserve, Active: true},
+		{Code: "XFER-B", Type: model.LocationTypeReserve, Active: true},
+	}); err != nil {
+		t.Fatalf("Failed to register test locations: %v", err)
+	}
+	return db
+}
+
+// cleanupTestStock 登记测试使用的物料，并在测试结束后删除物料及其相关数据
+func cleanupTestStock(t *testing.T, db *gorm.DB, materialCode string) {
+	if err := db.Create(&model.Material{Code: materialCode, BaseUnit: model.DefaultBaseUnit, Active: true}).Error; err != nil {
+		t.Fatalf("Failed to register test material: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Where("code = ?", materialCode).Delete(&model.Material{})
+		db.Where("material_code = ?", materialCode).Delete(&model.StockMovement{})
+		db.Where("material_code = ?", materialCode).Delete(&model.StockTransferLine{})
+		db.Where("material_code = ?", materialCode).Delete(&model.RecountTask{})
+		db.Where("material_code = ?", materialCode).Delete(&model.CycleCountTask{})
+		db.Where("material_code = ?", materialCode).Delete(&model.InventoryCheckRecord{})
+		db.Where("material_code = ?", materialCode).Delete(&model.Stock{})
+	})
+}
+
+// TestStockLedger_ConcurrentUpdatesAreNotLost 并发对同一个尚不存在的库存行做增量变更，
+// 验证首次写入不会在唯一索引上冲突，且所有增量都被保留
+func TestStockLedger_ConcurrentUpdatesAreNotLost(t *testing.T) {
+	db := openTestDB(t)
+	log, _ := logger.NewLogger("test")
+	materialCode := fmt.Sprintf("TEST-CONC-%d", time.Now().UnixNano())
+	cleanupTestStock(t, db, materialCode)
+
+	stockRepo := repository.NewStockRepository(db)
+	ledger := newStockLedger(stockRepo)
+	policy := DefaultRetryPolicy()
+
+	const workers = 20
+	const incrementsPerWorker = 5
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*incrementsPerWorker)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < incrementsPerWorker; i++ {
+				err := policy.run(log, "test_increment", func() error {
+					return db.Transaction(func(tx *gorm.DB) error {
+						_, err := ledger.apply(tx, StockChange{
+							MaterialCode: materialCode,
+							LocationCode: "CONC-01",
+							Delta:        1,
+							MovementType: model.MovementTypeStockTakeAdjustment,
+							OperatorID:   fmt.Sprintf("worker-%d", worker),
+						})
+						return err
+					})
+				})
+				if err != nil {
+					errs <- err
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("Concurrent update failed: %v", err)
+	}
+
+	var stock model.Stock
+	if err := db.Where("material_code = ? AND location_code = ?", materialCode, "CONC-01").First(&stock).Error; err != nil {
+		t.Fatalf("Failed to load stock: %v", err)
+	}
+	if want := workers * incrementsPerWorker; stock.Quantity != want {
+		t.Errorf("Lost updates detected: expected quantity %d, got %d", want, stock.Quantity)
+	}
+
+	var ledgerSum int
+	db.Model(&model.StockMovement{}).Where("material_code = ?", materialCode).Select("COALESCE(SUM(delta), 0)").Scan(&ledgerSum)
+	if ledgerSum != stock.Quantity {
+		t.Errorf("Ledger sum %d does not equal stock quantity %d", ledgerSum, stock.Quantity)
+	}
+}
+
+// TestProcessInventoryCheck_ConcurrentCountsStayReconciled 并发提交同一库位的盘点，
+// 验证每条盘点记录的差异都基于前一次提交后的库存计算，流水合计始终等于库存
+func TestProcessInventoryCheck_ConcurrentCountsStayReconciled(t *testing.T) {
+	db := openTestDB(t)
+	log, _ := logger.NewLogger("test")
+	materialCode := fmt.Sprintf("TEST-COUNT-%d", time.Now().UnixNano())
+	cleanupTestStock(t, db, materialCode)
+
+	svc := NewInventoryService(
+		repository.NewInventoryCheckRepository(db),
+		repository.NewStockRepository(db),
+		repository.NewVarianceToleranceRepository(db),
+		repository.NewStockTakeRepository(db),
+		repository.NewCycleCountRepository(db),
+		repository.NewVarianceReasonRepository(db),
+		repository.NewReplenishmentRepository(db),
+		InventoryOptions{Retry: DefaultRetryPolicy()},
+		log,
+	)
+
+	const counts = 20
+	var wg sync.WaitGroup
+	for i := 0; i < counts; i++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			_, err := svc.ProcessInventoryCheck(InventoryCheckInput{
+				CheckerID:      fmt.Sprintf("checker-%d", n),
+				LocationCode:   "CONC-02",
+				MaterialCode:   materialCode,
+				ActualQuantity: n + 1,
+			})
+			if err != nil {
+				t.Errorf("Concurrent inventory check failed: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	var stock model.Stock
+	if err := db.Where("material_code = ? AND location_code = ?", materialCode, "CONC-02").First(&stock).Error; err != nil {
+		t.Fatalf("Failed to load stock: %v", err)
+	}
+
+	var records []model.InventoryCheckRecord
+	db.Where("material_code = ?", materialCode).Order("id").Find(&records)
+	if len(records) != counts {
+		t.Fatalf("Expected %d check records, got %d", counts, len(records))
+	}
+
+	// 串行化保证：每条记录的 stock_quantity 等于上一条记录的实盘数量
+	for i := 1; i < len(records); i++ {
+		if records[i].StockQuantity != records[i-1].ActualQuantity {
+			t.Errorf("Record %d computed difference against stale stock %d, previous count was %d",
+				records[i].ID, records[i].StockQuantity, records[i-1].ActualQuantity)
+		}
+	}
+	if last := records[len(records)-1]; stock.Quantity != last.ActualQuantity {
+		t.Errorf("Expected final stock %d to equal last count, got %d", last.ActualQuantity, stock.Quantity)
+	}
+
+	var ledgerSum int
+	db.Model(&model.StockMovement{}).Where("material_code = ?", materialCode).Select("COALESCE(SUM(delta), 0)").Scan(&ledgerSum)
+	if ledgerSum != stock.Quantity {
+		t.Errorf("Ledger sum %d does not equal stock quantity %d", ledgerSum, stock.Quantity)
+	}
+}
+
+// TestCreateTransfer_OpposingTransfersDoNotDeadlock 并发执行相向的多行移库，
+// 验证按 (物料, 库位) 顺序加锁不会死锁，且两个库位的库存合计保持不变
+func TestCreateTransfer_OpposingTransfersDoNotDeadlock(t *testing.T) {
+	db := openTestDB(t)
+	log, _ := logger.NewLogger("test")
+	materialCode := fmt.Sprintf("TEST-XFER-%d", time.Now().UnixNano())
+	cleanupTestStock(t, db, materialCode)
+
+	stockRepo := repository.NewStockRepository(db)
+	svc := NewTransferService(repository.NewTransferRepository(db), stockRepo, DefaultRetryPolicy(), log)
+	seed := newStockLedger(stockRepo)
+	for _, location := range []string{"XFER-A", "XFER-B"} {
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			_, err := seed.apply(tx, StockChange{MaterialCode: materialCode, LocationCode: location, Delta: 100, MovementType: model.MovementTypeOpeningBalance})
+			return err
+		}); err != nil {
+			t.Fatalf("Failed to seed stock: %v", err)
+		}
+	}
+
+	const workers = 10
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			from, to := "XFER-A", "XFER-B"
+			if n%2 == 1 {
+				from, to = to, from
+			}
+			_, err := svc.CreateTransfer(TransferInput{
+				OperatorID: fmt.Sprintf("worker-%d", n),
+				Lines: []TransferLineInput{
+					{MaterialCode: materialCode, FromLocation: from, ToLocation: to, Quantity: 3},
+					{MaterialCode: materialCode, FromLocation: to, ToLocation: from, Quantity: 1},
+				},
+			})
+			if err != nil {
+				t.Errorf("Concurrent transfer failed: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	var total int
+	db.Model(&model.Stock{}).Where("material_code = ?", materialCode).Select("COALESCE(SUM(quantity), 0)").Scan(&total)
+	if total != 200 {
+		t.Errorf("Expected total stock 200 after transfers, got %d", total)
+	}
+	var ledgerSum int
+	db.Model(&model.StockMovement{}).Where("material_code = ?", materialCode).Select("COALESCE(SUM(delta), 0)").Scan(&ledgerSum)
+	if ledgerSum != total {
+		t.Errorf("Ledger sum %d does not equal stock total %d", ledgerSum, total)
+	}
+}
@@ -0,0 +1,351 @@
+package service
+
+import (
+	"fmt"
+	"time"
+	"wms/internal/model"
+	"wms/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// StockChange 描述一次库存数量变动
+// LocationCode 为被变动的库存所在库位；FromLocation/ToLocation 用于记录移库的来源与去向；ReasonCode 为差异原因代码。
+// ReservedDelta 为同时变动的预留数量（如拣货扣减在库并消耗预留）；
+// Counted 表示盘点调整：以实盘为准，允许在库数量低于已预留数量，其余变动不得动用已预留的数量；
+// ReceivedAt 为入库货物的原始入库时间（移库时沿用来源库存的时间），为空时取当前时间；
+// LotNumber 为被变动的批次，ManufactureDate 为入库批次的生产日期（库存尚未记录生产日期时写入）；
+// ExpiryDate 为入库货物的有效期，库存为空或新有效期更早时写入；
+// SerialNumbers 为启用序列号管理的物料入库或出库的序列号，入库时必须逐件给出；出库未给出时按 OutboundOrderID
+// 取拣货给该单据的序列号，OutboundOrderID 为 0 时取最早收货的序列号；
+// 盘点调整时 SerialNumbers 为盘点到但未登记在该库位的序列号，MissingSerials 为登记在该库位但未盘点到的序列号
+type StockChange struct {
+	MaterialCode    string
+	LocationCode    string
+	LotNumber       string
+	ManufactureDate *time.Time
+	ExpiryDate      *time.Time
+	Delta           int
+	ReservedDelta   int
+	Counted         bool
+	ReceivedAt      *time.Time
+	MovementType    string
+	ReferenceType   string
+	ReferenceID     string
+	FromLocation    string
+	ToLocation      string
+	ReasonCode      string
+	OperatorID      string
+	SerialNumbers   []string
+	MissingSerials  []string
+	OutboundOrderID uint
+}
+
+// stockLedger 是所有库存数量变动的唯一入口
+// 它在调用方提供的事务中更新 stocks 并追加 stock_movements 流水，保证两者始终一致
+type stockLedger struct {
+	repo repository.StockRepository
+}
+
+// newStockLedger 创建库存流水记账器
+func newStockLedger(repo repository.StockRepository) *stockLedger {
+	return &stockLedger{repo: repo}
+}
+
+// lock 在事务中锁定指定批次的库存行，库存不存在时创建数量为 0 的记录
+// 同一事务内需要先读取库存再决定变动量时（如盘点计算差异），必须先调用 lock；
+// 物料未登记或已停用、库位未登记、已停用或已冻结时返回对应的错误，不会为其创建库存行
+func (l *stockLedger) lock(tx *gorm.DB, materialCode, locationCode, lotNumber string) (*model.Stock, error) {
+	stock, _, err := l.lockChecked(tx, materialCode, locationCode, lotNumber, false)
+	return stock, err
+}
+
+// lockLot 与 lock 相同，但启用批次管理的物料未给出批次号时返回 ErrLotRequired
+// 用于入库与盘点等需要操作人给出批次的场景
+func (l *stockLedger) lockLot(tx *gorm.DB, materialCode, locationCode, lotNumber string) (*model.Stock, error) {
+	stock, _, err := l.lockChecked(tx, materialCode, locationCode, lotNumber, true)
+	return stock, err
+}
+
+// lockChecked 校验物料与库位主数据后锁定库存行，同时返回物料主数据
+// requireLot 为 true 时启用批次管理的物料未给出批次号返回 ErrLotRequired
+func (l *stockLedger) lockChecked(tx *gorm.DB, materialCode, locationCode, lotNumber string, requireLot bool) (*model.Stock, *model.Material, error) {
+	material, err := l.checkMaterial(tx, materialCode)
+	if err != nil {
+		return nil, nil, err
+	}
+	if requireLot && material.LotManaged && lotNumber == "" {
+		return nil, nil, fmt.Errorf("%w: %s", ErrLotRequired, materialCode)
+	}
+	if err := l.checkLocation(tx, locationCode); err != nil {
+		return nil, nil, err
+	}
+	stock, err := l.lockRow(tx, materialCode, locationCode, lotNumber)
+	if err != nil {
+		return nil, nil, err
+	}
+	return stock, material, nil
+}
+
+// checkMaterial 校验物料已在主数据中登记且处于启用状态，返回物料主数据
+func (l *stockLedger) checkMaterial(tx *gorm.DB, materialCode string) (*model.Material, error) {
+	material, err := l.repo.GetMaterial(tx, materialCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch material: %w", err)
+	}
+	if material == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownMaterial, materialCode)
+	}
+	if !material.Active {
+		return nil, fmt.Errorf("%w: %s", ErrInactiveMaterial, materialCode)
+	}
+	return material, nil
+}
+
+// checkLocation 校验库位已在主数据中登记、处于启用状态且未被冻结
+func (l *stockLedger) checkLocation(tx *gorm.DB, locationCode string) error {
+	location, err := l.repo.GetLocation(tx, locationCode)
+	if err != nil {
+		return fmt.Errorf("failed to fetch location: %w", err)
+	}
+	if location == nil {
+		return fmt.Errorf("%w: %s", ErrUnknownLocation, locationCode)
+	}
+	if !location.Active {
+		return fmt.Errorf("%w: %s", ErrInactiveLocation, locationCode)
+	}
+	if location.Blocked {
+		return fmt.Errorf("%w: %s %s", ErrLocationBlocked, locationCode, location.BlockReason)
+	}
+	return nil
+}
+
+// lockRow 锁定库存行而不校验物料与库位主数据
+func (l *stockLedger) lockRow(tx *gorm.DB, materialCode, locationCode, lotNumber string) (*model.Stock, error) {
+	stock, err := l.repo.GetStockForUpdate(tx, materialCode, locationCode, lotNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock stock: %w", err)
+	}
+	return stock, nil
+}
+
+// balanceAt 根据流水回溯指定批次在指定时点的库存余额
+func (l *stockLedger) balanceAt(tx *gorm.DB, materialCode, locationCode, lotNumber string, at time.Time) (int, error) {
+	balance, err := l.repo.GetBalanceAt(tx, materialCode, locationCode, lotNumber, at)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch historical balance: %w", err)
+	}
+	return balance, nil
+}
+
+// apply 在事务中按增量变更库存并记录流水
+// 库存行在变更前被锁定，并发变更同一库存会串行执行；
+// 变动后数量为负、或非盘点变动使在库数量低于预留数量时返回 ErrInsufficientStock；
+// Delta 为 0 时只确保库存记录存在，不写流水；
+// 单纯释放预留不改变在库数量，不校验物料与库位主数据，以便停用物料或冻结库位后仍能取消单据与任务；
+// 增加启用批次管理的物料的库存时必须给出批次号，否则返回 ErrLotRequired；
+// 已过有效期的库存不能新增预留，返回 ErrStockExpired；
+// 启用序列号管理的物料同时登记、发出或按盘点结果调整序列号，入库未逐件给出序列号时返回 ErrSerialRequired
+func (l *stockLedger) apply(tx *gorm.DB, change StockChange) (*model.Stock, error) {
+	return l.applyChange(tx, change, true)
+}
+
+// applyChange 执行 apply，trackSerials 为 false 时不处理序列号（由 move 在两个库位间整体转移）
+func (l *stockLedger) applyChange(tx *gorm.DB, change StockChange, trackSerials bool) (*model.Stock, error) {
+	var stock *model.Stock
+	var material *model.Material
+	var err error
+	if change.Delta == 0 && change.ReservedDelta < 0 {
+		stock, err = l.lockRow(tx, change.MaterialCode, change.LocationCode, change.LotNumber)
+	} else {
+		stock, material, err = l.lockChecked(tx, change.MaterialCode, change.LocationCode, change.LotNumber, change.Delta > 0)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	newQuantity := stock.Quantity + change.Delta
+	if newQuantity < 0 {
+		return nil, fmt.Errorf("%w: %s at %s has %d, cannot apply %d",
+			ErrInsufficientStock, change.MaterialCode, change.LocationCode, stock.Quantity, change.Delta)
+	}
+	newReserved := stock.ReservedQuantity + change.ReservedDelta
+	if newReserved < 0 {
+		return nil, fmt.Errorf("%w: %s at %s has %d reserved, cannot release %d",
+			ErrInvalidState, change.MaterialCode, change.LocationCode, stock.ReservedQuantity, -change.ReservedDelta)
+	}
+	if change.ReservedDelta > 0 {
+		if err := checkNotExpired(stock); err != nil {
+			return nil, err
+		}
+	}
+	if change.ReservedDelta > 0 && newReserved > newQuantity {
+		return nil, fmt.Errorf("%w: %s at %s has %d available, cannot reserve %d",
+			ErrInsufficientStock, change.MaterialCode, change.LocationCode, stock.Available(), change.ReservedDelta)
+	}
+	if !change.Counted && change.Delta < 0 && newQuantity < newReserved {
+		return nil, fmt.Errorf("%w: %s at %s has %d available, cannot apply %d",
+			ErrInsufficientStock, change.MaterialCode, change.LocationCode, stock.Available(), change.Delta)
+	}
+
+	// 盘点数量无差异时序列号仍可能不一致（少一件、多一件），同样按盘点结果调整
+	if trackSerials && (change.Delta != 0 || change.Counted) && material != nil && material.SerialTracked {
+		if err := l.applySerials(tx, change); err != nil {
+			return nil, err
+		}
+	}
+	if change.Delta == 0 && change.ReservedDelta == 0 {
+		return stock, nil
+	}
+
+	if change.Delta > 0 {
+		receivedAt := time.Now()
+		if change.ReceivedAt != nil {
+			receivedAt = *change.ReceivedAt
+		}
+		if stock.Quantity <= 0 || stock.ReceivedAt == nil || receivedAt.Before(*stock.ReceivedAt) {
+			stock.ReceivedAt = &receivedAt
+		}
+		if stock.ManufactureDate == nil && change.ManufactureDate != nil {
+			stock.ManufactureDate = change.ManufactureDate
+		}
+		if change.ExpiryDate != nil && (stock.Quantity <= 0 || stock.ExpiryDate == nil || change.ExpiryDate.Before(*stock.ExpiryDate)) {
+			stock.ExpiryDate = change.ExpiryDate
+		}
+	}
+	stock.Quantity = newQuantity
+	stock.ReservedQuantity = newReserved
+	if err := l.repo.SaveStock(tx, stock); err != nil {
+		return nil, fmt.Errorf("failed to update stock: %w", err)
+	}
+	if change.Delta == 0 {
+		return stock, nil
+	}
+
+	movement := &model.StockMovement{
+		MovementType:  change.MovementType,
+		ReferenceType: change.ReferenceType,
+		ReferenceID:   change.ReferenceID,
+		MaterialCode:  change.MaterialCode,
+		LocationCode:  change.LocationCode,
+		LotNumber:     change.LotNumber,
+		FromLocation:  change.FromLocation,
+		ToLocation:    change.ToLocation,
+		Delta:         change.Delta,
+		BalanceAfter:  newQuantity,
+		ReasonCode:    change.ReasonCode,
+		OperatorID:    change.OperatorID,
+		MovedAt:       time.Now(),
+	}
+	if err := l.repo.CreateMovement(tx, movement); err != nil {
+		return nil, fmt.Errorf("failed to record stock movement: %w", err)
+	}
+
+	return stock, nil
+}
+
+// reserve 在事务中为出库分配预留指定批次的库存，不改变在库数量也不写流水
+// 可分配数量不足时返回 ErrInsufficientStock，库存已过有效期时返回 ErrStockExpired
+func (l *stockLedger) reserve(tx *gorm.DB, materialCode, locationCode, lotNumber string, quantity int) (*model.Stock, error) {
+	return l.apply(tx, StockChange{MaterialCode: materialCode, LocationCode: locationCode, LotNumber: lotNumber, ReservedDelta: quantity})
+}
+
+// release 在事务中释放指定批次已预留的库存
+func (l *stockLedger) release(tx *gorm.DB, materialCode, locationCode, lotNumber string, quantity int) (*model.Stock, error) {
+	return l.apply(tx, StockChange{MaterialCode: materialCode, LocationCode: locationCode, LotNumber: lotNumber, ReservedDelta: -quantity})
+}
+
+// checkNotExpired 校验库存未过有效期，用于新增预留与按预留取出库存，已过期时返回 ErrStockExpired
+func checkNotExpired(stock *model.Stock) error {
+	if stock.Expired(time.Now()) {
+		return fmt.Errorf("%w: %s at %s expired on %s",
+			ErrStockExpired, stock.MaterialCode, stock.LocationCode, stock.ExpiryDate.Format("2006-01-02"))
+	}
+	return nil
+}
+
+// StockMove 描述一次库位间移库
+// LotNumber 为移动的批次，目标库位沿用来源库存的批次、生产日期、有效期与入库时间；
+// ConsumeReserved 为来源库存同时扣减的预留数量（如拣货消耗出库预留、补货消耗补货预留），
+// 来源库存已过有效期时不能按预留移出，返回 ErrStockExpired；不消耗预留的移库（如移到隔离区）不受限制；
+// KeepReserved 为 true 时移入的数量在目标库位继续预留（如拣货后在包装暂存库位为单据保留）；
+// SerialNumbers 为启用序列号管理的物料移动的序列号，须与数量一致且都在来源库位；未给出时移动来源库位最早收货的序列号，
+// RequireSerials 为 true 时（拣货、移库等由操作人扫描的作业）必须给出；OutboundOrderID 非 0 时在序列号上记录所拣的出库单据
+type StockMove struct {
+	MaterialCode    string
+	LotNumber       string
+	FromLocation    string
+	ToLocation      string
+	Quantity        int
+	ConsumeReserved int
+	KeepReserved    bool
+	MovementType    string
+	ReferenceType   string
+	ReferenceID     string
+	OperatorID      string
+	SerialNumbers   []string
+	RequireSerials  bool
+	OutboundOrderID uint
+}
+
+// move 在事务中将数量从来源库位移到目标库位，写入一出一入两条流水
+// 两个库存行先按库位编码顺序加锁，避免相向移库互相等待；来源库存不足时返回 ErrInsufficientStock
+func (l *stockLedger) move(tx *gorm.DB, m StockMove) error {
+	if m.Quantity <= 0 {
+		return fmt.Errorf("%w: move quantity must be positive", ErrInvalidInput)
+	}
+	if m.FromLocation == m.ToLocation {
+		return fmt.Errorf("%w: source and destination location are both %s", ErrInvalidInput, m.FromLocation)
+	}
+
+	first, second := m.FromLocation, m.ToLocation
+	if second < first {
+		first, second = second, first
+	}
+	locked := make(map[string]*model.Stock, 2)
+	var material *model.Material
+	for _, location := range []string{first, second} {
+		stock, checked, err := l.lockChecked(tx, m.MaterialCode, location, m.LotNumber, false)
+		if err != nil {
+			return err
+		}
+		locked[location], material = stock, checked
+	}
+	if m.ConsumeReserved > 0 {
+		if err := checkNotExpired(locked[m.FromLocation]); err != nil {
+			return err
+		}
+	}
+	if material.SerialTracked {
+		if err := l.moveSerials(tx, m); err != nil {
+			return err
+		}
+	}
+
+	change := StockChange{
+		MaterialCode:  m.MaterialCode,
+		LotNumber:     m.LotNumber,
+		MovementType:  m.MovementType,
+		ReferenceType: m.ReferenceType,
+		ReferenceID:   m.ReferenceID,
+		FromLocation:  m.FromLocation,
+		ToLocation:    m.ToLocation,
+		OperatorID:    m.OperatorID,
+	}
+	out := change
+	out.LocationCode, out.Delta, out.ReservedDelta = m.FromLocation, -m.Quantity, -m.ConsumeReserved
+	source, err := l.applyChange(tx, out, false)
+	if err != nil {
+		return err
+	}
+	in := change
+	in.LocationCode, in.Delta, in.ReceivedAt = m.ToLocation, m.Quantity, source.ReceivedAt
+	in.ManufactureDate, in.ExpiryDate = source.ManufactureDate, source.ExpiryDate
+	if m.KeepReserved {
+		in.ReservedDelta = m.Quantity
+	}
+	if _, err := l.applyChange(tx, in, false); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,493 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"testing"
+	"time"
+	"wms/internal/model"
+	"wms/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// memoryStockRepository 是基于内存的 StockRepository 测试替身
+// 嵌入接口以满足未被测试覆盖的方法
+// materials / locations 为空时视为所有物料 / 库位均已登记且可作业
+type memoryStockRepository struct {
+	repository.StockRepository
+	stocks    map[string]*model.Stock
+	materials map[string]*model.Material
+	locations map[string]*model.Location
+	movements []model.StockMovement
+	serials   map[string]*model.SerialNumber
+	nextID    uint
+}
+
+func newMemoryStockRepository() *memoryStockRepository {
+	return &memoryStockRepository{stocks: map[string]*model.Stock{}, serials: map[string]*model.SerialNumber{}}
+}
+
+// memoryStockKey 返回库存行在内存中的键，未启用批次的库存为 物料@库位，批次库存为 物料@库位#批次
+func memoryStockKey(materialCode, locationCode, lotNumber string) string {
+	if lotNumber == "" {
+		return materialCode + "@" + locationCode
+	}
+	return materialCode + "@" + locationCode + "#" + lotNumber
+}
+
+func (r *memoryStockRepository) GetStockByMaterialAndLocation(tx *gorm.DB, materialCode, locationCode, lotNumber string) (*model.Stock, error) {
+	stock, ok := r.stocks[memoryStockKey(materialCode, locationCode, lotNumber)]
+	if !ok {
+		return nil, nil
+	}
+	copied := *stock
+	return &copied, nil
+}
+
+func (r *memoryStockRepository) GetStockForUpdate(tx *gorm.DB, materialCode, locationCode, lotNumber string) (*model.Stock, error) {
+	stock, _ := r.GetStockByMaterialAndLocation(tx, materialCode, locationCode, lotNumber)
+	if stock == nil {
+		stock = &model.Stock{MaterialCode: materialCode, LocationCode: locationCode, LotNumber: lotNumber}
+		r.SaveStock(tx, stock)
+	}
+	return stock, nil
+}
+
+func (r *memoryStockRepository) GetMaterial(tx *gorm.DB, materialCode string) (*model.Material, error) {
+	if r.materials == nil {
+		return &model.Material{Code: materialCode, Active: true}, nil
+	}
+	return r.materials[materialCode], nil
+}
+
+func (r *memoryStockRepository) GetLocation(tx *gorm.DB, locationCode string) (*model.Location, error) {
+	if r.locations == nil {
+		return &model.Location{Code: locationCode, Type: model.LocationTypeReserve, Active: true}, nil
+	}
+	return r.locations[locationCode], nil
+}
+
+func (r *memoryStockRepository) SaveStock(tx *gorm.DB, stock *model.Stock) error {
+	if stock.ID == 0 {
+		r.nextID++
+		stock.ID = r.nextID
+	}
+	copied := *stock
+	r.stocks[memoryStockKey(stock.MaterialCode, stock.LocationCode, stock.LotNumber)] = &copied
+	return nil
+}
+
+func (r *memoryStockRepository) GetSerialsForUpdate(tx *gorm.DB, materialCode string, serialNumbers []string) ([]model.SerialNumber, error) {
+	var serials []model.SerialNumber
+	for _, number := range serialNumbers {
+		if serial, ok := r.serials[materialCode+"/"+number]; ok {
+			serials = append(serials, *serial)
+		}
+	}
+	return serials, nil
+}
+
+func (r *memoryStockRepository) ListSerialsForUpdate(tx *gorm.DB, materialCode, locationCode, lotNumber string, outboundOrderID uint, limit int) ([]model.SerialNumber, error) {
+	var serials []model.SerialNumber
+	for _, serial := range r.serials {
+		if serial.MaterialCode != materialCode || serial.LocationCode != locationCode || serial.LotNumber != lotNumber ||
+			serial.Status != model.SerialStatusInStock {
+			continue
+		}
+		if outboundOrderID != 0 && (serial.OutboundOrderID == nil || *serial.OutboundOrderID != outboundOrderID) {
+			continue
+		}
+		serials = append(serials, *serial)
+	}
+	sort.Slice(serials, func(i, j int) bool { return serials[i].SerialNumber < serials[j].SerialNumber })
+	if limit > 0 && len(serials) > limit {
+		serials = serials[:limit]
+	}
+	return serials, nil
+}
+
+func (r *memoryStockRepository) SaveSerial(tx *gorm.DB, serial *model.SerialNumber) error {
+	copied := *serial
+	r.serials[serial.MaterialCode+"/"+serial.SerialNumber] = &copied
+	return nil
+}
+
+func (r *memoryStockRepository) CreateMovement(tx *gorm.DB, movement *model.StockMovement) error {
+	r.movements = append(r.movements, *movement)
+	return nil
+}
+
+func TestStockLedgerApply_JournalsEveryChange(t *testing.T) {
+	repo := newMemoryStockRepository()
+	ledger := newStockLedger(repo)
+
+	changes := []StockChange{
+		{MaterialCode: "MAT001", LocationCode: "A-01-01", Delta: 10, MovementType: model.MovementTypeStockTakeAdjustment},
+		{MaterialCode: "MAT001", LocationCode: "A-01-01", Delta: -4, MovementType: model.MovementTypeStockTakeAdjustment},
+		{MaterialCode: "MAT001", LocationCode: "A-01-01", Delta: 0, MovementType: model.MovementTypeStockTakeAdjustment},
+	}
+	for _, change := range changes {
+		if _, err := ledger.apply(nil, change); err != nil {
+			t.Fatalf("apply(%+v) returned error: %v", change, err)
+		}
+	}
+
+	stock, _ := repo.GetStockByMaterialAndLocation(nil, "MAT001", "A-01-01", "")
+	if stock == nil || stock.Quantity != 6 {
+		t.Fatalf("Expected stock quantity 6, got %+v", stock)
+	}
+	if len(repo.movements) != 2 {
+		t.Fatalf("Expected 2 movements (zero delta is not journaled), got %d", len(repo.movements))
+	}
+
+	sum := 0
+	for _, m := range repo.movements {
+		sum += m.Delta
+	}
+	if sum != stock.Quantity {
+		t.Errorf("Ledger sum %d does not equal stock quantity %d", sum, stock.Quantity)
+	}
+	if last := repo.movements[len(repo.movements)-1]; last.BalanceAfter != 6 {
+		t.Errorf("Expected balance_after 6, got %d", last.BalanceAfter)
+	}
+}
+
+func TestStockLedgerApply_RejectsNegativeBalance(t *testing.T) {
+	repo := newMemoryStockRepository()
+	ledger := newStockLedger(repo)
+
+	if _, err := ledger.apply(nil, StockChange{MaterialCode: "MAT001", LocationCode: "A-01-01", Delta: 3}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err := ledger.apply(nil, StockChange{MaterialCode: "MAT001", LocationCode: "A-01-01", Delta: -5})
+	if !errors.Is(err, ErrInsufficientStock) {
+		t.Fatalf("Expected ErrInsufficientStock, got %v", err)
+	}
+	if len(repo.movements) != 1 {
+		t.Errorf("Rejected change must not be journaled, got %d movements", len(repo.movements))
+	}
+}
+
+func TestStockLedgerMove_TransfersBetweenLocations(t *testing.T) {
+	repo := newMemoryStockRepository()
+	ledger := newStockLedger(repo)
+	repo.SaveStock(nil, &model.Stock{MaterialCode: "MAT-1", LocationCode: "RECEIVING", Quantity: 10})
+
+	err := ledger.move(nil, StockMove{
+		MaterialCode:  "MAT-1",
+		FromLocation:  "RECEIVING",
+		ToLocation:    "A-01",
+		Quantity:      4,
+		MovementType:  model.MovementTypePutaway,
+		ReferenceType: model.ReferenceTypePutawayTask,
+		ReferenceID:   "1",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := repo.stocks["MAT-1@RECEIVING"].Quantity; got != 6 {
+		t.Errorf("Expected 6 left at source, got %d", got)
+	}
+	if got := repo.stocks["MAT-1@A-01"].Quantity; got != 4 {
+		t.Errorf("Expected 4 at target, got %d", got)
+	}
+	if len(repo.movements) != 2 || repo.movements[0].Delta != -4 || repo.movements[1].Delta != 4 {
+		t.Errorf("Expected out and in movements, got %+v", repo.movements)
+	}
+
+	if err := ledger.move(nil, StockMove{MaterialCode: "MAT-1", FromLocation: "RECEIVING", ToLocation: "A-01", Quantity: 7}); !errors.Is(err, ErrInsufficientStock) {
+		t.Errorf("Expected ErrInsufficientStock, got %v", err)
+	}
+	if err := ledger.move(nil, StockMove{MaterialCode: "MAT-1", FromLocation: "A-01", ToLocation: "A-01", Quantity: 1}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for same location, got %v", err)
+	}
+}
+
+func TestStockLedgerReserve_ProtectsReservedQuantity(t *testing.T) {
+	repo := newMemoryStockRepository()
+	ledger := newStockLedger(repo)
+	repo.SaveStock(nil, &model.Stock{MaterialCode: "MAT-1", LocationCode: "A-01", Quantity: 10})
+
+	if _, err := ledger.reserve(nil, "MAT-1", "A-01", "", 7); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := ledger.reserve(nil, "MAT-1", "A-01", "", 4); !errors.Is(err, ErrInsufficientStock) {
+		t.Errorf("Expected ErrInsufficientStock when over-reserving, got %v", err)
+	}
+	if len(repo.movements) != 0 {
+		t.Errorf("Expected reservations not to be journaled, got %d movements", len(repo.movements))
+	}
+
+	// 普通出库不能动用已预留的数量
+	if _, err := ledger.apply(nil, StockChange{MaterialCode: "MAT-1", LocationCode: "A-01", Delta: -4, MovementType: model.MovementTypeTransfer}); !errors.Is(err, ErrInsufficientStock) {
+		t.Errorf("Expected ErrInsufficientStock when moving reserved stock, got %v", err)
+	}
+
+	// 盘点以实盘为准，在库数量可以低于已预留数量，预留保持不变
+	stock, err := ledger.apply(nil, StockChange{MaterialCode: "MAT-1", LocationCode: "A-01", Delta: -5, Counted: true, MovementType: model.MovementTypeStockTakeAdjustment})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if stock.Quantity != 5 || stock.ReservedQuantity != 7 {
+		t.Errorf("Expected quantity 5 and reserved 7 after count, got %d and %d", stock.Quantity, stock.ReservedQuantity)
+	}
+
+	if _, err := ledger.release(nil, "MAT-1", "A-01", "", 7); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := repo.stocks["MAT-1@A-01"]; got.ReservedQuantity != 0 || got.Available() != 5 {
+		t.Errorf("Expected reservation released, got reserved %d available %d", got.ReservedQuantity, got.Available())
+	}
+}
+
+func TestStockLedgerMove_CarriesReservationToTarget(t *testing.T) {
+	repo := newMemoryStockRepository()
+	ledger := newStockLedger(repo)
+	repo.SaveStock(nil, &model.Stock{MaterialCode: "MAT-1", LocationCode: "A-01", Quantity: 10, ReservedQuantity: 5})
+
+	// 短拣：预留 5 件只拣到 3 件，来源的 5 件预留全部消耗，暂存库位的 3 件继续预留
+	err := ledger.move(nil, StockMove{
+		MaterialCode:    "MAT-1",
+		FromLocation:    "A-01",
+		ToLocation:      "PACKING",
+		Quantity:        3,
+		ConsumeReserved: 5,
+		KeepReserved:    true,
+		MovementType:    model.MovementTypePick,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	source, target := repo.stocks["MAT-1@A-01"], repo.stocks["MAT-1@PACKING"]
+	if source.Quantity != 7 || source.ReservedQuantity != 0 {
+		t.Errorf("Expected source 7/0 reserved, got %d/%d", source.Quantity, source.ReservedQuantity)
+	}
+	if target.Quantity != 3 || target.ReservedQuantity != 3 {
+		t.Errorf("Expected target 3/3 reserved, got %d/%d", target.Quantity, target.ReservedQuantity)
+	}
+}
+
+func TestStockLedgerApply_RejectsUnknownAndInactiveMaterials(t *testing.T) {
+	repo := newMemoryStockRepository()
+	repo.materials = map[string]*model.Material{
+		"MAT-1": {Code: "MAT-1", Active: true},
+		"MAT-2": {Code: "MAT-2", Active: false},
+	}
+	ledger := newStockLedger(repo)
+
+	if _, err := ledger.apply(nil, StockChange{MaterialCode: "MAT-1", LocationCode: "A-01", Delta: 5}); err != nil {
+		t.Fatalf("Unexpected error for active material: %v", err)
+	}
+	if _, err := ledger.apply(nil, StockChange{MaterialCode: "MAT-X", LocationCode: "A-01", Delta: 5}); !errors.Is(err, ErrUnknownMaterial) {
+		t.Errorf("Expected ErrUnknownMaterial, got %v", err)
+	}
+	if _, ok := repo.stocks["MAT-X@A-01"]; ok {
+		t.Error("Expected no stock row to be created for an unknown material")
+	}
+	if _, err := ledger.lock(nil, "MAT-2", "A-01", ""); !errors.Is(err, ErrInactiveMaterial) {
+		t.Errorf("Expected ErrInactiveMaterial from lock, got %v", err)
+	}
+
+	// 停用前已预留的库存仍可释放，以便取消单据
+	repo.SaveStock(nil, &model.Stock{MaterialCode: "MAT-2", LocationCode: "A-01", Quantity: 4, ReservedQuantity: 4})
+	if _, err := ledger.release(nil, "MAT-2", "A-01", "", 4); err != nil {
+		t.Fatalf("Expected release of inactive material to succeed, got %v", err)
+	}
+	if _, err := ledger.apply(nil, StockChange{MaterialCode: "MAT-2", LocationCode: "A-01", Delta: -1}); !errors.Is(err, ErrInactiveMaterial) {
+		t.Errorf("Expected ErrInactiveMaterial for stock change, got %v", err)
+	}
+}
+
+func TestStockLedgerApply_RejectsUnknownAndBlockedLocations(t *testing.T) {
+	repo := newMemoryStockRepository()
+	repo.locations = map[string]*model.Location{
+		"A-01": {Code: "A-01", Active: true},
+		"A-02": {Code: "A-02", Active: true, Blocked: true, BlockReason: "rack damaged"},
+		"A-03": {Code: "A-03", Active: false},
+	}
+	ledger := newStockLedger(repo)
+
+	if _, err := ledger.apply(nil, StockChange{MaterialCode: "MAT-1", LocationCode: "A-01", Delta: 5}); err != nil {
+		t.Fatalf("Unexpected error for operable location: %v", err)
+	}
+	if _, err := ledger.apply(nil, StockChange{MaterialCode: "MAT-1", LocationCode: "B-99", Delta: 1, Counted: true}); !errors.Is(err, ErrUnknownLocation) {
+		t.Errorf("Expected ErrUnknownLocation for count at unregistered bin, got %v", err)
+	}
+	if _, err := ledger.apply(nil, StockChange{MaterialCode: "MAT-1", LocationCode: "A-03", Delta: 1}); !errors.Is(err, ErrInactiveLocation) {
+		t.Errorf("Expected ErrInactiveLocation, got %v", err)
+	}
+	err := ledger.move(nil, StockMove{MaterialCode: "MAT-1", FromLocation: "A-01", ToLocation: "A-02", Quantity: 2})
+	if !errors.Is(err, ErrLocationBlocked) {
+		t.Errorf("Expected ErrLocationBlocked for move into blocked bin, got %v", err)
+	}
+	if got := repo.stocks["MAT-1@A-01"]; got.Quantity != 5 {
+		t.Errorf("Expected source untouched after rejected move, got %d", got.Quantity)
+	}
+}
+
+func TestStockLedger_KeepsLotsApart(t *testing.T) {
+	repo := newMemoryStockRepository()
+	repo.materials = map[string]*model.Material{
+		"MAT-1": {Code: "MAT-1", Active: true, LotManaged: true},
+	}
+	ledger := newStockLedger(repo)
+	made := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	if _, err := ledger.apply(nil, StockChange{MaterialCode: "MAT-1", LocationCode: "A-01", Delta: 5}); !errors.Is(err, ErrLotRequired) {
+		t.Fatalf("Expected ErrLotRequired for receipt without lot, got %v", err)
+	}
+	if _, err := ledger.apply(nil, StockChange{MaterialCode: "MAT-1", LocationCode: "A-01", LotNumber: "L1", ManufactureDate: &made, Delta: 5}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := ledger.apply(nil, StockChange{MaterialCode: "MAT-1", LocationCode: "A-01", LotNumber: "L2", Delta: 3}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// 一个批次的数量不足时不能动用同库位其他批次的库存
+	err := ledger.move(nil, StockMove{MaterialCode: "MAT-1", LotNumber: "L2", FromLocation: "A-01", ToLocation: "A-02", Quantity: 4})
+	if !errors.Is(err, ErrInsufficientStock) {
+		t.Fatalf("Expected ErrInsufficientStock when moving more than the lot holds, got %v", err)
+	}
+	if err := ledger.move(nil, StockMove{MaterialCode: "MAT-1", LotNumber: "L1", FromLocation: "A-01", ToLocation: "A-02", Quantity: 2}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got := repo.stocks["MAT-1@A-01#L1"].Quantity; got != 3 {
+		t.Errorf("Expected 3 of L1 left at A-01, got %d", got)
+	}
+	if got := repo.stocks["MAT-1@A-01#L2"].Quantity; got != 3 {
+		t.Errorf("Expected L2 untouched at A-01, got %d", got)
+	}
+	moved := repo.stocks["MAT-1@A-02#L1"]
+	if moved.Quantity != 2 || moved.ManufactureDate == nil || !moved.ManufactureDate.Equal(made) {
+		t.Errorf("Expected 2 of L1 at A-02 with manufacture date, got %+v", moved)
+	}
+	for _, m := range repo.movements {
+		if m.LotNumber == "" {
+			t.Errorf("Expected every movement to record its lot, got %+v", m)
+		}
+	}
+}
+
+func TestStockLedger_BlocksExpiredStockFromReservation(t *testing.T) {
+	repo := newMemoryStockRepository()
+	ledger := newStockLedger(repo)
+	today := startOfDay(time.Now())
+	yesterday, later := today.AddDate(0, 0, -1), today.AddDate(0, 0, 10)
+
+	if _, err := ledger.apply(nil, StockChange{MaterialCode: "MAT-1", LocationCode: "A-01", Delta: 5, ExpiryDate: &later}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// 同一库存行再入账更早到期的货物时保留最早的有效期
+	if _, err := ledger.apply(nil, StockChange{MaterialCode: "MAT-1", LocationCode: "A-01", Delta: 5, ExpiryDate: &yesterday}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := repo.stocks["MAT-1@A-01"].ExpiryDate; got == nil || !got.Equal(yesterday) {
+		t.Fatalf("Expected earliest expiry to be kept, got %v", got)
+	}
+
+	if _, err := ledger.reserve(nil, "MAT-1", "A-01", "", 3); !errors.Is(err, ErrStockExpired) {
+		t.Errorf("Expected ErrStockExpired when reserving expired stock, got %v", err)
+	}
+
+	// 已过期库存仍可移至隔离库位，有效期随货物转移
+	if err := ledger.move(nil, StockMove{MaterialCode: "MAT-1", FromLocation: "A-01", ToLocation: "QC-01", Quantity: 4}); err != nil {
+		t.Fatalf("Unexpected error moving expired stock: %v", err)
+	}
+	if got := repo.stocks["MAT-1@QC-01"].ExpiryDate; got == nil || !got.Equal(yesterday) {
+		t.Errorf("Expected expiry date to follow the move, got %v", got)
+	}
+
+	// 预留后过期的库存不能再拣货出库
+	repo.stocks["MAT-1@A-01"].ReservedQuantity = 2
+	err := ledger.move(nil, StockMove{MaterialCode: "MAT-1", FromLocation: "A-01", ToLocation: "PACKING", Quantity: 2, ConsumeReserved: 2, MovementType: model.MovementTypePick})
+	if !errors.Is(err, ErrStockExpired) {
+		t.Errorf("Expected ErrStockExpired when picking expired stock, got %v", err)
+	}
+	if _, err := ledger.release(nil, "MAT-1", "A-01", "", 2); err != nil {
+		t.Errorf("Expected reservation on expired stock to be releasable, got %v", err)
+	}
+}
+
+func TestStockLedger_TracksSerialNumbers(t *testing.T) {
+	repo := newMemoryStockRepository()
+	repo.materials = map[string]*model.Material{
+		"MAT-1": {Code: "MAT-1", Active: true, SerialTracked: true},
+	}
+	ledger := newStockLedger(repo)
+	receipt := StockChange{MaterialCode: "MAT-1", LocationCode: "RECEIVING", Delta: 3, MovementType: model.MovementTypeReceipt}
+
+	if _, err := ledger.apply(nil, receipt); !errors.Is(err, ErrSerialRequired) {
+		t.Fatalf("Expected ErrSerialRequired for receipt without serials, got %v", err)
+	}
+	receipt.SerialNumbers = []string{"SN-1", "SN-2", "SN-3"}
+	if _, err := ledger.apply(nil, receipt); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	receipt.Delta, receipt.SerialNumbers = 1, []string{"SN-2"}
+	if _, err := ledger.apply(nil, receipt); !errors.Is(err, ErrSerialMismatch) {
+		t.Errorf("Expected ErrSerialMismatch when receiving a serial already in stock, got %v", err)
+	}
+
+	// 拣货必须扫描序列号，且序列号必须在来源库位
+	pick := StockMove{MaterialCode: "MAT-1", FromLocation: "RECEIVING", ToLocation: "PACKING", Quantity: 1, RequireSerials: true, OutboundOrderID: 7}
+	if err := ledger.move(nil, pick); !errors.Is(err, ErrSerialRequired) {
+		t.Errorf("Expected ErrSerialRequired for pick without serials, got %v", err)
+	}
+	pick.SerialNumbers = []string{"SN-9"}
+	if err := ledger.move(nil, pick); !errors.Is(err, ErrSerialMismatch) {
+		t.Errorf("Expected ErrSerialMismatch for unknown serial, got %v", err)
+	}
+	pick.SerialNumbers = []string{"SN-3"}
+	if err := ledger.move(nil, pick); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := repo.serials["MAT-1/SN-3"]; got.LocationCode != "PACKING" || got.OutboundOrderID == nil || *got.OutboundOrderID != 7 {
+		t.Errorf("Expected SN-3 at PACKING for order 7, got %+v", got)
+	}
+
+	// 发运按单据发出拣货时扫描的序列号
+	ship := StockChange{MaterialCode: "MAT-1", LocationCode: "PACKING", Delta: -1, OutboundOrderID: 7, MovementType: model.MovementTypeShip}
+	if _, err := ledger.apply(nil, ship); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := repo.serials["MAT-1/SN-3"]; got.Status != model.SerialStatusShipped || got.LocationCode != "" {
+		t.Errorf("Expected SN-3 shipped, got %+v", got)
+	}
+
+	// 盘点：SN-1 未找到，SN-4 为多出的序列号，数量无差异时同样调整序列号
+	count := StockChange{MaterialCode: "MAT-1", LocationCode: "RECEIVING", Counted: true, MovementType: model.MovementTypeStockTakeAdjustment,
+		SerialNumbers: []string{"SN-4"}, MissingSerials: []string{"SN-1"}}
+	if _, err := ledger.apply(nil, count); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := repo.serials["MAT-1/SN-1"]; got.Status != model.SerialStatusMissing {
+		t.Errorf("Expected SN-1 missing, got %+v", got)
+	}
+	if got := repo.serials["MAT-1/SN-4"]; got.Status != model.SerialStatusInStock || got.LocationCode != "RECEIVING" {
+		t.Errorf("Expected SN-4 registered at RECEIVING, got %+v", got)
+	}
+	// 在其他库位在库的序列号须先移库，盘点不改登记；盘点缺失不清除所属单据
+	misplaced := StockChange{MaterialCode: "MAT-1", LocationCode: "PACKING", Counted: true, MovementType: model.MovementTypeStockTakeAdjustment,
+		SerialNumbers: []string{"SN-2"}}
+	if _, err := ledger.apply(nil, misplaced); !errors.Is(err, ErrSerialMismatch) {
+		t.Errorf("Expected ErrSerialMismatch for serial in stock elsewhere, got %v", err)
+	}
+	if got := repo.serials["MAT-1/SN-2"]; got.Status != model.SerialStatusInStock || got.LocationCode != "RECEIVING" {
+		t.Errorf("Expected SN-2 to stay at RECEIVING, got %+v", got)
+	}
+	pick.SerialNumbers, pick.OutboundOrderID = []string{"SN-2"}, 8
+	if err := ledger.move(nil, pick); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	lost := StockChange{MaterialCode: "MAT-1", LocationCode: "PACKING", Delta: -1, Counted: true, MovementType: model.MovementTypeStockTakeAdjustment,
+		MissingSerials: []string{"SN-2"}}
+	if _, err := ledger.apply(nil, lost); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := repo.serials["MAT-1/SN-2"]; got.Status != model.SerialStatusMissing || got.OutboundOrderID == nil || *got.OutboundOrderID != 8 {
+		t.Errorf("Expected SN-2 missing and still linked to order 8, got %+v", got)
+	}
+}
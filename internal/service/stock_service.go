@@ -0,0 +1,410 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+	"wms/internal/model"
+	"wms/internal/repository"
+	"wms/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultMovementPageSize 未指定分页大小时的默认值
+	defaultMovementPageSize = 50
+	// maxMovementPageSize 单页允许返回的最大流水数
+	maxMovementPageSize = 200
+	// DefaultExpiryAlertDays 临期库存查询未指定天数时的默认值
+	DefaultExpiryAlertDays = 30
+)
+
+// MovementQuery 表示库存流水列表查询参数
+type MovementQuery struct {
+	MaterialCode string
+	LocationCode string
+	LotNumber    string
+	MovementType string
+	From         time.Time
+	To           time.Time
+	Cursor       string
+	Limit        int
+}
+
+// MovementPage 表示一页库存流水
+type MovementPage struct {
+	Movements  []model.StockMovement
+	NextCursor string
+	HasMore    bool
+}
+
+// ReconciliationResult 表示库存与流水对账结果
+type ReconciliationResult struct {
+	CheckedAt  time.Time                   `json:"checked_at"`
+	Balanced   bool                        `json:"balanced"`
+	Mismatches []repository.LedgerMismatch `json:"mismatches"`
+}
+
+// StockBalance 表示一个库存行的在库、已预留与可分配数量
+type StockBalance struct {
+	model.Stock
+	AvailableQuantity int `json:"available_quantity"`
+}
+
+// LotTrace 表示一个批次的追溯结果
+// Locations 为批次当前在库的库位；Sources 为批次的来源（收货、退货、期初余额），
+// Shipments 为批次的去向（发运），Adjustments 为盘点差异调整；库内搬运不改变批次总量，不单独列出
+type LotTrace struct {
+	MaterialCode     string                          `json:"material_code,omitempty"`
+	LotNumber        string                          `json:"lot_number"`
+	OnHandQuantity   int                             `json:"on_hand_quantity"`
+	ReceivedQuantity int                             `json:"received_quantity"`
+	ShippedQuantity  int                             `json:"shipped_quantity"`
+	Locations        []StockBalance                  `json:"locations"`
+	Sources          []repository.LotMovementSummary `json:"sources"`
+	Shipments        []repository.LotMovementSummary `json:"shipments"`
+	Adjustments      []repository.LotMovementSummary `json:"adjustments"`
+}
+
+// ExpiringStock 表示一条临期或已过期的库存，DaysToExpiry 为距有效期的天数，已过期时为负数
+type ExpiringStock struct {
+	StockBalance
+	DaysToExpiry int  `json:"days_to_expiry"`
+	Expired      bool `json:"expired"`
+}
+
+// LocationExpiry 表示一个库位上的临期与已过期库存
+type LocationExpiry struct {
+	LocationCode     string          `json:"location_code"`
+	ExpiringQuantity int             `json:"expiring_quantity"`
+	ExpiredQuantity  int             `json:"expired_quantity"`
+	Stocks           []ExpiringStock `json:"stocks"`
+}
+
+// ExpiryReport 表示按库位汇总的临期库存清单
+// Cutoff 为 AsOf 所在日期加 WithinDays 天，有效期不晚于 Cutoff 的库存列入清单，已过期的库存同样列出
+type ExpiryReport struct {
+	AsOf          time.Time        `json:"as_of"`
+	WithinDays    int              `json:"within_days"`
+	Cutoff        time.Time        `json:"cutoff"`
+	StockCount    int              `json:"stock_count"`
+	ExpiredCount  int              `json:"expired_count"`
+	LocationCount int              `json:"location_count"`
+	Locations     []LocationExpiry `json:"locations"`
+}
+
+// StockService 定义库存与库存流水的查询及对账接口
+type StockService interface {
+	// ListStocks 按物料、库位与批次查询库存余额，条件为空时不过滤
+	ListStocks(filter repository.StockFilter) ([]StockBalance, error)
+
+	// TraceLot 追溯批次当前所在库位、来源与去向，materialCode 为空时追溯所有物料的同号批次
+	TraceLot(materialCode, lotNumber string) (*LotTrace, error)
+
+	// ExpiryReport 按库位列出 withinDays 天内到期与已过期的在库库存
+	ExpiryReport(withinDays int) (*ExpiryReport, error)
+
+	// ListSerials 按物料、序列号、库位、批次与状态查询序列号
+	ListSerials(filter repository.SerialFilter) ([]model.SerialNumber, error)
+
+	// ListMovements 按物料/库位等条件以游标分页方式查询库存流水（按时间倒序）
+	ListMovements(query MovementQuery) (*MovementPage, error)
+
+	// ReconcileLedger 校验每个 (物料, 库位, 批次) 的 stocks.quantity 等于流水 delta 合计
+	ReconcileLedger() (*ReconciliationResult, error)
+
+	// PostOpeningBalances 为启用流水前已存在、尚无任何流水的库存补记期初余额流水
+	// 返回补记的流水条数
+	PostOpeningBalances(operatorID string) (int, error)
+}
+
+// stockService 是 StockService 的具体实现
+type stockService struct {
+	repo   repository.StockRepository
+	logger *logger.Logger
+}
+
+// NewStockService 创建一个新的 StockService 实例
+func NewStockService(repo repository.StockRepository, log *logger.Logger) StockService {
+	return &stockService{
+		repo:   repo,
+		logger: log,
+	}
+}
+
+// ListStocks 查询库存余额
+func (s *stockService) ListStocks(filter repository.StockFilter) ([]StockBalance, error) {
+	stocks, err := s.repo.ListStocks(filter)
+	if err != nil {
+		s.logger.Error("Failed to list stocks",
+			zap.String("material_code", filter.MaterialCode),
+			zap.String("location_code", filter.LocationCode),
+			zap.String("lot_number", filter.LotNumber),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to list stocks: %w", err)
+	}
+
+	balances := make([]StockBalance, 0, len(stocks))
+	for _, stock := range stocks {
+		balances = append(balances, StockBalance{Stock: stock, AvailableQuantity: stock.Available()})
+	}
+	return balances, nil
+}
+
+// ListSerials 查询序列号
+func (s *stockService) ListSerials(filter repository.SerialFilter) ([]model.SerialNumber, error) {
+	switch filter.Status {
+	case "", model.SerialStatusInStock, model.SerialStatusShipped, model.SerialStatusMissing:
+	default:
+		return nil, fmt.Errorf("%w: unknown serial status %s", ErrInvalidInput, filter.Status)
+	}
+	serials, err := s.repo.ListSerials(filter)
+	if err != nil {
+		s.logger.Error("Failed to list serial numbers",
+			zap.String("material_code", filter.MaterialCode),
+			zap.String("serial_number", filter.SerialNumber),
+			zap.String("location_code", filter.LocationCode),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to list serial numbers: %w", err)
+	}
+	return serials, nil
+}
+
+// TraceLot 追溯批次
+// 在库库位取数量大于 0 的库存行；流水按关联单据汇总后按流水类型归入来源、去向与调整
+func (s *stockService) TraceLot(materialCode, lotNumber string) (*LotTrace, error) {
+	if lotNumber == "" {
+		return nil, fmt.Errorf("%w: lot_number is required", ErrInvalidInput)
+	}
+	stocks, err := s.ListStocks(repository.StockFilter{MaterialCode: materialCode, LotNumber: lotNumber})
+	if err != nil {
+		return nil, err
+	}
+	summaries, err := s.repo.SummarizeLotMovements(materialCode, lotNumber)
+	if err != nil {
+		s.logger.Error("Failed to summarize lot movements",
+			zap.String("material_code", materialCode),
+			zap.String("lot_number", lotNumber),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to summarize lot movements: %w", err)
+	}
+	if len(stocks) == 0 && len(summaries) == 0 {
+		return nil, fmt.Errorf("%w: lot %s", ErrNotFound, lotNumber)
+	}
+	return buildLotTrace(materialCode, lotNumber, stocks, summaries), nil
+}
+
+// buildLotTrace 由批次的库存行与流水汇总构造追溯结果
+func buildLotTrace(materialCode, lotNumber string, stocks []StockBalance, summaries []repository.LotMovementSummary) *LotTrace {
+	trace := &LotTrace{
+		MaterialCode: materialCode,
+		LotNumber:    lotNumber,
+		Locations:    []StockBalance{},
+		Sources:      []repository.LotMovementSummary{},
+		Shipments:    []repository.LotMovementSummary{},
+		Adjustments:  []repository.LotMovementSummary{},
+	}
+	for _, stock := range stocks {
+		if stock.Quantity == 0 {
+			continue
+		}
+		trace.OnHandQuantity += stock.Quantity
+		trace.Locations = append(trace.Locations, stock)
+	}
+	for _, summary := range summaries {
+		switch summary.MovementType {
+		case model.MovementTypeReceipt, model.MovementTypeReturn, model.MovementTypeOpeningBalance:
+			trace.ReceivedQuantity += summary.Quantity
+			trace.Sources = append(trace.Sources, summary)
+		case model.MovementTypeShip:
+			trace.ShippedQuantity -= summary.Quantity
+			trace.Shipments = append(trace.Shipments, summary)
+		case model.MovementTypeStockTakeAdjustment:
+			trace.Adjustments = append(trace.Adjustments, summary)
+		}
+	}
+	return trace
+}
+
+// ExpiryReport 查询临期库存
+func (s *stockService) ExpiryReport(withinDays int) (*ExpiryReport, error) {
+	if withinDays < 0 {
+		return nil, fmt.Errorf("%w: days cannot be negative", ErrInvalidInput)
+	}
+	now := time.Now()
+	cutoff := startOfDay(now).AddDate(0, 0, withinDays)
+	stocks, err := s.repo.ListExpiringStocks(cutoff)
+	if err != nil {
+		s.logger.Error("Failed to list expiring stocks", zap.Int("within_days", withinDays), zap.Error(err))
+		return nil, fmt.Errorf("failed to list expiring stocks: %w", err)
+	}
+	report := buildExpiryReport(now, withinDays, stocks)
+	report.Cutoff = cutoff
+	return report, nil
+}
+
+// buildExpiryReport 将已按库位排序的临期库存按库位汇总
+func buildExpiryReport(now time.Time, withinDays int, stocks []model.Stock) *ExpiryReport {
+	report := &ExpiryReport{AsOf: now, WithinDays: withinDays, Locations: []LocationExpiry{}}
+	for _, stock := range stocks {
+		if stock.ExpiryDate == nil {
+			continue
+		}
+		n := len(report.Locations)
+		if n == 0 || report.Locations[n-1].LocationCode != stock.LocationCode {
+			report.Locations = append(report.Locations, LocationExpiry{LocationCode: stock.LocationCode, Stocks: []ExpiringStock{}})
+			n++
+		}
+		location := &report.Locations[n-1]
+		item := ExpiringStock{
+			StockBalance: StockBalance{Stock: stock, AvailableQuantity: stock.Available()},
+			DaysToExpiry: calendarDaysUntil(now, *stock.ExpiryDate),
+			Expired:      stock.Expired(now),
+		}
+		location.Stocks = append(location.Stocks, item)
+		report.StockCount++
+		if item.Expired {
+			location.ExpiredQuantity += stock.Quantity
+			report.ExpiredCount++
+		} else {
+			location.ExpiringQuantity += stock.Quantity
+		}
+	}
+	report.LocationCount = len(report.Locations)
+	return report
+}
+
+// calendarDaysUntil 返回 now 所在日期到 day 所在日期的天数，只比较日期部分
+func calendarDaysUntil(now, day time.Time) int {
+	ny, nm, nd := now.Date()
+	dy, dm, dd := day.Date()
+	from := time.Date(ny, nm, nd, 0, 0, 0, 0, time.UTC)
+	to := time.Date(dy, dm, dd, 0, 0, 0, 0, time.UTC)
+	return int(to.Sub(from).Hours() / 24)
+}
+
+// ListMovements 查询库存流水
+func (s *stockService) ListMovements(query MovementQuery) (*MovementPage, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultMovementPageSize
+	}
+	if limit > maxMovementPageSize {
+		limit = maxMovementPageSize
+	}
+
+	filter := repository.MovementFilter{
+		MaterialCode: query.MaterialCode,
+		LocationCode: query.LocationCode,
+		LotNumber:    query.LotNumber,
+		MovementType: query.MovementType,
+		From:         query.From,
+		To:           query.To,
+		Limit:        limit + 1,
+	}
+	if query.Cursor != "" {
+		beforeID, err := decodeIDCursor(query.Cursor)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+		}
+		filter.BeforeID = beforeID
+	}
+
+	movements, err := s.repo.ListMovements(filter)
+	if err != nil {
+		s.logger.Error("Failed to list stock movements",
+			zap.String("material_code", query.MaterialCode),
+			zap.String("location_code", query.LocationCode),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to list movements: %w", err)
+	}
+
+	page := &MovementPage{Movements: movements}
+	if len(movements) > limit {
+		page.Movements = movements[:limit]
+		page.HasMore = true
+		page.NextCursor = encodeIDCursor(page.Movements[limit-1].ID)
+	}
+	if page.Movements == nil {
+		page.Movements = []model.StockMovement{}
+	}
+	return page, nil
+}
+
+// ReconcileLedger 对账库存与流水
+func (s *stockService) ReconcileLedger() (*ReconciliationResult, error) {
+	mismatches, err := s.repo.FindLedgerMismatches()
+	if err != nil {
+		s.logger.Error("Failed to reconcile stock ledger", zap.Error(err))
+		return nil, fmt.Errorf("failed to reconcile ledger: %w", err)
+	}
+	if mismatches == nil {
+		mismatches = []repository.LedgerMismatch{}
+	}
+
+	result := &ReconciliationResult{
+		CheckedAt:  time.Now(),
+		Balanced:   len(mismatches) == 0,
+		Mismatches: mismatches,
+	}
+
+	if result.Balanced {
+		s.logger.Info("Stock ledger reconciled successfully")
+	} else {
+		s.logger.Warn("Stock ledger mismatches found", zap.Int("mismatch_count", len(mismatches)))
+	}
+	return result, nil
+}
+
+// PostOpeningBalances 为无流水的库存补记期初余额
+func (s *stockService) PostOpeningBalances(operatorID string) (int, error) {
+	tx := s.repo.BeginTransaction()
+	if tx.Error != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", tx.Error)
+	}
+
+	stocks, err := s.repo.FindStocksWithoutMovements(tx)
+	if err != nil {
+		s.repo.RollbackTransaction(tx)
+		s.logger.Error("Failed to find stocks without movements", zap.Error(err))
+		return 0, fmt.Errorf("failed to find stocks without movements: %w", err)
+	}
+
+	now := time.Now()
+	for _, stock := range stocks {
+		movement := &model.StockMovement{
+			MovementType:  model.MovementTypeOpeningBalance,
+			ReferenceType: "stock",
+			ReferenceID:   strconv.FormatUint(uint64(stock.ID), 10),
+			MaterialCode:  stock.MaterialCode,
+			LocationCode:  stock.LocationCode,
+			LotNumber:     stock.LotNumber,
+			Delta:         stock.Quantity,
+			BalanceAfter:  stock.Quantity,
+			OperatorID:    operatorID,
+			MovedAt:       now,
+		}
+		if err := s.repo.CreateMovement(tx, movement); err != nil {
+			s.repo.RollbackTransaction(tx)
+			s.logger.Error("Failed to post opening balance",
+				zap.String("material_code", stock.MaterialCode),
+				zap.String("location_code", stock.LocationCode),
+				zap.Error(err),
+			)
+			return 0, fmt.Errorf("failed to post opening balance: %w", err)
+		}
+	}
+
+	if err := s.repo.CommitTransaction(tx); err != nil {
+		s.repo.RollbackTransaction(tx)
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.logger.Info("Opening balances posted", zap.Int("count", len(stocks)))
+	return len(stocks), nil
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	"wms/internal/model"
+	"wms/internal/repository"
+)
+
+func TestBuildLotTrace(t *testing.T) {
+	stocks := []StockBalance{
+		{Stock: model.Stock{MaterialCode: "MAT-1", LocationCode: "A-01", LotNumber: "L1", Quantity: 4}},
+		{Stock: model.Stock{MaterialCode: "MAT-1", LocationCode: "RECEIVING", LotNumber: "L1", Quantity: 0}},
+	}
+	summaries := []repository.LotMovementSummary{
+		{MaterialCode: "MAT-1", MovementType: model.MovementTypeReceipt, ReferenceType: model.ReferenceTypeInboundReceipt, ReferenceID: "7", Quantity: 10},
+		{MaterialCode: "MAT-1", MovementType: model.MovementTypePutaway, ReferenceType: model.ReferenceTypePutawayTask, ReferenceID: "3", Quantity: 0},
+		{MaterialCode: "MAT-1", MovementType: model.MovementTypeShip, ReferenceType: model.ReferenceTypeShipment, ReferenceID: "12", Quantity: -5},
+		{MaterialCode: "MAT-1", MovementType: model.MovementTypeStockTakeAdjustment, ReferenceType: model.ReferenceTypeInventoryCheck, ReferenceID: "9", Quantity: -1},
+	}
+
+	trace := buildLotTrace("MAT-1", "L1", stocks, summaries)
+
+	if trace.OnHandQuantity != 4 || len(trace.Locations) != 1 || trace.Locations[0].LocationCode != "A-01" {
+		t.Errorf("Expected 4 on hand at A-01 only, got %d at %+v", trace.OnHandQuantity, trace.Locations)
+	}
+	if trace.ReceivedQuantity != 10 || len(trace.Sources) != 1 {
+		t.Errorf("Expected 10 received from one source, got %d from %d", trace.ReceivedQuantity, len(trace.Sources))
+	}
+	if trace.ShippedQuantity != 5 || len(trace.Shipments) != 1 || trace.Shipments[0].ReferenceID != "12" {
+		t.Errorf("Expected 5 shipped on shipment 12, got %d on %+v", trace.ShippedQuantity, trace.Shipments)
+	}
+	if len(trace.Adjustments) != 1 {
+		t.Errorf("Expected one adjustment, got %d", len(trace.Adjustments))
+	}
+}
+
+func TestBuildExpiryReport(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+	day := func(d int) *time.Time {
+		at := time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC)
+		return &at
+	}
+	stocks := []model.Stock{
+		{MaterialCode: "MAT-1", LocationCode: "A-01", LotNumber: "L1", Quantity: 4, ExpiryDate: day(9)},
+		{MaterialCode: "MAT-2", LocationCode: "A-01", Quantity: 6, ReservedQuantity: 2, ExpiryDate: day(10)},
+		{MaterialCode: "MAT-1", LocationCode: "B-01", LotNumber: "L2", Quantity: 3, ExpiryDate: day(20)},
+		{MaterialCode: "MAT-3", LocationCode: "B-01", Quantity: 1},
+	}
+
+	report := buildExpiryReport(now, 10, stocks)
+
+	if report.StockCount != 3 || report.ExpiredCount != 1 || report.LocationCount != 2 {
+		t.Fatalf("Expected 3 lines, 1 expired at 2 locations, got %d, %d at %d", report.StockCount, report.ExpiredCount, report.LocationCount)
+	}
+	a := report.Locations[0]
+	if a.LocationCode != "A-01" || a.ExpiredQuantity != 4 || a.ExpiringQuantity != 6 {
+		t.Errorf("Expected A-01 with 4 expired and 6 expiring, got %+v", a)
+	}
+	// 有效期当天仍可使用
+	if a.Stocks[1].Expired || a.Stocks[1].DaysToExpiry != 0 || a.Stocks[1].AvailableQuantity != 4 {
+		t.Errorf("Expected stock expiring today to be usable with 4 available, got %+v", a.Stocks[1])
+	}
+	if got := a.Stocks[0].DaysToExpiry; got != -1 {
+		t.Errorf("Expected expired stock 1 day past expiry, got %d", got)
+	}
+	if got := report.Locations[1].Stocks[0].DaysToExpiry; got != 10 {
+		t.Errorf("Expected B-01 stock to expire in 10 days, got %d", got)
+	}
+}
+
+func TestCompareSerials(t *testing.T) {
+	missing, unexpected := compareSerials([]string{"SN-3", "SN-1", "SN-2"}, []string{"SN-2", "SN-5", "SN-1"})
+
+	if !reflect.DeepEqual(missing, []string{"SN-3"}) {
+		t.Errorf("Expected SN-3 missing, got %v", missing)
+	}
+	if !reflect.DeepEqual(unexpected, []string{"SN-5"}) {
+		t.Errorf("Expected SN-5 unexpected, got %v", unexpected)
+	}
+
+	missing, unexpected = compareSerials([]string{"SN-1"}, nil)
+	if len(missing) != 1 || unexpected == nil || len(unexpected) != 0 {
+		t.Errorf("Expected every registered serial missing on an empty count, got %v / %v", missing, unexpected)
+	}
+}
@@ -0,0 +1,498 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"wms/internal/model"
+	"wms/internal/repository"
+	"wms/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// StockTakeInput 表示创建盘点单的输入数据
+type StockTakeInput struct {
+	Name          string
+	Description   string
+	CreatedBy     string
+	LocationCodes []string
+	MaterialCodes []string
+	CheckerIDs    []string
+	BlindCount    bool
+}
+
+// StockTakeSummary 表示盘点单的进度与差异汇总
+// 已驳回的盘点不计入覆盖率与差异合计；ExpiredCount 为盘点时已过有效期的盘点记录数
+type StockTakeSummary struct {
+	StockTakeID          uint     `json:"stock_take_id"`
+	Status               string   `json:"status"`
+	ExpectedLocations    int      `json:"expected_locations"`
+	CountedLocations     int      `json:"counted_locations"`
+	CoveragePercent      float64  `json:"coverage_percent"`
+	UncountedLocations   []string `json:"uncounted_locations"`
+	RecordCount          int      `json:"record_count"`
+	PendingApprovalCount int      `json:"pending_approval_count"`
+	ExpiredCount         int      `json:"expired_count"`
+	NetVariance          int      `json:"net_variance"`
+	AbsoluteVariance     int      `json:"absolute_variance"`
+}
+
+// StockTakeService 定义盘点单的业务接口
+type StockTakeService interface {
+	// CreateStockTake 创建草稿状态的盘点单
+	CreateStockTake(input StockTakeInput) (*model.StockTake, error)
+
+	// GetStockTake 查询盘点单，不存在时返回 ErrNotFound
+	GetStockTake(id uint) (*model.StockTake, error)
+
+	// ListStockTakes 查询盘点单列表，status 为空时返回全部
+	ListStockTakes(status string) ([]model.StockTake, error)
+
+	// StartStockTake 开始盘点（draft -> in_progress），之后可上传关联该盘点单的盘点
+	// 盲盘盘点单在此时按库位生成盘点任务并冻结系统库存
+	StartStockTake(id uint) (*model.StockTake, error)
+
+	// CloseCounting 截止盘点（in_progress -> counting_closed），之后不再接受上传
+	CloseCounting(id uint) (*model.StockTake, error)
+
+	// ReconcileStockTake 确认差异（counting_closed -> reconciled），要求盘点单内没有待审批的差异
+	ReconcileStockTake(id uint) (*model.StockTake, error)
+
+	// PostStockTake 过账（reconciled -> posted），将已批准的差异按增量过账至当前库存
+	PostStockTake(id uint, operatorID string) (*model.StockTake, error)
+
+	// GetStockTakeSummary 汇总盘点单的覆盖率、未盘库位与差异合计
+	GetStockTakeSummary(id uint) (*StockTakeSummary, error)
+
+	// ListCountTasks 查询盲盘盘点单的盘点任务（不含系统库存），checkerID 非空时只返回分配给该盘点人的任务
+	ListCountTasks(stockTakeID uint, checkerID string) ([]model.CountTask, error)
+}
+
+// stockTakeService 是 StockTakeService 的具体实现
+type stockTakeService struct {
+	repo        repository.StockTakeRepository
+	checkRepo   repository.InventoryCheckRepository
+	stockRepo   repository.StockRepository
+	router      *taskRouter
+	ledger      *stockLedger
+	replenisher *replenisher
+	retry       RetryPolicy
+	logger      *logger.Logger
+}
+
+// NewStockTakeService 创建新的 StockTakeService 实例
+func NewStockTakeService(repo repository.StockTakeRepository, checkRepo repository.InventoryCheckRepository, stockRepo repository.StockRepository,
+	locationRepo repository.LocationRepository, layoutRepo repository.LayoutRepository, replenishmentRepo repository.ReplenishmentRepository,
+	retry RetryPolicy, log *logger.Logger) StockTakeService {
+	return &stockTakeService{
+		repo:        repo,
+		checkRepo:   checkRepo,
+		stockRepo:   stockRepo,
+		router:      newTaskRouter(layoutRepo, locationRepo),
+		ledger:      newStockLedger(stockRepo),
+		replenisher: newReplenisher(replenishmentRepo, stockRepo),
+		retry:       retry,
+		logger:      log,
+	}
+}
+
+// stockTakePredecessor 记录每个状态唯一允许的前置状态
+var stockTakePredecessor = map[string]string{
+	model.StockTakeStatusInProgress:     model.StockTakeStatusDraft,
+	model.StockTakeStatusCountingClosed: model.StockTakeStatusInProgress,
+	model.StockTakeStatusReconciled:     model.StockTakeStatusCountingClosed,
+	model.StockTakeStatusPosted:         model.StockTakeStatusReconciled,
+}
+
+// advanceStockTake 将盘点单流转到目标状态并记录对应时间
+// 当前状态不是目标状态的前置状态时返回 ErrInvalidState
+func advanceStockTake(st *model.StockTake, to string, now time.Time) error {
+	from, ok := stockTakePredecessor[to]
+	if !ok {
+		return fmt.Errorf("%w: unknown stock take status %q", ErrInvalidInput, to)
+	}
+	if st.Status != from {
+		return fmt.Errorf("%w: stock take %d is %s, expected %s", ErrInvalidState, st.ID, st.Status, from)
+	}
+
+	st.Status = to
+	switch to {
+	case model.StockTakeStatusInProgress:
+		st.StartedAt = &now
+	case model.StockTakeStatusCountingClosed:
+		st.CountingClosedAt = &now
+	case model.StockTakeStatusReconciled:
+		st.ReconciledAt = &now
+	case model.StockTakeStatusPosted:
+		st.PostedAt = &now
+	}
+	return nil
+}
+
+// CreateStockTake 创建盘点单
+func (s *stockTakeService) CreateStockTake(input StockTakeInput) (*model.StockTake, error) {
+	if strings.TrimSpace(input.Name) == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+	if strings.TrimSpace(input.CreatedBy) == "" {
+		return nil, fmt.Errorf("%w: created_by is required", ErrInvalidInput)
+	}
+
+	locations, err := normalizeCodes("location_codes", input.LocationCodes)
+	if err != nil {
+		return nil, err
+	}
+	materials, err := normalizeCodes("material_codes", input.MaterialCodes)
+	if err != nil {
+		return nil, err
+	}
+	checkers, err := normalizeCodes("checker_ids", input.CheckerIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	stockTake := &model.StockTake{
+		Name:        strings.TrimSpace(input.Name),
+		Description: input.Description,
+		Status:      model.StockTakeStatusDraft,
+		BlindCount:  input.BlindCount,
+		CreatedBy:   input.CreatedBy,
+		Locations:   make([]model.StockTakeLocation, 0, len(locations)),
+		Materials:   make([]model.StockTakeMaterial, 0, len(materials)),
+		Checkers:    make([]model.StockTakeChecker, 0, len(checkers)),
+	}
+	for _, code := range locations {
+		stockTake.Locations = append(stockTake.Locations, model.StockTakeLocation{LocationCode: code})
+	}
+	for _, code := range materials {
+		stockTake.Materials = append(stockTake.Materials, model.StockTakeMaterial{MaterialCode: code})
+	}
+	for _, id := range checkers {
+		stockTake.Checkers = append(stockTake.Checkers, model.StockTakeChecker{CheckerID: id})
+	}
+
+	if err := s.repo.Create(stockTake); err != nil {
+		s.logger.Error("Failed to create stock take", zap.String("name", stockTake.Name), zap.Error(err))
+		return nil, fmt.Errorf("failed to create stock take: %w", err)
+	}
+
+	s.logger.Info("Stock take created",
+		zap.Uint("stock_take_id", stockTake.ID),
+		zap.String("name", stockTake.Name),
+		zap.Bool("blind_count", stockTake.BlindCount),
+		zap.Int("location_count", len(locations)),
+		zap.Int("material_count", len(materials)),
+		zap.Int("checker_count", len(checkers)),
+	)
+	return stockTake, nil
+}
+
+// GetStockTake 查询盘点单
+func (s *stockTakeService) GetStockTake(id uint) (*model.StockTake, error) {
+	stockTake, err := s.repo.GetByID(id)
+	if err != nil {
+		s.logger.Error("Failed to fetch stock take", zap.Uint("stock_take_id", id), zap.Error(err))
+		return nil, fmt.Errorf("failed to fetch stock take: %w", err)
+	}
+	if stockTake == nil {
+		return nil, fmt.Errorf("%w: stock take %d", ErrNotFound, id)
+	}
+	return stockTake, nil
+}
+
+// ListStockTakes 查询盘点单列表
+func (s *stockTakeService) ListStockTakes(status string) ([]model.StockTake, error) {
+	stockTakes, err := s.repo.List(status)
+	if err != nil {
+		s.logger.Error("Failed to list stock takes", zap.String("status", status), zap.Error(err))
+		return nil, fmt.Errorf("failed to list stock takes: %w", err)
+	}
+	return stockTakes, nil
+}
+
+// StartStockTake 开始盘点
+func (s *stockTakeService) StartStockTake(id uint) (*model.StockTake, error) {
+	return s.transition(id, model.StockTakeStatusInProgress, func(tx *gorm.DB, st *model.StockTake) error {
+		if !st.BlindCount {
+			return nil
+		}
+		return s.generateCountTasks(tx, st, *st.StartedAt)
+	})
+}
+
+// CloseCounting 截止盘点
+// 与上传盘点时持有的共享锁互斥，截止后不会再有新的盘点记录写入
+func (s *stockTakeService) CloseCounting(id uint) (*model.StockTake, error) {
+	return s.transition(id, model.StockTakeStatusCountingClosed, nil)
+}
+
+// ReconcileStockTake 确认盘点差异
+func (s *stockTakeService) ReconcileStockTake(id uint) (*model.StockTake, error) {
+	return s.transition(id, model.StockTakeStatusReconciled, func(tx *gorm.DB, st *model.StockTake) error {
+		records, err := s.checkRepo.FindRecordsByStockTake(tx, st.ID)
+		if err != nil {
+			return fmt.Errorf("failed to fetch stock take records: %w", err)
+		}
+		pending := 0
+		for _, record := range records {
+			if record.ApprovalStatus == model.ApprovalStatusPending {
+				pending++
+			}
+		}
+		if pending > 0 {
+			return fmt.Errorf("%w: stock take %d has %d variances pending approval", ErrInvalidState, st.ID, pending)
+		}
+		return nil
+	})
+}
+
+// PostStockTake 过账盘点单
+// 差异以增量方式过账，保留盘点之后发生的其他库存变动；任一差异过账后库存为负时整单回滚
+// 过账后对低于补货最小水位的拣货位生成补货任务
+func (s *stockTakeService) PostStockTake(id uint, operatorID string) (*model.StockTake, error) {
+	if operatorID == "" {
+		return nil, fmt.Errorf("%w: operator_id is required", ErrInvalidInput)
+	}
+
+	var postedRecords []*model.InventoryCheckRecord
+	replenishments := 0
+	stockTake, err := s.transition(id, model.StockTakeStatusPosted, func(tx *gorm.DB, st *model.StockTake) error {
+		postedRecords = nil
+		st.PostedBy = operatorID
+
+		records, err := s.checkRepo.FindRecordsByStockTake(tx, st.ID)
+		if err != nil {
+			return fmt.Errorf("failed to fetch stock take records: %w", err)
+		}
+		for i := range records {
+			record := &records[i]
+			if record.IsProcessed {
+				continue
+			}
+			if record.ApprovalStatus != model.ApprovalStatusAutoApproved && record.ApprovalStatus != model.ApprovalStatusApproved {
+				continue
+			}
+
+			if _, err := s.ledger.apply(tx, stockTakeChange(record, operatorID)); err != nil {
+				s.logger.Error("Failed to post stock take variance",
+					zap.Uint("stock_take_id", st.ID),
+					zap.Uint("record_id", record.ID),
+					zap.String("material_code", record.MaterialCode),
+					zap.String("location_code", record.LocationCode),
+					zap.Error(err),
+				)
+				if errors.Is(err, ErrInsufficientStock) {
+					return fmt.Errorf("%w: record %d requires recount", err, record.ID)
+				}
+				return err
+			}
+
+			record.IsProcessed = true
+			if err := s.checkRepo.UpdateCheckRecord(tx, record); err != nil {
+				return fmt.Errorf("failed to update check record: %w", err)
+			}
+			postedRecords = append(postedRecords, record)
+		}
+
+		// 全部差异过账后再按最终在库数量检查拣货位的补货水位
+		replenishments = 0
+		for _, record := range postedRecords {
+			tasks, err := s.replenisher.check(tx, record.MaterialCode, record.LocationCode, model.ReplenishmentTriggerStockTake, operatorID)
+			if err != nil {
+				return err
+			}
+			replenishments += len(tasks)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Stock take posted",
+		zap.Uint("stock_take_id", id),
+		zap.String("operator_id", operatorID),
+		zap.Int("posted_records", len(postedRecords)),
+		zap.Int("replenishment_tasks", replenishments),
+	)
+	return stockTake, nil
+}
+
+// transition 在事务中锁定盘点单并流转到目标状态
+// check 在状态流转后、保存前执行，返回错误时整个操作回滚
+func (s *stockTakeService) transition(id uint, to string, check func(tx *gorm.DB, st *model.StockTake) error) (*model.StockTake, error) {
+	var stockTake *model.StockTake
+	err := s.retry.run(s.logger, "stock_take_"+to, func() error {
+		return runInTransaction(s.repo, s.logger, "stock_take_"+to, func(tx *gorm.DB) error {
+			st, err := s.repo.GetForUpdate(tx, id)
+			if err != nil {
+				return fmt.Errorf("failed to fetch stock take: %w", err)
+			}
+			if st == nil {
+				return fmt.Errorf("%w: stock take %d", ErrNotFound, id)
+			}
+			if err := advanceStockTake(st, to, time.Now()); err != nil {
+				return err
+			}
+			if check != nil {
+				if err := check(tx, st); err != nil {
+					return err
+				}
+			}
+			if err := s.repo.UpdateStatus(tx, st); err != nil {
+				return fmt.Errorf("failed to update stock take: %w", err)
+			}
+			stockTake = st
+			return nil
+		})
+	})
+	if err != nil {
+		s.logger.Warn("Stock take transition failed",
+			zap.Uint("stock_take_id", id),
+			zap.String("target_status", to),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	s.logger.Info("Stock take status changed",
+		zap.Uint("stock_take_id", id),
+		zap.String("status", to),
+	)
+	return stockTake, nil
+}
+
+// GetStockTakeSummary 汇总盘点单进度
+// 范围指定了库位时以这些库位为应盘库位；否则以范围内物料当前有库存的库位为应盘库位
+func (s *stockTakeService) GetStockTakeSummary(id uint) (*StockTakeSummary, error) {
+	stockTake, err := s.GetStockTake(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var expected []string
+	if len(stockTake.Locations) > 0 {
+		for _, l := range stockTake.Locations {
+			expected = append(expected, l.LocationCode)
+		}
+	} else {
+		materials := make([]string, 0, len(stockTake.Materials))
+		for _, m := range stockTake.Materials {
+			materials = append(materials, m.MaterialCode)
+		}
+		expected, err = s.stockRepo.ListStockedLocations(materials)
+		if err != nil {
+			s.logger.Error("Failed to list stocked locations", zap.Uint("stock_take_id", id), zap.Error(err))
+			return nil, fmt.Errorf("failed to list stocked locations: %w", err)
+		}
+	}
+
+	records, err := s.checkRepo.ListCheckRecords(repository.CheckRecordFilter{
+		StockTakeID: &stockTake.ID,
+		SortBy:      repository.SortByID,
+	})
+	if err != nil {
+		s.logger.Error("Failed to fetch stock take records", zap.Uint("stock_take_id", id), zap.Error(err))
+		return nil, fmt.Errorf("failed to fetch stock take records: %w", err)
+	}
+
+	return summarizeStockTake(stockTake, expected, records), nil
+}
+
+// summarizeStockTake 根据应盘库位与盘点记录计算汇总
+func summarizeStockTake(st *model.StockTake, expected []string, records []model.InventoryCheckRecord) *StockTakeSummary {
+	summary := &StockTakeSummary{
+		StockTakeID:        st.ID,
+		Status:             st.Status,
+		ExpectedLocations:  len(expected),
+		UncountedLocations: []string{},
+	}
+
+	counted := make(map[string]bool)
+	for _, record := range records {
+		if record.ApprovalStatus == model.ApprovalStatusRejected || record.ApprovalStatus == model.ApprovalStatusSuperseded {
+			continue
+		}
+		counted[record.LocationCode] = true
+		summary.RecordCount++
+		if record.ApprovalStatus == model.ApprovalStatusPending {
+			summary.PendingApprovalCount++
+		}
+		if record.Expired {
+			summary.ExpiredCount++
+		}
+		summary.NetVariance += record.Difference
+		if record.Difference < 0 {
+			summary.AbsoluteVariance -= record.Difference
+		} else {
+			summary.AbsoluteVariance += record.Difference
+		}
+	}
+
+	for _, location := range expected {
+		if counted[location] {
+			summary.CountedLocations++
+		} else {
+			summary.UncountedLocations = append(summary.UncountedLocations, location)
+		}
+	}
+
+	summary.CoveragePercent = 100
+	if len(expected) > 0 {
+		percent := float64(summary.CountedLocations) / float64(len(expected)) * 100
+		summary.CoveragePercent = math.Round(percent*100) / 100
+	}
+	return summary
+}
+
+// normalizeCodes 去除编码首尾空白并去重，拒绝空编码
+func normalizeCodes(field string, codes []string) ([]string, error) {
+	seen := make(map[string]bool, len(codes))
+	result := make([]string, 0, len(codes))
+	for _, code := range codes {
+		code = strings.TrimSpace(code)
+		if code == "" {
+			return nil, fmt.Errorf("%w: %s cannot contain empty values", ErrInvalidInput, field)
+		}
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+		result = append(result, code)
+	}
+	return result, nil
+}
+
+// checkStockTakeCount 校验盘点能否计入指定盘点单，复盘不做重复盘点检查
+// 必须在锁定库存行之后调用，使同一物料与库位的并发上传串行执行，重复盘点检查才可靠
+func (s *inventoryService) checkStockTakeCount(tx *gorm.DB, stockTake *model.StockTake, input InventoryCheckInput, isRecount bool) error {
+	if stockTake.Status != model.StockTakeStatusInProgress {
+		return fmt.Errorf("%w: stock take %d is %s, not accepting counts", ErrInvalidState, stockTake.ID, stockTake.Status)
+	}
+	if !stockTake.CoversLocation(input.LocationCode) {
+		return fmt.Errorf("%w: location %s is outside stock take %d scope", ErrInvalidInput, input.LocationCode, stockTake.ID)
+	}
+	if !stockTake.CoversMaterial(input.MaterialCode) {
+		return fmt.Errorf("%w: material %s is outside stock take %d scope", ErrInvalidInput, input.MaterialCode, stockTake.ID)
+	}
+	if !stockTake.IsAssigned(input.CheckerID) {
+		return fmt.Errorf("%w: checker %s is not assigned to stock take %d", ErrInvalidInput, input.CheckerID, stockTake.ID)
+	}
+	if isRecount {
+		return nil
+	}
+
+	existing, err := s.repo.CountActiveStockTakeRecords(tx, stockTake.ID, input.MaterialCode, input.LocationCode, input.LotNumber)
+	if err != nil {
+		return fmt.Errorf("failed to check existing counts: %w", err)
+	}
+	if existing > 0 {
+		return fmt.Errorf("%w: %s lot %q at %s already counted in stock take %d",
+			ErrInvalidState, input.MaterialCode, input.LotNumber, input.LocationCode, stockTake.ID)
+	}
+	return nil
+}
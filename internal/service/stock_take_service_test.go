@@ -0,0 +1,138 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+	"wms/internal/model"
+	"wms/internal/routing"
+)
+
+func TestAdvanceStockTake_FollowsLifecycle(t *testing.T) {
+	st := &model.StockTake{ID: 1, Status: model.StockTakeStatusDraft}
+	now := time.Now()
+
+	steps := []string{
+		model.StockTakeStatusInProgress,
+		model.StockTakeStatusCountingClosed,
+		model.StockTakeStatusReconciled,
+		model.StockTakeStatusPosted,
+	}
+	for _, to := range steps {
+		if err := advanceStockTake(st, to, now); err != nil {
+			t.Fatalf("Unexpected error advancing to %s: %v", to, err)
+		}
+		if st.Status != to {
+			t.Fatalf("Expected status %s, got %s", to, st.Status)
+		}
+	}
+	if st.StartedAt == nil || st.CountingClosedAt == nil || st.ReconciledAt == nil || st.PostedAt == nil {
+		t.Errorf("Expected all lifecycle timestamps to be set: %+v", st)
+	}
+}
+
+func TestAdvanceStockTake_RejectsSkippingStates(t *testing.T) {
+	st := &model.StockTake{ID: 1, Status: model.StockTakeStatusInProgress}
+
+	err := advanceStockTake(st, model.StockTakeStatusPosted, time.Now())
+	if !errors.Is(err, ErrInvalidState) {
+		t.Errorf("Expected ErrInvalidState, got %v", err)
+	}
+	if st.Status != model.StockTakeStatusInProgress {
+		t.Errorf("Status should be unchanged on failure, got %s", st.Status)
+	}
+}
+
+func TestSummarizeStockTake(t *testing.T) {
+	st := &model.StockTake{ID: 9, Status: model.StockTakeStatusCountingClosed}
+	expected := []string{"A-01", "A-02", "A-03", "A-04"}
+	records := []model.InventoryCheckRecord{
+		{LocationCode: "A-01", Difference: -3, ApprovalStatus: model.ApprovalStatusAutoApproved},
+		{LocationCode: "A-01", Difference: 2, ApprovalStatus: model.ApprovalStatusPending},
+		{LocationCode: "A-02", Difference: 5, ApprovalStatus: model.ApprovalStatusApproved, Expired: true},
+		// 驳回的盘点不计入覆盖率与差异
+		{LocationCode: "A-03", Difference: 100, ApprovalStatus: model.ApprovalStatusRejected},
+	}
+
+	summary := summarizeStockTake(st, expected, records)
+
+	if summary.CountedLocations != 2 || summary.ExpectedLocations != 4 {
+		t.Errorf("Expected 2/4 locations counted, got %d/%d", summary.CountedLocations, summary.ExpectedLocations)
+	}
+	if summary.CoveragePercent != 50 {
+		t.Errorf("Expected coverage 50%%, got %v", summary.CoveragePercent)
+	}
+	if !reflect.DeepEqual(summary.UncountedLocations, []string{"A-03", "A-04"}) {
+		t.Errorf("Unexpected uncounted locations: %v", summary.UncountedLocations)
+	}
+	if summary.RecordCount != 3 || summary.PendingApprovalCount != 1 {
+		t.Errorf("Expected 3 records with 1 pending, got %d with %d pending", summary.RecordCount, summary.PendingApprovalCount)
+	}
+	if summary.ExpiredCount != 1 {
+		t.Errorf("Expected 1 expired record, got %d", summary.ExpiredCount)
+	}
+	if summary.NetVariance != 4 || summary.AbsoluteVariance != 10 {
+		t.Errorf("Expected net 4 / absolute 10, got %d / %d", summary.NetVariance, summary.AbsoluteVariance)
+	}
+}
+
+func TestBuildCountTasks_SnapshotsStockPerLocation(t *testing.T) {
+	st := &model.StockTake{
+		ID:        4,
+		Locations: []model.StockTakeLocation{{LocationCode: "B-02"}, {LocationCode: "B-01"}, {LocationCode: "B-03"}},
+		Checkers:  []model.StockTakeChecker{{CheckerID: "c2"}, {CheckerID: "c1"}},
+	}
+	stocks := []model.Stock{
+		{MaterialCode: "MAT001", LocationCode: "B-01", Quantity: 10},
+		{MaterialCode: "MAT002", LocationCode: "B-01", Quantity: 3},
+		{MaterialCode: "MAT001", LocationCode: "B-02", Quantity: 7},
+	}
+	frozenAt := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+
+	tasks := buildCountTasks(st, stocks, frozenAt)
+
+	if len(tasks) != 3 {
+		t.Fatalf("Expected one task per scoped location, got %d", len(tasks))
+	}
+	wantLocations := []string{"B-01", "B-02", "B-03"}
+	wantAssignees := []string{"c1", "c2", "c1"}
+	wantLines := []int{2, 1, 0}
+	for i, task := range tasks {
+		if task.LocationCode != wantLocations[i] || task.AssignedTo != wantAssignees[i] || len(task.Lines) != wantLines[i] {
+			t.Errorf("Task %d: got location=%s assignee=%s lines=%d", i, task.LocationCode, task.AssignedTo, len(task.Lines))
+		}
+		if !task.FrozenAt.Equal(frozenAt) || task.Status != model.CountTaskStatusOpen {
+			t.Errorf("Task %d: unexpected freeze point or status: %v %s", i, task.FrozenAt, task.Status)
+		}
+	}
+	if tasks[0].Lines[0].FrozenQuantity != 10 || tasks[0].Lines[1].FrozenQuantity != 3 {
+		t.Errorf("Expected frozen quantities 10 and 3, got %+v", tasks[0].Lines)
+	}
+}
+
+func TestSequenceCountTasks_RoutesEachChecker(t *testing.T) {
+	locations := []model.Location{
+		{Code: "A-01", Aisle: "A", X: 0, Y: 8},
+		{Code: "A-02", Aisle: "A", X: 0, Y: 2},
+		{Code: "B-01", Aisle: "B", X: 4, Y: 8},
+		{Code: "B-02", Aisle: "B", X: 4, Y: 2},
+	}
+	route := newRouteContext(routing.StrategySShape, &model.WarehouseLayout{BackAisleY: 10}, locations, nil)
+	tasks := []model.CountTask{
+		{LocationCode: "A-01", AssignedTo: "c1"},
+		{LocationCode: "A-02", AssignedTo: "c2"},
+		{LocationCode: "B-01", AssignedTo: "c1"},
+		{LocationCode: "B-02", AssignedTo: "c2"},
+	}
+
+	sequenceCountTasks(tasks, route)
+
+	// c1：A 巷道由前向后，B 巷道由后向前；c2 同理，各自从 1 开始编号
+	want := []int{1, 1, 2, 2}
+	for i, task := range tasks {
+		if task.Sequence != want[i] {
+			t.Errorf("Task %s: expected sequence %d, got %d", task.LocationCode, want[i], task.Sequence)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"wms/internal/model"
+	"wms/internal/repository"
+	"wms/internal/routing"
+
+	"gorm.io/gorm"
+)
+
+// DefaultWarehouseLayout 返回尚未配置布局时使用的默认设置：出发点位于原点，未配置横向通道，按 S 形路线行走
+func DefaultWarehouseLayout() model.WarehouseLayout {
+	return model.WarehouseLayout{
+		ID:              1,
+		RoutingStrategy: routing.StrategySShape,
+	}
+}
+
+// RoutePlan 表示一组库位的行走顺序
+// LocationCodes 先按路线策略排列登记了布局位置的库位，再按远近顺序（Sequence）排列其余库位；
+// Distance 为从出发点经过已规划库位再返回的估算距离，Unrouted 为未参与路线规划的库位
+type RoutePlan struct {
+	Strategy      string   `json:"strategy"`
+	LocationCodes []string `json:"location_codes"`
+	Distance      float64  `json:"distance"`
+	Unrouted      []string `json:"unrouted"`
+}
+
+// taskRouter 按仓库布局为拣货与盘点任务排列行走顺序
+type taskRouter struct {
+	layoutRepo   repository.LayoutRepository
+	locationRepo repository.LocationRepository
+}
+
+// newTaskRouter 创建任务路线规划器
+func newTaskRouter(layoutRepo repository.LayoutRepository, locationRepo repository.LocationRepository) *taskRouter {
+	return &taskRouter{
+		layoutRepo:   layoutRepo,
+		locationRepo: locationRepo,
+	}
+}
+
+// layout 在事务中读取布局设置，未配置时返回默认设置
+func (r *taskRouter) layout(tx *gorm.DB) (*model.WarehouseLayout, error) {
+	layout, err := r.layoutRepo.GetLayout(tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch warehouse layout: %w", err)
+	}
+	if layout == nil {
+		defaults := DefaultWarehouseLayout()
+		layout = &defaults
+	}
+	return layout, nil
+}
+
+// load 在事务中读取布局、库位与行走图，strategy 为空时使用布局的默认策略
+func (r *taskRouter) load(tx *gorm.DB, strategy string) (*routeContext, error) {
+	layout, err := r.layout(tx)
+	if err != nil {
+		return nil, err
+	}
+	if strategy == "" {
+		strategy = layout.RoutingStrategy
+	}
+	if !routing.ValidStrategy(strategy) {
+		return nil, fmt.Errorf("%w: unsupported routing strategy %q", ErrInvalidInput, strategy)
+	}
+
+	locations, err := r.locationRepo.ListAll(tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch locations: %w", err)
+	}
+	edges, err := r.layoutRepo.ListEdges(tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch layout edges: %w", err)
+	}
+	return newRouteContext(strategy, layout, locations, edges), nil
+}
+
+// routeContext 保存一次路线规划所需的布局数据，可对多组库位重复规划
+type routeContext struct {
+	strategy  string
+	layout    routing.Layout
+	locations map[string]model.Location
+}
+
+// newRouteContext 根据布局设置、库位与行走图构造路线规划上下文
+func newRouteContext(strategy string, layout *model.WarehouseLayout, locations []model.Location, edges []model.LayoutEdge) *routeContext {
+	ctx := &routeContext{
+		strategy: strategy,
+		layout: routing.Layout{
+			Depot:  routing.Point{X: layout.DepotX, Y: layout.DepotY},
+			FrontY: layout.FrontAisleY,
+			BackY:  layout.BackAisleY,
+		},
+		locations: make(map[string]model.Location, len(locations)),
+	}
+	for _, location := range locations {
+		ctx.locations[location.Code] = location
+	}
+	if len(edges) > 0 {
+		graph := routing.NewGraph()
+		for _, edge := range edges {
+			graph.AddEdge(edge.FromNode, edge.ToNode, edge.Distance)
+		}
+		ctx.layout.Graph = graph
+	}
+	return ctx
+}
+
+// route 排列一组库位的行走顺序，重复的库位只保留一次
+func (c *routeContext) route(codes []string) RoutePlan {
+	plan := RoutePlan{Strategy: c.strategy, LocationCodes: make([]string, 0, len(codes)), Unrouted: []string{}}
+	seen := make(map[string]bool, len(codes))
+	stops := make([]routing.Stop, 0, len(codes))
+	for _, code := range codes {
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+		location, ok := c.locations[code]
+		if !ok || !location.HasLayout() {
+			plan.Unrouted = append(plan.Unrouted, code)
+			continue
+		}
+		stops = append(stops, routing.Stop{ID: code, Aisle: location.Aisle, Point: routing.Point{X: location.X, Y: location.Y}})
+	}
+	// 按编码排序后再规划，使结果与输入顺序无关
+	sort.Slice(stops, func(i, j int) bool { return stops[i].ID < stops[j].ID })
+
+	// 全部库位都未登记布局位置时无需规划，直接按远近顺序排列
+	if len(stops) > 0 {
+		routed, err := routing.Route(c.strategy, c.layout, stops)
+		if err != nil {
+			// 策略已在 load 中校验，这里只可能是调用方构造了无效的上下文
+			routed = routing.Plan{Stops: stops, Distance: c.layout.TourLength(stops)}
+		}
+		for _, stop := range routed.Stops {
+			plan.LocationCodes = append(plan.LocationCodes, stop.ID)
+		}
+		plan.Distance = routed.Distance
+	}
+
+	sort.SliceStable(plan.Unrouted, func(i, j int) bool {
+		return c.sequenceBefore(plan.Unrouted[i], plan.Unrouted[j])
+	})
+	plan.LocationCodes = append(plan.LocationCodes, plan.Unrouted...)
+	return plan
+}
+
+// sequenceBefore 判断未登记布局位置的库位 a 是否应排在 b 之前
+// 已登记库位按远近顺序（Sequence）排列，未登记的库位排在最后；同序按编码
+func (c *routeContext) sequenceBefore(a, b string) bool {
+	la, okA := c.locations[a]
+	lb, okB := c.locations[b]
+	if okA != okB {
+		return okA
+	}
+	if okA && la.Sequence != lb.Sequence {
+		return la.Sequence < lb.Sequence
+	}
+	return a < b
+}
+
+// positions 返回库位在路线中的序号（从 1 开始）
+func (p RoutePlan) positions() map[string]int {
+	index := make(map[string]int, len(p.LocationCodes))
+	for i, code := range p.LocationCodes {
+		index[code] = i + 1
+	}
+	return index
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"fmt"
+	"wms/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// transactor 表示可开启、提交与回滚事务的仓储
+type transactor interface {
+	BeginTransaction() *gorm.DB
+	CommitTransaction(tx *gorm.DB) error
+	RollbackTransaction(tx *gorm.DB) error
+}
+
+// runInTransaction 在新事务中执行 fn
+// fn 返回错误或发生 panic 时回滚事务，否则提交；通常与 RetryPolicy.run 搭配使用
+func runInTransaction(t transactor, log *logger.Logger, operation string, fn func(tx *gorm.DB) error) (err error) {
+	tx := t.BeginTransaction()
+	if tx.Error != nil {
+		log.Error("Failed to begin transaction", zap.String("operation", operation), zap.Error(tx.Error))
+		return fmt.Errorf("failed to begin transaction: %w", tx.Error)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			t.RollbackTransaction(tx)
+			log.Error("Panic during transaction, rolled back",
+				zap.String("operation", operation),
+				zap.Any("panic", r),
+			)
+			err = fmt.Errorf("panic during %s: %v", operation, r)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		t.RollbackTransaction(tx)
+		return err
+	}
+
+	if err := t.CommitTransaction(tx); err != nil {
+		t.RollbackTransaction(tx)
+		log.Error("Failed to commit transaction", zap.String("operation", operation), zap.Error(err))
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"wms/internal/model"
+	"wms/internal/repository"
+	"wms/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// TransferInput 表示一次移库的输入
+type TransferInput struct {
+	OperatorID string
+	Note       string
+	Lines      []TransferLineInput
+}
+
+// TransferLineInput 表示移库的一行，LotNumber 为移动的批次，未启用批次管理的物料为空；
+// SerialNumbers 为移动的序列号，启用序列号管理的物料必须给出且与数量一致
+type TransferLineInput struct {
+	MaterialCode  string
+	LotNumber     string
+	FromLocation  string
+	ToLocation    string
+	Quantity      int
+	SerialNumbers []string
+}
+
+// TransferService 定义库位间移库的业务接口
+type TransferService interface {
+	// CreateTransfer 在同一事务中按行扣减来源库位库存并增加目标库位库存
+	// 任一行使来源库存为负时返回 ErrInsufficientStock，整单回滚
+	CreateTransfer(input TransferInput) (*model.StockTransfer, error)
+
+	// GetTransfer 查询移库单，不存在时返回 ErrNotFound
+	GetTransfer(id uint) (*model.StockTransfer, error)
+}
+
+// transferService 是 TransferService 的具体实现
+type transferService struct {
+	repo   repository.TransferRepository
+	ledger *stockLedger
+	retry  RetryPolicy
+	logger *logger.Logger
+}
+
+// NewTransferService 创建新的 TransferService 实例
+func NewTransferService(repo repository.TransferRepository, stockRepo repository.StockRepository, retry RetryPolicy, log *logger.Logger) TransferService {
+	return &transferService{
+		repo:   repo,
+		ledger: newStockLedger(stockRepo),
+		retry:  retry,
+		logger: log,
+	}
+}
+
+// CreateTransfer 过账移库单
+// 先按 (物料, 库位, 批次) 顺序锁定涉及的全部库存行，再按请求顺序逐行过账，
+// 因此同一请求中先移入再移出同一库位的行可以成功，而并发移库不会互相等待
+func (s *transferService) CreateTransfer(input TransferInput) (*model.StockTransfer, error) {
+	if strings.TrimSpace(input.OperatorID) == "" {
+		return nil, fmt.Errorf("%w: operator_id is required", ErrInvalidInput)
+	}
+	if len(input.Lines) == 0 {
+		return nil, fmt.Errorf("%w: at least one line is required", ErrInvalidInput)
+	}
+	lines := make([]model.StockTransferLine, 0, len(input.Lines))
+	for i, line := range input.Lines {
+		if line.MaterialCode == "" || line.FromLocation == "" || line.ToLocation == "" {
+			return nil, fmt.Errorf("%w: line %d: material_code, from_location and to_location are required", ErrInvalidInput, i+1)
+		}
+		if line.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: line %d: quantity must be positive", ErrInvalidInput, i+1)
+		}
+		if line.FromLocation == line.ToLocation {
+			return nil, fmt.Errorf("%w: line %d: from_location and to_location are both %s", ErrInvalidInput, i+1, line.FromLocation)
+		}
+		lines = append(lines, model.StockTransferLine{
+			LineNo:       i + 1,
+			MaterialCode: line.MaterialCode,
+			LotNumber:    strings.TrimSpace(line.LotNumber),
+			FromLocation: line.FromLocation,
+			ToLocation:   line.ToLocation,
+			Quantity:     line.Quantity,
+		})
+	}
+
+	var transfer *model.StockTransfer
+	err := s.retry.run(s.logger, "stock_transfer", func() error {
+		return runInTransaction(s.repo, s.logger, "stock_transfer", func(tx *gorm.DB) error {
+			for _, key := range transferLockOrder(lines) {
+				if _, err := s.ledger.lock(tx, key.materialCode, key.locationCode, key.lotNumber); err != nil {
+					return err
+				}
+			}
+
+			transfer = &model.StockTransfer{
+				OperatorID: input.OperatorID,
+				Note:       input.Note,
+				Lines:      append([]model.StockTransferLine(nil), lines...),
+			}
+			if err := s.repo.Create(tx, transfer); err != nil {
+				return fmt.Errorf("failed to create stock transfer: %w", err)
+			}
+
+			referenceID := strconv.FormatUint(uint64(transfer.ID), 10)
+			for _, line := range transfer.Lines {
+				if err := s.ledger.move(tx, StockMove{
+					MaterialCode:   line.MaterialCode,
+					LotNumber:      line.LotNumber,
+					FromLocation:   line.FromLocation,
+					ToLocation:     line.ToLocation,
+					Quantity:       line.Quantity,
+					MovementType:   model.MovementTypeTransfer,
+					ReferenceType:  model.ReferenceTypeStockTransfer,
+					ReferenceID:    referenceID,
+					OperatorID:     input.OperatorID,
+					SerialNumbers:  input.Lines[line.LineNo-1].SerialNumbers,
+					RequireSerials: true,
+				}); err != nil {
+					return fmt.Errorf("line %d: %w", line.LineNo, err)
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		s.logger.Warn("Stock transfer failed",
+			zap.String("operator_id", input.OperatorID),
+			zap.Int("line_count", len(lines)),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	s.logger.Info("Stock transfer posted",
+		zap.Uint("transfer_id", transfer.ID),
+		zap.String("operator_id", input.OperatorID),
+		zap.Int("line_count", len(transfer.Lines)),
+	)
+	return transfer, nil
+}
+
+// GetTransfer 查询移库单
+func (s *transferService) GetTransfer(id uint) (*model.StockTransfer, error) {
+	transfer, err := s.repo.GetByID(id)
+	if err != nil {
+		s.logger.Error("Failed to fetch stock transfer", zap.Uint("transfer_id", id), zap.Error(err))
+		return nil, fmt.Errorf("failed to fetch stock transfer: %w", err)
+	}
+	if transfer == nil {
+		return nil, fmt.Errorf("%w: stock transfer %d", ErrNotFound, id)
+	}
+	return transfer, nil
+}
+
+// transferLockOrder 返回移库涉及的全部库存行，去重后按物料、库位、批次排序
+func transferLockOrder(lines []model.StockTransferLine) []stockKey {
+	seen := make(map[stockKey]bool, len(lines)*2)
+	keys := make([]stockKey, 0, len(lines)*2)
+	for _, line := range lines {
+		for _, location := range []string{line.FromLocation, line.ToLocation} {
+			key := stockKey{materialCode: line.MaterialCode, locationCode: location, lotNumber: line.LotNumber}
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].materialCode != keys[j].materialCode {
+			return keys[i].materialCode < keys[j].materialCode
+		}
+		if keys[i].locationCode != keys[j].locationCode {
+			return keys[i].locationCode < keys[j].locationCode
+		}
+		return keys[i].lotNumber < keys[j].lotNumber
+	})
+	return keys
+}
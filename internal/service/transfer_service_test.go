@@ -0,0 +1,163 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"wms/internal/model"
+	"wms/internal/repository"
+	"wms/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+func TestTransferLockOrder(t *testing.T) {
+	keys := transferLockOrder([]model.StockTransferLine{
+		{MaterialCode: "MAT-B", FromLocation: "B-02", ToLocation: "A-01"},
+		{MaterialCode: "MAT-A", FromLocation: "C-01", ToLocation: "A-01"},
+		{MaterialCode: "MAT-B", FromLocation: "A-01", ToLocation: "B-02"},
+		{MaterialCode: "MAT-A", LotNumber: "L2", FromLocation: "C-01", ToLocation: "A-01"},
+	})
+	want := []stockKey{
+		{"MAT-A", "A-01", ""},
+		{"MAT-A", "A-01", "L2"},
+		{"MAT-A", "C-01", ""},
+		{"MAT-A", "C-01", "L2"},
+		{"MAT-B", "A-01", ""},
+		{"MAT-B", "B-02", ""},
+	}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("Expected lock order %v, got %v", want, keys)
+	}
+}
+
+func TestCreateTransfer_RejectsInvalidLines(t *testing.T) {
+	svc := &transferService{}
+	cases := []TransferInput{
+		{Lines: []TransferLineInput{{MaterialCode: "MAT-1", FromLocation: "A", ToLocation: "B", Quantity: 1}}},
+		{OperatorID: "op1"},
+		{OperatorID: "op1", Lines: []TransferLineInput{{MaterialCode: "MAT-1", FromLocation: "A", ToLocation: "A", Quantity: 1}}},
+		{OperatorID: "op1", Lines: []TransferLineInput{{MaterialCode: "MAT-1", FromLocation: "A", ToLocation: "B", Quantity: 0}}},
+	}
+	for i, input := range cases {
+		if _, err := svc.CreateTransfer(input); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("Case %d: expected ErrInvalidInput, got %v", i, err)
+		}
+	}
+}
+
+// memoryTransferRepository 是基于内存的 TransferRepository 测试替身
+// 开启事务时保存库存与流水的快照，回滚时恢复，用于验证整单回滚
+type memoryTransferRepository struct {
+	repository.TransferRepository
+	stocks    *memoryStockRepository
+	transfers []model.StockTransfer
+	snapshot  map[string]model.Stock
+	movements int
+	saved     int
+}
+
+func (r *memoryTransferRepository) BeginTransaction() *gorm.DB {
+	r.snapshot = make(map[string]model.Stock, len(r.stocks.stocks))
+	for key, stock := range r.stocks.stocks {
+		r.snapshot[key] = *stock
+	}
+	r.movements, r.saved = len(r.stocks.movements), len(r.transfers)
+	return &gorm.DB{}
+}
+
+func (r *memoryTransferRepository) CommitTransaction(tx *gorm.DB) error {
+	return nil
+}
+
+func (r *memoryTransferRepository) RollbackTransaction(tx *gorm.DB) error {
+	r.stocks.stocks = make(map[string]*model.Stock, len(r.snapshot))
+	for key, stock := range r.snapshot {
+		copied := stock
+		r.stocks.stocks[key] = &copied
+	}
+	r.stocks.movements = r.stocks.movements[:r.movements]
+	r.transfers = r.transfers[:r.saved]
+	return nil
+}
+
+func (r *memoryTransferRepository) Create(tx *gorm.DB, transfer *model.StockTransfer) error {
+	transfer.ID = uint(len(r.transfers) + 1)
+	r.transfers = append(r.transfers, *transfer)
+	return nil
+}
+
+func newMemoryTransferService(stocks *memoryStockRepository) (*transferService, *memoryTransferRepository) {
+	log, _ := logger.NewLogger("test")
+	repo := &memoryTransferRepository{stocks: stocks}
+	return &transferService{repo: repo, ledger: newStockLedger(stocks), retry: RetryPolicy{MaxAttempts: 1}, logger: log}, repo
+}
+
+func TestCreateTransfer_RejectsInsufficientOrReservedStock(t *testing.T) {
+	stocks := newMemoryStockRepository()
+	stocks.SaveStock(nil, &model.Stock{MaterialCode: "MAT-1", LocationCode: "A-01", Quantity: 5})
+	stocks.SaveStock(nil, &model.Stock{MaterialCode: "MAT-2", LocationCode: "A-01", Quantity: 10, ReservedQuantity: 8})
+	svc, repo := newMemoryTransferService(stocks)
+
+	cases := []TransferLineInput{
+		// 来源库存只有 5 件
+		{MaterialCode: "MAT-1", FromLocation: "A-01", ToLocation: "B-01", Quantity: 6},
+		// 10 件中 8 件已为出库单据预留，只有 2 件可移
+		{MaterialCode: "MAT-2", FromLocation: "A-01", ToLocation: "B-01", Quantity: 3},
+	}
+	for _, line := range cases {
+		_, err := svc.CreateTransfer(TransferInput{OperatorID: "op1", Lines: []TransferLineInput{line}})
+		if !errors.Is(err, ErrInsufficientStock) {
+			t.Errorf("%s: expected ErrInsufficientStock, got %v", line.MaterialCode, err)
+		}
+	}
+
+	if got := stocks.stocks["MAT-1@A-01"]; got.Quantity != 5 {
+		t.Errorf("Expected MAT-1 source unchanged at 5, got %d", got.Quantity)
+	}
+	if got := stocks.stocks["MAT-2@A-01"]; got.Quantity != 10 || got.ReservedQuantity != 8 {
+		t.Errorf("Expected MAT-2 source unchanged at 10/8, got %d/%d", got.Quantity, got.ReservedQuantity)
+	}
+	if len(stocks.movements) != 0 || len(repo.transfers) != 0 {
+		t.Errorf("Expected no movements or transfers, got %d/%d", len(stocks.movements), len(repo.transfers))
+	}
+}
+
+func TestCreateTransfer_RollsBackWholeTransferOnLineFailure(t *testing.T) {
+	stocks := newMemoryStockRepository()
+	stocks.SaveStock(nil, &model.Stock{MaterialCode: "MAT-1", LocationCode: "A-01", Quantity: 10})
+	stocks.SaveStock(nil, &model.Stock{MaterialCode: "MAT-2", LocationCode: "A-02", Quantity: 3})
+	svc, repo := newMemoryTransferService(stocks)
+
+	// 第 1 行可以过账，第 2 行来源库存不足，整单不生效
+	_, err := svc.CreateTransfer(TransferInput{OperatorID: "op1", Lines: []TransferLineInput{
+		{MaterialCode: "MAT-1", FromLocation: "A-01", ToLocation: "B-01", Quantity: 4},
+		{MaterialCode: "MAT-2", FromLocation: "A-02", ToLocation: "B-02", Quantity: 5},
+	}})
+	if !errors.Is(err, ErrInsufficientStock) || !strings.HasPrefix(err.Error(), "line 2:") {
+		t.Fatalf("Expected ErrInsufficientStock on line 2, got %v", err)
+	}
+
+	if got := stocks.stocks["MAT-1@A-01"]; got.Quantity != 10 {
+		t.Errorf("Expected line 1 source rolled back to 10, got %d", got.Quantity)
+	}
+	if got := stocks.stocks["MAT-1@B-01"]; got != nil && got.Quantity != 0 {
+		t.Errorf("Expected line 1 target rolled back, got %d", got.Quantity)
+	}
+	if len(stocks.movements) != 0 || len(repo.transfers) != 0 {
+		t.Errorf("Expected no movements or transfers after rollback, got %d/%d", len(stocks.movements), len(repo.transfers))
+	}
+
+	// 同样的移库在库存足够时整单过账
+	transfer, err := svc.CreateTransfer(TransferInput{OperatorID: "op1", Lines: []TransferLineInput{
+		{MaterialCode: "MAT-1", FromLocation: "A-01", ToLocation: "B-01", Quantity: 4},
+		{MaterialCode: "MAT-2", FromLocation: "A-02", ToLocation: "B-02", Quantity: 3},
+	}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if transfer.ID == 0 || stocks.stocks["MAT-1@B-01"].Quantity != 4 || stocks.stocks["MAT-2@A-02"].Quantity != 0 || len(stocks.movements) != 4 {
+		t.Errorf("Expected both lines posted with 4 movements, got %d movements", len(stocks.movements))
+	}
+}
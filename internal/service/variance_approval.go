@@ -0,0 +1,172 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"wms/internal/model"
+	"wms/internal/repository"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ListPendingApprovals 返回主管待审批的盘点差异队列
+func (s *inventoryService) ListPendingApprovals() ([]model.InventoryCheckRecord, error) {
+	records, err := s.repo.ListCheckRecords(repository.CheckRecordFilter{
+		ApprovalStatus: model.ApprovalStatusPending,
+		SortBy:         repository.SortByCheckTime,
+	})
+	if err != nil {
+		s.logger.Error("Failed to fetch pending approvals", zap.Error(err))
+		return nil, fmt.Errorf("failed to fetch pending approvals: %w", err)
+	}
+	return records, nil
+}
+
+// ApproveVariance 批准盘点差异
+// 差异以增量方式过账到当前库存（而非直接覆盖为实盘数量），
+// 从而保留盘点之后至审批之前发生的其他库存变动；
+// 属于盘点单的盘点只标记为已批准，在盘点单过账时才调整库存
+func (s *inventoryService) ApproveVariance(recordID uint, input ApprovalInput) (*model.InventoryCheckRecord, error) {
+	return s.decideVariance(recordID, input, true)
+}
+
+// RejectVariance 驳回盘点差异，库存保持不变
+func (s *inventoryService) RejectVariance(recordID uint, input ApprovalInput) (*model.InventoryCheckRecord, error) {
+	return s.decideVariance(recordID, input, false)
+}
+
+// decideVariance 完成审批或驳回，事务冲突时按重试策略重试
+func (s *inventoryService) decideVariance(recordID uint, input ApprovalInput, approve bool) (*model.InventoryCheckRecord, error) {
+	if input.ApproverID == "" {
+		return nil, fmt.Errorf("%w: approver_id is required", ErrInvalidInput)
+	}
+
+	var record *model.InventoryCheckRecord
+	err := s.options.Retry.run(s.logger, "variance_decision", func() error {
+		var err error
+		record, err = s.decideVarianceOnce(recordID, input, approve)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Inventory variance decided",
+		zap.Uint("record_id", recordID),
+		zap.String("approver_id", input.ApproverID),
+		zap.String("approval_status", record.ApprovalStatus),
+		zap.Int("difference", record.Difference),
+	)
+
+	return record, nil
+}
+
+// decideVarianceOnce 在独立事务中完成一次审批或驳回
+func (s *inventoryService) decideVarianceOnce(recordID uint, input ApprovalInput, approve bool) (record *model.InventoryCheckRecord, err error) {
+
+	tx := s.repo.BeginTransaction()
+	if tx.Error != nil {
+		s.logger.Error("Failed to begin transaction", zap.Uint("record_id", recordID), zap.Error(tx.Error))
+		return nil, fmt.Errorf("failed to begin transaction: %w", tx.Error)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			s.repo.RollbackTransaction(tx)
+			s.logger.Error("Panic during variance approval, transaction rolled back",
+				zap.Uint("record_id", recordID),
+				zap.Any("panic", r),
+			)
+			record, err = nil, fmt.Errorf("panic during variance approval: %v", r)
+		}
+	}()
+
+	// 先锁定记录上未完成的复盘任务再锁定记录，与复盘上传的加锁顺序一致
+	recountTask, err := s.repo.FindOpenRecountTaskForUpdate(tx, recordID)
+	if err != nil {
+		s.repo.RollbackTransaction(tx)
+		s.logger.Error("Failed to fetch recount task", zap.Uint("record_id", recordID), zap.Error(err))
+		return nil, fmt.Errorf("failed to fetch recount task: %w", err)
+	}
+
+	record, err = s.repo.GetCheckRecordForUpdate(tx, recordID)
+	if err != nil {
+		s.repo.RollbackTransaction(tx)
+		s.logger.Error("Failed to fetch check record", zap.Uint("record_id", recordID), zap.Error(err))
+		return nil, fmt.Errorf("failed to fetch check record: %w", err)
+	}
+	if record == nil {
+		s.repo.RollbackTransaction(tx)
+		return nil, fmt.Errorf("%w: check record %d", ErrNotFound, recordID)
+	}
+	if record.ApprovalStatus != model.ApprovalStatusPending {
+		s.repo.RollbackTransaction(tx)
+		return nil, fmt.Errorf("%w: check record %d is %s", ErrInvalidState, recordID, record.ApprovalStatus)
+	}
+	if record.CheckerID == input.ApproverID {
+		s.repo.RollbackTransaction(tx)
+		return nil, fmt.Errorf("%w: checker cannot approve own count", ErrInvalidInput)
+	}
+
+	if approve {
+		if record.StockTakeID == nil {
+			if err := s.postVarianceTx(tx, record, input.ApproverID); err != nil {
+				s.repo.RollbackTransaction(tx)
+				return nil, err
+			}
+			record.IsProcessed = true
+		}
+		record.ApprovalStatus = model.ApprovalStatusApproved
+	} else {
+		record.ApprovalStatus = model.ApprovalStatusRejected
+		record.IsProcessed = true
+	}
+
+	now := time.Now()
+	record.ApprovedBy = input.ApproverID
+	record.ApprovedAt = &now
+	record.ApprovalComment = input.Comment
+
+	if err := s.repo.UpdateCheckRecord(tx, record); err != nil {
+		s.repo.RollbackTransaction(tx)
+		s.logger.Error("Failed to update check record", zap.Uint("record_id", recordID), zap.Error(err))
+		return nil, fmt.Errorf("failed to update check record: %w", err)
+	}
+
+	// 主管直接审批或驳回时不再需要复盘
+	if recountTask != nil {
+		if err := s.cancelRecountTask(tx, recountTask); err != nil {
+			s.repo.RollbackTransaction(tx)
+			s.logger.Error("Failed to cancel recount task", zap.Uint("record_id", recordID), zap.Error(err))
+			return nil, err
+		}
+	}
+
+	if err := s.repo.CommitTransaction(tx); err != nil {
+		s.repo.RollbackTransaction(tx)
+		s.logger.Error("Failed to commit transaction", zap.Uint("record_id", recordID), zap.Error(err))
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return record, nil
+}
+
+// postVarianceTx 在事务中将盘点差异增量过账至库存，过账后检查拣货位的补货水位
+func (s *inventoryService) postVarianceTx(tx *gorm.DB, record *model.InventoryCheckRecord, approverID string) error {
+	// 增量过账；结果为负说明盘点后已有出库，需重新盘点
+	if _, err := s.ledger.apply(tx, stockTakeChange(record, approverID)); err != nil {
+		s.logger.Error("Failed to post inventory variance",
+			zap.Uint("record_id", record.ID),
+			zap.String("material_code", record.MaterialCode),
+			zap.String("location_code", record.LocationCode),
+			zap.Error(err),
+		)
+		if errors.Is(err, ErrInsufficientStock) {
+			return fmt.Errorf("%w: recount required", err)
+		}
+		return err
+	}
+	return s.replenishAfterCount(tx, record, approverID)
+}
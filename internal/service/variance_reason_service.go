@@ -0,0 +1,157 @@
+package service
+
+import (
+	"fmt"
+	"wms/internal/model"
+	"wms/internal/repository"
+	"wms/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// defaultVarianceReasons 是首次启动时预置的差异原因代码
+var defaultVarianceReasons = []model.VarianceReason{
+	{Code: "DAMAGED", Description: "货物损坏", PostingCategory: model.PostingCategoryDamage, Direction: model.ReasonDirectionLoss, Active: true},
+	{Code: "THEFT", Description: "丢失或被盗", PostingCategory: model.PostingCategoryShrinkage, Direction: model.ReasonDirectionLoss, Active: true},
+	{Code: "MIS_PICK", Description: "错拣或错放库位", PostingCategory: model.PostingCategoryProcessError, Direction: model.ReasonDirectionAny, Active: true},
+	{Code: "UOM_ERROR", Description: "计量单位错误", PostingCategory: model.PostingCategoryProcessError, Direction: model.ReasonDirectionAny, Active: true},
+	{Code: "FOUND", Description: "找回库存", PostingCategory: model.PostingCategoryFoundStock, Direction: model.ReasonDirectionGain, Active: true},
+	{Code: "UNKNOWN", Description: "原因不明", PostingCategory: model.PostingCategoryShrinkage, Direction: model.ReasonDirectionAny, Active: true},
+}
+
+// VarianceReasonInput 表示新增或更新差异原因代码的输入
+// Active 为空时视为启用
+type VarianceReasonInput struct {
+	Code            string
+	Description     string
+	PostingCategory string
+	Direction       string
+	Active          *bool
+}
+
+// VarianceReasonService 定义差异原因代码的业务接口
+type VarianceReasonService interface {
+	// ListReasons 查询原因代码，activeOnly 为 true 时只返回启用的原因
+	ListReasons(activeOnly bool) ([]model.VarianceReason, error)
+
+	// UpsertReason 按代码新增或更新原因
+	UpsertReason(input VarianceReasonInput) (*model.VarianceReason, error)
+
+	// SeedDefaultReasons 预置默认原因代码，已存在的代码不会被覆盖
+	SeedDefaultReasons() error
+}
+
+// varianceReasonService 是 VarianceReasonService 的具体实现
+type varianceReasonService struct {
+	repo   repository.VarianceReasonRepository
+	logger *logger.Logger
+}
+
+// NewVarianceReasonService 创建新的 VarianceReasonService 实例
+func NewVarianceReasonService(repo repository.VarianceReasonRepository, log *logger.Logger) VarianceReasonService {
+	return &varianceReasonService{
+		repo:   repo,
+		logger: log,
+	}
+}
+
+// ListReasons 查询原因代码
+func (s *varianceReasonService) ListReasons(activeOnly bool) ([]model.VarianceReason, error) {
+	reasons, err := s.repo.List(activeOnly)
+	if err != nil {
+		s.logger.Error("Failed to list variance reasons", zap.Error(err))
+		return nil, fmt.Errorf("failed to list reasons: %w", err)
+	}
+	return reasons, nil
+}
+
+// UpsertReason 按代码新增或更新原因
+func (s *varianceReasonService) UpsertReason(input VarianceReasonInput) (*model.VarianceReason, error) {
+	if input.Code == "" {
+		return nil, fmt.Errorf("%w: code is required", ErrInvalidInput)
+	}
+	switch input.PostingCategory {
+	case model.PostingCategoryShrinkage, model.PostingCategoryDamage,
+		model.PostingCategoryProcessError, model.PostingCategoryFoundStock:
+	default:
+		return nil, fmt.Errorf("%w: unsupported posting_category %q", ErrInvalidInput, input.PostingCategory)
+	}
+	direction := input.Direction
+	switch direction {
+	case "":
+		direction = model.ReasonDirectionAny
+	case model.ReasonDirectionAny, model.ReasonDirectionGain, model.ReasonDirectionLoss:
+	default:
+		return nil, fmt.Errorf("%w: unsupported direction %q", ErrInvalidInput, input.Direction)
+	}
+
+	reason := &model.VarianceReason{
+		Code:            input.Code,
+		Description:     input.Description,
+		PostingCategory: input.PostingCategory,
+		Direction:       direction,
+		Active:          input.Active == nil || *input.Active,
+	}
+	if err := s.repo.Upsert(reason); err != nil {
+		s.logger.Error("Failed to upsert variance reason", zap.String("code", input.Code), zap.Error(err))
+		return nil, fmt.Errorf("failed to save reason: %w", err)
+	}
+
+	s.logger.Info("Variance reason saved",
+		zap.String("code", reason.Code),
+		zap.String("posting_category", reason.PostingCategory),
+		zap.Bool("active", reason.Active),
+	)
+	return reason, nil
+}
+
+// SeedDefaultReasons 预置默认原因代码
+func (s *varianceReasonService) SeedDefaultReasons() error {
+	reasons := append([]model.VarianceReason(nil), defaultVarianceReasons...)
+	if err := s.repo.CreateMissing(reasons); err != nil {
+		return fmt.Errorf("failed to seed variance reasons: %w", err)
+	}
+	return nil
+}
+
+// resolveVarianceReason 在事务中校验盘点给出的差异原因并返回原因代码
+// 未给出原因时返回 nil；原因须存在、已启用且适用于差异方向；
+// 配置了 ReasonRequiredAbove 时，绝对差异超过该阈值的盘点必须给出原因
+func (s *inventoryService) resolveVarianceReason(tx *gorm.DB, code string, difference int) (*model.VarianceReason, error) {
+	var reason *model.VarianceReason
+	if code != "" {
+		var err error
+		reason, err = s.reasonRepo.GetByCode(tx, code)
+		if err != nil {
+			s.logger.Error("Failed to fetch variance reason", zap.String("reason_code", code), zap.Error(err))
+			return nil, fmt.Errorf("failed to fetch reason: %w", err)
+		}
+	}
+	if err := checkVarianceReason(code, reason, difference, s.options.ReasonRequiredAbove); err != nil {
+		return nil, err
+	}
+	return reason, nil
+}
+
+// checkVarianceReason 校验差异原因，reason 为按 code 查到的原因（不存在时为 nil）
+func checkVarianceReason(code string, reason *model.VarianceReason, difference int, requiredAbove *int) error {
+	if code == "" {
+		absDiff := difference
+		if absDiff < 0 {
+			absDiff = -absDiff
+		}
+		if requiredAbove != nil && absDiff > *requiredAbove {
+			return fmt.Errorf("%w: reason_code is required for variances above %d", ErrInvalidInput, *requiredAbove)
+		}
+		return nil
+	}
+	if reason == nil || !reason.Active {
+		return fmt.Errorf("%w: unknown or inactive reason_code %q", ErrInvalidInput, code)
+	}
+	if !reason.Allows(difference) {
+		return fmt.Errorf("%w: reason_code %q only applies to %s variances, got difference %d",
+			ErrInvalidInput, code, reason.Direction, difference)
+	}
+	return nil
+}
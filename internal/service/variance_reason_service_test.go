@@ -0,0 +1,58 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"wms/internal/model"
+)
+
+func TestCheckVarianceReason(t *testing.T) {
+	threshold := 5
+	damaged := &model.VarianceReason{Code: "DAMAGED", Direction: model.ReasonDirectionLoss, Active: true}
+	retired := &model.VarianceReason{Code: "OLD", Direction: model.ReasonDirectionAny, Active: false}
+
+	cases := []struct {
+		name       string
+		code       string
+		reason     *model.VarianceReason
+		difference int
+		threshold  *int
+		wantErr    bool
+	}{
+		{"optional without threshold", "", nil, -50, nil, false},
+		{"within threshold", "", nil, -5, &threshold, false},
+		{"above threshold requires reason", "", nil, 6, &threshold, true},
+		{"unknown code", "NOPE", nil, -1, nil, true},
+		{"inactive code", "OLD", retired, -1, nil, true},
+		{"direction matches", "DAMAGED", damaged, -8, &threshold, false},
+		{"direction mismatch", "DAMAGED", damaged, 3, nil, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkVarianceReason(tc.code, tc.reason, tc.difference, tc.threshold)
+			if tc.wantErr {
+				if !errors.Is(err, ErrInvalidInput) {
+					t.Errorf("Expected ErrInvalidInput, got %v", err)
+				}
+			} else if err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestNormalizeVarianceGroupBy(t *testing.T) {
+	got, err := normalizeVarianceGroupBy(nil)
+	if err != nil || len(got) != 1 || got[0] != "reason_code" {
+		t.Errorf("Expected default reason_code grouping, got %v (%v)", got, err)
+	}
+
+	got, err = normalizeVarianceGroupBy([]string{" material_code", "location_code", "material_code"})
+	if err != nil || len(got) != 2 || got[0] != "material_code" || got[1] != "location_code" {
+		t.Errorf("Expected trimmed, de-duplicated fields, got %v (%v)", got, err)
+	}
+
+	if _, err := normalizeVarianceGroupBy([]string{"checker_id; DROP TABLE stocks"}); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for unsupported field, got %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"wms/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// defaultVarianceReportDays 未指定开始时间时差异报表回溯的天数
+const defaultVarianceReportDays = 30
+
+// VarianceReportQuery 表示差异报表查询参数
+// To 为空时取当前时间，From 为空时取 To 之前 30 天；GroupBy 为空时按原因代码分组
+type VarianceReportQuery struct {
+	From         time.Time
+	To           time.Time
+	MaterialCode string
+	LocationCode string
+	ReasonCode   string
+	GroupBy      []string
+}
+
+// VarianceReport 表示按分组汇总的盘点差异
+type VarianceReport struct {
+	From    time.Time                      `json:"from"`
+	To      time.Time                      `json:"to"`
+	GroupBy []string                       `json:"group_by"`
+	Rows    []repository.VarianceAggregate `json:"rows"`
+}
+
+// VarianceReport 汇总时间窗口内已生效的盘点差异
+// 未给出原因的差异以空原因代码单独成组
+func (s *inventoryService) VarianceReport(query VarianceReportQuery) (*VarianceReport, error) {
+	groupBy, err := normalizeVarianceGroupBy(query.GroupBy)
+	if err != nil {
+		return nil, err
+	}
+	to := query.To
+	if to.IsZero() {
+		to = time.Now()
+	}
+	from := query.From
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -defaultVarianceReportDays)
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: from must be earlier than to", ErrInvalidInput)
+	}
+
+	rows, err := s.repo.AggregateVariances(repository.VarianceAggregateFilter{
+		From:         from,
+		To:           to,
+		MaterialCode: query.MaterialCode,
+		LocationCode: query.LocationCode,
+		ReasonCode:   query.ReasonCode,
+		GroupBy:      groupBy,
+	})
+	if err != nil {
+		s.logger.Error("Failed to aggregate variances",
+			zap.Time("from", from),
+			zap.Time("to", to),
+			zap.Strings("group_by", groupBy),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to aggregate variances: %w", err)
+	}
+
+	return &VarianceReport{From: from, To: to, GroupBy: groupBy, Rows: rows}, nil
+}
+
+// normalizeVarianceGroupBy 校验分组字段并去重，保持调用方给出的顺序
+func normalizeVarianceGroupBy(fields []string) ([]string, error) {
+	if len(fields) == 0 {
+		return []string{"reason_code"}, nil
+	}
+	allowed := make(map[string]bool, len(repository.VarianceGroupColumns))
+	for _, col := range repository.VarianceGroupColumns {
+		allowed[col] = true
+	}
+	seen := make(map[string]bool, len(fields))
+	var groupBy []string
+	for _, f := range fields {
+		f = strings.TrimSpace(f)
+		if f == "" || seen[f] {
+			continue
+		}
+		if !allowed[f] {
+			return nil, fmt.Errorf("%w: unsupported group_by field %q, allowed: %s",
+				ErrInvalidInput, f, strings.Join(repository.VarianceGroupColumns, ", "))
+		}
+		seen[f] = true
+		groupBy = append(groupBy, f)
+	}
+	if len(groupBy) == 0 {
+		return []string{"reason_code"}, nil
+	}
+	return groupBy, nil
+}
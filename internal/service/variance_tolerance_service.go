@@ -0,0 +1,163 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"wms/internal/model"
+	"wms/internal/repository"
+	"wms/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// Tolerance 表示生效的差异容差
+// Absolute 与 Percentage 为空时表示该维度不限制
+type Tolerance struct {
+	Absolute   *int
+	Percentage *float64
+}
+
+// Exceeded 判断差异是否超出容差
+// 任意一个已配置的维度被超出即视为超出容差；系统库存为 0 时任何非零差异都超出百分比容差
+func (t Tolerance) Exceeded(stockQuantity, difference int) bool {
+	if difference == 0 {
+		return false
+	}
+	absDiff := difference
+	if absDiff < 0 {
+		absDiff = -absDiff
+	}
+	if t.Absolute != nil && absDiff > *t.Absolute {
+		return true
+	}
+	if t.Percentage != nil {
+		if stockQuantity <= 0 {
+			return true
+		}
+		percent := float64(absDiff) / float64(stockQuantity) * 100
+		if percent > *t.Percentage+1e-9 {
+			return true
+		}
+	}
+	return false
+}
+
+// selectTolerance 从候选配置中选出优先级最高的容差
+// 优先级：物料+库位 > 物料 > 库位 > 系统默认值
+func selectTolerance(candidates []model.VarianceTolerance, fallback Tolerance) Tolerance {
+	best := -1
+	bestRank := math.MaxInt
+	for i, c := range candidates {
+		rank := 3
+		switch {
+		case c.MaterialCode != "" && c.LocationCode != "":
+			rank = 0
+		case c.MaterialCode != "":
+			rank = 1
+		case c.LocationCode != "":
+			rank = 2
+		}
+		if rank < bestRank {
+			best, bestRank = i, rank
+		}
+	}
+	if best < 0 {
+		return fallback
+	}
+	return Tolerance{
+		Absolute:   candidates[best].AbsoluteTolerance,
+		Percentage: candidates[best].PercentageTolerance,
+	}
+}
+
+// VarianceToleranceInput 表示新增或更新容差配置的输入
+type VarianceToleranceInput struct {
+	MaterialCode        string
+	LocationCode        string
+	AbsoluteTolerance   *int
+	PercentageTolerance *float64
+}
+
+// VarianceToleranceService 定义差异容差配置的业务接口
+type VarianceToleranceService interface {
+	// ListTolerances 查询全部容差配置
+	ListTolerances() ([]model.VarianceTolerance, error)
+
+	// UpsertTolerance 按作用范围新增或更新容差配置
+	UpsertTolerance(input VarianceToleranceInput) (*model.VarianceTolerance, error)
+
+	// DeleteTolerance 删除容差配置，不存在时返回 ErrNotFound
+	DeleteTolerance(id uint) error
+}
+
+// varianceToleranceService 是 VarianceToleranceService 的具体实现
+type varianceToleranceService struct {
+	repo   repository.VarianceToleranceRepository
+	logger *logger.Logger
+}
+
+// NewVarianceToleranceService 创建新的 VarianceToleranceService 实例
+func NewVarianceToleranceService(repo repository.VarianceToleranceRepository, log *logger.Logger) VarianceToleranceService {
+	return &varianceToleranceService{
+		repo:   repo,
+		logger: log,
+	}
+}
+
+// ListTolerances 查询全部容差配置
+func (s *varianceToleranceService) ListTolerances() ([]model.VarianceTolerance, error) {
+	tolerances, err := s.repo.List()
+	if err != nil {
+		s.logger.Error("Failed to list variance tolerances", zap.Error(err))
+		return nil, fmt.Errorf("failed to list tolerances: %w", err)
+	}
+	return tolerances, nil
+}
+
+// UpsertTolerance 按作用范围新增或更新容差配置
+func (s *varianceToleranceService) UpsertTolerance(input VarianceToleranceInput) (*model.VarianceTolerance, error) {
+	if input.AbsoluteTolerance == nil && input.PercentageTolerance == nil {
+		return nil, fmt.Errorf("%w: at least one of absolute_tolerance and percentage_tolerance is required", ErrInvalidInput)
+	}
+	if input.AbsoluteTolerance != nil && *input.AbsoluteTolerance < 0 {
+		return nil, fmt.Errorf("%w: absolute_tolerance cannot be negative", ErrInvalidInput)
+	}
+	if input.PercentageTolerance != nil && *input.PercentageTolerance < 0 {
+		return nil, fmt.Errorf("%w: percentage_tolerance cannot be negative", ErrInvalidInput)
+	}
+
+	tolerance := &model.VarianceTolerance{
+		MaterialCode:        input.MaterialCode,
+		LocationCode:        input.LocationCode,
+		AbsoluteTolerance:   input.AbsoluteTolerance,
+		PercentageTolerance: input.PercentageTolerance,
+	}
+	if err := s.repo.Upsert(tolerance); err != nil {
+		s.logger.Error("Failed to upsert variance tolerance",
+			zap.String("material_code", input.MaterialCode),
+			zap.String("location_code", input.LocationCode),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to save tolerance: %w", err)
+	}
+
+	s.logger.Info("Variance tolerance saved",
+		zap.String("material_code", input.MaterialCode),
+		zap.String("location_code", input.LocationCode),
+	)
+	return tolerance, nil
+}
+
+// DeleteTolerance 删除容差配置
+func (s *varianceToleranceService) DeleteTolerance(id uint) error {
+	deleted, err := s.repo.Delete(id)
+	if err != nil {
+		s.logger.Error("Failed to delete variance tolerance", zap.Uint("tolerance_id", id), zap.Error(err))
+		return fmt.Errorf("failed to delete tolerance: %w", err)
+	}
+	if !deleted {
+		return fmt.Errorf("%w: tolerance %d", ErrNotFound, id)
+	}
+	s.logger.Info("Variance tolerance deleted", zap.Uint("tolerance_id", id))
+	return nil
+}
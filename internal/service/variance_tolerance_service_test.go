@@ -0,0 +1,57 @@
+package service
+
+import (
+	"testing"
+	"wms/internal/model"
+)
+
+func intPtr(v int) *int { return &v }
+
+func floatPtr(v float64) *float64 { return &v }
+
+func TestToleranceExceeded(t *testing.T) {
+	cases := []struct {
+		name       string
+		tolerance  Tolerance
+		stock      int
+		difference int
+		want       bool
+	}{
+		{"unlimited", Tolerance{}, 10, -10, false},
+		{"zero difference", Tolerance{Absolute: intPtr(0)}, 10, 0, false},
+		{"within absolute", Tolerance{Absolute: intPtr(3)}, 10, -3, false},
+		{"exceeds absolute", Tolerance{Absolute: intPtr(3)}, 10, 4, true},
+		{"within percentage", Tolerance{Percentage: floatPtr(10)}, 50, 5, false},
+		{"exceeds percentage", Tolerance{Percentage: floatPtr(10)}, 50, -6, true},
+		{"percentage on empty stock", Tolerance{Percentage: floatPtr(10)}, 0, 1, true},
+		{"both limits, absolute exceeded", Tolerance{Absolute: intPtr(2), Percentage: floatPtr(50)}, 100, 3, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.tolerance.Exceeded(tc.stock, tc.difference); got != tc.want {
+				t.Errorf("Exceeded(%d, %d) = %v, want %v", tc.stock, tc.difference, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSelectTolerance_PrefersMostSpecific(t *testing.T) {
+	fallback := Tolerance{Absolute: intPtr(100)}
+	candidates := []model.VarianceTolerance{
+		{LocationCode: "A-01-01", AbsoluteTolerance: intPtr(3)},
+		{MaterialCode: "MAT001", AbsoluteTolerance: intPtr(2)},
+		{MaterialCode: "MAT001", LocationCode: "A-01-01", AbsoluteTolerance: intPtr(1)},
+		{AbsoluteTolerance: intPtr(4)},
+	}
+
+	if got := selectTolerance(candidates, fallback); got.Absolute == nil || *got.Absolute != 1 {
+		t.Errorf("Expected material+location tolerance 1, got %v", got.Absolute)
+	}
+	if got := selectTolerance(candidates[:2], fallback); got.Absolute == nil || *got.Absolute != 2 {
+		t.Errorf("Expected material tolerance 2, got %v", got.Absolute)
+	}
+	if got := selectTolerance(nil, fallback); got.Absolute == nil || *got.Absolute != 100 {
+		t.Errorf("Expected fallback tolerance 100, got %v", got.Absolute)
+	}
+}
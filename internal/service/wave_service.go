@@ -0,0 +1,692 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"wms/internal/model"
+	"wms/internal/repository"
+	"wms/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// WavePlanInput 表示组织拣货波次的输入
+// GroupBy 为 carrier 时按承运商与截单时间分组，为 zone 时按拣货库位所属库区分组；
+// Carrier、CutoffBefore、OrderIDs 用于筛选参与组波的单据，零值表示不过滤；
+// Routing 为排列拣货任务的路线策略，为空时使用仓库布局的默认策略
+type WavePlanInput struct {
+	GroupBy      string
+	Carrier      string
+	CutoffBefore *time.Time
+	OrderIDs     []uint
+	Routing      string
+	CreatedBy    string
+}
+
+// WaveDetail 表示波次及其按行走顺序排列的拣货任务
+type WaveDetail struct {
+	Wave  *model.Wave      `json:"wave"`
+	Tasks []model.PickTask `json:"tasks"`
+}
+
+// PickConfirmInput 表示确认拣货的输入，PickedQuantity 小于任务数量即为短拣
+// SerialNumbers 为拣货时扫描的序列号，启用序列号管理的物料必须给出且个数与 PickedQuantity 一致
+type PickConfirmInput struct {
+	PickedQuantity int
+	OperatorID     string
+	SerialNumbers  []string
+}
+
+// PickConfirmResult 表示确认拣货的结果，短拣时 CountTask 为对该库位生成（或已存在）的盘点任务，
+// ReplenishmentTasks 为拣货后拣货位低于最小水位而生成的补货任务
+type PickConfirmResult struct {
+	Task               *model.PickTask           `json:"task"`
+	Wave               *model.Wave               `json:"wave"`
+	CountTask          *model.CycleCountTask     `json:"count_task,omitempty"`
+	ReplenishmentTasks []model.ReplenishmentTask `json:"replenishment_tasks,omitempty"`
+}
+
+// WaveOptions 表示波次服务的可配置项
+type WaveOptions struct {
+	// PackingLocation 组波时为单据指定的包装暂存库位，拣货确认后货物移至该库位
+	PackingLocation string
+	// Retry 事务遇到序列化失败或死锁时的重试策略
+	Retry RetryPolicy
+}
+
+// WaveService 定义拣货波次、拣货任务与拣货确认的业务接口
+type WaveService interface {
+	// PlanWaves 将已分配足量的出库单据组织为拣货波次并生成拣货任务，没有可组波的单据时返回 ErrInvalidState
+	PlanWaves(input WavePlanInput) ([]WaveDetail, error)
+
+	// ListWaves 按过滤条件查询波次
+	ListWaves(filter repository.WaveFilter) ([]model.Wave, error)
+
+	// GetWave 查询波次及其拣货任务，不存在时返回 ErrNotFound
+	GetWave(id uint) (*WaveDetail, error)
+
+	// ListPickTasks 按过滤条件查询拣货任务
+	ListPickTasks(filter repository.PickTaskFilter) ([]model.PickTask, error)
+
+	// AssignPickTask 将未完成的拣货任务指派给拣货员
+	AssignPickTask(id uint, assignedTo string) (*model.PickTask, error)
+
+	// ConfirmPick 确认拣货：将实拣数量移至包装暂存库位；短拣时释放未拣数量的预留并对库位生成盘点任务；
+	// 拣货库位低于补货最小水位时生成补货任务
+	ConfirmPick(id uint, input PickConfirmInput) (*PickConfirmResult, error)
+}
+
+// waveService 是 WaveService 的具体实现
+type waveService struct {
+	repo           repository.WaveRepository
+	outboundRepo   repository.OutboundRepository
+	router         *taskRouter
+	cycleCountRepo repository.CycleCountRepository
+	ledger         *stockLedger
+	replenisher    *replenisher
+	options        WaveOptions
+	logger         *logger.Logger
+}
+
+// NewWaveService 创建新的 WaveService 实例
+func NewWaveService(repo repository.WaveRepository, outboundRepo repository.OutboundRepository, locationRepo repository.LocationRepository,
+	layoutRepo repository.LayoutRepository, cycleCountRepo repository.CycleCountRepository, stockRepo repository.StockRepository,
+	replenishmentRepo repository.ReplenishmentRepository, options WaveOptions, log *logger.Logger) WaveService {
+	return &waveService{
+		repo:           repo,
+		outboundRepo:   outboundRepo,
+		router:         newTaskRouter(layoutRepo, locationRepo),
+		cycleCountRepo: cycleCountRepo,
+		ledger:         newStockLedger(stockRepo),
+		replenisher:    newReplenisher(replenishmentRepo, stockRepo),
+		options:        options,
+		logger:         log,
+	}
+}
+
+// PlanWaves 组织拣货波次
+// 锁定候选单据后按分组方式拆分预留记录，同一波次内同一库位同一物料的预留合并为一个拣货任务；
+// 组入波次的单据状态改为 picking 并记录包装暂存库位，之后不能再取消
+func (s *waveService) PlanWaves(input WavePlanInput) ([]WaveDetail, error) {
+	if input.GroupBy != model.WaveGroupByCarrier && input.GroupBy != model.WaveGroupByZone {
+		return nil, fmt.Errorf("%w: group_by must be carrier or zone", ErrInvalidInput)
+	}
+	if strings.TrimSpace(input.CreatedBy) == "" {
+		return nil, fmt.Errorf("%w: created_by is required", ErrInvalidInput)
+	}
+
+	var details []WaveDetail
+	err := s.options.Retry.run(s.logger, "wave_plan", func() error {
+		return runInTransaction(s.repo, s.logger, "wave_plan", func(tx *gorm.DB) error {
+			orders, err := s.repo.ListWaveCandidates(tx, repository.WaveCandidateFilter{
+				Carrier:      input.Carrier,
+				CutoffBefore: input.CutoffBefore,
+				OrderIDs:     input.OrderIDs,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to fetch wave candidates: %w", err)
+			}
+			if len(orders) == 0 {
+				return fmt.Errorf("%w: no allocated orders are waiting for a wave", ErrInvalidState)
+			}
+			orderIDs := make([]uint, 0, len(orders))
+			for _, order := range orders {
+				orderIDs = append(orderIDs, order.ID)
+			}
+			allocations, err := s.repo.ListUnwavedAllocations(tx, orderIDs)
+			if err != nil {
+				return fmt.Errorf("failed to fetch allocations: %w", err)
+			}
+			route, err := s.router.load(tx, input.Routing)
+			if err != nil {
+				return err
+			}
+
+			details = details[:0]
+			for _, group := range groupWaves(input.GroupBy, orders, allocations, route.locations) {
+				wave := group.wave
+				plans, walk := planPickTasks(group.allocations, route)
+				wave.RoutingStrategy = walk.Strategy
+				wave.PlannedDistance = walk.Distance
+				wave.Status = model.WaveStatusReleased
+				wave.TaskCount = len(plans)
+				wave.CreatedBy = input.CreatedBy
+				if err := s.repo.CreateWave(tx, &wave); err != nil {
+					return fmt.Errorf("failed to create wave: %w", err)
+				}
+				tasks := make([]model.PickTask, 0, len(plans))
+				for _, plan := range plans {
+					task := plan.task
+					task.WaveID = wave.ID
+					task.Status = model.PickTaskStatusOpen
+					if err := s.repo.CreatePickTask(tx, &task, plan.allocationIDs); err != nil {
+						return fmt.Errorf("failed to create pick task: %w", err)
+					}
+					tasks = append(tasks, task)
+				}
+				details = append(details, WaveDetail{Wave: &wave, Tasks: tasks})
+			}
+
+			for i := range orders {
+				orders[i].Status = model.OutboundOrderStatusPicking
+				orders[i].StagingLocation = s.options.PackingLocation
+				if err := s.outboundRepo.UpdateStatus(tx, &orders[i]); err != nil {
+					return fmt.Errorf("failed to update outbound order: %w", err)
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		s.logger.Warn("Wave planning failed", zap.String("group_by", input.GroupBy), zap.Error(err))
+		return nil, err
+	}
+
+	for _, detail := range details {
+		s.logger.Info("Wave released",
+			zap.Uint("wave_id", detail.Wave.ID),
+			zap.String("group_by", detail.Wave.GroupBy),
+			zap.String("carrier", detail.Wave.Carrier),
+			zap.String("zone", detail.Wave.Zone),
+			zap.Int("order_count", detail.Wave.OrderCount),
+			zap.Int("task_count", detail.Wave.TaskCount),
+			zap.String("routing_strategy", detail.Wave.RoutingStrategy),
+			zap.Float64("planned_distance", detail.Wave.PlannedDistance),
+			zap.String("created_by", input.CreatedBy),
+		)
+	}
+	return details, nil
+}
+
+// waveGroup 表示一个待创建的波次及其包含的预留记录
+type waveGroup struct {
+	wave        model.Wave
+	allocations []model.StockAllocation
+}
+
+// groupWaves 按分组方式将单据的预留记录拆分为波次
+// carrier：每个 (承运商, 截单时间) 一个波次，截单时间早的在前，没有截单时间的排在最后；
+// zone：每个库区一个波次，未登记库区的库位归入空库区
+func groupWaves(groupBy string, orders []model.OutboundOrder, allocations []model.StockAllocation, locations map[string]model.Location) []waveGroup {
+	groups := make(map[string]*waveGroup)
+	orderSets := make(map[string]map[uint]bool)
+	byOrder := make(map[uint]model.OutboundOrder, len(orders))
+	for _, order := range orders {
+		byOrder[order.ID] = order
+	}
+
+	for _, allocation := range allocations {
+		var key string
+		var wave model.Wave
+		if groupBy == model.WaveGroupByZone {
+			zone := locations[allocation.LocationCode].Zone
+			key = zone
+			wave = model.Wave{GroupBy: model.WaveGroupByZone, Zone: zone}
+		} else {
+			order := byOrder[allocation.OrderID]
+			key = order.Carrier + "|"
+			if order.CarrierCutoffAt != nil {
+				key += order.CarrierCutoffAt.UTC().Format(time.RFC3339Nano)
+			}
+			wave = model.Wave{GroupBy: model.WaveGroupByCarrier, Carrier: order.Carrier, CutoffAt: order.CarrierCutoffAt}
+		}
+		group, ok := groups[key]
+		if !ok {
+			group = &waveGroup{wave: wave}
+			groups[key] = group
+			orderSets[key] = make(map[uint]bool)
+		}
+		group.allocations = append(group.allocations, allocation)
+		orderSets[key][allocation.OrderID] = true
+	}
+
+	result := make([]waveGroup, 0, len(groups))
+	for key, group := range groups {
+		group.wave.OrderCount = len(orderSets[key])
+		result = append(result, *group)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i].wave, result[j].wave
+		if a.Zone != b.Zone {
+			return a.Zone < b.Zone
+		}
+		if (a.CutoffAt == nil) != (b.CutoffAt == nil) {
+			return a.CutoffAt != nil
+		}
+		if a.CutoffAt != nil && !a.CutoffAt.Equal(*b.CutoffAt) {
+			return a.CutoffAt.Before(*b.CutoffAt)
+		}
+		return a.Carrier < b.Carrier
+	})
+	return result
+}
+
+// pickTaskPlan 表示一个待创建的拣货任务及其合并的预留记录
+type pickTaskPlan struct {
+	task          model.PickTask
+	allocationIDs []uint
+}
+
+// planPickTasks 将预留记录按 (库位, 物料, 批次) 合并为拣货任务，并按路线规划的行走顺序编号，同一库位按物料、批次排序
+func planPickTasks(allocations []model.StockAllocation, route *routeContext) ([]pickTaskPlan, RoutePlan) {
+	index := make(map[stockKey]int)
+	plans := make([]pickTaskPlan, 0)
+	codes := make([]string, 0, len(allocations))
+	for _, allocation := range allocations {
+		key := stockKey{materialCode: allocation.MaterialCode, locationCode: allocation.LocationCode, lotNumber: allocation.LotNumber}
+		i, ok := index[key]
+		if !ok {
+			i = len(plans)
+			index[key] = i
+			plans = append(plans, pickTaskPlan{task: model.PickTask{
+				MaterialCode: allocation.MaterialCode,
+				LocationCode: allocation.LocationCode,
+				LotNumber:    allocation.LotNumber,
+				Zone:         route.locations[allocation.LocationCode].Zone,
+			}})
+			codes = append(codes, allocation.LocationCode)
+		}
+		plans[i].task.Quantity += allocation.Quantity
+		plans[i].allocationIDs = append(plans[i].allocationIDs, allocation.ID)
+	}
+
+	walk := route.route(codes)
+	positions := walk.positions()
+	sort.SliceStable(plans, func(i, j int) bool {
+		a, b := plans[i].task, plans[j].task
+		if positions[a.LocationCode] != positions[b.LocationCode] {
+			return positions[a.LocationCode] < positions[b.LocationCode]
+		}
+		if a.MaterialCode != b.MaterialCode {
+			return a.MaterialCode < b.MaterialCode
+		}
+		return a.LotNumber < b.LotNumber
+	})
+	for i := range plans {
+		plans[i].task.Sequence = i + 1
+	}
+	return plans, walk
+}
+
+// ListWaves 查询波次列表
+func (s *waveService) ListWaves(filter repository.WaveFilter) ([]model.Wave, error) {
+	waves, err := s.repo.ListWaves(filter)
+	if err != nil {
+		s.logger.Error("Failed to list waves", zap.Error(err))
+		return nil, fmt.Errorf("failed to list waves: %w", err)
+	}
+	return waves, nil
+}
+
+// GetWave 查询波次及其拣货任务
+func (s *waveService) GetWave(id uint) (*WaveDetail, error) {
+	wave, err := s.repo.GetWave(id)
+	if err != nil {
+		s.logger.Error("Failed to fetch wave", zap.Uint("wave_id", id), zap.Error(err))
+		return nil, fmt.Errorf("failed to fetch wave: %w", err)
+	}
+	if wave == nil {
+		return nil, fmt.Errorf("%w: wave %d", ErrNotFound, id)
+	}
+	tasks, err := s.repo.ListPickTasks(repository.PickTaskFilter{WaveID: id})
+	if err != nil {
+		s.logger.Error("Failed to list pick tasks", zap.Uint("wave_id", id), zap.Error(err))
+		return nil, fmt.Errorf("failed to list pick tasks: %w", err)
+	}
+	return &WaveDetail{Wave: wave, Tasks: tasks}, nil
+}
+
+// ListPickTasks 查询拣货任务列表
+func (s *waveService) ListPickTasks(filter repository.PickTaskFilter) ([]model.PickTask, error) {
+	tasks, err := s.repo.ListPickTasks(filter)
+	if err != nil {
+		s.logger.Error("Failed to list pick tasks", zap.Error(err))
+		return nil, fmt.Errorf("failed to list pick tasks: %w", err)
+	}
+	return tasks, nil
+}
+
+// AssignPickTask 指派拣货任务
+func (s *waveService) AssignPickTask(id uint, assignedTo string) (*model.PickTask, error) {
+	assignee := strings.TrimSpace(assignedTo)
+	if assignee == "" {
+		return nil, fmt.Errorf("%w: assigned_to is required", ErrInvalidInput)
+	}
+
+	var result *model.PickTask
+	err := s.options.Retry.run(s.logger, "pick_assign", func() error {
+		return runInTransaction(s.repo, s.logger, "pick_assign", func(tx *gorm.DB) error {
+			task, err := s.openPickTask(tx, id)
+			if err != nil {
+				return err
+			}
+			task.AssignedTo = assignee
+			if err := s.repo.UpdatePickTask(tx, task); err != nil {
+				return fmt.Errorf("failed to update pick task: %w", err)
+			}
+			result = task
+			return nil
+		})
+	})
+	if err != nil {
+		s.logger.Warn("Pick task assignment failed", zap.Uint("task_id", id), zap.Error(err))
+		return nil, err
+	}
+
+	s.logger.Info("Pick task assigned", zap.Uint("task_id", id), zap.String("assigned_to", assignee))
+	return result, nil
+}
+
+// openPickTask 在事务中锁定拣货任务并校验其尚未确认
+func (s *waveService) openPickTask(tx *gorm.DB, id uint) (*model.PickTask, error) {
+	task, err := s.repo.GetPickTaskForUpdate(tx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch pick task: %w", err)
+	}
+	if task == nil {
+		return nil, fmt.Errorf("%w: pick task %d", ErrNotFound, id)
+	}
+	if task.Status != model.PickTaskStatusOpen {
+		return nil, fmt.Errorf("%w: pick task %d is %s", ErrInvalidState, id, task.Status)
+	}
+	return task, nil
+}
+
+// ConfirmPick 确认拣货
+// 实拣数量按单据顺序分摊到任务合并的预留记录：已拣部分移至单据的包装暂存库位并继续预留，未拣部分只释放预留；
+// 短拣时对该物料与库位生成盘点任务，由现有盘点上传流程完成并修正在库数量
+// 拣货库位配置了补货水位且拣货后低于最小水位时，在同一事务中生成补货任务
+// 锁定顺序：拣货任务 -> 出库单据（按 ID）-> 库存行 -> 波次 -> 补货水位 -> 补货来源库存行
+func (s *waveService) ConfirmPick(id uint, input PickConfirmInput) (*PickConfirmResult, error) {
+	if strings.TrimSpace(input.OperatorID) == "" {
+		return nil, fmt.Errorf("%w: operator_id is required", ErrInvalidInput)
+	}
+	if input.PickedQuantity < 0 {
+		return nil, fmt.Errorf("%w: picked_quantity cannot be negative", ErrInvalidInput)
+	}
+	if len(input.SerialNumbers) > 0 && len(input.SerialNumbers) != input.PickedQuantity {
+		return nil, fmt.Errorf("%w: %d serial numbers scanned for picked_quantity %d", ErrInvalidInput, len(input.SerialNumbers), input.PickedQuantity)
+	}
+
+	var result *PickConfirmResult
+	err := s.options.Retry.run(s.logger, "pick_confirm", func() error {
+		return runInTransaction(s.repo, s.logger, "pick_confirm", func(tx *gorm.DB) error {
+			task, err := s.openPickTask(tx, id)
+			if err != nil {
+				return err
+			}
+			if input.PickedQuantity > task.Quantity {
+				return fmt.Errorf("%w: picked_quantity %d exceeds task quantity %d", ErrInvalidInput, input.PickedQuantity, task.Quantity)
+			}
+
+			allocations, err := s.repo.ListTaskAllocations(tx, task.ID)
+			if err != nil {
+				return fmt.Errorf("failed to fetch allocations: %w", err)
+			}
+			orders, err := s.lockOrders(tx, allocations)
+			if err != nil {
+				return err
+			}
+
+			now := time.Now()
+			remaining := input.PickedQuantity
+			for i := range allocations {
+				allocation := &allocations[i]
+				take := allocation.Quantity
+				if take > remaining {
+					take = remaining
+				}
+				var serials []string
+				if len(input.SerialNumbers) > 0 {
+					picked := input.PickedQuantity - remaining
+					serials = input.SerialNumbers[picked : picked+take]
+				}
+				remaining -= take
+				if err := s.stage(tx, orders[allocation.OrderID], allocation, take, serials, task.ID, input.OperatorID); err != nil {
+					return err
+				}
+				allocation.PickedQuantity = take
+				allocation.Status = model.AllocationStatusPicked
+				allocation.PickedAt = &now
+				if err := s.repo.UpdateAllocation(tx, allocation); err != nil {
+					return fmt.Errorf("failed to update allocation: %w", err)
+				}
+
+				line := findOutboundLine(orders[allocation.OrderID], allocation.LineID)
+				if line == nil {
+					return fmt.Errorf("outbound order %d has no line %d", allocation.OrderID, allocation.LineID)
+				}
+				line.PickedQuantity += take
+				line.AllocatedQuantity -= allocation.Quantity - take
+			}
+
+			for _, order := range sortedOrders(orders) {
+				if err := s.finishOrder(tx, order); err != nil {
+					return err
+				}
+			}
+
+			task.PickedQuantity = input.PickedQuantity
+			task.Status = model.PickTaskStatusPicked
+			task.ConfirmedBy = input.OperatorID
+			task.ConfirmedAt = &now
+			result = &PickConfirmResult{Task: task}
+			if input.PickedQuantity < task.Quantity {
+				task.Status = model.PickTaskStatusShort
+				countTask := &model.CycleCountTask{
+					ScheduledDate: startOfDay(now),
+					MaterialCode:  task.MaterialCode,
+					LocationCode:  task.LocationCode,
+					Source:        model.CycleCountSourceShortPick,
+					Status:        model.CycleCountTaskStatusOpen,
+				}
+				if err := s.cycleCountRepo.EnsureOpenTask(tx, countTask); err != nil {
+					return fmt.Errorf("failed to create count task: %w", err)
+				}
+				task.CountTaskID = &countTask.ID
+				result.CountTask = countTask
+			}
+			if err := s.repo.UpdatePickTask(tx, task); err != nil {
+				return fmt.Errorf("failed to update pick task: %w", err)
+			}
+
+			wave, err := s.repo.GetWaveForUpdate(tx, task.WaveID)
+			if err != nil {
+				return fmt.Errorf("failed to fetch wave: %w", err)
+			}
+			if wave == nil {
+				return fmt.Errorf("%w: wave %d", ErrNotFound, task.WaveID)
+			}
+			advanceWave(wave, task, now)
+			if err := s.repo.UpdateWave(tx, wave); err != nil {
+				return fmt.Errorf("failed to update wave: %w", err)
+			}
+			result.Wave = wave
+
+			tasks, err := s.replenisher.check(tx, task.MaterialCode, task.LocationCode, model.ReplenishmentTriggerPick, input.OperatorID)
+			if err != nil {
+				return err
+			}
+			result.ReplenishmentTasks = tasks
+			return nil
+		})
+	})
+	if err != nil {
+		s.logger.Warn("Pick confirmation failed", zap.Uint("task_id", id), zap.Error(err))
+		return nil, err
+	}
+
+	fields := []zap.Field{
+		zap.Uint("task_id", id),
+		zap.Uint("wave_id", result.Task.WaveID),
+		zap.String("material_code", result.Task.MaterialCode),
+		zap.String("location_code", result.Task.LocationCode),
+		zap.Int("quantity", result.Task.Quantity),
+		zap.Int("picked_quantity", result.Task.PickedQuantity),
+		zap.String("wave_status", result.Wave.Status),
+		zap.Int("replenishment_tasks", len(result.ReplenishmentTasks)),
+		zap.String("operator_id", input.OperatorID),
+	}
+	if result.CountTask != nil {
+		s.logger.Warn("Short pick confirmed, count task raised", append(fields, zap.Uint("count_task_id", result.CountTask.ID))...)
+	} else {
+		s.logger.Info("Pick confirmed", fields...)
+	}
+	return result, nil
+}
+
+// stage 将预留记录的实拣数量从拣货库位移至单据的包装暂存库位并在暂存库位继续为单据预留，
+// 同时消耗拣货库位上该记录的全部预留；实拣为 0 时只释放预留；
+// serials 为分摊到该记录的扫描序列号，启用序列号管理的物料必须给出，序列号上记录所属的出库单据；
+// 未记录暂存库位的单据在启用包装暂存前已组波，发运时不再扣减库存，拣货时按原方式直接扣减
+func (s *waveService) stage(tx *gorm.DB, order *model.OutboundOrder, allocation *model.StockAllocation, take int, serials []string, taskID uint, operatorID string) error {
+	if take == 0 {
+		_, err := s.ledger.release(tx, allocation.MaterialCode, allocation.LocationCode, allocation.LotNumber, allocation.Quantity)
+		return err
+	}
+	if order.StagingLocation == "" {
+		return s.pickDirect(tx, allocation, take, serials, taskID, operatorID)
+	}
+	return s.ledger.move(tx, StockMove{
+		MaterialCode:    allocation.MaterialCode,
+		LotNumber:       allocation.LotNumber,
+		FromLocation:    allocation.LocationCode,
+		ToLocation:      order.StagingLocation,
+		Quantity:        take,
+		ConsumeReserved: allocation.Quantity,
+		KeepReserved:    true,
+		MovementType:    model.MovementTypePick,
+		ReferenceType:   model.ReferenceTypePickTask,
+		ReferenceID:     strconv.FormatUint(uint64(taskID), 10),
+		OperatorID:      operatorID,
+		SerialNumbers:   serials,
+		RequireSerials:  true,
+		OutboundOrderID: order.ID,
+	})
+}
+
+// pickDirect 从拣货库位直接扣减实拣数量并消耗该记录的全部预留，用于未记录暂存库位的单据
+// 货物拣货后即离开仓库，流水记为 ship（reference_type 为 pick_task）；与移至暂存库位相同，库存已过有效期时返回 ErrStockExpired，
+// 启用序列号管理的物料须扫描序列号，发出的即为扫描的序列号
+func (s *waveService) pickDirect(tx *gorm.DB, allocation *model.StockAllocation, take int, serials []string, taskID uint, operatorID string) error {
+	stock, material, err := s.ledger.lockChecked(tx, allocation.MaterialCode, allocation.LocationCode, allocation.LotNumber, false)
+	if err != nil {
+		return err
+	}
+	if err := checkNotExpired(stock); err != nil {
+		return err
+	}
+	if material.SerialTracked {
+		if err := checkSerialCount(allocation.MaterialCode, serials, take, true); err != nil {
+			return err
+		}
+	}
+	_, err = s.ledger.apply(tx, StockChange{
+		MaterialCode:  allocation.MaterialCode,
+		LocationCode:  allocation.LocationCode,
+		LotNumber:     allocation.LotNumber,
+		Delta:         -take,
+		ReservedDelta: -allocation.Quantity,
+		MovementType:  model.MovementTypeShip,
+		ReferenceType: model.ReferenceTypePickTask,
+		ReferenceID:   strconv.FormatUint(uint64(taskID), 10),
+		FromLocation:  allocation.LocationCode,
+		OperatorID:    operatorID,
+		SerialNumbers: serials,
+	})
+	return err
+}
+
+// lockOrders 按 ID 顺序锁定预留记录所属的出库单据
+func (s *waveService) lockOrders(tx *gorm.DB, allocations []model.StockAllocation) (map[uint]*model.OutboundOrder, error) {
+	ids := make([]uint, 0)
+	orders := make(map[uint]*model.OutboundOrder)
+	for _, allocation := range allocations {
+		if _, ok := orders[allocation.OrderID]; !ok {
+			orders[allocation.OrderID] = nil
+			ids = append(ids, allocation.OrderID)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		order, err := s.outboundRepo.GetForUpdate(tx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch outbound order: %w", err)
+		}
+		if order == nil {
+			return nil, fmt.Errorf("%w: outbound order %d", ErrNotFound, id)
+		}
+		orders[id] = order
+	}
+	return orders, nil
+}
+
+// finishOrder 保存单据各行的拣货数量；单据已没有预留中的记录时结束拣货，按实拣数量标记各行为拣足或短拣
+func (s *waveService) finishOrder(tx *gorm.DB, order *model.OutboundOrder) error {
+	active, err := s.repo.CountActiveAllocations(tx, order.ID)
+	if err != nil {
+		return fmt.Errorf("failed to count allocations: %w", err)
+	}
+	for i := range order.Lines {
+		line := &order.Lines[i]
+		if active == 0 {
+			line.Status = model.OutboundLineStatusPicked
+			if line.PickedQuantity < line.OrderedQuantity {
+				line.Status = model.OutboundLineStatusShort
+			}
+		}
+		if err := s.outboundRepo.UpdateLine(tx, line); err != nil {
+			return fmt.Errorf("failed to update outbound order line: %w", err)
+		}
+	}
+	if active == 0 {
+		order.Status = model.OutboundOrderStatusPicked
+		if err := s.outboundRepo.UpdateStatus(tx, order); err != nil {
+			return fmt.Errorf("failed to update outbound order: %w", err)
+		}
+	}
+	return nil
+}
+
+// findOutboundLine 按 ID 查找单据行
+func findOutboundLine(order *model.OutboundOrder, lineID uint) *model.OutboundOrderLine {
+	if order == nil {
+		return nil
+	}
+	for i := range order.Lines {
+		if order.Lines[i].ID == lineID {
+			return &order.Lines[i]
+		}
+	}
+	return nil
+}
+
+// sortedOrders 按 ID 顺序返回单据
+func sortedOrders(orders map[uint]*model.OutboundOrder) []*model.OutboundOrder {
+	result := make([]*model.OutboundOrder, 0, len(orders))
+	for _, order := range orders {
+		result = append(result, order)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+// advanceWave 根据刚确认的拣货任务更新波次进度与状态
+func advanceWave(wave *model.Wave, task *model.PickTask, at time.Time) {
+	wave.CompletedTasks++
+	if task.Status == model.PickTaskStatusShort {
+		wave.ShortTasks++
+	}
+	if wave.StartedAt == nil {
+		wave.StartedAt = &at
+	}
+	wave.Status = model.WaveStatusPicking
+	if wave.CompletedTasks >= wave.TaskCount {
+		wave.Status = model.WaveStatusCompleted
+		wave.CompletedAt = &at
+	}
+}
@@ -0,0 +1,245 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+	"wms/internal/model"
+	"wms/internal/repository"
+	"wms/internal/routing"
+
+	"gorm.io/gorm"
+)
+
+func TestGroupWaves(t *testing.T) {
+	early := time.Date(2026, 3, 2, 15, 0, 0, 0, time.UTC)
+	late := time.Date(2026, 3, 2, 18, 0, 0, 0, time.UTC)
+	orders := []model.OutboundOrder{
+		{ID: 1, Carrier: "SF", CarrierCutoffAt: &late},
+		{ID: 2, Carrier: "SF", CarrierCutoffAt: &early},
+		{ID: 3, Carrier: "JD", CarrierCutoffAt: &late},
+		{ID: 4, Carrier: "SF", CarrierCutoffAt: &early},
+		{ID: 5},
+	}
+	allocations := []model.StockAllocation{
+		{ID: 11, OrderID: 1, LocationCode: "A-01"},
+		{ID: 12, OrderID: 2, LocationCode: "B-01"},
+		{ID: 13, OrderID: 3, LocationCode: "A-02"},
+		{ID: 14, OrderID: 4, LocationCode: "A-01"},
+		{ID: 15, OrderID: 5, LocationCode: "X-99"},
+		{ID: 16, OrderID: 2, LocationCode: "A-02"},
+	}
+	locations := map[string]model.Location{
+		"A-01": {Code: "A-01", Zone: "A"},
+		"A-02": {Code: "A-02", Zone: "A"},
+		"B-01": {Code: "B-01", Zone: "B"},
+	}
+
+	describe := func(groups []waveGroup) []string {
+		got := make([]string, 0, len(groups))
+		for _, g := range groups {
+			ids := make([]uint, 0, len(g.allocations))
+			for _, a := range g.allocations {
+				ids = append(ids, a.ID)
+			}
+			got = append(got, fmt.Sprintf("%s/%s/%d:%v", g.wave.Carrier, g.wave.Zone, g.wave.OrderCount, ids))
+		}
+		return got
+	}
+
+	byCarrier := describe(groupWaves(model.WaveGroupByCarrier, orders, allocations, locations))
+	wantCarrier := []string{"SF//2:[12 14 16]", "JD//1:[13]", "SF//1:[11]", "//1:[15]"}
+	if fmt.Sprint(byCarrier) != fmt.Sprint(wantCarrier) {
+		t.Errorf("carrier waves: expected %v, got %v", wantCarrier, byCarrier)
+	}
+
+	byZone := describe(groupWaves(model.WaveGroupByZone, orders, allocations, locations))
+	wantZone := []string{"//1:[15]", "/A/4:[11 13 14 16]", "/B/1:[12]"}
+	if fmt.Sprint(byZone) != fmt.Sprint(wantZone) {
+		t.Errorf("zone waves: expected %v, got %v", wantZone, byZone)
+	}
+}
+
+func TestPlanPickTasks_MergesAndSortsByWalkSequence(t *testing.T) {
+	locations := []model.Location{
+		{Code: "A-01", Sequence: 30},
+		{Code: "A-02", Sequence: 10},
+		{Code: "A-03", Sequence: 10},
+	}
+	route := newRouteContext(routing.StrategySShape, &model.WarehouseLayout{}, locations, nil)
+	allocations := []model.StockAllocation{
+		{ID: 1, MaterialCode: "MAT002", LocationCode: "A-01", Quantity: 2},
+		{ID: 2, MaterialCode: "MAT001", LocationCode: "Z-01", Quantity: 1},
+		{ID: 3, MaterialCode: "MAT001", LocationCode: "A-03", Quantity: 4},
+		{ID: 4, MaterialCode: "MAT002", LocationCode: "A-01", Quantity: 5},
+		{ID: 5, MaterialCode: "MAT001", LocationCode: "A-02", Quantity: 3},
+		{ID: 6, MaterialCode: "MAT001", LocationCode: "A-01", Quantity: 6},
+	}
+
+	plans, walk := planPickTasks(allocations, route)
+	got := make([]string, 0, len(plans))
+	for _, p := range plans {
+		got = append(got, fmt.Sprintf("%d:%s/%s=%d%v", p.task.Sequence, p.task.LocationCode, p.task.MaterialCode, p.task.Quantity, p.allocationIDs))
+	}
+	want := []string{
+		"1:A-02/MAT001=3[5]",
+		"2:A-03/MAT001=4[3]",
+		"3:A-01/MAT001=6[6]",
+		"4:A-01/MAT002=7[1 4]",
+		"5:Z-01/MAT001=1[2]",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if walk.Distance != 0 || len(walk.Unrouted) != 4 {
+		t.Errorf("expected all locations unrouted, got %+v", walk)
+	}
+}
+
+func TestRouteContext_AllUnrouted(t *testing.T) {
+	locations := []model.Location{{Code: "A-01", Sequence: 20}, {Code: "A-02", Sequence: 10}}
+	for _, strategy := range []string{routing.StrategySShape, routing.StrategyLargestGap, routing.StrategyTSP} {
+		route := newRouteContext(strategy, &model.WarehouseLayout{BackAisleY: 10}, locations, nil)
+
+		plan := route.route([]string{"A-01", "Z-01", "A-02"})
+		if got, want := fmt.Sprint(plan.LocationCodes), "[A-02 A-01 Z-01]"; got != want {
+			t.Errorf("%s: expected %s, got %s", strategy, want, got)
+		}
+		if plan.Distance != 0 || len(plan.Unrouted) != 3 {
+			t.Errorf("%s: expected all locations unrouted, got %+v", strategy, plan)
+		}
+	}
+}
+
+func TestPlanPickTasks_FollowsLayoutRoute(t *testing.T) {
+	// 两条巷道，出发点在左侧前通道；S 形路线先由前向后走 A 巷道，再由后向前走 B 巷道
+	locations := []model.Location{
+		{Code: "A-01", Aisle: "A", X: 0, Y: 2},
+		{Code: "A-05", Aisle: "A", X: 0, Y: 8},
+		{Code: "B-01", Aisle: "B", X: 4, Y: 2},
+		{Code: "B-05", Aisle: "B", X: 4, Y: 8},
+		{Code: "R-01", Sequence: 1},
+	}
+	layout := &model.WarehouseLayout{FrontAisleY: 0, BackAisleY: 10}
+	route := newRouteContext(routing.StrategySShape, layout, locations, nil)
+	allocations := []model.StockAllocation{
+		{ID: 1, MaterialCode: "MAT001", LocationCode: "B-01", Quantity: 1},
+		{ID: 2, MaterialCode: "MAT001", LocationCode: "R-01", Quantity: 1},
+		{ID: 3, MaterialCode: "MAT001", LocationCode: "A-05", Quantity: 1},
+		{ID: 4, MaterialCode: "MAT001", LocationCode: "B-05", Quantity: 1},
+		{ID: 5, MaterialCode: "MAT001", LocationCode: "A-01", Quantity: 1},
+	}
+
+	plans, walk := planPickTasks(allocations, route)
+	got := make([]string, 0, len(plans))
+	for _, p := range plans {
+		got = append(got, p.task.LocationCode)
+	}
+	want := []string{"A-01", "A-05", "B-05", "B-01", "R-01"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if walk.Strategy != routing.StrategySShape || walk.Distance != 28 || fmt.Sprint(walk.Unrouted) != "[R-01]" {
+		t.Errorf("unexpected route plan %+v", walk)
+	}
+}
+
+func TestAdvanceWave(t *testing.T) {
+	now := time.Now()
+	wave := &model.Wave{Status: model.WaveStatusReleased, TaskCount: 2}
+
+	advanceWave(wave, &model.PickTask{Status: model.PickTaskStatusShort}, now)
+	if wave.Status != model.WaveStatusPicking || wave.StartedAt == nil || wave.ShortTasks != 1 {
+		t.Fatalf("expected picking wave with one short task, got %+v", wave)
+	}
+
+	advanceWave(wave, &model.PickTask{Status: model.PickTaskStatusPicked}, now)
+	if wave.Status != model.WaveStatusCompleted || wave.CompletedAt == nil || wave.CompletedTasks != 2 {
+		t.Errorf("expected completed wave, got %+v", wave)
+	}
+}
+
+// pickedAllocationRepository 只返回给定的已拣预留记录
+type pickedAllocationRepository struct {
+	repository.OutboundRepository
+	picked []model.StockAllocation
+}
+
+func (r *pickedAllocationRepository) ListPickedAllocations(tx *gorm.DB, orderID uint) ([]model.StockAllocation, error) {
+	return r.picked, nil
+}
+
+func TestPickAndShip_DeductsStockOnce(t *testing.T) {
+	for _, staging := range []string{"PACKING", ""} {
+		repo := newMemoryStockRepository()
+		ledger := newStockLedger(repo)
+		repo.SaveStock(nil, &model.Stock{MaterialCode: "MAT-1", LocationCode: "A-01", Quantity: 10, ReservedQuantity: 4})
+		order := &model.OutboundOrder{ID: 1, StagingLocation: staging}
+		allocation := &model.StockAllocation{ID: 1, OrderID: 1, MaterialCode: "MAT-1", LocationCode: "A-01", Quantity: 4}
+		waves := &waveService{ledger: ledger, options: WaveOptions{PackingLocation: "PACKING"}}
+
+		// 短拣：预留 4 件只拣到 3 件
+		if err := waves.stage(nil, order, allocation, 3, nil, 1, "picker"); err != nil {
+			t.Fatalf("staging %q: unexpected pick error: %v", staging, err)
+		}
+		allocation.PickedQuantity = 3
+		packing := &packingService{ledger: ledger, outboundRepo: &pickedAllocationRepository{picked: []model.StockAllocation{*allocation}}}
+		if err := packing.deductStaged(nil, order, &model.Shipment{ID: 1}, "shipper"); err != nil {
+			t.Fatalf("staging %q: unexpected ship error: %v", staging, err)
+		}
+
+		// 无论是否经过暂存库位，发运后都只扣减一次实拣数量，不残留预留，且离开仓库的数量都记为 ship 流水
+		total, reserved, shipped := 0, 0, 0
+		for _, stock := range repo.stocks {
+			total += stock.Quantity
+			reserved += stock.ReservedQuantity
+		}
+		for _, movement := range repo.movements {
+			if movement.MovementType == model.MovementTypeShip {
+				shipped -= movement.Delta
+			}
+		}
+		if total != 7 || reserved != 0 || shipped != 3 {
+			t.Errorf("staging %q: expected 7 in stock, 0 reserved and 3 shipped, got %d/%d/%d", staging, total, reserved, shipped)
+		}
+	}
+}
+
+func TestPickDirect_RejectsExpiredStock(t *testing.T) {
+	repo := newMemoryStockRepository()
+	expired := time.Now().AddDate(0, 0, -1)
+	repo.SaveStock(nil, &model.Stock{MaterialCode: "MAT-1", LocationCode: "A-01", Quantity: 10, ReservedQuantity: 4, ExpiryDate: &expired})
+	waves := &waveService{ledger: newStockLedger(repo)}
+	allocation := &model.StockAllocation{ID: 1, OrderID: 1, MaterialCode: "MAT-1", LocationCode: "A-01", Quantity: 4}
+
+	// 未记录暂存库位的单据直接拣货出库，与移至暂存库位同样不能取出已过期的库存
+	if err := waves.stage(nil, &model.OutboundOrder{ID: 1}, allocation, 4, nil, 1, "picker"); !errors.Is(err, ErrStockExpired) {
+		t.Errorf("Expected ErrStockExpired, got %v", err)
+	}
+}
+
+func TestPickDirect_ShipsScannedSerials(t *testing.T) {
+	repo := newMemoryStockRepository()
+	repo.materials = map[string]*model.Material{"MAT-1": {Code: "MAT-1", Active: true, SerialTracked: true}}
+	repo.SaveStock(nil, &model.Stock{MaterialCode: "MAT-1", LocationCode: "A-01", Quantity: 2, ReservedQuantity: 1})
+	for _, number := range []string{"SN-1", "SN-2"} {
+		repo.SaveSerial(nil, &model.SerialNumber{MaterialCode: "MAT-1", SerialNumber: number, LocationCode: "A-01", Status: model.SerialStatusInStock})
+	}
+	waves := &waveService{ledger: newStockLedger(repo)}
+	order := &model.OutboundOrder{ID: 1}
+	allocation := &model.StockAllocation{ID: 1, OrderID: 1, MaterialCode: "MAT-1", LocationCode: "A-01", Quantity: 1}
+
+	if err := waves.stage(nil, order, allocation, 1, nil, 1, "picker"); !errors.Is(err, ErrSerialRequired) {
+		t.Fatalf("Expected ErrSerialRequired without scanned serials, got %v", err)
+	}
+	if err := waves.stage(nil, order, allocation, 1, []string{"SN-2"}, 1, "picker"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := repo.serials["MAT-1/SN-2"]; got.Status != model.SerialStatusShipped {
+		t.Errorf("Expected scanned SN-2 shipped, got %+v", got)
+	}
+	if got := repo.serials["MAT-1/SN-1"]; got.Status != model.SerialStatusInStock {
+		t.Errorf("Expected SN-1 still in stock, got %+v", got)
+	}
+}
@@ -19,6 +19,50 @@ type Config struct {
 
 	// 运行环境
 	Environment string
+
+	// 盘点差异默认容差（未匹配到物料/库位容差配置时生效），默认 10 件 / 5%，负数表示不限制
+	VarianceAbsoluteTolerance int
+	VariancePercentTolerance  float64
+
+	// 绝对差异超过该值的盘点必须给出差异原因代码，负数表示原因始终可选
+	VarianceReasonThreshold int
+
+	// 事务因序列化失败或死锁中止时的最大尝试次数与首次重试等待时间（毫秒）
+	TxMaxAttempts      int
+	TxRetryBaseDelayMs int
+
+	// 入库单据未指定时使用的收货暂存库位与允许超收的百分比
+	ReceivingLocation  string
+	OverReceiptPercent float64
+
+	// 出库单据未指定时使用的库存分配策略：fifo、fefo、fewest_locations、clear_small_bins
+	AllocationStrategy string
+
+	// 拣货确认后货物暂存待包装的库位，发运确认时从该库位扣减
+	PackingLocation string
+
+	// 越库暂存库位：标记为越库的物料到货后直接移至该库位并分配给等待中的出库单据
+	CrossDockLocation string
+
+	// 客户退货按处置方式过账的默认库位：可重新上架（收货后生成上架任务）、隔离、报废、待退供应商
+	// 为空表示该处置方式须在收货时指定库位
+	ReturnsLocation      string
+	QuarantineLocation   string
+	ScrapLocation        string
+	VendorReturnLocation string
+
+	// 是否在服务内每天定时生成循环盘点任务，以及生成时刻（0-23 点）
+	CycleCountEnabled bool
+	CycleCountRunHour int
+
+	// 是否在服务内定时扫描拣货位补货水位，以及扫描间隔（分钟）
+	ReplenishmentEnabled         bool
+	ReplenishmentIntervalMinutes int
+
+	// 是否在服务内每天定时列出临期库存，临期天数与执行时刻（0-23 点）
+	ExpiryAlertEnabled bool
+	ExpiryAlertDays    int
+	ExpiryAlertRunHour int
 }
 
 // NewConfig 创建并初始化一个新的 Config 实例
@@ -32,6 +76,35 @@ func NewConfig() (*Config, error) {
 		ServerPort:    getEnvAsInt("SERVER_PORT", 8080),
 		DatabaseDSN:   getEnv("DATABASE_DSN", ""),
 		Environment:   getEnv("ENVIRONMENT", "development"),
+
+		VarianceAbsoluteTolerance: getEnvAsInt("VARIANCE_ABSOLUTE_TOLERANCE", 10),
+		VariancePercentTolerance:  getEnvAsFloat("VARIANCE_PERCENT_TOLERANCE", 5),
+		VarianceReasonThreshold:   getEnvAsInt("VARIANCE_REASON_THRESHOLD", -1),
+
+		TxMaxAttempts:      getEnvAsInt("TX_MAX_ATTEMPTS", 3),
+		TxRetryBaseDelayMs: getEnvAsInt("TX_RETRY_BASE_DELAY_MS", 20),
+
+		ReceivingLocation:  getEnv("RECEIVING_LOCATION", "RECEIVING"),
+		OverReceiptPercent: getEnvAsFloat("OVER_RECEIPT_PERCENT", 0),
+
+		AllocationStrategy: getEnv("ALLOCATION_STRATEGY", "fifo"),
+		PackingLocation:    getEnv("PACKING_LOCATION", "PACKING"),
+		CrossDockLocation:  getEnv("CROSS_DOCK_LOCATION", "XDOCK"),
+
+		ReturnsLocation:      getEnv("RETURNS_LOCATION", "RETURNS"),
+		QuarantineLocation:   getEnv("QUARANTINE_LOCATION", "QUARANTINE"),
+		ScrapLocation:        getEnv("SCRAP_LOCATION", "SCRAP"),
+		VendorReturnLocation: getEnv("VENDOR_RETURN_LOCATION", "RTV"),
+
+		CycleCountEnabled: getEnvAsBool("CYCLE_COUNT_ENABLED", false),
+		CycleCountRunHour: getEnvAsInt("CYCLE_COUNT_RUN_HOUR", 2),
+
+		ReplenishmentEnabled:         getEnvAsBool("REPLENISHMENT_ENABLED", false),
+		ReplenishmentIntervalMinutes: getEnvAsInt("REPLENISHMENT_INTERVAL_MINUTES", 30),
+
+		ExpiryAlertEnabled: getEnvAsBool("EXPIRY_ALERT_ENABLED", false),
+		ExpiryAlertDays:    getEnvAsInt("EXPIRY_ALERT_DAYS", 30),
+		ExpiryAlertRunHour: getEnvAsInt("EXPIRY_ALERT_RUN_HOUR", 6),
 	}
 
 	// 校验必需的配置项
@@ -50,6 +123,32 @@ func (c *Config) validate() error {
 	if c.ServerPort <= 0 || c.ServerPort > 65535 {
 		return fmt.Errorf("SERVER_PORT must be between 1 and 65535, got: %d", c.ServerPort)
 	}
+	if c.TxMaxAttempts < 1 {
+		return fmt.Errorf("TX_MAX_ATTEMPTS must be at least 1, got: %d", c.TxMaxAttempts)
+	}
+	if c.OverReceiptPercent < 0 {
+		return fmt.Errorf("OVER_RECEIPT_PERCENT cannot be negative, got: %v", c.OverReceiptPercent)
+	}
+	switch c.AllocationStrategy {
+	case "fifo", "fefo", "fewest_locations", "clear_small_bins":
+	default:
+		return fmt.Errorf("ALLOCATION_STRATEGY must be one of fifo, fefo, fewest_locations, clear_small_bins, got: %s", c.AllocationStrategy)
+	}
+	if c.PackingLocation == "" {
+		return fmt.Errorf("PACKING_LOCATION cannot be empty")
+	}
+	if c.CycleCountRunHour < 0 || c.CycleCountRunHour > 23 {
+		return fmt.Errorf("CYCLE_COUNT_RUN_HOUR must be between 0 and 23, got: %d", c.CycleCountRunHour)
+	}
+	if c.ReplenishmentIntervalMinutes <= 0 {
+		return fmt.Errorf("REPLENISHMENT_INTERVAL_MINUTES must be positive, got: %d", c.ReplenishmentIntervalMinutes)
+	}
+	if c.ExpiryAlertDays < 0 {
+		return fmt.Errorf("EXPIRY_ALERT_DAYS cannot be negative, got: %d", c.ExpiryAlertDays)
+	}
+	if c.ExpiryAlertRunHour < 0 || c.ExpiryAlertRunHour > 23 {
+		return fmt.Errorf("EXPIRY_ALERT_RUN_HOUR must be between 0 and 23, got: %d", c.ExpiryAlertRunHour)
+	}
 	return nil
 }
 
@@ -82,3 +181,27 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+// getEnvAsFloat 获取环境变量的浮点数值，否则返回默认值
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+	if value, err := strconv.ParseFloat(valueStr, 64); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvAsBool 获取环境变量的布尔值，否则返回默认值
+func getEnvAsBool(key string, defaultValue bool) bool {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+	if value, err := strconv.ParseBool(valueStr); err == nil {
+		return value
+	}
+	return defaultValue
+}